@@ -140,3 +140,50 @@ func BenchmarkGetRowByKey(b *testing.B) {
 		}
 	}
 }
+
+// BenchmarkGetRowByKeyEmbedded reads into a struct whose fields are promoted
+// from embedded structs and named after their columns, for comparison with
+// BenchmarkGetRowByKey.
+func BenchmarkGetRowByKeyEmbedded(b *testing.B) {
+	ctx := context.TODO()
+
+	type benchKey struct {
+		Key string
+	}
+	type benchKVRow struct {
+		benchKey
+		Val string
+	}
+
+	localDB, teardown := ldb.LDBForTest(b)
+	defer teardown()
+	_, err := localDB.ExecContext(ctx, `
+			CREATE TABLE foo___bar (
+				key VARCHAR PRIMARY KEY,
+				val VARCHAR
+			);
+			INSERT INTO foo___bar VALUES('foo', 'bar');
+		`)
+	if err != nil {
+		b.Fatalf("Unexpected error inserting value into LDB: %v", err)
+	}
+	r := NewLDBReaderFromDB(localDB)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var row benchKVRow
+		found, err := r.GetRowByKey(ctx, &row, "foo", "bar", "foo")
+		if err != nil {
+			b.Fatalf("Unexpected error calling GetRowByKey: %v", err)
+		}
+		if !found {
+			b.Fatal("Should have found a row")
+		}
+		if row.Key != "foo" {
+			b.Fatalf("Unexpected value in row key: %v", row.Key)
+		}
+		if row.Val != "bar" {
+			b.Fatalf("Unexpected value in row val: %v", row.Val)
+		}
+	}
+}
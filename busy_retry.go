@@ -0,0 +1,101 @@
+package ctlstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/segmentio/errors-go"
+	"github.com/segmentio/go-sqlite3"
+)
+
+const (
+	// DefaultBusyRetries is how many times a reader retries a read which
+	// failed because the LDB was busy, unless configured by WithBusyRetry.
+	DefaultBusyRetries = 3
+	// DefaultBusyBackoff is how long a reader waits before its first retry
+	// of a busy read, unless configured by WithBusyRetry.
+	DefaultBusyBackoff = 10 * time.Millisecond
+
+	// maxBusyBackoff caps the doubling of the backoff between retries
+	maxBusyBackoff = time.Second
+)
+
+// WithBusyRetry configures how the reader retries reads which fail with
+// SQLITE_BUSY or SQLITE_LOCKED, which happens when they collide with the
+// reflector checkpointing the LDB. A read is retried up to retries times,
+// waiting backoff before the first retry and doubling it before each of the
+// others. Retries of zero disables retrying.
+//
+// Retries are counted by the busy-retries metric, and reads which are still
+// busy after the last retry by the busy-retries-exhausted metric.
+func WithBusyRetry(retries int, backoff time.Duration) ReaderOption {
+	if retries < 0 {
+		retries = 0
+	}
+	return func(reader *LDBReader) {
+		reader.busyRetry = &busyRetry{retries: retries, backoff: backoff}
+	}
+}
+
+type busyRetry struct {
+	retries int
+	backoff time.Duration
+}
+
+var defaultBusyRetry = busyRetry{retries: DefaultBusyRetries, backoff: DefaultBusyBackoff}
+
+// retryBusy calls fn, and calls it again while it fails because the LDB is
+// busy, as configured by WithBusyRetry.
+//
+// Only the call itself is retried. Errors from reading the *Rows which
+// GetRowsByKeyPrefix returns are left to the caller.
+func (reader *LDBReader) retryBusy(ctx context.Context, familyName, tableName string, fn func() error) error {
+	cfg := defaultBusyRetry
+	if reader.busyRetry != nil {
+		cfg = *reader.busyRetry
+	}
+	err := fn()
+	backoff := cfg.backoff
+	for i := 0; i < cfg.retries && isBusy(err); i++ {
+		reader.caller.incr("busy-retries", familyName, tableName)
+		if !reader.sleep(ctx, backoff) {
+			return err
+		}
+		if backoff *= 2; backoff > maxBusyBackoff {
+			backoff = maxBusyBackoff
+		}
+		err = fn()
+	}
+	if cfg.retries > 0 && isBusy(err) {
+		reader.caller.incr("busy-retries-exhausted", familyName, tableName)
+	}
+	return err
+}
+
+// sleep waits for d, or returns false if the read's context is done first.
+// As with the reads themselves, the context is only honored by readers
+// configured with WithQueryTimeout.
+func (reader *LDBReader) sleep(ctx context.Context, d time.Duration) bool {
+	if reader.queryTimeout == 0 || ctx == nil {
+		time.Sleep(d)
+		return true
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// isBusy returns whether err is SQLite reporting that the LDB is busy or
+// locked, which is transient.
+func isBusy(err error) bool {
+	if err == nil {
+		return false
+	}
+	e, ok := errors.Cause(err).(sqlite3.Error)
+	return ok && (e.Code == sqlite3.ErrBusy || e.Code == sqlite3.ErrLocked)
+}
@@ -0,0 +1,72 @@
+package ctlstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/segmentio/errors-go"
+	"github.com/segmentio/go-sqlite3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryBusy(t *testing.T) {
+	errBusy := errors.Wrap(sqlite3.Error{Code: sqlite3.ErrBusy}, "query target row error")
+	errLocked := sqlite3.Error{Code: sqlite3.ErrLocked}
+
+	// failing returns a func which fails with the errors in order, and then
+	// succeeds, along with how many times it was called
+	failing := func(errs ...error) (func() error, *int) {
+		calls := new(int)
+		return func() error {
+			*calls++
+			if *calls <= len(errs) {
+				return errs[*calls-1]
+			}
+			return nil
+		}, calls
+	}
+
+	t.Run("busy and locked are retried", func(t *testing.T) {
+		reader := &LDBReader{}
+		WithBusyRetry(3, time.Millisecond)(reader)
+		fn, calls := failing(errBusy, errLocked)
+		require.NoError(t, reader.retryBusy(context.Background(), "family", "table", fn))
+		require.Equal(t, 3, *calls)
+	})
+
+	t.Run("retries are limited", func(t *testing.T) {
+		reader := &LDBReader{}
+		WithBusyRetry(2, time.Millisecond)(reader)
+		fn, calls := failing(errBusy, errBusy, errBusy, errBusy)
+		err := reader.retryBusy(context.Background(), "family", "table", fn)
+		require.True(t, isBusy(err))
+		require.Equal(t, 3, *calls)
+	})
+
+	t.Run("other errors are not retried", func(t *testing.T) {
+		reader := &LDBReader{}
+		fn, calls := failing(ErrTableNotFound)
+		require.Equal(t, ErrTableNotFound, reader.retryBusy(context.Background(), "family", "table", fn))
+		require.Equal(t, 1, *calls)
+	})
+
+	t.Run("retrying can be disabled", func(t *testing.T) {
+		reader := &LDBReader{}
+		WithBusyRetry(0, 0)(reader)
+		fn, calls := failing(errBusy)
+		require.Equal(t, errBusy, reader.retryBusy(context.Background(), "family", "table", fn))
+		require.Equal(t, 1, *calls)
+	})
+
+	t.Run("retrying stops when the context is done", func(t *testing.T) {
+		reader := &LDBReader{}
+		WithQueryTimeout(time.Minute)(reader)
+		WithBusyRetry(3, time.Minute)(reader)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		fn, calls := failing(errBusy, errBusy)
+		require.Equal(t, errBusy, reader.retryBusy(ctx, "family", "table", fn))
+		require.Equal(t, 1, *calls)
+	})
+}
@@ -0,0 +1,36 @@
+package ctlstore
+
+import (
+	"github.com/segmentio/stats/v4"
+
+	"github.com/segmentio/ctlstore/pkg/globalstats"
+)
+
+// WithCallerTag tags the reader's get_row_by_key and get_rows_by_key_prefix
+// metrics, and its full-table-scans counter, with the service and endpoint
+// using it, so that load on the LDB can be attributed to its consumers.
+func WithCallerTag(service, endpoint string) ReaderOption {
+	return func(reader *LDBReader) {
+		reader.caller = callerTag{service: service, endpoint: endpoint}
+	}
+}
+
+type callerTag struct {
+	service  string
+	endpoint string
+}
+
+func (c callerTag) tags(familyName, tableName string) []stats.Tag {
+	tags := []stats.Tag{stats.T("family", familyName), stats.T("table", tableName)}
+	if c.service != "" {
+		tags = append(tags, stats.T("caller_service", c.service))
+	}
+	if c.endpoint != "" {
+		tags = append(tags, stats.T("caller_endpoint", c.endpoint))
+	}
+	return tags
+}
+
+func (c callerTag) incr(name, familyName, tableName string) {
+	globalstats.IncrCaller(name, familyName, tableName, c.service, c.endpoint)
+}
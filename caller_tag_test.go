@@ -0,0 +1,31 @@
+package ctlstore
+
+import (
+	"testing"
+
+	"github.com/segmentio/stats/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithCallerTag(t *testing.T) {
+	reader := &LDBReader{}
+	require.Equal(t, []stats.Tag{
+		stats.T("family", "foo"),
+		stats.T("table", "bar"),
+	}, reader.caller.tags("foo", "bar"))
+
+	WithCallerTag("service-x", "endpoint-y")(reader)
+	require.Equal(t, []stats.Tag{
+		stats.T("family", "foo"),
+		stats.T("table", "bar"),
+		stats.T("caller_service", "service-x"),
+		stats.T("caller_endpoint", "endpoint-y"),
+	}, reader.caller.tags("foo", "bar"))
+
+	WithCallerTag("service-x", "")(reader)
+	require.Equal(t, []stats.Tag{
+		stats.T("family", "foo"),
+		stats.T("table", "bar"),
+		stats.T("caller_service", "service-x"),
+	}, reader.caller.tags("foo", "bar"))
+}
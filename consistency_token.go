@@ -0,0 +1,117 @@
+package ctlstore
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/segmentio/errors-go"
+
+	"github.com/segmentio/ctlstore/pkg/ldb"
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+// ConsistencyTokenHeader is the HTTP header the sidecar uses both to return
+// a ConsistencyToken and to accept one from a client.
+const ConsistencyTokenHeader = "X-Ctlstore-Consistency-Token"
+
+const consistencyPollInterval = 10 * time.Millisecond
+
+var (
+	ErrInvalidConsistencyToken  = errors.New("invalid consistency token")
+	ErrConsistencyTokenMismatch = errors.New("consistency token was issued by a different LDB")
+	ErrConsistencyNotReached    = errors.New("LDB has not caught up to the consistency token")
+)
+
+// ConsistencyToken captures how fresh an LDB was when a read was served.
+// Tokens issued by the in-process reader and by the sidecar are
+// interchangeable, so a client can read through one and then demand that
+// a later read through the other be at least as fresh.
+type ConsistencyToken struct {
+	// LDB identifies the LDB the token was issued by. LDBs bootstrapped
+	// from the same snapshot share an identity, and so can compare
+	// sequences.
+	LDB string
+	// Sequence is the last ledger sequence applied to the LDB.
+	Sequence schema.DMLSequence
+}
+
+// String encodes the token for use in a header or query parameter.
+func (t ConsistencyToken) String() string {
+	raw := t.LDB + ":" + strconv.FormatInt(t.Sequence.Int(), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseConsistencyToken decodes a token produced by ConsistencyToken.String.
+func ParseConsistencyToken(s string) (ConsistencyToken, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return ConsistencyToken{}, ErrInvalidConsistencyToken
+	}
+	idx := strings.LastIndexByte(string(raw), ':')
+	if idx < 0 {
+		return ConsistencyToken{}, ErrInvalidConsistencyToken
+	}
+	seq, err := strconv.ParseInt(string(raw[idx+1:]), 10, 64)
+	if err != nil || seq < 0 {
+		return ConsistencyToken{}, ErrInvalidConsistencyToken
+	}
+	return ConsistencyToken{LDB: string(raw[:idx]), Sequence: schema.DMLSequence(seq)}, nil
+}
+
+// Satisfies reports whether a read made at t is at least as fresh as one
+// made at other. Tokens from different LDBs are never comparable, unless
+// either LDB predates identities.
+func (t ConsistencyToken) Satisfies(other ConsistencyToken) (bool, error) {
+	if t.LDB != "" && other.LDB != "" && t.LDB != other.LDB {
+		return false, ErrConsistencyTokenMismatch
+	}
+	return t.Sequence >= other.Sequence, nil
+}
+
+// ConsistencyToken returns a token describing the current state of the
+// LDB. Reads made after calling it observe at least that state.
+func (reader *LDBReader) ConsistencyToken(ctx context.Context) (ConsistencyToken, error) {
+	ctx, cancel := reader.queryContext(ctx)
+	defer cancel()
+	reader.mu.RLock()
+	defer reader.mu.RUnlock()
+	identity, err := ldb.FetchIdentityFromLdb(ctx, reader.Db)
+	if err != nil {
+		return ConsistencyToken{}, observeQueryErr(ctx, errors.Wrap(err, "fetch ldb identity"), "", "")
+	}
+	seq, err := ldb.FetchSeqFromLdb(ctx, reader.Db)
+	if err != nil {
+		return ConsistencyToken{}, observeQueryErr(ctx, errors.Wrap(err, "fetch ldb sequence"), "", "")
+	}
+	return ConsistencyToken{LDB: identity, Sequence: seq}, nil
+}
+
+// WaitForConsistency blocks until the LDB is at least as fresh as the
+// supplied token. It returns ErrConsistencyNotReached if the context is
+// done first, and ErrConsistencyTokenMismatch if the token was issued by
+// a different LDB.
+//
+// Unlike the read methods, this honors the context, since waiting for the
+// reflector is not a blocking sqlite call.
+func (reader *LDBReader) WaitForConsistency(ctx context.Context, token ConsistencyToken) error {
+	ticker := time.NewTicker(consistencyPollInterval)
+	defer ticker.Stop()
+	for {
+		current, err := reader.ConsistencyToken(ctx)
+		if err != nil {
+			return err
+		}
+		ok, err := current.Satisfies(token)
+		if err != nil || ok {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ErrConsistencyNotReached
+		case <-ticker.C:
+		}
+	}
+}
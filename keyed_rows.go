@@ -0,0 +1,251 @@
+package ctlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/segmentio/errors-go"
+
+	"github.com/segmentio/ctlstore/pkg/globalstats"
+	"github.com/segmentio/ctlstore/pkg/scanfunc"
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+// maxKeyVarsPerQuery bounds how many values GetRowsByKeys binds in a single
+// query, keeping well under SQLite's limit on variables. Larger sets of keys
+// are read in chunks.
+const maxKeyVarsPerQuery = 900
+
+// KeyedRows are the rows read by GetRowsByKeys, keyed by their primary key.
+// Keys which didn't match a row are absent.
+type KeyedRows struct {
+	pk   schema.PrimaryKey
+	rows map[string]map[string]interface{}
+}
+
+// Get returns the row with the primary key, if one was read. The key is
+// given the same way as to GetRowByKey.
+func (r *KeyedRows) Get(key ...interface{}) (row map[string]interface{}, found bool) {
+	if r == nil {
+		return nil, false
+	}
+	// rows read from a sidecar don't come with the primary key
+	if !r.pk.Zero() {
+		if len(key) != len(r.pk.Fields) {
+			return nil, false
+		}
+		key = append([]interface{}{}, key...)
+		if err := convertKeyBeforeQuery(r.pk, key); err != nil {
+			return nil, false
+		}
+	}
+	row, found = r.rows[encodeRowKey(key)]
+	return row, found
+}
+
+// Len returns how many rows were read.
+func (r *KeyedRows) Len() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.rows)
+}
+
+// Range calls fn with each row read, in no particular order, until fn
+// returns false.
+func (r *KeyedRows) Range(fn func(row map[string]interface{}) bool) {
+	if r == nil {
+		return
+	}
+	for _, row := range r.rows {
+		if !fn(row) {
+			return
+		}
+	}
+}
+
+func (r *KeyedRows) add(row map[string]interface{}) {
+	key := make([]interface{}, len(r.pk.Fields))
+	for i, f := range r.pk.Fields {
+		key[i] = row[f.Name]
+	}
+	r.rows[encodeRowKey(key)] = row
+}
+
+// GetRowsByKeys reads the rows of the table with each of the primary keys,
+// in as few queries as possible, which is much cheaper than calling
+// GetRowByKey for each key. As with GetRowByKey, each key must have a value
+// for every primary key field.
+func (reader *LDBReader) GetRowsByKeys(ctx context.Context, familyName string, tableName string, keys [][]interface{}) (res *KeyedRows, err error) {
+	err = reader.retryBusy(ctx, familyName, tableName, func() (err error) {
+		res, err = reader.getRowsByKeys(ctx, nil, familyName, tableName, keys)
+		return err
+	})
+	return res, err
+}
+
+// getRowsByKeys reads the rows from the snapshot, if it isn't nil
+func (reader *LDBReader) getRowsByKeys(ctx context.Context, snap *Snapshot, familyName string, tableName string, keys [][]interface{}) (res *KeyedRows, err error) {
+	ctx, cancel := reader.queryContext(ctx)
+	defer cancel()
+	defer func() { err = observeQueryErr(ctx, err, familyName, tableName) }()
+	start := time.Now()
+	defer func() {
+		globalstats.Observe("get_rows_by_keys", time.Now().Sub(start),
+			reader.caller.tags(familyName, tableName)...)
+	}()
+
+	reader.mu.RLock()
+	defer reader.mu.RUnlock()
+	ldbTable, pk, err := reader.keyedTable(ctx, familyName, tableName)
+	if err == ErrTableNotFound && reader.fallback != nil && snap == nil {
+		// the sidecar reads a row at a time
+		res = &KeyedRows{pk: pk, rows: map[string]map[string]interface{}{}}
+		for _, key := range keys {
+			row := map[string]interface{}{}
+			found, err := reader.fallback.getRowByKey(ctx, row, familyName, tableName, key)
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				res.rows[encodeRowKey(key)] = row
+			}
+		}
+		return res, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the keys are converted and deduplicated before they're queried
+	unique := make([][]interface{}, 0, len(keys))
+	seen := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		if len(key) != len(pk.Fields) {
+			return nil, ErrNeedFullKey
+		}
+		key = append([]interface{}{}, key...)
+		if err := convertKeyBeforeQuery(pk, key); err != nil {
+			return nil, err
+		}
+		enc := encodeRowKey(key)
+		if _, ok := seen[enc]; ok {
+			continue
+		}
+		seen[enc] = struct{}{}
+		unique = append(unique, key)
+	}
+
+	res = &KeyedRows{pk: pk, rows: make(map[string]map[string]interface{}, len(unique))}
+	chunkSize := maxKeyVarsPerQuery / len(pk.Fields)
+	for len(unique) > 0 {
+		chunk := unique
+		if len(chunk) > chunkSize {
+			chunk = chunk[:chunkSize]
+		}
+		unique = unique[len(chunk):]
+
+		args := make([]interface{}, 0, len(chunk)*len(pk.Fields))
+		for _, key := range chunk {
+			args = append(args, key...)
+		}
+		query := rowsByKeysQuery(pk, ldbTable, len(chunk))
+		var rows *sql.Rows
+		if snap != nil {
+			var stmt *sql.Stmt
+			if stmt, err = snap.prepare(ctx, query); err != nil {
+				return nil, err
+			}
+			rows, err = stmt.QueryContext(ctx, args...)
+		} else {
+			rows, err = reader.Db.QueryContext(ctx, query, args...)
+		}
+		if err != nil {
+			reader.invalidatePKCache(ldbTable) // assumes RLock is held
+			return nil, errors.Wrap(err, "query target rows error")
+		}
+		if err = res.scan(rows); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// scan adds the rows to the result, and closes them.
+func (r *KeyedRows) scan(rows *sql.Rows) error {
+	defer rows.Close()
+	cols, err := schema.DBColumnMetaFromRows(rows)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		row := make(map[string]interface{}, len(cols))
+		scanFunc, err := scanfunc.New(row, cols)
+		if err != nil {
+			return err
+		}
+		if err := scanFunc(rows); err != nil {
+			return errors.Wrap(err, "target row scan error")
+		}
+		r.add(row)
+	}
+	return rows.Err()
+}
+
+// rowsByKeysQuery selects the rows of the table matching any of numKeys
+// full primary keys.
+func rowsByKeysQuery(pk schema.PrimaryKey, ldbTable string, numKeys int) string {
+	fields := make([]string, len(pk.Fields))
+	for i, f := range pk.Fields {
+		fields[i] = f.Name
+	}
+	tuple := "?"
+	if len(fields) > 1 {
+		tuple = "(" + strings.TrimSuffix(strings.Repeat("?, ", len(fields)), ", ") + ")"
+	}
+	tuples := strings.TrimSuffix(strings.Repeat(tuple+", ", numKeys), ", ")
+
+	if len(fields) == 1 {
+		return "SELECT * FROM " + ldbTable + " WHERE " + fields[0] + " IN (" + tuples + ")"
+	}
+	return "SELECT * FROM " + ldbTable + " WHERE (" + strings.Join(fields, ", ") + ") IN (VALUES " + tuples + ")"
+}
+
+// encodeRowKey encodes the values of a primary key as a map key, such that
+// the values supplied by callers and those scanned from the LDB encode the
+// same way.
+func encodeRowKey(key []interface{}) string {
+	var b strings.Builder
+	for _, v := range key {
+		rv := reflect.ValueOf(v)
+		switch {
+		case v == nil:
+			b.WriteString("n")
+		case rv.Kind() == reflect.Bool:
+			if rv.Bool() {
+				b.WriteString("i1")
+			} else {
+				b.WriteString("i0")
+			}
+		case rv.Kind() >= reflect.Int && rv.Kind() <= reflect.Int64:
+			b.WriteString("i" + strconv.FormatInt(rv.Int(), 10))
+		case rv.Kind() >= reflect.Uint && rv.Kind() <= reflect.Uint64:
+			b.WriteString("i" + strconv.FormatUint(rv.Uint(), 10))
+		case rv.Kind() == reflect.Float32 || rv.Kind() == reflect.Float64:
+			b.WriteString("f" + strconv.FormatFloat(rv.Float(), 'g', -1, 64))
+		case rv.Kind() == reflect.String:
+			b.WriteString("s" + strconv.Quote(rv.String()))
+		case rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8:
+			b.WriteString("s" + strconv.Quote(string(rv.Bytes())))
+		default:
+			b.WriteString("v" + strconv.Quote(fmt.Sprintf("%T:%v", v, v)))
+		}
+		b.WriteByte(',')
+	}
+	return b.String()
+}
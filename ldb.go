@@ -36,12 +36,13 @@ func init() {
 
 // ReaderForPath opens an LDB at the provided path and returns an LDBReader
 // instance pointed at that LDB.
-func ReaderForPath(path string) (*LDBReader, error) {
-	return newLDBReader(path)
+func ReaderForPath(path string, opts ...ReaderOption) (*LDBReader, error) {
+	return newLDBReader(path, opts...)
 }
 
-// Reader returns an LDBReader that can be used globally.
-func Reader() (*LDBReader, error) {
+// Reader returns an LDBReader that can be used globally. The options only
+// apply to the first call, which opens the LDB.
+func Reader(opts ...ReaderOption) (*LDBReader, error) {
 	globalReaderMu.RLock()
 	defer globalReaderMu.RUnlock()
 
@@ -55,13 +56,14 @@ func Reader() (*LDBReader, error) {
 			var reader *LDBReader
 			var err error
 			if ldbVersioning {
-				reader, err = newVersionedLDBReader(globalLDBVersioningDirPath)
+				reader, err = newVersionedLDBReader(globalLDBVersioningDirPath, opts...)
 			} else {
-				reader, err = newLDBReader(filepath.Join(globalLDBDirPath, ldb.DefaultLDBFilename))
+				reader, err = newLDBReader(filepath.Join(globalLDBDirPath, ldb.DefaultLDBFilename), opts...)
 			}
 			if err != nil {
 				return nil, err
 			}
+			reader.changelogPath = globalCLPath
 			globalReader = reader
 		}
 	}
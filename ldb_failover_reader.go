@@ -0,0 +1,161 @@
+package ctlstore
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/segmentio/errors-go"
+	"github.com/segmentio/events/v2"
+	"github.com/segmentio/stats/v4"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+	"github.com/segmentio/ctlstore/pkg/globalstats"
+)
+
+const (
+	defaultFailoverCheckInterval = time.Second
+	// how much further behind the active LDB must be than the freshest one
+	// before reads fail over, so that reads don't flap between LDBs which
+	// are applying the same ledger a moment apart
+	defaultFailoverLatencyMargin = 10 * time.Second
+)
+
+// LDBFailoverReader reads from whichever of several LDBs on the host is the
+// healthiest, for deployments running one reflector per LDB. Each LDB is
+// checked in the background, and reads fail over from the active LDB if it
+// stops responding to pings or falls behind the others, e.g. because its
+// reflector stalled.
+type LDBFailoverReader struct {
+	active         int32
+	readers        []*LDBReader
+	checkInterval  time.Duration
+	latencyMargin  time.Duration
+	cancelChecking context.CancelFunc
+}
+
+// ReaderForPaths opens the LDBs at the provided paths and returns a reader
+// which routes reads to the healthiest of them. Close the reader to stop
+// health checking.
+func ReaderForPaths(paths []string, opts ...ReaderOption) (*LDBFailoverReader, error) {
+	r, err := failoverReader(paths, opts...)
+	if err != nil {
+		return nil, err
+	}
+	r.check(context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancelChecking = cancel
+	go r.checkLoop(ctx)
+	return r, nil
+}
+
+func failoverReader(paths []string, opts ...ReaderOption) (*LDBFailoverReader, error) {
+	if len(paths) == 0 {
+		return nil, errors.New("ReaderForPaths requires at least 1 ldb")
+	}
+	r := &LDBFailoverReader{
+		checkInterval: defaultFailoverCheckInterval,
+		latencyMargin: defaultFailoverLatencyMargin,
+	}
+	for _, p := range paths {
+		events.Log("Opening ldb %s for reading", p)
+		reader, err := newLDBReader(p, opts...)
+		if err != nil {
+			r.closeReaders()
+			return nil, err
+		}
+		r.readers = append(r.readers, reader)
+	}
+	return r, nil
+}
+
+func (r *LDBFailoverReader) checkLoop(ctx context.Context) {
+	ticker := time.NewTicker(r.checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.check(ctx)
+		}
+	}
+}
+
+// check health checks every LDB and switches the active one if it is
+// unhealthy, or further behind the freshest LDB than the latency margin.
+func (r *LDBFailoverReader) check(ctx context.Context) {
+	best, bestLatency := -1, time.Duration(0)
+	latencies := make([]time.Duration, len(r.readers))
+	healthy := make([]bool, len(r.readers))
+	for i, reader := range r.readers {
+		if !reader.Ping(ctx) {
+			continue
+		}
+		latency, err := reader.GetLedgerLatency(ctx)
+		if err != nil {
+			continue
+		}
+		latencies[i], healthy[i] = latency, true
+		if best == -1 || latency < bestLatency {
+			best, bestLatency = i, latency
+		}
+	}
+	if best == -1 {
+		// with nothing to fail over to, keep reading from the active LDB
+		errs.Incr("failover_reader.no_healthy_ldb")
+		return
+	}
+
+	active := int(atomic.LoadInt32(&r.active))
+	if healthy[active] && latencies[active]-bestLatency <= r.latencyMargin {
+		return
+	}
+	atomic.StoreInt32(&r.active, int32(best))
+	events.Log("Failing over reads from ldb %s to %s", r.readers[active].path, r.readers[best].path)
+	stats.Incr("failover_reader.failover", stats.T("from", strconv.Itoa(active)), stats.T("to", strconv.Itoa(best)))
+	globalstats.Set("failover_reader.active", best)
+}
+
+func (r *LDBFailoverReader) activeReader() *LDBReader {
+	return r.readers[atomic.LoadInt32(&r.active)]
+}
+
+// GetRowsByKeyPrefix delegates to the active LDBReader
+func (r *LDBFailoverReader) GetRowsByKeyPrefix(ctx context.Context, familyName string, tableName string, key ...interface{}) (*Rows, error) {
+	return r.activeReader().GetRowsByKeyPrefix(ctx, familyName, tableName, key...)
+}
+
+// GetRowByKey delegates to the active LDBReader
+func (r *LDBFailoverReader) GetRowByKey(ctx context.Context, out interface{}, familyName string, tableName string, key ...interface{}) (found bool, err error) {
+	return r.activeReader().GetRowByKey(ctx, out, familyName, tableName, key...)
+}
+
+// GetLedgerLatency delegates to the active LDBReader
+func (r *LDBFailoverReader) GetLedgerLatency(ctx context.Context) (time.Duration, error) {
+	return r.activeReader().GetLedgerLatency(ctx)
+}
+
+// Ping delegates to the active LDBReader
+func (r *LDBFailoverReader) Ping(ctx context.Context) bool {
+	return r.activeReader().Ping(ctx)
+}
+
+// Close stops health checking and closes every LDB.
+func (r *LDBFailoverReader) Close() error {
+	if r.cancelChecking != nil {
+		r.cancelChecking()
+	}
+	return r.closeReaders()
+}
+
+func (r *LDBFailoverReader) closeReaders() error {
+	var err error
+	for _, reader := range r.readers {
+		if cerr := reader.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,71 @@
+package ctlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/ldb"
+)
+
+func TestFailoverReader(t *testing.T) {
+	ctx := context.Background()
+	dbs, paths := getMultiDBs(t, 2)
+	setLedgerUpdate := func(db *sql.DB, seq int64, at time.Time) {
+		_, err := db.Exec(fmt.Sprintf("REPLACE INTO %s (id, seq) VALUES (?, ?)", ldb.LDBSeqTableName), ldb.LDBSeqTableID, seq)
+		require.NoError(t, err)
+		_, err = db.Exec(fmt.Sprintf("REPLACE INTO %s (name, timestamp) VALUES (?, ?)", ldb.LDBLastUpdateTableName), ldb.LDBLastLedgerUpdateColumn, at)
+		require.NoError(t, err)
+	}
+	for i, db := range dbs {
+		_, err := db.Exec("CREATE TABLE family___table (x integer primary key)")
+		require.NoError(t, err)
+		_, err = db.Exec("INSERT INTO family___table VALUES (?)", i+1)
+		require.NoError(t, err)
+	}
+
+	r, err := failoverReader(paths)
+	require.NoError(t, err)
+	defer r.Close()
+	activeRow := func() int32 {
+		var out basic
+		found, err := r.GetRowByKey(ctx, &out, "family", "table", r.active+1)
+		require.NoError(t, err)
+		require.True(t, found)
+		return out.x
+	}
+
+	// neither LDB has applied the ledger yet, so reads stay on the first
+	r.check(ctx)
+	require.EqualValues(t, 1, activeRow())
+
+	// the first LDB only falls behind within the margin
+	now := time.Now()
+	setLedgerUpdate(dbs[0], 10, now.Add(-5*time.Second))
+	setLedgerUpdate(dbs[1], 12, now)
+	r.check(ctx)
+	require.EqualValues(t, 1, activeRow())
+
+	// and then its reflector stalls
+	setLedgerUpdate(dbs[0], 10, now.Add(-time.Minute))
+	r.check(ctx)
+	require.EqualValues(t, 2, activeRow())
+
+	// once it recovers, reads stay where they are until the second stalls
+	setLedgerUpdate(dbs[0], 12, now)
+	r.check(ctx)
+	require.EqualValues(t, 2, activeRow())
+	_, err = dbs[1].Exec("DELETE FROM " + ldb.LDBSeqTableName)
+	require.NoError(t, err)
+	r.check(ctx)
+	require.EqualValues(t, 1, activeRow())
+}
+
+func TestReaderForPathsRequiresPaths(t *testing.T) {
+	_, err := ReaderForPaths(nil)
+	require.EqualError(t, err, "ReaderForPaths requires at least 1 ldb")
+}
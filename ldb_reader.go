@@ -13,7 +13,6 @@ import (
 
 	"github.com/segmentio/errors-go"
 	"github.com/segmentio/events/v2"
-	"github.com/segmentio/stats/v4"
 
 	"github.com/segmentio/ctlstore/pkg/errs"
 	"github.com/segmentio/ctlstore/pkg/globalstats"
@@ -32,8 +31,20 @@ type LDBReader struct {
 	pkCache                     map[string]schema.PrimaryKey // keyed by ldbTableName()
 	getRowByKeyStmtCache        map[string]*sql.Stmt         // keyed by ldbTableName()
 	getRowsByKeyPrefixStmtCache map[prefixCacheKey]*sql.Stmt
+	queryStmtCache              map[string]*sql.Stmt // keyed by query, for counts and existence checks
 	mu                          sync.RWMutex
 	cancelWatcher               context.CancelFunc
+	fallback                    *sidecarFallback
+	changelogPath               string
+	watch                       rowWatchers
+	queryTimeout                time.Duration // see WithQueryTimeout
+	caller                      callerTag     // see WithCallerTag
+	pragmas                     *ldb.Pragmas  // see WithPragmas
+	busyRetry                   *busyRetry    // see WithBusyRetry
+	immutable                   bool          // see WithImmutable
+	sharedCache                 bool          // see WithSharedCache
+	maxOpenConns                int           // see WithMaxOpenConns
+	resultLimits                ResultLimits  // see WithResultLimits
 }
 
 type prefixCacheKey struct {
@@ -45,6 +56,7 @@ var (
 	ErrTableHasNoPrimaryKey = errors.New("Table provided has no primary key")
 	ErrNeedFullKey          = errors.New("All primary key fields are required")
 	ErrNoLedgerUpdates      = errors.New("no ledger updates have been received yet")
+	ErrTableNotFound        = errors.New("Table not found")
 )
 
 type RowRetriever interface {
@@ -52,19 +64,38 @@ type RowRetriever interface {
 	GetRowByKey(ctx context.Context, out interface{}, familyName string, tableName string, key ...interface{}) (found bool, err error)
 }
 
-func newLDBReader(path string) (*LDBReader, error) {
-	db, err := newLDB(path)
+func newLDBReader(path string, opts ...ReaderOption) (*LDBReader, error) {
+	reader := &LDBReader{path: path}
+	for _, opt := range opts {
+		opt(reader)
+	}
+	// the reflector may have rebuilt the LDB elsewhere, in which case it
+	// names the rebuilt LDB in a file next to the configured one
+	current, err := ldb.CurrentPath(path)
+	if err != nil {
+		return nil, err
+	}
+	db, err := reader.openLDB(current)
 	if err != nil {
 		return nil, err
 	}
-	return &LDBReader{Db: db, path: path}, nil
+	reader.Db = db
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reader.cancelWatcher = cancel
+	go reader.watchForRebuiltLDB(ctx, path, current)
+
+	return reader, nil
 }
 
-func newVersionedLDBReader(dirPath string) (*LDBReader, error) {
+func newVersionedLDBReader(dirPath string, opts ...ReaderOption) (*LDBReader, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 	reader := &LDBReader{
 		cancelWatcher: cancel,
 	}
+	for _, opt := range opts {
+		opt(reader)
+	}
 
 	// To initialize this reader, we must first load an LDB:
 	last, err := lookupLastLDBSync(dirPath)
@@ -86,33 +117,6 @@ func newVersionedLDBReader(dirPath string) (*LDBReader, error) {
 	return reader, nil
 }
 
-func newLDB(path string) (*sql.DB, error) {
-	_, err := os.Stat(path)
-	switch {
-	case os.IsNotExist(err):
-		return nil, fmt.Errorf("no LDB found at %s", path)
-	case err != nil:
-		return nil, err
-	}
-
-	var db *sql.DB
-	if ldbVersioning {
-		db, err = ldb.OpenImmutableLDB(path)
-	} else {
-		mode := "ro"
-		if !globalLDBReadOnly {
-			mode = "rwc"
-		}
-
-		db, err = ldb.OpenLDB(path, mode)
-	}
-	if err != nil {
-		return nil, err
-	}
-
-	return db, nil
-}
-
 // Constructs an LDBReader from a sql.DB. Really only useful for testing.
 func NewLDBReaderFromDB(db *sql.DB) *LDBReader {
 	return &LDBReader{Db: db}
@@ -120,39 +124,138 @@ func NewLDBReaderFromDB(db *sql.DB) *LDBReader {
 
 // GetLastSequence returns the highest sequence number applied to the DB
 func (reader *LDBReader) GetLastSequence(ctx context.Context) (schema.DMLSequence, error) {
-	ctx = discardContext()
+	ctx, cancel := reader.queryContext(ctx)
+	defer cancel()
 	reader.mu.RLock()
 	defer reader.mu.RUnlock()
-	return ldb.FetchSeqFromLdb(ctx, reader.Db)
+	var seq schema.DMLSequence
+	err := reader.retryBusy(ctx, "", "", func() (err error) {
+		seq, err = ldb.FetchSeqFromLdb(ctx, reader.Db)
+		return err
+	})
+	return seq, observeQueryErr(ctx, err, "", "")
+}
+
+// GetLedgerSequences returns the highest sequence number applied to the DB
+// from each upstream ledger, keyed by ledger ID
+func (reader *LDBReader) GetLedgerSequences(ctx context.Context) (map[int]schema.DMLSequence, error) {
+	ctx, cancel := reader.queryContext(ctx)
+	defer cancel()
+	reader.mu.RLock()
+	defer reader.mu.RUnlock()
+	var seqs map[int]schema.DMLSequence
+	err := reader.retryBusy(ctx, "", "", func() (err error) {
+		seqs, err = ldb.FetchLedgerSeqsFromLdb(ctx, reader.Db)
+		return err
+	})
+	return seqs, observeQueryErr(ctx, err, "", "")
 }
 
 // GetLedgerLatency returns the difference between the current time and the timestamp
 // from the last DML ledger update processed by the reflector. ErrNoLedgerUpdates will
 // be returned if no DML statements have been processed.
 func (reader *LDBReader) GetLedgerLatency(ctx context.Context) (time.Duration, error) {
-	ctx = discardContext()
-	row := reader.Db.QueryRowContext(ctx, "select timestamp from "+ldb.LDBLastUpdateTableName+" where name=?", ldb.LDBLastLedgerUpdateColumn)
+	ctx, cancel := reader.queryContext(ctx)
+	defer cancel()
 	var timestamp time.Time
-	err := row.Scan(&timestamp)
+	err := reader.retryBusy(ctx, "", "", func() error {
+		row := reader.Db.QueryRowContext(ctx, "select timestamp from "+ldb.LDBLastUpdateTableName+" where name=?", ldb.LDBLastLedgerUpdateColumn)
+		return row.Scan(&timestamp)
+	})
 	switch {
 	case err == sql.ErrNoRows:
 		return 0, ErrNoLedgerUpdates
 	case err != nil:
-		return 0, errors.Wrap(err, "get ledger latency")
+		return 0, observeQueryErr(ctx, errors.Wrap(err, "get ledger latency"), "", "")
 	default:
-		return time.Now().Sub(timestamp), nil
+		latency := time.Now().Sub(timestamp)
+		if latency < 0 {
+			// the LDB was written on a host whose clock is ahead of ours
+			latency = 0
+		}
+		return latency, nil
 	}
 }
 
+// TableStats describes one table in the LDB.
+type TableStats struct {
+	Family string
+	Table  string
+	Rows   int64
+}
+
+// GetTableStats returns every table in the LDB along with its row count.
+// Counting rows scans each table, so this is meant for diagnostics rather
+// than for the read path.
+func (reader *LDBReader) GetTableStats(ctx context.Context) (res []TableStats, err error) {
+	ctx, cancel := reader.queryContext(ctx)
+	defer cancel()
+	defer func() { err = observeQueryErr(ctx, err, "", "") }()
+	reader.mu.RLock()
+	defer reader.mu.RUnlock()
+	rows, err := reader.Db.QueryContext(ctx, "SELECT name FROM sqlite_master WHERE type='table' ORDER BY name")
+	if err != nil {
+		return nil, errors.Wrap(err, "query table names")
+	}
+	var tables []schema.FamilyTable
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, errors.Wrap(err, "scan table name")
+		}
+		if ft, ok := schema.ParseFamilyTable(name); ok {
+			tables = append(tables, ft)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "query table names")
+	}
+
+	res = make([]TableStats, 0, len(tables))
+	for _, ft := range tables {
+		qName, err := sqlgen.SQLQuote(ft.String())
+		if err != nil {
+			return nil, err
+		}
+		ts := TableStats{Family: ft.Family, Table: ft.Table}
+		err = reader.Db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+qName).Scan(&ts.Rows)
+		if err != nil {
+			return nil, errors.Wrapf(err, "count rows of %s", ft)
+		}
+		res = append(res, ts)
+	}
+	return res, nil
+}
+
 // GetRowsByKeyPrefix returns a *Rows iterator that will supply all of the rows in
 // the family and table match the supplied primary key prefix.
-func (reader *LDBReader) GetRowsByKeyPrefix(ctx context.Context, familyName string, tableName string, key ...interface{}) (*Rows, error) {
-	ctx = discardContext()
+func (reader *LDBReader) GetRowsByKeyPrefix(ctx context.Context, familyName string, tableName string, key ...interface{}) (res *Rows, err error) {
+	err = reader.retryBusy(ctx, familyName, tableName, func() (err error) {
+		res, err = reader.getRowsByKeyPrefix(ctx, nil, familyName, tableName, key)
+		return err
+	})
+	return res, err
+}
+
+// getRowsByKeyPrefix reads the rows from the snapshot, if it isn't nil
+func (reader *LDBReader) getRowsByKeyPrefix(ctx context.Context, snap *Snapshot, familyName string, tableName string, key []interface{}) (res *Rows, err error) {
+	ctx, cancel := reader.queryContext(ctx)
+	defer func() {
+		if err != nil || res == nil {
+			cancel()
+			err = observeQueryErr(ctx, err, familyName, tableName)
+			return
+		}
+		// the rows are read after we return, so the context lives until
+		// they're closed
+		res.cancel = cancel
+	}()
 	start := time.Now()
 	defer func() {
 		globalstats.Observe("get_rows_by_key_prefix", time.Now().Sub(start),
-			stats.T("family", familyName),
-			stats.T("table", tableName))
+			reader.caller.tags(familyName, tableName)...)
 	}()
 
 	reader.mu.RLock()
@@ -167,6 +270,9 @@ func (reader *LDBReader) GetRowsByKeyPrefix(ctx context.Context, familyName stri
 	}
 	ldbTable := schema.LDBTableName(famName, tblName)
 	pk, err := reader.getPrimaryKey(ctx, ldbTable)
+	if err == ErrTableNotFound && reader.fallback != nil && snap == nil {
+		return reader.fallback.getRowsByKeyPrefix(ctx, familyName, tableName, key)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -180,12 +286,17 @@ func (reader *LDBReader) GetRowsByKeyPrefix(ctx context.Context, familyName stri
 	if err != nil {
 		return nil, err
 	}
-	stmt, err := reader.getRowsByKeyPrefixStmt(ctx, pk, ldbTable, len(key))
+	var stmt *sql.Stmt
+	if snap != nil {
+		stmt, err = snap.prepare(ctx, rowsByKeyPrefixQuery(pk, ldbTable, len(key)))
+	} else {
+		stmt, err = reader.getRowsByKeyPrefixStmt(ctx, pk, ldbTable, len(key))
+	}
 	if err != nil {
 		return nil, err
 	}
 	if len(key) == 0 {
-		globalstats.Incr("full-table-scans", familyName, tableName)
+		reader.caller.incr("full-table-scans", familyName, tableName)
 	}
 	rows, err := stmt.QueryContext(ctx, key...)
 	switch {
@@ -194,8 +305,7 @@ func (reader *LDBReader) GetRowsByKeyPrefix(ctx context.Context, familyName stri
 		if err != nil {
 			return nil, err
 		}
-		res := &Rows{rows: rows, cols: cols}
-		return res, nil
+		return &Rows{rows: rows, cols: cols, limiter: newResultLimiter(reader.resultLimits, familyName, tableName, len(cols))}, nil
 	case err == sql.ErrNoRows:
 		return &Rows{}, nil
 	default:
@@ -218,12 +328,29 @@ func (reader *LDBReader) GetRowByKey(
 	tableName string,
 	key ...interface{},
 ) (found bool, err error) {
-	ctx = discardContext()
+	err = reader.retryBusy(ctx, familyName, tableName, func() (err error) {
+		found, err = reader.getRowByKey(ctx, nil, out, familyName, tableName, key)
+		return err
+	})
+	return found, err
+}
+
+// getRowByKey reads the row from the snapshot, if it isn't nil
+func (reader *LDBReader) getRowByKey(
+	ctx context.Context,
+	snap *Snapshot,
+	out interface{},
+	familyName string,
+	tableName string,
+	key []interface{},
+) (found bool, err error) {
+	ctx, cancel := reader.queryContext(ctx)
+	defer cancel()
+	defer func() { err = observeQueryErr(ctx, err, familyName, tableName) }()
 	start := time.Now()
 	defer func() {
 		globalstats.Observe("get_row_by_key", time.Now().Sub(start),
-			stats.T("family", familyName),
-			stats.T("table", tableName))
+			reader.caller.tags(familyName, tableName)...)
 	}()
 
 	reader.mu.RLock()
@@ -250,6 +377,9 @@ func (reader *LDBReader) GetRowByKey(
 	// go stale. The way that this is dealt with is to clear the cache if
 	// the statement encounters any execution errors.
 	pk, err := reader.getPrimaryKey(ctx, ldbTable) // assumes RLock held
+	if err == ErrTableNotFound && reader.fallback != nil && snap == nil {
+		return reader.fallback.getRowByKey(ctx, out, familyName, tableName, key)
+	}
 	if err != nil {
 		return
 	}
@@ -266,7 +396,12 @@ func (reader *LDBReader) GetRowByKey(
 
 	// Stmt & PK cache are separate now to give the option to gracefully
 	// move back.
-	stmt, err := reader.getGetRowByKeyStmt(ctx, pk, ldbTable) // assumes RLock held
+	var stmt *sql.Stmt
+	if snap != nil {
+		stmt, err = snap.prepare(ctx, rowByKeyQuery(pk, ldbTable))
+	} else {
+		stmt, err = reader.getGetRowByKeyStmt(ctx, pk, ldbTable) // assumes RLock held
+	}
 	if err != nil {
 		return
 	}
@@ -319,6 +454,154 @@ func (reader *LDBReader) GetRowByKey(
 	return
 }
 
+// GetRowCountByKeyPrefix returns the number of rows in the family and table
+// which match the supplied primary key prefix, without reading the rows.
+// Supplying no key counts every row in the table.
+func (reader *LDBReader) GetRowCountByKeyPrefix(ctx context.Context, familyName string, tableName string, key ...interface{}) (count int64, err error) {
+	err = reader.retryBusy(ctx, familyName, tableName, func() (err error) {
+		count, err = reader.getRowCountByKeyPrefix(ctx, nil, familyName, tableName, key)
+		return err
+	})
+	return count, err
+}
+
+// getRowCountByKeyPrefix counts the rows in the snapshot, if it isn't nil
+func (reader *LDBReader) getRowCountByKeyPrefix(ctx context.Context, snap *Snapshot, familyName string, tableName string, key []interface{}) (count int64, err error) {
+	ctx, cancel := reader.queryContext(ctx)
+	defer cancel()
+	defer func() { err = observeQueryErr(ctx, err, familyName, tableName) }()
+	start := time.Now()
+	defer func() {
+		globalstats.Observe("get_row_count_by_key_prefix", time.Now().Sub(start),
+			reader.caller.tags(familyName, tableName)...)
+	}()
+
+	reader.mu.RLock()
+	defer reader.mu.RUnlock()
+	ldbTable, pk, err := reader.keyedTable(ctx, familyName, tableName)
+	if err == ErrTableNotFound && reader.fallback != nil && snap == nil {
+		// the sidecar can't count rows, so they're read and counted here
+		rows, err := reader.fallback.getRowsByKeyPrefix(ctx, familyName, tableName, key)
+		if err != nil {
+			return 0, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			count++
+		}
+		return count, rows.Err()
+	}
+	if err != nil {
+		return 0, err
+	}
+	if len(key) > len(pk.Fields) {
+		return 0, errors.New("too many keys supplied for table's primary key")
+	}
+	if err = convertKeyBeforeQuery(pk, key); err != nil {
+		return 0, err
+	}
+	query := keyPrefixQuery("COUNT(*)", pk, ldbTable, len(key))
+	var stmt *sql.Stmt
+	if snap != nil {
+		stmt, err = snap.prepare(ctx, query)
+	} else {
+		stmt, err = reader.getQueryStmt(ctx, query) // assumes RLock held
+	}
+	if err != nil {
+		return 0, err
+	}
+	if len(key) == 0 {
+		reader.caller.incr("full-table-scans", familyName, tableName)
+	}
+	if err = stmt.QueryRowContext(ctx, key...).Scan(&count); err != nil {
+		reader.invalidatePKCache(ldbTable) // assumes RLock is held
+		return 0, errors.Wrap(err, "count rows error")
+	}
+	return count, nil
+}
+
+// RowExists returns whether the supplied table has a row with the key,
+// without reading the row. As with GetRowByKey, the full primary key is
+// required.
+func (reader *LDBReader) RowExists(ctx context.Context, familyName string, tableName string, key ...interface{}) (exists bool, err error) {
+	err = reader.retryBusy(ctx, familyName, tableName, func() (err error) {
+		exists, err = reader.rowExists(ctx, nil, familyName, tableName, key)
+		return err
+	})
+	return exists, err
+}
+
+// rowExists checks for the row in the snapshot, if it isn't nil
+func (reader *LDBReader) rowExists(ctx context.Context, snap *Snapshot, familyName string, tableName string, key []interface{}) (exists bool, err error) {
+	ctx, cancel := reader.queryContext(ctx)
+	defer cancel()
+	defer func() { err = observeQueryErr(ctx, err, familyName, tableName) }()
+	start := time.Now()
+	defer func() {
+		globalstats.Observe("row_exists", time.Now().Sub(start),
+			reader.caller.tags(familyName, tableName)...)
+	}()
+
+	reader.mu.RLock()
+	defer reader.mu.RUnlock()
+	ldbTable, pk, err := reader.keyedTable(ctx, familyName, tableName)
+	if err == ErrTableNotFound && reader.fallback != nil && snap == nil {
+		return reader.fallback.getRowByKey(ctx, map[string]interface{}{}, familyName, tableName, key)
+	}
+	if err != nil {
+		return false, err
+	}
+	if len(pk.Fields) != len(key) {
+		return false, ErrNeedFullKey
+	}
+	if err = convertKeyBeforeQuery(pk, key); err != nil {
+		return false, err
+	}
+	query := keyPrefixQuery("1", pk, ldbTable, len(key)) + " LIMIT 1"
+	var stmt *sql.Stmt
+	if snap != nil {
+		stmt, err = snap.prepare(ctx, query)
+	} else {
+		stmt, err = reader.getQueryStmt(ctx, query) // assumes RLock held
+	}
+	if err != nil {
+		return false, err
+	}
+	var one int
+	err = stmt.QueryRowContext(ctx, key...).Scan(&one)
+	switch {
+	case err == nil:
+		return true, nil
+	case err == sql.ErrNoRows:
+		return false, nil
+	default:
+		reader.invalidatePKCache(ldbTable) // assumes RLock is held
+		return false, errors.Wrap(err, "query target row error")
+	}
+}
+
+// keyedTable validates the family and table names, and returns the table's
+// LDB name and primary key. It assumes the RLock is held.
+func (reader *LDBReader) keyedTable(ctx context.Context, familyName string, tableName string) (string, schema.PrimaryKey, error) {
+	famName, err := schema.NewFamilyName(familyName)
+	if err != nil {
+		return "", schema.PrimaryKey{}, err
+	}
+	tblName, err := schema.NewTableName(tableName)
+	if err != nil {
+		return "", schema.PrimaryKey{}, err
+	}
+	ldbTable := schema.LDBTableName(famName, tblName)
+	pk, err := reader.getPrimaryKey(ctx, ldbTable)
+	if err != nil {
+		return ldbTable, pk, err
+	}
+	if pk.Zero() {
+		return ldbTable, pk, ErrTableHasNoPrimaryKey
+	}
+	return ldbTable, pk, nil
+}
+
 func (reader *LDBReader) Close() error {
 	reader.mu.Lock()
 	defer reader.mu.Unlock()
@@ -326,6 +609,7 @@ func (reader *LDBReader) Close() error {
 	if reader.cancelWatcher != nil {
 		reader.cancelWatcher()
 	}
+	reader.watch.close()
 
 	return reader.closeDB()
 }
@@ -345,6 +629,12 @@ func (reader *LDBReader) closeDB() error {
 		}
 	}
 	reader.getRowsByKeyPrefixStmtCache = map[prefixCacheKey]*sql.Stmt{}
+	for _, stmt := range reader.queryStmtCache {
+		if err := stmt.Close(); err != nil {
+			return err
+		}
+	}
+	reader.queryStmtCache = map[string]*sql.Stmt{}
 
 	if reader.Db != nil {
 		return reader.Db.Close()
@@ -354,7 +644,8 @@ func (reader *LDBReader) closeDB() error {
 
 // Ping checks if the LDB is available
 func (reader *LDBReader) Ping(ctx context.Context) bool {
-	ctx = discardContext()
+	ctx, cancel := reader.queryContext(ctx)
+	defer cancel()
 	reader.mu.RLock()
 	defer reader.mu.RUnlock()
 
@@ -467,7 +758,7 @@ func (reader *LDBReader) getPrimaryKey(ctx context.Context, ldbTable string) (sc
 			_, err := reader.Db.ExecContext(ctx, qs)
 			if err != nil {
 				if strings.Index(err.Error(), "no such table:") == 0 {
-					return schema.PrimaryKeyZero, errors.New("Table not found")
+					return schema.PrimaryKeyZero, ErrTableNotFound
 				}
 				return schema.PrimaryKeyZero, err
 			}
@@ -514,8 +805,22 @@ func (reader *LDBReader) getRowsByKeyPrefixStmt(ctx context.Context, pk schema.P
 	reader.mu.Lock()
 	defer reader.mu.Unlock()
 
+	stmt, err := reader.Db.PrepareContext(ctx, rowsByKeyPrefixQuery(pk, ldbTable, numKeys))
+	if err == nil {
+		reader.getRowsByKeyPrefixStmtCache[pck] = stmt
+	}
+	return stmt, err
+}
+
+func rowsByKeyPrefixQuery(pk schema.PrimaryKey, ldbTable string, numKeys int) string {
+	return keyPrefixQuery("*", pk, ldbTable, numKeys)
+}
+
+// keyPrefixQuery selects the expression from the rows of the table which
+// match the first numKeys fields of the primary key
+func keyPrefixQuery(selectExpr string, pk schema.PrimaryKey, ldbTable string, numKeys int) string {
 	qsTokens := []string{
-		"SELECT * FROM",
+		"SELECT " + selectExpr + " FROM",
 		ldbTable,
 	}
 	if numKeys > 0 {
@@ -531,12 +836,7 @@ func (reader *LDBReader) getRowsByKeyPrefixStmt(ctx context.Context, pk schema.P
 				"?")
 		}
 	}
-	qs := strings.Join(qsTokens, " ")
-	stmt, err := reader.Db.PrepareContext(ctx, qs)
-	if err == nil {
-		reader.getRowsByKeyPrefixStmtCache[pck] = stmt
-	}
-	return stmt, err
+	return strings.Join(qsTokens, " ")
 }
 
 func (reader *LDBReader) getGetRowByKeyStmt(ctx context.Context, pk schema.PrimaryKey, ldbTable string) (*sql.Stmt, error) {
@@ -565,6 +865,50 @@ func (reader *LDBReader) getGetRowByKeyStmt(ctx context.Context, pk schema.Prima
 	reader.mu.Lock()
 	defer reader.mu.Unlock()
 
+	stmt, err := reader.Db.PrepareContext(ctx, rowByKeyQuery(pk, ldbTable))
+	if err == nil {
+		reader.getRowByKeyStmtCache[ldbTable] = stmt
+	}
+
+	return stmt, err
+}
+
+// getQueryStmt returns a cached statement for the query
+func (reader *LDBReader) getQueryStmt(ctx context.Context, query string) (*sql.Stmt, error) {
+	// assumes RLock is held
+	if reader.queryStmtCache == nil {
+		reader.mu.RUnlock()
+		reader.mu.Lock()
+		// double check because there could be a race which would result
+		// in us wiping out the cache
+		if reader.queryStmtCache == nil {
+			reader.queryStmtCache = make(map[string]*sql.Stmt)
+		}
+		reader.mu.Unlock()
+		reader.mu.RLock()
+	}
+	stmt, found := reader.queryStmtCache[query]
+	if found {
+		return stmt, nil
+	}
+
+	reader.mu.RUnlock()
+	defer reader.mu.RLock()
+	reader.mu.Lock()
+	defer reader.mu.Unlock()
+
+	// another reader may have prepared it while we waited for the lock
+	if stmt, found := reader.queryStmtCache[query]; found {
+		return stmt, nil
+	}
+	stmt, err := reader.Db.PrepareContext(ctx, query)
+	if err == nil {
+		reader.queryStmtCache[query] = stmt
+	}
+	return stmt, err
+}
+
+func rowByKeyQuery(pk schema.PrimaryKey, ldbTable string) string {
 	qsTokens := []string{
 		"SELECT * FROM",
 		ldbTable,
@@ -581,13 +925,7 @@ func (reader *LDBReader) getGetRowByKeyStmt(ctx context.Context, pk schema.Prima
 			"?")
 	}
 
-	qs := strings.Join(qsTokens, " ")
-	stmt, err := reader.Db.PrepareContext(ctx, qs)
-	if err == nil {
-		reader.getRowByKeyStmtCache[ldbTable] = stmt
-	}
-
-	return stmt, err
+	return strings.Join(qsTokens, " ")
 }
 
 func (reader *LDBReader) watchForLDBs(ctx context.Context, dirPath string, last int64) {
@@ -621,10 +959,45 @@ func (reader *LDBReader) watchForLDBs(ctx context.Context, dirPath string, last
 	}
 }
 
+// watchForRebuiltLDB switches the reader to the LDB that the one configured
+// at path was rebuilt as, each time the reflector rebuilds it.
+func (reader *LDBReader) watchForRebuiltLDB(ctx context.Context, path string, current string) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rebuilt, err := ldb.CurrentPath(path)
+			if err != nil {
+				events.Log("failed checking for rebuilt LDB: %{error}+v", err)
+				errs.Incr("check-rebuilt-ldb")
+				continue
+			}
+			if rebuilt == current {
+				continue
+			}
+			events.Log("LDB %{path}s was rebuilt as %{rebuilt}s, switching...", path, rebuilt)
+
+			if err := reader.switchLDBPath(rebuilt); err != nil {
+				events.Log("failed switching to rebuilt LDB: %{error}+v", err)
+				errs.Incr("switch-ldb")
+				continue
+			}
+			current = rebuilt
+		}
+	}
+}
+
 func (reader *LDBReader) switchLDB(dirPath string, timestamp int64) error {
-	fullPath := filepath.Join(dirPath, fmt.Sprintf("%013d", timestamp), ldb.DefaultLDBFilename)
+	return reader.switchLDBPath(filepath.Join(dirPath, fmt.Sprintf("%013d", timestamp), ldb.DefaultLDBFilename))
+}
 
-	db, err := newLDB(fullPath)
+// switchLDBPath replaces the reader's DB with the LDB at fullPath.
+func (reader *LDBReader) switchLDBPath(fullPath string) error {
+	db, err := reader.openLDB(fullPath)
 	if err != nil {
 		return errors.Wrap(err, "new ldb")
 	}
@@ -705,7 +1078,8 @@ func lookupLastLDBSync(dirPath string) (int64, error) {
 // value instead. This is done because the underlying sqlite CGO code that
 // the reader API ultimately calls does not handle interruptions optimally. Additionally
 // because the calls read from disk instead of making network calls, context cancellation is
-// arguably less important to begin with.
+// arguably less important to begin with. Readers configured with WithQueryTimeout
+// honor the context instead.
 func discardContext() context.Context {
 	return context.Background()
 }
@@ -24,6 +24,12 @@ type testKVStruct struct {
 	Val string `ctlstore:"value"`
 }
 
+type testTypedStruct struct {
+	Key     string    `ctlstore:"key"`
+	At      time.Time `ctlstore:"at"`
+	Enabled bool      `ctlstore:"enabled"`
+}
+
 func benchmarkGetRowByKey(b *testing.B, target interface{}) {
 	ctx := context.Background()
 	db, teardown := ldb.LDBForTest(b)
@@ -250,6 +256,40 @@ func TestGetRowsByKeyPrefix(t *testing.T) {
 
 }
 
+func TestRowsColumnsAndRawScan(t *testing.T) {
+	ctx := context.Background()
+	db, teardown := ldb.LDBForTest(t)
+	defer teardown()
+
+	_, err := db.Exec(initSQLForReadKeyByRow)
+	require.NoError(t, err)
+	reader := LDBReader{Db: db}
+	rows, err := reader.GetRowsByKeyPrefix(ctx, "foo", "multirow", "a")
+	require.NoError(t, err)
+	defer rows.Close()
+
+	require.Equal(t, []ColumnInfo{
+		{Name: "k1", DatabaseType: "varchar", FieldType: schema.FTString},
+		{Name: "k2", DatabaseType: "varchar", FieldType: schema.FTString},
+		{Name: "val", DatabaseType: "INT"},
+	}, rows.Columns())
+
+	var got [][]interface{}
+	for rows.Next() {
+		values, err := rows.RawScan()
+		require.NoError(t, err)
+		got = append(got, values)
+	}
+	require.NoError(t, rows.Err())
+	require.Equal(t, [][]interface{}{
+		{"a", "A", int64(42)},
+		{"a", "B", int64(43)},
+	}, got)
+
+	_, err = (&Rows{}).RawScan()
+	require.Equal(t, sql.ErrNoRows, err)
+}
+
 func TestGetRowByKey(t *testing.T) {
 	suite := []struct {
 		desc        string
@@ -361,6 +401,26 @@ func TestGetRowByKey(t *testing.T) {
 			expectFound: true,
 			expectErr:   nil,
 		},
+		{
+			desc:        "map timestamp and boolean",
+			familyName:  "foo",
+			tableName:   "typed",
+			key:         []string{"foo"},
+			gotOut:      map[string]interface{}{},
+			expectOut:   map[string]interface{}{"key": "foo", "at": time.Date(2020, 1, 2, 3, 4, 5, 500000000, time.UTC), "enabled": true},
+			expectFound: true,
+			expectErr:   nil,
+		},
+		{
+			desc:        "struct timestamp and boolean",
+			familyName:  "foo",
+			tableName:   "typed",
+			key:         []string{"foo"},
+			gotOut:      &testTypedStruct{},
+			expectOut:   &testTypedStruct{"foo", time.Date(2020, 1, 2, 3, 4, 5, 500000000, time.UTC), true},
+			expectFound: true,
+			expectErr:   nil,
+		},
 	}
 
 	for _, testCase := range suite {
@@ -597,4 +657,310 @@ const initSQLForReadKeyByRow = `
 		INSERT INTO foo___multirow (k1,k2,val) VALUES ('a', 'A', 42);
 		INSERT INTO foo___multirow (k1,k2,val) VALUES ('a', 'B', 43);
 		INSERT INTO foo___multirow (k1,k2,val) VALUES ('b', 'B', 44);
+
+		CREATE TABLE foo___typed (
+			key VARCHAR PRIMARY KEY,
+			at DATETIME,
+			enabled BOOLEAN
+		);
+		INSERT INTO foo___typed VALUES ('foo', '2020-01-02 03:04:05.5', 1);
 `
+
+func TestConsistencyToken(t *testing.T) {
+	ctx := context.Background()
+	tu, teardown := NewLDBTestUtil(t)
+	defer teardown()
+	reader := NewLDBReaderFromDB(tu.DB)
+
+	setSeq := func(seq int64) {
+		_, err := tu.DB.Exec(
+			fmt.Sprintf("REPLACE INTO %s (id, seq) VALUES(?, ?)", ldb.LDBSeqTableName),
+			ldb.LDBSeqTableID, seq)
+		require.NoError(t, err)
+	}
+	setSeq(5)
+
+	token, err := reader.ConsistencyToken(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, token.LDB)
+	require.EqualValues(t, 5, token.Sequence)
+
+	parsed, err := ParseConsistencyToken(token.String())
+	require.NoError(t, err)
+	require.Equal(t, token, parsed)
+	_, err = ParseConsistencyToken("not a token")
+	require.Equal(t, ErrInvalidConsistencyToken, err)
+
+	// re-initializing keeps the identity
+	require.NoError(t, ldb.EnsureLdbInitialized(ctx, tu.DB))
+	again, err := reader.ConsistencyToken(ctx)
+	require.NoError(t, err)
+	require.Equal(t, token, again)
+
+	require.NoError(t, reader.WaitForConsistency(ctx, token))
+
+	ahead := ConsistencyToken{LDB: token.LDB, Sequence: 6}
+	shortCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	require.Equal(t, ErrConsistencyNotReached, reader.WaitForConsistency(shortCtx, ahead))
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		setSeq(6)
+	}()
+	require.NoError(t, reader.WaitForConsistency(ctx, ahead))
+
+	other := ConsistencyToken{LDB: "other", Sequence: 1}
+	require.Equal(t, ErrConsistencyTokenMismatch, reader.WaitForConsistency(ctx, other))
+	// tokens from LDBs without an identity are comparable with any LDB
+	require.NoError(t, reader.WaitForConsistency(ctx, ConsistencyToken{Sequence: 1}))
+}
+
+func TestGetTableStats(t *testing.T) {
+	ctx := context.Background()
+	tu, teardown := NewLDBTestUtil(t)
+	defer teardown()
+	tu.CreateTable(LDBTestTableDef{
+		Family:    "family1",
+		Name:      "table1",
+		Fields:    [][]string{{"key", "string"}},
+		KeyFields: []string{"key"},
+		Rows:      [][]interface{}{{"key-1"}, {"key-2"}},
+	})
+	tu.CreateTable(LDBTestTableDef{
+		Family:    "family2",
+		Name:      "table2",
+		Fields:    [][]string{{"key", "integer"}},
+		KeyFields: []string{"key"},
+	})
+
+	reader := NewLDBReaderFromDB(tu.DB)
+	tables, err := reader.GetTableStats(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []TableStats{
+		{Family: "family1", Table: "table1", Rows: 2},
+		{Family: "family2", Table: "table2", Rows: 0},
+	}, tables)
+}
+
+func TestSnapshot(t *testing.T) {
+	ctx := context.Background()
+	db, teardown, _ := ldb.LDBForTestWithPath(t)
+	defer teardown()
+	_, err := db.Exec(initSQLForReadKeyByRow)
+	require.NoError(t, err)
+	_, err = db.Exec(
+		fmt.Sprintf("REPLACE INTO %s (id, seq) VALUES(?, ?)", ldb.LDBSeqTableName),
+		ldb.LDBSeqTableID, 5)
+	require.NoError(t, err)
+	reader := NewLDBReaderFromDB(db)
+
+	snap, err := reader.BeginSnapshot(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 5, snap.Sequence())
+
+	// changes applied after the snapshot began aren't visible through it
+	_, err = db.Exec("UPDATE foo___bar SET value = 'baz' WHERE key = 'foo'")
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO foo___multirow (k1,k2,val) VALUES ('a', 'C', 45)")
+	require.NoError(t, err)
+
+	var out testKVStruct
+	found, err := snap.GetRowByKey(ctx, &out, "foo", "bar", "foo")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, "bar", out.Val)
+	found, err = reader.GetRowByKey(ctx, &out, "foo", "bar", "foo")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, "baz", out.Val)
+
+	countRows := func(rows *Rows, err error) int {
+		require.NoError(t, err)
+		defer rows.Close()
+		n := 0
+		for rows.Next() {
+			n++
+		}
+		require.NoError(t, rows.Err())
+		return n
+	}
+	require.Equal(t, 2, countRows(snap.GetRowsByKeyPrefix(ctx, "foo", "multirow", "a")))
+	require.Equal(t, 3, countRows(reader.GetRowsByKeyPrefix(ctx, "foo", "multirow", "a")))
+	count, err := snap.GetRowCountByKeyPrefix(ctx, "foo", "multirow", "a")
+	require.NoError(t, err)
+	require.EqualValues(t, 2, count)
+	exists, err := snap.RowExists(ctx, "foo", "multirow", "a", "C")
+	require.NoError(t, err)
+	require.False(t, exists)
+	exists, err = reader.RowExists(ctx, "foo", "multirow", "a", "C")
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	require.NoError(t, snap.Release())
+	require.NoError(t, snap.Release())
+	_, err = snap.GetRowByKey(ctx, &out, "foo", "bar", "foo")
+	require.Equal(t, ErrSnapshotReleased, errors.Cause(err))
+	_, err = snap.GetRowsByKeyPrefix(ctx, "foo", "multirow", "a")
+	require.Equal(t, ErrSnapshotReleased, errors.Cause(err))
+}
+
+func TestGetRowCountByKeyPrefix(t *testing.T) {
+	ctx := context.Background()
+	db, teardown := ldb.LDBForTest(t)
+	defer teardown()
+	_, err := db.Exec(initSQLForReadKeyByRow)
+	require.NoError(t, err)
+	reader := NewLDBReaderFromDB(db)
+
+	for _, test := range []struct {
+		desc  string
+		table string
+		key   []interface{}
+		count int64
+		err   error
+	}{
+		{desc: "whole table", table: "multirow", count: 3},
+		{desc: "key prefix", table: "multirow", key: []interface{}{"a"}, count: 2},
+		{desc: "full key", table: "multirow", key: []interface{}{"a", "B"}, count: 1},
+		{desc: "no match", table: "multirow", key: []interface{}{"c"}, count: 0},
+		{desc: "too many keys", table: "bar", key: []interface{}{"foo", "bar"}, err: errors.New("too many keys supplied for table's primary key")},
+		{desc: "missing table", table: "missing", err: ErrTableNotFound},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			count, err := reader.GetRowCountByKeyPrefix(ctx, "foo", test.table, test.key...)
+			if test.err != nil {
+				require.EqualError(t, errors.Cause(err), test.err.Error())
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.count, count)
+		})
+	}
+}
+
+func TestRowExists(t *testing.T) {
+	ctx := context.Background()
+	db, teardown := ldb.LDBForTest(t)
+	defer teardown()
+	_, err := db.Exec(initSQLForReadKeyByRow)
+	require.NoError(t, err)
+	reader := NewLDBReaderFromDB(db)
+
+	exists, err := reader.RowExists(ctx, "foo", "bar", "foo")
+	require.NoError(t, err)
+	require.True(t, exists)
+	exists, err = reader.RowExists(ctx, "foo", "bar", "nope")
+	require.NoError(t, err)
+	require.False(t, exists)
+	exists, err = reader.RowExists(ctx, "foo", "multirow", "b", "B")
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	_, err = reader.RowExists(ctx, "foo", "multirow", "a")
+	require.Equal(t, ErrNeedFullKey, errors.Cause(err))
+	_, err = reader.RowExists(ctx, "foo", "missing", "a")
+	require.Equal(t, ErrTableNotFound, errors.Cause(err))
+}
+
+func TestGetRowsByKeys(t *testing.T) {
+	ctx := context.Background()
+	db, teardown := ldb.LDBForTest(t)
+	defer teardown()
+	_, err := db.Exec(initSQLForReadKeyByRow)
+	require.NoError(t, err)
+	reader := NewLDBReaderFromDB(db)
+
+	rows, err := reader.GetRowsByKeys(ctx, "foo", "multirow", [][]interface{}{
+		{"a", "A"}, {"b", "B"}, {"a", "A"}, {"c", "C"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, rows.Len())
+	row, found := rows.Get("a", "A")
+	require.True(t, found)
+	require.Equal(t, map[string]interface{}{"k1": "a", "k2": "A", "val": int64(42)}, row)
+	_, found = rows.Get("a", "B")
+	require.False(t, found)
+	_, found = rows.Get("c", "C")
+	require.False(t, found)
+
+	// string keys of binary fields are converted, as with GetRowByKey
+	rows, err = reader.GetRowsByKeys(ctx, "foo", "varbinarykey", [][]interface{}{{"beef"}})
+	require.NoError(t, err)
+	row, found = rows.Get("beef")
+	require.True(t, found)
+	require.Equal(t, "moo", row["value"])
+
+	// large sets of keys are read in chunks
+	_, err = db.Exec("CREATE TABLE foo___ints (id INTEGER PRIMARY KEY, value VARCHAR);" +
+		"INSERT INTO foo___ints WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x+1 FROM c LIMIT 2000) " +
+		"SELECT x, 'v' || x FROM c")
+	require.NoError(t, err)
+	keys := [][]interface{}{}
+	for i := 0; i < 2500; i++ {
+		keys = append(keys, []interface{}{i})
+	}
+	rows, err = reader.GetRowsByKeys(ctx, "foo", "ints", keys)
+	require.NoError(t, err)
+	require.Equal(t, 2000, rows.Len())
+	row, found = rows.Get(int64(1999))
+	require.True(t, found)
+	require.Equal(t, "v1999", row["value"])
+
+	_, err = reader.GetRowsByKeys(ctx, "foo", "multirow", [][]interface{}{{"a"}})
+	require.Equal(t, ErrNeedFullKey, errors.Cause(err))
+	_, err = reader.GetRowsByKeys(ctx, "foo", "missing", [][]interface{}{{"a"}})
+	require.Equal(t, ErrTableNotFound, errors.Cause(err))
+}
+
+func TestQueryRowsByKeyPrefix(t *testing.T) {
+	ctx := context.Background()
+	db, teardown := ldb.LDBForTest(t)
+	defer teardown()
+	_, err := db.Exec(initSQLForReadKeyByRow)
+	require.NoError(t, err)
+	reader := NewLDBReaderFromDB(db)
+
+	read := func(query PrefixQuery, key ...interface{}) ([]map[string]interface{}, error) {
+		rows, err := reader.QueryRowsByKeyPrefix(ctx, "foo", "multirow", query, key...)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		res := []map[string]interface{}{}
+		for rows.Next() {
+			row := map[string]interface{}{}
+			if err := rows.Scan(row); err != nil {
+				return nil, err
+			}
+			res = append(res, row)
+		}
+		return res, rows.Err()
+	}
+
+	res, err := read(PrefixQuery{Fields: []string{"k2", "VAL"}}, "a")
+	require.NoError(t, err)
+	require.Equal(t, []map[string]interface{}{
+		{"k2": "A", "val": int64(42)},
+		{"k2": "B", "val": int64(43)},
+	}, res)
+
+	res, err = read(PrefixQuery{Fields: []string{"k1"}, Where: map[string]interface{}{"val": int64(44)}})
+	require.NoError(t, err)
+	require.Equal(t, []map[string]interface{}{{"k1": "b"}}, res)
+
+	// filter values given as strings are compared numerically, so that they
+	// can be read from query strings
+	res, err = read(PrefixQuery{Where: map[string]interface{}{"val": "43"}}, "a")
+	require.NoError(t, err)
+	require.Equal(t, []map[string]interface{}{{"k1": "a", "k2": "B", "val": int64(43)}}, res)
+
+	res, err = read(PrefixQuery{Where: map[string]interface{}{"val": 43}}, "b")
+	require.NoError(t, err)
+	require.Empty(t, res)
+
+	_, err = read(PrefixQuery{Fields: []string{"nope"}}, "a")
+	require.Equal(t, ErrInvalidQuery, errors.Cause(err))
+	_, err = read(PrefixQuery{Where: map[string]interface{}{"k2": "A"}}, "a")
+	require.Equal(t, ErrInvalidQuery, errors.Cause(err))
+}
@@ -2,6 +2,8 @@ package ctlstore
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"reflect"
 	"testing"
 	"time"
@@ -11,6 +13,7 @@ import (
 	"github.com/segmentio/ctlstore/pkg/schema"
 	"github.com/segmentio/ctlstore/pkg/utils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type testNUMTSBasic struct {
@@ -177,3 +180,201 @@ func TestNewUnmarshalTargetSlice(t *testing.T) {
 		})
 	}
 }
+
+// testScanUUID implements sql.Scanner, which database/sql uses directly
+type testScanUUID [2]byte
+
+func (u *testScanUUID) Scan(src interface{}) error {
+	b, ok := src.([]byte)
+	if !ok || len(b) != len(u) {
+		return fmt.Errorf("invalid uuid %v", src)
+	}
+	copy(u[:], b)
+	return nil
+}
+
+// testScanStatus implements encoding.TextUnmarshaler
+type testScanStatus int
+
+func (s *testScanStatus) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "active", "1":
+		*s = 1
+	case "inactive", "0":
+		*s = 0
+	default:
+		return fmt.Errorf("unknown status %q", text)
+	}
+	return nil
+}
+
+// testScanAttrs implements json.Unmarshaler
+type testScanAttrs struct {
+	Color string
+}
+
+func (a *testScanAttrs) UnmarshalJSON(b []byte) error {
+	var m map[string]string
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	a.Color = m["color"]
+	return nil
+}
+
+type testScanCustom struct {
+	Key       string          `ctlstore:"key"`
+	ID        testScanUUID    `ctlstore:"id"`
+	Status    testScanStatus  `ctlstore:"status"`
+	StatusInt *testScanStatus `ctlstore:"status_int"`
+	Attrs     testScanAttrs   `ctlstore:"attrs"`
+	Missing   *testScanAttrs  `ctlstore:"missing"`
+	Raw       json.RawMessage `ctlstore:"attrs_raw"`
+}
+
+func TestScanFuncStructCustomTypes(t *testing.T) {
+	initSQL := `
+		CREATE TABLE test___scancustom (
+			key VARCHAR PRIMARY KEY,
+			id BLOB,
+			status VARCHAR,
+			status_int INTEGER,
+			attrs TEXT,
+			missing TEXT,
+			attrs_raw TEXT
+		);
+		INSERT INTO test___scancustom VALUES('foo', x'beef', 'active', 0, '{"color":"red"}', NULL, '{"a":1}');
+		INSERT INTO test___scancustom VALUES('bar', x'beef', 'unknown', 0, '{}', NULL, '{}');
+	`
+	ctx := context.Background()
+	db, teardown := ldb.LDBForTest(t)
+	defer teardown()
+
+	_, err := db.Exec(initSQL)
+	require.NoError(t, err)
+	reader := NewLDBReaderFromDB(db)
+
+	out := testScanCustom{Missing: &testScanAttrs{Color: "blue"}}
+	found, err := reader.GetRowByKey(ctx, &out, "test", "scancustom", "foo")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, testScanUUID{0xbe, 0xef}, out.ID)
+	require.Equal(t, testScanStatus(1), out.Status)
+	require.NotNil(t, out.StatusInt)
+	require.Equal(t, testScanStatus(0), *out.StatusInt)
+	require.Equal(t, "red", out.Attrs.Color)
+	require.Nil(t, out.Missing, "NULL resets the field")
+	require.JSONEq(t, `{"a":1}`, string(out.Raw))
+
+	_, err = reader.GetRowByKey(ctx, &out, "test", "scancustom", "bar")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `unknown status "unknown"`)
+}
+
+type testScanEmbeddedBase struct {
+	Key       string
+	CreatedAt string
+	Note      string
+}
+
+func TestScanFuncStructEmbedded(t *testing.T) {
+	initSQL := `
+		CREATE TABLE test___scanembedded (
+			key VARCHAR PRIMARY KEY,
+			created_at VARCHAR,
+			note VARCHAR,
+			updated_by VARCHAR,
+			name VARCHAR,
+			user_id INTEGER,
+			http_status VARCHAR,
+			skipped VARCHAR,
+			val VARCHAR,
+			hidden VARCHAR
+		);
+		INSERT INTO test___scanembedded VALUES('foo', 'yesterday', 'ambiguous', 'admin', 'outer',
+			42, 'ok', 'skipped', 'renamed', 'hidden');
+	`
+	type Audit struct {
+		UpdatedBy string
+		Name      string
+		Note      string
+	}
+	type row struct {
+		testScanEmbeddedBase
+		*Audit
+		UserID     int64
+		HTTPStatus string
+		Name       string
+		Skipped    string `ctlstore:"-"`
+		Renamed    string `ctlstore:"val"`
+		hidden     string
+	}
+
+	ctx := context.Background()
+	db, teardown := ldb.LDBForTest(t)
+	defer teardown()
+	_, err := db.Exec(initSQL)
+	require.NoError(t, err)
+	reader := NewLDBReaderFromDB(db)
+
+	var out row
+	found, err := reader.GetRowByKey(ctx, &out, "test", "scanembedded", "foo")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, row{
+		testScanEmbeddedBase: testScanEmbeddedBase{Key: "foo", CreatedAt: "yesterday"},
+		Audit:                &Audit{UpdatedBy: "admin"},
+		UserID:               42,
+		HTTPStatus:           "ok",
+		Name:                 "outer",
+		Renamed:              "renamed",
+	}, out, "the outer name hides the embedded one, and the note is ambiguous")
+}
+
+func TestScanFuncStructJSON(t *testing.T) {
+	initSQL := `
+		CREATE TABLE test___scanjson (
+			key VARCHAR PRIMARY KEY,
+			doc JSON TEXT,
+			raw JSON TEXT,
+			tags JSON TEXT,
+			missing JSON TEXT
+		);
+		INSERT INTO test___scanjson VALUES('foo', '{"a":1,"b":["x"]}', '{"a":1}', '["x","y"]', NULL);
+		INSERT INTO test___scanjson VALUES('bar', 'not json', NULL, NULL, NULL);
+	`
+	type row struct {
+		Key     string
+		Doc     map[string]interface{}
+		Raw     json.RawMessage
+		Tags    []string
+		Missing map[string]interface{}
+	}
+
+	ctx := context.Background()
+	db, teardown := ldb.LDBForTest(t)
+	defer teardown()
+	_, err := db.Exec(initSQL)
+	require.NoError(t, err)
+	reader := NewLDBReaderFromDB(db)
+
+	out := row{Missing: map[string]interface{}{"stale": true}}
+	found, err := reader.GetRowByKey(ctx, &out, "test", "scanjson", "foo")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, row{
+		Key:  "foo",
+		Doc:  map[string]interface{}{"a": float64(1), "b": []interface{}{"x"}},
+		Raw:  json.RawMessage(`{"a":1}`),
+		Tags: []string{"x", "y"},
+	}, out)
+
+	m := map[string]interface{}{}
+	found, err = reader.GetRowByKey(ctx, m, "test", "scanjson", "foo")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, `{"a":1,"b":["x"]}`, m["doc"], "maps hold the document's text")
+
+	_, err = reader.GetRowByKey(ctx, &row{}, "test", "scanjson", "bar")
+	require.Error(t, err)
+}
@@ -0,0 +1,65 @@
+package ctlstore
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+
+	"github.com/segmentio/ctlstore/pkg/ldb"
+)
+
+// WithImmutable makes the reader open its LDB in SQLite's immutable mode,
+// in which it neither locks the LDB nor checks it for changes, which is
+// cheaper for readers making many queries. Only LDBs which are no longer
+// written may be opened this way, since changes made while it's open, or
+// still in the WAL, aren't seen. Versioned LDBs are always immutable.
+func WithImmutable() ReaderOption {
+	return func(reader *LDBReader) {
+		reader.immutable = true
+	}
+}
+
+// WithSharedCache makes the reader's connections to its LDB share a page
+// cache, which saves memory when many queries are made at once.
+func WithSharedCache() ReaderOption {
+	return func(reader *LDBReader) {
+		reader.sharedCache = true
+	}
+}
+
+// WithMaxOpenConns bounds how many connections the reader opens to its
+// LDB. Zero leaves them unbounded.
+func WithMaxOpenConns(n int) ReaderOption {
+	return func(reader *LDBReader) {
+		reader.maxOpenConns = n
+	}
+}
+
+// openLDB opens the LDB at path as the reader was configured to, which
+// defaults to the global configuration.
+func (reader *LDBReader) openLDB(path string) (*sql.DB, error) {
+	_, err := os.Stat(path)
+	switch {
+	case os.IsNotExist(err):
+		return nil, fmt.Errorf("no LDB found at %s", path)
+	case err != nil:
+		return nil, err
+	}
+
+	o := ldb.OpenOptions{
+		Mode:        "ro",
+		Immutable:   reader.immutable || ldbVersioning,
+		SharedCache: reader.sharedCache,
+	}
+	if !globalLDBReadOnly {
+		o.Mode = "rwc"
+	}
+	db, err := ldb.OpenLDBWithOptions(path, o, reader.ldbPragmas())
+	if err != nil {
+		return nil, err
+	}
+	if reader.maxOpenConns > 0 {
+		db.SetMaxOpenConns(reader.maxOpenConns)
+	}
+	return db, nil
+}
@@ -0,0 +1,41 @@
+package ctlstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/ldb"
+)
+
+func TestOpenOptions(t *testing.T) {
+	ctx := context.Background()
+	db, teardown, path := ldb.LDBForTestWithPath(t)
+	defer teardown()
+	_, err := db.Exec(initSQLForReadKeyByRow)
+	require.NoError(t, err)
+	// immutable readers don't see the WAL
+	_, err = db.Exec("PRAGMA wal_checkpoint(TRUNCATE)")
+	require.NoError(t, err)
+
+	reader, err := ReaderForPath(path, WithImmutable(), WithSharedCache(), WithMaxOpenConns(2))
+	require.NoError(t, err)
+	defer reader.Close()
+	require.Equal(t, 2, reader.Db.Stats().MaxOpenConnections)
+
+	out := map[string]interface{}{}
+	found, err := reader.GetRowByKey(ctx, out, "foo", "multirow", "a", "A")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.EqualValues(t, 42, out["val"])
+
+	_, err = reader.Db.Exec("DELETE FROM foo___multirow")
+	require.Error(t, err, "immutable LDBs are read-only")
+
+	// without options, connections are unbounded
+	reader, err = ReaderForPath(path)
+	require.NoError(t, err)
+	defer reader.Close()
+	require.Equal(t, 0, reader.Db.Stats().MaxOpenConnections)
+}
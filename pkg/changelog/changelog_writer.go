@@ -20,6 +20,19 @@ type (
 		Family string
 		Table  string
 		Key    []interface{}
+		// LedgerSeq is the sequence of the ledger statement which made the
+		// change. Unlike Seq, it's the same across reflector restarts.
+		LedgerSeq int64
+		// LedgerID identifies the ledger of LedgerSeq. Sharded ctldbs have a
+		// ledger each, whose sequences overlap.
+		LedgerID int
+		// TxSeq is the ledger sequence of the transaction's begin statement,
+		// for changes made within a ledger transaction.
+		TxSeq int64
+		// TxEnd marks the last change of a statement, or of a ledger
+		// transaction. Consumers can persist LedgerSeq as their offset once
+		// they've processed it.
+		TxEnd bool
 	}
 )
 
@@ -29,15 +42,23 @@ func NewChangelogEntry(seq int64, family string, table string, key []interface{}
 
 func (w *ChangelogWriter) WriteChange(e ChangelogEntry) error {
 	structure := struct {
-		Seq    int64         `json:"seq"`
-		Family string        `json:"family"`
-		Table  string        `json:"table"`
-		Key    []interface{} `json:"key"`
+		Seq       int64         `json:"seq"`
+		Family    string        `json:"family"`
+		Table     string        `json:"table"`
+		Key       []interface{} `json:"key"`
+		LedgerSeq int64         `json:"ledgerSeq,omitempty"`
+		LedgerID  int           `json:"ledgerId,omitempty"`
+		TxSeq     int64         `json:"txSeq,omitempty"`
+		TxEnd     bool          `json:"txEnd,omitempty"`
 	}{
 		e.Seq,
 		e.Family,
 		e.Table,
 		e.Key,
+		e.LedgerSeq,
+		e.LedgerID,
+		e.TxSeq,
+		e.TxEnd,
 	}
 
 	bytes, err := json.Marshal(structure)
@@ -33,4 +33,17 @@ func TestWriteChange(t *testing.T) {
 	require.NoError(t, err)
 	require.EqualValues(t, 1, len(mock.Lines))
 	require.Equal(t, `{"seq":42,"family":"family1","table":"table1","key":[18014398509481984,"foo"]}`, mock.Lines[0])
+
+	err = clw.WriteChange(ChangelogEntry{
+		Seq:       43,
+		Family:    "family1",
+		Table:     "table1",
+		Key:       []interface{}{1},
+		LedgerSeq: 100,
+		LedgerID:  1,
+		TxSeq:     98,
+		TxEnd:     true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, `{"seq":43,"family":"family1","table":"table1","key":[1],"ledgerSeq":100,"ledgerId":1,"txSeq":98,"txEnd":true}`, mock.Lines[1])
 }
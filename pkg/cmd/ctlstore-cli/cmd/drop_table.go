@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/segmentio/cli"
+)
+
+var cliDropTable = &cli.CommandFunc{
+	Help: "Drop a table",
+	Desc: unindent(fmt.Sprintf(`
+		Drop a table
+
+		This command makes an HTTP request to the executive service
+		to drop a table and all of its rows. The executive must be
+		running with destructive schema changes enabled. Since this
+		cannot be undone, the table name must be typed to confirm
+		unless --yes is given.
+
+		Example:
+
+		%s drop-table --family foo testtable
+	`, filepath.Base(os.Args[0]))),
+	Func: func(ctx context.Context, config struct {
+		flagBase
+		flagExecutive
+		flagFamily
+		flagYes
+	}, args []string) error {
+		if len(args) != 1 {
+			bail("Table required")
+		}
+		executive := config.MustExecutive()
+		familyName := config.MustFamily()
+		tableName := args[0]
+		if !config.Yes {
+			prompt := fmt.Sprintf("This will drop %s.%s and all of its rows.", familyName, tableName)
+			if !confirm(os.Stdin, os.Stdout, prompt, tableName) {
+				bail("Aborted")
+			}
+		}
+		url := executive + "/families/" + familyName + "/tables/" + tableName
+		req, err := http.NewRequest(http.MethodDelete, url, nil)
+		if err != nil {
+			bail("could not create request: %s", err)
+		}
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			bail("could not make request: %s", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			bailResponse(resp, "could not drop table '%s'", tableName)
+		}
+		return nil
+	},
+}
@@ -30,6 +30,23 @@ type flagQuiet struct {
 	Quiet bool `flag:"-q,--quiet"`
 }
 
+type flagOutput struct {
+	Output string `flag:"-o,--output" help:"Output format, table or json" default:"table"`
+}
+
+func (f flagOutput) MustOutput() string {
+	switch f.Output {
+	case "table", "json":
+	default:
+		bail("invalid output %q, expected table or json", f.Output)
+	}
+	return f.Output
+}
+
+type flagYes struct {
+	Yes bool `flag:"-y,--yes" help:"Skip the confirmation prompt"`
+}
+
 type flagExecutive struct {
 	Executive string `flag:"-e,--executive" default:"ctlstore-executive.segment.local"`
 }
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/segmentio/cli"
+)
+
+var cliReadRows = &cli.CommandFunc{
+	Help: "Read rows of a table from the executive",
+	Desc: unindent(fmt.Sprintf(`
+		Read rows of a table from the executive
+
+		This command makes an HTTP request to the executive service
+		to read rows of a table in primary key order. Unlike read-keys,
+		it doesn't need a local LDB. To read the next page, pass the
+		key of the last row printed as a JSON array with --after.
+
+		Example:
+
+		%s read-rows --family foo --limit 10 --after '["name1"]' testtable
+	`, filepath.Base(os.Args[0]))),
+	Func: func(ctx context.Context, config struct {
+		flagBase
+		flagExecutive
+		flagFamily
+		flagOutput
+		Limit int    `flag:"--limit" default:"100"`
+		After string `flag:"--after" default:"-"`
+	}, args []string) error {
+		if len(args) != 1 {
+			bail("Table required")
+		}
+		executive := config.MustExecutive()
+		familyName := config.MustFamily()
+		output := config.MustOutput()
+		tableName := args[0]
+
+		params := url.Values{"limit": {strconv.Itoa(config.Limit)}}
+		if config.After != "" {
+			params.Set("after", config.After)
+		}
+		u := executive + "/families/" + familyName + "/tables/" + tableName + "/rows?" + params.Encode()
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			bail("could not create request: %s", err)
+		}
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			bail("could not make request: %s", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			bailResponse(resp, "could not read rows of '%s'", tableName)
+		}
+		var rows []map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&rows); err != nil {
+			bail("could not decode response: %s", err)
+		}
+		return printRows(os.Stdout, rows, output)
+	},
+}
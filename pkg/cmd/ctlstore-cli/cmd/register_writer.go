@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/segmentio/cli"
+)
+
+var cliRegisterWriter = &cli.CommandFunc{
+	Help: "Register a writer",
+	Desc: unindent(fmt.Sprintf(`
+		Register a writer
+
+		This command makes an HTTP request to the executive service
+		to register a writer with the given secret. Registering an
+		existing writer with the same secret succeeds. The secret is
+		read from stdin if --secret is not given.
+
+		Example:
+
+		%s register-writer --secret s3cret my-writer
+	`, filepath.Base(os.Args[0]))),
+	Func: func(ctx context.Context, config struct {
+		flagBase
+		flagExecutive
+		Secret string `flag:"--secret" default:"-"`
+	}, args []string) error {
+		if len(args) != 1 {
+			bail("Writer required")
+		}
+		executive := config.MustExecutive()
+		writerName := args[0]
+		secret := config.Secret
+		if secret == "" {
+			b, err := ioutil.ReadAll(os.Stdin)
+			if err != nil {
+				bail("could not read secret: %s", err)
+			}
+			secret = strings.TrimSpace(string(b))
+		}
+		if secret == "" {
+			bail("Secret required")
+		}
+		url := executive + "/writers/" + writerName
+		req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(secret))
+		if err != nil {
+			bail("could not create request: %s", err)
+		}
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			bail("could not make request: %s", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			bailResponse(resp, "could not register writer '%s'", writerName)
+		}
+		return nil
+	},
+}
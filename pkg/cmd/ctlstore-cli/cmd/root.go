@@ -11,12 +11,15 @@ func Execute() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 	cli.ExecContext(ctx, cli.CommandSet{
-		"table-limits":  cliTableLimits,
-		"create-table":  cliCreateTable,
-		"create-family": cliCreateFamily,
-		"add-fields":    cliAddFields,
-		"read-keys":     cliReadKeys,
-		"read-seq":      cliReadSeq,
-		"writer-limits": cliWriterLimits,
+		"table-limits":    cliTableLimits,
+		"create-table":    cliCreateTable,
+		"create-family":   cliCreateFamily,
+		"add-fields":      cliAddFields,
+		"read-keys":       cliReadKeys,
+		"read-seq":        cliReadSeq,
+		"writer-limits":   cliWriterLimits,
+		"register-writer": cliRegisterWriter,
+		"read-rows":       cliReadRows,
+		"drop-table":      cliDropTable,
 	})
 }
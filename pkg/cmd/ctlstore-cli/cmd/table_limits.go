@@ -19,6 +19,7 @@ var cliTableLimits = cli.CommandSet{
 		Func: func(ctx context.Context, config struct {
 			flagBase
 			flagExecutive
+			flagOutput
 		}) error {
 			url := config.MustExecutive() + "/limits/tables"
 			req, err := http.NewRequest(http.MethodGet, url, nil)
@@ -37,6 +38,9 @@ var cliTableLimits = cli.CommandSet{
 			if err := json.NewDecoder(resp.Body).Decode(&tsl); err != nil {
 				bail("could not decode response: %s", err)
 			}
+			if config.MustOutput() == "json" {
+				return printJSON(os.Stdout, tsl)
+			}
 			fmt.Printf("warn: %d bytes\n", tsl.Global.WarnSize)
 			fmt.Printf("max : %d bytes\n", tsl.Global.MaxSize)
 			if len(tsl.Tables) == 0 {
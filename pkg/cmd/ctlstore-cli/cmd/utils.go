@@ -1,13 +1,18 @@
 package cmd
 
 import (
+	"bufio"
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
+	"sort"
 	"strings"
+	"text/tabwriter"
 )
 
 // field represents a table field. it has a name and a type.
@@ -60,3 +65,60 @@ func unindent(str string) string {
 	}
 	return out.String()
 }
+
+// confirm asks the user to type expect to go ahead with a destructive
+// operation, and reports whether they did.
+func confirm(in io.Reader, out io.Writer, prompt, expect string) bool {
+	fmt.Fprintf(out, "%s\nType '%s' to continue: ", prompt, expect)
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false
+	}
+	return strings.TrimSpace(line) == expect
+}
+
+// printJSON writes v to out as indented JSON.
+func printJSON(out io.Writer, v interface{}) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// printRows writes rows to out in the requested output format. The table
+// format has a column for every field that appears in any row, sorted by
+// name.
+func printRows(out io.Writer, rows []map[string]interface{}, output string) error {
+	if output == "json" {
+		return printJSON(out, rows)
+	}
+	seen := map[string]bool{}
+	var cols []string
+	for _, row := range rows {
+		for col := range row {
+			if !seen[col] {
+				seen[col] = true
+				cols = append(cols, col)
+			}
+		}
+	}
+	sort.Strings(cols)
+	// no TabIndent, since rows may start with empty cells
+	w := tabwriter.NewWriter(out, 0, 0, 1, ' ', 0)
+	var header, underline []string
+	for _, col := range cols {
+		header = append(header, strings.ToUpper(col))
+		underline = append(underline, strings.Repeat("-", len(col)))
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	fmt.Fprintln(w, strings.Join(underline, "\t"))
+	for _, row := range rows {
+		values := make([]string, len(cols))
+		for i, col := range cols {
+			if v, ok := row[col]; ok && v != nil {
+				values[i] = fmt.Sprint(v)
+			}
+		}
+		fmt.Fprintln(w, strings.Join(values, "\t"))
+	}
+	return w.Flush()
+}
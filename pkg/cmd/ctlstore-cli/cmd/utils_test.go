@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfirm(t *testing.T) {
+	for _, test := range []struct {
+		input  string
+		expect bool
+	}{
+		{"testtable\n", true},
+		{"  testtable  \n", true},
+		{"testtable", true},
+		{"y\n", false},
+		{"", false},
+	} {
+		var out bytes.Buffer
+		require.Equal(t, test.expect, confirm(strings.NewReader(test.input), &out, "Drop it?", "testtable"), "input %q", test.input)
+		require.Equal(t, "Drop it?\nType 'testtable' to continue: ", out.String())
+	}
+}
+
+func TestPrintRows(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"name": "a", "count": 1.0},
+		{"name": "bb", "extra": nil},
+	}
+	var out bytes.Buffer
+	require.NoError(t, printRows(&out, rows, "table"))
+	require.Equal(t, ""+
+		"COUNT EXTRA NAME\n"+
+		"----- ----- ----\n"+
+		"1           a\n"+
+		"            bb\n", out.String())
+
+	out.Reset()
+	require.NoError(t, printRows(&out, rows[:1], "json"))
+	require.JSONEq(t, `[{"name":"a","count":1}]`, out.String())
+}
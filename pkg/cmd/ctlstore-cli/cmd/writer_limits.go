@@ -21,6 +21,7 @@ var cliWriterLimits = &cli.CommandSet{
 		Func: func(ctx context.Context, config struct {
 			flagBase
 			flagExecutive
+			flagOutput
 		}) error {
 			executive := config.MustExecutive()
 			url := executive + "/limits/writers"
@@ -44,6 +45,9 @@ var cliWriterLimits = &cli.CommandSet{
 			if err := json.Unmarshal(b, &wrl); err != nil {
 				bail("could not decode response: %s", err)
 			}
+			if config.MustOutput() == "json" {
+				return printJSON(os.Stdout, wrl)
+			}
 			fmt.Println("default:", wrl.Global)
 			if len(wrl.Writers) == 0 {
 				return nil
@@ -1,22 +1,29 @@
-// This program sends constant load to the executive service.  It is intended to be used in a test
+// This program sends load to the executive service.  It is intended to be used in a test
 // or simulation environment.  The first use of it will be to generate load so that output can be
-// queried using the ctlstore-cli.
+// queried using the ctlstore-cli.  It can also be used to benchmark the executive, by selecting a
+// workload profile and a target rate, and reading the latency and error statistics it prints.
 package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math/rand"
 	"net/http"
+	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/segmentio/conf"
 	"github.com/segmentio/ctlstore/pkg/utils"
 	"github.com/segmentio/errors-go"
+	"github.com/segmentio/events/v2"
 )
 
 type config struct {
@@ -24,13 +31,36 @@ type config struct {
 	WriterName        string `conf:"writer-name"`
 	WriterSecret      string `conf:"writer-secret"`
 	FamilyName        string `conf:"family-name"`
-	TableName         string `conf:"table-name"`
+	TableName         string `conf:"table-name" help:"The table to write to, or the prefix of the tables if there is more than one"`
+
+	Profile        string  `conf:"profile" help:"The workload profile to send: default, small, large or churn"`
+	RowsPerRequest int     `conf:"rows-per-request" help:"Overrides the number of rows written by each request"`
+	KeySpace       int     `conf:"key-space" help:"Overrides the number of distinct keys written to in each table"`
+	ValueSize      int     `conf:"value-size" help:"Overrides the size in bytes of each written value"`
+	DeleteRatio    float64 `conf:"delete-ratio" help:"Overrides the fraction of rows deleted rather than upserted"`
+	TableCount     int     `conf:"table-count" help:"Overrides the number of tables written to"`
+
+	Concurrency    int           `conf:"concurrency" help:"How many requests to have in flight at once"`
+	Rate           float64       `conf:"rate" help:"The target rows/sec across all requests, or 0 to send as fast as possible"`
+	RampUp         time.Duration `conf:"ramp-up" help:"How long to take to increase to the target rate"`
+	Duration       time.Duration `conf:"duration" help:"How long to send mutations for, or 0 to send them until interrupted"`
+	ReportInterval time.Duration `conf:"report-interval" help:"How often to print statistics, or 0 to only print them on exit"`
 }
 
-const (
-	// how much to sleep between mutations
-	sleepTime = 1 * time.Second
-)
+// unset is the default of the workload overrides, so that the profile's
+// value is used unless one is passed
+const unset = -1
+
+type mutation struct {
+	Table  string                 `json:"table"`
+	Delete bool                   `json:"delete"`
+	Values map[string]interface{} `json:"values"`
+}
+
+type payload struct {
+	Cookie    []byte     `json:"cookie"`
+	Mutations []mutation `json:"mutations"`
+}
 
 var (
 	client = &http.Client{}
@@ -43,90 +73,152 @@ func main() {
 		WriterSecret:      "load-writer-secret",
 		FamilyName:        "loadfamily",
 		TableName:         "loadtable",
+		Profile:           "default",
+		RowsPerRequest:    unset,
+		KeySpace:          unset,
+		ValueSize:         unset,
+		DeleteRatio:       unset,
+		TableCount:        unset,
+		Concurrency:       1,
+		Rate:              2,
+		ReportInterval:    10 * time.Second,
 	}
 	conf.Load(&cfg)
+
+	wl, err := cfg.workload()
+	if err != nil {
+		fmt.Println("Invalid workload:", err)
+		os.Exit(1)
+	}
+	if cfg.Concurrency < 1 {
+		fmt.Println("Invalid concurrency:", cfg.Concurrency)
+		os.Exit(1)
+	}
+	fmt.Printf("Sending workload %+v with concurrency=%d rate=%.1f ramp-up=%s\n", wl, cfg.Concurrency, cfg.Rate, cfg.RampUp)
+
+	ctx, cancel := events.WithSignals(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+	if cfg.Duration > 0 {
+		ctx, cancel = context.WithTimeout(ctx, cfg.Duration)
+		defer cancel()
+	}
+
 	executiveURL := fmt.Sprintf("http://%s", cfg.ExecutiveEndpoint)
+	tables := wl.tableNames(cfg.TableName)
 	for {
-		if err := setup(cfg, executiveURL); err != nil {
+		if err := setup(cfg, executiveURL, tables); err != nil {
 			fmt.Println("Setup failed:", err)
-			time.Sleep(time.Second)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
 			continue
 		}
 		break
 	}
 
-	type mutation struct {
-		Table  string                 `json:"table"`
-		Delete bool                   `json:"delete"`
-		Values map[string]interface{} `json:"values"`
-	}
-	type payload struct {
-		Cookie    []byte     `json:"cookie"`
-		Mutations []mutation `json:"mutations"`
+	res := newResults()
+	if cfg.ReportInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(cfg.ReportInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					res.summarize().print(os.Stdout)
+				}
+			}
+		}()
 	}
 
 	// start sending the mutations
+	pace := newPacer(cfg.Rate, cfg.RampUp)
 	iter := uint64(time.Now().UnixNano())
-
-	for {
-		iter += 1
-		err := func() error {
-			cookie := make([]byte, 8)
-			binary.BigEndian.PutUint64(cookie, iter)
-			cookieStr := hex.EncodeToString(cookie)
-			payload := payload{
-				Cookie: cookie,
-				Mutations: []mutation{
-					{
-						Table:  cfg.TableName,
-						Delete: false,
-						Values: map[string]interface{}{
-							"type":  "general",
-							"name":  "cookie",
-							"value": cookieStr,
-						},
-					},
-					{
-						Table:  cfg.TableName,
-						Delete: false,
-						Values: map[string]interface{}{
-							"type":  "upcased",
-							"name":  "cookie",
-							"value": strings.ToUpper(cookieStr),
-						},
-					},
-				},
+	var wg sync.WaitGroup
+	for i := 0; i < cfg.Concurrency; i++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			rnd := rand.New(rand.NewSource(seed))
+			for {
+				if err := pace.wait(ctx, wl.RowsPerRequest); err != nil {
+					return
+				}
+				p := payload{
+					Cookie:    make([]byte, 8),
+					Mutations: wl.mutations(rnd, tables),
+				}
+				binary.BigEndian.PutUint64(p.Cookie, atomic.AddUint64(&iter, 1))
+				start := time.Now()
+				err := mutate(ctx, cfg, executiveURL, p)
+				if ctx.Err() != nil {
+					// the run ended while the request was in flight
+					return
+				}
+				res.record(len(p.Mutations), time.Since(start), err)
 			}
-			b, err := json.Marshal(payload)
-			if err != nil {
-				return errors.Wrap(err, "marshaling payload")
-			}
-			req, err := http.NewRequest("POST", executiveURL+"/families/"+cfg.FamilyName+"/mutations", bytes.NewReader(b))
-			req.Header.Set("Content-Type", "application/json")
-			req.Header.Set("ctlstore-writer", cfg.WriterName)
-			req.Header.Set("ctlstore-secret", cfg.WriterSecret)
-			resp, err := client.Do(req)
-			if err != nil {
-				return errors.Wrap(err, "making mutation request")
-			}
-			defer resp.Body.Close()
-			if resp.StatusCode != http.StatusOK {
-				b, _ = ioutil.ReadAll(resp.Body)
-				return fmt.Errorf("could not make mutation request: %d: %s", resp.StatusCode, b)
-			}
-			return nil
-		}()
-		if err != nil {
-			fmt.Println("Mutation failed:", err)
-		}
-		time.Sleep(sleepTime)
+		}(time.Now().UnixNano() + int64(i))
 	}
+	wg.Wait()
 
+	fmt.Println("Summary:")
+	res.summarize().print(os.Stdout)
+}
+
+// workload returns the selected profile with any overrides applied.
+func (cfg config) workload() (workload, error) {
+	wl, ok := profiles[cfg.Profile]
+	if !ok {
+		return wl, fmt.Errorf("unknown profile %q, must be one of %s", cfg.Profile, profileNames())
+	}
+	if cfg.RowsPerRequest != unset {
+		wl.RowsPerRequest = cfg.RowsPerRequest
+	}
+	if cfg.KeySpace != unset {
+		wl.KeySpace = cfg.KeySpace
+	}
+	if cfg.ValueSize != unset {
+		wl.ValueSize = cfg.ValueSize
+	}
+	if cfg.DeleteRatio != unset {
+		wl.DeleteRatio = cfg.DeleteRatio
+	}
+	if cfg.TableCount != unset {
+		wl.TableCount = cfg.TableCount
+	}
+	return wl, wl.validate()
+}
+
+func mutate(ctx context.Context, cfg config, url string, p payload) error {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return errors.Wrap(err, "marshaling payload")
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url+"/families/"+cfg.FamilyName+"/mutations", bytes.NewReader(b))
+	if err != nil {
+		return errors.Wrap(err, "create mutation request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("ctlstore-writer", cfg.WriterName)
+	req.Header.Set("ctlstore-secret", cfg.WriterSecret)
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "making mutation request")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ = ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("could not make mutation request: %d: %s", resp.StatusCode, b)
+	}
+	return nil
 }
 
 // setup does all the prep on the ctldb before it can start sending
 // mutations
-func setup(cfg config, url string) error {
+func setup(cfg config, url string, tables []string) error {
 
 	// register the writer first
 
@@ -160,7 +252,7 @@ func setup(cfg config, url string) error {
 
 	fmt.Println("Registered family:", cfg.FamilyName)
 
-	// create the table name
+	// create the tables
 
 	var tableDef = struct {
 		Fields    [][]string `json:"fields"`
@@ -173,21 +265,23 @@ func setup(cfg config, url string) error {
 		},
 		KeyFields: []string{"type", "name"},
 	}
-	req, err = http.NewRequest("POST", url+"/families/"+cfg.FamilyName+"/tables/"+cfg.TableName, utils.NewJsonReader(tableDef))
-	if err != nil {
-		return errors.Wrap(err, "create family request")
-	}
-	req.Header.Set("Content-Type", "application/json")
-	res, err = client.Do(req)
-	if err != nil {
-		return errors.Wrap(err, "making table request")
-	}
-	defer res.Body.Close()
-	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusConflict {
+	for _, table := range tables {
+		req, err = http.NewRequest("POST", url+"/families/"+cfg.FamilyName+"/tables/"+table, utils.NewJsonReader(tableDef))
+		if err != nil {
+			return errors.Wrap(err, "create table request")
+		}
+		req.Header.Set("Content-Type", "application/json")
+		res, err = client.Do(req)
+		if err != nil {
+			return errors.Wrap(err, "making table request")
+		}
 		b, _ := ioutil.ReadAll(res.Body)
-		return fmt.Errorf("could not make table request: %v: %s", res.StatusCode, b)
-	}
+		res.Body.Close()
+		if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusConflict {
+			return fmt.Errorf("could not make table request: %v: %s", res.StatusCode, b)
+		}
 
-	fmt.Println("Registered table:", cfg.TableName)
+		fmt.Println("Registered table:", table)
+	}
 	return nil
 }
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// pacer spaces requests out across all of the workers so that, together,
+// they send rows at the target rate. The rate climbs linearly from zero to
+// the target over the ramp up period.
+type pacer struct {
+	rowsPerSec float64 // zero is unlimited
+	rampUp     time.Duration
+	start      time.Time
+
+	mu   sync.Mutex
+	rows float64 // claimed by requests so far
+}
+
+func newPacer(rowsPerSec float64, rampUp time.Duration) *pacer {
+	return &pacer{
+		rowsPerSec: rowsPerSec,
+		rampUp:     rampUp,
+		start:      time.Now(),
+	}
+}
+
+// offset returns how long after the start the target rate allows the given
+// number of rows to have been sent, which is where the area under the rate
+// curve reaches it.
+func (p *pacer) offset(rows float64) time.Duration {
+	ramp := p.rampUp.Seconds()
+	var secs float64
+	if rampRows := p.rowsPerSec * ramp / 2; rows < rampRows {
+		secs = math.Sqrt(2 * ramp * rows / p.rowsPerSec)
+	} else {
+		secs = rows/p.rowsPerSec + ramp/2
+	}
+	return time.Duration(secs * float64(time.Second))
+}
+
+// wait blocks until a request of the given number of rows may be sent. A
+// request is sent once the rows of all of the requests before it are due,
+// so after a stall, requests are sent as fast as possible to catch up.
+func (p *pacer) wait(ctx context.Context, rows int) error {
+	if p.rowsPerSec <= 0 {
+		return ctx.Err()
+	}
+	p.mu.Lock()
+	at := p.start.Add(p.offset(p.rows))
+	p.rows += float64(rows)
+	p.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(time.Until(at)):
+		return nil
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// results accumulates the outcome of every request so that it can be
+// summarized.
+type results struct {
+	mu        sync.Mutex
+	start     time.Time
+	rows      int
+	errors    int
+	latencies []time.Duration
+	errCounts map[string]int
+}
+
+func newResults() *results {
+	return &results{
+		start:     time.Now(),
+		errCounts: map[string]int{},
+	}
+}
+
+func (r *results) record(rows int, latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.latencies = append(r.latencies, latency)
+	if err != nil {
+		r.errors++
+		r.errCounts[err.Error()]++
+		return
+	}
+	r.rows += rows
+}
+
+// summary is a snapshot of the results so far.
+type summary struct {
+	Elapsed  time.Duration
+	Requests int
+	Rows     int
+	Errors   int
+	P50      time.Duration
+	P90      time.Duration
+	P99      time.Duration
+	Max      time.Duration
+	// the most common errors, with how many times each happened
+	TopErrors []errorCount
+}
+
+type errorCount struct {
+	Err   string
+	Count int
+}
+
+func (r *results) summarize() summary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := summary{
+		Elapsed:  time.Since(r.start),
+		Requests: len(r.latencies),
+		Rows:     r.rows,
+		Errors:   r.errors,
+	}
+	if len(r.latencies) > 0 {
+		sorted := make([]time.Duration, len(r.latencies))
+		copy(sorted, r.latencies)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		pct := func(p float64) time.Duration {
+			return sorted[int(p*float64(len(sorted)-1))]
+		}
+		s.P50, s.P90, s.P99, s.Max = pct(0.5), pct(0.9), pct(0.99), sorted[len(sorted)-1]
+	}
+	for err, count := range r.errCounts {
+		s.TopErrors = append(s.TopErrors, errorCount{err, count})
+	}
+	sort.Slice(s.TopErrors, func(i, j int) bool { return s.TopErrors[i].Count > s.TopErrors[j].Count })
+	if len(s.TopErrors) > 5 {
+		s.TopErrors = s.TopErrors[:5]
+	}
+	return s
+}
+
+func (s summary) print(w io.Writer) {
+	secs := s.Elapsed.Seconds()
+	if secs == 0 {
+		secs = 1
+	}
+	fmt.Fprintf(w, "elapsed=%s requests=%d rows=%d errors=%d rows/sec=%.1f latency p50=%s p90=%s p99=%s max=%s\n",
+		s.Elapsed.Round(time.Second), s.Requests, s.Rows, s.Errors, float64(s.Rows)/secs,
+		s.P50.Round(time.Millisecond), s.P90.Round(time.Millisecond), s.P99.Round(time.Millisecond), s.Max.Round(time.Millisecond))
+	for _, e := range s.TopErrors {
+		fmt.Fprintf(w, "  %d x %s\n", e.Count, e.Err)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// workload describes the mutations sent to the executive.
+type workload struct {
+	// how many rows are written by each mutation request
+	RowsPerRequest int
+	// how many distinct keys are written to in each table
+	KeySpace int
+	// how many bytes each written value is
+	ValueSize int
+	// the fraction of rows which are deleted rather than upserted
+	DeleteRatio float64
+	// how many tables the rows are spread across
+	TableCount int
+}
+
+// profiles are the named workloads that can be selected with -profile.
+// Any of their fields can be overridden by the flag of the same name.
+var profiles = map[string]workload{
+	// the mutator's original behavior: upserting the same two small rows
+	"default": {RowsPerRequest: 2, KeySpace: 2, ValueSize: 16, TableCount: 1},
+	// many small requests, which exercises per-request overhead such as
+	// the ledger lock and writer rate limits
+	"small": {RowsPerRequest: 1, KeySpace: 10000, ValueSize: 32, TableCount: 1},
+	// large batches of large values, which exercises the ledger size limits
+	// and the reflectors' block sizes
+	"large": {RowsPerRequest: 100, KeySpace: 100000, ValueSize: 4096, TableCount: 4},
+	// upserts and deletes in equal measure, which keeps table sizes steady
+	"churn": {RowsPerRequest: 10, KeySpace: 1000, ValueSize: 256, DeleteRatio: 0.5, TableCount: 8},
+}
+
+func profileNames() string {
+	var names []string
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func (w workload) validate() error {
+	switch {
+	case w.RowsPerRequest < 1:
+		return fmt.Errorf("rows-per-request must be at least 1")
+	case w.KeySpace < 1:
+		return fmt.Errorf("key-space must be at least 1")
+	case w.ValueSize < 0:
+		return fmt.Errorf("value-size must not be negative")
+	case w.DeleteRatio < 0 || w.DeleteRatio > 1:
+		return fmt.Errorf("delete-ratio must be between 0 and 1")
+	case w.TableCount < 1:
+		return fmt.Errorf("table-count must be at least 1")
+	}
+	return nil
+}
+
+// tableNames returns the names of the tables the workload writes to. A
+// single table keeps the configured name, so that the default profile
+// writes to the same table as it always has.
+func (w workload) tableNames(base string) []string {
+	if w.TableCount == 1 {
+		return []string{base}
+	}
+	names := make([]string, w.TableCount)
+	for i := range names {
+		names[i] = fmt.Sprintf("%s%d", base, i)
+	}
+	return names
+}
+
+// mutations returns the rows of one mutation request.
+func (w workload) mutations(rnd *rand.Rand, tables []string) []mutation {
+	muts := make([]mutation, 0, w.RowsPerRequest)
+	for i := 0; i < w.RowsPerRequest; i++ {
+		m := mutation{
+			Table: tables[rnd.Intn(len(tables))],
+			Values: map[string]interface{}{
+				"type": "load",
+				"name": fmt.Sprintf("key-%d", rnd.Intn(w.KeySpace)),
+			},
+		}
+		if rnd.Float64() < w.DeleteRatio {
+			m.Delete = true
+		} else {
+			value := make([]byte, (w.ValueSize+1)/2)
+			rnd.Read(value)
+			m.Values["value"] = hex.EncodeToString(value)[:w.ValueSize]
+		}
+		muts = append(muts, m)
+	}
+	return muts
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"sort"
+	"sync"
+
+	"github.com/segmentio/events/v2"
+
+	reflectorpkg "github.com/segmentio/ctlstore/pkg/reflector"
+)
+
+// debugMux serves the --debug-bind address, which is separate from the
+// metrics bind so that it can be kept to a private interface.
+var debugMux = http.NewServeMux()
+
+// debugReflectors are the reflectors shown by /debug/ctlstore, keyed by ID
+var debugReflectors = struct {
+	sync.Mutex
+	m map[string]*reflectorpkg.Reflector
+}{m: map[string]*reflectorpkg.Reflector{}}
+
+func init() {
+	debugMux.HandleFunc("/debug/pprof/", pprof.Index)
+	debugMux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	debugMux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	debugMux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	debugMux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	debugMux.Handle("/debug/vars", expvar.Handler())
+	debugMux.HandleFunc("/debug/ctlstore", serveReflectorsDebug)
+}
+
+// serveDebug serves pprof, expvar and /debug/ctlstore on the address, if
+// one is set.
+func serveDebug(addr string) {
+	if addr == "" {
+		return
+	}
+	go func() {
+		events.Log("Serving debug endpoints on %{addr}s", addr)
+		if err := http.ListenAndServe(addr, debugMux); err != nil {
+			events.Log("Failed to serve debug endpoints: %{error}v", err)
+		}
+	}()
+}
+
+// addDebugReflector shows the reflector on /debug/ctlstore, and on its own
+// at /debug/ctlstore/{id}.
+func addDebugReflector(id string, r *reflectorpkg.Reflector) {
+	debugReflectors.Lock()
+	defer debugReflectors.Unlock()
+	if _, ok := debugReflectors.m[id]; !ok {
+		debugMux.Handle("/debug/ctlstore/"+id, r.DebugHandler())
+	}
+	debugReflectors.m[id] = r
+}
+
+// serveReflectorsDebug writes the DebugInfo of every reflector, in ID order.
+func serveReflectorsDebug(w http.ResponseWriter, r *http.Request) {
+	debugReflectors.Lock()
+	ids := make([]string, 0, len(debugReflectors.m))
+	reflectors := make(map[string]*reflectorpkg.Reflector, len(debugReflectors.m))
+	for id, ref := range debugReflectors.m {
+		ids = append(ids, id)
+		reflectors[id] = ref
+	}
+	debugReflectors.Unlock()
+	sort.Strings(ids)
+
+	res := make([]reflectorpkg.DebugInfo, 0, len(ids))
+	for _, id := range ids {
+		info, err := reflectors[id].DebugInfo(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		res = append(res, info)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugHandlersOnlyOnDebugMux(t *testing.T) {
+	for _, path := range []string{"/debug/pprof/", "/debug/vars"} {
+		rr := httptest.NewRecorder()
+		debugMux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, rr.Code, path)
+
+		rr = httptest.NewRecorder()
+		metricsMux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusNotFound, rr.Code, path)
+	}
+}
@@ -2,11 +2,14 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
 	"path"
 	"reflect"
+	"regexp"
 	"strings"
 	"sync"
 	"syscall"
@@ -18,31 +21,44 @@ import (
 	"github.com/segmentio/errors-go"
 	"github.com/segmentio/events/v2"
 	_ "github.com/segmentio/events/v2/sigevents"
+	"github.com/segmentio/objconv/yaml"
 	"github.com/segmentio/stats/v4"
 	"github.com/segmentio/stats/v4/datadog"
 	"github.com/segmentio/stats/v4/procstats"
 	"github.com/segmentio/stats/v4/prometheus"
 
 	"github.com/segmentio/ctlstore"
+	"github.com/segmentio/ctlstore/pkg/ctlcrypto"
 	"github.com/segmentio/ctlstore/pkg/ctldb"
 	"github.com/segmentio/ctlstore/pkg/errs"
 	executivepkg "github.com/segmentio/ctlstore/pkg/executive"
+	"github.com/segmentio/ctlstore/pkg/globalstats"
 	heartbeatpkg "github.com/segmentio/ctlstore/pkg/heartbeat"
+	"github.com/segmentio/ctlstore/pkg/ldb"
 	"github.com/segmentio/ctlstore/pkg/ldbwriter"
 	"github.com/segmentio/ctlstore/pkg/ledger"
 	reflectorpkg "github.com/segmentio/ctlstore/pkg/reflector"
 	sidecarpkg "github.com/segmentio/ctlstore/pkg/sidecar"
 	supervisorpkg "github.com/segmentio/ctlstore/pkg/supervisor"
+	"github.com/segmentio/ctlstore/pkg/tracing"
 	"github.com/segmentio/ctlstore/pkg/units"
 	"github.com/segmentio/ctlstore/pkg/utils"
 )
 
 var DebugEnabled = false
 
+// metricsMux serves the --metrics-bind address. It's not the default mux,
+// which packages such as net/http/pprof register debug handlers on that
+// are only meant to be served on the debug bind.
+var metricsMux = http.NewServeMux()
+
 type dogstatsdConfig struct {
 	Address    string        `conf:"address" help:"Address of the dogstatsd agent that will receive metrics"`
 	BufferSize int           `conf:"buffer-size" help:"Size of the statsd metrics buffer" validate:"min=0"`
 	FlushEvery time.Duration `conf:"flush-every" help:"Flush AT LEAST this frequently"`
+	// OTLPEndpoint lives here so that every command which reports stats
+	// can also export them to OpenTelemetry.
+	OTLPEndpoint string `conf:"otlp-endpoint" help:"URL of an OpenTelemetry collector's OTLP/HTTP metrics endpoint, e.g. http://localhost:4318/v1/metrics"`
 }
 
 type sidecarConfig struct {
@@ -51,61 +67,220 @@ type sidecarConfig struct {
 	MaxRows     int             `conf:"max-rows" help:"Maximum number of rows that can be returned in one response"`
 	Application string          `conf:"application" help:"The name of the application that will be using the sidecar"`
 	Dogstatsd   dogstatsdConfig `conf:"dogstatsd" help:"dogstatsd Configuration"`
+
+	ConsistencyTimeout time.Duration `conf:"consistency-timeout" help:"How long a read waits for the LDB to catch up to a client's consistency token"`
+	ACLPath            string        `conf:"acl-path" help:"Path to a JSON file mapping application tokens to the families and tables they may read. Reads are unrestricted if unset"`
+	UI                 bool          `conf:"ui" help:"Serve pages under /ui/ for browsing the LDB. With an ACL, the pages need an application token and only list the tables it may read"`
+	MaxLedgerLatency   time.Duration `conf:"max-ledger-latency" help:"If set, /healthz responds with a 503 once the LDB's ledger latency exceeds this"`
+	SQLite             sqliteConfig  `conf:"sqlite" help:"SQLite pragmas applied to the LDB when ldb-path is set"`
+	ReloadInterval     time.Duration `conf:"reload-interval" help:"How often to check the ACL file for changes, which are applied without a restart. The config is always reloaded on SIGHUP"`
+	DebugBind          string        `conf:"debug-bind" help:"Address to serve pprof and expvar on under /debug/"`
+	RequestLimits      requestLimits `conf:"request-limits" help:"Limits on the reads of each application, identified by the X-Ctlstore-Application header"`
+}
+
+type requestLimits struct {
+	Rate          float64  `conf:"rate" help:"Requests per second each application may make. 0 doesn't limit the rate"`
+	Burst         int      `conf:"burst" help:"Most requests an application may make at once within its rate. Defaults to the rate"`
+	MaxConcurrent int      `conf:"max-concurrent" help:"Most requests each application may have in flight. 0 doesn't limit them"`
+	Applications  []string `conf:"applications" help:"Applications limited separately. Requests from any other application share the limits of the \"unknown\" application"`
+}
+
+type sqliteConfig struct {
+	MmapSize    int64  `conf:"mmap-size" help:"Bytes of the LDB to memory map. 0 disables memory mapping"`
+	CacheSize   int    `conf:"cache-size" help:"Page cache size of each connection, in pages if positive or KiB if negative"`
+	PageSize    int    `conf:"page-size" help:"Page size of newly created LDBs. 0 uses SQLite's default"`
+	Synchronous string `conf:"synchronous" help:"How durably the LDB is written: OFF, NORMAL, FULL or EXTRA. Defaults to SQLite's default"`
+}
+
+func defaultSQLiteConfig() sqliteConfig {
+	return sqliteConfig{
+		MmapSize:    ldb.DefaultPragmas.MmapSize,
+		CacheSize:   ldb.DefaultPragmas.CacheSize,
+		PageSize:    ldb.DefaultPragmas.PageSize,
+		Synchronous: ldb.DefaultPragmas.Synchronous,
+	}
+}
+
+func (c sqliteConfig) pragmas() ldb.Pragmas {
+	return ldb.Pragmas{
+		MmapSize:    c.MmapSize,
+		CacheSize:   c.CacheSize,
+		PageSize:    c.PageSize,
+		Synchronous: c.Synchronous,
+	}
 }
 
 type reflectorCliConfig struct {
 	LDBPath                    string                   `conf:"ldb-path" help:"Path to LDB file" validate:"nonzero"`
 	ChangelogPath              string                   `conf:"changelog-path" help:"Path to changelog file"`
 	ChangelogSize              int                      `conf:"changelog-size" help:"Maximum size of the changelog file"`
-	UpstreamDriver             string                   `conf:"upstream-driver" help:"Upstream driver name (e.g. sqlite3)" validate:"nonzero"`
+	ChangelogTables            []string                 `conf:"changelog-tables" help:"family.table globs (e.g. payments.*) of the only tables whose changes are written to the changelog. All tables if unset"`
+	ChangelogExcludeTables     []string                 `conf:"changelog-exclude-tables" help:"family.table globs of tables whose changes aren't written to the changelog"`
+	UpstreamDriver             string                   `conf:"upstream-driver" help:"Upstream driver name (e.g. sqlite3), or mysql-binlog to read a MySQL upstream's ledger from its binlog rather than polling it" validate:"nonzero"`
 	UpstreamDSN                string                   `conf:"upstream-dsn" help:"Upstream DSN (e.g. path to file if sqlite3)" validate:"nonzero"`
 	UpstreamLedgerTable        string                   `conf:"upstream-ledger-table" help:"Table on the upstream to look for statement ledger"`
-	BootstrapURL               string                   `conf:"bootstrap-url" help:"Bootstraps LDB from an S3 URL"`
+	UpstreamBinlogServerID     uint32                   `conf:"upstream-binlog-server-id" help:"Server ID to read the binlog as, with --upstream-driver=mysql-binlog. Must be unique among the upstream's replicas, and is random if unset"`
+	UpstreamBusyTimeout        time.Duration            `conf:"upstream-busy-timeout" help:"How long ledger queries wait on the writer of a sqlite3 upstream. Defaults to 5s"`
+	UpstreamShardingSpec       string                   `conf:"upstream-sharding-spec" help:"Path to a JSON file listing additional ctldb shards whose ledgers are merged into the LDB"`
+	BootstrapURL               string                   `conf:"bootstrap-url" help:"Bootstraps LDB from an s3://, gs:// or https:// URL, including a peer reflector's LDB snapshot"`
 	BootstrapRegion            string                   `conf:"bootstrap-region" help:"If specified, indicates which region in which the S3 bucket lives"`
+	BootstrapBearerToken       string                   `conf:"bootstrap-bearer-token" help:"Bearer token sent when bootstrapping from an https:// or gs:// URL"`
 	PollInterval               time.Duration            `conf:"poll-interval" help:"How often to pull the upstream" validate:"nonzero"`
 	PollJitterCoefficient      float64                  `conf:"poll-jitter-coefficient" help:"Coefficient for poll jittering"`
+	PollIntervalMax            time.Duration            `conf:"poll-interval-max" help:"If greater than poll-interval, polling backs off up to this interval while the upstream is under load"`
+	PollSlowQueryThreshold     time.Duration            `conf:"poll-slow-query-threshold" help:"Ledger queries slower than this back off polling. Defaults to 500ms"`
 	PollTimeout                time.Duration            `conf:"poll-timeout" help:"How long to poll from the source before canceling"`
 	QueryBlockSize             int                      `conf:"query-block-size" help:"Number of ledger entries to get at once"`
+	QueryBlockBytes            int                      `conf:"query-block-bytes" help:"Maximum bytes of ledger statements to get at once. A larger statement is still fetched on its own. Defaults to 8MB"`
 	Debug                      bool                     `conf:"debug" help:"Turns on debug logging"`
 	LedgerHealth               ledgerHealthConfig       `conf:"ledger-latency" help:"Configure ledger latency behavior"`
 	Dogstatsd                  dogstatsdConfig          `conf:"dogstatsd" help:"dogstatsd Configuration"`
 	MetricsBind                string                   `conf:"metrics-bind" help:"address to serve Prometheus metircs"`
+	DebugBind                  string                   `conf:"debug-bind" help:"Address to serve pprof, expvar and the reflectors' progress on under /debug/"`
 	WALPollInterval            time.Duration            `conf:"wal-poll-interval" help:"How often to pull the sqlite's wal size and status. 0 indicates disabled monitoring'"`
 	WALCheckpointThresholdSize int                      `conf:"wal-checkpoint-threshold-size" help:"Performs a checkpoint after the WAL file exceeds this size in bytes"`
 	WALCheckpointType          ldbwriter.CheckpointType `conf:"wal-checkpoint-type" help:"what type of checkpoint to manually perform once the wal size is exceeded"`
+	CheckpointSchedule         checkpointScheduleConfig `conf:"wal-checkpoint-schedule" help:"Configuration for deferring WAL checkpoints while readers are busy"`
 	BusyTimeoutMS              int                      `conf:"busy-timeout-ms" help:"Set a busy timeout on the connection string for sqlite in milliseconds"`
+	SQLite                     sqliteConfig             `conf:"sqlite" help:"SQLite pragmas applied to the LDB"`
+	Vacuum                     vacuumConfig             `conf:"vacuum" help:"Configuration for periodically compacting the LDB"`
+	GapRepairGracePeriod       time.Duration            `conf:"gap-repair-grace-period" help:"How long to wait for skipped ledger sequences to appear before aborting. 0 aborts immediately"`
+	GroupCommitStatements      int                      `conf:"group-commit-statements" help:"Commit up to this many ledger statements in one LDB transaction. 0 commits each statement on its own"`
+	GroupCommitDelay           time.Duration            `conf:"group-commit-delay" help:"How long a group commit may stay open before it is committed"`
+	GapReportDir               string                   `conf:"gap-report-dir" help:"Where to write reports of ledger sequences that never appeared. Defaults to the LDB's directory"`
+	ShovelStateInterval        time.Duration            `conf:"shovel-state-interval" help:"How often to save the shovel's state next to the LDB for faster restarts. 0 disables the state file"`
 	MultiReflector             multiReflectorConfig     `conf:"multi-reflector" help:"Configuration for running multiple reflectors at once"`
+	TraceSampling              traceSamplingConfig      `conf:"trace-sampling" help:"Configuration for sampling applied statements, served on the metrics bind"`
+	FIPSMode                   bool                     `conf:"fips-mode" help:"Only use FIPS approved hash algorithms. Requires the crypto module to run in FIPS mode"`
+	ServePeerSnapshots         bool                     `conf:"serve-peer-snapshots" help:"Serve copies of the LDB on the metrics bind for peer reflectors to bootstrap from"`
+	Verify                     verifyConfig             `conf:"verify" help:"Configuration for verifying the LDB against the ctldb on startup"`
+	Rebuild                    bool                     `conf:"rebuild" help:"Rebuild the LDB on startup into a new versioned LDB directory, from the bootstrap URL, or by replaying the ledger from the start without one"`
+	RebuildJitter              time.Duration            `conf:"rebuild-jitter" help:"Longest random delay before rebuilding the LDB, to spread out the rebuilds of many reflectors"`
+	RebootstrapLag             int64                    `conf:"rebootstrap-lag" help:"Bootstrap the LDB again from the bootstrap URL on startup if it's more than this many ledger sequences behind, instead of replaying them. 0 always replays"`
+	Guard                      guardConfig              `conf:"guard" help:"Configuration for refusing to apply harmful statements, which are quarantined in the LDB"`
+	StatementTimeout           time.Duration            `conf:"statement-timeout" help:"Interrupt ledger statements which take longer than this to apply, failing them. 0 doesn't bound them"`
+	SlowStatementThreshold     time.Duration            `conf:"slow-statement-threshold" help:"Log ledger statements which take at least this long to apply. 0 doesn't log them"`
+}
+
+type guardConfig struct {
+	AllowTables    []string `conf:"allow-tables" help:"family.table globs (e.g. payments.*) of the only tables whose statements are applied. All tables if unset"`
+	DenyTables     []string `conf:"deny-tables" help:"family.table globs of tables whose statements are refused"`
+	DenyStatements []string `conf:"deny-statements" help:"Regular expressions matched against the SQL of statements, which are refused if any match"`
+}
+
+type verifyConfig struct {
+	SampleSize       int    `conf:"sample-size" help:"Number of random rows per table to compare with the ctldb on startup. 0 disables verification"`
+	FailOnDivergence bool   `conf:"fail-on-divergence" help:"Refuse to start if any sampled row differs from the ctldb"`
+	ReportDir        string `conf:"report-dir" help:"Where to write verification reports. Defaults to the LDB's directory"`
+}
+
+type traceSamplingConfig struct {
+	Every int `conf:"every" help:"Record every Nth applied statement. 0 disables sampling"`
+	Size  int `conf:"size" help:"Maximum number of samples to retain"`
+}
+
+type checkpointScheduleConfig struct {
+	BusyFile            string        `conf:"busy-file" help:"Defer WAL checkpoints while this file exists, which readers may create while they're busy"`
+	MaxDeferral         time.Duration `conf:"max-deferral" help:"Longest a WAL checkpoint may be deferred for while readers are busy. Defaults to 5m"`
+	TruncateWindowStart time.Duration `conf:"truncate-window-start" help:"Start of the low-traffic window in which TRUNCATE checkpoints are performed, as an offset from midnight UTC"`
+	TruncateWindowEnd   time.Duration `conf:"truncate-window-end" help:"End of the low-traffic window in which TRUNCATE checkpoints are performed, as an offset from midnight UTC"`
+}
+
+type vacuumConfig struct {
+	Interval     time.Duration `conf:"interval" help:"How often to vacuum the LDB. 0 disables vacuuming"`
+	MaxDuration  time.Duration `conf:"max-duration" help:"Longest a single vacuum may run for. 0 means no limit"`
+	WindowStart  time.Duration `conf:"window-start" help:"Start of the off-peak window in which vacuums may run, as an offset from midnight UTC"`
+	WindowEnd    time.Duration `conf:"window-end" help:"End of the off-peak window in which vacuums may run, as an offset from midnight UTC"`
+	MinFreePages int64         `conf:"min-free-pages" help:"Skip vacuuming while the LDB has fewer free pages than this"`
+	Incremental  bool          `conf:"incremental" help:"Switch the LDB to incremental auto-vacuum and free pages in batches"`
 }
 
 type multiReflectorConfig struct {
-	LDBPaths []string `conf:"ldb-paths" help:"list of ldbs, each ldb is managed by a unique reflector"`
+	LDBPaths     []string `conf:"ldb-paths" help:"list of ldbs, each ldb is managed by a unique reflector"`
+	ShardingSpec string   `conf:"ldb-sharding-spec" help:"Path to a JSON file assigning families to LDBs of their own. Other families are reflected into ldb-path, and ldb-paths is ignored"`
 }
 
 type executiveCliConfig struct {
-	Bind                           string          `conf:"bind" help:"Address for binding the HTTP server" validate:"nonzero"`
-	CtlDBDSN                       string          `conf:"ctldb" help:"SQL DSN for ctldb" validate:"nonzero"`
-	Debug                          bool            `conf:"debug" help:"Turns on debug logging"`
-	HandlerTimeout                 time.Duration   `conf:"handler-timeout" help:"Timeout on request handling"`
-	MaxTableSize                   int64           `conf:"max-table-size" help:"Max table size in bytes"`
-	WarnTableSize                  int64           `conf:"warn-table-size" help:"Emit a metric when a table sizes grows past this threshold"`
-	WriterLimitPeriod              time.Duration   `conf:"writer-limit-period" help:"The period to use for writer-limit"`
-	WriterLimit                    int64           `conf:"writer-limit" help:"How many rows a writer may mutate per period"`
-	Shadow                         bool            `conf:"shadow" help:"set this to true to emit shadow=true metric tags"`
-	Dogstatsd                      dogstatsdConfig `conf:"dogstatsd" help:"dogstatsd Configuration"`
-	EnableDestructiveSchemaChanges bool            `conf:"enable-destructive-schema-changes" help:"Turns on the ability to clear and drop tables from the executive API"`
+	Bind                           string              `conf:"bind" help:"Address for binding the HTTP server" validate:"nonzero"`
+	CtlDBDSN                       string              `conf:"ctldb" help:"SQL DSN for ctldb" validate:"nonzero"`
+	CtlDBReadDSN                   string              `conf:"ctldb-read" help:"Optional SQL DSN for a ctldb read replica used by read-only endpoints"`
+	ReplicaHealthInterval          time.Duration       `conf:"replica-health-interval" help:"How often to check the health of the ctldb read replica"`
+	Debug                          bool                `conf:"debug" help:"Turns on debug logging"`
+	HandlerTimeout                 time.Duration       `conf:"handler-timeout" help:"Timeout on request handling"`
+	MaxTableSize                   int64               `conf:"max-table-size" help:"Max table size in bytes"`
+	WarnTableSize                  int64               `conf:"warn-table-size" help:"Emit a metric when a table sizes grows past this threshold"`
+	WriterLimitPeriod              time.Duration       `conf:"writer-limit-period" help:"The period to use for writer-limit"`
+	WriterLimit                    int64               `conf:"writer-limit" help:"How many rows a writer may mutate per period"`
+	WriterBurst                    int64               `conf:"writer-burst" help:"If set, writers without their own limit may burst up to this many rows, refilled at writer-limit per period"`
+	Shadow                         bool                `conf:"shadow" help:"set this to true to emit shadow=true metric tags"`
+	Dogstatsd                      dogstatsdConfig     `conf:"dogstatsd" help:"dogstatsd Configuration"`
+	EnableDestructiveSchemaChanges bool                `conf:"enable-destructive-schema-changes" help:"Turns on the ability to clear and drop tables from the executive API"`
+	ShadowURL                      string              `conf:"shadow-url" help:"Base URL of a secondary executive that write requests are asynchronously replayed against"`
+	ShadowQueueSize                int                 `conf:"shadow-queue-size" help:"How many write requests may wait to be replayed against the shadow executive before they are dropped"`
+	ParameterizedDML               bool                `conf:"parameterized-dml" help:"Write parameterized DML statements to the ledger. The ledger must have a version column, and every reflector must support them before this is enabled"`
+	FIPSMode                       bool                `conf:"fips-mode" help:"Only use FIPS approved hash algorithms. Requires the crypto module to run in FIPS mode"`
+	OTLPTracesEndpoint             string              `conf:"otlp-traces-endpoint" help:"URL of an OpenTelemetry collector's OTLP/HTTP traces endpoint, e.g. http://localhost:4318/v1/traces"`
+	RecordTraceIDs                 bool                `conf:"record-trace-ids" help:"Record the trace ID of each request in the ledger. The ledger must have a trace_id column"`
+	TableAnalyzer                  tableAnalyzerConfig `conf:"table-analyzer" help:"Configures the refreshing of the ctldb tables' index statistics"`
+	Webhooks                       webhooksConfig      `conf:"webhooks" help:"Configures the delivery of notifications to family webhooks"`
+	WriterExpiry                   writerExpiryConfig  `conf:"writer-expiry" help:"Configures the disabling of writers which have been idle for too long"`
+	RequireWriterApproval          bool                `conf:"require-writer-approval" help:"Writers must be requested with POST /writers/{name}/request and approved with an admin token, instead of registered directly"`
+	TrustedProxies                 []string            `conf:"trusted-proxies" help:"Addresses or CIDR ranges of the load balancers whose X-Forwarded-For header is used as the source IP of requests"`
+	RequireAuth                    bool                `conf:"require-auth" help:"Reject requests without an admin or API token, other than those writers make with their own credentials"`
+	AdminTokensPath                string              `conf:"admin-tokens-path" help:"Path to a JSON list of {\"name\", \"token\"} admin tokens, which authorize any request, including reviewing writer registrations"`
+	ExportDir                      string              `conf:"export-dir" help:"Directory which table exports to file:// destinations are written within. Only s3:// destinations are allowed if unset"`
+	Migrate                        bool                `conf:"migrate" help:"Apply pending ctldb migrations before serving traffic. The executive refuses to start while migrations are pending"`
+	DebugBind                      string              `conf:"debug-bind" help:"Address to serve pprof and expvar on under /debug/"`
+}
+
+// tableAnalyzerConfig configures the periodic ANALYZE TABLE of the ctldb's
+// family tables.
+type tableAnalyzerConfig struct {
+	Interval     time.Duration `conf:"interval" help:"How often to analyze tables. When unset, tables are only analyzed on demand"`
+	MinTableSize int64         `conf:"min-table-size" help:"Size in bytes a table must exceed to be analyzed on schedule"`
+	Concurrency  int           `conf:"concurrency" help:"How many tables to analyze at once"`
+	Optimize     bool          `conf:"optimize" help:"Also rebuild the tables with OPTIMIZE TABLE to reclaim the space of deleted rows"`
+}
+
+// webhooksConfig configures the delivery of webhook notifications. Unset
+// values use the executive's defaults.
+type webhooksConfig struct {
+	QueueSize       int           `conf:"queue-size" help:"How many deliveries can wait to be sent before notifications are dropped"`
+	Concurrency     int           `conf:"concurrency" help:"How many deliveries to send at once"`
+	Retries         int           `conf:"retries" help:"How many times to retry a failed delivery"`
+	Backoff         time.Duration `conf:"backoff" help:"Wait before the first retry of a delivery, doubled before each of the others"`
+	Timeout         time.Duration `conf:"timeout" help:"Timeout of each attempt to deliver"`
+	RefreshInterval time.Duration `conf:"refresh-interval" help:"How often to reload the webhooks from the ctldb"`
+}
+
+// writerExpiryConfig configures the disabling of idle writers.
+type writerExpiryConfig struct {
+	IdleAfter time.Duration `conf:"idle-after" help:"Disable writers which haven't mutated for this long. When unset, writers are never disabled"`
+	Interval  time.Duration `conf:"interval" help:"How often to look for idle writers"`
 }
 
 // supervisorCliConfig also composes a reflectorCliConfig because it ends up
 // running its own reflector.  The LDBPath will come from the composed
 // reflector config instead of being a top level element in this struct.
 type supervisorCliConfig struct {
-	SnapshotInterval    time.Duration      `conf:"snapshot-interval" help:"Wait time between snapshots" validate:"nonzero"`
-	SnapshotURL         string             `conf:"snapshot-url" help:"URL for snapshot upload (i.e. s3://bucket/key)" validate:"nonzero"`
-	Debug               bool               `conf:"debug" help:"Turns on debug logging"`
-	LedgerLatencyConfig ledgerHealthConfig `conf:"ledger-latency-health" help:"Configures ledger latency health behavior"`
-	ReflectorConfig     reflectorCliConfig `conf:"reflector" help:"reflector configuration"`
-	Shadow              bool               `conf:"shadow" help:"set this to true to emit shadow=true metric tags"`
-	Dogstatsd           dogstatsdConfig    `conf:"dogstatsd" help:"dogstatsd Configuration"`
+	SnapshotInterval    time.Duration        `conf:"snapshot-interval" help:"Wait time between snapshots" validate:"nonzero"`
+	SnapshotURL         string               `conf:"snapshot-url" help:"URL for snapshot upload (i.e. s3://bucket/key), or a comma separated list of URLs to upload each snapshot to all of them" validate:"nonzero"`
+	Debug               bool                 `conf:"debug" help:"Turns on debug logging"`
+	LedgerLatencyConfig ledgerHealthConfig   `conf:"ledger-latency-health" help:"Configures ledger latency health behavior"`
+	ReflectorConfig     reflectorCliConfig   `conf:"reflector" help:"reflector configuration"`
+	Shadow              bool                 `conf:"shadow" help:"set this to true to emit shadow=true metric tags"`
+	Dogstatsd           dogstatsdConfig      `conf:"dogstatsd" help:"dogstatsd Configuration"`
+	FIPSMode            bool                 `conf:"fips-mode" help:"Only use FIPS approved hash algorithms, including for snapshot checksums. Requires the crypto module to run in FIPS mode"`
+	LeaderElection      leaderElectionConfig `conf:"leader-election" help:"Configures leader election among supervisors"`
+	StatusBind          string               `conf:"status-bind" help:"Address to serve GET /status, reporting snapshot progress, and POST /snapshot, taking a snapshot immediately. Disabled when empty"`
+}
+
+// leaderElectionConfig configures the election of one supervisor to take
+// snapshots among several which run for availability.
+type leaderElectionConfig struct {
+	CtlDBDSN      string        `conf:"ctldb" help:"SQL DSN for the ctldb holding the lease. When unset, every supervisor takes snapshots"`
+	LeaseName     string        `conf:"lease-name" help:"Name of the lease, which supervisors uploading to the same snapshot URLs must share"`
+	LeaseDuration time.Duration `conf:"lease-duration" help:"How long the leader's lease lasts unless renewed"`
 }
 
 // ledgerHealthConfig configures the behavior of the container
@@ -171,6 +346,7 @@ func main() {
 			{Name: "heartbeat", Help: "Run the ctlstore Heartbeat service"},
 			{Name: "ldb-read-key", Help: "Reads a key from the LDB"},
 			{Name: "ctldb-schema", Help: "Dump the MySQL schema for the CtlDB"},
+			{Name: "tail", Help: "Print changes from the ledger or changelog as they happen"},
 		},
 	}
 
@@ -198,6 +374,8 @@ func main() {
 		ctldbSchema(ctx, args)
 	case "ldb-read-key":
 		ldbReadKey(ctx, args)
+	case "tail":
+		tail(ctx, args)
 	default:
 		panic("inconceivable")
 	}
@@ -209,6 +387,16 @@ func enableDebug() {
 	DebugEnabled = true
 }
 
+func configureCrypto(fipsMode bool) error {
+	if err := ctlcrypto.Configure(ctlcrypto.Config{FIPSMode: fipsMode}); err != nil {
+		return errors.Wrap(err, "configure crypto")
+	}
+	if fipsMode {
+		events.Log("Running in FIPS mode")
+	}
+	return nil
+}
+
 func defaultDogstatsdConfig() dogstatsdConfig {
 	return dogstatsdConfig{
 		BufferSize: 1024,
@@ -246,6 +434,14 @@ func configureDogstatsd(ctx context.Context, opts dogstatsdOpts) (dd *datadog.Cl
 		stats.Register(opts.prometheusHandler)
 	}
 
+	if config.OTLPEndpoint != "" {
+		stats.Register(globalstats.NewOTLPHandler(globalstats.OTLPConfig{
+			Endpoint:    config.OTLPEndpoint,
+			ServiceName: "ctlstore-" + opts.statsPrefix,
+		}))
+		events.Log("Exporting OTLP metrics to %{endpoint}s", config.OTLPEndpoint)
+	}
+
 	if stats.DefaultEngine.Handler != stats.Discard {
 		stats.DefaultEngine.Prefix = fmt.Sprintf("ctlstore.%s", opts.statsPrefix)
 		stats.DefaultEngine.Tags = append(stats.DefaultEngine.Tags, stats.Tag{Name: "version", Value: ctlstore.Version})
@@ -280,7 +476,9 @@ func ldbReadKey(_ context.Context, args []string) {
 }
 
 func ctldbSchema(_ context.Context, _ []string) {
-	fmt.Printf("%s\n", ctldb.CtlDBSchemaByDriver["mysql"])
+	for _, m := range ctldb.Migrations {
+		fmt.Printf("/* migration %d: %s */\n%s\n", m.Version, m.Name, m.Up["mysql"])
+	}
 }
 
 func supervisor(ctx context.Context, args []string) {
@@ -290,11 +488,18 @@ func supervisor(ctx context.Context, args []string) {
 			SnapshotInterval: 5 * time.Minute,
 			Dogstatsd:        defaultDogstatsdConfig(),
 			ReflectorConfig:  reflectorConfig,
+			LeaderElection: leaderElectionConfig{
+				LeaseName:     "snapshots",
+				LeaseDuration: 30 * time.Second,
+			},
 		}
 		loadConfig(&cliCfg, "supervisor", args)
 		if cliCfg.Debug {
 			enableDebug()
 		}
+		if err := configureCrypto(cliCfg.FIPSMode || cliCfg.ReflectorConfig.FIPSMode); err != nil {
+			return err
+		}
 
 		shadow := "false"
 		if cliCfg.Shadow {
@@ -307,20 +512,41 @@ func supervisor(ctx context.Context, args []string) {
 			defaultTags: []stats.Tag{stats.T("shadow", shadow)},
 		})
 		defer teardown()
+		serveDebug(cliCfg.ReflectorConfig.DebugBind)
 		if err := utils.EnsureDirForFile(cliCfg.ReflectorConfig.LDBPath); err != nil {
 			return errors.Wrap(err, "ensure ldb dir")
 		}
 
-		reflector, err := newReflector(cliCfg.ReflectorConfig, true, 0)
+		reflector, err := newReflector(ctx, cliCfg.ReflectorConfig, true, 0, ldbwriter.FamilyFilter{})
 		if err != nil {
 			return errors.Wrap(err, "build supervisor reflector")
 		}
 
+		var leaderElection *supervisorpkg.LeaderElectionConfig
+		if cliCfg.LeaderElection.CtlDBDSN != "" {
+			dsn, err := ctldb.SetCtldbDSNParameters(cliCfg.LeaderElection.CtlDBDSN)
+			if err != nil {
+				return errors.Wrap(err, "leader election dsn")
+			}
+			db, err := sql.Open("mysql", dsn)
+			if err != nil {
+				return errors.Wrap(err, "open leader election ctldb")
+			}
+			defer db.Close()
+			leaderElection = &supervisorpkg.LeaderElectionConfig{
+				DB:            db,
+				Name:          cliCfg.LeaderElection.LeaseName,
+				LeaseDuration: cliCfg.LeaderElection.LeaseDuration,
+			}
+		}
+
 		supervisor, err := supervisorpkg.SupervisorFromConfig(supervisorpkg.SupervisorConfig{
 			SnapshotInterval: cliCfg.SnapshotInterval,
 			SnapshotURL:      cliCfg.SnapshotURL,
-			LDBPath:          cliCfg.ReflectorConfig.LDBPath, // use the reflector config's ldb path here
-			Reflector:        reflector,                      // compose the reflector, since it will start with the supervisor
+			LDBPath:          reflector.LDBPath(), // use the reflector's ldb path here, which may have been rebuilt
+			Reflector:        reflector,           // compose the reflector, since it will start with the supervisor
+			LeaderElection:   leaderElection,
+			StatusBind:       cliCfg.StatusBind,
 		})
 		if err != nil {
 			return errors.Wrap(err, "start supervisor")
@@ -375,12 +601,17 @@ func executive(ctx context.Context, args []string) {
 		Bind:                           "",
 		CtlDBDSN:                       "",
 		HandlerTimeout:                 30 * time.Second,
+		ReplicaHealthInterval:          5 * time.Second,
 		Dogstatsd:                      defaultDogstatsdConfig(),
 		WriterLimitPeriod:              time.Minute,
 		WriterLimit:                    1000,
 		WarnTableSize:                  50 * units.MEGABYTE,
 		MaxTableSize:                   100 * units.MEGABYTE,
 		EnableDestructiveSchemaChanges: false,
+		TableAnalyzer: tableAnalyzerConfig{
+			MinTableSize: 10 * units.MEGABYTE,
+			Concurrency:  1,
+		},
 	}
 
 	loadConfig(&cliCfg, "executive", args)
@@ -390,6 +621,18 @@ func executive(ctx context.Context, args []string) {
 	if cliCfg.Debug {
 		enableDebug()
 	}
+	if err := configureCrypto(cliCfg.FIPSMode); err != nil {
+		events.Log("Fatal error starting Executive: %{error}+v", err)
+		errs.IncrDefault(stats.T("op", "startup"))
+		return
+	}
+
+	adminTokens, err := loadAdminTokens(cliCfg.AdminTokensPath)
+	if err != nil {
+		events.Log("Fatal error starting Executive: %{error}+v", err)
+		errs.IncrDefault(stats.T("op", "startup"))
+		return
+	}
 
 	shadow := "false"
 	if cliCfg.Shadow {
@@ -402,15 +645,57 @@ func executive(ctx context.Context, args []string) {
 		defaultTags: []stats.Tag{stats.T("shadow", shadow)},
 	})
 	defer teardown()
+	serveDebug(cliCfg.DebugBind)
+
+	if cliCfg.OTLPTracesEndpoint != "" {
+		exporter := tracing.NewOTLPExporter(tracing.OTLPConfig{
+			Endpoint:    cliCfg.OTLPTracesEndpoint,
+			ServiceName: "ctlstore-executive",
+		})
+		tracing.SetExporter(exporter)
+		go exporter.Run(ctx)
+		events.Log("Exporting OTLP spans to %{endpoint}s", cliCfg.OTLPTracesEndpoint)
+	}
 
 	executive, err := executivepkg.ExecutiveServiceFromConfig(executivepkg.ExecutiveServiceConfig{
 		CtlDBDSN:                       cliCfg.CtlDBDSN,
+		CtlDBReadDSN:                   cliCfg.CtlDBReadDSN,
+		ReplicaHealthInterval:          cliCfg.ReplicaHealthInterval,
 		RequestTimeout:                 cliCfg.HandlerTimeout,
 		MaxTableSize:                   cliCfg.MaxTableSize,
 		WarnTableSize:                  cliCfg.WarnTableSize,
 		WriterLimit:                    cliCfg.WriterLimit,
+		WriterBurst:                    cliCfg.WriterBurst,
 		WriterLimitPeriod:              cliCfg.WriterLimitPeriod,
 		EnableDestructiveSchemaChanges: cliCfg.EnableDestructiveSchemaChanges,
+		ShadowURL:                      cliCfg.ShadowURL,
+		ShadowQueueSize:                cliCfg.ShadowQueueSize,
+		ParameterizedDML:               cliCfg.ParameterizedDML,
+		RecordTraceIDs:                 cliCfg.RecordTraceIDs,
+		RequireWriterApproval:          cliCfg.RequireWriterApproval,
+		TrustedProxies:                 cliCfg.TrustedProxies,
+		RequireAuth:                    cliCfg.RequireAuth,
+		AdminTokens:                    adminTokens,
+		ExportDir:                      cliCfg.ExportDir,
+		TableAnalyzer: executivepkg.TableAnalyzerConfig{
+			Interval:     cliCfg.TableAnalyzer.Interval,
+			MinTableSize: cliCfg.TableAnalyzer.MinTableSize,
+			Concurrency:  cliCfg.TableAnalyzer.Concurrency,
+			Optimize:     cliCfg.TableAnalyzer.Optimize,
+		},
+		Webhooks: executivepkg.WebhooksConfig{
+			QueueSize:       cliCfg.Webhooks.QueueSize,
+			Concurrency:     cliCfg.Webhooks.Concurrency,
+			Retries:         cliCfg.Webhooks.Retries,
+			Backoff:         cliCfg.Webhooks.Backoff,
+			Timeout:         cliCfg.Webhooks.Timeout,
+			RefreshInterval: cliCfg.Webhooks.RefreshInterval,
+		},
+		WriterExpiry: executivepkg.WriterExpiryConfig{
+			IdleAfter: cliCfg.WriterExpiry.IdleAfter,
+			Interval:  cliCfg.WriterExpiry.Interval,
+		},
+		Migrate: cliCfg.Migrate,
 	})
 	if err != nil {
 		errs.IncrDefault(stats.T("op", "startup"))
@@ -427,12 +712,33 @@ func executive(ctx context.Context, args []string) {
 	}
 }
 
-func sidecar(ctx context.Context, args []string) {
-	config := sidecarConfig{
-		BindAddr:  "0.0.0.0:1331",
-		Dogstatsd: defaultDogstatsdConfig(),
+func defaultSidecarConfig() sidecarConfig {
+	return sidecarConfig{
+		BindAddr:           "0.0.0.0:1331",
+		Dogstatsd:          defaultDogstatsdConfig(),
+		ConsistencyTimeout: time.Second,
+		SQLite:             defaultSQLiteConfig(),
+	}
+}
+
+// sidecarLoader loads the sidecar's config from a YAML -config-file as well
+// as the environment and arguments, so that editing the file and sending a
+// SIGHUP changes the config.
+func sidecarLoader(args []string) conf.Loader {
+	return conf.Loader{
+		Name: "ctlstore sidecar",
+		Args: args,
+		Sources: []conf.Source{
+			conf.NewFileSource("config-file", nil, os.ReadFile, yaml.Unmarshal),
+			conf.NewEnvSource("CTLSTORE", os.Environ()...),
+		},
 	}
-	loadConfig(&config, "sidecar", args)
+}
+
+func sidecar(ctx context.Context, args []string) {
+	config := defaultSidecarConfig()
+	ld := sidecarLoader(args)
+	conf.LoadWith(&config, ld)
 	dd, teardown := configureDogstatsd(ctx, dogstatsdOpts{
 		config:            config.Dogstatsd,
 		statsPrefix:       "sidecar",
@@ -442,31 +748,102 @@ func sidecar(ctx context.Context, args []string) {
 	if dd != nil {
 		ctlstore.Initialize(ctx, "ctlstore-sidecar", dd)
 	}
+	serveDebug(config.DebugBind)
 	sidecar, err := newSidecar(config)
 	if err != nil {
 		events.Log("Fatal error starting sidecar: %{error}+v", err)
 		errs.IncrDefault(stats.T("op", "startup"))
 		return
 	}
+	go reloadSidecar(ctx, sidecar, ld, config)
 	sidecar.Start(ctx)
 }
 
+// reloadSidecar reloads the sidecar's config on SIGHUP, and when the ACL
+// file changes if there's a reload interval. Invalid configs are logged and
+// otherwise ignored, leaving the sidecar's config as it was.
+func reloadSidecar(ctx context.Context, sidecar *sidecarpkg.Sidecar, ld conf.Loader, config sidecarConfig) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	var tick <-chan time.Time
+	if config.ReloadInterval > 0 {
+		ticker := time.NewTicker(config.ReloadInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	aclModTime := fileModTime(config.ACLPath)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			events.Log("Reloading the sidecar config on SIGHUP")
+		case <-tick:
+			modTime := fileModTime(config.ACLPath)
+			if modTime.Equal(aclModTime) {
+				continue
+			}
+			// not retried until the ACL changes again, even if it's invalid
+			aclModTime = modTime
+			events.Log("Reloading the sidecar config since the ACL changed")
+		}
+		next := defaultSidecarConfig()
+		if _, _, err := ld.Load(&next); err != nil {
+			errs.IncrDefault(stats.T("op", "reload"))
+			events.Log("Could not reload the sidecar config: %{error}v", err)
+			continue
+		}
+		acl, err := loadSidecarACL(next.ACLPath)
+		if err == nil {
+			err = sidecar.Reload(sidecarPkgConfig(next, nil, acl))
+		}
+		if err != nil {
+			errs.IncrDefault(stats.T("op", "reload"))
+			events.Log("Could not reload the sidecar config: %{error}v", err)
+			continue
+		}
+		config = next
+		aclModTime = fileModTime(config.ACLPath)
+		events.Log("Reloaded the sidecar config")
+	}
+}
+
+// fileModTime returns the time the file was modified, or the zero time if
+// it can't be read.
+func fileModTime(path string) time.Time {
+	if path == "" {
+		return time.Time{}
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return fi.ModTime()
+}
+
 func reflector(ctx context.Context, args []string) {
 	cliCfg := defaultReflectorCLIConfig(false)
 	loadConfig(&cliCfg, "reflector", args)
 	if cliCfg.Debug {
 		enableDebug()
 	}
+	if err := configureCrypto(cliCfg.FIPSMode); err != nil {
+		events.Log("Fatal error starting Reflector: %{error}+v", err)
+		errs.IncrDefault(stats.T("op", "startup"))
+		return
+	}
 
+	serveDebug(cliCfg.DebugBind)
 	var promHandler *prometheus.Handler
 	if len(cliCfg.MetricsBind) > 0 {
 		promHandler = &prometheus.Handler{}
 
-		http.Handle("/metrics", promHandler)
+		metricsMux.Handle("/metrics", promHandler)
 
 		go func() {
 			events.Log("Serving Prometheus metrics on %s", cliCfg.MetricsBind)
-			err := http.ListenAndServe(cliCfg.MetricsBind, nil)
+			err := http.ListenAndServe(cliCfg.MetricsBind, metricsMux)
 			if err != nil {
 				events.Log("Failed to served Prometheus metrics: %s", err)
 			}
@@ -478,7 +855,10 @@ func reflector(ctx context.Context, args []string) {
 		prometheusHandler: promHandler,
 	})
 	defer teardown()
-	reflector, err := newReflector(cliCfg, false, 0)
+	if promHandler != nil {
+		reflectorpkg.RegisterPrometheusBuckets(stats.DefaultEngine.Prefix)
+	}
+	reflector, err := newReflector(ctx, cliCfg, false, 0, ldbwriter.FamilyFilter{})
 	if err != nil {
 		events.Log("Fatal error starting Reflector: %{error}+v", err)
 		errs.IncrDefault(stats.T("op", "startup"))
@@ -495,19 +875,28 @@ func multiReflector(ctx context.Context, args []string) {
 		enableDebug()
 	}
 
-	if len(cliCfg.MultiReflector.LDBPaths) <= 1 {
-		panic("multi-reflector mode requires at least 2 ldb paths")
+	configs, families, err := multiReflectorConfigs(cliCfg)
+	if err != nil {
+		events.Log("Fatal error starting Reflector: %{error}+v", err)
+		errs.IncrDefault(stats.T("op", "startup"))
+		return
+	}
+	if err := configureCrypto(cliCfg.FIPSMode); err != nil {
+		events.Log("Fatal error starting Reflector: %{error}+v", err)
+		errs.IncrDefault(stats.T("op", "startup"))
+		return
 	}
 
+	serveDebug(cliCfg.DebugBind)
 	var promHandler *prometheus.Handler
 	if len(cliCfg.MetricsBind) > 0 {
 		promHandler = &prometheus.Handler{}
 
-		http.Handle("/metrics", promHandler)
+		metricsMux.Handle("/metrics", promHandler)
 
 		go func() {
 			events.Log("Serving Prometheus metrics on %s", cliCfg.MetricsBind)
-			err := http.ListenAndServe(cliCfg.MetricsBind, nil)
+			err := http.ListenAndServe(cliCfg.MetricsBind, metricsMux)
 			if err != nil {
 				events.Log("Failed to served Prometheus metrics: %s", err)
 			}
@@ -519,32 +908,26 @@ func multiReflector(ctx context.Context, args []string) {
 		prometheusHandler: promHandler,
 	})
 	defer teardown()
+	if promHandler != nil {
+		reflectorpkg.RegisterPrometheusBuckets(stats.DefaultEngine.Prefix)
+	}
 
-	reflectors := make([]*reflectorpkg.Reflector, len(cliCfg.MultiReflector.LDBPaths))
+	reflectors := make([]*reflectorpkg.Reflector, len(configs))
 	var wg sync.WaitGroup
-	errChan := make(chan error, len(cliCfg.MultiReflector.LDBPaths))
-	wg.Add(len(cliCfg.MultiReflector.LDBPaths))
-	for i, ldbPath := range cliCfg.MultiReflector.LDBPaths {
-		p := ldbPath
-		x := cliCfg
-		x.LDBPath = p
-		if i > 0 {
-			events.Log("changelog only created for 1st ldb path: %{path}, skipping #%{num}d", cliCfg.MultiReflector.LDBPaths[0], i+1)
-			x.ChangelogPath = ""
-			x.ChangelogSize = 0
-
-		}
-		go func(x reflectorCliConfig, idx int) {
+	errChan := make(chan error, len(configs))
+	wg.Add(len(configs))
+	for i, x := range configs {
+		go func(x reflectorCliConfig, families ldbwriter.FamilyFilter, idx int) {
 			defer wg.Done()
-			r, err := newReflector(x, false, idx)
+			r, err := newReflector(ctx, x, false, idx, families)
 			if err != nil {
 				events.Log("Fatal error starting Reflector: %{error}+v", err)
-				errs.IncrDefault(stats.T("op", "startup"), stats.T("path", p))
+				errs.IncrDefault(stats.T("op", "startup"), stats.T("path", x.LDBPath))
 				errChan <- err
 				return
 			}
 			reflectors[idx] = r
-		}(x, i)
+		}(x, families[i], i)
 	}
 
 	wg.Wait()
@@ -563,7 +946,7 @@ func multiReflector(ctx context.Context, args []string) {
 		})
 	}
 
-	err := grp.Wait()
+	err = grp.Wait()
 	if err != nil {
 		events.Log("reflectors ended in error %{error}v", err)
 		errs.Incr("multi.shutdown", stats.T("err", reflect.ValueOf(err).Type().String()))
@@ -571,20 +954,74 @@ func multiReflector(ctx context.Context, args []string) {
 	}
 }
 
+// multiReflectorConfigs returns the config of each reflector run in
+// multi-reflector mode, along with the families each reflects.
+func multiReflectorConfigs(cliCfg reflectorCliConfig) ([]reflectorCliConfig, []ldbwriter.FamilyFilter, error) {
+	var configs []reflectorCliConfig
+	var families []ldbwriter.FamilyFilter
+
+	if cliCfg.MultiReflector.ShardingSpec != "" {
+		spec, err := reflectorpkg.LoadLDBShardingSpec(cliCfg.MultiReflector.ShardingSpec)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(spec.Shards) == 0 {
+			return nil, nil, errors.New("the ldb sharding spec has no shards")
+		}
+		// the remaining families stay in the main LDB, along with its changelog
+		configs = append(configs, cliCfg)
+		families = append(families, spec.RemainderFilter())
+		for _, shard := range spec.Shards {
+			if shard.LDBPath == cliCfg.LDBPath {
+				return nil, nil, errors.Errorf("ldb shard %s can't be the main ldb", shard.LDBPath)
+			}
+			x := cliCfg
+			x.LDBPath = shard.LDBPath
+			x.ChangelogPath = shard.ChangelogPath
+			if x.ChangelogPath == "" {
+				x.ChangelogSize = 0
+			}
+			configs = append(configs, x)
+			families = append(families, shard.Filter())
+			events.Log("Reflecting families %{families}v into %{path}s", shard.Families, shard.LDBPath)
+		}
+		return configs, families, nil
+	}
+
+	if len(cliCfg.MultiReflector.LDBPaths) <= 1 {
+		panic("multi-reflector mode requires at least 2 ldb paths")
+	}
+	for i, ldbPath := range cliCfg.MultiReflector.LDBPaths {
+		x := cliCfg
+		x.LDBPath = ldbPath
+		if i > 0 {
+			events.Log("changelog only created for 1st ldb path: %{path}, skipping #%{num}d", cliCfg.MultiReflector.LDBPaths[0], i+1)
+			x.ChangelogPath = ""
+			x.ChangelogSize = 0
+
+		}
+		configs = append(configs, x)
+		families = append(families, ldbwriter.FamilyFilter{})
+	}
+	return configs, families, nil
+}
+
 func defaultReflectorCLIConfig(isSupervisor bool) reflectorCliConfig {
 	config := reflectorCliConfig{
-		LDBPath:               "",
-		ChangelogPath:         "",
-		ChangelogSize:         1 * 1024 * 1024,
-		UpstreamDriver:        "",
-		UpstreamDSN:           "",
-		UpstreamLedgerTable:   "ctlstore_dml_ledger",
-		BootstrapURL:          "",
-		PollInterval:          1 * time.Second,
-		PollJitterCoefficient: 0.25,
-		QueryBlockSize:        100,
-		Dogstatsd:             defaultDogstatsdConfig(),
-		PollTimeout:           5 * time.Second,
+		LDBPath:                "",
+		ChangelogPath:          "",
+		ChangelogSize:          1 * 1024 * 1024,
+		UpstreamDriver:         "",
+		UpstreamDSN:            "",
+		UpstreamLedgerTable:    "ctlstore_dml_ledger",
+		BootstrapURL:           "",
+		PollInterval:           1 * time.Second,
+		PollJitterCoefficient:  0.25,
+		QueryBlockSize:         100,
+		Dogstatsd:              defaultDogstatsdConfig(),
+		PollTimeout:            5 * time.Second,
+		GroupCommitDelay:       100 * time.Millisecond,
+		SlowStatementThreshold: time.Second,
 		LedgerHealth: ledgerHealthConfig{
 			Disable:                 false,
 			MaxHealthyLatency:       time.Minute,
@@ -599,6 +1036,12 @@ func defaultReflectorCLIConfig(isSupervisor bool) reflectorCliConfig {
 		// 8 MB, double what a "healthy" WAL file should be https://www.sqlite.org/compile.html#default_wal_autocheckpoint
 		WALCheckpointThresholdSize: 8 * 1024 * 1024,
 		WALCheckpointType:          ldbwriter.Passive,
+		TraceSampling: traceSamplingConfig{
+			Every: 0,
+			Size:  100,
+		},
+		ShovelStateInterval: 10 * time.Second,
+		SQLite:              defaultSQLiteConfig(),
 	}
 	if isSupervisor {
 		// the supervisor runs as an ECS task, so it cannot yet set
@@ -614,33 +1057,108 @@ func newSidecar(config sidecarConfig) (*sidecarpkg.Sidecar, error) {
 	if config.LDBPath == "" {
 		reader, err = ctlstore.Reader()
 	} else {
-		reader, err = ctlstore.ReaderForPath(config.LDBPath)
+		reader, err = ctlstore.ReaderForPath(config.LDBPath, ctlstore.WithPragmas(config.SQLite.pragmas()))
+	}
+	if err != nil {
+		return nil, err
+	}
+	acl, err := loadSidecarACL(config.ACLPath)
+	if err != nil {
+		return nil, err
+	}
+	return sidecarpkg.New(sidecarPkgConfig(config, reader, acl))
+}
+
+func loadSidecarACL(path string) (*sidecarpkg.ACL, error) {
+	if path == "" {
+		return nil, nil
 	}
+	acl, err := sidecarpkg.LoadACL(path)
 	if err != nil {
 		return nil, err
 	}
-	return sidecarpkg.New(sidecarpkg.Config{
+	events.Log("Loaded sidecar ACL for %{count}d applications", len(acl.Applications))
+	return acl, nil
+}
+
+func loadAdminTokens(path string) ([]executivepkg.AdminToken, error) {
+	if path == "" {
+		return nil, nil
+	}
+	tokens, err := executivepkg.LoadAdminTokens(path)
+	if err != nil {
+		return nil, err
+	}
+	events.Log("Loaded %{count}d admin tokens", len(tokens))
+	return tokens, nil
+}
+
+func sidecarPkgConfig(config sidecarConfig, reader sidecarpkg.Reader, acl *sidecarpkg.ACL) sidecarpkg.Config {
+	return sidecarpkg.Config{
 		BindAddr:    config.BindAddr,
 		Reader:      reader,
 		MaxRows:     config.MaxRows,
 		Application: config.Application,
-	})
+
+		ConsistencyTimeout: config.ConsistencyTimeout,
+		ACL:                acl,
+		UI:                 config.UI,
+		MaxLedgerLatency:   config.MaxLedgerLatency,
+		RequestLimits: sidecarpkg.RequestLimits{
+			Rate:          config.RequestLimits.Rate,
+			Burst:         config.RequestLimits.Burst,
+			MaxConcurrent: config.RequestLimits.MaxConcurrent,
+			Applications:  config.RequestLimits.Applications,
+		},
+	}
 }
 
-func newReflector(cliCfg reflectorCliConfig, isSupervisor bool, i int) (*reflectorpkg.Reflector, error) {
+func newReflector(ctx context.Context, cliCfg reflectorCliConfig, isSupervisor bool, i int, families ldbwriter.FamilyFilter) (*reflectorpkg.Reflector, error) {
 	if cliCfg.LedgerHealth.Disable {
 		events.Log("DEPRECATION NOTICE: use --disable-ecs-behavior instead of --disable to control this ledger monitor behavior")
 	}
 	id := fmt.Sprintf("%s-%d", path.Base(cliCfg.LDBPath), i)
 	l := events.NewLogger(events.DefaultHandler).With(events.Args{{"id", id}})
 	l.EnableDebug = cliCfg.Debug
-	return reflectorpkg.ReflectorFromConfig(reflectorpkg.ReflectorConfig{
-		LDBPath:         cliCfg.LDBPath,
-		ChangelogPath:   cliCfg.ChangelogPath,
-		ChangelogSize:   cliCfg.ChangelogSize,
-		BootstrapURL:    cliCfg.BootstrapURL,
-		BootstrapRegion: cliCfg.BootstrapRegion,
-		IsSupervisor:    isSupervisor,
+	pragmas := cliCfg.SQLite.pragmas()
+	var sampler *ldbwriter.TraceSampler
+	if cliCfg.TraceSampling.Every > 0 {
+		sampler = ldbwriter.NewTraceSampler(cliCfg.TraceSampling.Every, cliCfg.TraceSampling.Size)
+		samplesPath := "/debug/trace-samples/" + id
+		metricsMux.Handle(samplesPath, sampler)
+		events.Log("Sampling every %{every}d applied statements, served at %{path}s", cliCfg.TraceSampling.Every, samplesPath)
+	}
+	guard := ldbwriter.StatementGuard{
+		AllowTables: cliCfg.Guard.AllowTables,
+		DenyTables:  cliCfg.Guard.DenyTables,
+	}
+	for _, expr := range cliCfg.Guard.DenyStatements {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid deny statement expression %q", expr)
+		}
+		guard.DenyStatements = append(guard.DenyStatements, re)
+	}
+	var sharding reflectorpkg.ShardingSpec
+	if cliCfg.UpstreamShardingSpec != "" {
+		var err error
+		sharding, err = reflectorpkg.LoadShardingSpec(cliCfg.UpstreamShardingSpec)
+		if err != nil {
+			return nil, err
+		}
+	}
+	r, err := reflectorpkg.ReflectorFromConfigContext(ctx, reflectorpkg.ReflectorConfig{
+		LDBPath:       cliCfg.LDBPath,
+		ChangelogPath: cliCfg.ChangelogPath,
+		ChangelogSize: cliCfg.ChangelogSize,
+		ChangelogFilter: ldbwriter.ChangelogFilter{
+			Include: cliCfg.ChangelogTables,
+			Exclude: cliCfg.ChangelogExcludeTables,
+		},
+		BootstrapURL:         cliCfg.BootstrapURL,
+		BootstrapRegion:      cliCfg.BootstrapRegion,
+		BootstrapBearerToken: cliCfg.BootstrapBearerToken,
+		IsSupervisor:         isSupervisor,
 		LedgerHealth: ledger.HealthConfig{
 			DisableECSBehavior:      cliCfg.LedgerHealth.Disable || cliCfg.LedgerHealth.DisableECSBehavior,
 			MaxHealthyLatency:       cliCfg.LedgerHealth.MaxHealthyLatency,
@@ -654,17 +1172,80 @@ func newReflector(cliCfg reflectorCliConfig, isSupervisor bool, i int) (*reflect
 			Driver:                cliCfg.UpstreamDriver,
 			DSN:                   cliCfg.UpstreamDSN,
 			LedgerTable:           cliCfg.UpstreamLedgerTable,
+			BinlogServerID:        cliCfg.UpstreamBinlogServerID,
+			BusyTimeout:           cliCfg.UpstreamBusyTimeout,
 			PollInterval:          cliCfg.PollInterval,
 			PollJitterCoefficient: cliCfg.PollJitterCoefficient,
 			QueryBlockSize:        cliCfg.QueryBlockSize,
+			QueryBlockBytes:       cliCfg.QueryBlockBytes,
 			PollTimeout:           cliCfg.PollTimeout,
+			Shards:                sharding.Shards,
+			AdaptivePolling: reflectorpkg.AdaptivePollingConfig{
+				MaxPollInterval:    cliCfg.PollIntervalMax,
+				SlowQueryThreshold: cliCfg.PollSlowQueryThreshold,
+			},
 		},
 		WALPollInterval:            cliCfg.WALPollInterval,
 		DoMonitorWAL:               cliCfg.WALPollInterval > 0,
 		WALCheckpointThresholdSize: cliCfg.WALCheckpointThresholdSize,
 		WALCheckpointType:          cliCfg.WALCheckpointType,
 		BusyTimeoutMS:              cliCfg.BusyTimeoutMS,
+		Pragmas:                    &pragmas,
+		TraceSampler:               sampler,
 		ID:                         id,
 		Logger:                     l,
+		GapRepairGracePeriod:       cliCfg.GapRepairGracePeriod,
+		GapReportDir:               cliCfg.GapReportDir,
+		StateInterval:              cliCfg.ShovelStateInterval,
+		Rebuild:                    cliCfg.Rebuild,
+		RebuildJitter:              cliCfg.RebuildJitter,
+		RebootstrapLag:             cliCfg.RebootstrapLag,
+		Families:                   families,
+		Guard:                      guard,
+		StatementTimeout:           cliCfg.StatementTimeout,
+		SlowStatementThreshold:     cliCfg.SlowStatementThreshold,
+		GroupCommit: ldbwriter.GroupCommit{
+			MaxStatements: cliCfg.GroupCommitStatements,
+			MaxDelay:      cliCfg.GroupCommitDelay,
+		},
+		CheckpointSchedule: reflectorpkg.CheckpointSchedule{
+			BusyFile:            cliCfg.CheckpointSchedule.BusyFile,
+			MaxDeferral:         cliCfg.CheckpointSchedule.MaxDeferral,
+			TruncateWindowStart: cliCfg.CheckpointSchedule.TruncateWindowStart,
+			TruncateWindowEnd:   cliCfg.CheckpointSchedule.TruncateWindowEnd,
+		},
+		Vacuum: reflectorpkg.VacuumConfig{
+			Interval:     cliCfg.Vacuum.Interval,
+			MaxDuration:  cliCfg.Vacuum.MaxDuration,
+			WindowStart:  cliCfg.Vacuum.WindowStart,
+			WindowEnd:    cliCfg.Vacuum.WindowEnd,
+			MinFreePages: cliCfg.Vacuum.MinFreePages,
+			Incremental:  cliCfg.Vacuum.Incremental,
+		},
+		Verify: reflectorpkg.VerifyConfig{
+			SampleSize:       cliCfg.Verify.SampleSize,
+			FailOnDivergence: cliCfg.Verify.FailOnDivergence,
+			ReportDir:        cliCfg.Verify.ReportDir,
+		},
 	})
+	if err != nil {
+		return nil, err
+	}
+	if h := r.StateHandler(); h != nil {
+		statePath := "/debug/shovel-state/" + id
+		metricsMux.Handle(statePath, h)
+		events.Log("Serving the shovel state at %{path}s", statePath)
+	}
+	addDebugReflector(id, r)
+	if cliCfg.ServePeerSnapshots {
+		// registered once the LDB exists, so that peers aren't sent an
+		// LDB which is still being bootstrapped
+		snapshotPath := "/ldb-snapshot/" + id
+		metricsMux.Handle(snapshotPath, reflectorpkg.NewPeerSnapshotHandler(r.LDBPath()))
+		events.Log("Serving LDB snapshots for peers at %{path}s", snapshotPath)
+		if cliCfg.MetricsBind == "" {
+			events.Log("LDB snapshots for peers need --metrics-bind to be served")
+		}
+	}
+	return r, nil
 }
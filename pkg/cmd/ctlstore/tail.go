@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/segmentio/errors-go"
+	"github.com/segmentio/events/v2"
+
+	"github.com/segmentio/ctlstore/pkg/ctldb"
+	"github.com/segmentio/ctlstore/pkg/errs"
+	"github.com/segmentio/ctlstore/pkg/event"
+	"github.com/segmentio/ctlstore/pkg/sqlgen"
+)
+
+type tailCliConfig struct {
+	Source        string        `conf:"source" help:"Where to read changes from: ledger or changelog" validate:"nonzero"`
+	CtlDBDriver   string        `conf:"ctldb-driver" help:"Driver for the ctldb when tailing the ledger (e.g. mysql or sqlite3)"`
+	CtlDBDSN      string        `conf:"ctldb" help:"SQL DSN for the ctldb when tailing the ledger"`
+	LedgerTable   string        `conf:"ledger-table" help:"Table on the ctldb to read the statement ledger from"`
+	FromSeq       int64         `conf:"from-seq" help:"Print ledger statements after this sequence. Defaults to the end of the ledger"`
+	PollInterval  time.Duration `conf:"poll-interval" help:"How often to poll the ledger for new statements"`
+	ChangelogPath string        `conf:"changelog-path" help:"Path to the changelog when tailing the changelog"`
+	Family        string        `conf:"family" help:"Only print changes to this family"`
+	Table         string        `conf:"table" help:"Only print changes to this table. Requires family"`
+	JSON          bool          `conf:"json" help:"Print one JSON object per change"`
+}
+
+// tailChange is how a change from either source is printed.
+type tailChange struct {
+	Seq       int64       `json:"seq"`
+	Timestamp *time.Time  `json:"timestamp,omitempty"`
+	Statement string      `json:"statement,omitempty"`
+	Family    string      `json:"family,omitempty"`
+	Table     string      `json:"table,omitempty"`
+	Keys      []event.Key `json:"keys,omitempty"`
+}
+
+func tail(ctx context.Context, args []string) {
+	cliCfg := tailCliConfig{
+		Source:        "ledger",
+		CtlDBDriver:   "mysql",
+		LedgerTable:   "ctlstore_dml_ledger",
+		FromSeq:       -1,
+		PollInterval:  time.Second,
+		ChangelogPath: "/var/spool/ctlstore/change.log",
+	}
+	loadConfig(&cliCfg, "tail", args)
+
+	var err error
+	switch {
+	case cliCfg.Table != "" && cliCfg.Family == "":
+		err = errors.New("--table requires --family")
+	case cliCfg.Source == "ledger":
+		err = tailLedger(ctx, cliCfg, os.Stdout)
+	case cliCfg.Source == "changelog":
+		err = tailChangelog(ctx, cliCfg, os.Stdout)
+	default:
+		err = errors.Errorf("unknown source %q, expected ledger or changelog", cliCfg.Source)
+	}
+	if err != nil && !errs.IsCanceled(err) && !events.IsTermination(errors.Cause(err)) {
+		events.Log("Fatal error tailing %{source}s: %{error}+v", cliCfg.Source, err)
+		os.Exit(1)
+	}
+}
+
+func tailLedger(ctx context.Context, cliCfg tailCliConfig, out io.Writer) error {
+	if cliCfg.CtlDBDSN == "" {
+		return errors.New("--ctldb is required to tail the ledger")
+	}
+	dsn := cliCfg.CtlDBDSN
+	if cliCfg.CtlDBDriver == "mysql" {
+		var err error
+		dsn, err = ctldb.SetCtldbDSNParameters(dsn)
+		if err != nil {
+			return err
+		}
+	}
+	db, err := sql.Open(cliCfg.CtlDBDriver, dsn)
+	if err != nil {
+		return errors.Wrap(err, "open ctldb")
+	}
+	defer db.Close()
+
+	seq := cliCfg.FromSeq
+	if seq < 0 {
+		var max sql.NullInt64
+		err := db.QueryRowContext(ctx, sqlgen.SqlSprintf("SELECT MAX(seq) FROM $1", cliCfg.LedgerTable)).Scan(&max)
+		if err != nil {
+			return errors.Wrap(err, "find max seq")
+		}
+		seq = max.Int64
+	}
+	filter := ledgerFilter(cliCfg.Family, cliCfg.Table)
+	qs := sqlgen.SqlSprintf("SELECT seq, leader_ts, statement FROM $1 WHERE seq > ? ORDER BY seq LIMIT ?", cliCfg.LedgerTable)
+
+	ticker := time.NewTicker(cliCfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		// keep reading while full pages come back, so that a burst of
+		// statements is printed without waiting a tick per page
+		for {
+			n, err := tailLedgerPage(ctx, db, qs, &seq, filter, cliCfg.JSON, out)
+			if err != nil {
+				return err
+			}
+			if n < tailLedgerPageSize {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// how many ledger statements are read per query
+const tailLedgerPageSize = 100
+
+// tailLedgerPage prints the statements of the page of the ledger after seq,
+// advancing seq past them, and returns how many statements were read.
+func tailLedgerPage(ctx context.Context, db *sql.DB, qs string, seq *int64, filter *regexp.Regexp, asJSON bool, out io.Writer) (int, error) {
+	rows, err := db.QueryContext(ctx, qs, *seq, tailLedgerPageSize)
+	if err != nil {
+		return 0, errors.Wrap(err, "query ledger")
+	}
+	defer rows.Close()
+	n := 0
+	for rows.Next() {
+		var change tailChange
+		var leaderTs sql.NullString
+		if err := rows.Scan(&change.Seq, &leaderTs, &change.Statement); err != nil {
+			return n, errors.Wrap(err, "scan ledger row")
+		}
+		n++
+		*seq = change.Seq
+		if filter != nil && !filter.MatchString(change.Statement) {
+			continue
+		}
+		// mysql returns the timestamp as text, while sqlite returns
+		// a time which database/sql formats as RFC3339
+		for _, layout := range []string{"2006-01-02 15:04:05", time.RFC3339Nano} {
+			if ts, err := time.Parse(layout, leaderTs.String); err == nil {
+				change.Timestamp = &ts
+				break
+			}
+		}
+		if err := printChange(out, change, asJSON); err != nil {
+			return n, err
+		}
+	}
+	return n, errors.Wrap(rows.Err(), "read ledger")
+}
+
+// ledgerFilter returns a regexp matching ledger statements which touch the
+// family and table, or nil if there is no filter. Ledger statements are plain
+// SQL, so this only looks for the LDB table name in the statement.
+// Transaction markers never match a filter.
+func ledgerFilter(family, table string) *regexp.Regexp {
+	if family == "" {
+		return nil
+	}
+	const boundary = "[^a-z0-9_]"
+	pattern := "(?i)(^|" + boundary + ")" + regexp.QuoteMeta(family+"___")
+	if table != "" {
+		pattern += regexp.QuoteMeta(table) + "(" + boundary + "|$)"
+	}
+	return regexp.MustCompile(pattern)
+}
+
+func tailChangelog(ctx context.Context, cliCfg tailCliConfig, out io.Writer) error {
+	iter, err := event.NewIterator(ctx, cliCfg.ChangelogPath)
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+	for {
+		ev, err := iter.Next(ctx)
+		switch {
+		case errors.Cause(err) == event.ErrOutOfSync:
+			// the iterator keeps going after this, so just let the
+			// reader know that some changes were missed
+			events.Log("Changelog skipped to seq %{seq}d, some changes were not printed", ev.Sequence)
+		case err != nil:
+			return err
+		}
+		update := ev.RowUpdate
+		if cliCfg.Family != "" && !strings.EqualFold(update.FamilyName, cliCfg.Family) {
+			continue
+		}
+		if cliCfg.Table != "" && !strings.EqualFold(update.TableName, cliCfg.Table) {
+			continue
+		}
+		err = printChange(out, tailChange{
+			Seq:    ev.Sequence,
+			Family: update.FamilyName,
+			Table:  update.TableName,
+			Keys:   update.Keys,
+		}, cliCfg.JSON)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func printChange(out io.Writer, change tailChange, asJSON bool) error {
+	if asJSON {
+		return json.NewEncoder(out).Encode(change)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d", change.Seq)
+	if change.Timestamp != nil {
+		fmt.Fprintf(&b, " %s", change.Timestamp.Format(time.RFC3339))
+	}
+	if change.Statement != "" {
+		fmt.Fprintf(&b, " %s", change.Statement)
+	} else {
+		fmt.Fprintf(&b, " %s.%s", change.Family, change.Table)
+		for _, key := range change.Keys {
+			fmt.Fprintf(&b, " %s=%v", key.Name, key.Value)
+		}
+	}
+	_, err := fmt.Fprintln(out, b.String())
+	return err
+}
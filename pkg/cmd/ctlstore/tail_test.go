@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/ctldb"
+)
+
+// syncBuffer is written by tailLedger while the test reads it
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) lines() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return strings.Split(strings.TrimSpace(b.buf.String()), "\n")
+}
+
+func newTailTestCtlDB(t *testing.T, statements int) string {
+	path := filepath.Join(t.TempDir(), "ctldb.db")
+	db, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(ctldb.CtlDBSchemaByDriver["sqlite3"])
+	require.NoError(t, err)
+	for i := 1; i <= statements; i++ {
+		table := "table1"
+		if i%2 == 0 {
+			table = "table2"
+		}
+		_, err = db.Exec("INSERT INTO ctlstore_dml_ledger (statement) VALUES(?)",
+			fmt.Sprintf("INSERT INTO family1___%s VALUES(%d);", table, i))
+		require.NoError(t, err)
+	}
+	return path
+}
+
+func runTailLedger(t *testing.T, cliCfg tailCliConfig, expected int) []string {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := &syncBuffer{}
+	done := make(chan error)
+	go func() { done <- tailLedger(ctx, cliCfg, out) }()
+
+	require.Eventually(t, func() bool {
+		return len(out.lines()) >= expected
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	require.Equal(t, context.Canceled, <-done)
+	return out.lines()
+}
+
+func TestTailLedgerPages(t *testing.T) {
+	path := newTailTestCtlDB(t, 2*tailLedgerPageSize+50)
+	lines := runTailLedger(t, tailCliConfig{
+		CtlDBDriver: "sqlite3",
+		CtlDBDSN:    path,
+		LedgerTable: "ctlstore_dml_ledger",
+		FromSeq:     0,
+		// every page has to be read without waiting on the ticker
+		PollInterval: time.Hour,
+	}, 2*tailLedgerPageSize+50)
+
+	require.Len(t, lines, 2*tailLedgerPageSize+50)
+	for i, line := range lines {
+		require.True(t, strings.HasPrefix(line, fmt.Sprintf("%d ", i+1)), line)
+	}
+}
+
+func TestTailLedgerFilter(t *testing.T) {
+	path := newTailTestCtlDB(t, 2*tailLedgerPageSize+50)
+	lines := runTailLedger(t, tailCliConfig{
+		CtlDBDriver:  "sqlite3",
+		CtlDBDSN:     path,
+		LedgerTable:  "ctlstore_dml_ledger",
+		FromSeq:      10,
+		PollInterval: time.Hour,
+		Family:       "family1",
+		Table:        "table2",
+	}, tailLedgerPageSize+20)
+
+	require.Len(t, lines, tailLedgerPageSize+20)
+	for _, line := range lines {
+		require.Contains(t, line, "family1___table2")
+	}
+	require.True(t, strings.HasPrefix(lines[0], "12 "), lines[0])
+}
+
+func TestLedgerFilter(t *testing.T) {
+	for _, test := range []struct {
+		family, table string
+		matches       []string
+		misses        []string
+	}{
+		{
+			family:  "family1",
+			matches: []string{"INSERT INTO family1___table1 VALUES(1);", `DELETE FROM "family1___table2"`},
+			misses:  []string{"INSERT INTO family10___table1 VALUES(1);", "--- BEGIN"},
+		},
+		{
+			family:  "family1",
+			table:   "table1",
+			matches: []string{"INSERT INTO family1___table1 VALUES(1);", "DROP TABLE family1___table1"},
+			misses:  []string{"INSERT INTO family1___table10 VALUES(1);", "INSERT INTO family1___table2 VALUES(1);"},
+		},
+	} {
+		filter := ledgerFilter(test.family, test.table)
+		for _, st := range test.matches {
+			require.True(t, filter.MatchString(st), st)
+		}
+		for _, st := range test.misses {
+			require.False(t, filter.MatchString(st), st)
+		}
+	}
+	require.Nil(t, ledgerFilter("", ""))
+}
@@ -0,0 +1,106 @@
+// Package ctlcrypto makes the hashing choices for ctlstore, such as how
+// writer secrets are stored and how snapshots are checksummed, so that they
+// can be restricted to FIPS approved algorithms in one place.
+package ctlcrypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ErrFIPSUnavailable is returned when FIPS mode is requested but the binary
+// wasn't built with a FIPS validated crypto module, or it isn't enabled.
+var ErrFIPSUnavailable = errors.New("FIPS mode requested but the crypto module is not running in FIPS mode")
+
+// Provider supplies the hash functions ctlstore uses.
+type Provider interface {
+	// HashSecret returns the hex encoded hash of a secret which is stored,
+	// such as a writer secret.
+	HashSecret(secret string) string
+	// NewMAC returns a keyed hash used to sign tokens.
+	NewMAC(key []byte) hash.Hash
+	// NewChecksum returns a hash used to verify the contents of files,
+	// such as LDB snapshots.
+	NewChecksum() hash.Hash
+	// ChecksumAlgorithm names the hash returned by NewChecksum.
+	ChecksumAlgorithm() string
+	// FIPS reports whether the provider only uses FIPS approved algorithms.
+	FIPS() bool
+}
+
+// Config configures the Provider returned by New.
+type Config struct {
+	// FIPSMode restricts hashing to FIPS approved algorithms. It requires
+	// the crypto module to be running in FIPS mode, e.g. a binary built
+	// with GOFIPS140 or run with GODEBUG=fips140=on.
+	FIPSMode bool
+}
+
+// New returns a Provider for the config.
+func New(config Config) (Provider, error) {
+	if !config.FIPSMode {
+		return standardProvider{}, nil
+	}
+	if !fipsModuleEnabled() {
+		return nil, ErrFIPSUnavailable
+	}
+	return fipsProvider{}, nil
+}
+
+var (
+	defaultProvider   Provider = standardProvider{}
+	defaultProviderMu sync.RWMutex
+)
+
+// Configure sets the Provider returned by Default. It should be called once
+// at startup, before anything is hashed.
+func Configure(config Config) error {
+	p, err := New(config)
+	if err != nil {
+		return err
+	}
+	defaultProviderMu.Lock()
+	defer defaultProviderMu.Unlock()
+	defaultProvider = p
+	return nil
+}
+
+// Default returns the process-wide Provider. Unless Configure says
+// otherwise, it's the standard, non-FIPS provider.
+func Default() Provider {
+	defaultProviderMu.RLock()
+	defer defaultProviderMu.RUnlock()
+	return defaultProvider
+}
+
+// standardProvider is what ctlstore has always used. Snapshot checksums are
+// SHA-1, which consumers of existing snapshots expect.
+type standardProvider struct{}
+
+func (standardProvider) HashSecret(secret string) string { return hashSecret(secret) }
+func (standardProvider) NewMAC(key []byte) hash.Hash     { return hmac.New(sha256.New, key) }
+func (standardProvider) NewChecksum() hash.Hash          { return sha1.New() }
+func (standardProvider) ChecksumAlgorithm() string       { return "sha1" }
+func (standardProvider) FIPS() bool                      { return false }
+
+// fipsProvider only uses SHA-256. Secrets and tokens are hashed the same way
+// as by the standard provider, so switching modes doesn't invalidate
+// registered writers.
+type fipsProvider struct{}
+
+func (fipsProvider) HashSecret(secret string) string { return hashSecret(secret) }
+func (fipsProvider) NewMAC(key []byte) hash.Hash     { return hmac.New(sha256.New, key) }
+func (fipsProvider) NewChecksum() hash.Hash          { return sha256.New() }
+func (fipsProvider) ChecksumAlgorithm() string       { return "sha256" }
+func (fipsProvider) FIPS() bool                      { return true }
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
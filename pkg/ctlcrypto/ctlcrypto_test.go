@@ -0,0 +1,37 @@
+package ctlcrypto
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviders(t *testing.T) {
+	std, err := New(Config{})
+	require.NoError(t, err)
+	require.False(t, std.FIPS())
+	require.Equal(t, "sha1", std.ChecksumAlgorithm())
+	// registered writers' secrets must still match
+	require.Equal(t, "2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b", std.HashSecret("secret"))
+
+	fips, err := New(Config{FIPSMode: true})
+	if !fipsModuleEnabled() {
+		require.Equal(t, ErrFIPSUnavailable, err)
+		require.Equal(t, ErrFIPSUnavailable, Configure(Config{FIPSMode: true}))
+		require.Equal(t, std, Default())
+		// build the provider directly to check its choices
+		fips = fipsProvider{}
+	} else {
+		require.NoError(t, err)
+	}
+	require.True(t, fips.FIPS())
+	require.Equal(t, "sha256", fips.ChecksumAlgorithm())
+	require.Equal(t, std.HashSecret("secret"), fips.HashSecret("secret"))
+	h := fips.NewChecksum()
+	h.Write([]byte("snapshot"))
+	sum := sha256.Sum256([]byte("snapshot"))
+	require.Equal(t, hex.EncodeToString(sum[:]), hex.EncodeToString(h.Sum(nil)))
+	require.Equal(t, std.NewMAC([]byte("key")).Sum(nil), fips.NewMAC([]byte("key")).Sum(nil))
+}
@@ -0,0 +1,9 @@
+//go:build !go1.24 && goexperiment.boringcrypto
+
+package ctlcrypto
+
+import "crypto/boring"
+
+func fipsModuleEnabled() bool {
+	return boring.Enabled()
+}
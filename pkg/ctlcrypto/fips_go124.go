@@ -0,0 +1,9 @@
+//go:build go1.24
+
+package ctlcrypto
+
+import "crypto/fips140"
+
+func fipsModuleEnabled() bool {
+	return fips140.Enabled()
+}
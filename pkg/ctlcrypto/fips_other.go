@@ -0,0 +1,8 @@
+//go:build !go1.24 && !goexperiment.boringcrypto
+
+package ctlcrypto
+
+// Before Go 1.24, only boringcrypto builds have a FIPS validated module.
+func fipsModuleEnabled() bool {
+	return false
+}
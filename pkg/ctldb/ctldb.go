@@ -1,9 +1,9 @@
 package ctldb
 
 import (
+	"context"
 	"database/sql"
 	"database/sql/driver"
-	"strings"
 )
 
 const LimiterDBSchemaUp = `
@@ -28,6 +28,76 @@ CREATE TABLE writer_usage (
 	PRIMARY KEY (writer_name, bucket)
 ); `
 
+const WriterGroupsDBSchemaUp = `
+CREATE TABLE writer_groups (
+	group_name VARCHAR(50) NOT NULL, /* same limit as writer names */
+	max_rows_per_minute BIGINT NOT NULL ,
+	PRIMARY KEY (group_name)
+);
+
+CREATE TABLE writer_group_members (
+	writer_name VARCHAR(50) NOT NULL, /* limit pulled from validate.go */
+	group_name VARCHAR(50) NOT NULL,
+	PRIMARY KEY (writer_name)
+);
+
+CREATE TABLE writer_group_usage (
+	group_name VARCHAR(50) NOT NULL,
+	bucket BIGINT NOT NULL,
+	amount BIGINT NOT NULL ,
+	PRIMARY KEY (group_name, bucket)
+); `
+
+const TableSizeSamplesDBSchemaUp = `
+CREATE TABLE table_size_samples (
+	family_name VARCHAR(30) NOT NULL, /* limit pulled from validate.go */
+	table_name  VARCHAR(50) NOT NULL, /* limit pulled from validate.go */
+	sampled_at BIGINT NOT NULL, /* unix seconds */
+	size_bytes BIGINT NOT NULL,
+	PRIMARY KEY (family_name, table_name, sampled_at)
+); `
+
+const TableTemplatesDBSchemaUp = `
+CREATE TABLE table_templates (
+	family_name VARCHAR(191) NOT NULL,
+	template_name VARCHAR(191) NOT NULL,
+	definition TEXT NOT NULL, /* JSON encoded schema.TableTemplate */
+	PRIMARY KEY (family_name, template_name)
+); `
+
+const ExportJobsDBSchemaUp = `
+CREATE TABLE export_jobs (
+	id VARCHAR(64) NOT NULL PRIMARY KEY,
+	family_name VARCHAR(191) NOT NULL,
+	table_name VARCHAR(191) NOT NULL,
+	format VARCHAR(16) NOT NULL,
+	destination VARCHAR(1024) NOT NULL,
+	state VARCHAR(16) NOT NULL,
+	rows_exported BIGINT NOT NULL DEFAULT 0,
+	error TEXT,
+	created_at BIGINT NOT NULL, /* unix seconds */
+	updated_at BIGINT NOT NULL /* unix seconds */
+); `
+
+const MaintenanceDBSchemaUp = `
+CREATE TABLE maintenance (
+	id VARCHAR(32) NOT NULL PRIMARY KEY,
+	enabled INTEGER NOT NULL DEFAULT 0,
+	reason VARCHAR(1024) NOT NULL DEFAULT '',
+	updated_at BIGINT NOT NULL DEFAULT 0 /* unix seconds */
+); `
+
+const SupervisorLeasesDBSchemaUp = `
+CREATE TABLE supervisor_leases (
+	name VARCHAR(191) NOT NULL PRIMARY KEY,
+	holder VARCHAR(191) NOT NULL,
+	expires_at BIGINT NOT NULL /* unix milliseconds */
+); `
+
+// CtlDBSchemaByDriver is the initial schema of the ctldb, which is its
+// first migration. It must not be changed, since ctldbs which predate
+// migrations are assumed to have it. Later changes to the schema are made
+// by adding to Migrations.
 var CtlDBSchemaByDriver = map[string]string{
 	"mysql": `
 
@@ -89,21 +159,8 @@ INSERT INTO locks VALUES('ledger', 0);
 ` + LimiterDBSchemaUp,
 }
 
+// InitializeCtlDB creates the ctldb's schema by applying every migration.
 func InitializeCtlDB(db *sql.DB, driverFunc func(driver driver.Driver) (name string)) error {
-	driverName := driverFunc(db.Driver())
-	schema := CtlDBSchemaByDriver[driverName]
-	statements := strings.Split(schema, ";")
-
-	for _, statement := range statements {
-		tsql := strings.TrimSpace(statement)
-		if tsql == "" {
-			continue
-		}
-		_, err := db.Exec(tsql)
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	_, err := Migrate(context.Background(), db, driverFunc(db.Driver()))
+	return err
 }
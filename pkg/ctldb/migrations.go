@@ -0,0 +1,480 @@
+package ctldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MigrationsTableName is the table which records the migrations that have
+// been applied to the ctldb.
+const MigrationsTableName = "ctldb_migrations"
+
+// migrationLockName is the MySQL named lock held while migrating, so that
+// executives which start at the same time don't both apply a migration.
+const migrationLockName = "ctldb_migrations"
+
+// migrationLockTimeout is how long to wait for another executive to finish
+// migrating the ctldb.
+const migrationLockTimeout = 10 * time.Minute
+
+// Migration is a versioned change to the ctldb's schema. Up holds the
+// semicolon separated statements which apply it, keyed by driver name.
+type Migration struct {
+	Version int
+	Name    string
+	Up      map[string]string
+}
+
+// Migrations are the changes to the ctldb's schema, in the order they're
+// applied. A schema change is made by appending a migration with the next
+// version. A migration must never be changed once it has been released,
+// since ctldbs which have already applied it won't apply it again.
+var Migrations = []Migration{
+	{Version: 1, Name: "initial schema", Up: CtlDBSchemaByDriver},
+	{Version: 2, Name: "table templates", Up: map[string]string{
+		"mysql":   TableTemplatesDBSchemaUp,
+		"sqlite3": TableTemplatesDBSchemaUp,
+	}},
+	{Version: 3, Name: "writer activity", Up: map[string]string{
+		"mysql":   writerActivitySchemaUp,
+		"sqlite3": writerActivitySchemaUp,
+	}},
+	{Version: 4, Name: "writer groups", Up: map[string]string{
+		"mysql":   WriterGroupsDBSchemaUp,
+		"sqlite3": WriterGroupsDBSchemaUp,
+	}},
+	{Version: 5, Name: "export jobs", Up: map[string]string{
+		"mysql":   ExportJobsDBSchemaUp,
+		"sqlite3": ExportJobsDBSchemaUp,
+	}},
+	{Version: 6, Name: "maintenance mode", Up: map[string]string{
+		"mysql":   MaintenanceDBSchemaUp,
+		"sqlite3": MaintenanceDBSchemaUp,
+	}},
+	{Version: 7, Name: "table size samples", Up: map[string]string{
+		"mysql":   TableSizeSamplesDBSchemaUp,
+		"sqlite3": TableSizeSamplesDBSchemaUp,
+	}},
+	{Version: 8, Name: "ledger trace ids", Up: map[string]string{
+		"mysql":   ledgerTraceIDsSchemaUp,
+		"sqlite3": ledgerTraceIDsSchemaUp,
+	}},
+	{Version: 9, Name: "supervisor leases", Up: map[string]string{
+		"mysql":   SupervisorLeasesDBSchemaUp,
+		"sqlite3": SupervisorLeasesDBSchemaUp,
+	}},
+	{Version: 10, Name: "writer creation times", Up: map[string]string{
+		"mysql":   writerCreationTimesSchemaUp,
+		"sqlite3": writerCreationTimesSchemaUp,
+	}},
+	{Version: 11, Name: "writer rate limit bursts", Up: map[string]string{
+		"mysql":   writerBurstsSchemaUp,
+		"sqlite3": writerBurstsSchemaUp,
+	}},
+	{Version: 12, Name: "webhooks", Up: map[string]string{
+		"mysql":   webhooksSchemaUp,
+		"sqlite3": webhooksSchemaUp,
+	}},
+	{Version: 13, Name: "field references", Up: map[string]string{
+		"mysql":   fieldReferencesSchemaUp,
+		"sqlite3": fieldReferencesSchemaUp,
+	}},
+	{Version: 14, Name: "api tokens", Up: map[string]string{
+		"mysql":   apiTokensSchemaUp,
+		"sqlite3": apiTokensSchemaUp,
+	}},
+	{Version: 15, Name: "family defaults", Up: map[string]string{
+		"mysql":   familyDefaultsSchemaUp,
+		"sqlite3": familyDefaultsSchemaUp,
+	}},
+	{Version: 16, Name: "disabled writers", Up: map[string]string{
+		"mysql":   disabledWritersSchemaUp,
+		"sqlite3": disabledWritersSchemaUp,
+	}},
+	{Version: 17, Name: "writer registrations", Up: map[string]string{
+		"mysql":   writerRegistrationsSchemaUp + writerRegistrationAuditSchemaUpForMySQL,
+		"sqlite3": writerRegistrationsSchemaUp + writerRegistrationAuditSchemaUpForSQLite3,
+	}},
+	{Version: 18, Name: "unique constraints", Up: map[string]string{
+		"mysql":   uniqueConstraintsSchemaUp,
+		"sqlite3": uniqueConstraintsSchemaUp,
+	}},
+	{Version: 19, Name: "writer group audit", Up: map[string]string{
+		"mysql":   writerGroupAuditSchemaUpForMySQL,
+		"sqlite3": writerGroupAuditSchemaUpForSQLite3,
+	}},
+	{Version: 20, Name: "ledger statement versions", Up: map[string]string{
+		"mysql":   ledgerVersionsSchemaUp,
+		"sqlite3": ledgerVersionsSchemaUp,
+	}},
+}
+
+// writerActivitySchemaUp records when each writer last mutated, from where,
+// and how many mutations it has made.
+const writerActivitySchemaUp = `
+ALTER TABLE mutators ADD COLUMN last_mutation_at BIGINT NOT NULL DEFAULT 0 /* unix seconds */;
+
+ALTER TABLE mutators ADD COLUMN last_source_ip VARCHAR(64) NOT NULL DEFAULT '';
+
+ALTER TABLE mutators ADD COLUMN mutation_count BIGINT NOT NULL DEFAULT 0; `
+
+// ledgerTraceIDsSchemaUp records the trace of the request which wrote each
+// ledger statement.
+const ledgerTraceIDsSchemaUp = `
+ALTER TABLE ctlstore_dml_ledger ADD COLUMN trace_id VARCHAR(32); `
+
+// ledgerVersionsSchemaUp records how each ledger statement is encoded. See
+// schema.DMLVersionSQL. Statements written before it are SQL.
+const ledgerVersionsSchemaUp = `
+ALTER TABLE ctlstore_dml_ledger ADD COLUMN version INTEGER NOT NULL DEFAULT 1; `
+
+// writerCreationTimesSchemaUp records when writers were registered. It's
+// zero for writers registered before it was recorded.
+const writerCreationTimesSchemaUp = `
+ALTER TABLE mutators ADD COLUMN created_at BIGINT NOT NULL DEFAULT 0 /* unix seconds */; `
+
+// writerBurstsSchemaUp adds the bursts of writer and group rate limits, and
+// the token buckets which enforce them.
+const writerBurstsSchemaUp = `
+ALTER TABLE max_writer_rates ADD COLUMN burst BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE writer_groups ADD COLUMN burst BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE writer_tokens (
+	writer_name VARCHAR(50) NOT NULL PRIMARY KEY,
+	tokens DOUBLE NOT NULL,
+	refilled_at BIGINT NOT NULL /* unix nanoseconds */
+);
+
+CREATE TABLE writer_group_tokens (
+	group_name VARCHAR(50) NOT NULL PRIMARY KEY,
+	tokens DOUBLE NOT NULL,
+	refilled_at BIGINT NOT NULL /* unix nanoseconds */
+); `
+
+// webhooksSchemaUp adds the webhooks which the executive notifies of changes
+// to their family.
+const webhooksSchemaUp = `
+CREATE TABLE webhooks (
+	id VARCHAR(64) NOT NULL PRIMARY KEY,
+	family_name VARCHAR(191) NOT NULL,
+	url VARCHAR(2048) NOT NULL,
+	secret VARCHAR(255) NOT NULL,
+	created_at BIGINT NOT NULL /* unix seconds */
+);
+
+CREATE INDEX webhooks_family_name ON webhooks (family_name); `
+
+// fieldReferencesSchemaUp adds the references of fields to the key fields of
+// other tables, and the tables whose mutations are validated against them.
+const fieldReferencesSchemaUp = `
+CREATE TABLE field_references (
+	family_name VARCHAR(191) NOT NULL,
+	table_name VARCHAR(191) NOT NULL,
+	field_name VARCHAR(191) NOT NULL,
+	ref_family_name VARCHAR(191) NOT NULL,
+	ref_table_name VARCHAR(191) NOT NULL,
+	ref_field_name VARCHAR(191) NOT NULL,
+	PRIMARY KEY (family_name, table_name, field_name)
+);
+
+CREATE INDEX field_references_ref_table ON field_references (ref_family_name, ref_table_name);
+
+CREATE TABLE strict_reference_tables (
+	family_name VARCHAR(191) NOT NULL,
+	table_name VARCHAR(191) NOT NULL,
+	PRIMARY KEY (family_name, table_name)
+); `
+
+// apiTokensSchemaUp adds the read-only API tokens, of which only the SHA-256
+// is stored.
+const apiTokensSchemaUp = `
+CREATE TABLE api_tokens (
+	name VARCHAR(191) NOT NULL PRIMARY KEY,
+	token_hash CHAR(64) NOT NULL UNIQUE,
+	created_at BIGINT NOT NULL /* unix seconds */
+); `
+
+// familyDefaultsSchemaUp adds the defaults which are applied to the tables
+// created in a family.
+const familyDefaultsSchemaUp = `
+CREATE TABLE family_defaults (
+	family_name VARCHAR(191) NOT NULL PRIMARY KEY,
+	definition TEXT NOT NULL /* JSON encoded executive.FamilyDefaults */
+); `
+
+// disabledWritersSchemaUp records when writers were disabled for being idle,
+// and when they were last re-enabled, which restarts their idle period.
+const disabledWritersSchemaUp = `
+ALTER TABLE mutators ADD COLUMN disabled_at BIGINT NOT NULL DEFAULT 0 /* unix seconds */;
+
+ALTER TABLE mutators ADD COLUMN enabled_at BIGINT NOT NULL DEFAULT 0 /* unix seconds */; `
+
+// writerRegistrationsSchemaUp adds the requests to register writers, which
+// are approved or rejected by an admin, and the audit records of them.
+const writerRegistrationsSchemaUp = `
+CREATE TABLE writer_registrations (
+	writer_name VARCHAR(191) NOT NULL PRIMARY KEY,
+	secret VARCHAR(255) NOT NULL, /* hashed like the mutators' secrets */
+	description VARCHAR(1024) NOT NULL DEFAULT '',
+	status VARCHAR(16) NOT NULL,
+	requested_at BIGINT NOT NULL, /* unix seconds */
+	reviewed_at BIGINT NOT NULL DEFAULT 0, /* unix seconds */
+	reviewer VARCHAR(191) NOT NULL DEFAULT '',
+	review_reason VARCHAR(1024) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX writer_registrations_status ON writer_registrations (status); `
+
+const writerRegistrationAuditSchemaUpForMySQL = `
+CREATE TABLE writer_registration_audit (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	writer_name VARCHAR(191) NOT NULL,
+	action VARCHAR(16) NOT NULL,
+	actor VARCHAR(191) NOT NULL DEFAULT '',
+	reason VARCHAR(1024) NOT NULL DEFAULT '',
+	source_ip VARCHAR(64) NOT NULL DEFAULT '',
+	created_at BIGINT NOT NULL /* unix seconds */
+);
+
+CREATE INDEX writer_registration_audit_writer_name ON writer_registration_audit (writer_name); `
+
+const writerRegistrationAuditSchemaUpForSQLite3 = `
+CREATE TABLE writer_registration_audit (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	writer_name VARCHAR(191) NOT NULL,
+	action VARCHAR(16) NOT NULL,
+	actor VARCHAR(191) NOT NULL DEFAULT '',
+	reason VARCHAR(1024) NOT NULL DEFAULT '',
+	source_ip VARCHAR(64) NOT NULL DEFAULT '',
+	created_at INTEGER NOT NULL /* unix seconds */
+);
+
+CREATE INDEX writer_registration_audit_writer_name ON writer_registration_audit (writer_name); `
+
+// uniqueConstraintsSchemaUp adds the fields of the tables' unique
+// constraints, which the executive checks upserts against.
+const uniqueConstraintsSchemaUp = `
+CREATE TABLE unique_constraints (
+	family_name VARCHAR(191) NOT NULL,
+	table_name VARCHAR(191) NOT NULL,
+	constraint_num INTEGER NOT NULL,
+	position INTEGER NOT NULL,
+	field_name VARCHAR(191) NOT NULL,
+	PRIMARY KEY (family_name, table_name, constraint_num, position)
+); `
+
+var migrationsTableDDL = `CREATE TABLE IF NOT EXISTS ` + MigrationsTableName + ` (
+	version INTEGER NOT NULL PRIMARY KEY,
+	name VARCHAR(191) NOT NULL,
+	applied_at BIGINT NOT NULL /* unix seconds */
+)`
+
+// Migrate applies the migrations which haven't been applied to the ctldb,
+// in order, and returns them. Migrations are applied under a lock, so it's
+// safe for several executives to migrate the ctldb at once.
+//
+// A ctldb which was initialized before migrations were recorded is assumed
+// to have the initial schema, which is recorded as applied rather than
+// being applied again.
+//
+// On sqlite3, the migrations are applied in one transaction, so none are
+// applied if any fails. MySQL can't roll back schema changes, so a
+// migration which fails part of the way through has to be finished by hand
+// before migrating again.
+func Migrate(ctx context.Context, db *sql.DB, driverName string) (applied []Migration, err error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	unlock, err := lockMigrations(ctx, conn, driverName)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if unlockErr := unlock(err == nil); err == nil && unlockErr != nil {
+			applied, err = nil, unlockErr
+		}
+	}()
+
+	if _, err := conn.ExecContext(ctx, migrationsTableDDL); err != nil {
+		return nil, fmt.Errorf("create migrations table: %w", err)
+	}
+	if err := baselineMigrations(ctx, conn, driverName); err != nil {
+		return nil, err
+	}
+	pending, err := pendingMigrations(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range pending {
+		if err := applyMigration(ctx, conn, driverName, m); err != nil {
+			return nil, err
+		}
+	}
+	return pending, nil
+}
+
+// lockMigrations takes the MySQL named lock, or on sqlite3 begins a write
+// transaction, which unlock commits or rolls back.
+func lockMigrations(ctx context.Context, conn *sql.Conn, driverName string) (unlock func(commit bool) error, err error) {
+	switch driverName {
+	case "mysql":
+		var locked sql.NullInt64
+		err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", migrationLockName, int(migrationLockTimeout.Seconds())).Scan(&locked)
+		if err != nil {
+			return nil, fmt.Errorf("lock migrations: %w", err)
+		}
+		if locked.Int64 != 1 {
+			return nil, fmt.Errorf("lock migrations: timed out after %v", migrationLockTimeout)
+		}
+		return func(bool) error {
+			_, err := conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", migrationLockName)
+			return err
+		}, nil
+	case "sqlite3":
+		if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+			return nil, fmt.Errorf("lock migrations: %w", err)
+		}
+		return func(commit bool) error {
+			if !commit {
+				_, err := conn.ExecContext(context.Background(), "ROLLBACK")
+				return err
+			}
+			if _, err := conn.ExecContext(context.Background(), "COMMIT"); err != nil {
+				return fmt.Errorf("commit migrations: %w", err)
+			}
+			return nil
+		}, nil
+	default:
+		return nil, fmt.Errorf("migrations aren't supported on %q", driverName)
+	}
+}
+
+// PendingMigrations returns the migrations which haven't been applied to
+// the ctldb, without applying them or taking the migration lock. A ctldb
+// which was initialized before migrations were recorded is taken to have
+// the initial schema, as it is by Migrate.
+func PendingMigrations(ctx context.Context, db *sql.DB, driverName string) ([]Migration, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	recorded, err := tableExists(ctx, conn, driverName, MigrationsTableName)
+	if err != nil {
+		return nil, err
+	}
+	if recorded {
+		return pendingMigrations(ctx, conn)
+	}
+	initialized, err := tableExists(ctx, conn, driverName, "families")
+	if err != nil {
+		return nil, err
+	}
+	if initialized {
+		return Migrations[1:], nil
+	}
+	return Migrations, nil
+}
+
+// baselineMigrations records the initial schema as applied to a ctldb which
+// was initialized before migrations were recorded.
+func baselineMigrations(ctx context.Context, conn *sql.Conn, driverName string) error {
+	var recorded int
+	err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+MigrationsTableName).Scan(&recorded)
+	if err != nil || recorded > 0 {
+		return err
+	}
+	initialized, err := tableExists(ctx, conn, driverName, "families")
+	if err != nil || !initialized {
+		return err
+	}
+	return recordMigration(ctx, conn, Migrations[0])
+}
+
+func tableExists(ctx context.Context, conn *sql.Conn, driverName string, name string) (bool, error) {
+	query := "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = database() AND table_name = ?"
+	if driverName == "sqlite3" {
+		query = "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?"
+	}
+	var n int
+	if err := conn.QueryRowContext(ctx, query, name).Scan(&n); err != nil {
+		return false, fmt.Errorf("check for table %s: %w", name, err)
+	}
+	return n > 0, nil
+}
+
+func pendingMigrations(ctx context.Context, conn *sql.Conn) ([]Migration, error) {
+	var applied int
+	err := conn.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM "+MigrationsTableName).Scan(&applied)
+	if err != nil {
+		return nil, fmt.Errorf("read applied migrations: %w", err)
+	}
+	for i, m := range Migrations {
+		if m.Version > applied {
+			return Migrations[i:], nil
+		}
+	}
+	return nil, nil
+}
+
+// applyMigration runs the migration's statements and records it.
+func applyMigration(ctx context.Context, conn *sql.Conn, driverName string, m Migration) error {
+	up, ok := m.Up[driverName]
+	if !ok {
+		return fmt.Errorf("migration %d (%s) has no statements for %q", m.Version, m.Name, driverName)
+	}
+	for _, statement := range strings.Split(up, ";") {
+		tsql := strings.TrimSpace(statement)
+		if tsql == "" {
+			continue
+		}
+		if _, err := conn.ExecContext(ctx, tsql); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", m.Version, m.Name, err)
+		}
+	}
+	return recordMigration(ctx, conn, m)
+}
+
+func recordMigration(ctx context.Context, conn *sql.Conn, m Migration) error {
+	_, err := conn.ExecContext(ctx,
+		"INSERT INTO "+MigrationsTableName+" (version, name, applied_at) VALUES (?, ?, ?)",
+		m.Version, m.Name, time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("record migration %d (%s): %w", m.Version, m.Name, err)
+	}
+	return nil
+}
+
+// writerGroupAuditSchemaUpForMySQL adds the audit records of writers being
+// added to and removed from writer groups.
+const writerGroupAuditSchemaUpForMySQL = `
+CREATE TABLE writer_group_audit (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	group_name VARCHAR(50) NOT NULL,
+	writer_name VARCHAR(50) NOT NULL,
+	action VARCHAR(16) NOT NULL,
+	source_ip VARCHAR(64) NOT NULL DEFAULT '',
+	created_at BIGINT NOT NULL /* unix seconds */
+);
+
+CREATE INDEX writer_group_audit_group_name ON writer_group_audit (group_name); `
+
+const writerGroupAuditSchemaUpForSQLite3 = `
+CREATE TABLE writer_group_audit (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	group_name VARCHAR(50) NOT NULL,
+	writer_name VARCHAR(50) NOT NULL,
+	action VARCHAR(16) NOT NULL,
+	source_ip VARCHAR(64) NOT NULL DEFAULT '',
+	created_at INTEGER NOT NULL /* unix seconds */
+);
+
+CREATE INDEX writer_group_audit_group_name ON writer_group_audit (group_name); `
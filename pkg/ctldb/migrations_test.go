@@ -0,0 +1,136 @@
+package ctldb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "github.com/segmentio/go-sqlite3"
+	"github.com/stretchr/testify/require"
+)
+
+func openTestSQLite(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "ctldb.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func appliedVersions(t *testing.T, db *sql.DB) []int {
+	rows, err := db.Query("SELECT version FROM " + MigrationsTableName + " ORDER BY version")
+	require.NoError(t, err)
+	defer rows.Close()
+	var versions []int
+	for rows.Next() {
+		var v int
+		require.NoError(t, rows.Scan(&v))
+		versions = append(versions, v)
+	}
+	require.NoError(t, rows.Err())
+	return versions
+}
+
+func allVersions() []int {
+	var versions []int
+	for _, m := range Migrations {
+		versions = append(versions, m.Version)
+	}
+	return versions
+}
+
+func TestMigrationVersions(t *testing.T) {
+	for i, m := range Migrations {
+		require.Equal(t, i+1, m.Version, m.Name)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	db := openTestSQLite(t)
+
+	applied, err := Migrate(ctx, db, "sqlite3")
+	require.NoError(t, err)
+	require.Equal(t, Migrations, applied)
+	require.Equal(t, allVersions(), appliedVersions(t, db))
+	var n int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM locks").Scan(&n))
+	require.Equal(t, 1, n)
+	_, err = db.Exec("INSERT INTO writer_groups (group_name, max_rows_per_minute, burst) VALUES ('group', 60, 10)")
+	require.NoError(t, err)
+
+	applied, err = Migrate(ctx, db, "sqlite3")
+	require.NoError(t, err)
+	require.Empty(t, applied)
+}
+
+func TestMigrateBaseline(t *testing.T) {
+	ctx := context.Background()
+	db := openTestSQLite(t)
+	// a ctldb initialized before migrations were recorded
+	for _, statement := range strings.Split(CtlDBSchemaByDriver["sqlite3"], ";") {
+		if strings.TrimSpace(statement) == "" {
+			continue
+		}
+		_, err := db.Exec(statement)
+		require.NoError(t, err)
+	}
+
+	pending, err := PendingMigrations(ctx, db, "sqlite3")
+	require.NoError(t, err)
+	require.Equal(t, Migrations[1:], pending)
+
+	applied, err := Migrate(ctx, db, "sqlite3")
+	require.NoError(t, err)
+	require.Equal(t, Migrations[1:], applied)
+	require.Equal(t, allVersions(), appliedVersions(t, db))
+
+	// the tables and columns added since the initial schema exist
+	for _, statement := range []string{
+		"INSERT INTO maintenance (id, enabled) VALUES ('executive', 1)",
+		"INSERT INTO writer_groups (group_name, max_rows_per_minute, burst) VALUES ('group', 60, 10)",
+		"INSERT INTO mutators (writer, secret, cookie, last_mutation_at, last_source_ip, mutation_count, created_at) VALUES ('w', 's', x'00', 1, '10.0.0.1', 1, 1)",
+		"INSERT INTO ctlstore_dml_ledger (statement, trace_id, version) VALUES ('statement', 'trace', 2)",
+		"INSERT INTO supervisor_leases (name, holder, expires_at) VALUES ('snapshots', 'host', 0)",
+		"INSERT INTO writer_group_audit (group_name, writer_name, action, created_at) VALUES ('group', 'w', 'added', 1)",
+	} {
+		_, err := db.Exec(statement)
+		require.NoError(t, err, statement)
+	}
+
+	pending, err = PendingMigrations(ctx, db, "sqlite3")
+	require.NoError(t, err)
+	require.Empty(t, pending)
+}
+
+func TestPendingMigrationsUninitialized(t *testing.T) {
+	pending, err := PendingMigrations(context.Background(), openTestSQLite(t), "sqlite3")
+	require.NoError(t, err)
+	require.Equal(t, Migrations, pending)
+}
+
+func TestMigrateFailureRollsBack(t *testing.T) {
+	ctx := context.Background()
+	db := openTestSQLite(t)
+	require.NoError(t, InitializeCtlDB(db, func(_ driver.Driver) string { return "sqlite3" }))
+
+	versions := allVersions()
+	defer func(m []Migration) { Migrations = m }(Migrations)
+	Migrations = append(Migrations[:len(Migrations):len(Migrations)],
+		Migration{Version: len(Migrations) + 1, Name: "add widgets", Up: map[string]string{
+			"sqlite3": "CREATE TABLE widgets (id INTEGER PRIMARY KEY); ALTER TABLE missing ADD COLUMN x INTEGER",
+		}})
+
+	_, err := Migrate(ctx, db, "sqlite3")
+	require.EqualError(t, err, fmt.Sprintf("migration %d (add widgets): no such table: missing", len(Migrations)))
+	require.Equal(t, versions, appliedVersions(t, db))
+	var n int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'widgets'").Scan(&n))
+	require.Equal(t, 0, n)
+
+	_, err = Migrate(ctx, db, "mysql8")
+	require.EqualError(t, err, `migrations aren't supported on "mysql8"`)
+}
@@ -3,6 +3,7 @@ package errs
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/segmentio/errors-go"
 	"github.com/segmentio/stats/v4"
@@ -86,14 +87,44 @@ func (e PayloadTooLargeError) Error() string {
 	return e.Err
 }
 
-type RateLimitExceededErr baseError
+// LimitDetails describes the limit a request was rejected for, so that
+// clients can tell being throttled apart from running out of space.
+type LimitDetails struct {
+	// Limit is the maximum allowed: mutations per period for rate limits,
+	// and bytes for table size limits.
+	Limit int64 `json:"limit"`
+	// Current is the usage the request was rejected at.
+	Current int64 `json:"current"`
+	// ResetAt is when the usage resets. It's nil for table size limits,
+	// which are only relieved by deleting rows.
+	ResetAt *time.Time `json:"resetAt,omitempty"`
+}
+
+type RateLimitExceededErr struct {
+	Err     string
+	Details *LimitDetails // optional
+}
 
 func (e RateLimitExceededErr) Error() string {
 	return e.Err
 }
 
-type InsufficientStorageErr baseError
+type InsufficientStorageErr struct {
+	Err     string
+	Details *LimitDetails // optional
+}
 
 func (e InsufficientStorageErr) Error() string {
 	return e.Err
 }
+
+// ServiceUnavailableErr is returned for requests that can't be served right
+// now but may be retried, such as mutations during maintenance.
+type ServiceUnavailableErr struct {
+	Err        string
+	RetryAfter time.Duration // optional
+}
+
+func (e ServiceUnavailableErr) Error() string {
+	return e.Err
+}
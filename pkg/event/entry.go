@@ -4,16 +4,24 @@ package event
 // e.g.
 //   {"seq":1,"family":"fam","table":"foo","key":[{"name":"id","type":"int","value":1}]}
 type entry struct {
-	Seq    int64  `json:"seq"`
-	Family string `json:"family"`
-	Table  string `json:"table"`
-	Key    []Key  `json:"key"`
+	Seq       int64  `json:"seq"`
+	Family    string `json:"family"`
+	Table     string `json:"table"`
+	Key       []Key  `json:"key"`
+	LedgerSeq int64  `json:"ledgerSeq,omitempty"`
+	LedgerID  int    `json:"ledgerId,omitempty"`
+	TxSeq     int64  `json:"txSeq,omitempty"`
+	TxEnd     bool   `json:"txEnd,omitempty"`
 }
 
 // event converts the entry into an event for the iterator to return
 func (e entry) event() Event {
 	return Event{
-		Sequence: e.Seq,
+		Sequence:  e.Seq,
+		LedgerSeq: e.LedgerSeq,
+		LedgerID:  e.LedgerID,
+		TxSeq:     e.TxSeq,
+		TxEnd:     e.TxEnd,
 		RowUpdate: RowUpdate{
 			FamilyName: e.Family,
 			TableName:  e.Table,
@@ -2,7 +2,19 @@ package event
 
 // Event is the type that the Iterator produces
 type Event struct {
-	Sequence  int64
+	Sequence int64
+	// LedgerSeq is the sequence of the ledger statement which made the
+	// change. It's zero for changelogs written by older reflectors.
+	LedgerSeq int64
+	// LedgerID identifies the ledger of LedgerSeq, when the ctldb is sharded
+	// into several ledgers whose sequences overlap.
+	LedgerID int
+	// TxSeq is the ledger sequence of the ledger transaction the change was
+	// made in, if any.
+	TxSeq int64
+	// TxEnd marks the last change of a statement or ledger transaction, after
+	// which a consumer can save its offset. See ConsumerOffset.
+	TxEnd     bool
 	RowUpdate RowUpdate
 }
 
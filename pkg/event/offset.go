@@ -0,0 +1,112 @@
+package event
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/segmentio/errors-go"
+)
+
+// ConsumerOffset persists the ledger sequences that a changelog consumer has
+// processed up to, for each ledger, so that after a restart it can skip the events it has
+// already processed, which the changelog may replay. Offsets only advance
+// at the end of a statement or ledger transaction, so a consumer never
+// skips the rest of one that it was part way through.
+//
+// A typical consumer looks like:
+//
+//	offset, err := event.LoadConsumerOffset(path)
+//	...
+//	for {
+//		e, err := iter.Next(ctx)
+//		...
+//		if offset.Seen(e) {
+//			continue
+//		}
+//		process(e)
+//		if err := offset.Processed(e); err != nil {
+//			...
+//		}
+//	}
+type ConsumerOffset struct {
+	path string
+	// the ledger sequences processed, by ledger ID. Sharded ctldbs have a
+	// ledger each, whose sequences overlap.
+	ledgerSeqs map[int]int64
+}
+
+type consumerOffsetFile struct {
+	// LedgerSeq is ledger 0's sequence, which is all that offsets saved
+	// before ledger IDs were recorded have.
+	LedgerSeq  int64         `json:"ledgerSeq"`
+	LedgerSeqs map[int]int64 `json:"ledgerSeqs,omitempty"`
+	SavedAt    time.Time     `json:"savedAt"`
+}
+
+// LoadConsumerOffset reads the offset saved at path. A missing file starts
+// the consumer from the beginning.
+func LoadConsumerOffset(path string) (*ConsumerOffset, error) {
+	o := &ConsumerOffset{path: path, ledgerSeqs: map[int]int64{}}
+	b, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		return o, nil
+	case err != nil:
+		return nil, errors.Wrap(err, "read consumer offset")
+	}
+	var f consumerOffsetFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, errors.Wrap(err, "decode consumer offset")
+	}
+	for ledgerID, seq := range f.LedgerSeqs {
+		o.ledgerSeqs[ledgerID] = seq
+	}
+	if f.LedgerSeq != 0 {
+		o.ledgerSeqs[0] = f.LedgerSeq
+	}
+	return o, nil
+}
+
+// LedgerSeq returns the sequence of the ledger which has been processed.
+func (o *ConsumerOffset) LedgerSeq(ledgerID int) int64 {
+	return o.ledgerSeqs[ledgerID]
+}
+
+// Seen returns whether the event was processed before the offset was
+// saved. Events without a ledger sequence are never seen.
+func (o *ConsumerOffset) Seen(e Event) bool {
+	return e.LedgerSeq != 0 && e.LedgerSeq <= o.ledgerSeqs[e.LedgerID]
+}
+
+// Processed records that the event has been processed, saving the offset
+// if the event ends a statement or ledger transaction.
+func (o *ConsumerOffset) Processed(e Event) error {
+	if !e.TxEnd || e.LedgerSeq <= o.ledgerSeqs[e.LedgerID] {
+		return nil
+	}
+	ledgerSeqs := make(map[int]int64, len(o.ledgerSeqs)+1)
+	for ledgerID, seq := range o.ledgerSeqs {
+		ledgerSeqs[ledgerID] = seq
+	}
+	ledgerSeqs[e.LedgerID] = e.LedgerSeq
+	b, err := json.Marshal(consumerOffsetFile{
+		LedgerSeq:  ledgerSeqs[0],
+		LedgerSeqs: ledgerSeqs,
+		SavedAt:    time.Now(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "encode consumer offset")
+	}
+	// the file is replaced atomically so that a crash never leaves a
+	// partial offset behind
+	tmpPath := o.path + ".tmp"
+	if err := os.WriteFile(tmpPath, b, 0644); err != nil {
+		return errors.Wrap(err, "write consumer offset")
+	}
+	if err := os.Rename(tmpPath, o.path); err != nil {
+		return errors.Wrap(err, "rename consumer offset")
+	}
+	o.ledgerSeqs = ledgerSeqs
+	return nil
+}
@@ -0,0 +1,54 @@
+package event
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsumerOffset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "offset.json")
+	offset, err := LoadConsumerOffset(path)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, offset.LedgerSeq(0))
+
+	// the offset only advances at the end of a transaction
+	require.False(t, offset.Seen(Event{LedgerSeq: 10, TxSeq: 9}))
+	require.NoError(t, offset.Processed(Event{LedgerSeq: 10, TxSeq: 9}))
+	require.EqualValues(t, 0, offset.LedgerSeq(0))
+	require.NoError(t, offset.Processed(Event{LedgerSeq: 11, TxSeq: 9, TxEnd: true}))
+	require.EqualValues(t, 11, offset.LedgerSeq(0))
+
+	// and is restored after a restart
+	offset, err = LoadConsumerOffset(path)
+	require.NoError(t, err)
+	require.EqualValues(t, 11, offset.LedgerSeq(0))
+	require.True(t, offset.Seen(Event{LedgerSeq: 10}))
+	require.True(t, offset.Seen(Event{LedgerSeq: 11}))
+	require.False(t, offset.Seen(Event{LedgerSeq: 12}))
+	// events from older reflectors have no ledger seq
+	require.False(t, offset.Seen(Event{Sequence: 1}))
+
+	// the sequences of sharded ledgers overlap, so each has its own offset
+	require.False(t, offset.Seen(Event{LedgerSeq: 5, LedgerID: 1}))
+	require.NoError(t, offset.Processed(Event{LedgerSeq: 5, LedgerID: 1, TxEnd: true}))
+	require.True(t, offset.Seen(Event{LedgerSeq: 5, LedgerID: 1}))
+	require.False(t, offset.Seen(Event{LedgerSeq: 6, LedgerID: 1}))
+	offset, err = LoadConsumerOffset(path)
+	require.NoError(t, err)
+	require.EqualValues(t, 11, offset.LedgerSeq(0))
+	require.EqualValues(t, 5, offset.LedgerSeq(1))
+
+	// offsets saved before ledger IDs were recorded are ledger 0's
+	require.NoError(t, os.WriteFile(path, []byte(`{"ledgerSeq":7}`), 0644))
+	offset, err = LoadConsumerOffset(path)
+	require.NoError(t, err)
+	require.EqualValues(t, 7, offset.LedgerSeq(0))
+	require.EqualValues(t, 0, offset.LedgerSeq(1))
+
+	require.NoError(t, os.WriteFile(path, []byte("nope"), 0644))
+	_, err = LoadConsumerOffset(path)
+	require.Error(t, err)
+}
@@ -0,0 +1,92 @@
+package executive
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrAuthenticationRequired is returned when the executive requires requests
+// to be authenticated and one presents no credentials.
+var ErrAuthenticationRequired = errors.New("Authentication required")
+
+// ErrAdminRequired is returned when a request which only an admin may make,
+// such as reviewing a writer registration, presents no admin token.
+var ErrAdminRequired = errors.New("Admin token required")
+
+// AdminToken authorizes an admin, who may make any request to the executive,
+// including managing API tokens and reviewing writer registrations, which
+// record the token's name as the admin making them. Admin tokens are
+// presented like API tokens, in an "Authorization: Bearer" header.
+type AdminToken struct {
+	Name  string `json:"name"`
+	Token string `json:"token"`
+}
+
+// LoadAdminTokens reads a JSON encoded list of admin tokens from the file
+// at path. They're read from a file, rather than flags, so that they don't
+// show up in the process' command line.
+func LoadAdminTokens(path string) ([]AdminToken, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "read admin tokens")
+	}
+	var tokens []AdminToken
+	if err := json.Unmarshal(b, &tokens); err != nil {
+		return nil, errors.Wrap(err, "decode admin tokens")
+	}
+	if err := validateAdminTokens(tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// validateAdminTokens checks that every admin token has a name and a
+// distinct token, which can't be mistaken for an API token.
+func validateAdminTokens(tokens []AdminToken) error {
+	names := map[string]string{}
+	for _, token := range tokens {
+		if token.Name == "" {
+			return errors.New("admin token has no name")
+		}
+		if token.Token == "" {
+			return errors.Errorf("admin token %q has no token", token.Name)
+		}
+		if strings.HasPrefix(token.Token, apiTokenPrefix) {
+			return errors.Errorf("admin token %q starts with the API token prefix %q", token.Name, apiTokenPrefix)
+		}
+		if other, ok := names[token.Token]; ok {
+			return errors.Errorf("admin tokens %q and %q are the same", other, token.Name)
+		}
+		names[token.Token] = token.Name
+	}
+	return nil
+}
+
+// findAdminToken returns the admin token matching a presented token.
+func findAdminToken(tokens []AdminToken, token string) (AdminToken, bool) {
+	for _, admin := range tokens {
+		if subtle.ConstantTimeCompare([]byte(admin.Token), []byte(token)) == 1 {
+			return admin, true
+		}
+	}
+	return AdminToken{}, false
+}
+
+type adminContextKey struct{}
+
+// contextWithAdmin records the name of the admin making a request.
+func contextWithAdmin(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, adminContextKey{}, name)
+}
+
+// adminFromContext returns the name of the admin making a request, if it
+// was made with an admin token.
+func adminFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(adminContextKey{}).(string)
+	return name, ok
+}
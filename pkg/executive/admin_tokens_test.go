@@ -0,0 +1,32 @@
+package executive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadAdminTokens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "admins.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[{"name": "ops", "token": "secret"}]`), 0600))
+	tokens, err := LoadAdminTokens(path)
+	require.NoError(t, err)
+	require.Equal(t, []AdminToken{{Name: "ops", Token: "secret"}}, tokens)
+
+	admin, ok := findAdminToken(tokens, "secret")
+	require.True(t, ok)
+	require.Equal(t, "ops", admin.Name)
+	_, ok = findAdminToken(tokens, "secret0")
+	require.False(t, ok)
+
+	for _, invalid := range [][]AdminToken{
+		{{Token: "secret"}},
+		{{Name: "ops"}},
+		{{Name: "ops", Token: apiTokenPrefix + "secret"}},
+		{{Name: "ops", Token: "secret"}, {Name: "ci", Token: "secret"}},
+	} {
+		require.Error(t, validateAdminTokens(invalid), "%+v", invalid)
+	}
+}
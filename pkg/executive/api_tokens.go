@@ -0,0 +1,146 @@
+package executive
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/segmentio/events/v2"
+
+	"github.com/segmentio/ctlstore/pkg/ctlcrypto"
+	"github.com/segmentio/ctlstore/pkg/errs"
+)
+
+// apiTokenPrefix makes read-only API tokens recognizable, e.g. in logs or
+// secret scanners.
+const apiTokenPrefix = "ctlro_"
+
+var apiTokenNameRegexp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_\-]{0,190}$`)
+
+// ErrInvalidAPIToken is returned when a request presents an API token which
+// doesn't exist, or has been deleted.
+var ErrInvalidAPIToken = errors.New("Invalid API token")
+
+// APIToken is a read-only API token, which authorizes GET requests to the
+// executive, such as those reading schemas and limits. The token itself is
+// only known when it's created, since just its hash is stored.
+type APIToken struct {
+	Name      string    `json:"name"`
+	Token     string    `json:"token,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// CreateAPIToken creates a read-only API token, which is returned along with
+// the plaintext token.
+func (e *dbExecutive) CreateAPIToken(name string) (APIToken, error) {
+	ctx, cancel := e.ctx()
+	defer cancel()
+
+	if !apiTokenNameRegexp.MatchString(name) {
+		return APIToken{}, errs.BadRequest("Token names must match %s", apiTokenNameRegexp)
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return APIToken{}, errors.Wrap(err, "generate token")
+	}
+	token := APIToken{
+		Name:      name,
+		Token:     apiTokenPrefix + hex.EncodeToString(b),
+		CreatedAt: time.Now().Truncate(time.Second),
+	}
+
+	var n int
+	err := e.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM api_tokens WHERE name=?", name).Scan(&n)
+	if err != nil {
+		return APIToken{}, errors.Wrap(err, "select api token")
+	}
+	if n > 0 {
+		return APIToken{}, &errs.ConflictError{Err: "API token already exists"}
+	}
+	_, err = e.DB.ExecContext(ctx, "INSERT INTO api_tokens (name, token_hash, created_at) VALUES (?, ?, ?)",
+		token.Name, hashAPIToken(token.Token), token.CreatedAt.Unix())
+	if err != nil {
+		return APIToken{}, errors.Wrap(err, "insert api token")
+	}
+	events.Log("Created API token %{name}s", name)
+	return token, nil
+}
+
+// ReadAPITokens returns the API tokens, ordered by name, without the tokens
+// themselves.
+func (e *dbExecutive) ReadAPITokens() ([]APIToken, error) {
+	ctx, cancel := e.ctx()
+	defer cancel()
+
+	rows, err := e.readDB().QueryContext(ctx, "SELECT name, created_at FROM api_tokens ORDER BY name")
+	if err != nil {
+		return nil, errors.Wrap(err, "select api tokens")
+	}
+	defer rows.Close()
+	res := []APIToken{}
+	for rows.Next() {
+		var token APIToken
+		var createdAt int64
+		if err := rows.Scan(&token.Name, &createdAt); err != nil {
+			return nil, errors.Wrap(err, "scan api token")
+		}
+		token.CreatedAt = time.Unix(createdAt, 0)
+		res = append(res, token)
+	}
+	return res, rows.Err()
+}
+
+// DeleteAPIToken revokes an API token.
+func (e *dbExecutive) DeleteAPIToken(name string) error {
+	ctx, cancel := e.ctx()
+	defer cancel()
+
+	res, err := e.DB.ExecContext(ctx, "DELETE FROM api_tokens WHERE name=?", name)
+	if err != nil {
+		return errors.Wrap(err, "delete api token")
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "rows affected")
+	}
+	if rows == 0 {
+		return &errs.NotFoundError{Err: "API token not found"}
+	}
+	events.Log("Deleted API token %{name}s", name)
+	return nil
+}
+
+// CheckAPIToken returns the API token matching a plaintext token, or
+// ErrInvalidAPIToken if there isn't one.
+func (e *dbExecutive) CheckAPIToken(token string) (APIToken, error) {
+	ctx, cancel := e.ctx()
+	defer cancel()
+
+	if !strings.HasPrefix(token, apiTokenPrefix) {
+		return APIToken{}, ErrInvalidAPIToken
+	}
+	var res APIToken
+	var createdAt int64
+	// the primary is read so that tokens can be used as soon as they're
+	// created, and not once they're deleted
+	err := e.DB.QueryRowContext(ctx, "SELECT name, created_at FROM api_tokens WHERE token_hash=?",
+		hashAPIToken(token)).Scan(&res.Name, &createdAt)
+	switch {
+	case err == sql.ErrNoRows:
+		return APIToken{}, ErrInvalidAPIToken
+	case err != nil:
+		return APIToken{}, errors.Wrap(err, "select api token")
+	}
+	res.CreatedAt = time.Unix(createdAt, 0)
+	return res, nil
+}
+
+// hashAPIToken hashes tokens the way writer secrets are, so that they're
+// stored using the configured crypto provider.
+func hashAPIToken(token string) string {
+	return ctlcrypto.Default().HashSecret(token)
+}
@@ -0,0 +1,50 @@
+package executive
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+)
+
+// testDBExecutiveAPITokens is run from TestAllDBExecutive
+func testDBExecutiveAPITokens(t *testing.T, dbType string) {
+	u := newDbExecTestUtil(t, dbType)
+	defer u.Close()
+
+	_, err := u.e.CreateAPIToken("not a name")
+	require.IsType(t, &errs.BadRequestError{}, errors.Cause(err))
+
+	token, err := u.e.CreateAPIToken("dashboard")
+	require.NoError(t, err)
+	require.Equal(t, "dashboard", token.Name)
+	require.True(t, strings.HasPrefix(token.Token, apiTokenPrefix))
+	_, err = u.e.CreateAPIToken("dashboard")
+	require.IsType(t, &errs.ConflictError{}, errors.Cause(err))
+
+	// only the hash is stored
+	var stored string
+	require.NoError(t, u.db.QueryRow("SELECT token_hash FROM api_tokens WHERE name='dashboard'").Scan(&stored))
+	require.NotEqual(t, token.Token, stored)
+
+	tokens, err := u.e.ReadAPITokens()
+	require.NoError(t, err)
+	require.Equal(t, []APIToken{{Name: "dashboard", CreatedAt: token.CreatedAt}}, tokens)
+
+	checked, err := u.e.CheckAPIToken(token.Token)
+	require.NoError(t, err)
+	require.Equal(t, "dashboard", checked.Name)
+	_, err = u.e.CheckAPIToken(token.Token + "0")
+	require.Equal(t, ErrInvalidAPIToken, err)
+	_, err = u.e.CheckAPIToken("dashboard")
+	require.Equal(t, ErrInvalidAPIToken, err)
+
+	require.NoError(t, u.e.DeleteAPIToken("dashboard"))
+	err = u.e.DeleteAPIToken("dashboard")
+	require.IsType(t, &errs.NotFoundError{}, errors.Cause(err))
+	_, err = u.e.CheckAPIToken(token.Token)
+	require.Equal(t, ErrInvalidAPIToken, err)
+}
@@ -0,0 +1,61 @@
+package executive
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+	"github.com/segmentio/ctlstore/pkg/schema"
+	"github.com/segmentio/ctlstore/pkg/sqlgen"
+)
+
+// conditionHolds reports whether a conditional mutation should be applied,
+// which is when the row it mutates doesn't exist, or when the row's values
+// match the request's IfValues. It must be called in the mutation's
+// transaction, which must have taken the ledger lock before reading
+// anything, so that the row can't change between the check and the
+// mutation. On MySQL, the transaction's snapshot is taken by its first
+// read, so a read before the lock could check a row which another
+// mutation has since changed.
+func (r *mutationRequest) conditionHolds(ctx context.Context, tx *sql.Tx, tbl sqlgen.MetaTable) (bool, error) {
+	if r.IfValues == nil {
+		return true, nil
+	}
+
+	keyValues, err := r.valuesByOrder(tbl.KeyFields.Fields)
+	if err != nil {
+		return false, err
+	}
+
+	fieldNames := []schema.FieldName{}
+	values := []interface{}{}
+	for fn, v := range r.IfValues {
+		ft, ok := tbl.FieldTypeByName(fn)
+		if !ok {
+			return false, errs.BadRequest("Condition on unknown field %s", fn)
+		}
+		v, err := schema.NormalizeFieldValue(ft, v)
+		if err != nil {
+			return false, errs.BadRequest("Condition on field %s: %s", fn, err)
+		}
+		fieldNames = append(fieldNames, fn)
+		values = append(values, v)
+	}
+
+	query, err := tbl.ConditionQuery(keyValues, fieldNames, values)
+	if err != nil {
+		return false, errs.BadRequest("Condition: %s", err)
+	}
+
+	var matches sql.NullBool
+	err = tx.QueryRowContext(ctx, query.SQL, query.Args...).Scan(&matches)
+	switch {
+	case err == sql.ErrNoRows:
+		return true, nil
+	case err != nil:
+		return false, errors.Wrap(err, "checking mutation condition")
+	}
+	return matches.Valid && matches.Bool, nil
+}
@@ -3,7 +3,12 @@ package executive
 import (
 	"context"
 	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -15,6 +20,7 @@ import (
 	"github.com/segmentio/ctlstore/pkg/scanfunc"
 	"github.com/segmentio/ctlstore/pkg/schema"
 	"github.com/segmentio/ctlstore/pkg/sqlgen"
+	"github.com/segmentio/ctlstore/pkg/tracing"
 	"github.com/segmentio/events/v2"
 	"github.com/segmentio/go-sqlite3"
 )
@@ -24,9 +30,30 @@ const mutatorsTableName = "mutators"
 
 // A database-backed (ctldb) Executive.
 type dbExecutive struct {
-	DB      *sql.DB
-	limiter *dbLimiter
-	Ctx     context.Context
+	DB *sql.DB
+	// ReadDB, if set, serves read-only requests that can tolerate
+	// replication lag. Mutations and DDL always go to DB.
+	ReadDB   *sql.DB
+	limiter  *dbLimiter
+	exporter *exporter
+	analyzer *tableAnalyzer
+	webhooks *webhookNotifier
+	// ledgerSeq caches the max ledger seq for ReadLedgerSeq
+	ledgerSeq *ledgerSeqCache
+	Ctx       context.Context
+	// SourceIP is the address the request came from. It is recorded
+	// against writers when they mutate.
+	SourceIP string
+	// ParameterizedDML makes mutations write parameterized statements to
+	// the ledger rather than SQL with their values quoted into it, and
+	// records the version of each ledger entry in the version column,
+	// which the ledger must have. Only reflectors which support them can
+	// apply them.
+	ParameterizedDML bool
+	// RecordTraceIDs records the trace ID of each request in the trace_id
+	// column of the ledger entries it writes, which the column must have
+	// been added to.
+	RecordTraceIDs bool
 }
 
 var ErrTableDoesNotExist = errors.New("table does not exist")
@@ -36,7 +63,7 @@ func (e *dbExecutive) FamilySchemas(family string) ([]schema.Table, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "family name")
 	}
-	dbInfo := getDBInfo(e.DB)
+	dbInfo := getDBInfo(e.readDB())
 	tables, err := dbInfo.GetAllTables(context.TODO())
 	if err != nil {
 		return nil, errors.Wrap(err, "get table names")
@@ -54,6 +81,27 @@ func (e *dbExecutive) FamilySchemas(family string) ([]schema.Table, error) {
 	return res, nil
 }
 
+// FamilyTables returns the sorted names of the family's tables.
+func (e *dbExecutive) FamilyTables(family string) ([]string, error) {
+	familyName, err := schema.NewFamilyName(family)
+	if err != nil {
+		return nil, errors.Wrap(err, "family name")
+	}
+	dbInfo := getDBInfo(e.readDB())
+	tables, err := dbInfo.GetAllTables(context.TODO())
+	if err != nil {
+		return nil, errors.Wrap(err, "get table names")
+	}
+	res := []string{}
+	for _, table := range tables {
+		if table.Family == familyName.String() {
+			res = append(res, table.Table)
+		}
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
 func (e *dbExecutive) TableSchema(family, table string) (*schema.Table, error) {
 	familyName, err := schema.NewFamilyName(family)
 	if err != nil {
@@ -69,7 +117,7 @@ func (e *dbExecutive) TableSchema(family, table string) (*schema.Table, error) {
 	if normalized := tableName.String(); normalized != table {
 		return nil, errors.Wrapf(err, "passed in table name does not match normalized table name: %q", normalized)
 	}
-	tbl, ok, err := e.fetchMetaTableByName(familyName, tableName)
+	tbl, ok, err := e.fetchMetaTableByNameFrom(e.readDB(), familyName, tableName)
 	if err != nil {
 		return nil, errors.Wrap(err, "fetch meta table")
 	}
@@ -88,6 +136,9 @@ func (e *dbExecutive) TableSchema(family, table string) (*schema.Table, error) {
 		case schema.FTText:
 		case schema.FTBinary:
 		case schema.FTByteString:
+		case schema.FTTimestamp:
+		case schema.FTBoolean:
+		case schema.FTJSON:
 		default:
 			return nil, errors.Errorf("unsupported field type: %q", field.FieldType)
 		}
@@ -98,6 +149,26 @@ func (e *dbExecutive) TableSchema(family, table string) (*schema.Table, error) {
 	for _, field := range tbl.KeyFields.Fields {
 		res.KeyFields = append(res.KeyFields, field.Name)
 	}
+	for fn, opts := range tbl.FieldOptions {
+		if res.FieldOptions == nil {
+			res.FieldOptions = map[string]schema.FieldOptions{}
+		}
+		res.FieldOptions[fn.Name] = opts
+	}
+	for _, fns := range tbl.UniqueConstraints {
+		res.UniqueConstraints = append(res.UniqueConstraints, schema.StringifyFieldNames(fns))
+	}
+	refs, err := readTableReferences(context.TODO(), e.readDB(), familyName, tableName)
+	if err != nil {
+		return nil, errors.Wrap(err, "read references")
+	}
+	for fn, ref := range refs.references {
+		if res.References == nil {
+			res.References = map[string]schema.FieldReference{}
+		}
+		res.References[fn.Name] = ref
+	}
+	res.StrictReferences = refs.strict
 
 	return res, nil
 }
@@ -114,7 +185,40 @@ func (e *dbExecutive) ctx() (context.Context, context.CancelFunc) {
 	return context.WithCancel(e.Ctx)
 }
 
-func (e *dbExecutive) CreateFamily(familyName string) error {
+// trace starts a span around an operation, which the contexts forked by
+// ctx() carry until the returned func is deferred with the operation's
+// error, e.g.
+//
+//	defer e.trace("executive.CreateFamily")(&err)
+func (e *dbExecutive) trace(name string, attrs ...tracing.Attribute) func(*error) {
+	parent := e.Ctx
+	ctx := parent
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := tracing.Start(ctx, name, attrs...)
+	e.Ctx = ctx
+	return func(err *error) {
+		e.Ctx = parent
+		span.Finish(*err)
+	}
+}
+
+// ledgerWriter returns a writer of entries to the ledger within tx
+func (e *dbExecutive) ledgerWriter(tx *sql.Tx) *dmlLedgerWriter {
+	return &dmlLedgerWriter{Tx: tx, TableName: dmlLedgerTableName, TraceIDs: e.RecordTraceIDs, Versions: e.ParameterizedDML}
+}
+
+// readDB returns the database that read-only requests should use
+func (e *dbExecutive) readDB() *sql.DB {
+	if e.ReadDB != nil {
+		return e.ReadDB
+	}
+	return e.DB
+}
+
+func (e *dbExecutive) CreateFamily(familyName string) (err error) {
+	defer e.trace("executive.CreateFamily", tracing.String("family", familyName))(&err)
 	ctx, cancel := e.ctx()
 	defer cancel()
 
@@ -137,6 +241,11 @@ func (e *dbExecutive) CreateFamily(familyName string) error {
 }
 
 func (e *dbExecutive) CreateTable(familyName string, tableName string, fieldNames []string, fieldTypes []schema.FieldType, keyFields []string) error {
+	return e.createTable(familyName, tableName, fieldNames, fieldTypes, keyFields, nil, nil)
+}
+
+func (e *dbExecutive) createTable(familyName string, tableName string, fieldNames []string, fieldTypes []schema.FieldType, keyFields []string, fieldOptions map[string]schema.FieldOptions, uniqueConstraints [][]string) (err error) {
+	defer e.trace("executive.CreateTable", tracing.String("family", familyName), tracing.String("table", tableName))(&err)
 	ctx, cancel := e.ctx()
 	defer cancel()
 
@@ -155,6 +264,14 @@ func (e *dbExecutive) CreateTable(familyName string, tableName string, fieldName
 	if len(tbl.KeyFields.Fields) == 0 {
 		return &errs.BadRequestError{Err: "table must have at least one key field"}
 	}
+	tbl.FieldOptions, err = fieldOptionsByName(fieldOptions)
+	if err != nil {
+		return err
+	}
+	tbl.UniqueConstraints, err = uniqueConstraintsByName(uniqueConstraints)
+	if err != nil {
+		return err
+	}
 
 	_, ok, err := e.fetchFamilyByName(famName)
 	if err != nil {
@@ -169,6 +286,18 @@ func (e *dbExecutive) CreateTable(familyName string, tableName string, fieldName
 		return &errs.BadRequestError{err.Error()}
 	}
 
+	defaults, err := readFamilyDefaults(ctx, e.DB, famName.Name)
+	if err != nil {
+		return err
+	}
+	keyNames := make([]string, len(tbl.KeyFields.Fields))
+	for i, f := range tbl.KeyFields.Fields {
+		keyNames[i] = f.Name
+	}
+	if err := defaults.checkKeyFields(keyNames); err != nil {
+		return err
+	}
+
 	ddl, err := tbl.AsCreateTableDDL()
 	if err != nil {
 		return err
@@ -198,10 +327,7 @@ func (e *dbExecutive) CreateTable(familyName string, tableName string, fieldName
 		return errors.Wrap(err, "take ledger lock")
 	}
 
-	dlw := dmlLedgerWriter{
-		Tx:        tx,
-		TableName: dmlLedgerTableName,
-	}
+	dlw := e.ledgerWriter(tx)
 	defer dlw.Close()
 
 	seq, err := dlw.Add(ctx, logDDL)
@@ -218,23 +344,53 @@ func (e *dbExecutive) CreateTable(familyName string, tableName string, fieldName
 		return errors.Wrap(err, "apply ddl")
 	}
 
+	if sl := defaults.TableSizeLimits; sl != nil {
+		_, err = tx.ExecContext(ctx, "replace into max_table_sizes "+
+			"(family_name, table_name, warn_size_bytes, max_size_bytes) "+
+			"values (?, ?, ?, ?)",
+			famName.Name, tbl.TableName.Name, sl.WarnSize, sl.MaxSize)
+		if err != nil {
+			return errors.Wrap(err, "replace into max_table_sizes")
+		}
+	}
+	if err := insertUniqueConstraints(ctx, tx, tbl); err != nil {
+		return err
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return err
 	}
 
 	events.Log("Successfully created new table `%{tableName}s` at seq %{seq}v", tableName, seq)
+	e.ledgerSeq.advance(seq.Int())
+	e.webhooks.notify(WebhookNotification{
+		Event:     WebhookEventCreateTable,
+		Family:    famName.Name,
+		Tables:    []string{tbl.TableName.Name},
+		LedgerSeq: seq.Int(),
+	})
 
 	return nil
 }
 
 func (e *dbExecutive) CreateTables(tables []schema.Table) error {
 	for _, table := range tables {
+		if table.Template != "" {
+			tmpl, err := e.readTableTemplate(table.Family, table.Template)
+			if err != nil {
+				return errors.Wrap(err, fmt.Sprintf("reading template %q for family %q", table.Template, table.Family))
+			}
+			table.Fields, table.KeyFields, err = tmpl.Apply(table.Fields, table.KeyFields)
+			if err != nil {
+				return errors.Wrap(err, fmt.Sprintf("applying template %q for family %q table %q", table.Template, table.Family, table.Name))
+			}
+		}
 		fieldNames, fieldTypes, err := schema.UnzipFieldsParam(table.Fields)
 		if err != nil {
 			return errors.Wrap(err, fmt.Sprintf("unzipping fields param for family %q table %q", table.Family, table.Name))
 		}
-		err = e.CreateTable(table.Family, table.Name, fieldNames, fieldTypes, table.KeyFields)
+		err = e.createTable(table.Family, table.Name, fieldNames, fieldTypes, table.KeyFields, table.FieldOptions, table.UniqueConstraints)
 		if err != nil {
 			return errors.Wrap(err, fmt.Sprintf("creating table for family %q table %q", table.Family, table.Name))
 		}
@@ -242,6 +398,128 @@ func (e *dbExecutive) CreateTables(tables []schema.Table) error {
 	return nil
 }
 
+// SaveTableTemplate creates or replaces a table template for a family.
+// Tables that were already created from the template are not changed.
+func (e *dbExecutive) SaveTableTemplate(template schema.TableTemplate) error {
+	ctx, cancel := e.ctx()
+	defer cancel()
+
+	if err := template.Validate(); err != nil {
+		return err
+	}
+	famName, err := schema.NewFamilyName(template.Family)
+	if err != nil {
+		return &errs.BadRequestError{Err: err.Error()}
+	}
+	template.Family = famName.Name
+	_, ok, err := e.fetchFamilyByName(famName)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return &errs.NotFoundError{Err: "Family not found"}
+	}
+	definition, err := json.Marshal(template)
+	if err != nil {
+		return errors.Wrap(err, "marshal template")
+	}
+
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin tx")
+	}
+	defer tx.Rollback()
+	_, err = tx.ExecContext(ctx, "DELETE FROM table_templates WHERE family_name=? AND template_name=?",
+		template.Family, template.Name)
+	if err != nil {
+		return errors.Wrap(err, "delete template")
+	}
+	_, err = tx.ExecContext(ctx, "INSERT INTO table_templates (family_name, template_name, definition) VALUES (?, ?, ?)",
+		template.Family, template.Name, string(definition))
+	if err != nil {
+		return errors.Wrap(err, "insert template")
+	}
+	return errors.Wrap(tx.Commit(), "commit tx")
+}
+
+// ReadTableTemplates returns the table templates of a family, ordered by name.
+func (e *dbExecutive) ReadTableTemplates(familyName string) ([]schema.TableTemplate, error) {
+	ctx, cancel := e.ctx()
+	defer cancel()
+
+	famName, err := schema.NewFamilyName(familyName)
+	if err != nil {
+		return nil, &errs.BadRequestError{Err: err.Error()}
+	}
+	rows, err := e.readDB().QueryContext(ctx,
+		"SELECT definition FROM table_templates WHERE family_name=? ORDER BY template_name", famName.Name)
+	if err != nil {
+		return nil, errors.Wrap(err, "select templates")
+	}
+	defer rows.Close()
+	res := []schema.TableTemplate{}
+	for rows.Next() {
+		var definition string
+		if err := rows.Scan(&definition); err != nil {
+			return nil, errors.Wrap(err, "scan template")
+		}
+		var tmpl schema.TableTemplate
+		if err := json.Unmarshal([]byte(definition), &tmpl); err != nil {
+			return nil, errors.Wrap(err, "unmarshal template")
+		}
+		res = append(res, tmpl)
+	}
+	return res, rows.Err()
+}
+
+func (e *dbExecutive) readTableTemplate(familyName string, templateName string) (schema.TableTemplate, error) {
+	ctx, cancel := e.ctx()
+	defer cancel()
+
+	var tmpl schema.TableTemplate
+	famName, err := schema.NewFamilyName(familyName)
+	if err != nil {
+		return tmpl, &errs.BadRequestError{Err: err.Error()}
+	}
+	var definition string
+	err = e.DB.QueryRowContext(ctx,
+		"SELECT definition FROM table_templates WHERE family_name=? AND template_name=?",
+		famName.Name, templateName).Scan(&definition)
+	switch {
+	case err == sql.ErrNoRows:
+		return tmpl, &errs.NotFoundError{Err: "Template not found"}
+	case err != nil:
+		return tmpl, errors.Wrap(err, "select template")
+	}
+	err = json.Unmarshal([]byte(definition), &tmpl)
+	return tmpl, errors.Wrap(err, "unmarshal template")
+}
+
+// DeleteTableTemplate removes a table template. Tables that were created
+// from it are not changed.
+func (e *dbExecutive) DeleteTableTemplate(familyName string, templateName string) error {
+	ctx, cancel := e.ctx()
+	defer cancel()
+
+	famName, err := schema.NewFamilyName(familyName)
+	if err != nil {
+		return &errs.BadRequestError{Err: err.Error()}
+	}
+	res, err := e.DB.ExecContext(ctx, "DELETE FROM table_templates WHERE family_name=? AND template_name=?",
+		famName.Name, templateName)
+	if err != nil {
+		return errors.Wrap(err, "delete template")
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "rows affected")
+	}
+	if rows == 0 {
+		return &errs.NotFoundError{Err: "Template not found"}
+	}
+	return nil
+}
+
 // applyDDL executes the DDL in the tx if the backing store is sqlite, and outside of the
 // tx if the backing store is mysql. The reason for this is that sqlite treats ddl transactionally,
 // while mysql does not. When DDL is executed as part of an ongoing tx, mysql implicitly commits
@@ -262,7 +540,11 @@ func (e *dbExecutive) applyDDL(ctx context.Context, tx *sql.Tx, ddl string) (sql
 	}
 }
 
-func (e *dbExecutive) AddFields(familyName string, tableName string, fieldNames []string, fieldTypes []schema.FieldType) error {
+// AddFields adds columns to a table. fieldOptions optionally sets the
+// default and nullability of the new fields by name. NOT NULL fields need a
+// default, since existing rows have no value for them.
+func (e *dbExecutive) AddFields(familyName string, tableName string, fieldNames []string, fieldTypes []schema.FieldType, fieldOptions map[string]schema.FieldOptions) (err error) {
+	defer e.trace("executive.AddFields", tracing.String("family", familyName), tracing.String("table", tableName))(&err)
 	ctx, cancel := e.ctx()
 	defer cancel()
 	// We create a metatable here with no fields. We will
@@ -285,12 +567,47 @@ func (e *dbExecutive) AddFields(familyName string, tableName string, fieldNames
 	if lfn, lft := len(fieldNames), len(fieldTypes); lfn != lft {
 		return &errs.BadRequestError{Err: fmt.Sprintf("number of fields (%d) != number of types (%d)", lfn, lft)}
 	}
+	optionsByName, err := fieldOptionsByName(fieldOptions)
+	if err != nil {
+		return err
+	}
+	for fn := range optionsByName {
+		found := false
+		for _, fieldName := range fieldNames {
+			found = found || fieldName == fn.Name
+		}
+		if !found {
+			return errs.BadRequest("Options given for unknown field '%s'", fn)
+		}
+	}
+	// each field is added in its own transaction, so the webhooks are
+	// notified of the fields which were added even if a later one fails
+	var lastSeq schema.DMLSequence
+	defer func() {
+		if lastSeq != 0 {
+			e.ledgerSeq.advance(lastSeq.Int())
+			e.webhooks.notify(WebhookNotification{
+				Event:     WebhookEventAddFields,
+				Family:    famName.Name,
+				Tables:    []string{tbl.TableName.Name},
+				LedgerSeq: lastSeq.Int(),
+			})
+		}
+	}()
 	for i, fieldName := range fieldNames {
 		fn, err := schema.NewFieldName(fieldName)
 		if err != nil {
 			return err
 		}
 		fieldType := fieldTypes[i]
+		opts := optionsByName[fn]
+		if err := opts.Validate(fieldType); err != nil {
+			return &errs.BadRequestError{Err: err.Error()}
+		}
+		if opts.NotNull && !opts.HasDefault() {
+			return errs.BadRequest("Field '%s' must have a default to be added as NOT NULL", fn)
+		}
+		tbl.FieldOptions = map[schema.FieldName]schema.FieldOptions{fn: opts}
 		ddl, err := tbl.AddColumnDDL(fn, fieldType)
 		if err != nil {
 			return err
@@ -322,7 +639,7 @@ func (e *dbExecutive) AddFields(familyName string, tableName string, fieldNames
 			// It's important that this is done befored the DDL is applied to the ctldb, as
 			// the DDL is not able to be rolled back. In this way, if the DDL fails, the DML
 			// can be rolled back.
-			dlw := dmlLedgerWriter{Tx: tx, TableName: dmlLedgerTableName}
+			dlw := e.ledgerWriter(tx)
 			defer dlw.Close()
 			seq, err := dlw.Add(ctx, logDDL)
 			if err != nil {
@@ -346,6 +663,7 @@ func (e *dbExecutive) AddFields(familyName string, tableName string, fieldNames
 				return errors.Wrap(err, "commit tx")
 			}
 			events.Log("Successfully created new field `%{fieldName}s %{fieldType}v` on table %{tableName}s at seq %{seq}v", fieldName, fieldType, tableName, seq)
+			lastSeq = seq
 			return nil
 		}()
 		if err != nil {
@@ -355,6 +673,161 @@ func (e *dbExecutive) AddFields(familyName string, tableName string, fieldNames
 	return nil
 }
 
+// AlterField renames a field and/or widens its type. An empty newFieldName
+// keeps the current name, and a zero newFieldType keeps the current type.
+func (e *dbExecutive) AlterField(familyName string, tableName string, fieldName string, newFieldName string, newFieldType schema.FieldType) (err error) {
+	defer e.trace("executive.AlterField", tracing.String("family", familyName), tracing.String("table", tableName))(&err)
+	ctx, cancel := e.ctx()
+	defer cancel()
+
+	famName, err := schema.NewFamilyName(familyName)
+	if err != nil {
+		return &errs.BadRequestError{Err: err.Error()}
+	}
+	tblName, err := schema.NewTableName(tableName)
+	if err != nil {
+		return &errs.BadRequestError{Err: err.Error()}
+	}
+	fn, err := schema.NewFieldName(fieldName)
+	if err != nil {
+		return &errs.BadRequestError{Err: err.Error()}
+	}
+	newFn := fn
+	if newFieldName != "" {
+		newFn, err = schema.NewFieldName(newFieldName)
+		if err != nil {
+			return &errs.BadRequestError{Err: err.Error()}
+		}
+	}
+
+	tbl, ok, err := e.fetchMetaTableByName(famName, tblName)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return &errs.NotFoundError{Err: "Table not found"}
+	}
+	fieldType, ok := tbl.FieldTypeByName(fn)
+	if !ok {
+		return &errs.NotFoundError{Err: "Field not found"}
+	}
+	if newFieldType == 0 {
+		newFieldType = fieldType
+	}
+	if newFn == fn && newFieldType == fieldType {
+		return errs.BadRequest("No changes requested for field '%s'", fn)
+	}
+	if _, exists := tbl.FieldTypeByName(newFn); exists && newFn != fn {
+		return &errs.ConflictError{Err: "Column already exists"}
+	}
+	if !fieldType.CanWidenTo(newFieldType) {
+		return errs.BadRequest("Cannot change field '%s' from %s to %s", fn, fieldType, newFieldType)
+	}
+	if tbl.FieldOptions[fn].HasDefault() && !newFieldType.CanHaveDefault() {
+		return errs.BadRequest("Cannot change field '%s' with a default to %s", fn, newFieldType)
+	}
+	for _, kf := range tbl.KeyFields.Fields {
+		// readers cache primary keys, so changing them out from under
+		// them isn't safe
+		if kf == fn {
+			return errs.BadRequest("Cannot alter key field '%s'", fn)
+		}
+	}
+	if !newFieldType.CanBeKey() {
+		for _, fns := range tbl.UniqueConstraints {
+			for _, uf := range fns {
+				if uf == fn {
+					return errs.BadRequest("Cannot change field '%s' in a unique constraint to %s", fn, newFieldType)
+				}
+			}
+		}
+	}
+
+	dmlLogTbl, err := tbl.ForDriver(ldb.LDBDatabaseDriver)
+	if err != nil {
+		return err
+	}
+	ddls, err := tbl.ChangeColumnDDL(fn, newFn, newFieldType)
+	if err != nil {
+		return err
+	}
+	logDDLs, err := dmlLogTbl.ChangeColumnDDL(fn, newFn, newFieldType)
+	if err != nil {
+		return err
+	}
+
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = e.takeLedgerLock(ctx, tx)
+	if err != nil {
+		return errors.Wrap(err, "take ledger lock")
+	}
+
+	// As with AddFields, the ledger is written before the DDL is applied
+	// to the ctldb so that a failed DDL rolls back the ledger entries.
+	dlw := e.ledgerWriter(tx)
+	defer dlw.Close()
+	if len(logDDLs) > 1 {
+		if _, err = dlw.BeginTx(ctx); err != nil {
+			return errors.Wrap(err, "begin dml tx")
+		}
+	}
+	var seq schema.DMLSequence
+	for _, logDDL := range logDDLs {
+		events.Debug("[AlterField %{tableName}s] log DDL: %{ddl}s", tableName, logDDL)
+		seq, err = dlw.Add(ctx, logDDL)
+		if err != nil {
+			return errors.Wrap(err, "add dml")
+		}
+	}
+	if len(logDDLs) > 1 {
+		if seq, err = dlw.CommitTx(ctx); err != nil {
+			return errors.Wrap(err, "commit dml tx")
+		}
+	}
+
+	for _, ddl := range ddls {
+		events.Debug("[AlterField %{tableName}s] ctldb DDL: %{ddl}s", tableName, ddl)
+		_, err = e.applyDDL(ctx, tx, ddl)
+		if err != nil {
+			return errors.Wrap(err, "apply ddl")
+		}
+	}
+	if newFn != fn {
+		_, err = tx.ExecContext(ctx, "UPDATE field_references SET field_name = ? "+
+			"WHERE family_name = ? AND table_name = ? AND field_name = ?",
+			newFn.Name, famName.Name, tblName.Name, fn.Name)
+		if err != nil {
+			return errors.Wrap(err, "rename references")
+		}
+		_, err = tx.ExecContext(ctx, "UPDATE unique_constraints SET field_name = ? "+
+			"WHERE family_name = ? AND table_name = ? AND field_name = ?",
+			newFn.Name, famName.Name, tblName.Name, fn.Name)
+		if err != nil {
+			return errors.Wrap(err, "rename unique constraints")
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "commit tx")
+	}
+	events.Log("Successfully altered field `%{fieldName}s` to `%{newFieldName}s %{fieldType}v` on table %{tableName}s at seq %{seq}v",
+		fn, newFn, newFieldType, tableName, seq)
+	e.ledgerSeq.advance(seq.Int())
+	e.webhooks.notify(WebhookNotification{
+		Event:     WebhookEventAlterField,
+		Family:    famName.Name,
+		Tables:    []string{tblName.Name},
+		LedgerSeq: seq.Int(),
+	})
+	return nil
+}
+
 func (e *dbExecutive) GetWriterCookie(writerName string, writerSecret string) ([]byte, error) {
 	ctx, cancel := e.ctx()
 	defer cancel()
@@ -424,7 +897,7 @@ func (e *dbExecutive) SetWriterCookie(writerName string, writerSecret string, co
 		TableName: mutatorsTableName,
 	}
 
-	err = ms.Update(wn, writerSecret, cookie, nil)
+	err = ms.Update(wn, writerSecret, cookie, nil, nil)
 
 	if err == ErrWriterNotFound {
 		return &errs.NotFoundError{Err: err.Error()}
@@ -436,6 +909,57 @@ func (e *dbExecutive) SetWriterCookie(writerName string, writerSecret string, co
 	return err
 }
 
+// CompareAndSwapWriterCookie sets the writer's cookie only if its current
+// cookie is the expected one, so that writers sharing work can claim it
+// without applying a mutation. If the cookie doesn't match, it's left alone
+// and the current cookie is returned.
+func (e *dbExecutive) CompareAndSwapWriterCookie(writerName string, writerSecret string, expected []byte, cookie []byte) (CookieSwap, error) {
+	ctx, cancel := e.ctx()
+	defer cancel()
+
+	wn, err := schema.NewWriterName(writerName)
+	if err != nil {
+		return CookieSwap{}, &errs.BadRequestError{Err: err.Error()}
+	}
+	if expected == nil {
+		// a nil cookie isn't checked by the update
+		expected = []byte{}
+	}
+
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return CookieSwap{}, errors.Wrap(err, "start tx")
+	}
+	defer tx.Rollback()
+
+	ms := mutatorStore{
+		DB:        tx,
+		Ctx:       ctx,
+		TableName: mutatorsTableName,
+	}
+	err = ms.Update(wn, writerSecret, cookie, expected, nil)
+	switch err {
+	case nil:
+		if err := tx.Commit(); err != nil {
+			return CookieSwap{}, errors.Wrap(err, "commit tx")
+		}
+		return CookieSwap{Swapped: true, Cookie: cookie}, nil
+	case ErrCookieConflict:
+		// the current cookie is read in the same transaction, so it's the
+		// one which didn't match
+		current, _, err := ms.Get(wn, writerSecret)
+		if err != nil {
+			return CookieSwap{}, err
+		}
+		return CookieSwap{Cookie: current}, nil
+	case ErrWriterNotFound:
+		return CookieSwap{}, &errs.NotFoundError{Err: err.Error()}
+	case ErrCookieTooLong:
+		return CookieSwap{}, &errs.BadRequestError{Err: err.Error()}
+	}
+	return CookieSwap{}, err
+}
+
 func (e *dbExecutive) HealthCheck() error {
 	// TODO: implement actual health checks
 	return nil
@@ -447,41 +971,109 @@ func (e *dbExecutive) Mutate(
 	familyName string,
 	cookie []byte,
 	checkCookie []byte,
-	requests []ExecutiveMutationRequest) error {
-
-	ctx, cancel := e.ctx()
-	defer cancel()
+	requests []ExecutiveMutationRequest) (MutationResult, error) {
 
-	// Reject requests that are too large
-	if len(requests) > limits.LimitMaxMutateRequestCount {
-		return &errs.PayloadTooLargeError{Err: "Number of requests exceeds maximum"}
+	famRequests := make([]ExecutiveMutationRequest, len(requests))
+	for i, req := range requests {
+		req.FamilyName = familyName
+		famRequests[i] = req
 	}
+	return e.mutate(writerName, writerSecret, cookie, checkCookie, famRequests)
+}
 
-	famName, err := schema.NewFamilyName(familyName)
+// MutateFamilies is like Mutate, but each request names the family of its
+// table, so that a writer can atomically mutate tables in several families.
+func (e *dbExecutive) MutateFamilies(
+	writerName string,
+	writerSecret string,
+	cookie []byte,
+	checkCookie []byte,
+	requests []ExecutiveMutationRequest) (MutationResult, error) {
+
+	return e.mutate(writerName, writerSecret, cookie, checkCookie, requests)
+}
+
+// upsertDML returns the DML which upserts the values of the named fields
+// into the table in the ctldb, and the DML which the ledger records for the
+// LDBs. They're the same unless the table has unique constraints, whose
+// upserts are in the dialect of the database they're applied to.
+func (e *dbExecutive) upsertDML(tbl sqlgen.MetaTable, fieldNames []schema.FieldName, values []interface{}) (dml schema.ParameterizedDML, ledgerDML schema.ParameterizedDML, err error) {
+	upsert := func(tbl sqlgen.MetaTable) (dml schema.ParameterizedDML, err error) {
+		if e.ParameterizedDML {
+			return tbl.UpsertFieldsParameterizedDML(fieldNames, values)
+		}
+		dml.SQL, err = tbl.UpsertFieldsDML(fieldNames, values)
+		return dml, err
+	}
+	dml, err = upsert(tbl)
 	if err != nil {
-		return err
+		return dml, ledgerDML, err
+	}
+	if len(tbl.UniqueConstraints) == 0 || tbl.DriverName == ldb.LDBDatabaseDriver {
+		return dml, dml, nil
+	}
+	ldbTbl, err := tbl.ForDriver(ldb.LDBDatabaseDriver)
+	if err != nil {
+		return dml, ledgerDML, err
+	}
+	ledgerDML, err = upsert(ldbTbl)
+	return dml, ledgerDML, err
+}
+
+func (e *dbExecutive) mutate(
+	writerName string,
+	writerSecret string,
+	cookie []byte,
+	checkCookie []byte,
+	requests []ExecutiveMutationRequest) (_ MutationResult, err error) {
+
+	defer e.trace("executive.Mutate", tracing.String("writer", writerName))(&err)
+	ctx, cancel := e.ctx()
+	defer cancel()
+
+	// Reject requests that are too large
+	if len(requests) > limits.LimitMaxMutateRequestCount {
+		return MutationResult{}, &errs.PayloadTooLargeError{Err: "Number of requests exceeds maximum"}
 	}
 
 	wn, err := schema.NewWriterName(writerName)
 	if err != nil {
-		return err
+		return MutationResult{}, err
 	}
 
-	reqset, err := newMutationRequestSet(famName, requests)
+	reqset, err := newMutationRequestSet(requests)
 	if err != nil {
-		return err
+		return MutationResult{}, err
+	}
+	if len(reqset.Requests) == 0 {
+		return MutationResult{}, errs.BadRequest("At least one mutation is required")
 	}
 
 	// Validate table names
-	tblNames := reqset.TableNames()
-	tbls, err := e.fetchMetaTablesByName(famName, tblNames)
-	if err != nil {
-		return errors.Wrap(err, "fetch meta tables error")
-	}
+	tbls := map[schema.FamilyTable]sqlgen.MetaTable{}
+	refs := map[schema.FamilyTable]tableReferences{}
+	famNames := reqset.FamilyNames()
+	for _, famName := range famNames {
+		tblNames := reqset.TableNames(famName)
+		famTbls, err := e.fetchMetaTablesByName(famName, tblNames)
+		if err != nil {
+			return MutationResult{}, errors.Wrap(err, "fetch meta tables error")
+		}
 
-	for _, tblName := range tblNames {
-		if _, ok := tbls[tblName]; !ok {
-			return errors.Errorf("Table not found: %s", tblName)
+		for _, tblName := range tblNames {
+			tbl, ok := famTbls[tblName]
+			if !ok {
+				return MutationResult{}, errors.Errorf("Table not found: %s", tblName)
+			}
+			tbls[schema.FamilyTable{Family: famName.Name, Table: tblName.Name}] = tbl
+		}
+
+		famRefs, err := readStrictReferences(ctx, e.DB, famName)
+		if err != nil {
+			return MutationResult{}, err
+		}
+		for tblName, tblRefs := range famRefs {
+			refs[schema.FamilyTable{Family: famName.Name, Table: tblName.Name}] = tblRefs
 		}
 	}
 
@@ -491,28 +1083,33 @@ func (e *dbExecutive) Mutate(
 	// dope, y'all.
 	tx, err := e.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return errors.Wrap(err, "begin tx error")
+		return MutationResult{}, errors.Wrap(err, "begin tx error")
 	}
 	defer tx.Rollback()
 
-	// First check to make sure we can actually make these mutations
-	allowed, err := e.limiter.allowed(ctx, tx, limiterRequest{
+	// We must first take the ledger lock in order to prevent ledger anomalies.
+	// See the method documentation for more information. It's taken before
+	// anything is read in the transaction, so that on MySQL the transaction's
+	// snapshot is taken once the mutations before it have been committed,
+	// which the conditional mutations and unique constraints are checked
+	// against.
+	err = e.takeLedgerLock(ctx, tx)
+	if err != nil {
+		return MutationResult{}, errors.Wrap(err, "taking ledger lock")
+	}
+
+	// Then check to make sure we can actually make these mutations
+	usage, err := e.limiter.allowed(ctx, tx, limiterRequest{
 		writerName: writerName,
-		familyName: familyName,
 		requests:   requests,
 	})
 	if err != nil {
-		return err
-	}
-	if !allowed {
-		return &errs.RateLimitExceededErr{Err: "rate limit exceeded"}
+		return MutationResult{}, err
 	}
 
-	// We must first take the ledger lock in order to prevent ledger anomalies.
-	// See the method documentation for more information.
-	err = e.takeLedgerLock(ctx, tx)
+	err = checkMaintenance(ctx, tx)
 	if err != nil {
-		return errors.Wrap(err, "taking ledger lock")
+		return MutationResult{}, err
 	}
 
 	// Check Cookie
@@ -525,16 +1122,17 @@ func (e *dbExecutive) Mutate(
 	// If the writer doesn't exist, this will ErrCookieConflict. That is good,
 	// because the writer should "create" itself by first calling the
 	// GetWriterCookie endpoint.
-	err = ms.Update(wn, writerSecret, cookie, checkCookie)
+	err = ms.Update(wn, writerSecret, cookie, checkCookie, &mutatorActivity{
+		At:        time.Now(),
+		SourceIP:  e.SourceIP,
+		Mutations: len(reqset.Requests),
+	})
 	if err != nil {
-		return err
+		return MutationResult{}, err
 	}
 
 	// Now apply all the requests
-	dlw := dmlLedgerWriter{
-		Tx:        tx,
-		TableName: dmlLedgerTableName,
-	}
+	dlw := e.ledgerWriter(tx)
 	defer dlw.Close()
 
 	// To retain transactionality in the log itself, transaction
@@ -544,88 +1142,161 @@ func (e *dbExecutive) Mutate(
 	if len(reqset.Requests) > 1 {
 		_, err := dlw.BeginTx(ctx)
 		if err != nil {
-			return errors.Wrap(err, "logging tx begin failed")
+			return MutationResult{}, errors.Wrap(err, "logging tx begin failed")
 		}
 	}
 
 	var lastSeq schema.DMLSequence
-	for _, req := range reqset.Requests {
+	result := MutationResult{Skipped: []int{}}
+	// the number of mutations applied to each table, by family, which the
+	// family's webhooks are notified of
+	appliedByFamily := map[string]map[string]int{}
+	for i, req := range reqset.Requests {
 		// TODO: wrap errors in here by request index
-		tbl := tbls[req.TableName]
+		ft := schema.FamilyTable{Family: req.FamilyName.Name, Table: req.TableName.Name}
+		tbl := tbls[ft]
+
+		ok, err := req.conditionHolds(ctx, tx, tbl)
+		if err != nil {
+			return MutationResult{}, err
+		}
+		if !ok {
+			result.Skipped = append(result.Skipped, i)
+			continue
+		}
 
 		var values []interface{}
-		var dmlSQL string
+		var dml, ledgerDML schema.ParameterizedDML
 
 		// Generate the DML first
 		if !req.Delete {
 			// UPSERT
-			values, err = req.valuesByOrder(tbl.FieldNames())
+			var fieldNames []schema.FieldName
+			fieldNames, values, err = req.upsertValues(tbl)
 			if err != nil {
-				return err
+				return MutationResult{}, err
+			}
+			if err = refs[ft].check(ctx, tx, fieldNames, values); err != nil {
+				return MutationResult{}, err
+			}
+			if err = checkUniqueConstraints(ctx, tx, tbl, fieldNames, values); err != nil {
+				return MutationResult{}, err
 			}
 
-			dmlSQL, err = tbl.UpsertDML(values)
+			dml, ledgerDML, err = e.upsertDML(tbl, fieldNames, values)
 			if err != nil {
-				return err
+				return MutationResult{}, err
 			}
 		} else {
 			// DELETE
 			values, err = req.valuesByOrder(tbl.KeyFields.Fields)
 			if err != nil {
-				return err
+				return MutationResult{}, err
 			}
 
-			dmlSQL, err = tbl.DeleteDML(values)
+			if e.ParameterizedDML {
+				dml, err = tbl.DeleteParameterizedDML(values)
+			} else {
+				dml.SQL, err = tbl.DeleteDML(values)
+			}
 			if err != nil {
-				return err
+				return MutationResult{}, err
+			}
+			ledgerDML = dml
+		}
+
+		ledgerStatement, ledgerVersion := ledgerDML.SQL, schema.DMLVersionSQL
+		if e.ParameterizedDML {
+			ledgerStatement, err = ledgerDML.Encode()
+			if err != nil {
+				return MutationResult{}, err
 			}
+			ledgerVersion = schema.DMLVersionParameterized
 		}
 
-		if len(dmlSQL) > limits.LimitMaxDMLSize {
-			return &errs.BadRequestError{Err: "Request generated too large of a DML statement"}
+		if len(ledgerStatement) > limits.LimitMaxDMLSize {
+			return MutationResult{}, &errs.BadRequestError{Err: "Request generated too large of a DML statement"}
 		}
 
 		// Execute the actual DML write
-		_, err = tx.ExecContext(ctx, dmlSQL)
+		_, err = tx.ExecContext(ctx, dml.SQL, dml.Args...)
 		if err != nil {
-			events.Log("dml exec error, Request: %{req}+v SQL: %{sql}s", req, dmlSQL)
-			return errors.Wrap(err, "dml exec error")
+			events.Log("dml exec error, Request: %{req}+v SQL: %{sql}s", req, dml.SQL)
+			return MutationResult{}, errors.Wrap(err, "dml exec error")
 		}
 
 		// Now record it in the log table
-		lastSeq, err = dlw.Add(ctx, dmlSQL)
+		lastSeq, err = dlw.AddVersion(ctx, ledgerStatement, ledgerVersion)
 		if err != nil {
-			return errors.Wrap(err, "log write error")
+			return MutationResult{}, errors.Wrap(err, "log write error")
+		}
+		result.Applied++
+		result.DMLBytes += len(ledgerStatement)
+		if appliedByFamily[req.FamilyName.Name] == nil {
+			appliedByFamily[req.FamilyName.Name] = map[string]int{}
 		}
+		appliedByFamily[req.FamilyName.Name][req.TableName.Name]++
 	}
 
 	if len(reqset.Requests) > 1 {
 		lastSeq, err = dlw.CommitTx(ctx)
 		if err != nil {
-			return errors.Wrap(err, "logging tx commit failed")
+			return MutationResult{}, errors.Wrap(err, "logging tx commit failed")
 		}
 	}
 
 	err = tx.Commit()
 	if err != nil {
-		return errors.Wrap(err, "commit failed")
+		return MutationResult{}, errors.Wrap(err, "commit failed")
 	}
 
 	events.Debug(
-		"Mutate success on family %{familyName}s "+
+		"Mutate success on families %{familyNames}v "+
 			"applied %{mutationCount}d mutations "+
 			"at seq %{lastSeq}d "+
 			"by writer %{writerName}s",
-		famName,
-		len(requests),
+		famNames,
+		result.Applied,
 		lastSeq.Int(),
 		writerName,
 	)
 
-	return nil
+	for _, famName := range famNames {
+		applied, ok := appliedByFamily[famName.Name]
+		if !ok {
+			continue
+		}
+		notification := WebhookNotification{
+			Event:     WebhookEventMutation,
+			Family:    famName.Name,
+			Writer:    writerName,
+			LedgerSeq: lastSeq.Int(),
+		}
+		for table, n := range applied {
+			notification.Tables = append(notification.Tables, table)
+			notification.Mutations += n
+		}
+		sort.Strings(notification.Tables)
+		e.webhooks.notify(notification)
+	}
+
+	result.LedgerSeq = lastSeq.Int()
+	e.ledgerSeq.advance(result.LedgerSeq)
+	if usage.ResetAt != nil {
+		remaining := usage.Limit - usage.Current
+		if remaining < 0 {
+			remaining = 0
+		}
+		result.RateLimit = &RateLimitBudget{Limit: usage.Limit, Remaining: remaining, ResetAt: *usage.ResetAt}
+	}
+	return result, nil
 }
 
 func (e *dbExecutive) fetchMetaTablesByName(famName schema.FamilyName, tblNames []schema.TableName) (map[schema.TableName]sqlgen.MetaTable, error) {
+	return e.fetchMetaTablesByNameFrom(e.DB, famName, tblNames)
+}
+
+func (e *dbExecutive) fetchMetaTablesByNameFrom(db *sql.DB, famName schema.FamilyName, tblNames []schema.TableName) (map[schema.TableName]sqlgen.MetaTable, error) {
 	ctx, cancel := e.ctx()
 	defer cancel()
 
@@ -638,14 +1309,14 @@ func (e *dbExecutive) fetchMetaTablesByName(famName schema.FamilyName, tblNames
 		encodedTableNames = append(encodedTableNames, schema.LDBTableName(famName, tblName))
 	}
 
-	dbInfo := getDBInfo(e.DB)
+	dbInfo := getDBInfo(db)
 	colInfos, err := dbInfo.GetColumnInfo(ctx, encodedTableNames)
 	if err != nil {
 		return nil, err
 	}
 
 	tbls := map[schema.TableName]sqlgen.MetaTable{}
-	driverName := sqlgen.SqlDriverToDriverName(e.DB.Driver())
+	driverName := sqlgen.SqlDriverToDriverName(db.Driver())
 
 	var tbl sqlgen.MetaTable
 	for _, colInfo := range colInfos {
@@ -691,6 +1362,22 @@ func (e *dbExecutive) fetchMetaTablesByName(famName schema.FamilyName, tblNames
 		if colInfo.IsPrimaryKey {
 			tbl.KeyFields.Fields = append(tbl.KeyFields.Fields, fn)
 		}
+
+		opts, err := columnFieldOptions(colInfo, ft)
+		if err != nil {
+			return nil, err
+		}
+		// MySQL reports key columns as NOT NULL whether or not they were
+		// created that way
+		if colInfo.IsPrimaryKey {
+			opts.NotNull = false
+		}
+		if opts != (schema.FieldOptions{}) {
+			if tbl.FieldOptions == nil {
+				tbl.FieldOptions = map[schema.FieldName]schema.FieldOptions{}
+			}
+			tbl.FieldOptions[fn] = opts
+		}
 	}
 
 	// for loop will exit before "current" table is added to map
@@ -698,11 +1385,26 @@ func (e *dbExecutive) fetchMetaTablesByName(famName schema.FamilyName, tblNames
 		tbls[tbl.TableName] = tbl
 	}
 
+	uniqueConstraints, err := readUniqueConstraints(ctx, db, famName)
+	if err != nil {
+		return nil, err
+	}
+	for tblName, constraints := range uniqueConstraints {
+		if tbl, ok := tbls[tblName]; ok {
+			tbl.UniqueConstraints = constraints
+			tbls[tblName] = tbl
+		}
+	}
+
 	return tbls, nil
 }
 
 func (e *dbExecutive) fetchMetaTableByName(famName schema.FamilyName, tblName schema.TableName) (tbl sqlgen.MetaTable, ok bool, err error) {
-	tbls, err := e.fetchMetaTablesByName(famName, []schema.TableName{tblName})
+	return e.fetchMetaTableByNameFrom(e.DB, famName, tblName)
+}
+
+func (e *dbExecutive) fetchMetaTableByNameFrom(db *sql.DB, famName schema.FamilyName, tblName schema.TableName) (tbl sqlgen.MetaTable, ok bool, err error) {
+	tbls, err := e.fetchMetaTablesByNameFrom(db, famName, []schema.TableName{tblName})
 	if err != nil {
 		return
 	}
@@ -711,6 +1413,61 @@ func (e *dbExecutive) fetchMetaTableByName(famName schema.FamilyName, tblName sc
 	return
 }
 
+// columnFieldOptions converts the default and nullability of a column to
+// the FieldOptions of a field of type ft.
+func columnFieldOptions(colInfo schema.DBColumnInfo, ft schema.FieldType) (schema.FieldOptions, error) {
+	opts := schema.FieldOptions{NotNull: colInfo.NotNull}
+	if colInfo.Default == nil {
+		return opts, nil
+	}
+	def := *colInfo.Default
+	switch ft {
+	case schema.FTInteger:
+		v, err := strconv.ParseInt(def, 10, 64)
+		if err != nil {
+			return opts, errors.Wrapf(err, "parse default of %s", colInfo.ColumnName)
+		}
+		opts.Default = v
+	case schema.FTDecimal:
+		v, err := strconv.ParseFloat(def, 64)
+		if err != nil {
+			return opts, errors.Wrapf(err, "parse default of %s", colInfo.ColumnName)
+		}
+		opts.Default = v
+	case schema.FTByteString:
+		raw := []byte(def)
+		// MySQL reports binary defaults as hex literals
+		if strings.HasPrefix(def, "0x") {
+			b, err := hex.DecodeString(def[2:])
+			if err != nil {
+				return opts, errors.Wrapf(err, "parse default of %s", colInfo.ColumnName)
+			}
+			raw = b
+		}
+		opts.Default = base64.StdEncoding.EncodeToString(raw)
+	default:
+		opts.Default = def
+	}
+	return opts, nil
+}
+
+// fieldOptionsByName validates the names of fieldOptions, as supplied to
+// the executive, and keys them by field name.
+func fieldOptionsByName(fieldOptions map[string]schema.FieldOptions) (map[schema.FieldName]schema.FieldOptions, error) {
+	if len(fieldOptions) == 0 {
+		return nil, nil
+	}
+	res := make(map[schema.FieldName]schema.FieldOptions, len(fieldOptions))
+	for name, opts := range fieldOptions {
+		fn, err := schema.NewFieldName(name)
+		if err != nil {
+			return nil, &errs.BadRequestError{Err: err.Error()}
+		}
+		res[fn] = opts
+	}
+	return res, nil
+}
+
 // Represents a family as persisted to ctldb
 type dbFamily struct {
 	ID   int64
@@ -765,6 +1522,48 @@ func (e *dbExecutive) RegisterWriter(writerName string, secret string) error {
 	return ms.Register(wn, secret)
 }
 
+// CheckWriterSecret returns whether the writer is registered with the
+// secret, so that re-registering it can be told apart from registering a
+// new writer.
+func (e *dbExecutive) CheckWriterSecret(writerName string, secret string) (bool, error) {
+	ctx, cancel := e.ctx()
+	defer cancel()
+
+	wn, err := schema.NewWriterName(writerName)
+	if err != nil {
+		return false, &errs.BadRequestError{Err: err.Error()}
+	}
+	ms := mutatorStore{
+		DB:        e.DB,
+		Ctx:       ctx,
+		TableName: mutatorsTableName,
+	}
+	return ms.registeredWith(wn, hashMutatorSecret(secret))
+}
+
+func (e *dbExecutive) ReadWriters() ([]WriterInfo, error) {
+	ctx, cancel := e.ctx()
+	defer cancel()
+
+	ms := mutatorStore{
+		DB:        e.readDB(),
+		Ctx:       ctx,
+		TableName: mutatorsTableName,
+	}
+	writers, err := ms.List()
+	if err != nil {
+		return nil, err
+	}
+	if e.limiter != nil {
+		for i := range writers {
+			group, limit := e.limiter.limitForWriter(writers[i].Name)
+			writers[i].RateLimitGroup = group
+			writers[i].RateLimit = limits.RateLimit{Amount: limit.amount, Period: e.limiter.defaultWriterLimit.Period, Burst: limit.burst}
+		}
+	}
+	return writers, nil
+}
+
 func (e *dbExecutive) ReadRow(familyName string, tableName string, where map[string]interface{}) (map[string]interface{}, error) {
 	ctx, cancel := e.ctx()
 	defer cancel()
@@ -779,7 +1578,7 @@ func (e *dbExecutive) ReadRow(familyName string, tableName string, where map[str
 		return nil, &errs.BadRequestError{Err: err.Error()}
 	}
 
-	metaTable, ok, err := e.fetchMetaTableByName(famName, tblName)
+	metaTable, ok, err := e.fetchMetaTableByNameFrom(e.readDB(), famName, tblName)
 	if err != nil {
 		return nil, err
 	}
@@ -827,7 +1626,7 @@ func (e *dbExecutive) ReadRow(familyName string, tableName string, where map[str
 	qs = qs + strings.Join(whereClauseParts, " AND ") + " LIMIT 1"
 
 	out := map[string]interface{}{}
-	rows, err := e.DB.QueryContext(ctx, qs, qsArgs...)
+	rows, err := e.readDB().QueryContext(ctx, qs, qsArgs...)
 	if err == sql.ErrNoRows || !rows.Next() {
 		rows.Close()
 		return out, nil
@@ -850,11 +1649,136 @@ func (e *dbExecutive) ReadRow(familyName string, tableName string, where map[str
 	return out, err
 }
 
+// ReadRows calls fn with each row selected by the query, stopping at the
+// first error.
+func (e *dbExecutive) ReadRows(familyName string, tableName string, query RowsQuery, fn func(row map[string]interface{}) error) error {
+	ctx, cancel := e.ctx()
+	defer cancel()
+
+	famName, err := schema.NewFamilyName(familyName)
+	if err != nil {
+		return &errs.BadRequestError{Err: err.Error()}
+	}
+	tblName, err := schema.NewTableName(tableName)
+	if err != nil {
+		return &errs.BadRequestError{Err: err.Error()}
+	}
+	metaTable, ok, err := e.fetchMetaTableByNameFrom(e.readDB(), famName, tblName)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return &errs.NotFoundError{Err: "Table not found"}
+	}
+
+	keys := make([]string, 0, len(metaTable.KeyFields.Fields))
+	placeholders := make([]string, 0, len(metaTable.KeyFields.Fields))
+	for _, kf := range metaTable.KeyFields.Fields {
+		keys = append(keys, kf.Name)
+		placeholders = append(placeholders, "?")
+	}
+
+	qs := "SELECT * FROM " + schema.LDBTableName(famName, tblName)
+	var qsArgs []interface{}
+	if query.After != nil {
+		if len(query.After) != len(keys) {
+			return errs.BadRequest("after must have a value for each of the %d key fields", len(keys))
+		}
+		qs += fmt.Sprintf(" WHERE (%s) > (%s)", strings.Join(keys, ", "), strings.Join(placeholders, ", "))
+		qsArgs = append(qsArgs, query.After...)
+	}
+	qs += fmt.Sprintf(" ORDER BY %s LIMIT %d OFFSET %d", strings.Join(keys, ", "), query.Limit, query.Offset)
+
+	rows, err := e.readDB().QueryContext(ctx, qs, qsArgs...)
+	if err != nil {
+		return errors.Wrap(err, "select rows")
+	}
+	defer rows.Close()
+	cols, err := schema.DBColumnMetaFromRows(rows)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		row := map[string]interface{}{}
+		sfn, err := scanfunc.New(row, cols)
+		if err != nil {
+			return err
+		}
+		if err := sfn(rows); err != nil {
+			return errors.Wrap(err, "scan row")
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return errors.Wrap(rows.Err(), "read rows")
+}
+
+func (e *dbExecutive) ReadLedger(query LedgerQuery) ([]LedgerEntry, error) {
+	ctx, cancel := e.ctx()
+	defer cancel()
+
+	columns := "seq, leader_ts, statement"
+	if e.RecordTraceIDs {
+		columns += ", trace_id"
+	}
+	if e.ParameterizedDML {
+		columns += ", version"
+	}
+	qs := sqlgen.SqlSprintf("SELECT $1 FROM $2 WHERE seq >= ?", columns, dmlLedgerTableName)
+	qsArgs := []interface{}{query.FromSeq}
+	if query.ToSeq != 0 {
+		qs += " AND seq <= ?"
+		qsArgs = append(qsArgs, query.ToSeq)
+	}
+	qs += fmt.Sprintf(" ORDER BY seq LIMIT %d", query.Limit)
+
+	rows, err := e.readDB().QueryContext(ctx, qs, qsArgs...)
+	if err != nil {
+		return nil, errors.Wrap(err, "select ledger entries")
+	}
+	defer rows.Close()
+	var entries []LedgerEntry
+	for rows.Next() {
+		var entry LedgerEntry
+		var leaderTs, traceID sql.NullString
+		dest := []interface{}{&entry.Seq, &leaderTs, &entry.Statement}
+		if e.RecordTraceIDs {
+			dest = append(dest, &traceID)
+		}
+		if e.ParameterizedDML {
+			dest = append(dest, &entry.Version)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, errors.Wrap(err, "scan ledger entry")
+		}
+		entry.TraceID = traceID.String
+		// mysql returns the timestamp as text, while sqlite returns
+		// a time which database/sql formats as RFC3339
+		for _, layout := range []string{"2006-01-02 15:04:05", time.RFC3339Nano} {
+			if ts, err := time.Parse(layout, leaderTs.String); err == nil {
+				entry.Timestamp = &ts
+				break
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, errors.Wrap(rows.Err(), "read ledger entries")
+}
+
+// ReadLedgerSeq returns the sequence of the last ledger entry, which may be
+// cached for up to a second.
+func (e *dbExecutive) ReadLedgerSeq() (int64, error) {
+	ctx, cancel := e.ctx()
+	defer cancel()
+	return e.ledgerSeq.get(ctx, e.DB)
+}
+
 func (e *dbExecutive) ReadTableSizeLimits() (res limits.TableSizeLimits, err error) {
 	ctx, cancel := e.ctx()
 	defer cancel()
 	res.Global = e.limiter.tableSizer.defaultTableLimit
-	rows, err := e.DB.QueryContext(ctx,
+	rows, err := e.readDB().QueryContext(ctx,
 		"select family_name, table_name, warn_size_bytes, max_size_bytes "+
 			"FROM max_table_sizes "+
 			"ORDER BY family_name, table_name")
@@ -872,6 +1796,13 @@ func (e *dbExecutive) ReadTableSizeLimits() (res limits.TableSizeLimits, err err
 	return res, rows.Err()
 }
 
+// ReadTableSizeProjection estimates when the table will reach its max size.
+func (e *dbExecutive) ReadTableSizeProjection(ft schema.FamilyTable) (limits.TableSizeProjection, error) {
+	ctx, cancel := e.ctx()
+	defer cancel()
+	return e.limiter.tableSizer.projection(ctx, ft)
+}
+
 func (e *dbExecutive) UpdateTableSizeLimit(limit limits.TableSizeLimit) error {
 	ctx, cancel := e.ctx()
 	defer cancel()
@@ -930,8 +1861,8 @@ func (e *dbExecutive) ReadWriterRateLimits() (res limits.WriterRateLimits, err e
 	ctx, cancel := e.ctx()
 	defer cancel()
 	res.Global = e.limiter.defaultWriterLimit
-	rows, err := e.DB.QueryContext(ctx,
-		"select writer_name, max_rows_per_minute "+
+	rows, err := e.readDB().QueryContext(ctx,
+		"select writer_name, max_rows_per_minute, burst "+
 			"FROM max_writer_rates "+
 			"ORDER BY writer_name")
 	if err != nil {
@@ -941,7 +1872,7 @@ func (e *dbExecutive) ReadWriterRateLimits() (res limits.WriterRateLimits, err e
 	for rows.Next() {
 		var wrl limits.WriterRateLimit
 		wrl.RateLimit.Period = time.Minute
-		if err := rows.Scan(&wrl.Writer, &wrl.RateLimit.Amount); err != nil {
+		if err := rows.Scan(&wrl.Writer, &wrl.RateLimit.Amount, &wrl.RateLimit.Burst); err != nil {
 			return res, errors.Wrap(err, "scan writer rates")
 		}
 		res.Writers = append(res.Writers, wrl)
@@ -976,9 +1907,12 @@ func (e *dbExecutive) UpdateWriterRateLimit(limit limits.WriterRateLimit) error
 	if err != nil {
 		return errors.Wrap(err, "check limit")
 	}
+	if limit.RateLimit.Burst < 0 {
+		return errors.Errorf("invalid burst: %d", limit.RateLimit.Burst)
+	}
 	res, err := tx.ExecContext(ctx, "replace into max_writer_rates "+
-		"(writer_name, max_rows_per_minute) "+
-		"values (?, ?)", limit.Writer, adjustedAmount)
+		"(writer_name, max_rows_per_minute, burst) "+
+		"values (?, ?, ?)", limit.Writer, adjustedAmount, limit.RateLimit.Burst)
 	if err != nil {
 		return errors.Wrap(err, "replace into max_writer_rates")
 	}
@@ -1012,7 +1946,250 @@ func (e *dbExecutive) DeleteWriterRateLimit(writerName string) error {
 	return nil
 }
 
-func (e *dbExecutive) DropTable(table schema.FamilyTable) error {
+func (e *dbExecutive) ReadWriterGroups() ([]limits.WriterGroup, error) {
+	ctx, cancel := e.ctx()
+	defer cancel()
+	rows, err := e.readDB().QueryContext(ctx,
+		"select g.group_name, g.max_rows_per_minute, g.burst, m.writer_name "+
+			"FROM writer_groups g LEFT JOIN writer_group_members m ON m.group_name = g.group_name "+
+			"ORDER BY g.group_name, m.writer_name")
+	if err != nil {
+		return nil, errors.Wrap(err, "select writer groups")
+	}
+	defer rows.Close()
+	res := []limits.WriterGroup{}
+	for rows.Next() {
+		var groupName string
+		var amount, burst int64
+		var writerName sql.NullString
+		if err := rows.Scan(&groupName, &amount, &burst, &writerName); err != nil {
+			return nil, errors.Wrap(err, "scan writer groups")
+		}
+		if len(res) == 0 || res[len(res)-1].Name != groupName {
+			res = append(res, limits.WriterGroup{
+				Name:      groupName,
+				RateLimit: limits.RateLimit{Amount: amount, Period: time.Minute, Burst: burst},
+				Members:   []string{},
+			})
+		}
+		if writerName.Valid {
+			group := &res[len(res)-1]
+			group.Members = append(group.Members, writerName.String)
+		}
+	}
+	return res, rows.Err()
+}
+
+// UpdateWriterGroup creates the group, or changes its limit if it exists.
+func (e *dbExecutive) UpdateWriterGroup(groupName string, limit limits.RateLimit) error {
+	ctx, cancel := e.ctx()
+	defer cancel()
+	// group names follow the same rules as writer names
+	if _, err := schema.NewWriterName(groupName); err != nil {
+		return &errs.BadRequestError{Err: err.Error()}
+	}
+	adjustedAmount, err := limit.AdjustAmount(time.Minute)
+	if err != nil {
+		return &errs.BadRequestError{Err: err.Error()}
+	}
+	if limit.Burst < 0 {
+		return errs.BadRequest("invalid burst: %d", limit.Burst)
+	}
+	_, err = e.DB.ExecContext(ctx, "replace into writer_groups "+
+		"(group_name, max_rows_per_minute, burst) "+
+		"values (?, ?, ?)", groupName, adjustedAmount, limit.Burst)
+	return errors.Wrap(err, "replace into writer_groups")
+}
+
+// DeleteWriterGroup deletes the group, and its members go back to their own
+// limits.
+func (e *dbExecutive) DeleteWriterGroup(groupName string) error {
+	ctx, cancel := e.ctx()
+	defer cancel()
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "start tx")
+	}
+	defer tx.Rollback()
+	res, err := tx.ExecContext(ctx, "delete from writer_groups where group_name=?", groupName)
+	if err != nil {
+		return errors.Wrap(err, "delete from writer_groups")
+	}
+	ra, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "get rows affected from writer_groups")
+	}
+	if ra <= 0 {
+		return &errs.NotFoundError{Err: fmt.Sprintf("no writer group named '%s' was found", groupName)}
+	}
+	members, err := groupMembers(ctx, tx, groupName)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		if err := e.auditWriterGroup(ctx, tx, groupName, member, WriterGroupMemberRemoved); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, "delete from writer_group_members where group_name=?", groupName); err != nil {
+		return errors.Wrap(err, "delete from writer_group_members")
+	}
+	if _, err := tx.ExecContext(ctx, "delete from writer_group_usage where group_name=?", groupName); err != nil {
+		return errors.Wrap(err, "delete from writer_group_usage")
+	}
+	return errors.Wrap(tx.Commit(), "commit tx")
+}
+
+// AddWriterGroupMember moves the writer into the group. If a secret is
+// given, the writer is registered with it first, which lets an admin hand
+// out credentials for new members of the group. Otherwise the writer must
+// already exist.
+func (e *dbExecutive) AddWriterGroupMember(groupName string, writerName string, writerSecret string) error {
+	ctx, cancel := e.ctx()
+	defer cancel()
+	wn, err := schema.NewWriterName(writerName)
+	if err != nil {
+		return &errs.BadRequestError{Err: err.Error()}
+	}
+	var exists int64
+	err = e.DB.QueryRowContext(ctx, "select count(*) from writer_groups where group_name=?", groupName).Scan(&exists)
+	if err != nil {
+		return errors.Wrap(err, "select from writer_groups")
+	}
+	if exists == 0 {
+		return &errs.NotFoundError{Err: fmt.Sprintf("no writer group named '%s' was found", groupName)}
+	}
+
+	if writerSecret != "" {
+		if err := e.RegisterWriter(writerName, writerSecret); err != nil {
+			if err == ErrWriterAlreadyExists {
+				return &errs.ConflictError{Err: "writer already exists with a different secret"}
+			}
+			return err
+		}
+	} else {
+		ms := mutatorStore{DB: e.DB, Ctx: ctx, TableName: mutatorsTableName}
+		ok, err := ms.Exists(wn)
+		if err != nil {
+			return errors.Wrap(err, "check writer exists")
+		}
+		if !ok {
+			return &errs.NotFoundError{Err: fmt.Sprintf("no writer with the name '%s' exists", writerName)}
+		}
+	}
+
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "start tx")
+	}
+	defer tx.Rollback()
+	var previousGroup string
+	err = tx.QueryRowContext(ctx, "select group_name from writer_group_members where writer_name=?", writerName).Scan(&previousGroup)
+	switch {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		return errors.Wrap(err, "select from writer_group_members")
+	case previousGroup == groupName:
+		// already a member
+		return nil
+	default:
+		if err := e.auditWriterGroup(ctx, tx, previousGroup, writerName, WriterGroupMemberRemoved); err != nil {
+			return err
+		}
+	}
+	_, err = tx.ExecContext(ctx, "replace into writer_group_members "+
+		"(writer_name, group_name) "+
+		"values (?, ?)", writerName, groupName)
+	if err != nil {
+		return errors.Wrap(err, "replace into writer_group_members")
+	}
+	if err := e.auditWriterGroup(ctx, tx, groupName, writerName, WriterGroupMemberAdded); err != nil {
+		return err
+	}
+	return errors.Wrap(tx.Commit(), "commit tx")
+}
+
+func (e *dbExecutive) RemoveWriterGroupMember(groupName string, writerName string) error {
+	ctx, cancel := e.ctx()
+	defer cancel()
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "start tx")
+	}
+	defer tx.Rollback()
+	res, err := tx.ExecContext(ctx, "delete from writer_group_members where group_name=? and writer_name=?", groupName, writerName)
+	if err != nil {
+		return errors.Wrap(err, "delete from writer_group_members")
+	}
+	ra, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "get rows affected from writer_group_members")
+	}
+	if ra <= 0 {
+		return &errs.NotFoundError{Err: fmt.Sprintf("writer '%s' is not in the group '%s'", writerName, groupName)}
+	}
+	if err := e.auditWriterGroup(ctx, tx, groupName, writerName, WriterGroupMemberRemoved); err != nil {
+		return err
+	}
+	return errors.Wrap(tx.Commit(), "commit tx")
+}
+
+// ReadWriterGroupAudit returns the audit records of the changes to the
+// group's members, oldest first.
+func (e *dbExecutive) ReadWriterGroupAudit(groupName string) ([]WriterGroupEvent, error) {
+	ctx, cancel := e.ctx()
+	defer cancel()
+
+	rows, err := e.readDB().QueryContext(ctx, "select group_name, writer_name, action, source_ip, created_at "+
+		"from writer_group_audit where group_name=? order by id", groupName)
+	if err != nil {
+		return nil, errors.Wrap(err, "select from writer_group_audit")
+	}
+	defer rows.Close()
+	res := []WriterGroupEvent{}
+	for rows.Next() {
+		var event WriterGroupEvent
+		var createdAt int64
+		if err := rows.Scan(&event.Group, &event.Writer, &event.Action, &event.SourceIP, &createdAt); err != nil {
+			return nil, errors.Wrap(err, "scan writer_group_audit")
+		}
+		event.At = time.Unix(createdAt, 0).UTC()
+		res = append(res, event)
+	}
+	return res, rows.Err()
+}
+
+func (e *dbExecutive) auditWriterGroup(ctx context.Context, tx *sql.Tx, groupName string, writerName string, action string) error {
+	_, err := tx.ExecContext(ctx, "insert into writer_group_audit "+
+		"(group_name, writer_name, action, source_ip, created_at) values (?, ?, ?, ?, ?)",
+		groupName, writerName, action, e.SourceIP, time.Now().Unix())
+	if err != nil {
+		return errors.Wrap(err, "insert into writer_group_audit")
+	}
+	events.Log("Writer group %{group}s member %{writer}s was %{action}s from %{sourceIP}s",
+		groupName, writerName, action, e.SourceIP)
+	return nil
+}
+
+func groupMembers(ctx context.Context, tx *sql.Tx, groupName string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, "select writer_name from writer_group_members where group_name=? order by writer_name", groupName)
+	if err != nil {
+		return nil, errors.Wrap(err, "select from writer_group_members")
+	}
+	defer rows.Close()
+	var members []string
+	for rows.Next() {
+		var member string
+		if err := rows.Scan(&member); err != nil {
+			return nil, errors.Wrap(err, "scan writer_group_members")
+		}
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
+
+func (e *dbExecutive) DropTable(table schema.FamilyTable) (err error) {
+	defer e.trace("executive.DropTable", tracing.String("family", table.Family), tracing.String("table", table.Table))(&err)
 	ctx, cancel := e.ctx()
 	defer cancel()
 
@@ -1057,10 +2234,7 @@ func (e *dbExecutive) DropTable(table schema.FamilyTable) error {
 		return errors.Wrap(err, "take ledger lock")
 	}
 
-	dlw := dmlLedgerWriter{
-		Tx:        tx,
-		TableName: dmlLedgerTableName,
-	}
+	dlw := e.ledgerWriter(tx)
 	defer dlw.Close()
 
 	_, err = tx.ExecContext(ctx, ddl)
@@ -1073,17 +2247,111 @@ func (e *dbExecutive) DropTable(table schema.FamilyTable) error {
 		return errors.Wrap(err, "error inserting drop command into ledger")
 	}
 
+	// the table's references go with it, as do references to it
+	for _, qs := range []string{
+		"DELETE FROM field_references WHERE family_name = ? AND table_name = ?",
+		"DELETE FROM field_references WHERE ref_family_name = ? AND ref_table_name = ?",
+		"DELETE FROM strict_reference_tables WHERE family_name = ? AND table_name = ?",
+	} {
+		if _, err = tx.ExecContext(ctx, qs, famName.Name, tblName.Name); err != nil {
+			return errors.Wrap(err, "error deleting references")
+		}
+	}
+	_, err = tx.ExecContext(ctx, "DELETE FROM unique_constraints WHERE family_name = ? AND table_name = ?",
+		famName.Name, tblName.Name)
+	if err != nil {
+		return errors.Wrap(err, "error deleting unique constraints")
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return errors.Wrap(err, "error committing transaction")
 	}
 
 	events.Log("Successfully dropped `%{tableName}s` at seq %{seq}v", table.String(), seq)
+	e.ledgerSeq.advance(seq.Int())
+	e.webhooks.notify(WebhookNotification{
+		Event:     WebhookEventDropTable,
+		Family:    famName.Name,
+		Tables:    []string{tblName.Name},
+		LedgerSeq: seq.Int(),
+	})
 
 	return nil
 }
 
-func (e *dbExecutive) ClearTable(table schema.FamilyTable) error {
+// DeleteFamily drops every table of a family, and then removes the family
+// along with its table size limits, table templates and defaults. With tombstones,
+// each table's rows are deleted before it's dropped, so that changelog
+// consumers see a deletion for every key.
+//
+// Each table is dropped in its own ledger transaction, so a deletion that
+// fails part way through is finished by deleting the family again.
+func (e *dbExecutive) DeleteFamily(familyName string, tombstones bool) (err error) {
+	defer e.trace("executive.DeleteFamily", tracing.String("family", familyName))(&err)
+	famName, err := schema.NewFamilyName(familyName)
+	if err != nil {
+		return &errs.BadRequestError{Err: err.Error()}
+	}
+	_, ok, err := e.fetchFamilyByName(famName)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return &errs.NotFoundError{Err: "Family not found"}
+	}
+
+	ctx, cancel := e.ctx()
+	defer cancel()
+	tables, err := getDBInfo(e.DB).GetAllTables(ctx)
+	if err != nil {
+		return errors.Wrap(err, "get table names")
+	}
+	for _, table := range tables {
+		if table.Family != famName.Name {
+			continue
+		}
+		if tombstones {
+			if err := e.ClearTable(table); err != nil {
+				return errors.Wrapf(err, "clearing table %s", table)
+			}
+		}
+		if err := e.DropTable(table); err != nil {
+			return errors.Wrapf(err, "dropping table %s", table)
+		}
+	}
+
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "error beginning transaction")
+	}
+	defer tx.Rollback()
+	for _, qs := range []string{
+		"DELETE FROM max_table_sizes WHERE family_name = ?",
+		"DELETE FROM table_templates WHERE family_name = ?",
+		"DELETE FROM family_defaults WHERE family_name = ?",
+		"DELETE FROM webhooks WHERE family_name = ?",
+		"DELETE FROM families WHERE name = ?",
+	} {
+		if _, err := tx.ExecContext(ctx, qs, famName.Name); err != nil {
+			return errors.Wrap(err, "delete family metadata")
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "error committing transaction")
+	}
+
+	events.Log("Successfully deleted family `%{familyName}s`", famName.Name)
+	// the family's webhooks were deleted with it, but are notified from the
+	// cache before it's refreshed
+	e.webhooks.notify(WebhookNotification{Event: WebhookEventDeleteFamily, Family: famName.Name})
+	e.refreshWebhooks(ctx)
+	return nil
+}
+
+func (e *dbExecutive) ClearTable(table schema.FamilyTable) (err error) {
+	defer e.trace("executive.ClearTable", tracing.String("family", table.Family), tracing.String("table", table.Table))(&err)
 	ctx, cancel := e.ctx()
 	defer cancel()
 
@@ -1129,10 +2397,7 @@ func (e *dbExecutive) ClearTable(table schema.FamilyTable) error {
 		return errors.Wrap(err, "take ledger lock")
 	}
 
-	dlw := dmlLedgerWriter{
-		Tx:        tx,
-		TableName: dmlLedgerTableName,
-	}
+	dlw := e.ledgerWriter(tx)
 	defer dlw.Close()
 
 	_, err = tx.ExecContext(ctx, ddl)
@@ -1151,6 +2416,13 @@ func (e *dbExecutive) ClearTable(table schema.FamilyTable) error {
 	}
 
 	events.Log("Successfully deleted all rows from `%{tableName}s` at seq %{seq}v", table.String(), seq)
+	e.ledgerSeq.advance(seq.Int())
+	e.webhooks.notify(WebhookNotification{
+		Event:     WebhookEventClearTable,
+		Family:    famName.Name,
+		Tables:    []string{tblName.Name},
+		LedgerSeq: seq.Int(),
+	})
 
 	return nil
 }
@@ -1160,7 +2432,7 @@ func (e *dbExecutive) ReadFamilyTableNames(family schema.FamilyName) (tables []s
 	defer cancel()
 
 	events.Debug("reading family table names where f=%s", family)
-	rows, err := e.DB.QueryContext(ctx, fmt.Sprintf(`select table_name from information_schema.tables where table_name like '%s___%%'`, family.String()))
+	rows, err := e.readDB().QueryContext(ctx, fmt.Sprintf(`select table_name from information_schema.tables where table_name like '%s___%%'`, family.String()))
 	if err != nil {
 		return nil, errors.Wrap(err, "error reading family table names")
 	}
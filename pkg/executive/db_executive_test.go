@@ -11,13 +11,17 @@
 package executive
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -25,10 +29,12 @@ import (
 	"github.com/pkg/errors"
 	"github.com/segmentio/ctlstore/pkg/ctldb"
 	"github.com/segmentio/ctlstore/pkg/errs"
+	"github.com/segmentio/ctlstore/pkg/ldb"
 	"github.com/segmentio/ctlstore/pkg/limits"
 	"github.com/segmentio/ctlstore/pkg/schema"
 	"github.com/segmentio/ctlstore/pkg/sqlgen"
 	"github.com/segmentio/ctlstore/pkg/tests"
+	"github.com/segmentio/ctlstore/pkg/tracing"
 	"github.com/segmentio/ctlstore/pkg/units"
 	"github.com/segmentio/ctlstore/pkg/utils"
 	"github.com/stretchr/testify/require"
@@ -122,18 +128,47 @@ func TestAllDBExecutive(t *testing.T) {
 		"testDBExecutiveCreateTableLocksLedger": testDBExecutiveCreateTableLocksLedger,
 		"testDBExecutiveAddFields":              testDBExecutiveAddFields,
 		"testDBExecutiveAddFieldsLocksLedger":   testDBExecutiveAddFieldsLocksLedger,
+		"testDBExecutiveAlterField":             testDBExecutiveAlterField,
+		"testDBExecutiveFieldOptions":           testDBExecutiveFieldOptions,
+		"testDBExecutiveTimestampBooleanFields": testDBExecutiveTimestampBooleanFields,
+		"testDBExecutiveJSONFields":             testDBExecutiveJSONFields,
+		"testDBExecutiveTableTemplates":         testDBExecutiveTableTemplates,
+		"testDBExecutiveReadWriters":            testDBExecutiveReadWriters,
+		"testDBExecutiveFamilyTables":           testDBExecutiveFamilyTables,
+		"testDBExecutiveReadRows":               testDBExecutiveReadRows,
+		"testDBExecutiveReadLedger":             testDBExecutiveReadLedger,
+		"testDBExecutiveReadLedgerSeq":          testDBExecutiveReadLedgerSeq,
+		"testDBExecutiveExport":                 testDBExecutiveExport,
+		"testDBExecutiveMaintenance":            testDBExecutiveMaintenance,
 		"testDBExecutiveFetchFamilyByName":      testDBExecutiveFetchFamilyByName,
 		"testDBExecutiveMutate":                 testDBExecutiveMutate,
+		"testDBExecutiveMutateFamilies":         testDBExecutiveMutateFamilies,
+		"testDBExecutiveConditionalMutate":      testDBExecutiveConditionalMutate,
+		"testDBExecutiveParameterizedDML":       testDBExecutiveParameterizedDML,
+		"testDBExecutiveRecordTraceIDs":         testDBExecutiveRecordTraceIDs,
+		"testDBExecutiveApplySchema":            testDBExecutiveApplySchema,
 		"testDBExecutiveGetWriterCookie":        testDBExecutiveGetWriterCookie,
 		"testDBExecutiveSetWriterCookie":        testDBExecutiveSetWriterCookie,
+		"testDBExecutiveCookieCAS":              testDBExecutiveCookieCAS,
 		"testFetchMetaTableByName":              testFetchMetaTableByName,
 		"testDBExecutiveRegisterWriter":         testDBExecutiveRegisterWriter,
 		"testDBExecutiveReadRow":                testDBExecutiveReadRow,
 		"testDBLimiter":                         testDBLimiter,
+		"testDBLimiterBurst":                    testDBLimiterBurst,
 		"testDBExecutiveWriterRates":            testDBExecutiveWriterRates,
+		"testDBExecutiveWriterGroups":           testDBExecutiveWriterGroups,
 		"testDBExecutiveTableLimits":            testDBExecutiveTableLimits,
 		"testDBExecutiveClearTable":             testDBExecutiveClearTable,
 		"testDBExecutiveDropTable":              testDBExecutiveDropTable,
+		"testDBExecutiveCloneTable":             testDBExecutiveCloneTable,
+		"testDBExecutiveWebhooks":               testDBExecutiveWebhooks,
+		"testDBExecutiveAPITokens":              testDBExecutiveAPITokens,
+		"testDBExecutiveFamilyDefaults":         testDBExecutiveFamilyDefaults,
+		"testDBExecutiveWriterExpiry":           testDBExecutiveWriterExpiry,
+		"testDBExecutiveWriterRegs":             testDBExecutiveWriterRegs,
+		"testDBExecutiveReferences":             testDBExecutiveReferences,
+		"testDBExecutiveUniqueConstraints":      testDBExecutiveUniqueConstraints,
+		"testDBExecutiveDeleteFamily":           testDBExecutiveDeleteFamily,
 		"testDBExecutiveReadFamilyTableNames":   testDBExecutiveReadFamilyTableNames,
 		"testDBExecutiveTableSchema":            testDBExecutiveTableSchema,
 		"testDBExecutiveFamilySchemas":          testDBExecutiveFamilySchemas,
@@ -241,7 +276,7 @@ func newDbExecTestUtil(t *testing.T, dbType string) *dbExecTestUtil {
 	db, teardown := newCtlDBTestConnection(t, dbType)
 
 	// TODO: review size limits and constraints on ctldb
-	limiter := newDBLimiter(db, dbType, testDefaultTableLimit, testDefaultWriterLimit.Period, testDefaultWriterLimit.Amount)
+	limiter := newDBLimiter(db, dbType, testDefaultTableLimit, testDefaultWriterLimit)
 	dbe := dbExecutive{DB: db, Ctx: ctx, limiter: limiter}
 
 	return &dbExecTestUtil{
@@ -463,7 +498,7 @@ func testDBExecutiveAddFieldsLocksLedger(t *testing.T, dbType string) {
 					fieldNames = append(fieldNames, fmt.Sprintf("%s_field_%d", prefix, i))
 					fieldTypes = append(fieldTypes, schema.FTText)
 				}
-				return u.e.AddFields("family1", "table2", fieldNames, fieldTypes)
+				return u.e.AddFields("family1", "table2", fieldNames, fieldTypes, nil)
 			}()
 			errs <- err
 		}(prefix)
@@ -514,6 +549,360 @@ func testDBExecutiveAddFieldsLocksLedger(t *testing.T, dbType string) {
 	}
 }
 
+func testDBExecutiveAlterField(t *testing.T, dbType string) {
+	u := newDbExecTestUtil(t, dbType)
+	defer u.Close()
+
+	err := u.e.CreateTable("family1",
+		"table2",
+		[]string{"field1", "field2", "field3"},
+		[]schema.FieldType{schema.FTString, schema.FTString, schema.FTInteger},
+		[]string{"field1"},
+	)
+	require.NoError(t, err)
+	_, err = u.db.Exec(`INSERT INTO family1___table2 (field1, field2, field3) VALUES ('a', 'b', 1)`)
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		desc     string
+		field    string
+		newField string
+		newType  schema.FieldType
+		err      error
+	}{
+		{desc: "no changes", field: "field2", err: &errs.BadRequestError{}},
+		{desc: "unknown field", field: "field9", newField: "field10", err: &errs.NotFoundError{}},
+		{desc: "existing field", field: "field2", newField: "field3", err: &errs.ConflictError{}},
+		{desc: "narrowing", field: "field3", newType: schema.FTString, err: &errs.BadRequestError{}},
+		{desc: "key field", field: "field1", newField: "field4", err: &errs.BadRequestError{}},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			err := u.e.AlterField("family1", "table2", test.field, test.newField, test.newType)
+			require.IsType(t, test.err, errors.Cause(err))
+		})
+	}
+
+	err = u.e.AlterField("family1", "table2", "field2", "field4", schema.FTText)
+	require.NoError(t, err)
+
+	tbl, err := u.e.TableSchema("family1", "table2")
+	require.NoError(t, err)
+	require.EqualValues(t, [][]string{
+		{"field1", "string"},
+		{"field4", "text"},
+		{"field3", "integer"},
+	}, tbl.Fields)
+	require.EqualValues(t, []string{"field1"}, tbl.KeyFields)
+
+	row, err := u.e.ReadRow("family1", "table2", map[string]interface{}{"field1": "a"})
+	require.NoError(t, err)
+	require.EqualValues(t, "b", row["field4"])
+
+	require.EqualValues(t, []string{
+		schema.DMLTxEndKey,
+		"ALTER TABLE _rebuild_family1___table2 RENAME TO family1___table2",
+		"DROP TABLE family1___table2",
+		"INSERT INTO _rebuild_family1___table2 SELECT * FROM family1___table2",
+		`CREATE TABLE _rebuild_family1___table2 ("field1" VARCHAR(191), "field4" TEXT, "field3" INTEGER, PRIMARY KEY("field1"));`,
+		`ALTER TABLE family1___table2 RENAME COLUMN "field2" TO "field4"`,
+		schema.DMLTxBeginKey,
+	}, queryDMLTable(t, u.db, 7))
+
+	// the ledger statements must also apply cleanly to an LDB
+	ldbDB, teardown := ldb.LDBForTest(t)
+	defer teardown()
+	_, err = ldbDB.Exec(`CREATE TABLE family1___table2 ("field1" VARCHAR(191), "field2" VARCHAR(191), "field3" INTEGER, PRIMARY KEY("field1"));
+		INSERT INTO family1___table2 VALUES ('a', 'b', 1)`)
+	require.NoError(t, err)
+	tx, err := ldbDB.Begin()
+	require.NoError(t, err)
+	statements := queryDMLTable(t, u.db, 6)[1:]
+	for i := len(statements) - 1; i >= 0; i-- {
+		_, err = tx.Exec(statements[i])
+		require.NoError(t, err)
+	}
+	require.NoError(t, tx.Commit())
+	var field4 string
+	require.NoError(t, ldbDB.QueryRow("SELECT field4 FROM family1___table2 WHERE field1='a'").Scan(&field4))
+	require.Equal(t, "b", field4)
+}
+
+func testDBExecutiveReadWriters(t *testing.T, dbType string) {
+	u := newDbExecTestUtil(t, dbType)
+	defer u.Close()
+
+	writers, err := u.e.ReadWriters()
+	require.NoError(t, err)
+	require.EqualValues(t, []WriterInfo{{Name: "writer1", CookieLength: 1, RateLimit: testDefaultWriterLimit}}, writers)
+
+	// setting the cookie directly is not a mutation
+	require.NoError(t, u.e.SetWriterCookie("writer1", "", []byte{2, 3}))
+	writers, err = u.e.ReadWriters()
+	require.NoError(t, err)
+	require.EqualValues(t, []WriterInfo{{Name: "writer1", CookieLength: 2, RateLimit: testDefaultWriterLimit}}, writers)
+
+	// newly registered writers record when they were
+	registered := time.Now().Add(-time.Second)
+	require.NoError(t, u.e.RegisterWriter("writer2", "secret"))
+	require.NoError(t, u.e.UpdateWriterRateLimit(limits.WriterRateLimit{
+		Writer:    "writer2",
+		RateLimit: limits.RateLimit{Amount: 10, Period: time.Minute},
+	}))
+	require.NoError(t, u.e.limiter.refreshWriterLimits(u.ctx))
+	writers, err = u.e.ReadWriters()
+	require.NoError(t, err)
+	require.Len(t, writers, 2)
+	require.Equal(t, "writer2", writers[1].Name)
+	require.NotNil(t, writers[1].CreatedAt)
+	require.True(t, writers[1].CreatedAt.After(registered), "created at %v should be after %v", writers[1].CreatedAt, registered)
+	require.Equal(t, limits.RateLimit{Amount: 10, Period: time.Minute}, writers[1].RateLimit)
+	require.Positive(t, writers[1].CookieLength)
+
+	u.e.SourceIP = "10.0.0.1"
+	before := time.Now().Add(-time.Second)
+	_, err = u.e.Mutate("writer1", "", "family1", []byte{3}, nil, []ExecutiveMutationRequest{
+		{TableName: "table10", Values: map[string]interface{}{"field1": 2, "field2": "bar", "field3": 1.5}},
+		{TableName: "table10", Values: map[string]interface{}{"field1": 3, "field2": "baz", "field3": 2.5}},
+	})
+	require.NoError(t, err)
+
+	writers, err = u.e.ReadWriters()
+	require.NoError(t, err)
+	require.Len(t, writers, 2)
+	require.Equal(t, "10.0.0.1", writers[0].LastSourceIP)
+	require.EqualValues(t, 2, writers[0].MutationCount)
+	require.NotNil(t, writers[0].LastMutationAt)
+	require.True(t, writers[0].LastMutationAt.After(before), "last mutation %v should be after %v", writers[0].LastMutationAt, before)
+}
+
+func testDBExecutiveFamilyTables(t *testing.T, dbType string) {
+	u := newDbExecTestUtil(t, dbType)
+	defer u.Close()
+
+	err := u.e.CreateTable("family1", "table2",
+		[]string{"field1"}, []schema.FieldType{schema.FTInteger}, []string{"field1"})
+	require.NoError(t, err)
+
+	tables, err := u.e.FamilyTables("family1")
+	require.NoError(t, err)
+	require.EqualValues(t, []string{"binary_table1", "table1", "table10", "table100", "table11", "table2"}, tables)
+
+	tables, err = u.e.FamilyTables("family9")
+	require.NoError(t, err)
+	require.Empty(t, tables)
+}
+
+func testDBExecutiveTableTemplates(t *testing.T, dbType string) {
+	u := newDbExecTestUtil(t, dbType)
+	defer u.Close()
+
+	template := schema.TableTemplate{
+		Family:    "family1",
+		Name:      "tenant-scoped",
+		KeyFields: [][]string{{"tenant_id", "string"}},
+		Fields:    [][]string{{"updated_at", "integer"}},
+	}
+	err := u.e.SaveTableTemplate(schema.TableTemplate{Family: "family9", Name: "tenant-scoped"})
+	require.IsType(t, &errs.NotFoundError{}, errors.Cause(err))
+	require.NoError(t, u.e.SaveTableTemplate(template))
+	// saving again replaces the template
+	require.NoError(t, u.e.SaveTableTemplate(template))
+
+	templates, err := u.e.ReadTableTemplates("family1")
+	require.NoError(t, err)
+	require.EqualValues(t, []schema.TableTemplate{template}, templates)
+
+	err = u.e.CreateTables([]schema.Table{{
+		Family:    "family1",
+		Name:      "table2",
+		Fields:    [][]string{{"id", "string"}, {"value", "text"}},
+		KeyFields: []string{"id"},
+		Template:  "tenant-scoped",
+	}})
+	require.NoError(t, err)
+	tbl, err := u.e.TableSchema("family1", "table2")
+	require.NoError(t, err)
+	require.EqualValues(t, [][]string{
+		{"tenant_id", "string"},
+		{"id", "string"},
+		{"value", "text"},
+		{"updated_at", "integer"},
+	}, tbl.Fields)
+	require.EqualValues(t, []string{"tenant_id", "id"}, tbl.KeyFields)
+
+	err = u.e.CreateTables([]schema.Table{{
+		Family:    "family1",
+		Name:      "table3",
+		Fields:    [][]string{{"id", "string"}},
+		KeyFields: []string{"id"},
+		Template:  "no-such-template",
+	}})
+	require.IsType(t, &errs.NotFoundError{}, errors.Cause(err))
+
+	require.NoError(t, u.e.DeleteTableTemplate("family1", "tenant-scoped"))
+	err = u.e.DeleteTableTemplate("family1", "tenant-scoped")
+	require.IsType(t, &errs.NotFoundError{}, errors.Cause(err))
+	templates, err = u.e.ReadTableTemplates("family1")
+	require.NoError(t, err)
+	require.Empty(t, templates)
+}
+
+func testDBExecutiveFieldOptions(t *testing.T, dbType string) {
+	u := newDbExecTestUtil(t, dbType)
+	defer u.Close()
+
+	err := u.e.CreateTables([]schema.Table{{
+		Family:    "family1",
+		Name:      "defaults",
+		Fields:    [][]string{{"id", "string"}, {"status", "string"}, {"count", "integer"}, {"note", "text"}},
+		KeyFields: []string{"id"},
+		FieldOptions: map[string]schema.FieldOptions{
+			"status": {Default: "none", NotNull: true},
+			"count":  {Default: float64(7)},
+		},
+	}})
+	require.NoError(t, err)
+
+	tableSchema, err := u.e.TableSchema("family1", "defaults")
+	require.NoError(t, err)
+	require.EqualValues(t, map[string]schema.FieldOptions{
+		"status": {Default: "none", NotNull: true},
+		"count":  {Default: int64(7)},
+	}, tableSchema.FieldOptions)
+
+	// fields with defaults can be left out, others can't
+	_, err = u.e.Mutate("writer1", "", "family1", []byte{2}, nil, []ExecutiveMutationRequest{{
+		TableName: "defaults",
+		Values:    map[string]interface{}{"id": "a"},
+	}})
+	require.EqualError(t, err, "Missing field note")
+	_, err = u.e.Mutate("writer1", "", "family1", []byte{2}, nil, []ExecutiveMutationRequest{{
+		TableName: "defaults",
+		Values:    map[string]interface{}{"id": "a", "note": nil},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`REPLACE INTO family1___defaults ("id","note") VALUES('a',NULL)`,
+	}, queryDMLTable(t, u.db, 1))
+
+	var status string
+	var count int64
+	err = u.db.QueryRow("SELECT status, count FROM family1___defaults WHERE id='a'").Scan(&status, &count)
+	require.NoError(t, err)
+	require.Equal(t, "none", status)
+	require.EqualValues(t, 7, count)
+
+	// new NOT NULL fields need a default for the existing rows
+	err = u.e.AddFields("family1", "defaults", []string{"flag"}, []schema.FieldType{schema.FTInteger},
+		map[string]schema.FieldOptions{"flag": {NotNull: true}})
+	require.IsType(t, &errs.BadRequestError{}, err)
+	err = u.e.AddFields("family1", "defaults", []string{"flag"}, []schema.FieldType{schema.FTInteger},
+		map[string]schema.FieldOptions{"flag": {Default: float64(1), NotNull: true}})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`ALTER TABLE family1___defaults ADD COLUMN "flag" INTEGER NOT NULL DEFAULT 1`,
+	}, queryDMLTable(t, u.db, 1))
+	var flag int64
+	err = u.db.QueryRow("SELECT flag FROM family1___defaults WHERE id='a'").Scan(&flag)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, flag)
+
+	// text fields can't have defaults
+	err = u.e.AlterField("family1", "defaults", "status", "", schema.FTText)
+	require.IsType(t, &errs.BadRequestError{}, err)
+}
+
+func testDBExecutiveTimestampBooleanFields(t *testing.T, dbType string) {
+	u := newDbExecTestUtil(t, dbType)
+	defer u.Close()
+
+	err := u.e.CreateTables([]schema.Table{{
+		Family:    "family1",
+		Name:      "events",
+		Fields:    [][]string{{"id", "string"}, {"at", "timestamp"}, {"enabled", "boolean"}},
+		KeyFields: []string{"id"},
+	}})
+	require.NoError(t, err)
+
+	tableSchema, err := u.e.TableSchema("family1", "events")
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"id", "string"}, {"at", "timestamp"}, {"enabled", "boolean"}}, tableSchema.Fields)
+
+	_, err = u.e.Mutate("writer1", "", "family1", []byte{2}, nil, []ExecutiveMutationRequest{{
+		TableName: "events",
+		Values:    map[string]interface{}{"id": "a", "at": "2020-01-02T03:04:05.5-01:00", "enabled": true},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`REPLACE INTO family1___events ("id","at","enabled") VALUES('a','2020-01-02 04:04:05.5',1)`,
+	}, queryDMLTable(t, u.db, 1))
+
+	var at time.Time
+	var enabled bool
+	row := u.db.QueryRow("SELECT at, enabled FROM family1___events WHERE id='a'")
+	if dbType == "mysql" {
+		// the test connection doesn't parse times
+		var atStr string
+		require.NoError(t, row.Scan(&atStr, &enabled))
+		at, err = time.Parse(schema.TimestampLayout, atStr)
+		require.NoError(t, err)
+	} else {
+		require.NoError(t, row.Scan(&at, &enabled))
+	}
+	require.True(t, time.Date(2020, 1, 2, 4, 4, 5, 500000000, time.UTC).Equal(at), at)
+	require.True(t, enabled)
+
+	for _, values := range []map[string]interface{}{
+		{"id": "b", "at": "yesterday", "enabled": false},
+		{"id": "b", "at": nil, "enabled": "yes"},
+	} {
+		_, err = u.e.Mutate("writer1", "", "family1", []byte{3}, nil, []ExecutiveMutationRequest{{
+			TableName: "events",
+			Values:    values,
+		}})
+		require.IsType(t, &errs.BadRequestError{}, errors.Cause(err))
+	}
+}
+
+func testDBExecutiveJSONFields(t *testing.T, dbType string) {
+	u := newDbExecTestUtil(t, dbType)
+	defer u.Close()
+
+	err := u.e.CreateTables([]schema.Table{{
+		Family:    "family1",
+		Name:      "docs",
+		Fields:    [][]string{{"id", "string"}, {"doc", "json"}},
+		KeyFields: []string{"id"},
+	}})
+	require.NoError(t, err)
+
+	tableSchema, err := u.e.TableSchema("family1", "docs")
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"id", "string"}, {"doc", "json"}}, tableSchema.Fields)
+
+	_, err = u.e.Mutate("writer1", "", "family1", []byte{2}, nil, []ExecutiveMutationRequest{
+		{TableName: "docs", Values: map[string]interface{}{"id": "a", "doc": `{"a":[1,"b"]}`}},
+		{TableName: "docs", Values: map[string]interface{}{"id": "b", "doc": map[string]interface{}{"c": true}}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"--- COMMIT",
+		`REPLACE INTO family1___docs ("id","doc") VALUES('b','{"c":true}')`,
+		`REPLACE INTO family1___docs ("id","doc") VALUES('a','{"a":[1,"b"]}')`,
+	}, queryDMLTable(t, u.db, 3))
+
+	var doc string
+	err = u.db.QueryRow("SELECT doc FROM family1___docs WHERE id='b'").Scan(&doc)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"c":true}`, doc)
+
+	_, err = u.e.Mutate("writer1", "", "family1", []byte{3}, nil, []ExecutiveMutationRequest{{
+		TableName: "docs",
+		Values:    map[string]interface{}{"id": "c", "doc": `{"a":`},
+	}})
+	require.IsType(t, &errs.BadRequestError{}, errors.Cause(err))
+}
+
 func testDBExecutiveAddFields(t *testing.T, dbType string) {
 	u := newDbExecTestUtil(t, dbType)
 	defer u.Close()
@@ -523,6 +912,7 @@ func testDBExecutiveAddFields(t *testing.T, dbType string) {
 			"table2",
 			[]string{"field7", "field8", "field9", "field10", "field11", "field12"},
 			[]schema.FieldType{schema.FTString, schema.FTInteger, schema.FTByteString, schema.FTDecimal, schema.FTText, schema.FTBinary},
+			nil,
 		)
 	}
 
@@ -579,6 +969,7 @@ func testDBExecutiveAddFields(t *testing.T, dbType string) {
 		"table2",
 		[]string{"field7", "field8", "field9", "field10", "field11", "field12"},
 		[]schema.FieldType{schema.FTString, schema.FTInteger, schema.FTByteString, schema.FTDecimal, schema.FTText, schema.FTBinary},
+		nil,
 	)
 	if err == nil || !strings.Contains(err.Error(), "Column already exists") {
 		t.Fatalf("Unexpected error calling UpdateTable: %+v", err)
@@ -1027,8 +1418,9 @@ func testDBExecutiveWriterRates(t *testing.T, dbType string) {
 	require.EqualValues(t, testDefaultWriterLimit, wrLimits.Global)
 	require.EqualValues(t, []limits.WriterRateLimit{writerLimit2}, wrLimits.Writers)
 
-	// update the second writer limit to a different value
+	// update the second writer limit to a different value, with a burst
 	writerLimit2.RateLimit.Amount = 300
+	writerLimit2.RateLimit.Burst = 1000
 	require.NoError(t, u.e.UpdateWriterRateLimit(writerLimit2))
 
 	// verify that the value was updated
@@ -1036,6 +1428,107 @@ func testDBExecutiveWriterRates(t *testing.T, dbType string) {
 	require.NoError(t, err)
 	require.EqualValues(t, testDefaultWriterLimit, wrLimits.Global)
 	require.EqualValues(t, []limits.WriterRateLimit{writerLimit2}, wrLimits.Writers)
+
+	// bursts can't be negative
+	writerLimit2.RateLimit.Burst = -1
+	require.EqualError(t, u.e.UpdateWriterRateLimit(writerLimit2), "invalid burst: -1")
+}
+
+func testDBExecutiveWriterGroups(t *testing.T, dbType string) {
+	u := newDbExecTestUtil(t, dbType)
+	defer u.Close()
+	ctx := context.Background()
+	u.e.SourceIP = "10.0.0.1"
+
+	groups, err := u.e.ReadWriterGroups()
+	require.NoError(t, err)
+	require.Empty(t, groups)
+
+	err = u.e.AddWriterGroupMember("group1", "writer1", "")
+	require.IsType(t, &errs.NotFoundError{}, errors.Cause(err))
+
+	require.NoError(t, u.e.UpdateWriterGroup("group1", limits.RateLimit{Amount: 3, Period: time.Minute}))
+	require.NoError(t, u.e.UpdateWriterGroup("group2", limits.RateLimit{Amount: 2, Period: time.Second, Burst: 50}))
+	err = u.e.UpdateWriterGroup("group3", limits.RateLimit{Amount: 2, Period: time.Second, Burst: -1})
+	require.IsType(t, &errs.BadRequestError{}, errors.Cause(err))
+	require.NoError(t, u.e.AddWriterGroupMember("group1", "writer1", ""))
+
+	// writers must exist unless they're given a secret to be registered with
+	err = u.e.AddWriterGroupMember("group1", "writer2", "")
+	require.IsType(t, &errs.NotFoundError{}, errors.Cause(err))
+	require.NoError(t, u.e.AddWriterGroupMember("group1", "writer2", "writer2-secret"))
+	require.NoError(t, u.e.AddWriterGroupMember("group1", "writer2", "writer2-secret"))
+	err = u.e.AddWriterGroupMember("group1", "writer2", "another-secret")
+	require.IsType(t, &errs.ConflictError{}, errors.Cause(err))
+
+	groups, err = u.e.ReadWriterGroups()
+	require.NoError(t, err)
+	require.Equal(t, []limits.WriterGroup{
+		{Name: "group1", RateLimit: limits.RateLimit{Amount: 3, Period: time.Minute}, Members: []string{"writer1", "writer2"}},
+		{Name: "group2", RateLimit: limits.RateLimit{Amount: 120, Period: time.Minute, Burst: 50}, Members: []string{}},
+	}, groups)
+
+	// the members of the group share its limit
+	require.NoError(t, u.e.limiter.refreshWriterLimits(ctx))
+	mutate := func(writer, secret string, field1 int) error {
+		_, err := u.e.Mutate(writer, secret, "family1", []byte{byte(field1)}, nil, []ExecutiveMutationRequest{
+			{TableName: "table10", Values: map[string]interface{}{"field1": field1, "field2": "bar", "field3": 1.5}},
+		})
+		return err
+	}
+	require.NoError(t, mutate("writer1", "", 1))
+	require.NoError(t, mutate("writer2", "writer2-secret", 2))
+	require.NoError(t, mutate("writer1", "", 3))
+	err = mutate("writer2", "writer2-secret", 4)
+	require.IsType(t, &errs.RateLimitExceededErr{}, errors.Cause(err))
+
+	// moving a writer to another group gives it that group's limit
+	require.NoError(t, u.e.AddWriterGroupMember("group2", "writer2", ""))
+	require.NoError(t, u.e.limiter.refreshWriterLimits(ctx))
+	require.NoError(t, mutate("writer2", "writer2-secret", 5))
+	err = mutate("writer1", "", 6)
+	require.IsType(t, &errs.RateLimitExceededErr{}, errors.Cause(err))
+
+	// and once the group is gone, its members get their own limits back
+	err = u.e.RemoveWriterGroupMember("group1", "writer2")
+	require.IsType(t, &errs.NotFoundError{}, errors.Cause(err))
+	require.NoError(t, u.e.DeleteWriterGroup("group1"))
+	err = u.e.DeleteWriterGroup("group1")
+	require.IsType(t, &errs.NotFoundError{}, errors.Cause(err))
+	require.NoError(t, u.e.limiter.refreshWriterLimits(ctx))
+	require.NoError(t, mutate("writer1", "", 7))
+
+	require.NoError(t, u.e.RemoveWriterGroupMember("group2", "writer2"))
+	groups, err = u.e.ReadWriterGroups()
+	require.NoError(t, err)
+	require.Equal(t, []limits.WriterGroup{
+		{Name: "group2", RateLimit: limits.RateLimit{Amount: 120, Period: time.Minute, Burst: 50}, Members: []string{}},
+	}, groups)
+
+	// every change to the groups' members was audited
+	audit := func(groupName string) [][]string {
+		events, err := u.e.ReadWriterGroupAudit(groupName)
+		require.NoError(t, err)
+		res := [][]string{}
+		for _, event := range events {
+			require.Equal(t, groupName, event.Group)
+			require.Equal(t, "10.0.0.1", event.SourceIP)
+			require.False(t, event.At.IsZero())
+			res = append(res, []string{event.Action, event.Writer})
+		}
+		return res
+	}
+	require.Equal(t, [][]string{
+		{WriterGroupMemberAdded, "writer1"},
+		{WriterGroupMemberAdded, "writer2"},
+		{WriterGroupMemberRemoved, "writer2"},
+		{WriterGroupMemberRemoved, "writer1"},
+	}, audit("group1"))
+	require.Equal(t, [][]string{
+		{WriterGroupMemberAdded, "writer2"},
+		{WriterGroupMemberRemoved, "writer2"},
+	}, audit("group2"))
+	require.Equal(t, [][]string{}, audit("group3"))
 }
 
 func testDBExecutiveFetchFamilyByName(t *testing.T, dbType string) {
@@ -1158,6 +1651,372 @@ func testFetchMetaTableByName(t *testing.T, dbType string) {
 	}
 }
 
+func testDBExecutiveMutateFamilies(t *testing.T, dbType string) {
+	u := newDbExecTestUtil(t, dbType)
+	defer u.Close()
+
+	require.NoError(t, u.e.CreateFamily("family2"))
+	require.NoError(t, u.e.CreateTable("family2", "table1",
+		[]string{"key", "val"},
+		[]schema.FieldType{schema.FTString, schema.FTInteger},
+		[]string{"key"}))
+
+	_, err := u.e.MutateFamilies("writer1", "", []byte{2}, nil, []ExecutiveMutationRequest{
+		{
+			FamilyName: "family1",
+			TableName:  "table10",
+			Values:     map[string]interface{}{"field1": 2, "field2": "bar", "field3": 2.3},
+		},
+		{
+			FamilyName: "family2",
+			TableName:  "table1",
+			Values:     map[string]interface{}{"key": "a", "val": 1},
+		},
+		{
+			FamilyName: "family1",
+			TableName:  "table10",
+			Delete:     true,
+			Values:     map[string]interface{}{"field1": 1},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		schema.DMLTxEndKey,
+		`DELETE FROM family1___table10 WHERE "field1" = 1`,
+		`REPLACE INTO family2___table1 ("key","val") VALUES('a',1)`,
+		`REPLACE INTO family1___table10 ("field1","field2","field3") VALUES(2,'bar',2.3)`,
+		schema.DMLTxBeginKey,
+	}, queryDMLTable(t, u.db, 5))
+
+	cookie, err := u.e.GetWriterCookie("writer1", "")
+	require.NoError(t, err)
+	require.Equal(t, []byte{2}, cookie)
+
+	t.Run("missing table rolls back", func(t *testing.T) {
+		_, err := u.e.MutateFamilies("writer1", "", []byte{3}, nil, []ExecutiveMutationRequest{
+			{
+				FamilyName: "family1",
+				TableName:  "table10",
+				Values:     map[string]interface{}{"field1": 3, "field2": "baz", "field3": 1.0},
+			},
+			{
+				FamilyName: "family2",
+				TableName:  "table10",
+				Values:     map[string]interface{}{"field1": 3, "field2": "baz", "field3": 1.0},
+			},
+		})
+		require.EqualError(t, err, "Table not found: table10")
+
+		cookie, err := u.e.GetWriterCookie("writer1", "")
+		require.NoError(t, err)
+		require.Equal(t, []byte{2}, cookie)
+	})
+
+	t.Run("family required", func(t *testing.T) {
+		_, err := u.e.MutateFamilies("writer1", "", []byte{3}, nil, []ExecutiveMutationRequest{{
+			TableName: "table10",
+			Values:    map[string]interface{}{"field1": 3, "field2": "baz", "field3": 1.0},
+		}})
+		require.Error(t, err)
+	})
+}
+
+func testDBExecutiveConditionalMutate(t *testing.T, dbType string) {
+	u := newDbExecTestUtil(t, dbType)
+	defer u.Close()
+
+	res, err := u.e.Mutate("writer1", "", "family1", []byte{2}, nil, []ExecutiveMutationRequest{
+		{
+			// matches the existing row
+			TableName: "table10",
+			Values:    map[string]interface{}{"field1": 1, "field2": "bar", "field3": 1.2},
+			IfValues:  map[string]interface{}{"field2": "foo"},
+		},
+		{
+			// doesn't match, since the row was just updated
+			TableName: "table10",
+			Values:    map[string]interface{}{"field1": 1, "field2": "baz", "field3": 1.2},
+			IfValues:  map[string]interface{}{"field2": "foo"},
+		},
+		{
+			// the row doesn't exist
+			TableName: "table10",
+			Values:    map[string]interface{}{"field1": 2, "field2": "new", "field3": 0.5},
+			IfValues:  map[string]interface{}{"field2": "foo"},
+		},
+		{
+			TableName: "table10",
+			Delete:    true,
+			Values:    map[string]interface{}{"field1": 2},
+			IfValues:  map[string]interface{}{"field2": "old"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 3}, res.Skipped)
+	require.Equal(t, []string{
+		schema.DMLTxEndKey,
+		`REPLACE INTO family1___table10 ("field1","field2","field3") VALUES(2,'new',0.5)`,
+		`REPLACE INTO family1___table10 ("field1","field2","field3") VALUES(1,'bar',1.2)`,
+		schema.DMLTxBeginKey,
+	}, queryDMLTable(t, u.db, 4))
+	require.Equal(t, 2, res.Applied)
+	require.Equal(t, 2*len(`REPLACE INTO family1___table10 ("field1","field2","field3") VALUES(1,'bar',1.2)`), res.DMLBytes)
+	var maxSeq int64
+	require.NoError(t, u.db.QueryRow("SELECT MAX(seq) FROM "+dmlLedgerTableName).Scan(&maxSeq))
+	require.Equal(t, maxSeq, res.LedgerSeq)
+	// skipped mutations count towards the rate limit
+	require.NotNil(t, res.RateLimit)
+	require.Equal(t, testDefaultWriterLimit.Amount, res.RateLimit.Limit)
+	require.Equal(t, testDefaultWriterLimit.Amount-4, res.RateLimit.Remaining)
+	require.True(t, res.RateLimit.ResetAt.After(time.Now()))
+
+	row, err := u.e.ReadRow("family1", "table10", map[string]interface{}{"field1": 1})
+	require.NoError(t, err)
+	require.EqualValues(t, "bar", row["field2"])
+
+	t.Run("null values", func(t *testing.T) {
+		res, err := u.e.Mutate("writer1", "", "family1", []byte{3}, nil, []ExecutiveMutationRequest{{
+			TableName: "table10",
+			Delete:    true,
+			Values:    map[string]interface{}{"field1": 2},
+			IfValues:  map[string]interface{}{"field2": nil},
+		}})
+		require.NoError(t, err)
+		require.Equal(t, []int{0}, res.Skipped)
+		require.Zero(t, res.Applied)
+		require.Zero(t, res.DMLBytes)
+		require.Zero(t, res.LedgerSeq)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		_, err := u.e.Mutate("writer1", "", "family1", []byte{4}, nil, []ExecutiveMutationRequest{{
+			TableName: "table10",
+			Values:    map[string]interface{}{"field1": 1, "field2": "qux", "field3": 1.2},
+			IfValues:  map[string]interface{}{"field9": "bar"},
+		}})
+		require.Error(t, err)
+		require.IsType(t, &errs.BadRequestError{}, errors.Cause(err))
+
+		cookie, err := u.e.GetWriterCookie("writer1", "")
+		require.NoError(t, err)
+		require.Equal(t, []byte{3}, cookie)
+	})
+
+	t.Run("binary values", func(t *testing.T) {
+		// binary values are base64 encoded, as they are in upserts
+		res, err := u.e.Mutate("writer1", "", "family1", []byte{5}, nil, []ExecutiveMutationRequest{
+			{
+				TableName: "binary_table1",
+				Values:    map[string]interface{}{"field1": 1, "field2": "AQI="},
+			},
+			{
+				TableName: "binary_table1",
+				Values:    map[string]interface{}{"field1": 1, "field2": "AwQ="},
+				IfValues:  map[string]interface{}{"field2": "AQI="},
+			},
+			{
+				TableName: "binary_table1",
+				Values:    map[string]interface{}{"field1": 1, "field2": "BQY="},
+				IfValues:  map[string]interface{}{"field2": "AQI="},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []int{2}, res.Skipped)
+		require.Equal(t, 2, res.Applied)
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		// of the writes racing to replace the same value, only one applies
+		const writers = 8
+		var wg sync.WaitGroup
+		results := make([]MutationResult, writers)
+		mutateErrs := make([]error, writers)
+		for i := 0; i < writers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i], mutateErrs[i] = u.e.Mutate("writer1", "", "family1", []byte{6}, nil, []ExecutiveMutationRequest{{
+					TableName: "table10",
+					Values:    map[string]interface{}{"field1": 1, "field2": fmt.Sprintf("racer%d", i), "field3": 1.2},
+					IfValues:  map[string]interface{}{"field2": "bar"},
+				}})
+			}(i)
+		}
+		wg.Wait()
+
+		winner := -1
+		for i := 0; i < writers; i++ {
+			require.NoError(t, mutateErrs[i])
+			if results[i].Applied == 1 {
+				require.Equal(t, -1, winner, "more than one write applied")
+				winner = i
+			} else {
+				require.Equal(t, []int{0}, results[i].Skipped)
+			}
+		}
+		require.NotEqual(t, -1, winner, "no write applied")
+		row, err := u.e.ReadRow("family1", "table10", map[string]interface{}{"field1": 1})
+		require.NoError(t, err)
+		require.EqualValues(t, fmt.Sprintf("racer%d", winner), row["field2"])
+	})
+}
+
+func testDBExecutiveApplySchema(t *testing.T, dbType string) {
+	u := newDbExecTestUtil(t, dbType)
+	defer u.Close()
+
+	tables := []schema.Table{
+		{
+			Family:    "family1",
+			Name:      "table10",
+			Fields:    [][]string{{"field1", "integer"}, {"field2", "string"}, {"field4", "integer"}},
+			KeyFields: []string{"field1"},
+		},
+		{
+			Family:    "family3",
+			Name:      "table1",
+			Fields:    [][]string{{"key", "string"}},
+			KeyFields: []string{"key"},
+		},
+	}
+	wantChanges := []SchemaChange{
+		{Type: SchemaChangeAddField, Family: "family1", Table: "table10", Field: "field4", FieldType: "integer"},
+		{Type: SchemaChangeCreateFamily, Family: "family3"},
+		{Type: SchemaChangeCreateTable, Family: "family3", Table: "table1"},
+	}
+
+	plan, err := u.e.ApplySchema(tables, true)
+	require.NoError(t, err)
+	require.Equal(t, SchemaPlan{Changes: wantChanges}, plan)
+	_, err = u.e.TableSchema("family3", "table1")
+	require.Error(t, err)
+
+	plan, err = u.e.ApplySchema(tables, false)
+	require.NoError(t, err)
+	require.Equal(t, SchemaPlan{Changes: wantChanges, Applied: true}, plan)
+
+	tbl, err := u.e.TableSchema("family1", "table10")
+	require.NoError(t, err)
+	require.Contains(t, tbl.Fields, []string{"field4", "integer"})
+	tbl, err = u.e.TableSchema("family3", "table1")
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"key", "string"}}, tbl.Fields)
+
+	// applying again changes nothing
+	plan, err = u.e.ApplySchema(tables, false)
+	require.NoError(t, err)
+	require.Equal(t, SchemaPlan{Changes: []SchemaChange{}, Applied: true}, plan)
+
+	t.Run("conflicts", func(t *testing.T) {
+		_, err := u.e.ApplySchema([]schema.Table{
+			{
+				Family:    "family1",
+				Name:      "table10",
+				Fields:    [][]string{{"field1", "integer"}, {"field2", "integer"}, {"field5", "string"}},
+				KeyFields: []string{"field2"},
+			},
+		}, false)
+		require.IsType(t, &errs.ConflictError{}, err)
+		require.Contains(t, err.Error(), "family1___table10 has key fields [field1], not [field2]")
+		require.Contains(t, err.Error(), "family1___table10 field field2 is of type string, not integer")
+
+		tbl, err := u.e.TableSchema("family1", "table10")
+		require.NoError(t, err)
+		require.NotContains(t, tbl.Fields, []string{"field5", "string"})
+	})
+}
+
+func testDBExecutiveParameterizedDML(t *testing.T, dbType string) {
+	u := newDbExecTestUtil(t, dbType)
+	defer u.Close()
+	u.e.ParameterizedDML = true
+
+	_, err := u.e.Mutate("writer1", "", "family1", []byte{2}, nil, []ExecutiveMutationRequest{{
+		TableName: "table10",
+		Values:    map[string]interface{}{"field1": 2, "field2": "it's", "field3": 2.5},
+	}})
+	require.NoError(t, err)
+
+	lastDML := func() schema.ParameterizedDML {
+		entries, err := u.e.ReadLedger(LedgerQuery{Limit: 1000})
+		require.NoError(t, err)
+		require.NotEmpty(t, entries)
+		last := entries[len(entries)-1]
+		require.Equal(t, schema.DMLVersionParameterized, last.Version)
+		dml, err := schema.DMLStatement{Statement: last.Statement, Version: last.Version}.DML()
+		require.NoError(t, err)
+		return dml
+	}
+	require.Equal(t, schema.ParameterizedDML{
+		SQL:  `REPLACE INTO family1___table10 ("field1","field2","field3") VALUES(?,?,?)`,
+		Args: []interface{}{int64(2), "it's", 2.5},
+	}, lastDML())
+
+	var field2 string
+	err = u.db.QueryRow("SELECT field2 FROM family1___table10 WHERE field1 = 2").Scan(&field2)
+	require.NoError(t, err)
+	require.Equal(t, "it's", field2)
+
+	_, err = u.e.Mutate("writer1", "", "family1", []byte{3}, nil, []ExecutiveMutationRequest{{
+		TableName: "table10",
+		Delete:    true,
+		Values:    map[string]interface{}{"field1": 2},
+	}})
+	require.NoError(t, err)
+
+	require.Equal(t, schema.ParameterizedDML{
+		SQL:  `DELETE FROM family1___table10 WHERE "field1" = ?`,
+		Args: []interface{}{int64(2)},
+	}, lastDML())
+
+	// DDL is still SQL
+	err = u.e.CreateTable("family1", "table_versions", []string{"field1"}, []schema.FieldType{schema.FTInteger}, []string{"field1"})
+	require.NoError(t, err)
+	var version int
+	err = u.db.QueryRow("SELECT version FROM ctlstore_dml_ledger ORDER BY seq DESC LIMIT 1").Scan(&version)
+	require.NoError(t, err)
+	require.Equal(t, schema.DMLVersionSQL, version)
+}
+
+func testDBExecutiveRecordTraceIDs(t *testing.T, dbType string) {
+	u := newDbExecTestUtil(t, dbType)
+	defer u.Close()
+	u.e.RecordTraceIDs = true
+
+	remote, ok := tracing.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.True(t, ok)
+	u.e.Ctx = tracing.ContextWithRemote(u.ctx, remote)
+
+	_, err := u.e.Mutate("writer1", "", "family1", []byte{2}, nil, []ExecutiveMutationRequest{{
+		TableName: "table10",
+		Values:    map[string]interface{}{"field1": 2, "field2": "x", "field3": 2.5},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, tracing.ContextWithRemote(u.ctx, remote), u.e.Ctx, "the span should have ended")
+
+	entries, err := u.e.ReadLedger(LedgerQuery{Limit: 1000})
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+	last := entries[len(entries)-1]
+	require.Contains(t, last.Statement, "family1___table10")
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", last.TraceID)
+
+	// without a caller's trace, the mutation starts its own
+	u.e.Ctx = u.ctx
+	_, err = u.e.Mutate("writer1", "", "family1", []byte{3}, nil, []ExecutiveMutationRequest{{
+		TableName: "table10",
+		Delete:    true,
+		Values:    map[string]interface{}{"field1": 2},
+	}})
+	require.NoError(t, err)
+	var traceID sql.NullString
+	err = u.db.QueryRow("SELECT trace_id FROM ctlstore_dml_ledger ORDER BY seq DESC LIMIT 1").Scan(&traceID)
+	require.NoError(t, err)
+	require.True(t, traceID.Valid)
+	require.Len(t, traceID.String, 32)
+	require.NotEqual(t, last.TraceID, traceID.String)
+}
+
 func testDBExecutiveMutate(t *testing.T, dbType string) {
 	suite := []struct {
 		desc        string
@@ -1487,7 +2346,7 @@ func testDBExecutiveMutate(t *testing.T, dbType string) {
 				cookie = []byte{2}
 			}
 
-			err := u.e.Mutate(writerName, "", "family1", cookie, testCase.checkCookie, testCase.reqs)
+			_, err := u.e.Mutate(writerName, "", "family1", cookie, testCase.checkCookie, testCase.reqs)
 
 			if err != nil {
 				if testCase.expectErr != nil {
@@ -1676,6 +2535,225 @@ func testDBExecutiveSetWriterCookie(t *testing.T, dbType string) {
 	}
 }
 
+func testDBExecutiveCookieCAS(t *testing.T, dbType string) {
+	u := newDbExecTestUtil(t, dbType)
+	defer u.Close()
+
+	require.NoError(t, u.e.SetWriterCookie("writer1", "", []byte("shard-1")))
+
+	res, err := u.e.CompareAndSwapWriterCookie("writer1", "", []byte("shard-1"), []byte("shard-2"))
+	require.NoError(t, err)
+	require.Equal(t, CookieSwap{Swapped: true, Cookie: []byte("shard-2")}, res)
+
+	// a writer which lost the race learns who won
+	res, err = u.e.CompareAndSwapWriterCookie("writer1", "", []byte("shard-1"), []byte("shard-3"))
+	require.NoError(t, err)
+	require.Equal(t, CookieSwap{Cookie: []byte("shard-2")}, res)
+	cookie, err := u.e.GetWriterCookie("writer1", "")
+	require.NoError(t, err)
+	require.Equal(t, []byte("shard-2"), cookie)
+
+	// swapping to the same cookie still succeeds
+	res, err = u.e.CompareAndSwapWriterCookie("writer1", "", []byte("shard-2"), []byte("shard-2"))
+	require.NoError(t, err)
+	require.True(t, res.Swapped)
+
+	// no expected cookie only matches an empty one
+	res, err = u.e.CompareAndSwapWriterCookie("writer1", "", nil, []byte("shard-4"))
+	require.NoError(t, err)
+	require.False(t, res.Swapped)
+
+	_, err = u.e.CompareAndSwapWriterCookie("writer1", "wrong", []byte("shard-2"), []byte("shard-3"))
+	require.IsType(t, &errs.NotFoundError{}, errors.Cause(err))
+	_, err = u.e.CompareAndSwapWriterCookie("writer1", "", []byte("shard-2"), make([]byte, limits.LimitWriterCookieSize+1))
+	require.IsType(t, &errs.BadRequestError{}, errors.Cause(err))
+}
+
+func testDBExecutiveReadRows(t *testing.T, dbType string) {
+	u := newDbExecTestUtil(t, dbType)
+	defer u.Close()
+
+	for i := 2; i <= 5; i++ {
+		_, err := u.db.Exec("INSERT INTO family1___table10 VALUES(?, 'bar', 0)", i)
+		require.NoError(t, err)
+	}
+	readKeys := func(query RowsQuery) []int64 {
+		var keys []int64
+		err := u.e.ReadRows("family1", "table10", query, func(row map[string]interface{}) error {
+			keys = append(keys, row["field1"].(int64))
+			return nil
+		})
+		require.NoError(t, err)
+		return keys
+	}
+
+	require.Equal(t, []int64{1, 2, 3, 4, 5}, readKeys(RowsQuery{Limit: 10}))
+	require.Equal(t, []int64{3, 4}, readKeys(RowsQuery{Limit: 2, Offset: 2}))
+	require.Equal(t, []int64{3, 4}, readKeys(RowsQuery{Limit: 2, After: []interface{}{2}}))
+	require.Empty(t, readKeys(RowsQuery{Limit: 2, After: []interface{}{5}}))
+
+	err := u.e.ReadRows("family1", "table10", RowsQuery{Limit: 1, After: []interface{}{1, 2}}, nil)
+	require.IsType(t, &errs.BadRequestError{}, errors.Cause(err))
+	err = u.e.ReadRows("family1", "missing", RowsQuery{Limit: 1}, nil)
+	require.IsType(t, &errs.NotFoundError{}, errors.Cause(err))
+}
+
+func testDBExecutiveReadLedger(t *testing.T, dbType string) {
+	u := newDbExecTestUtil(t, dbType)
+	defer u.Close()
+
+	var seqs []int64
+	for i := 0; i < 5; i++ {
+		res, err := u.db.Exec("INSERT INTO ctlstore_dml_ledger (statement) VALUES(?)", fmt.Sprintf("statement %d", i))
+		require.NoError(t, err)
+		seq, err := res.LastInsertId()
+		require.NoError(t, err)
+		seqs = append(seqs, seq)
+	}
+	readSeqs := func(query LedgerQuery) []int64 {
+		entries, err := u.e.ReadLedger(query)
+		require.NoError(t, err)
+		var out []int64
+		for _, entry := range entries {
+			require.NotNil(t, entry.Timestamp)
+			out = append(out, entry.Seq)
+		}
+		return out
+	}
+
+	require.Equal(t, seqs, readSeqs(LedgerQuery{Limit: 10}))
+	require.Equal(t, seqs[1:3], readSeqs(LedgerQuery{FromSeq: seqs[1], ToSeq: seqs[2], Limit: 10}))
+	require.Equal(t, seqs[1:3], readSeqs(LedgerQuery{FromSeq: seqs[1], Limit: 2}))
+	require.Empty(t, readSeqs(LedgerQuery{FromSeq: seqs[4] + 1, Limit: 10}))
+
+	entries, err := u.e.ReadLedger(LedgerQuery{FromSeq: seqs[3], ToSeq: seqs[3], Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "statement 3", entries[0].Statement)
+}
+
+func testDBExecutiveReadLedgerSeq(t *testing.T, dbType string) {
+	u := newDbExecTestUtil(t, dbType)
+	defer u.Close()
+
+	readSeq := func() int64 {
+		t.Helper()
+		seq, err := u.e.ReadLedgerSeq()
+		require.NoError(t, err)
+		return seq
+	}
+	var maxSeq sql.NullInt64
+	require.NoError(t, u.db.QueryRow("SELECT MAX(seq) FROM "+dmlLedgerTableName).Scan(&maxSeq))
+	require.Equal(t, maxSeq.Int64, readSeq())
+
+	// the seq is cached, but advanced by the executive's own writes
+	u.e.ledgerSeq = newLedgerSeqCache(time.Hour)
+	require.Equal(t, maxSeq.Int64, readSeq())
+	_, err := u.db.Exec("INSERT INTO ctlstore_dml_ledger (statement) VALUES('statement')")
+	require.NoError(t, err)
+	require.Equal(t, maxSeq.Int64, readSeq())
+
+	require.NoError(t, u.e.ClearTable(schema.FamilyTable{Family: "family1", Table: "table1"}))
+	require.NoError(t, u.db.QueryRow("SELECT MAX(seq) FROM "+dmlLedgerTableName).Scan(&maxSeq))
+	require.Equal(t, maxSeq.Int64, readSeq())
+}
+
+func testDBExecutiveMaintenance(t *testing.T, dbType string) {
+	u := newDbExecTestUtil(t, dbType)
+	defer u.Close()
+
+	m, err := u.e.ReadMaintenance()
+	require.NoError(t, err)
+	require.Equal(t, Maintenance{}, m)
+
+	err = u.e.SetMaintenance(Maintenance{Enabled: true, Reason: "ctldb upgrade"})
+	require.NoError(t, err)
+
+	m, err = u.e.ReadMaintenance()
+	require.NoError(t, err)
+	require.True(t, m.Enabled)
+	require.Equal(t, "ctldb upgrade", m.Reason)
+	require.NotNil(t, m.UpdatedAt)
+
+	ledger := queryDMLTable(t, u.db, -1)
+	requests := []ExecutiveMutationRequest{{
+		TableName: "table10",
+		Values:    map[string]interface{}{"field1": 2, "field2": "bar", "field3": 2.3},
+	}}
+	_, err = u.e.Mutate("writer1", "", "family1", []byte{2}, nil, requests)
+	var unavailable *errs.ServiceUnavailableErr
+	require.True(t, errors.As(err, &unavailable), "%v", err)
+	require.Equal(t, "Executive is in maintenance mode: ctldb upgrade", unavailable.Error())
+	require.Equal(t, maintenanceRetryAfter, unavailable.RetryAfter)
+	require.Equal(t, ledger, queryDMLTable(t, u.db, -1))
+
+	// reads continue to work
+	row, err := u.e.ReadRow("family1", "table10", map[string]interface{}{"field1": 1})
+	require.NoError(t, err)
+	require.EqualValues(t, "foo", row["field2"])
+	_, err = u.e.TableSchema("family1", "table10")
+	require.NoError(t, err)
+
+	err = u.e.SetMaintenance(Maintenance{Enabled: false})
+	require.NoError(t, err)
+	_, err = u.e.Mutate("writer1", "", "family1", []byte{2}, nil, requests)
+	require.NoError(t, err)
+}
+
+func testDBExecutiveExport(t *testing.T, dbType string) {
+	u := newDbExecTestUtil(t, dbType)
+	defer u.Close()
+
+	// exports can't be started without an exporter
+	_, err := u.e.StartExport("family1", "table10", ExportRequest{Format: ExportFormatCSV, Destination: "s3://bucket/table10.csv"})
+	require.IsType(t, &errs.ServiceUnavailableErr{}, errors.Cause(err))
+
+	dir, err := ioutil.TempDir("", "export-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	u.e.exporter = newExporter(u.db, nil, dir)
+	defer u.e.exporter.Close()
+
+	_, err = u.db.Exec("INSERT INTO family1___table10 VALUES(2, NULL, 3.5)")
+	require.NoError(t, err)
+
+	export := func(format string) (*ExportJob, []byte) {
+		path := filepath.Join(dir, "table10."+format)
+		job, err := u.e.StartExport("family1", "table10", ExportRequest{Format: format, Destination: "file://" + path})
+		require.NoError(t, err)
+		require.Equal(t, ExportStateRunning, job.State)
+		for job.State == ExportStateRunning {
+			time.Sleep(10 * time.Millisecond)
+			job, err = u.e.ReadExportJob(job.ID)
+			require.NoError(t, err)
+		}
+		require.Equal(t, ExportStateSucceeded, job.State, job.Error)
+		b, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+		return job, b
+	}
+
+	job, b := export(ExportFormatCSV)
+	require.EqualValues(t, 2, job.Rows)
+	require.Equal(t, "field1,field2,field3\n1,foo,1.2\n2,,3.5\n", string(b))
+
+	job, b = export(ExportFormatParquet)
+	require.EqualValues(t, 2, job.Rows)
+	require.True(t, bytes.HasPrefix(b, []byte("PAR1")))
+	require.True(t, bytes.HasSuffix(b, []byte("PAR1")))
+
+	_, err = u.e.StartExport("family1", "table10", ExportRequest{Format: "xml", Destination: "file://" + dir})
+	require.IsType(t, &errs.BadRequestError{}, errors.Cause(err))
+	_, err = u.e.StartExport("family1", "table10", ExportRequest{Format: ExportFormatCSV, Destination: "http://example.com"})
+	require.IsType(t, &errs.BadRequestError{}, errors.Cause(err))
+	_, err = u.e.StartExport("family1", "table10", ExportRequest{Format: ExportFormatCSV, Destination: "file://" + dir + "/../table10.csv"})
+	require.IsType(t, &errs.BadRequestError{}, errors.Cause(err))
+	_, err = u.e.StartExport("family1", "missing", ExportRequest{Format: ExportFormatCSV, Destination: "file://" + filepath.Join(dir, "missing.csv")})
+	require.IsType(t, &errs.NotFoundError{}, errors.Cause(err))
+	_, err = u.e.ReadExportJob("missing")
+	require.IsType(t, &errs.NotFoundError{}, errors.Cause(err))
+}
+
 func testDBExecutiveReadRow(t *testing.T, dbType string) {
 	suite := []struct {
 		desc       string
@@ -1774,6 +2852,175 @@ func testDBExecutiveDropTable(t *testing.T, dbType string) {
 	require.EqualValues(t, "DROP TABLE IF EXISTS family1___delete_test", statement)
 }
 
+func testDBExecutiveCloneTable(t *testing.T, dbType string) {
+	u := newDbExecTestUtil(t, dbType)
+	defer u.Close()
+
+	require.NoError(t, u.e.CreateTable("family1", "clone_src",
+		[]string{"id", "name", "data"},
+		[]schema.FieldType{schema.FTInteger, schema.FTString, schema.FTBinary},
+		[]string{"id"},
+	))
+	for _, id := range []int{3, 1, 2} {
+		_, err := u.db.Exec("INSERT INTO family1___clone_src (id, name, data) VALUES (?, ?, ?)",
+			id, fmt.Sprintf("name%d", id), []byte{byte(id)})
+		require.NoError(t, err)
+	}
+
+	res, err := u.e.CloneTable(
+		schema.FamilyTable{Family: "family1", Table: "clone_src"},
+		schema.FamilyTable{Family: "family1", Table: "clone_dst"},
+		2,
+		"writer1",
+	)
+	require.NoError(t, err)
+	require.Equal(t, CloneResult{Family: "family1", Table: "clone_dst", RowsCopied: 2}, res)
+
+	src, err := u.e.TableSchema("family1", "clone_src")
+	require.NoError(t, err)
+	dst, err := u.e.TableSchema("family1", "clone_dst")
+	require.NoError(t, err)
+	require.Equal(t, src.Fields, dst.Fields)
+	require.Equal(t, src.KeyFields, dst.KeyFields)
+
+	// the rows with the lowest keys are copied
+	var copied []string
+	require.NoError(t, u.e.ReadRows("family1", "clone_dst", RowsQuery{Limit: 10}, func(row map[string]interface{}) error {
+		require.Equal(t, []byte{byte(row["id"].(int64))}, row["data"])
+		copied = append(copied, row["name"].(string))
+		return nil
+	}))
+	require.Equal(t, []string{"name1", "name2"}, copied)
+
+	require.Equal(t, []string{
+		schema.DMLTxEndKey,
+		`REPLACE INTO family1___clone_dst ("id","name","data") VALUES(2,'name2',x'02')`,
+		`REPLACE INTO family1___clone_dst ("id","name","data") VALUES(1,'name1',x'01')`,
+		schema.DMLTxBeginKey,
+	}, queryDMLTable(t, u.db, 4))
+
+	_, err = u.e.CloneTable(
+		schema.FamilyTable{Family: "family1", Table: "clone_src"},
+		schema.FamilyTable{Family: "family1", Table: "clone_dst"},
+		0,
+		"",
+	)
+	require.IsType(t, &errs.ConflictError{}, err)
+
+	_, err = u.e.CloneTable(
+		schema.FamilyTable{Family: "family1", Table: "missing"},
+		schema.FamilyTable{Family: "family1", Table: "clone_other"},
+		0,
+		"",
+	)
+	require.IsType(t, &errs.NotFoundError{}, err)
+
+	_, err = u.e.CloneTable(
+		schema.FamilyTable{Family: "family1", Table: "clone_src"},
+		schema.FamilyTable{Family: "family1", Table: "clone_other"},
+		limits.LimitMaxCloneRows+1,
+		"writer1",
+	)
+	require.IsType(t, &errs.BadRequestError{}, err)
+
+	t.Run("writer", func(t *testing.T) {
+		_, err := u.e.CloneTable(
+			schema.FamilyTable{Family: "family1", Table: "clone_src"},
+			schema.FamilyTable{Family: "family1", Table: "clone_other"},
+			1,
+			"",
+		)
+		require.IsType(t, &errs.BadRequestError{}, err)
+
+		_, err = u.e.CloneTable(
+			schema.FamilyTable{Family: "family1", Table: "clone_src"},
+			schema.FamilyTable{Family: "family1", Table: "clone_other"},
+			1,
+			"nope",
+		)
+		require.IsType(t, &errs.NotFoundError{}, err)
+	})
+
+	t.Run("limits", func(t *testing.T) {
+		require.NoError(t, u.e.UpdateWriterRateLimit(limits.WriterRateLimit{
+			Writer:    "writer1",
+			RateLimit: limits.RateLimit{Amount: 2, Period: time.Minute},
+		}))
+		require.NoError(t, u.e.limiter.refreshWriterLimits(u.ctx))
+		defer func() {
+			require.NoError(t, u.e.DeleteWriterRateLimit("writer1"))
+			require.NoError(t, u.e.limiter.refreshWriterLimits(u.ctx))
+		}()
+
+		// the rows copied into clone_dst used up the writer's limit
+		res, err := u.e.CloneTable(
+			schema.FamilyTable{Family: "family1", Table: "clone_src"},
+			schema.FamilyTable{Family: "family1", Table: "clone_limited"},
+			3,
+			"writer1",
+		)
+		require.IsType(t, &errs.RateLimitExceededErr{}, errors.Cause(err))
+		require.Zero(t, res.RowsCopied)
+	})
+
+	t.Run("maintenance", func(t *testing.T) {
+		require.NoError(t, u.e.SetMaintenance(Maintenance{Enabled: true}))
+		defer func() { require.NoError(t, u.e.SetMaintenance(Maintenance{Enabled: false})) }()
+
+		_, err := u.e.CloneTable(
+			schema.FamilyTable{Family: "family1", Table: "clone_src"},
+			schema.FamilyTable{Family: "family1", Table: "clone_maintenance"},
+			1,
+			"writer1",
+		)
+		require.IsType(t, &errs.ServiceUnavailableErr{}, errors.Cause(err))
+	})
+}
+
+func testDBExecutiveDeleteFamily(t *testing.T, dbType string) {
+	u := newDbExecTestUtil(t, dbType)
+	defer u.Close()
+
+	require.NoError(t, u.e.CreateFamily("family2"))
+	for _, table := range []string{"table1", "table2"} {
+		require.NoError(t, u.e.CreateTable("family2", table,
+			[]string{"key"}, []schema.FieldType{schema.FTString}, []string{"key"}))
+	}
+	_, err := u.db.Exec("INSERT INTO family2___table1 (key) VALUES ('a')")
+	require.NoError(t, err)
+	require.NoError(t, u.e.UpdateTableSizeLimit(limits.TableSizeLimit{
+		Family:     "family2",
+		Table:      "table1",
+		SizeLimits: limits.SizeLimits{MaxSize: 10, WarnSize: 5},
+	}))
+
+	err = u.e.DeleteFamily("family2", true)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{
+		"DROP TABLE IF EXISTS family2___table2",
+		"DELETE FROM family2___table2",
+		"DROP TABLE IF EXISTS family2___table1",
+		"DELETE FROM family2___table1",
+	}, queryDMLTable(t, u.db, 4))
+
+	tables, err := u.e.FamilyTables("family2")
+	require.NoError(t, err)
+	require.Empty(t, tables)
+	var cnt int
+	require.NoError(t, u.db.QueryRow("SELECT COUNT(*) FROM families WHERE name = 'family2'").Scan(&cnt))
+	require.Equal(t, 0, cnt)
+	require.NoError(t, u.db.QueryRow("SELECT COUNT(*) FROM max_table_sizes WHERE family_name = 'family2'").Scan(&cnt))
+	require.Equal(t, 0, cnt)
+
+	// family1 is untouched
+	_, err = u.e.TableSchema("family1", "table10")
+	require.NoError(t, err)
+
+	err = u.e.DeleteFamily("family2", false)
+	require.IsType(t, &errs.NotFoundError{}, err)
+}
+
 func testDBExecutiveClearTable(t *testing.T, dbType string) {
 	u := newDbExecTestUtil(t, dbType)
 	defer u.Close()
@@ -3,6 +3,7 @@ package executive
 import (
 	"context"
 	"database/sql"
+	"math"
 	"sync"
 	"time"
 
@@ -28,23 +29,32 @@ type (
 		tableSizer         *tableSizer
 		mut                sync.Mutex // protects da maps
 		defaultWriterLimit limits.RateLimit
-		perWriterLimits    map[string]int64 // writer name -> max mutations per period
+		perWriterLimits    map[string]writerLimit // writer name -> limit
+		groupLimits        map[string]writerLimit // group name -> limit
+		writerGroups       map[string]string      // writer name -> group name
 		timeFunc           func() time.Time
 	}
+	// writerLimit is the limit of a writer or group, in mutations per the
+	// limiter's period
+	writerLimit struct {
+		amount int64
+		burst  int64 // if set, the limit is enforced with a token bucket
+	}
 	// limiterRequest represents a request to the limiter for an impending set of writes
 	limiterRequest struct {
 		writerName string
-		familyName string
 		requests   []ExecutiveMutationRequest
 	}
 )
 
-func newDBLimiter(db *sql.DB, dbType string, defaultTableLimit limits.SizeLimits, writerLimitPeriod time.Duration, writerLimit int64) *dbLimiter {
+func newDBLimiter(db *sql.DB, dbType string, defaultTableLimit limits.SizeLimits, defaultWriterLimit limits.RateLimit) *dbLimiter {
 	return &dbLimiter{
 		db:                 db,
 		tableSizer:         newTableSizer(db, dbType, defaultTableLimit, time.Minute),
-		defaultWriterLimit: limits.RateLimit{Amount: writerLimit, Period: writerLimitPeriod},
-		perWriterLimits:    make(map[string]int64),
+		defaultWriterLimit: defaultWriterLimit,
+		perWriterLimits:    make(map[string]writerLimit),
+		groupLimits:        make(map[string]writerLimit),
+		writerGroups:       make(map[string]string),
 	}
 }
 
@@ -53,69 +63,177 @@ func newDBLimiter(db *sql.DB, dbType string, defaultTableLimit limits.SizeLimits
 // the limiter request that has exceeded its max size, the entire request will be rejected.  This is
 // to prevent partial mutations being performed over and over due to the client retrying a failed
 // request that includes some tables that are not over their limits.
-func (l *dbLimiter) allowed(ctx context.Context, tx *sql.Tx, lr limiterRequest) (bool, error) {
+//
+// Requests over a limit are rejected with an *errs.InsufficientStorageErr or
+// *errs.RateLimitExceededErr detailing the limit. The writer's usage of its
+// rate limit, including the request, is returned for allowed requests.
+func (l *dbLimiter) allowed(ctx context.Context, tx *sql.Tx, lr limiterRequest) (errs.LimitDetails, error) {
 	if err := l.checkTableSizes(ctx, lr); err != nil {
-		return false, errors.Wrap(err, "check table sizes")
+		return errs.LimitDetails{}, errors.Wrap(err, "check table sizes")
 	}
-	allowed, err := l.checkWriterRates(ctx, tx, lr)
+	allowed, usage, err := l.checkWriterRates(ctx, tx, lr)
 	if err != nil {
-		return false, errors.Wrap(err, "check writer rates")
+		return errs.LimitDetails{}, errors.Wrap(err, "check writer rates")
+	}
+	if !allowed {
+		return errs.LimitDetails{}, &errs.RateLimitExceededErr{Err: "rate limit exceeded", Details: &usage}
 	}
-	return allowed, nil
+	return usage, nil
 }
 
 // checkWriterRates ensures that the writer has enough of a quote in the current bucket to make writes.
+// Writers in a group draw from the group's quota instead of their own. Limits with a burst are
+// enforced by takeTokens instead.
 //
 // in order to have this work on both mysql and sqlite3, we had to forego the use of nice upsert
 // syntax that is highly driver-dependent. we instead fall back to doing a read-then-write inside
 // of a transaction for writer rates.
-func (l *dbLimiter) checkWriterRates(ctx context.Context, tx *sql.Tx, lr limiterRequest) (bool, error) {
+// The writer's usage of the current bucket, including the request, is
+// returned alongside.
+func (l *dbLimiter) checkWriterRates(ctx context.Context, tx *sql.Tx, lr limiterRequest) (bool, errs.LimitDetails, error) {
+	var usage errs.LimitDetails
 	numMutations := len(lr.requests)
 	if numMutations == 0 {
 		// no problem, we will always allow zero mutations
-		return true, nil
+		return true, usage, nil
 	}
 	bucket := l.periodEpoch()
-	row := tx.QueryRowContext(ctx, "SELECT amount FROM writer_usage WHERE writer_name=? AND bucket=?", lr.writerName, bucket)
+	usageTable, usageColumn, usageName := "writer_usage", "writer_name", lr.writerName
+	group, limit := l.limitForWriter(lr.writerName)
+	if group != "" {
+		usageTable, usageColumn, usageName = "writer_group_usage", "group_name", group
+		stats.Add("writer-group-mutations", numMutations, stats.T("group", group), stats.T("writer", lr.writerName))
+	}
+	if limit.burst > 0 {
+		tokensTable := "writer_tokens"
+		if group != "" {
+			tokensTable = "writer_group_tokens"
+		}
+		allowed, usage, err := l.takeTokens(ctx, tx, tokensTable, usageColumn, usageName, limit, int64(numMutations))
+		if !allowed && err == nil && group != "" {
+			events.Log("writer %{writer}s exceeded the rate limit of its group %{group}s", lr.writerName, group)
+			stats.Incr("writer-group-limited", stats.T("group", group), stats.T("writer", lr.writerName))
+		}
+		return allowed, usage, err
+	}
+	writerLimit := limit.amount
+	row := tx.QueryRowContext(ctx, "SELECT amount FROM "+usageTable+" WHERE "+usageColumn+"=? AND bucket=?", usageName, bucket)
 	var amount int64
 	err := row.Scan(&amount)
 	if err != nil && err != sql.ErrNoRows {
-		return false, errors.Wrap(err, "select from writer_usage")
+		return false, usage, errors.Wrap(err, "select from "+usageTable)
 	}
 	amount += int64(numMutations)
 	if err == sql.ErrNoRows {
 		// do an insert
-		res, err := tx.ExecContext(ctx, "INSERT INTO writer_usage (bucket,writer_name,amount) VALUES (?,?,?)",
-			bucket, lr.writerName, amount)
+		res, err := tx.ExecContext(ctx, "INSERT INTO "+usageTable+" (bucket,"+usageColumn+",amount) VALUES (?,?,?)",
+			bucket, usageName, amount)
 		if err != nil {
-			return false, errors.Wrap(err, "insert into writer_usage")
+			return false, usage, errors.Wrap(err, "insert into "+usageTable)
 		}
 		rowsAffected, err := res.RowsAffected()
 		if err != nil {
-			return false, errors.Wrap(err, "affected rows from insert into writer_usage")
+			return false, usage, errors.Wrap(err, "affected rows from insert into "+usageTable)
 		}
 		if rowsAffected == 0 {
-			return false, errors.New("insert into writer_usage failed (no rows updated)")
+			return false, usage, errors.New("insert into " + usageTable + " failed (no rows updated)")
 		}
 	} else {
 		// do an update
-		res, err := tx.ExecContext(ctx, "UPDATE writer_usage SET amount=? where bucket=? and writer_name=?",
-			amount, bucket, lr.writerName)
+		res, err := tx.ExecContext(ctx, "UPDATE "+usageTable+" SET amount=? where bucket=? and "+usageColumn+"=?",
+			amount, bucket, usageName)
 		if err != nil {
-			return false, errors.Wrap(err, "update writer_usage")
+			return false, usage, errors.Wrap(err, "update "+usageTable)
 		}
 		rowsAffected, err := res.RowsAffected()
 		if err != nil {
-			return false, errors.Wrap(err, "affected rows from update writer_usage")
+			return false, usage, errors.Wrap(err, "affected rows from update "+usageTable)
 		}
 		if rowsAffected == 0 {
-			return false, errors.New("updating writer_usage failed (no rows updated)")
+			return false, usage, errors.New("updating " + usageTable + " failed (no rows updated)")
 		}
 	}
-	writerLimit := l.limitForWriter(lr.writerName)
 	allowed := amount <= writerLimit
-	events.Debug("limiter: writer:%v writerLimit:%v amount:%v allowed:%v", lr.writerName, writerLimit, amount, allowed)
-	return allowed, nil
+	events.Debug("limiter: writer:%v group:%v writerLimit:%v amount:%v allowed:%v", lr.writerName, group, writerLimit, amount, allowed)
+	if !allowed && group != "" {
+		events.Log("writer %{writer}s exceeded the rate limit of its group %{group}s", lr.writerName, group)
+		stats.Incr("writer-group-limited", stats.T("group", group), stats.T("writer", lr.writerName))
+	}
+	resetAt := time.Unix(bucket, 0).Add(l.defaultWriterLimit.Period)
+	usage = errs.LimitDetails{Limit: writerLimit, Current: amount, ResetAt: &resetAt}
+	return allowed, usage, nil
+}
+
+// takeTokens takes a token for each mutation from the token bucket of a
+// writer or group, if it holds enough of them. The bucket holds up to the
+// limit's burst, which it starts out with, and refills at the limit's rate.
+// A request of more mutations than the burst is never allowed.
+//
+// The usage returned counts the tokens missing from the bucket as used, and
+// resets once the bucket has refilled.
+func (l *dbLimiter) takeTokens(ctx context.Context, tx *sql.Tx, table, column, name string, limit writerLimit, n int64) (bool, errs.LimitDetails, error) {
+	now := l.getTime()
+	var tokens float64
+	var refilledAt int64
+	err := tx.QueryRowContext(ctx, "SELECT tokens, refilled_at FROM "+table+" WHERE "+column+"=?", name).Scan(&tokens, &refilledAt)
+	switch {
+	case err == sql.ErrNoRows:
+		tokens = float64(limit.burst)
+	case err != nil:
+		return false, errs.LimitDetails{}, errors.Wrap(err, "select from "+table)
+	default:
+		if elapsed := now.Sub(time.Unix(0, refilledAt)); elapsed > 0 {
+			tokens += l.tokensPer(limit, elapsed)
+		}
+		tokens = math.Min(tokens, float64(limit.burst))
+	}
+	allowed := tokens >= float64(n)
+	if allowed {
+		tokens -= float64(n)
+	}
+
+	// the bucket is saved even when the request isn't allowed, so that the
+	// tokens which it has been refilled with are kept
+	var res sql.Result
+	if err == sql.ErrNoRows {
+		res, err = tx.ExecContext(ctx, "INSERT INTO "+table+" ("+column+",tokens,refilled_at) VALUES (?,?,?)",
+			name, tokens, now.UnixNano())
+	} else {
+		res, err = tx.ExecContext(ctx, "UPDATE "+table+" SET tokens=?, refilled_at=? WHERE "+column+"=?",
+			tokens, now.UnixNano(), name)
+	}
+	if err != nil {
+		return false, errs.LimitDetails{}, errors.Wrap(err, "save "+table)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, errs.LimitDetails{}, errors.Wrap(err, "affected rows from saving "+table)
+	}
+	if rowsAffected == 0 {
+		return false, errs.LimitDetails{}, errors.New("saving " + table + " failed (no rows updated)")
+	}
+
+	used := int64(math.Ceil(float64(limit.burst) - tokens))
+	if !allowed {
+		// as with fixed windows, the usage the request was rejected at
+		used += n
+	}
+	resetAt := now.Add(l.timeToRefill(limit, float64(limit.burst)-tokens))
+	events.Debug("limiter: %v:%v burst:%v tokens:%v allowed:%v", column, name, limit.burst, tokens, allowed)
+	return allowed, errs.LimitDetails{Limit: limit.burst, Current: used, ResetAt: &resetAt}, nil
+}
+
+// tokensPer returns how many tokens a bucket refills with in the duration.
+func (l *dbLimiter) tokensPer(limit writerLimit, d time.Duration) float64 {
+	return float64(limit.amount) * d.Seconds() / l.defaultWriterLimit.Period.Seconds()
+}
+
+// timeToRefill returns how long a bucket takes to refill with the tokens.
+func (l *dbLimiter) timeToRefill(limit writerLimit, tokens float64) time.Duration {
+	if tokens <= 0 || limit.amount <= 0 {
+		return 0
+	}
+	return time.Duration(tokens / float64(limit.amount) * float64(l.defaultWriterLimit.Period)).Round(time.Second)
 }
 
 // checkTableSizes ensures that if we are over our limit for a particular table that's being
@@ -123,7 +241,7 @@ func (l *dbLimiter) checkWriterRates(ctx context.Context, tx *sql.Tx, lr limiter
 func (l *dbLimiter) checkTableSizes(ctx context.Context, lr limiterRequest) error {
 	tables := make(map[schema.FamilyTable]struct{})
 	for _, req := range lr.requests {
-		ft := schema.FamilyTable{Family: lr.familyName, Table: req.TableName}
+		ft := schema.FamilyTable{Family: req.FamilyName, Table: req.TableName}
 		if _, ok := tables[ft]; !ok {
 			tables[ft] = struct{}{} // mark this FamilyTable as having been visited
 			if _, err := l.tableSizer.tableOK(ft); err != nil {
@@ -165,67 +283,127 @@ func (l *dbLimiter) start(ctx context.Context) error {
 	return nil
 }
 
-// deleteOldUsageData cleans the writer_usage table of old entries
+// deleteOldUsageData cleans the writer_usage table of old entries, along
+// with token buckets which haven't been used in as long. Those have long
+// since refilled, which is also how a missing bucket starts out.
 func (l *dbLimiter) deleteOldUsageData(ctx context.Context) error {
-	deleteEpoch := l.getTime().Add(-defaultDeleteUsageOlderThan).Truncate(l.defaultWriterLimit.Period).Unix()
+	deleteTime := l.getTime().Add(-defaultDeleteUsageOlderThan)
+	deleteEpoch := deleteTime.Truncate(l.defaultWriterLimit.Period).Unix()
+
+	for _, table := range []string{"writer_usage", "writer_group_usage"} {
+		if err := l.deleteRows(ctx, table, "bucket", deleteEpoch); err != nil {
+			return err
+		}
+	}
+	for _, table := range []string{"writer_tokens", "writer_group_tokens"} {
+		if err := l.deleteRows(ctx, table, "refilled_at", deleteTime.UnixNano()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	res, err := l.db.ExecContext(ctx, "delete from writer_usage where bucket < ?", deleteEpoch)
+// deleteRows deletes the rows of the table whose column is before the value
+func (l *dbLimiter) deleteRows(ctx context.Context, table, column string, before int64) error {
+	res, err := l.db.ExecContext(ctx, "delete from "+table+" where "+column+" < ?", before)
 	if err != nil {
-		return errors.Wrap(err, "could not delete from writer_usage table")
+		return errors.Wrapf(err, "could not delete from %s table", table)
 	}
 	rows, err := res.RowsAffected()
 	if err != nil {
-		return errors.Wrap(err, "could not get rows affected after deleting from writer_usage")
+		return errors.Wrapf(err, "could not get rows affected after deleting from %s", table)
 	}
 	if rows > 0 {
-		events.Log("deleted %{rows}d rows from the writer_usage table", rows)
+		events.Log("deleted %{rows}d rows from the %{table}s table", rows, table)
 	}
-	stats.Add("writer-usage-rows-deleted", rows)
+	stats.Add("writer-usage-rows-deleted", rows, stats.T("table", table))
 	return nil
 }
 
 // refreshWriterLimits queries the database for the current writer limits configuration
 // and updates the cached values
 func (l *dbLimiter) refreshWriterLimits(ctx context.Context) error {
-	rows, err := l.db.QueryContext(ctx, "select writer_name, max_rows_per_minute FROM max_writer_rates")
+	writerLimits, err := l.queryLimits(ctx, "select writer_name, max_rows_per_minute, burst FROM max_writer_rates")
+	if err != nil {
+		return errors.Wrap(err, "query max_writer_rates")
+	}
+	groupLimits, err := l.queryLimits(ctx, "select group_name, max_rows_per_minute, burst FROM writer_groups")
+	if err != nil {
+		return errors.Wrap(err, "query writer_groups")
+	}
+	writerGroups, err := l.queryWriterGroups(ctx)
+	if err != nil {
+		return errors.Wrap(err, "query writer_group_members")
+	}
+	// update the shared data while locked
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	l.perWriterLimits = writerLimits
+	l.groupLimits = groupLimits
+	l.writerGroups = writerGroups
+	return nil
+}
+
+func (l *dbLimiter) queryWriterGroups(ctx context.Context) (map[string]string, error) {
+	rows, err := l.db.QueryContext(ctx, "select writer_name, group_name FROM writer_group_members")
 	if err != nil {
-		return errors.Wrap(err, "could not query max_writer_rates")
+		return nil, err
 	}
 	defer rows.Close()
-	writerLimits := make(map[string]int64)
+	res := make(map[string]string)
 	for rows.Next() {
-		var writerName string
-		var maxRowsPerMinute int64
-		if err = rows.Scan(&writerName, &maxRowsPerMinute); err != nil {
-			return errors.Wrap(err, "could not scan max_writer_rates")
+		var writerName, groupName string
+		if err := rows.Scan(&writerName, &groupName); err != nil {
+			return nil, errors.Wrap(err, "could not scan writer_group_members")
+		}
+		res[writerName] = groupName
+	}
+	return res, errors.Wrap(rows.Err(), "rows err after scanning")
+}
+
+// queryLimits reads names and their max rows per minute, adjusted to the
+// limiter's period, along with their bursts.
+func (l *dbLimiter) queryLimits(ctx context.Context, query string) (map[string]writerLimit, error) {
+	rows, err := l.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := make(map[string]writerLimit)
+	for rows.Next() {
+		var name string
+		var maxRowsPerMinute, burst int64
+		if err = rows.Scan(&name, &maxRowsPerMinute, &burst); err != nil {
+			return nil, errors.Wrap(err, "could not scan limit")
 		}
 		// we need to convert the max rows per minute to the rate for the period which we're checking
 
 		rateLimit := limits.RateLimit{Amount: maxRowsPerMinute, Period: time.Minute}
 		adjustedRate, err := rateLimit.AdjustAmount(l.defaultWriterLimit.Period)
 		if err != nil {
-			return errors.Wrap(err, "adjust found rate limit")
+			return nil, errors.Wrap(err, "adjust found rate limit")
 		}
-		events.Debug("adjusted %v limit from %v/%v to %v/%v", writerName, maxRowsPerMinute, time.Minute, adjustedRate, l.defaultWriterLimit.Period)
-		writerLimits[writerName] = adjustedRate
+		events.Debug("adjusted %v limit from %v/%v to %v/%v", name, maxRowsPerMinute, time.Minute, adjustedRate, l.defaultWriterLimit.Period)
+		res[name] = writerLimit{amount: adjustedRate, burst: burst}
 	}
-	if err := rows.Err(); err != nil {
-		return errors.Wrap(err, "rows err after scanning")
-	}
-	// update the shared data while locked
-	l.mut.Lock()
-	defer l.mut.Unlock()
-	l.perWriterLimits = writerLimits
-	return nil
+	return res, errors.Wrap(rows.Err(), "rows err after scanning")
 }
 
-func (l *dbLimiter) limitForWriter(writer string) int64 {
+// limitForWriter returns the group the writer is in, if any, and the limit
+// that applies to it. The default limit's burst only applies to writers
+// without a limit of their own.
+func (l *dbLimiter) limitForWriter(writer string) (group string, limit writerLimit) {
 	l.mut.Lock()
 	defer l.mut.Unlock()
+	if group, ok := l.writerGroups[writer]; ok {
+		if groupLimit, ok := l.groupLimits[group]; ok {
+			return group, groupLimit
+		}
+	}
 	if perWriterLimit, ok := l.perWriterLimits[writer]; ok {
-		return perWriterLimit
+		return "", perWriterLimit
 	}
-	return l.defaultWriterLimit.Amount
+	return "", writerLimit{amount: l.defaultWriterLimit.Amount, burst: l.defaultWriterLimit.Burst}
 }
 
 func (l *dbLimiter) periodEpoch() int64 {
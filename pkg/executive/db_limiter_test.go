@@ -41,7 +41,7 @@ func testDBLimiter(t *testing.T, dbType string) {
 	// we control the time using a fakeTime with an epoch of 1000s
 	fakeTime := newFakeTime(1000)
 	defaultTableLimit := limits.SizeLimits{MaxSize: 30 * units.KILOBYTE, WarnSize: 20 * units.KILOBYTE}
-	limiter := newDBLimiter(ctldb, dbType, defaultTableLimit, bucketInterval, writerLimit)
+	limiter := newDBLimiter(ctldb, dbType, defaultTableLimit, limits.RateLimit{Amount: writerLimit, Period: bucketInterval})
 	limiter.timeFunc = fakeTime.get
 	require.NoError(t, limiter.tableSizer.refresh(ctx))
 	require.NoError(t, u.e.CreateFamily(familyName))
@@ -57,7 +57,7 @@ func testDBLimiter(t *testing.T, dbType string) {
 
 	// makeMutation is a func that performs a mutation supplied by the payloadFunc. it fails the test if it
 	// fails, so no need to return a value.
-	makeMutation := func(expectedCode int) {
+	makeMutation := func(expectedCode int) *httptest.ResponseRecorder {
 		req := httptest.NewRequest("POST", "/families/"+familyName+"/mutations", payloadFunc())
 		req.Header.Set("ctlstore-writer", writerName)
 		req.Header.Set("ctlstore-secret", writerSecret)
@@ -69,6 +69,7 @@ func testDBLimiter(t *testing.T, dbType string) {
 			b, _ := ioutil.ReadAll(resp.Body)
 			require.Failf(t, "request failed", "Expected %d, got %d: %s", expectedCode, resp.StatusCode, b)
 		}
+		return w
 	}
 
 	// do the first mutation to create the table
@@ -82,8 +83,15 @@ func testDBLimiter(t *testing.T, dbType string) {
 		makeMutation(http.StatusOK)
 	}
 
-	// if we do another mutation it should fail
-	makeMutation(http.StatusTooManyRequests)
+	// if we do another mutation it should fail, detailing the limit
+	w := makeMutation(http.StatusTooManyRequests)
+	require.Equal(t, "5", w.Header().Get("X-Ctlstore-Limit"))
+	require.Equal(t, "6", w.Header().Get("X-Ctlstore-Limit-Current"))
+	require.Equal(t, "1005", w.Header().Get("X-Ctlstore-Limit-Reset"))
+	require.NotEmpty(t, w.Header().Get("Retry-After"))
+	require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	require.JSONEq(t, `{"error":"rate limit exceeded","limit":5,"current":6,"resetAt":"`+
+		time.Unix(1005, 0).Format(time.RFC3339Nano)+`"}`, w.Body.String())
 
 	// shift the epoch up by $period
 	fakeTime.add(int64(bucketInterval / time.Second))
@@ -117,7 +125,10 @@ func testDBLimiter(t *testing.T, dbType string) {
 	if dbType == "sqlite3" {
 		makeMutation(http.StatusOK)
 	} else {
-		makeMutation(http.StatusInsufficientStorage)
+		w := makeMutation(http.StatusInsufficientStorage)
+		require.Equal(t, "30720", w.Header().Get("X-Ctlstore-Limit"))
+		require.NotEmpty(t, w.Header().Get("X-Ctlstore-Limit-Current"))
+		require.Empty(t, w.Header().Get("X-Ctlstore-Limit-Reset"))
 	}
 
 	countRows := func() int64 {
@@ -150,6 +161,105 @@ func testDBLimiter(t *testing.T, dbType string) {
 
 }
 
+// testDBLimiterBurst is run from TestAllDBExecutive
+func testDBLimiterBurst(t *testing.T, dbType string) {
+	u := newDbExecTestUtil(t, dbType)
+	ctx := u.ctx
+
+	const (
+		writerName = "db-limiter-burst-writer"
+		groupName  = "db-limiter-burst-group"
+	)
+
+	// 1 row per second, bursting up to 10 rows
+	fakeTime := newFakeTime(1000)
+	defaultWriterLimit := limits.RateLimit{Amount: 5, Period: 5 * time.Second, Burst: 10}
+	limiter := newDBLimiter(u.db, dbType, testDefaultTableLimit, defaultWriterLimit)
+	limiter.timeFunc = fakeTime.get
+	require.NoError(t, limiter.refreshWriterLimits(ctx))
+
+	check := func(name string, n int) (bool, int64) {
+		tx, err := u.db.BeginTx(ctx, nil)
+		require.NoError(t, err)
+		defer tx.Rollback()
+		allowed, usage, err := limiter.checkWriterRates(ctx, tx, limiterRequest{
+			writerName: name,
+			requests:   make([]ExecutiveMutationRequest, n),
+		})
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+		return allowed, usage.Current
+	}
+
+	// the bucket starts out full, so the whole burst may be written at once
+	allowed, current := check(writerName, 10)
+	require.True(t, allowed)
+	require.EqualValues(t, 10, current)
+	allowed, current = check(writerName, 1)
+	require.False(t, allowed)
+	require.EqualValues(t, 11, current)
+
+	// it refills at the rate of the limit, and not past the burst
+	fakeTime.add(3)
+	allowed, _ = check(writerName, 4)
+	require.False(t, allowed)
+	allowed, current = check(writerName, 3)
+	require.True(t, allowed)
+	require.EqualValues(t, 10, current)
+	fakeTime.add(60)
+	allowed, _ = check(writerName, 11)
+	require.False(t, allowed)
+	allowed, _ = check(writerName, 10)
+	require.True(t, allowed)
+
+	// a writer's own limit replaces the default burst
+	_, err := u.db.ExecContext(ctx, "insert into max_writer_rates (writer_name, max_rows_per_minute, burst) values(?,?,?)",
+		writerName, 60, 20)
+	require.NoError(t, err)
+	require.NoError(t, limiter.refreshWriterLimits(ctx))
+	fakeTime.add(20)
+	allowed, _ = check(writerName, 20)
+	require.True(t, allowed)
+
+	// and a writer limit without a burst uses a fixed window
+	_, err = u.db.ExecContext(ctx, "update max_writer_rates set burst=0 where writer_name=?", writerName)
+	require.NoError(t, err)
+	require.NoError(t, limiter.refreshWriterLimits(ctx))
+	allowed, _ = check(writerName, 5)
+	require.True(t, allowed)
+
+	// the members of a group share its bucket
+	_, err = u.db.ExecContext(ctx, "insert into writer_groups (group_name, max_rows_per_minute, burst) values(?,?,?)",
+		groupName, 60, 5)
+	require.NoError(t, err)
+	for _, member := range []string{"burst-member-1", "burst-member-2"} {
+		_, err = u.db.ExecContext(ctx, "insert into writer_group_members (writer_name, group_name) values(?,?)",
+			member, groupName)
+		require.NoError(t, err)
+	}
+	require.NoError(t, limiter.refreshWriterLimits(ctx))
+	allowed, _ = check("burst-member-1", 3)
+	require.True(t, allowed)
+	allowed, _ = check("burst-member-2", 3)
+	require.False(t, allowed)
+	allowed, _ = check("burst-member-2", 2)
+	require.True(t, allowed)
+
+	// token buckets which haven't been refilled in a day are cleaned up
+	countTokens := func() (count int64) {
+		err := u.db.QueryRowContext(ctx, "select (select count(*) from writer_tokens) + "+
+			"(select count(*) from writer_group_tokens)").Scan(&count)
+		require.NoError(t, err)
+		return count
+	}
+	require.EqualValues(t, 2, countTokens())
+	require.NoError(t, limiter.deleteOldUsageData(ctx))
+	require.EqualValues(t, 2, countTokens())
+	fakeTime.add(int64(25 * time.Hour / time.Second))
+	require.NoError(t, limiter.deleteOldUsageData(ctx))
+	require.EqualValues(t, 0, countTokens())
+}
+
 // newMutationPayload is a helper that produces a func that produces a reader that supplies a
 // payload to the mutation api. it maintains its own internal cookie that gets incremented on
 // each payload
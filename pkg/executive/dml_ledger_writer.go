@@ -4,9 +4,11 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/pkg/errors"
 	"github.com/segmentio/ctlstore/pkg/errs"
 	"github.com/segmentio/ctlstore/pkg/schema"
 	"github.com/segmentio/ctlstore/pkg/sqlgen"
+	"github.com/segmentio/ctlstore/pkg/tracing"
 	"github.com/segmentio/stats/v4"
 )
 
@@ -15,7 +17,13 @@ import (
 type dmlLedgerWriter struct {
 	Tx        *sql.Tx
 	TableName string
-	_stmt     *sql.Stmt
+	// TraceIDs records the trace ID of the context's span, if any, along
+	// with each entry.
+	TraceIDs bool
+	// Versions records the version of each entry, which the ledger needs
+	// a version column for. Entries are otherwise SQL statements.
+	Versions bool
+	_stmt    *sql.Stmt
 }
 
 func (w *dmlLedgerWriter) BeginTx(ctx context.Context) (seq schema.DMLSequence, err error) {
@@ -29,8 +37,25 @@ func (w *dmlLedgerWriter) CommitTx(ctx context.Context) (seq schema.DMLSequence,
 // Writes an entry to the DML log, returning the sequence or an error
 // if any occurs.
 func (w *dmlLedgerWriter) Add(ctx context.Context, statement string) (seq schema.DMLSequence, err error) {
+	return w.AddVersion(ctx, statement, schema.DMLVersionSQL)
+}
+
+// AddVersion is like Add, except that the statement is encoded as the
+// given version, such as schema.DMLVersionParameterized, which requires
+// Versions to be set.
+func (w *dmlLedgerWriter) AddVersion(ctx context.Context, statement string, version int) (seq schema.DMLSequence, err error) {
+	if version != schema.DMLVersionSQL && !w.Versions {
+		return 0, errors.Errorf("ledger statement version %d requires recording versions", version)
+	}
 	if w._stmt == nil {
-		qs := sqlgen.SqlSprintf("INSERT INTO $1 (statement) VALUES(?)", w.TableName)
+		columns, placeholders := "statement", "?"
+		if w.TraceIDs {
+			columns, placeholders = columns+", trace_id", placeholders+", ?"
+		}
+		if w.Versions {
+			columns, placeholders = columns+", version", placeholders+", ?"
+		}
+		qs := sqlgen.SqlSprintf("INSERT INTO $1 ($2) VALUES($3)", w.TableName, columns, placeholders)
 		stmt, err := w.Tx.PrepareContext(ctx, qs)
 		if err != nil {
 			errs.Incr("dml_ledger_writer.prepare.error")
@@ -39,7 +64,18 @@ func (w *dmlLedgerWriter) Add(ctx context.Context, statement string) (seq schema
 		w._stmt = stmt
 	}
 
-	res, err := w._stmt.ExecContext(ctx, statement)
+	args := []interface{}{statement}
+	if w.TraceIDs {
+		var traceID sql.NullString
+		if id, ok := tracing.TraceIDFromContext(ctx); ok {
+			traceID = sql.NullString{String: id.String(), Valid: true}
+		}
+		args = append(args, traceID)
+	}
+	if w.Versions {
+		args = append(args, version)
+	}
+	res, err := w._stmt.ExecContext(ctx, args...)
 	if err != nil {
 		errs.Incr("dml_ledger_writer.exec.error")
 		return
@@ -1,9 +1,13 @@
 package executive
 
 import (
+	"time"
+
 	"github.com/pkg/errors"
+	"github.com/segmentio/ctlstore/pkg/errs"
 	"github.com/segmentio/ctlstore/pkg/limits"
 	"github.com/segmentio/ctlstore/pkg/schema"
+	"github.com/segmentio/ctlstore/pkg/sqlgen"
 )
 
 const (
@@ -12,9 +16,136 @@ const (
 )
 
 type ExecutiveMutationRequest struct {
-	TableName string
-	Delete    bool
-	Values    map[string]interface{}
+	// FamilyName is the family of the table. It's only set for
+	// MutateFamilies, since all of the requests passed to Mutate are in
+	// the family it's passed.
+	FamilyName string
+	TableName  string
+	Delete     bool
+	Values     map[string]interface{}
+	// IfValues makes the mutation conditional. If it's set, the mutation is
+	// only applied if the row doesn't exist, or if the row's values for the
+	// fields in IfValues match it.
+	IfValues map[string]interface{}
+}
+
+// MutationResult describes the outcome of a Mutate or MutateFamilies call.
+type MutationResult struct {
+	// Skipped holds the indexes of the conditional mutations that weren't
+	// applied because their condition didn't hold
+	Skipped []int `json:"skipped"`
+	// Applied is how many of the mutations were applied
+	Applied int `json:"applied"`
+	// DMLBytes is the size of the statements written to the ledger for the
+	// applied mutations
+	DMLBytes int `json:"dmlBytes"`
+	// RateLimit is what's left of the writer's rate limit for the current
+	// period, so that writers can slow down before they are rate limited
+	RateLimit *RateLimitBudget `json:"rateLimit,omitempty"`
+	// LedgerSeq is the sequence of the last ledger entry that was written,
+	// which is zero if there were none
+	LedgerSeq int64 `json:"ledgerSeq,omitempty"`
+}
+
+// RateLimitBudget is the state of a writer's rate limit as of a mutation.
+// Skipped mutations count towards the limit.
+type RateLimitBudget struct {
+	Limit     int64     `json:"limit"`
+	Remaining int64     `json:"remaining"`
+	ResetAt   time.Time `json:"resetAt"`
+}
+
+// WriterInfo describes a registered writer, the limit on its mutations
+// and its most recent activity. It doesn't include the writer's secret.
+type WriterInfo struct {
+	Name string `json:"name"`
+	// CreatedAt is nil for writers registered before it was recorded
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+	// LastMutationAt is nil if the writer has never applied a mutation
+	LastMutationAt *time.Time `json:"lastMutationAt,omitempty"`
+	LastSourceIP   string     `json:"lastSourceIP,omitempty"`
+	MutationCount  int64      `json:"mutationCount"`
+	// CookieLength is the size of the writer's cookie in bytes
+	CookieLength int `json:"cookieLength"`
+	// RateLimit is the limit on the rows the writer may mutate, which is
+	// its group's if RateLimitGroup is set
+	RateLimit      limits.RateLimit `json:"rateLimit"`
+	RateLimitGroup string           `json:"rateLimitGroup,omitempty"`
+	// DisabledAt is set once the writer has been disabled for being idle
+	DisabledAt *time.Time `json:"disabledAt,omitempty"`
+}
+
+// CookieSwap is the outcome of a compare-and-swap of a writer's cookie.
+type CookieSwap struct {
+	Swapped bool `json:"swapped"`
+	// Cookie is the new cookie if it was swapped, and otherwise the current
+	// cookie, which didn't match the expected one
+	Cookie []byte `json:"cookie"`
+}
+
+// WriterActivity describes how recently a writer has mutated.
+type WriterActivity struct {
+	Name string `json:"name"`
+	// CreatedAt is nil for writers registered before it was recorded
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+	// LastMutationAt is nil if the writer has never applied a mutation
+	LastMutationAt *time.Time `json:"lastMutationAt,omitempty"`
+	LastSourceIP   string     `json:"lastSourceIP,omitempty"`
+	MutationCount  int64      `json:"mutationCount"`
+	// IdleSeconds is the time since the writer last mutated, was registered
+	// or was re-enabled, whichever is latest. It's zero if none are known.
+	IdleSeconds int64 `json:"idleSeconds"`
+	// DisabledAt is set once the writer has been disabled for being idle
+	DisabledAt *time.Time `json:"disabledAt,omitempty"`
+	// EnabledAt is when the writer was last re-enabled, if ever
+	EnabledAt *time.Time `json:"enabledAt,omitempty"`
+}
+
+// The actions of the audit records of writer group members.
+const (
+	WriterGroupMemberAdded   = "added"
+	WriterGroupMemberRemoved = "removed"
+)
+
+// WriterGroupEvent is an audit record of a writer being added to or removed
+// from a writer group.
+type WriterGroupEvent struct {
+	Group    string    `json:"group"`
+	Writer   string    `json:"writer"`
+	Action   string    `json:"action"`
+	SourceIP string    `json:"sourceIP,omitempty"`
+	At       time.Time `json:"at"`
+}
+
+// RowsQuery selects a page of a table's rows, ordered by primary key.
+type RowsQuery struct {
+	Limit  int
+	Offset int
+	// After, if set, is the primary key of the last row of the previous
+	// page, in key field order. Unlike Offset, it doesn't require the ctldb
+	// to skip over all of the earlier rows.
+	After []interface{}
+}
+
+// LedgerQuery selects a range of DML ledger entries, ordered by seq.
+type LedgerQuery struct {
+	FromSeq int64
+	// ToSeq is inclusive, and zero reads to the end of the ledger
+	ToSeq int64
+	Limit int
+}
+
+// LedgerEntry is a statement written to the DML ledger.
+type LedgerEntry struct {
+	Seq       int64      `json:"seq"`
+	Statement string     `json:"statement"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	// TraceID is the trace of the request which wrote the entry, if trace
+	// IDs are recorded.
+	TraceID string `json:"traceID,omitempty"`
+	// Version is how the statement is encoded, if versions are recorded.
+	// See schema.DMLVersionSQL.
+	Version int `json:"version,omitempty"`
 }
 
 //counterfeiter:generate -o fakes/executive_interface.go . ExecutiveInterface
@@ -22,19 +153,58 @@ type ExecutiveInterface interface {
 	CreateFamily(familyName string) error
 	CreateTable(familyName string, tableName string, fieldNames []string, fieldTypes []schema.FieldType, keyFields []string) error
 	CreateTables([]schema.Table) error
-	AddFields(familyName string, tableName string, fieldNames []string, fieldTypes []schema.FieldType) error
+	CloneTable(source schema.FamilyTable, target schema.FamilyTable, copyRows int, writerName string) (CloneResult, error)
+	ApplySchema(tables []schema.Table, dryRun bool) (SchemaPlan, error)
+	AddFields(familyName string, tableName string, fieldNames []string, fieldTypes []schema.FieldType, fieldOptions map[string]schema.FieldOptions) error
+	AlterField(familyName string, tableName string, fieldName string, newFieldName string, newFieldType schema.FieldType) error
+	SetTableReferences(table schema.FamilyTable, references map[string]schema.FieldReference, strict bool) error
 
-	Mutate(writerName string, writerSecret string, familyName string, cookie []byte, checkCookie []byte, requests []ExecutiveMutationRequest) error
+	Mutate(writerName string, writerSecret string, familyName string, cookie []byte, checkCookie []byte, requests []ExecutiveMutationRequest) (MutationResult, error)
+	MutateFamilies(writerName string, writerSecret string, cookie []byte, checkCookie []byte, requests []ExecutiveMutationRequest) (MutationResult, error)
 	GetWriterCookie(writerName string, writerSecret string) ([]byte, error)
 	SetWriterCookie(writerName string, writerSecret string, cookie []byte) error
+	CompareAndSwapWriterCookie(writerName string, writerSecret string, expected []byte, cookie []byte) (CookieSwap, error)
 	RegisterWriter(writerName string, writerSecret string) error
+	CheckWriterSecret(writerName string, writerSecret string) (bool, error)
+	ReadWriters() ([]WriterInfo, error)
+	ReadWriterActivity(writerName string) (WriterActivity, error)
+	EnableWriter(writerName string) error
+	RequestWriterRegistration(writerName string, writerSecret string, description string) (WriterRegistration, error)
+	ReadWriterRegistrations(status string) ([]WriterRegistration, error)
+	ApproveWriterRegistration(writerName string, review WriterReview) error
+	RejectWriterRegistration(writerName string, review WriterReview) error
+	ReadWriterRegistrationAudit(writerName string) ([]WriterRegistrationEvent, error)
+
+	SaveTableTemplate(template schema.TableTemplate) error
+	ReadTableTemplates(familyName string) ([]schema.TableTemplate, error)
+	DeleteTableTemplate(familyName string, templateName string) error
+	SaveFamilyDefaults(defaults FamilyDefaults) error
+	ReadFamilyDefaults(familyName string) (FamilyDefaults, error)
+
+	RegisterWebhook(familyName string, url string, secret string) (Webhook, error)
+	ReadWebhooks(familyName string) ([]Webhook, error)
+	DeleteWebhook(familyName string, id string) error
+
+	CreateAPIToken(name string) (APIToken, error)
+	ReadAPITokens() ([]APIToken, error)
+	DeleteAPIToken(name string) error
+	CheckAPIToken(token string) (APIToken, error)
 
 	TableSchema(familyName string, tableName string) (*schema.Table, error)
 	FamilySchemas(familyName string) ([]schema.Table, error)
+	FamilyTables(familyName string) ([]string, error)
 
 	ReadRow(familyName string, tableName string, where map[string]interface{}) (map[string]interface{}, error)
+	ReadRows(familyName string, tableName string, query RowsQuery, fn func(row map[string]interface{}) error) error
+	ReadLedger(query LedgerQuery) ([]LedgerEntry, error)
+	ReadLedgerSeq() (int64, error)
+
+	StartExport(familyName string, tableName string, req ExportRequest) (*ExportJob, error)
+	ReadExportJob(id string) (*ExportJob, error)
 
 	ReadTableSizeLimits() (limits.TableSizeLimits, error)
+	AnalyzeTables(tables []schema.FamilyTable) ([]TableAnalysis, error)
+	ReadTableSizeProjection(table schema.FamilyTable) (limits.TableSizeProjection, error)
 	UpdateTableSizeLimit(limit limits.TableSizeLimit) error
 	DeleteTableSizeLimit(table schema.FamilyTable) error
 
@@ -42,8 +212,19 @@ type ExecutiveInterface interface {
 	UpdateWriterRateLimit(limit limits.WriterRateLimit) error
 	DeleteWriterRateLimit(writerName string) error
 
+	ReadWriterGroups() ([]limits.WriterGroup, error)
+	UpdateWriterGroup(groupName string, limit limits.RateLimit) error
+	DeleteWriterGroup(groupName string) error
+	AddWriterGroupMember(groupName string, writerName string, writerSecret string) error
+	RemoveWriterGroupMember(groupName string, writerName string) error
+	ReadWriterGroupAudit(groupName string) ([]WriterGroupEvent, error)
+
+	SetMaintenance(m Maintenance) error
+	ReadMaintenance() (Maintenance, error)
+
 	ClearTable(table schema.FamilyTable) error
 	DropTable(table schema.FamilyTable) error
+	DeleteFamily(familyName string, tombstones bool) error
 	ReadFamilyTableNames(familyName schema.FamilyName) ([]schema.FamilyTable, error)
 }
 
@@ -52,9 +233,16 @@ type mutationRequest struct {
 	TableName  schema.TableName
 	Delete     bool
 	Values     map[schema.FieldName]interface{}
+	// IfValues is nil unless the mutation is conditional
+	IfValues map[schema.FieldName]interface{}
 }
 
-func newMutationRequest(famName schema.FamilyName, req ExecutiveMutationRequest) (mutationRequest, error) {
+func newMutationRequest(req ExecutiveMutationRequest) (mutationRequest, error) {
+	famName, err := schema.NewFamilyName(req.FamilyName)
+	if err != nil {
+		return mutationRequest{}, err
+	}
+
 	tblName, err := schema.NewTableName(req.TableName)
 	if err != nil {
 		return mutationRequest{}, nil
@@ -69,11 +257,24 @@ func newMutationRequest(famName schema.FamilyName, req ExecutiveMutationRequest)
 		vals[fn] = val
 	}
 
+	var ifVals map[schema.FieldName]interface{}
+	if req.IfValues != nil {
+		ifVals = map[schema.FieldName]interface{}{}
+		for name, val := range req.IfValues {
+			fn, err := schema.NewFieldName(name)
+			if err != nil {
+				return mutationRequest{}, err
+			}
+			ifVals[fn] = val
+		}
+	}
+
 	return mutationRequest{
 		FamilyName: famName,
 		TableName:  tblName,
 		Delete:     req.Delete,
 		Values:     vals,
+		IfValues:   ifVals,
 	}, nil
 }
 
@@ -91,14 +292,38 @@ func (r *mutationRequest) valuesByOrder(fieldOrder []schema.FieldName) ([]interf
 	return values, nil
 }
 
+// upsertValues returns the fields an upsert of the request sets, in table
+// order, with their values. Fields with a default may be omitted from the
+// request, in which case they are left out so that they get their default.
+func (r *mutationRequest) upsertValues(tbl sqlgen.MetaTable) ([]schema.FieldName, []interface{}, error) {
+	fieldNames := []schema.FieldName{}
+	values := []interface{}{}
+	for _, field := range tbl.Fields {
+		fn := field.Name
+		v, ok := r.Values[fn]
+		switch {
+		case ok:
+			v, err := schema.NormalizeFieldValue(field.FieldType, v)
+			if err != nil {
+				return nil, nil, errs.BadRequest("Field %s: %s", fn, err)
+			}
+			fieldNames = append(fieldNames, fn)
+			values = append(values, v)
+		case !tbl.FieldOptions[fn].HasDefault():
+			return nil, nil, errors.Errorf("Missing field %s", fn)
+		}
+	}
+	return fieldNames, values, nil
+}
+
 type mutationRequestSet struct {
 	Requests []mutationRequest
 }
 
-func newMutationRequestSet(famName schema.FamilyName, exReqs []ExecutiveMutationRequest) (mutationRequestSet, error) {
+func newMutationRequestSet(exReqs []ExecutiveMutationRequest) (mutationRequestSet, error) {
 	reqs := make([]mutationRequest, len(exReqs))
 	for i, exReq := range exReqs {
-		req, err := newMutationRequest(famName, exReq)
+		req, err := newMutationRequest(exReq)
 		if err != nil {
 			return mutationRequestSet{}, err
 		}
@@ -107,19 +332,35 @@ func newMutationRequestSet(famName schema.FamilyName, exReqs []ExecutiveMutation
 	return mutationRequestSet{reqs}, nil
 }
 
-// Return the unique set of table names as a O(1) lookup map
-func (s *mutationRequestSet) TableNameSet() map[schema.TableName]struct{} {
+// Return the unique set of family names, in the order they're first
+// mutated
+func (s *mutationRequestSet) FamilyNames() []schema.FamilyName {
+	fns := []schema.FamilyName{}
+	seen := map[schema.FamilyName]struct{}{}
+	for _, req := range s.Requests {
+		if _, ok := seen[req.FamilyName]; !ok {
+			seen[req.FamilyName] = struct{}{}
+			fns = append(fns, req.FamilyName)
+		}
+	}
+	return fns
+}
+
+// Return the unique set of table names in a family as a O(1) lookup map
+func (s *mutationRequestSet) TableNameSet(famName schema.FamilyName) map[schema.TableName]struct{} {
 	tnset := map[schema.TableName]struct{}{}
 	for _, req := range s.Requests {
-		tnset[req.TableName] = struct{}{}
+		if req.FamilyName == famName {
+			tnset[req.TableName] = struct{}{}
+		}
 	}
 	return tnset
 }
 
-// Return the unique set of table names as a slice
-func (s *mutationRequestSet) TableNames() []schema.TableName {
+// Return the unique set of table names in a family as a slice
+func (s *mutationRequestSet) TableNames(famName schema.FamilyName) []schema.TableName {
 	tns := []schema.TableName{}
-	for tableName := range s.TableNameSet() {
+	for tableName := range s.TableNameSet(famName) {
 		tns = append(tns, tableName)
 	}
 	return tns
@@ -1,10 +1,15 @@
 package executive
 
 import (
+	"compress/gzip"
 	"encoding/json"
+	"io"
 	"io/ioutil"
+	"math"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/segmentio/ctlstore/pkg/errs"
@@ -17,11 +22,25 @@ import (
 	"github.com/segmentio/events/v2"
 )
 
+const (
+	defaultRowsLimit = 1000
+	maxRowsLimit     = 10000
+)
+
 // ExecutiveEndpoint is an HTTP 'wrapper' for ExecutiveInterface
 type ExecutiveEndpoint struct {
 	HealthChecker                  HealthChecker
 	Exec                           ExecutiveInterface
 	EnableDestructiveSchemaChanges bool
+	// RequireWriterApproval refuses to register writers directly, so that
+	// they must be requested and then approved by an admin
+	RequireWriterApproval bool
+	// RequireAuth rejects requests which present neither an admin or API
+	// token, other than those to the routes writers call with their own
+	// credentials. API tokens can then only be managed by admins.
+	RequireAuth bool
+	// AdminTokens authorize any request. See AdminToken.
+	AdminTokens []AdminToken
 }
 
 func (ee *ExecutiveEndpoint) handleFamilyRoute(w http.ResponseWriter, r *http.Request) {
@@ -62,6 +81,37 @@ func (ee *ExecutiveEndpoint) handleTablesRoute(w http.ResponseWriter, r *http.Re
 	}
 }
 
+// handleSchemaApply creates the families, tables and fields of the desired
+// tables that don't exist yet, and responds with the changes. With
+// ?dryRun=true the changes are only planned.
+func (ee *ExecutiveEndpoint) handleSchemaApply(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		var dryRun bool
+		if raw := r.URL.Query().Get("dryRun"); raw != "" {
+			var err error
+			dryRun, err = strconv.ParseBool(raw)
+			if err != nil {
+				return errs.BadRequest("dryRun must be a boolean")
+			}
+		}
+		var payload []schema.Table
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			return &errs.BadRequestError{Err: "JSON Error: " + err.Error()}
+		}
+		plan, err := ee.Exec.ApplySchema(payload, dryRun)
+		if err != nil {
+			return err
+		}
+		b, err := json.Marshal(plan)
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, err = w.Write(b)
+		return err
+	})
+}
+
 func (ee *ExecutiveEndpoint) handleTableRoute(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	// if these panic, Mux is broken and nothing is sacred anymore
@@ -77,8 +127,11 @@ func (ee *ExecutiveEndpoint) handleTableRoute(w http.ResponseWriter, r *http.Req
 	switch r.Method {
 	case "POST":
 		payload := struct {
-			Fields    [][]string `json:"fields"`
-			KeyFields []string   `json:"keyFields"`
+			Fields            [][]string                     `json:"fields"`
+			KeyFields         []string                       `json:"keyFields"`
+			Template          string                         `json:"template"`
+			FieldOptions      map[string]schema.FieldOptions `json:"fieldOptions"`
+			UniqueConstraints [][]string                     `json:"uniqueConstraints"`
 		}{}
 
 		err = json.Unmarshal(rawBody, &payload)
@@ -87,6 +140,22 @@ func (ee *ExecutiveEndpoint) handleTableRoute(w http.ResponseWriter, r *http.Req
 			return
 		}
 
+		if payload.Template != "" || len(payload.FieldOptions) > 0 || len(payload.UniqueConstraints) > 0 {
+			err = ee.Exec.CreateTables([]schema.Table{{
+				Family:            familyName,
+				Name:              tableName,
+				Fields:            payload.Fields,
+				KeyFields:         payload.KeyFields,
+				Template:          payload.Template,
+				FieldOptions:      payload.FieldOptions,
+				UniqueConstraints: payload.UniqueConstraints,
+			}})
+			if err != nil {
+				writeErrorResponse(err, w)
+			}
+			return
+		}
+
 		fieldNames, fieldTypes, err := schema.UnzipFieldsParam(payload.Fields)
 		if err != nil {
 			writeErrorResponse(&errs.BadRequestError{Err: "Error unzipping fields: " + err.Error()}, w)
@@ -101,7 +170,8 @@ func (ee *ExecutiveEndpoint) handleTableRoute(w http.ResponseWriter, r *http.Req
 
 	case "PUT":
 		payload := struct {
-			Fields [][]string `json:"fields"`
+			Fields       [][]string                     `json:"fields"`
+			FieldOptions map[string]schema.FieldOptions `json:"fieldOptions"`
 		}{}
 
 		err = json.Unmarshal(rawBody, &payload)
@@ -116,7 +186,7 @@ func (ee *ExecutiveEndpoint) handleTableRoute(w http.ResponseWriter, r *http.Req
 			return
 		}
 
-		err = ee.Exec.AddFields(familyName, tableName, fieldNames, fieldTypes)
+		err = ee.Exec.AddFields(familyName, tableName, fieldNames, fieldTypes, payload.FieldOptions)
 		if err != nil {
 			writeErrorResponse(err, w)
 			return
@@ -127,6 +197,255 @@ func (ee *ExecutiveEndpoint) handleTableRoute(w http.ResponseWriter, r *http.Req
 	}
 }
 
+// handleColumnRoute renames a column and/or widens its type. Either
+// attribute may be omitted from the payload to leave it unchanged.
+func (ee *ExecutiveEndpoint) handleColumnRoute(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		vars := mux.Vars(r)
+		familyName := vars["familyName"]
+		tableName := vars["tableName"]
+		columnName := vars["columnName"]
+
+		payload := struct {
+			Name string `json:"name"`
+			Type string `json:"type"`
+		}{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			return &errs.BadRequestError{Err: "JSON Error: " + err.Error()}
+		}
+
+		var fieldType schema.FieldType
+		if payload.Type != "" {
+			ft, ok := schema.FieldTypeMap()[payload.Type]
+			if !ok {
+				return errs.BadRequest("Unknown field type: '%s'", payload.Type)
+			}
+			fieldType = ft
+		}
+		return ee.Exec.AlterField(familyName, tableName, columnName, payload.Name, fieldType)
+	})
+}
+
+func (ee *ExecutiveEndpoint) handleTemplatesRead(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		familyName := mux.Vars(r)["familyName"]
+		templates, err := ee.Exec.ReadTableTemplates(familyName)
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(w).Encode(templates)
+	})
+}
+
+func (ee *ExecutiveEndpoint) handleTemplateSave(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		vars := mux.Vars(r)
+		var template schema.TableTemplate
+		if err := json.NewDecoder(r.Body).Decode(&template); err != nil {
+			return &errs.BadRequestError{Err: "JSON Error: " + err.Error()}
+		}
+		template.Family = vars["familyName"]
+		template.Name = vars["templateName"]
+		return ee.Exec.SaveTableTemplate(template)
+	})
+}
+
+func (ee *ExecutiveEndpoint) handleFamilyDefaultsRead(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		defaults, err := ee.Exec.ReadFamilyDefaults(mux.Vars(r)["familyName"])
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(w).Encode(defaults)
+	})
+}
+
+// handleFamilyDefaultsSave replaces the defaults of a family from a body
+// like {"tableSizeLimits": {"max-size": 1000, "warn-size": 800},
+// "keyFieldPattern": "_id$", "maxKeyFields": 2}. An empty body object
+// removes them.
+func (ee *ExecutiveEndpoint) handleFamilyDefaultsSave(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		var defaults FamilyDefaults
+		if err := json.NewDecoder(r.Body).Decode(&defaults); err != nil {
+			return &errs.BadRequestError{Err: "JSON Error: " + err.Error()}
+		}
+		defaults.Family = mux.Vars(r)["familyName"]
+		return ee.Exec.SaveFamilyDefaults(defaults)
+	})
+}
+
+// handleTableReferencesUpdate replaces the references of a table's fields
+// from a body like {"references": {"user_id": {"family": "...", "table":
+// "...", "field": "..."}}, "strict": true}.
+func (ee *ExecutiveEndpoint) handleTableReferencesUpdate(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		vars := mux.Vars(r)
+		var req struct {
+			References map[string]schema.FieldReference `json:"references"`
+			Strict     bool                             `json:"strict"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return &errs.BadRequestError{Err: "JSON Error: " + err.Error()}
+		}
+		table := schema.FamilyTable{Family: vars["familyName"], Table: vars["tableName"]}
+		return ee.Exec.SetTableReferences(table, req.References, req.Strict)
+	})
+}
+
+func (ee *ExecutiveEndpoint) handleTemplateDelete(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		vars := mux.Vars(r)
+		return ee.Exec.DeleteTableTemplate(vars["familyName"], vars["templateName"])
+	})
+}
+
+func (ee *ExecutiveEndpoint) handleWebhooksRead(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		hooks, err := ee.Exec.ReadWebhooks(mux.Vars(r)["familyName"])
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(w).Encode(hooks)
+	})
+}
+
+// handleWebhookRegister registers a webhook from a body like
+// {"url": "https://...", "secret": "..."}, and responds with the webhook.
+func (ee *ExecutiveEndpoint) handleWebhookRegister(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		var req struct {
+			URL    string `json:"url"`
+			Secret string `json:"secret"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return &errs.BadRequestError{Err: "JSON Error: " + err.Error()}
+		}
+		hook, err := ee.Exec.RegisterWebhook(mux.Vars(r)["familyName"], req.URL, req.Secret)
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(w).Encode(hook)
+	})
+}
+
+func (ee *ExecutiveEndpoint) handleWebhookDelete(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		vars := mux.Vars(r)
+		return ee.Exec.DeleteWebhook(vars["familyName"], vars["webhookID"])
+	})
+}
+
+func (ee *ExecutiveEndpoint) handleAPITokensRead(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		tokens, err := ee.Exec.ReadAPITokens()
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(w).Encode(tokens)
+	})
+}
+
+// handleAPITokenCreate creates a read-only API token from a body like
+// {"name": "dashboard"}, and responds with the token, which can't be read
+// again.
+func (ee *ExecutiveEndpoint) handleAPITokenCreate(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		var req struct {
+			Name string `json:"name"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return &errs.BadRequestError{Err: "JSON Error: " + err.Error()}
+		}
+		token, err := ee.Exec.CreateAPIToken(req.Name)
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(w).Encode(token)
+	})
+}
+
+func (ee *ExecutiveEndpoint) handleAPITokenDelete(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		return ee.Exec.DeleteAPIToken(mux.Vars(r)["tokenName"])
+	})
+}
+
+// writerRoutes are the routes which writers call, authenticated by their own
+// credentials, along with the health check. They're served without an admin
+// or API token even when authentication is required.
+var writerRoutes = map[string]bool{
+	"/cookie":                          true,
+	"/cookie/compare-and-swap":         true,
+	"/families/{familyName}/mutations": true,
+	"/mutations":                       true,
+	"/writers/{writerName}":            true,
+	"/writers/{writerName}/request":    true,
+	"/status":                          true,
+}
+
+// adminRoutes manage the API tokens, so they can't be called with one, and
+// require an admin token when authentication is required.
+var adminRoutes = map[string]bool{
+	"/tokens":             true,
+	"/tokens/{tokenName}": true,
+}
+
+// reviewRoutes approve or reject writer registrations, which always requires
+// an admin token, so that the admin is known.
+var reviewRoutes = map[string]bool{
+	"/writer-registrations/{writerName}/approve": true,
+	"/writer-registrations/{writerName}/reject":  true,
+}
+
+// authorizeTokens authorizes requests which present an admin or API token in
+// their Authorization header. Admin tokens authorize any request, while API
+// tokens are restricted to GET endpoints other than the admin and review
+// routes, which need an admin token.
+// Requests without a token are served as before, unless authentication is
+// required.
+func (ee *ExecutiveEndpoint) authorizeTokens(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var route string
+		if cur := mux.CurrentRoute(r); cur != nil {
+			route, _ = cur.GetPathTemplate()
+		}
+		auth := r.Header.Get("Authorization")
+		if auth == "" {
+			switch {
+			case reviewRoutes[route]:
+				writeErrorResponse(ErrAdminRequired, w)
+				return
+			case ee.RequireAuth && !writerRoutes[route]:
+				writeErrorResponse(ErrAuthenticationRequired, w)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		token := strings.TrimPrefix(auth, "Bearer ")
+		if token == auth {
+			writeErrorResponse(ErrInvalidAPIToken, w)
+			return
+		}
+		if admin, ok := findAdminToken(ee.AdminTokens, token); ok {
+			next.ServeHTTP(w, r.WithContext(contextWithAdmin(r.Context(), admin.Name)))
+			return
+		}
+		apiToken, err := ee.Exec.CheckAPIToken(token)
+		if err != nil {
+			writeErrorResponse(err, w)
+			return
+		}
+		if (r.Method != http.MethodGet && r.Method != http.MethodHead) || adminRoutes[route] || reviewRoutes[route] {
+			stats.Incr("api-token-forbidden", stats.T("token", apiToken.Name))
+			http.Error(w, "API tokens are read-only", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func (ee *ExecutiveEndpoint) handleCookieRoute(w http.ResponseWriter, r *http.Request) {
 	hdrWriter := r.Header.Get("ctlstore-writer")
 	hdrSecret := r.Header.Get("ctlstore-secret")
@@ -159,23 +478,215 @@ func (ee *ExecutiveEndpoint) handleCookieRoute(w http.ResponseWriter, r *http.Re
 	w.WriteHeader(http.StatusMethodNotAllowed)
 }
 
+// handleCookieCompareAndSwap sets the writer's cookie to the new cookie if
+// it's currently the expected one. If it isn't, the cookie is left alone
+// and the current cookie is returned with 409 Conflict. Cookies are base64
+// encoded, as they are in mutations.
+func (ee *ExecutiveEndpoint) handleCookieCompareAndSwap(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		var payload struct {
+			Expected []byte `json:"expected"`
+			Cookie   []byte `json:"cookie"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			return &errs.BadRequestError{Err: "JSON Error: " + err.Error()}
+		}
+		res, err := ee.Exec.CompareAndSwapWriterCookie(
+			r.Header.Get("ctlstore-writer"),
+			r.Header.Get("ctlstore-secret"),
+			payload.Expected,
+			payload.Cookie)
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if !res.Swapped {
+			w.WriteHeader(http.StatusConflict)
+		}
+		return json.NewEncoder(w).Encode(res)
+	})
+}
+
+// handleFamilySchemasRoute returns the schemas of a family's tables. Large
+// families can be paged through by passing a limit, in which case the name
+// of the last table is returned in the X-Ctlstore-Next-After header if there
+// are more tables, to be passed as the after parameter of the next request.
 func (ee *ExecutiveEndpoint) handleFamilySchemasRoute(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	familyName := vars["familyName"]
-	schemas, err := ee.Exec.FamilySchemas(familyName)
-	switch {
-	case err == nil:
-	default:
-		writeErrorResponse(err, w)
-		return
+	handlingErrorDo(w, func() error {
+		familyName := mux.Vars(r)["familyName"]
+		var limit int
+		if raw := r.URL.Query().Get("limit"); raw != "" {
+			var err error
+			limit, err = strconv.Atoi(raw)
+			if err != nil || limit < 1 {
+				return errs.BadRequest("Invalid limit: '%s'", raw)
+			}
+		}
+
+		var schemas []schema.Table
+		if limit == 0 {
+			var err error
+			schemas, err = ee.Exec.FamilySchemas(familyName)
+			if err != nil {
+				return err
+			}
+		} else {
+			names, err := ee.Exec.FamilyTables(familyName)
+			if err != nil {
+				return err
+			}
+			after := r.URL.Query().Get("after")
+			start := sort.SearchStrings(names, after)
+			if start < len(names) && names[start] == after {
+				start++
+			}
+			end := start + limit
+			if end < len(names) {
+				w.Header().Set("X-Ctlstore-Next-After", names[end-1])
+			} else {
+				end = len(names)
+			}
+			for _, name := range names[start:end] {
+				tbl, err := ee.Exec.TableSchema(familyName, name)
+				if err != nil {
+					return err
+				}
+				schemas = append(schemas, *tbl)
+			}
+		}
+
+		return writeJSONArray(w, r, len(schemas), func(i int) interface{} { return schemas[i] })
+	})
+}
+
+// handleFamilyTablesRoute returns just the names of a family's tables.
+func (ee *ExecutiveEndpoint) handleFamilyTablesRoute(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		names, err := ee.Exec.FamilyTables(mux.Vars(r)["familyName"])
+		if err != nil {
+			return err
+		}
+		return writeJSONArray(w, r, len(names), func(i int) interface{} { return names[i] })
+	})
+}
+
+// writeJSONArray encodes the array one element at a time rather than
+// marshaling it all at once, and gzips it if the client accepts that.
+func writeJSONArray(w http.ResponseWriter, r *http.Request, n int, elem func(i int) interface{}) error {
+	aw := &jsonArrayWriter{w: w, r: r}
+	for i := 0; i < n; i++ {
+		if err := aw.Write(elem(i)); err != nil {
+			return err
+		}
 	}
-	bs, err := json.Marshal(schemas)
+	return aw.Close()
+}
+
+// jsonArrayWriter streams a JSON array to a response. Nothing is written
+// until the first element or Close, so an error response can still be
+// written up until then.
+type jsonArrayWriter struct {
+	w   http.ResponseWriter
+	r   *http.Request
+	out io.Writer
+	gz  *gzip.Writer
+	n   int
+}
+
+func (aw *jsonArrayWriter) start() error {
+	aw.w.Header().Set("Content-Type", "application/json")
+	aw.out = aw.w
+	if strings.Contains(aw.r.Header.Get("Accept-Encoding"), "gzip") {
+		aw.w.Header().Set("Content-Encoding", "gzip")
+		aw.gz = gzip.NewWriter(aw.w)
+		aw.out = aw.gz
+	}
+	_, err := io.WriteString(aw.out, "[")
+	return err
+}
+
+func (aw *jsonArrayWriter) Write(v interface{}) error {
+	b, err := json.Marshal(v)
 	if err != nil {
-		writeErrorResponse(err, w)
-		return
+		return err
 	}
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(bs)
+	if aw.out == nil {
+		if err := aw.start(); err != nil {
+			return err
+		}
+	}
+	if aw.n > 0 {
+		if _, err := io.WriteString(aw.out, ","); err != nil {
+			return err
+		}
+	}
+	aw.n++
+	_, err = aw.out.Write(b)
+	return err
+}
+
+func (aw *jsonArrayWriter) Close() error {
+	if aw.out == nil {
+		if err := aw.start(); err != nil {
+			return err
+		}
+	}
+	if _, err := io.WriteString(aw.out, "]"); err != nil {
+		return err
+	}
+	if aw.gz != nil {
+		return aw.gz.Close()
+	}
+	return nil
+}
+
+// handleTableRowsRead streams a page of a table's rows, ordered by primary
+// key. Pages are selected with limit and either offset or after, which is
+// the JSON encoded primary key of the last row of the previous page. A page
+// with fewer than limit rows is the last one.
+func (ee *ExecutiveEndpoint) handleTableRowsRead(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		vars := mux.Vars(r)
+		params := r.URL.Query()
+		query := RowsQuery{Limit: defaultRowsLimit}
+		for name, dst := range map[string]*int{"limit": &query.Limit, "offset": &query.Offset} {
+			raw := params.Get(name)
+			if raw == "" {
+				continue
+			}
+			v, err := strconv.Atoi(raw)
+			if err != nil || v < 0 {
+				return errs.BadRequest("Invalid %s: '%s'", name, raw)
+			}
+			*dst = v
+		}
+		if query.Limit < 1 || query.Limit > maxRowsLimit {
+			return errs.BadRequest("limit must be between 1 and %d", maxRowsLimit)
+		}
+		if raw := params.Get("after"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &query.After); err != nil {
+				return errs.BadRequest("after must be a JSON array of key values: %s", err)
+			}
+			if query.Offset > 0 {
+				return errs.BadRequest("offset and after cannot be combined")
+			}
+		}
+
+		aw := &jsonArrayWriter{w: w, r: r}
+		err := ee.Exec.ReadRows(vars["familyName"], vars["tableName"], query, func(row map[string]interface{}) error {
+			return aw.Write(row)
+		})
+		if err != nil {
+			if aw.out != nil {
+				// too late for an error response, so leave the array
+				// unterminated so the client can't mistake it for a page
+				events.Log("Failed streaming rows of %{family}s.%{table}s: %{error}+v", vars["familyName"], vars["tableName"], err)
+				return nil
+			}
+			return err
+		}
+		return aw.Close()
+	})
 }
 
 func (ee *ExecutiveEndpoint) handleTableSchemaRoute(w http.ResponseWriter, r *http.Request) {
@@ -213,6 +724,19 @@ func (ee *ExecutiveEndpoint) handleWritersRoute(w http.ResponseWriter, r *http.R
 		}
 
 		secret := string(rawBody)
+		if ee.RequireWriterApproval {
+			// writers re-register at startup, which is a no-op for a
+			// writer that's already registered with the secret
+			registered, err := ee.Exec.CheckWriterSecret(vars["writerName"], secret)
+			if err != nil {
+				writeErrorResponse(err, w)
+				return
+			}
+			if !registered {
+				writeErrorResponse(ErrWriterApprovalRequired, w)
+				return
+			}
+		}
 		err = ee.Exec.RegisterWriter(vars["writerName"], secret)
 		if err != nil {
 			writeErrorResponse(err, w)
@@ -226,6 +750,283 @@ func (ee *ExecutiveEndpoint) handleWritersRoute(w http.ResponseWriter, r *http.R
 	w.WriteHeader(http.StatusMethodNotAllowed)
 }
 
+func (ee *ExecutiveEndpoint) handleWritersRead(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		writers, err := ee.Exec.ReadWriters()
+		if err != nil {
+			return err
+		}
+		b, err := json.Marshal(writers)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	})
+}
+
+func (ee *ExecutiveEndpoint) handleWriterActivityRead(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		activity, err := ee.Exec.ReadWriterActivity(mux.Vars(r)["writerName"])
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(w).Encode(activity)
+	})
+}
+
+func (ee *ExecutiveEndpoint) handleWriterEnable(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		return ee.Exec.EnableWriter(mux.Vars(r)["writerName"])
+	})
+}
+
+// handleWriterRegistrationRequest requests the registration of a writer,
+// which an admin then approves or rejects.
+func (ee *ExecutiveEndpoint) handleWriterRegistrationRequest(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		var body struct {
+			Secret      string `json:"secret"`
+			Description string `json:"description"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			return errs.BadRequest("Invalid request body: %s", err)
+		}
+		reg, err := ee.Exec.RequestWriterRegistration(mux.Vars(r)["writerName"], body.Secret, body.Description)
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(w).Encode(reg)
+	})
+}
+
+func (ee *ExecutiveEndpoint) handleWriterRegistrationsRead(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		regs, err := ee.Exec.ReadWriterRegistrations(r.URL.Query().Get("status"))
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(w).Encode(regs)
+	})
+}
+
+func (ee *ExecutiveEndpoint) handleWriterRegistrationApprove(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		review, err := decodeWriterReview(r)
+		if err != nil {
+			return err
+		}
+		return ee.Exec.ApproveWriterRegistration(mux.Vars(r)["writerName"], review)
+	})
+}
+
+func (ee *ExecutiveEndpoint) handleWriterRegistrationReject(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		review, err := decodeWriterReview(r)
+		if err != nil {
+			return err
+		}
+		return ee.Exec.RejectWriterRegistration(mux.Vars(r)["writerName"], review)
+	})
+}
+
+// decodeWriterReview reads the optional review of a writer registration from
+// the request body. The reviewer is the admin whose token authorized the
+// request, which authorizeTokens requires for reviews.
+func decodeWriterReview(r *http.Request) (WriterReview, error) {
+	var review WriterReview
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil && err != io.EOF {
+		return review, errs.BadRequest("Invalid request body: %s", err)
+	}
+	admin, ok := adminFromContext(r.Context())
+	if !ok {
+		return review, ErrAdminRequired
+	}
+	review.Reviewer = admin
+	return review, nil
+}
+
+func (ee *ExecutiveEndpoint) handleWriterRegistrationAuditRead(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		audit, err := ee.Exec.ReadWriterRegistrationAudit(mux.Vars(r)["writerName"])
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(w).Encode(audit)
+	})
+}
+
+// handleLedgerRead returns the DML ledger entries from from_seq up to and
+// including to_seq, or the end of the ledger if to_seq is not set, for
+// debugging without direct access to the ctldb.
+func (ee *ExecutiveEndpoint) handleLedgerRead(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		params := r.URL.Query()
+		query := LedgerQuery{Limit: defaultRowsLimit}
+		for name, dst := range map[string]*int64{"from_seq": &query.FromSeq, "to_seq": &query.ToSeq} {
+			raw := params.Get(name)
+			if raw == "" {
+				continue
+			}
+			v, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil || v < 0 {
+				return errs.BadRequest("Invalid %s: '%s'", name, raw)
+			}
+			*dst = v
+		}
+		if raw := params.Get("limit"); raw != "" {
+			limit, err := strconv.Atoi(raw)
+			if err != nil {
+				return errs.BadRequest("Invalid limit: '%s'", raw)
+			}
+			query.Limit = limit
+		}
+		if query.Limit < 1 || query.Limit > maxRowsLimit {
+			return errs.BadRequest("limit must be between 1 and %d", maxRowsLimit)
+		}
+		if query.ToSeq != 0 && query.ToSeq < query.FromSeq {
+			return errs.BadRequest("to_seq must not be less than from_seq")
+		}
+
+		entries, err := ee.Exec.ReadLedger(query)
+		if err != nil {
+			return err
+		}
+		return writeJSONArray(w, r, len(entries), func(i int) interface{} { return entries[i] })
+	})
+}
+
+// handleExportStart starts a background job which exports a table, and
+// returns it so that its status can be polled.
+func (ee *ExecutiveEndpoint) handleExportStart(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		vars := mux.Vars(r)
+		var req ExportRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return &errs.BadRequestError{Err: "JSON Error: " + err.Error()}
+		}
+		job, err := ee.Exec.StartExport(vars["familyName"], vars["tableName"], req)
+		if err != nil {
+			return err
+		}
+		b, err := json.Marshal(job)
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_, err = w.Write(b)
+		return err
+	})
+}
+
+// handleTableClone creates the table named by the target-table parameter
+// with the same schema as the table in the path, in the family named by the
+// target-family parameter, which defaults to the table's own family. With
+// copy-rows, up to that many of the table's rows are copied into it, which
+// count against the rate limit of the writer named by the writer parameter.
+func (ee *ExecutiveEndpoint) handleTableClone(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		vars := mux.Vars(r)
+		query := r.URL.Query()
+		source := schema.FamilyTable{Family: vars["familyName"], Table: vars["tableName"]}
+		target := schema.FamilyTable{Family: query.Get("target-family"), Table: query.Get("target-table")}
+		if target.Family == "" {
+			target.Family = source.Family
+		}
+		if target.Table == "" {
+			return errs.BadRequest("target-table is required")
+		}
+		var copyRows int
+		if raw := query.Get("copy-rows"); raw != "" {
+			var err error
+			copyRows, err = strconv.Atoi(raw)
+			if err != nil || copyRows < 0 {
+				return errs.BadRequest("Invalid copy-rows: '%s'", raw)
+			}
+		}
+		res, err := ee.Exec.CloneTable(source, target, copyRows, query.Get("writer"))
+		if err != nil {
+			return err
+		}
+		b, err := json.Marshal(res)
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, err = w.Write(b)
+		return err
+	})
+}
+
+func (ee *ExecutiveEndpoint) handleExportJobRead(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		job, err := ee.Exec.ReadExportJob(mux.Vars(r)["jobID"])
+		if err != nil {
+			return err
+		}
+		b, err := json.Marshal(job)
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, err = w.Write(b)
+		return err
+	})
+}
+
+// handleAnalyzeTables refreshes the index statistics of a table, or of
+// every table over the analyzer's size threshold, and returns the outcome
+// for each table once they've all been analyzed.
+func (ee *ExecutiveEndpoint) handleAnalyzeTables(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		vars := mux.Vars(r)
+		var tables []schema.FamilyTable
+		if vars["familyName"] != "" {
+			tables = append(tables, schema.FamilyTable{Family: vars["familyName"], Table: vars["tableName"]})
+		}
+		res, err := ee.Exec.AnalyzeTables(tables)
+		if err != nil {
+			return err
+		}
+		return writeJSONArray(w, r, len(res), func(i int) interface{} { return res[i] })
+	})
+}
+
+func (ee *ExecutiveEndpoint) handleMaintenanceRead(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		m, err := ee.Exec.ReadMaintenance()
+		if err != nil {
+			return err
+		}
+		b, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, err = w.Write(b)
+		return err
+	})
+}
+
+// handleMaintenanceUpdate switches maintenance mode on or off, during which
+// mutations are rejected with a 503.
+func (ee *ExecutiveEndpoint) handleMaintenanceUpdate(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		payload := struct {
+			Enabled *bool  `json:"enabled"`
+			Reason  string `json:"reason"`
+		}{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			return &errs.BadRequestError{Err: "JSON Error: " + err.Error()}
+		}
+		if payload.Enabled == nil {
+			return errs.BadRequest("enabled must be set")
+		}
+		return ee.Exec.SetMaintenance(Maintenance{Enabled: *payload.Enabled, Reason: payload.Reason})
+	})
+}
+
 func (ee *ExecutiveEndpoint) handleMutationsRoute(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	familyName := vars["familyName"]
@@ -248,6 +1049,7 @@ func (ee *ExecutiveEndpoint) handleMutationsRoute(w http.ResponseWriter, r *http
 				TableName string                 `json:"table"`
 				Delete    bool                   `json:"delete"`
 				Values    map[string]interface{} `json:"values"`
+				IfValues  map[string]interface{} `json:"ifValues"`
 			} `json:"mutations"`
 		}{}
 
@@ -274,29 +1076,92 @@ func (ee *ExecutiveEndpoint) handleMutationsRoute(w http.ResponseWriter, r *http
 				TableName: req.TableName,
 				Delete:    req.Delete,
 				Values:    req.Values,
+				IfValues:  req.IfValues,
 			})
 			totalValues += len(req.Values)
 		}
 
 		stats.Add("mutation-values-received", totalValues, stats.T("writer", hdrWriter))
 
-		err = ee.Exec.Mutate(
+		res, err := ee.Exec.Mutate(
 			hdrWriter,
 			hdrSecret,
 			familyName,
 			payload.Cookie,
 			payload.CheckCookie,
 			unpackedReqs)
-
-		_ = err
-
 		if err != nil {
 			writeErrorResponse(err, w)
 			return
 		}
+
+		if err := writeMutationResult(w, res); err != nil {
+			writeErrorResponse(err, w)
+		}
 	}
 }
 
+// handleFamiliesMutationsRoute applies mutations to tables in any number of
+// families in a single transaction.
+func (ee *ExecutiveEndpoint) handleFamiliesMutationsRoute(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		hdrWriter := r.Header.Get("ctlstore-writer")
+		hdrSecret := r.Header.Get("ctlstore-secret")
+
+		payload := struct {
+			Cookie      []byte `json:"cookie"`
+			CheckCookie []byte `json:"check_cookie"`
+			Requests    []struct {
+				FamilyName string                 `json:"family"`
+				TableName  string                 `json:"table"`
+				Delete     bool                   `json:"delete"`
+				Values     map[string]interface{} `json:"values"`
+				IfValues   map[string]interface{} `json:"ifValues"`
+			} `json:"mutations"`
+		}{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			return &errs.BadRequestError{Err: "JSON Error: " + err.Error()}
+		}
+
+		totalValues := 0
+		unpackedReqs := []ExecutiveMutationRequest{}
+		for i, req := range payload.Requests {
+			if req.FamilyName == "" {
+				return errs.BadRequest("Mutation %d has no family", i)
+			}
+			unpackedReqs = append(unpackedReqs, ExecutiveMutationRequest{
+				FamilyName: req.FamilyName,
+				TableName:  req.TableName,
+				Delete:     req.Delete,
+				Values:     req.Values,
+				IfValues:   req.IfValues,
+			})
+			totalValues += len(req.Values)
+		}
+
+		stats.Add("mutation-values-received", totalValues, stats.T("writer", hdrWriter))
+
+		res, err := ee.Exec.MutateFamilies(
+			hdrWriter,
+			hdrSecret,
+			payload.Cookie,
+			payload.CheckCookie,
+			unpackedReqs)
+		if err != nil {
+			return err
+		}
+		return writeMutationResult(w, res)
+	})
+}
+
+// writeMutationResult writes the body of a successful mutations response,
+// which tells writers which of their conditional mutations were skipped,
+// and how much of their rate limit is left.
+func writeMutationResult(w http.ResponseWriter, res MutationResult) error {
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(res)
+}
+
 func (ee *ExecutiveEndpoint) handleSleepRoute(w http.ResponseWriter, r *http.Request) {
 	body := "slept"
 
@@ -343,31 +1208,78 @@ func (ee *ExecutiveEndpoint) Handler() http.Handler {
 		})
 	})
 
+	r.Use(ee.authorizeTokens)
+
+	r.Use(ee.reportLedgerSeq)
+
 	r.HandleFunc("/cookie", ee.handleCookieRoute).Methods("GET", "POST")
+	r.HandleFunc("/cookie/compare-and-swap", ee.handleCookieCompareAndSwap).Methods("POST")
 	r.HandleFunc("/families/{familyName}", ee.handleFamilyRoute).Methods("POST")
 	r.HandleFunc("/families/{familyName}/tables/{tableName}", ee.handleTableRoute).Methods("POST", "PUT")
+	r.HandleFunc("/families/{familyName}/tables/{tableName}/rows", ee.handleTableRowsRead).Methods("GET")
+	r.HandleFunc("/families/{familyName}/tables/{tableName}/clone", ee.handleTableClone).Methods("POST")
+	r.HandleFunc("/families/{familyName}/tables/{tableName}/references", ee.handleTableReferencesUpdate).Methods("PUT")
+	r.HandleFunc("/families/{familyName}/tables/{tableName}/columns/{columnName}", ee.handleColumnRoute).Methods("PATCH")
+	r.HandleFunc("/families/{familyName}/defaults", ee.handleFamilyDefaultsRead).Methods("GET")
+	r.HandleFunc("/families/{familyName}/defaults", ee.handleFamilyDefaultsSave).Methods("POST")
+	r.HandleFunc("/families/{familyName}/templates", ee.handleTemplatesRead).Methods("GET")
+	r.HandleFunc("/families/{familyName}/templates/{templateName}", ee.handleTemplateSave).Methods("POST")
+	r.HandleFunc("/families/{familyName}/templates/{templateName}", ee.handleTemplateDelete).Methods("DELETE")
+	r.HandleFunc("/families/{familyName}/webhooks", ee.handleWebhooksRead).Methods("GET")
+	r.HandleFunc("/families/{familyName}/webhooks", ee.handleWebhookRegister).Methods("POST")
+	r.HandleFunc("/families/{familyName}/webhooks/{webhookID}", ee.handleWebhookDelete).Methods("DELETE")
 	r.HandleFunc("/families/{familyName}/mutations", ee.handleMutationsRoute).Methods("POST")
+	r.HandleFunc("/mutations", ee.handleFamiliesMutationsRoute).Methods("POST")
 	r.HandleFunc("/tables", ee.handleTablesRoute).Methods("POST")
 	r.HandleFunc("/sleep", ee.handleSleepRoute).Methods("GET")
 	r.HandleFunc("/status", ee.handleStatusRoute).Methods("GET")
+	r.HandleFunc("/writers", ee.handleWritersRead).Methods("GET")
+	r.HandleFunc("/ledger", ee.handleLedgerRead).Methods("GET")
+	r.HandleFunc("/families/{familyName}/tables/{tableName}/export", ee.handleExportStart).Methods("POST")
+	r.HandleFunc("/export-jobs/{jobID}", ee.handleExportJobRead).Methods("GET")
+	r.HandleFunc("/analyze", ee.handleAnalyzeTables).Methods("POST")
+	r.HandleFunc("/families/{familyName}/tables/{tableName}/analyze", ee.handleAnalyzeTables).Methods("POST")
 	r.HandleFunc("/writers/{writerName}", ee.handleWritersRoute).Methods("POST")
+	r.HandleFunc("/writers/{writerName}/activity", ee.handleWriterActivityRead).Methods("GET")
+	r.HandleFunc("/writers/{writerName}/enable", ee.handleWriterEnable).Methods("POST")
+	r.HandleFunc("/writers/{writerName}/request", ee.handleWriterRegistrationRequest).Methods("POST")
+	r.HandleFunc("/writer-registrations", ee.handleWriterRegistrationsRead).Methods("GET")
+	r.HandleFunc("/writer-registrations/{writerName}/approve", ee.handleWriterRegistrationApprove).Methods("POST")
+	r.HandleFunc("/writer-registrations/{writerName}/reject", ee.handleWriterRegistrationReject).Methods("POST")
+	r.HandleFunc("/writer-registrations/{writerName}/audit", ee.handleWriterRegistrationAuditRead).Methods("GET")
+	r.HandleFunc("/maintenance", ee.handleMaintenanceRead).Methods("GET")
+	r.HandleFunc("/maintenance", ee.handleMaintenanceUpdate).Methods("POST")
+	r.HandleFunc("/tokens", ee.handleAPITokensRead).Methods("GET")
+	r.HandleFunc("/tokens", ee.handleAPITokenCreate).Methods("POST")
+	r.HandleFunc("/tokens/{tokenName}", ee.handleAPITokenDelete).Methods("DELETE")
 
 	r.HandleFunc("/schema/table/{familyName}/{tableName}", ee.handleTableSchemaRoute).Methods(http.MethodGet)
 	r.HandleFunc("/schema/family/{familyName}", ee.handleFamilySchemasRoute).Methods(http.MethodGet)
+	r.HandleFunc("/schema/family/{familyName}/tables", ee.handleFamilyTablesRoute).Methods(http.MethodGet)
+	r.HandleFunc("/schema/apply", ee.handleSchemaApply).Methods(http.MethodPost)
 
 	r.HandleFunc("/limits/tables", ee.handleTableLimitsRead).Methods("GET")
 	r.HandleFunc("/limits/tables/{familyName}/{tableName}", ee.handleTableLimitsUpdate).Methods("POST")
 	r.HandleFunc("/limits/tables/{familyName}/{tableName}", ee.handleTableLimitsDelete).Methods("DELETE")
+	r.HandleFunc("/limits/tables/{familyName}/{tableName}/projection", ee.handleTableSizeProjectionRead).Methods("GET")
 
 	r.HandleFunc("/limits/writers", ee.handleWriterLimitsRead).Methods("GET")
 	r.HandleFunc("/limits/writers/{writerName}", ee.handleWriterLimitsUpdate).Methods("POST")
 	r.HandleFunc("/limits/writers/{writerName}", ee.handleWriterLimitsDelete).Methods("DELETE")
 
+	r.HandleFunc("/writer-groups", ee.handleWriterGroupsRead).Methods("GET")
+	r.HandleFunc("/writer-groups/{groupName}", ee.handleWriterGroupUpdate).Methods("POST")
+	r.HandleFunc("/writer-groups/{groupName}", ee.handleWriterGroupDelete).Methods("DELETE")
+	r.HandleFunc("/writer-groups/{groupName}/members/{writerName}", ee.handleWriterGroupMemberAdd).Methods("POST")
+	r.HandleFunc("/writer-groups/{groupName}/members/{writerName}", ee.handleWriterGroupMemberRemove).Methods("DELETE")
+	r.HandleFunc("/writer-groups/{groupName}/audit", ee.handleWriterGroupAuditRead).Methods("GET")
+
 	// destructive routes below
 
 	r.HandleFunc("/clear-rows/families/{familyName}", ee.handleClearFamilyRows).Methods("DELETE")
 	r.HandleFunc("/clear-rows/families/{familyName}/tables/{tableName}", ee.handleClearTableRows).Methods("DELETE")
 	r.HandleFunc("/families/{familyName}/tables/{tableName}", ee.handleDropTable).Methods("DELETE")
+	r.HandleFunc("/families/{familyName}", ee.handleDeleteFamily).Methods("DELETE")
 
 	// Limit request body sizes
 	r.Use(func(next http.Handler) http.Handler {
@@ -399,6 +1311,22 @@ func (ee *ExecutiveEndpoint) handleTableLimitsRead(w http.ResponseWriter, r *htt
 	})
 }
 
+func (ee *ExecutiveEndpoint) handleTableSizeProjectionRead(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		vars := mux.Vars(r)
+		familyName, tableName, err := sanitizeFamilyAndTableNames(vars["familyName"], vars["tableName"])
+		if err != nil {
+			return &errs.BadRequestError{Err: err.Error()}
+		}
+		projection, err := ee.Exec.ReadTableSizeProjection(schema.FamilyTable{Family: familyName, Table: tableName})
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", "application/json")
+		return json.NewEncoder(w).Encode(projection)
+	})
+}
+
 func (ee *ExecutiveEndpoint) handleTableLimitsUpdate(w http.ResponseWriter, r *http.Request) {
 	handlingErrorDo(w, func() error {
 		vars := mux.Vars(r)
@@ -473,6 +1401,67 @@ func (ee *ExecutiveEndpoint) handleWriterLimitsDelete(w http.ResponseWriter, r *
 	})
 }
 
+func (ee *ExecutiveEndpoint) handleWriterGroupsRead(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		groups, err := ee.Exec.ReadWriterGroups()
+		if err != nil {
+			return err
+		}
+		b, err := json.Marshal(groups)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	})
+}
+
+func (ee *ExecutiveEndpoint) handleWriterGroupUpdate(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		var limit limits.RateLimit
+		if err := json.NewDecoder(r.Body).Decode(&limit); err != nil {
+			return &errs.BadRequestError{Err: err.Error()}
+		}
+		return ee.Exec.UpdateWriterGroup(mux.Vars(r)["groupName"], limit)
+	})
+}
+
+func (ee *ExecutiveEndpoint) handleWriterGroupDelete(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		return ee.Exec.DeleteWriterGroup(mux.Vars(r)["groupName"])
+	})
+}
+
+// handleWriterGroupMemberAdd takes an optional body with the secret to
+// register the writer with, like the one of POST /writers/{writerName}.
+func (ee *ExecutiveEndpoint) handleWriterGroupMemberAdd(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		vars := mux.Vars(r)
+		secret, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		return ee.Exec.AddWriterGroupMember(vars["groupName"], vars["writerName"], string(secret))
+	})
+}
+
+func (ee *ExecutiveEndpoint) handleWriterGroupMemberRemove(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		vars := mux.Vars(r)
+		return ee.Exec.RemoveWriterGroupMember(vars["groupName"], vars["writerName"])
+	})
+}
+
+func (ee *ExecutiveEndpoint) handleWriterGroupAuditRead(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		audit, err := ee.Exec.ReadWriterGroupAudit(mux.Vars(r)["groupName"])
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(w).Encode(audit)
+	})
+}
+
 func handlingErrorDo(w http.ResponseWriter, fn func() error) {
 	if err := fn(); err != nil {
 		writeErrorResponse(err, w)
@@ -505,6 +1494,26 @@ func (ee *ExecutiveEndpoint) handleDropTable(w http.ResponseWriter, r *http.Requ
 	return
 }
 
+// handleDeleteFamily drops all of a family's tables and removes the family.
+// With ?tombstones=true, the rows of each table are deleted before it's
+// dropped, so that changelog consumers see their deletion.
+func (ee *ExecutiveEndpoint) handleDeleteFamily(w http.ResponseWriter, r *http.Request) {
+	handlingErrorDo(w, func() error {
+		if !ee.EnableDestructiveSchemaChanges {
+			return &errs.BadRequestError{Err: "Deleting families is not enabled."}
+		}
+		var tombstones bool
+		if raw := r.URL.Query().Get("tombstones"); raw != "" {
+			var err error
+			tombstones, err = strconv.ParseBool(raw)
+			if err != nil {
+				return errs.BadRequest("tombstones must be a boolean")
+			}
+		}
+		return ee.Exec.DeleteFamily(mux.Vars(r)["familyName"], tombstones)
+	})
+}
+
 func (ee *ExecutiveEndpoint) handleClearTableRows(w http.ResponseWriter, r *http.Request) {
 	if !ee.EnableDestructiveSchemaChanges {
 		writeErrorResponse(&errs.BadRequestError{Err: "Clearing tables is not enabled."}, w)
@@ -574,13 +1583,18 @@ func writeErrorResponse(e error, w http.ResponseWriter) {
 	resBody := e.Error()
 
 	cause := errors.Cause(e)
+	var limit *errs.LimitDetails
 	// first check for generic error values
 	switch cause {
 	case ErrWriterAlreadyExists:
 		status = http.StatusConflict
+	case ErrWriterDisabled, ErrWriterApprovalRequired:
+		status = http.StatusForbidden
+	case ErrInvalidAPIToken, ErrAuthenticationRequired, ErrAdminRequired:
+		status = http.StatusUnauthorized
 	default:
 		// if no generic error values matched, check the error types as well
-		switch cause.(type) {
+		switch cause := cause.(type) {
 		case *errs.ConflictError:
 			status = http.StatusConflict
 		case *errs.BadRequestError:
@@ -589,18 +1603,56 @@ func writeErrorResponse(e error, w http.ResponseWriter) {
 			status = http.StatusNotFound
 		case *errs.RateLimitExceededErr:
 			status = http.StatusTooManyRequests
+			limit = cause.Details
 		case *errs.InsufficientStorageErr:
 			status = http.StatusInsufficientStorage
+			limit = cause.Details
+		case *errs.ServiceUnavailableErr:
+			status = http.StatusServiceUnavailable
+			if cause.RetryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(cause.RetryAfter.Seconds())), 10))
+			}
 		default:
 			status = http.StatusInternalServerError
 		}
 
 	}
-	w.WriteHeader(status)
-	_, _ = w.Write([]byte(resBody))
+	if limit != nil {
+		writeLimitResponse(w, status, resBody, limit)
+	} else {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(resBody))
+	}
 
 	events.Log("Error Status %{status}v, Reason: %{reason}v, Internal Error: %{error}+v",
 		status, resBody, e.Error())
 
 	return
 }
+
+// limitErrorResponse is the body of responses to requests rejected for
+// exceeding a limit.
+type limitErrorResponse struct {
+	Error string `json:"error"`
+	errs.LimitDetails
+}
+
+// writeLimitResponse describes the limit a request exceeded in both headers
+// and a JSON body, so that writers can decide whether to back off or give
+// up without parsing the message.
+func writeLimitResponse(w http.ResponseWriter, status int, msg string, limit *errs.LimitDetails) {
+	h := w.Header()
+	h.Set("Content-Type", "application/json")
+	h.Set("X-Ctlstore-Limit", strconv.FormatInt(limit.Limit, 10))
+	h.Set("X-Ctlstore-Limit-Current", strconv.FormatInt(limit.Current, 10))
+	if limit.ResetAt != nil {
+		h.Set("X-Ctlstore-Limit-Reset", strconv.FormatInt(limit.ResetAt.Unix(), 10))
+		retryAfter := int64(math.Ceil(time.Until(*limit.ResetAt).Seconds()))
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+		h.Set("Retry-After", strconv.FormatInt(retryAfter, 10))
+	}
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(limitErrorResponse{Error: msg, LimitDetails: *limit})
+}
@@ -2,7 +2,9 @@ package executive_test
 
 import (
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
@@ -28,6 +30,7 @@ type testExecEndpointHandlerAtom struct {
 	ExpectedStatusCode int
 	JSONBody           interface{}
 	RawBody            []byte
+	Headers            map[string]string
 	PreFunc            func(t *testing.T, atom *testExecEndpointHandlerAtom)
 	PostFunc           func(t *testing.T, atom *testExecEndpointHandlerAtom)
 
@@ -203,6 +206,68 @@ func TestExecEndpointHandler(_t *testing.T) {
 				require.EqualValues(t, "Writer names must be at least 3 characters", atom.rr.Body.String())
 			},
 		},
+		{
+			Desc:   "Update Writer Group Success",
+			Path:   "/writer-groups/mygroup",
+			Method: http.MethodPost,
+			JSONBody: map[string]interface{}{
+				"amount": 1000,
+				"period": "1m",
+			},
+			ExpectedStatusCode: http.StatusOK,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.EqualValues(t, 1, atom.ei.UpdateWriterGroupCallCount())
+				groupName, limit := atom.ei.UpdateWriterGroupArgsForCall(0)
+				require.Equal(t, "mygroup", groupName)
+				require.Equal(t, limits.RateLimit{Amount: 1000, Period: time.Minute}, limit)
+			},
+		},
+		{
+			Desc:               "Add Writer Group Member With Secret",
+			Path:               "/writer-groups/mygroup/members/mywriter",
+			Method:             http.MethodPost,
+			RawBody:            []byte("mysecret"),
+			ExpectedStatusCode: http.StatusOK,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.EqualValues(t, 1, atom.ei.AddWriterGroupMemberCallCount())
+				groupName, writerName, secret := atom.ei.AddWriterGroupMemberArgsForCall(0)
+				require.Equal(t, "mygroup", groupName)
+				require.Equal(t, "mywriter", writerName)
+				require.Equal(t, "mysecret", secret)
+			},
+		},
+		{
+			Desc:               "Remove Writer Group Member Not Found",
+			Path:               "/writer-groups/mygroup/members/mywriter",
+			Method:             http.MethodDelete,
+			ExpectedStatusCode: http.StatusNotFound,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.RemoveWriterGroupMemberReturns(&errs.NotFoundError{Err: "not in the group"})
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.EqualValues(t, 1, atom.ei.RemoveWriterGroupMemberCallCount())
+			},
+		},
+		{
+			Desc:               "Read Writer Group Audit Success",
+			Path:               "/writer-groups/mygroup/audit",
+			Method:             http.MethodGet,
+			ExpectedStatusCode: http.StatusOK,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.ReadWriterGroupAuditReturns([]executive.WriterGroupEvent{
+					{Group: "mygroup", Writer: "mywriter", Action: executive.WriterGroupMemberAdded, SourceIP: "10.0.0.1", At: time.Unix(100, 0).UTC()},
+				}, nil)
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.EqualValues(t, 1, atom.ei.ReadWriterGroupAuditCallCount())
+				require.Equal(t, "mygroup", atom.ei.ReadWriterGroupAuditArgsForCall(0))
+				var audit []executive.WriterGroupEvent
+				require.NoError(t, json.NewDecoder(atom.rr.Body).Decode(&audit))
+				require.Equal(t, []executive.WriterGroupEvent{
+					{Group: "mygroup", Writer: "mywriter", Action: executive.WriterGroupMemberAdded, SourceIP: "10.0.0.1", At: time.Unix(100, 0).UTC()},
+				}, audit)
+			},
+		},
 		{
 			Desc:               "Read Table Limits Success",
 			Path:               "/limits/tables",
@@ -382,6 +447,39 @@ func TestExecEndpointHandler(_t *testing.T) {
 					atom.rr.Body.String())
 			},
 		},
+		{
+			Desc:               "Read Table Size Projection Success",
+			Path:               "/limits/tables/myfamily/mytable/projection",
+			Method:             http.MethodGet,
+			ExpectedStatusCode: http.StatusOK,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				days := 7.0
+				atom.ei.ReadTableSizeProjectionReturns(limits.TableSizeProjection{
+					Family:         "myfamily",
+					Table:          "mytable",
+					Size:           300,
+					MaxSize:        1000,
+					Samples:        3,
+					GrowthPerDay:   100,
+					DaysUntilLimit: &days,
+				}, nil)
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.EqualValues(t, 1, atom.ei.ReadTableSizeProjectionCallCount())
+				require.Equal(t, schema.FamilyTable{Family: "myfamily", Table: "mytable"}, atom.ei.ReadTableSizeProjectionArgsForCall(0))
+				require.JSONEq(t, `{"family":"myfamily","table":"mytable","size":300,"max-size":1000,"samples":3,"growth-per-day":100,"days-until-limit":7}`,
+					atom.rr.Body.String())
+			},
+		},
+		{
+			Desc:               "Read Table Size Projection Not Found",
+			Path:               "/limits/tables/myfamily/mytable/projection",
+			Method:             http.MethodGet,
+			ExpectedStatusCode: http.StatusNotFound,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.ReadTableSizeProjectionReturns(limits.TableSizeProjection{}, &errs.NotFoundError{Err: "table 'myfamily___mytable' not found"})
+			},
+		},
 
 		{
 			Desc:               "Create Family Success",
@@ -475,6 +573,56 @@ func TestExecEndpointHandler(_t *testing.T) {
 				}
 			},
 		},
+		{
+			Desc:   "Create Table With Unique Constraints",
+			Path:   "/families/foo/tables/bar",
+			Method: "POST",
+			JSONBody: map[string]interface{}{
+				"fields":            [][]interface{}{{"id", "integer"}, {"slug", "string"}},
+				"keyFields":         []string{"id"},
+				"uniqueConstraints": [][]string{{"slug"}},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.Equal(t, 0, atom.ei.CreateTableCallCount())
+				require.Equal(t, 1, atom.ei.CreateTablesCallCount())
+				tables := atom.ei.CreateTablesArgsForCall(0)
+				require.Equal(t, [][]string{{"slug"}}, tables[0].UniqueConstraints)
+			},
+		},
+		{
+			Desc:   "Create Table Reports Ledger Seq",
+			Path:   "/families/foo/tables/bar",
+			Method: "POST",
+			JSONBody: map[string]interface{}{
+				"fields":    [][]interface{}{{"id", "integer"}},
+				"keyFields": []string{"id"},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.ReadLedgerSeqReturns(1234, nil)
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.Equal(t, "1234", atom.rr.Header().Get(executive.LedgerSeqHeader))
+			},
+		},
+		{
+			Desc:   "Create Table Without Ledger Seq",
+			Path:   "/families/foo/tables/bar",
+			Method: "POST",
+			JSONBody: map[string]interface{}{
+				"fields":    [][]interface{}{{"id", "integer"}},
+				"keyFields": []string{"id"},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.ReadLedgerSeqReturns(0, errors.New("ctldb is down"))
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.Equal(t, 1, atom.ei.CreateTableCallCount())
+				require.Empty(t, atom.rr.Header().Get(executive.LedgerSeqHeader))
+			},
+		},
 		{
 			Desc:   "Alter Table Success",
 			Path:   "/families/foo/tables/bar",
@@ -495,7 +643,7 @@ func TestExecEndpointHandler(_t *testing.T) {
 					t.Fatalf("Expected AddFields call count to be %v, was %v", want, got)
 				}
 
-				a1, a2, a3, a4 := atom.ei.AddFieldsArgsForCall(0)
+				a1, a2, a3, a4, _ := atom.ei.AddFieldsArgsForCall(0)
 				if want, got := "foo", a1; want != got {
 					t.Errorf("Expected: %v, got %v", want, got)
 				}
@@ -648,7 +796,7 @@ func TestExecEndpointHandler(_t *testing.T) {
 			},
 			ExpectedStatusCode: 200,
 			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
-				atom.ei.MutateReturns(nil)
+				atom.ei.MutateReturns(executive.MutationResult{}, nil)
 			},
 			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
 				if want, got := 1, atom.ei.MutateCallCount(); want != got {
@@ -886,6 +1034,31 @@ func TestExecEndpointHandler(_t *testing.T) {
 				}, ft)
 			},
 		},
+		{
+			Desc:               "Delete Family Success",
+			Path:               "/families/myfamily?tombstones=true",
+			Method:             http.MethodDelete,
+			ExpectedStatusCode: http.StatusOK,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.EqualValues(t, 1, atom.ei.DeleteFamilyCallCount())
+				family, tombstones := atom.ei.DeleteFamilyArgsForCall(0)
+				require.Equal(t, "myfamily", family)
+				require.True(t, tombstones)
+			},
+		},
+		{
+			Desc:               "Delete Family Checks Errors when not enabled",
+			Path:               "/families/myfamily",
+			Method:             http.MethodDelete,
+			ExpectedStatusCode: http.StatusBadRequest,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ee.EnableDestructiveSchemaChanges = false
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.EqualValues(t, 0, atom.ei.DeleteFamilyCallCount())
+				require.EqualValues(t, "Deleting families is not enabled.", atom.rr.Body.String())
+			},
+		},
 		{
 			Desc:               "Get Table Schema Success",
 			Path:               "/schema/table/foofamily/bartable",
@@ -984,6 +1157,135 @@ func TestExecEndpointHandler(_t *testing.T) {
 				require.EqualValues(t, string(bs), atom.rr.Body.String())
 			},
 		},
+		{
+			Desc:               "Get Family Schema Page",
+			Path:               "/schema/family/foofamily?limit=2&after=a",
+			Method:             http.MethodGet,
+			ExpectedStatusCode: http.StatusOK,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.FamilyTablesReturns([]string{"a", "b", "c", "d"}, nil)
+				atom.ei.TableSchemaStub = func(family, table string) (*schema.Table, error) {
+					return &schema.Table{Family: family, Name: table}, nil
+				}
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.EqualValues(t, 0, atom.ei.FamilySchemasCallCount())
+				require.EqualValues(t, 2, atom.ei.TableSchemaCallCount())
+				require.Equal(t, "c", atom.rr.Header().Get("X-Ctlstore-Next-After"))
+				var got []schema.Table
+				require.NoError(t, json.Unmarshal(atom.rr.Body.Bytes(), &got))
+				require.EqualValues(t, []schema.Table{
+					{Family: "foofamily", Name: "b"},
+					{Family: "foofamily", Name: "c"},
+				}, got)
+			},
+		},
+		{
+			Desc:               "Get Family Schema Last Page",
+			Path:               "/schema/family/foofamily?limit=2&after=c",
+			Method:             http.MethodGet,
+			ExpectedStatusCode: http.StatusOK,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.FamilyTablesReturns([]string{"a", "b", "c", "d"}, nil)
+				atom.ei.TableSchemaStub = func(family, table string) (*schema.Table, error) {
+					return &schema.Table{Family: family, Name: table}, nil
+				}
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.EqualValues(t, 1, atom.ei.TableSchemaCallCount())
+				require.Equal(t, "", atom.rr.Header().Get("X-Ctlstore-Next-After"))
+			},
+		},
+		{
+			Desc:               "Get Family Schema Invalid Limit",
+			Path:               "/schema/family/foofamily?limit=0",
+			Method:             http.MethodGet,
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			Desc:               "Get Family Schema Gzip",
+			Path:               "/schema/family/foofamily",
+			Method:             http.MethodGet,
+			Headers:            map[string]string{"Accept-Encoding": "gzip"},
+			ExpectedStatusCode: http.StatusOK,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.FamilySchemasReturns([]schema.Table{{Family: "foofamily", Name: "bartable"}}, nil)
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.Equal(t, "gzip", atom.rr.Header().Get("Content-Encoding"))
+				gr, err := gzip.NewReader(atom.rr.Body)
+				require.NoError(t, err)
+				body, err := ioutil.ReadAll(gr)
+				require.NoError(t, err)
+				bs, err := json.Marshal([]schema.Table{{Family: "foofamily", Name: "bartable"}})
+				require.NoError(t, err)
+				require.Equal(t, string(bs), string(body))
+			},
+		},
+		{
+			Desc:               "Get Family Table Names",
+			Path:               "/schema/family/foofamily/tables",
+			Method:             http.MethodGet,
+			ExpectedStatusCode: http.StatusOK,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.FamilyTablesReturns([]string{"bartable", "bartable2"}, nil)
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.Equal(t, `["bartable","bartable2"]`, atom.rr.Body.String())
+			},
+		},
+		{
+			Desc:               "Read Table Rows",
+			Path:               "/families/foofamily/tables/bartable/rows?limit=2&after=%5B%22a%22%5D",
+			Method:             http.MethodGet,
+			ExpectedStatusCode: http.StatusOK,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.ReadRowsStub = func(family, table string, query executive.RowsQuery, fn func(map[string]interface{}) error) error {
+					for _, key := range []string{"b", "c"} {
+						if err := fn(map[string]interface{}{"id": key}); err != nil {
+							return err
+						}
+					}
+					return nil
+				}
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				family, table, query, _ := atom.ei.ReadRowsArgsForCall(0)
+				require.Equal(t, "foofamily", family)
+				require.Equal(t, "bartable", table)
+				require.Equal(t, executive.RowsQuery{Limit: 2, After: []interface{}{"a"}}, query)
+				require.Equal(t, `[{"id":"b"},{"id":"c"}]`, atom.rr.Body.String())
+			},
+		},
+		{
+			Desc:               "Read Table Rows Empty",
+			Path:               "/families/foofamily/tables/bartable/rows",
+			Method:             http.MethodGet,
+			ExpectedStatusCode: http.StatusOK,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				_, _, query, _ := atom.ei.ReadRowsArgsForCall(0)
+				require.Equal(t, 1000, query.Limit)
+				require.Equal(t, `[]`, atom.rr.Body.String())
+			},
+		},
+		{
+			Desc:               "Read Table Rows Not Found",
+			Path:               "/families/foofamily/tables/bartable/rows",
+			Method:             http.MethodGet,
+			ExpectedStatusCode: http.StatusNotFound,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.ReadRowsReturns(&errs.NotFoundError{Err: "Table not found"})
+			},
+		},
+		{
+			Desc:               "Read Table Rows Offset And After",
+			Path:               "/families/foofamily/tables/bartable/rows?offset=2&after=%5B1%5D",
+			Method:             http.MethodGet,
+			ExpectedStatusCode: http.StatusBadRequest,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.Equal(t, 0, atom.ei.ReadRowsCallCount())
+			},
+		},
 		{
 			Desc:               "Create Tables Success",
 			Path:               "/tables",
@@ -1040,41 +1342,912 @@ func TestExecEndpointHandler(_t *testing.T) {
 				}
 			},
 		},
-	}
-
-	///////////////////////////////////////////////////
-	// Execute the table
-	for _, atom := range atoms {
-		a := &atom
-		_t.Run(a.Desc, func(t *testing.T) {
-			// Why does this bug in Parallel mode? Is it because of
-			// closure variable capture?
-			// t.Parallel()
-
-			var body []byte
-			var err error
-			var contentType string
-
-			if a.JSONBody != nil {
-				body, err = json.Marshal(a.JSONBody)
-				if err != nil {
-					t.Fatalf("Couldn't marshal JSON body: %v", err)
-				}
-				contentType = "application/json"
-			} else {
-				body = a.RawBody
-			}
-
-			req, err := http.NewRequest(a.Method, a.Path, bytes.NewReader(body))
-			if err != nil {
-				t.Fatalf("Unexpected error creating request: %v", err)
-			}
-
-			req.Header.Set("ctlstore-writer", "writer1")
-			req.Header.Set("ctlstore-secret", "")
-
-			if contentType != "" {
-				req.Header.Set("content-type", contentType)
+		{
+			Desc:   "Alter Column Success",
+			Path:   "/families/family1/tables/table1/columns/field1",
+			Method: http.MethodPatch,
+			JSONBody: map[string]interface{}{
+				"name": "field2",
+				"type": "text",
+			},
+			ExpectedStatusCode: http.StatusOK,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.EqualValues(t, 1, atom.ei.AlterFieldCallCount())
+				family, table, field, newField, newType := atom.ei.AlterFieldArgsForCall(0)
+				require.Equal(t, "family1", family)
+				require.Equal(t, "table1", table)
+				require.Equal(t, "field1", field)
+				require.Equal(t, "field2", newField)
+				require.Equal(t, schema.FTText, newType)
+			},
+		},
+		{
+			Desc:   "Alter Column Unknown Type",
+			Path:   "/families/family1/tables/table1/columns/field1",
+			Method: http.MethodPatch,
+			JSONBody: map[string]interface{}{
+				"type": "widestring",
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.EqualValues(t, 0, atom.ei.AlterFieldCallCount())
+			},
+		},
+		{
+			Desc:   "Create Table From Template",
+			Path:   "/families/foo/tables/bar",
+			Method: "POST",
+			JSONBody: map[string]interface{}{
+				"fields":    [][]interface{}{{"field1", "string"}},
+				"keyFields": []string{"field1"},
+				"template":  "tenant-scoped",
+			},
+			ExpectedStatusCode: http.StatusOK,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.EqualValues(t, 0, atom.ei.CreateTableCallCount())
+				require.EqualValues(t, 1, atom.ei.CreateTablesCallCount())
+				require.EqualValues(t, []schema.Table{{
+					Family:    "foo",
+					Name:      "bar",
+					Fields:    [][]string{{"field1", "string"}},
+					KeyFields: []string{"field1"},
+					Template:  "tenant-scoped",
+				}}, atom.ei.CreateTablesArgsForCall(0))
+			},
+		},
+		{
+			Desc:   "Save Template",
+			Path:   "/families/foo/templates/tenant-scoped",
+			Method: http.MethodPost,
+			JSONBody: map[string]interface{}{
+				"keyFields": [][]interface{}{{"tenant_id", "string"}},
+				"fields":    [][]interface{}{{"updated_at", "integer"}},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.EqualValues(t, 1, atom.ei.SaveTableTemplateCallCount())
+				require.EqualValues(t, schema.TableTemplate{
+					Family:    "foo",
+					Name:      "tenant-scoped",
+					KeyFields: [][]string{{"tenant_id", "string"}},
+					Fields:    [][]string{{"updated_at", "integer"}},
+				}, atom.ei.SaveTableTemplateArgsForCall(0))
+			},
+		},
+		{
+			Desc:               "Read Templates",
+			Path:               "/families/foo/templates",
+			Method:             http.MethodGet,
+			ExpectedStatusCode: http.StatusOK,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.ReadTableTemplatesReturns([]schema.TableTemplate{{Family: "foo", Name: "tenant-scoped"}}, nil)
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.EqualValues(t, "foo", atom.ei.ReadTableTemplatesArgsForCall(0))
+				var templates []schema.TableTemplate
+				require.NoError(t, json.NewDecoder(atom.rr.Body).Decode(&templates))
+				require.EqualValues(t, []schema.TableTemplate{{Family: "foo", Name: "tenant-scoped"}}, templates)
+			},
+		},
+		{
+			Desc:               "Delete Template Not Found",
+			Path:               "/families/foo/templates/tenant-scoped",
+			Method:             http.MethodDelete,
+			ExpectedStatusCode: http.StatusNotFound,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.DeleteTableTemplateReturns(&errs.NotFoundError{Err: "Template not found"})
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				family, template := atom.ei.DeleteTableTemplateArgsForCall(0)
+				require.EqualValues(t, "foo", family)
+				require.EqualValues(t, "tenant-scoped", template)
+			},
+		},
+		{
+			Desc:   "Register Webhook",
+			Path:   "/families/foo/webhooks",
+			Method: http.MethodPost,
+			JSONBody: map[string]interface{}{
+				"url":    "https://example.com/hook",
+				"secret": "hook-secret",
+			},
+			ExpectedStatusCode: http.StatusOK,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.RegisterWebhookReturns(executive.Webhook{ID: "hook-id", Family: "foo", URL: "https://example.com/hook"}, nil)
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				family, url, secret := atom.ei.RegisterWebhookArgsForCall(0)
+				require.EqualValues(t, "foo", family)
+				require.EqualValues(t, "https://example.com/hook", url)
+				require.EqualValues(t, "hook-secret", secret)
+				var hook executive.Webhook
+				require.NoError(t, json.NewDecoder(atom.rr.Body).Decode(&hook))
+				require.EqualValues(t, "hook-id", hook.ID)
+			},
+		},
+		{
+			Desc:               "Read Webhooks",
+			Path:               "/families/foo/webhooks",
+			Method:             http.MethodGet,
+			ExpectedStatusCode: http.StatusOK,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.ReadWebhooksReturns([]executive.Webhook{{ID: "hook-id", Family: "foo", URL: "https://example.com/hook"}}, nil)
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.EqualValues(t, "foo", atom.ei.ReadWebhooksArgsForCall(0))
+				var hooks []executive.Webhook
+				require.NoError(t, json.NewDecoder(atom.rr.Body).Decode(&hooks))
+				require.Len(t, hooks, 1)
+				require.EqualValues(t, "https://example.com/hook", hooks[0].URL)
+			},
+		},
+		{
+			Desc:               "Delete Webhook",
+			Path:               "/families/foo/webhooks/hook-id",
+			Method:             http.MethodDelete,
+			ExpectedStatusCode: http.StatusOK,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				family, id := atom.ei.DeleteWebhookArgsForCall(0)
+				require.EqualValues(t, "foo", family)
+				require.EqualValues(t, "hook-id", id)
+			},
+		},
+		{
+			Desc:   "Set Table References",
+			Path:   "/families/foo/tables/memberships/references",
+			Method: http.MethodPut,
+			JSONBody: map[string]interface{}{
+				"references": map[string]interface{}{
+					"user_id": map[string]string{"family": "foo", "table": "users", "field": "id"},
+				},
+				"strict": true,
+			},
+			ExpectedStatusCode: http.StatusOK,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				table, refs, strict := atom.ei.SetTableReferencesArgsForCall(0)
+				require.EqualValues(t, schema.FamilyTable{Family: "foo", Table: "memberships"}, table)
+				require.EqualValues(t, map[string]schema.FieldReference{
+					"user_id": {Family: "foo", Table: "users", Field: "id"},
+				}, refs)
+				require.True(t, strict)
+			},
+		},
+		{
+			Desc:               "Read Family Defaults",
+			Path:               "/families/family1/defaults",
+			Method:             http.MethodGet,
+			ExpectedStatusCode: http.StatusOK,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.ReadFamilyDefaultsReturns(executive.FamilyDefaults{Family: "family1", MaxKeyFields: 2}, nil)
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.Equal(t, "family1", atom.ei.ReadFamilyDefaultsArgsForCall(0))
+				var defaults executive.FamilyDefaults
+				require.NoError(t, json.NewDecoder(atom.rr.Body).Decode(&defaults))
+				require.Equal(t, 2, defaults.MaxKeyFields)
+			},
+		},
+		{
+			Desc:   "Save Family Defaults",
+			Path:   "/families/family1/defaults",
+			Method: http.MethodPost,
+			JSONBody: map[string]interface{}{
+				"tableSizeLimits": map[string]int64{"max-size": 1000, "warn-size": 800},
+				"keyFieldPattern": "_id$",
+			},
+			ExpectedStatusCode: http.StatusOK,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.Equal(t, executive.FamilyDefaults{
+					Family:          "family1",
+					TableSizeLimits: &limits.SizeLimits{MaxSize: 1000, WarnSize: 800},
+					KeyFieldPattern: "_id$",
+				}, atom.ei.SaveFamilyDefaultsArgsForCall(0))
+			},
+		},
+		{
+			Desc:               "Create API Token",
+			Path:               "/tokens",
+			Method:             http.MethodPost,
+			JSONBody:           map[string]string{"name": "dashboard"},
+			ExpectedStatusCode: http.StatusOK,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.CreateAPITokenReturns(executive.APIToken{Name: "dashboard", Token: "ctlro_abc"}, nil)
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.Equal(t, "dashboard", atom.ei.CreateAPITokenArgsForCall(0))
+				var token executive.APIToken
+				require.NoError(t, json.NewDecoder(atom.rr.Body).Decode(&token))
+				require.Equal(t, "ctlro_abc", token.Token)
+			},
+		},
+		{
+			Desc:               "Delete API Token",
+			Path:               "/tokens/dashboard",
+			Method:             http.MethodDelete,
+			ExpectedStatusCode: http.StatusOK,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.Equal(t, "dashboard", atom.ei.DeleteAPITokenArgsForCall(0))
+			},
+		},
+		{
+			Desc:               "Read Schema With API Token",
+			Path:               "/schema/family/foofamily",
+			Method:             http.MethodGet,
+			Headers:            map[string]string{"Authorization": "Bearer ctlro_abc"},
+			ExpectedStatusCode: http.StatusOK,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.Equal(t, "ctlro_abc", atom.ei.CheckAPITokenArgsForCall(0))
+				require.EqualValues(t, 1, atom.ei.FamilySchemasCallCount())
+			},
+		},
+		{
+			Desc:               "Read Limits With Invalid API Token",
+			Path:               "/limits/tables",
+			Method:             http.MethodGet,
+			Headers:            map[string]string{"Authorization": "Bearer ctlro_nope"},
+			ExpectedStatusCode: http.StatusUnauthorized,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.CheckAPITokenReturns(executive.APIToken{}, executive.ErrInvalidAPIToken)
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.EqualValues(t, 0, atom.ei.ReadTableSizeLimitsCallCount())
+			},
+		},
+		{
+			Desc:               "Update Limits With API Token",
+			Path:               "/limits/writers/writer1",
+			Method:             http.MethodPost,
+			JSONBody:           limits.RateLimit{Amount: 100, Period: time.Minute},
+			Headers:            map[string]string{"Authorization": "Bearer ctlro_abc"},
+			ExpectedStatusCode: http.StatusForbidden,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.EqualValues(t, 0, atom.ei.UpdateWriterRateLimitCallCount())
+			},
+		},
+		{
+			Desc:               "Read API Tokens With API Token",
+			Path:               "/tokens",
+			Method:             http.MethodGet,
+			Headers:            map[string]string{"Authorization": "Bearer ctlro_abc"},
+			ExpectedStatusCode: http.StatusForbidden,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.EqualValues(t, 0, atom.ei.ReadAPITokensCallCount())
+			},
+		},
+		{
+			Desc:               "Require Auth Without Token",
+			Path:               "/limits/tables",
+			Method:             http.MethodGet,
+			ExpectedStatusCode: http.StatusUnauthorized,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ee.RequireAuth = true
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.EqualValues(t, 0, atom.ei.ReadTableSizeLimitsCallCount())
+			},
+		},
+		{
+			Desc:               "Require Auth Writer Route Without Token",
+			Path:               "/cookie",
+			Method:             http.MethodGet,
+			ExpectedStatusCode: http.StatusOK,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ee.RequireAuth = true
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.EqualValues(t, 1, atom.ei.GetWriterCookieCallCount())
+			},
+		},
+		{
+			Desc:               "Require Auth Read API Tokens Without Token",
+			Path:               "/tokens",
+			Method:             http.MethodGet,
+			ExpectedStatusCode: http.StatusUnauthorized,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ee.RequireAuth = true
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.EqualValues(t, 0, atom.ei.ReadAPITokensCallCount())
+			},
+		},
+		{
+			Desc:               "Require Auth Create API Token With Admin Token",
+			Path:               "/tokens",
+			Method:             http.MethodPost,
+			JSONBody:           map[string]string{"name": "dashboard"},
+			Headers:            map[string]string{"Authorization": "Bearer admin-secret"},
+			ExpectedStatusCode: http.StatusOK,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ee.RequireAuth = true
+				atom.ee.AdminTokens = []executive.AdminToken{{Name: "ops", Token: "admin-secret"}}
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.EqualValues(t, 0, atom.ei.CheckAPITokenCallCount())
+				require.Equal(t, "dashboard", atom.ei.CreateAPITokenArgsForCall(0))
+			},
+		},
+		{
+			Desc:               "Read Writers",
+			Path:               "/writers",
+			Method:             http.MethodGet,
+			ExpectedStatusCode: http.StatusOK,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.ReadWritersReturns([]executive.WriterInfo{{Name: "writer1", LastSourceIP: "10.0.0.1", MutationCount: 3, CookieLength: 8, RateLimit: limits.RateLimit{Amount: 100, Period: time.Minute}}}, nil)
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.EqualValues(t, 1, atom.ei.ReadWritersCallCount())
+				var writers []executive.WriterInfo
+				require.NoError(t, json.NewDecoder(atom.rr.Body).Decode(&writers))
+				require.EqualValues(t, []executive.WriterInfo{{Name: "writer1", LastSourceIP: "10.0.0.1", MutationCount: 3, CookieLength: 8, RateLimit: limits.RateLimit{Amount: 100, Period: time.Minute}}}, writers)
+			},
+		},
+		{
+			Desc:               "Read Writer Activity",
+			Path:               "/writers/writer1/activity",
+			Method:             http.MethodGet,
+			ExpectedStatusCode: http.StatusOK,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.ReadWriterActivityReturns(executive.WriterActivity{Name: "writer1", MutationCount: 3, IdleSeconds: 60}, nil)
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.Equal(t, "writer1", atom.ei.ReadWriterActivityArgsForCall(0))
+				var activity executive.WriterActivity
+				require.NoError(t, json.NewDecoder(atom.rr.Body).Decode(&activity))
+				require.Equal(t, executive.WriterActivity{Name: "writer1", MutationCount: 3, IdleSeconds: 60}, activity)
+			},
+		},
+		{
+			Desc:               "Enable Writer",
+			Path:               "/writers/writer1/enable",
+			Method:             http.MethodPost,
+			ExpectedStatusCode: http.StatusOK,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.Equal(t, "writer1", atom.ei.EnableWriterArgsForCall(0))
+			},
+		},
+		{
+			Desc:               "Request Writer Registration",
+			Path:               "/writers/writer2/request",
+			Method:             http.MethodPost,
+			JSONBody:           map[string]string{"secret": "secret", "description": "team-a's pipeline"},
+			ExpectedStatusCode: http.StatusOK,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ee.RequireWriterApproval = true
+				atom.ei.RequestWriterRegistrationReturns(executive.WriterRegistration{Name: "writer2", Status: "pending"}, nil)
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				writer, secret, description := atom.ei.RequestWriterRegistrationArgsForCall(0)
+				require.Equal(t, "writer2", writer)
+				require.Equal(t, "secret", secret)
+				require.Equal(t, "team-a's pipeline", description)
+				var reg executive.WriterRegistration
+				require.NoError(t, json.Unmarshal(atom.rr.Body.Bytes(), &reg))
+				require.Equal(t, "pending", reg.Status)
+			},
+		},
+		{
+			Desc:               "Register Writer Requires Approval",
+			Path:               "/writers/writer2",
+			Method:             http.MethodPost,
+			RawBody:            []byte("secret"),
+			ExpectedStatusCode: http.StatusForbidden,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ee.RequireWriterApproval = true
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				writer, secret := atom.ei.CheckWriterSecretArgsForCall(0)
+				require.Equal(t, "writer2", writer)
+				require.Equal(t, "secret", secret)
+				require.Equal(t, 0, atom.ei.RegisterWriterCallCount())
+			},
+		},
+		{
+			Desc:               "Re-register Writer While Approval Is Required",
+			Path:               "/writers/writer2",
+			Method:             http.MethodPost,
+			RawBody:            []byte("secret"),
+			ExpectedStatusCode: http.StatusOK,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ee.RequireWriterApproval = true
+				atom.ei.CheckWriterSecretReturns(true, nil)
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				writer, secret := atom.ei.RegisterWriterArgsForCall(0)
+				require.Equal(t, "writer2", writer)
+				require.Equal(t, "secret", secret)
+			},
+		},
+		{
+			Desc:               "Read Pending Writer Registrations",
+			Path:               "/writer-registrations?status=pending",
+			Method:             http.MethodGet,
+			ExpectedStatusCode: http.StatusOK,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.Equal(t, "pending", atom.ei.ReadWriterRegistrationsArgsForCall(0))
+			},
+		},
+		{
+			Desc:               "Approve Writer Registration",
+			Path:               "/writer-registrations/writer2/approve",
+			Method:             http.MethodPost,
+			JSONBody:           map[string]string{"reviewer": "someone-else", "reason": "owned by team-a"},
+			Headers:            map[string]string{"Authorization": "Bearer admin-secret"},
+			ExpectedStatusCode: http.StatusOK,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ee.AdminTokens = []executive.AdminToken{{Name: "ops", Token: "admin-secret"}}
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				writer, review := atom.ei.ApproveWriterRegistrationArgsForCall(0)
+				require.Equal(t, "writer2", writer)
+				require.Equal(t, executive.WriterReview{Reviewer: "ops", Reason: "owned by team-a"}, review)
+			},
+		},
+		{
+			Desc:               "Approve Writer Registration Without Admin Token",
+			Path:               "/writer-registrations/writer2/approve",
+			Method:             http.MethodPost,
+			ExpectedStatusCode: http.StatusUnauthorized,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.Equal(t, 0, atom.ei.ApproveWriterRegistrationCallCount())
+			},
+		},
+		{
+			Desc:               "Approve Writer Registration With API Token",
+			Path:               "/writer-registrations/writer2/approve",
+			Method:             http.MethodPost,
+			Headers:            map[string]string{"Authorization": "Bearer ctlro_abc"},
+			ExpectedStatusCode: http.StatusForbidden,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.Equal(t, 0, atom.ei.ApproveWriterRegistrationCallCount())
+			},
+		},
+		{
+			Desc:               "Reject Writer Registration Without Review",
+			Path:               "/writer-registrations/writer2/reject",
+			Method:             http.MethodPost,
+			Headers:            map[string]string{"Authorization": "Bearer admin-secret"},
+			ExpectedStatusCode: http.StatusConflict,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ee.AdminTokens = []executive.AdminToken{{Name: "ops", Token: "admin-secret"}}
+				atom.ei.RejectWriterRegistrationReturns(&errs.ConflictError{Err: "Writer registration was already approved"})
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				writer, review := atom.ei.RejectWriterRegistrationArgsForCall(0)
+				require.Equal(t, "writer2", writer)
+				require.Equal(t, executive.WriterReview{Reviewer: "ops"}, review)
+			},
+		},
+		{
+			Desc:               "Compare And Swap Cookie",
+			Path:               "/cookie/compare-and-swap",
+			Method:             http.MethodPost,
+			Headers:            map[string]string{"ctlstore-writer": "writer1", "ctlstore-secret": "secret"},
+			JSONBody:           map[string][]byte{"expected": []byte("shard-1"), "cookie": []byte("shard-2")},
+			ExpectedStatusCode: http.StatusOK,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.CompareAndSwapWriterCookieReturns(executive.CookieSwap{Swapped: true, Cookie: []byte("shard-2")}, nil)
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				writer, secret, expected, cookie := atom.ei.CompareAndSwapWriterCookieArgsForCall(0)
+				require.Equal(t, "writer1", writer)
+				require.Equal(t, "secret", secret)
+				require.Equal(t, []byte("shard-1"), expected)
+				require.Equal(t, []byte("shard-2"), cookie)
+			},
+		},
+		{
+			Desc:               "Compare And Swap Cookie Mismatch",
+			Path:               "/cookie/compare-and-swap",
+			Method:             http.MethodPost,
+			JSONBody:           map[string][]byte{"expected": []byte("shard-1"), "cookie": []byte("shard-2")},
+			ExpectedStatusCode: http.StatusConflict,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.CompareAndSwapWriterCookieReturns(executive.CookieSwap{Cookie: []byte("shard-3")}, nil)
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				var res executive.CookieSwap
+				require.NoError(t, json.NewDecoder(atom.rr.Body).Decode(&res))
+				require.Equal(t, executive.CookieSwap{Cookie: []byte("shard-3")}, res)
+			},
+		},
+		{
+			Desc:               "Disabled Writer Can't Fetch Cookie",
+			Path:               "/cookie",
+			Method:             http.MethodGet,
+			ExpectedStatusCode: http.StatusForbidden,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.GetWriterCookieReturns(nil, executive.ErrWriterDisabled)
+			},
+		},
+		{
+			Desc:               "Read Ledger",
+			Path:               "/ledger?from_seq=10&to_seq=20&limit=5",
+			Method:             http.MethodGet,
+			ExpectedStatusCode: http.StatusOK,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.ReadLedgerReturns([]executive.LedgerEntry{{Seq: 10, Statement: "DELETE FROM family1___table1"}}, nil)
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.EqualValues(t, 1, atom.ei.ReadLedgerCallCount())
+				require.Equal(t, executive.LedgerQuery{FromSeq: 10, ToSeq: 20, Limit: 5}, atom.ei.ReadLedgerArgsForCall(0))
+				var entries []executive.LedgerEntry
+				require.NoError(t, json.NewDecoder(atom.rr.Body).Decode(&entries))
+				require.Equal(t, []executive.LedgerEntry{{Seq: 10, Statement: "DELETE FROM family1___table1"}}, entries)
+			},
+		},
+		{
+			Desc:               "Read Ledger Backwards Range",
+			Path:               "/ledger?from_seq=20&to_seq=10",
+			Method:             http.MethodGet,
+			ExpectedStatusCode: http.StatusBadRequest,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.EqualValues(t, 0, atom.ei.ReadLedgerCallCount())
+			},
+		},
+		{
+			Desc:               "Read Ledger Invalid Limit",
+			Path:               "/ledger?limit=0",
+			Method:             http.MethodGet,
+			ExpectedStatusCode: http.StatusBadRequest,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.EqualValues(t, 0, atom.ei.ReadLedgerCallCount())
+			},
+		},
+		{
+			Desc:               "Start Export",
+			Path:               "/families/family1/tables/table1/export",
+			Method:             http.MethodPost,
+			JSONBody:           executive.ExportRequest{Format: "csv", Destination: "s3://bucket/table1.csv"},
+			ExpectedStatusCode: http.StatusAccepted,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.StartExportReturns(&executive.ExportJob{ID: "job1", State: "running"}, nil)
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.EqualValues(t, 1, atom.ei.StartExportCallCount())
+				family, table, req := atom.ei.StartExportArgsForCall(0)
+				require.Equal(t, "family1", family)
+				require.Equal(t, "table1", table)
+				require.Equal(t, executive.ExportRequest{Format: "csv", Destination: "s3://bucket/table1.csv"}, req)
+				var job executive.ExportJob
+				require.NoError(t, json.NewDecoder(atom.rr.Body).Decode(&job))
+				require.Equal(t, "job1", job.ID)
+			},
+		},
+		{
+			Desc:               "Start Export Invalid Format",
+			Path:               "/families/family1/tables/table1/export",
+			Method:             http.MethodPost,
+			JSONBody:           executive.ExportRequest{Format: "xml", Destination: "s3://bucket/table1.xml"},
+			ExpectedStatusCode: http.StatusBadRequest,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.StartExportReturns(nil, errs.BadRequest("format must be csv or parquet"))
+			},
+		},
+		{
+			Desc:               "Start Export Disabled",
+			Path:               "/families/family1/tables/table1/export",
+			Method:             http.MethodPost,
+			JSONBody:           executive.ExportRequest{Format: "csv", Destination: "s3://bucket/table1.csv"},
+			ExpectedStatusCode: http.StatusServiceUnavailable,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.StartExportReturns(nil, &errs.ServiceUnavailableErr{Err: "Exports are not enabled"})
+			},
+		},
+		{
+			Desc:               "Clone Table",
+			Path:               "/families/family1/tables/table1/clone?target-family=family2&target-table=table2&copy-rows=10&writer=writer1",
+			Method:             http.MethodPost,
+			ExpectedStatusCode: http.StatusOK,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.CloneTableReturns(executive.CloneResult{Family: "family2", Table: "table2", RowsCopied: 3}, nil)
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.EqualValues(t, 1, atom.ei.CloneTableCallCount())
+				source, target, copyRows, writer := atom.ei.CloneTableArgsForCall(0)
+				require.Equal(t, schema.FamilyTable{Family: "family1", Table: "table1"}, source)
+				require.Equal(t, schema.FamilyTable{Family: "family2", Table: "table2"}, target)
+				require.Equal(t, 10, copyRows)
+				require.Equal(t, "writer1", writer)
+				var res executive.CloneResult
+				require.NoError(t, json.NewDecoder(atom.rr.Body).Decode(&res))
+				require.Equal(t, 3, res.RowsCopied)
+			},
+		},
+		{
+			Desc:               "Clone Table Into Same Family",
+			Path:               "/families/family1/tables/table1/clone?target-table=table2",
+			Method:             http.MethodPost,
+			ExpectedStatusCode: http.StatusOK,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				_, target, copyRows, _ := atom.ei.CloneTableArgsForCall(0)
+				require.Equal(t, schema.FamilyTable{Family: "family1", Table: "table2"}, target)
+				require.Equal(t, 0, copyRows)
+			},
+		},
+		{
+			Desc:               "Clone Table Missing Target",
+			Path:               "/families/family1/tables/table1/clone",
+			Method:             http.MethodPost,
+			ExpectedStatusCode: http.StatusBadRequest,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.EqualValues(t, 0, atom.ei.CloneTableCallCount())
+			},
+		},
+		{
+			Desc:               "Clone Table Invalid Copy Rows",
+			Path:               "/families/family1/tables/table1/clone?target-table=table2&copy-rows=-1",
+			Method:             http.MethodPost,
+			ExpectedStatusCode: http.StatusBadRequest,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.EqualValues(t, 0, atom.ei.CloneTableCallCount())
+			},
+		},
+		{
+			Desc:               "Read Export Job",
+			Path:               "/export-jobs/job1",
+			Method:             http.MethodGet,
+			ExpectedStatusCode: http.StatusOK,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.ReadExportJobReturns(&executive.ExportJob{ID: "job1", State: "succeeded", Rows: 42}, nil)
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.Equal(t, "job1", atom.ei.ReadExportJobArgsForCall(0))
+				var job executive.ExportJob
+				require.NoError(t, json.NewDecoder(atom.rr.Body).Decode(&job))
+				require.EqualValues(t, 42, job.Rows)
+			},
+		},
+		{
+			Desc:               "Read Export Job Not Found",
+			Path:               "/export-jobs/job1",
+			Method:             http.MethodGet,
+			ExpectedStatusCode: http.StatusNotFound,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.ReadExportJobReturns(nil, &errs.NotFoundError{Err: "Export job not found"})
+			},
+		},
+		{
+			Desc:               "Analyze Tables",
+			Path:               "/analyze",
+			Method:             http.MethodPost,
+			ExpectedStatusCode: http.StatusOK,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.AnalyzeTablesReturns([]executive.TableAnalysis{{Family: "family1", Table: "table1", Size: 42}}, nil)
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.Nil(t, atom.ei.AnalyzeTablesArgsForCall(0))
+				var res []executive.TableAnalysis
+				require.NoError(t, json.NewDecoder(atom.rr.Body).Decode(&res))
+				require.Equal(t, []executive.TableAnalysis{{Family: "family1", Table: "table1", Size: 42}}, res)
+			},
+		},
+		{
+			Desc:               "Analyze Table",
+			Path:               "/families/family1/tables/table1/analyze",
+			Method:             http.MethodPost,
+			ExpectedStatusCode: http.StatusOK,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.Equal(t, []schema.FamilyTable{{Family: "family1", Table: "table1"}}, atom.ei.AnalyzeTablesArgsForCall(0))
+			},
+		},
+		{
+			Desc:               "Analyze Tables Already Running",
+			Path:               "/analyze",
+			Method:             http.MethodPost,
+			ExpectedStatusCode: http.StatusConflict,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.AnalyzeTablesReturns(nil, &errs.ConflictError{Err: "Tables are already being analyzed"})
+			},
+		},
+		{
+			Desc:   "Multi-Family Mutation Success",
+			Path:   "/mutations",
+			Method: http.MethodPost,
+			JSONBody: map[string]interface{}{
+				"cookie": []byte("cookie1"),
+				"mutations": []map[string]interface{}{
+					{"family": "family1", "table": "table1", "values": map[string]interface{}{"foo": "bar"}},
+					{"family": "family2", "table": "table2", "delete": true, "values": map[string]interface{}{"baz": "bim"}},
+				},
+			},
+			Headers: map[string]string{
+				"ctlstore-writer": "writer1",
+				"ctlstore-secret": "secret1",
+			},
+			ExpectedStatusCode: http.StatusOK,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.Equal(t, 1, atom.ei.MutateFamiliesCallCount())
+				writer, secret, cookie, checkCookie, reqs := atom.ei.MutateFamiliesArgsForCall(0)
+				require.Equal(t, "writer1", writer)
+				require.Equal(t, "secret1", secret)
+				require.Equal(t, []byte("cookie1"), cookie)
+				require.Nil(t, checkCookie)
+				require.Equal(t, []executive.ExecutiveMutationRequest{
+					{FamilyName: "family1", TableName: "table1", Values: map[string]interface{}{"foo": "bar"}},
+					{FamilyName: "family2", TableName: "table2", Delete: true, Values: map[string]interface{}{"baz": "bim"}},
+				}, reqs)
+			},
+		},
+		{
+			Desc:   "Conditional Mutation Skipped",
+			Path:   "/families/family1/mutations",
+			Method: http.MethodPost,
+			JSONBody: map[string]interface{}{
+				"mutations": []map[string]interface{}{
+					{
+						"table":    "table1",
+						"values":   map[string]interface{}{"foo": "bar"},
+						"ifValues": map[string]interface{}{"foo": "baz"},
+					},
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.MutateReturns(executive.MutationResult{
+					Skipped:   []int{0},
+					RateLimit: &executive.RateLimitBudget{Limit: 100, Remaining: 99, ResetAt: time.Unix(60, 0).UTC()},
+					LedgerSeq: 41,
+				}, nil)
+				atom.ei.ReadLedgerSeqReturns(41, nil)
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.Equal(t, 1, atom.ei.MutateCallCount())
+				_, _, _, _, _, reqs := atom.ei.MutateArgsForCall(0)
+				require.Equal(t, []executive.ExecutiveMutationRequest{{
+					TableName: "table1",
+					Values:    map[string]interface{}{"foo": "bar"},
+					IfValues:  map[string]interface{}{"foo": "baz"},
+				}}, reqs)
+				require.JSONEq(t, `{"skipped":[0],"applied":0,"dmlBytes":0,"rateLimit":{"limit":100,"remaining":99,"resetAt":"1970-01-01T00:01:00Z"},"ledgerSeq":41}`, atom.rr.Body.String())
+				require.Equal(t, "41", atom.rr.Header().Get(executive.LedgerSeqHeader))
+			},
+		},
+		{
+			Desc:   "Multi-Family Mutation Without Family",
+			Path:   "/mutations",
+			Method: http.MethodPost,
+			JSONBody: map[string]interface{}{
+				"mutations": []map[string]interface{}{
+					{"table": "table1", "values": map[string]interface{}{"foo": "bar"}},
+				},
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.Equal(t, 0, atom.ei.MutateFamiliesCallCount())
+			},
+		},
+		{
+			Desc:               "Enable Maintenance",
+			Path:               "/maintenance",
+			Method:             http.MethodPost,
+			JSONBody:           map[string]interface{}{"enabled": true, "reason": "ctldb upgrade"},
+			ExpectedStatusCode: http.StatusOK,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.Equal(t, 1, atom.ei.SetMaintenanceCallCount())
+				require.Equal(t, executive.Maintenance{Enabled: true, Reason: "ctldb upgrade"}, atom.ei.SetMaintenanceArgsForCall(0))
+			},
+		},
+		{
+			Desc:               "Update Maintenance Without Enabled",
+			Path:               "/maintenance",
+			Method:             http.MethodPost,
+			JSONBody:           map[string]interface{}{"reason": "ctldb upgrade"},
+			ExpectedStatusCode: http.StatusBadRequest,
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.Equal(t, 0, atom.ei.SetMaintenanceCallCount())
+			},
+		},
+		{
+			Desc:               "Read Maintenance",
+			Path:               "/maintenance",
+			Method:             http.MethodGet,
+			ExpectedStatusCode: http.StatusOK,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.ReadMaintenanceReturns(executive.Maintenance{Enabled: true, Reason: "cutover"}, nil)
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				var m executive.Maintenance
+				require.NoError(t, json.NewDecoder(atom.rr.Body).Decode(&m))
+				require.Equal(t, executive.Maintenance{Enabled: true, Reason: "cutover"}, m)
+			},
+		},
+		{
+			Desc:               "Mutation During Maintenance",
+			Path:               "/families/foo/mutations",
+			Method:             http.MethodPost,
+			JSONBody:           map[string]interface{}{"mutations": []map[string]interface{}{}},
+			ExpectedStatusCode: http.StatusServiceUnavailable,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.MutateReturns(executive.MutationResult{}, &errs.ServiceUnavailableErr{Err: "Executive is in maintenance mode", RetryAfter: 30 * time.Second})
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.Equal(t, "30", atom.rr.Header().Get("Retry-After"))
+				require.Equal(t, "Executive is in maintenance mode", atom.rr.Body.String())
+			},
+		},
+		{
+			Desc:   "Apply Schema Dry Run",
+			Path:   "/schema/apply?dryRun=true",
+			Method: http.MethodPost,
+			JSONBody: []map[string]interface{}{{
+				"family":    "family1",
+				"name":      "table1",
+				"fields":    [][]string{{"field1", "string"}},
+				"keyFields": []string{"field1"},
+			}},
+			ExpectedStatusCode: http.StatusOK,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.ApplySchemaReturns(executive.SchemaPlan{Changes: []executive.SchemaChange{
+					{Type: executive.SchemaChangeCreateTable, Family: "family1", Table: "table1"},
+				}}, nil)
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				require.Equal(t, 1, atom.ei.ApplySchemaCallCount())
+				tables, dryRun := atom.ei.ApplySchemaArgsForCall(0)
+				require.True(t, dryRun)
+				require.Equal(t, []schema.Table{{
+					Family:    "family1",
+					Name:      "table1",
+					Fields:    [][]string{{"field1", "string"}},
+					KeyFields: []string{"field1"},
+				}}, tables)
+				var plan executive.SchemaPlan
+				require.NoError(t, json.NewDecoder(atom.rr.Body).Decode(&plan))
+				require.Equal(t, executive.SchemaChangeCreateTable, plan.Changes[0].Type)
+			},
+		},
+		{
+			Desc:               "Apply Schema Conflict",
+			Path:               "/schema/apply",
+			Method:             http.MethodPost,
+			JSONBody:           []map[string]interface{}{},
+			ExpectedStatusCode: http.StatusConflict,
+			PreFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				atom.ei.ApplySchemaReturns(executive.SchemaPlan{}, &errs.ConflictError{Err: "Schema conflicts"})
+			},
+			PostFunc: func(t *testing.T, atom *testExecEndpointHandlerAtom) {
+				_, dryRun := atom.ei.ApplySchemaArgsForCall(0)
+				require.False(t, dryRun)
+			},
+		},
+	}
+
+	///////////////////////////////////////////////////
+	// Execute the table
+	for _, atom := range atoms {
+		a := &atom
+		_t.Run(a.Desc, func(t *testing.T) {
+			// Why does this bug in Parallel mode? Is it because of
+			// closure variable capture?
+			// t.Parallel()
+
+			var body []byte
+			var err error
+			var contentType string
+
+			if a.JSONBody != nil {
+				body, err = json.Marshal(a.JSONBody)
+				if err != nil {
+					t.Fatalf("Couldn't marshal JSON body: %v", err)
+				}
+				contentType = "application/json"
+			} else {
+				body = a.RawBody
+			}
+
+			req, err := http.NewRequest(a.Method, a.Path, bytes.NewReader(body))
+			if err != nil {
+				t.Fatalf("Unexpected error creating request: %v", err)
+			}
+
+			req.Header.Set("ctlstore-writer", "writer1")
+			req.Header.Set("ctlstore-secret", "")
+
+			if contentType != "" {
+				req.Header.Set("content-type", contentType)
+			}
+			for k, v := range a.Headers {
+				req.Header.Set(k, v)
 			}
 
 			a.ei = new(fakes.FakeExecutiveInterface)
@@ -5,9 +5,12 @@ import (
 	"database/sql"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -15,6 +18,7 @@ import (
 	ctldbpkg "github.com/segmentio/ctlstore/pkg/ctldb"
 	"github.com/segmentio/ctlstore/pkg/errs"
 	"github.com/segmentio/ctlstore/pkg/limits"
+	"github.com/segmentio/ctlstore/pkg/tracing"
 	"github.com/segmentio/ctlstore/pkg/utils"
 	"github.com/segmentio/events/v2"
 	"github.com/segmentio/stats/v4"
@@ -26,21 +30,81 @@ type ExecutiveService interface {
 }
 
 type ExecutiveServiceConfig struct {
-	CtlDBDSN                       string
-	RequestTimeout                 time.Duration
-	MaxTableSize                   int64
-	WarnTableSize                  int64
-	WriterLimitPeriod              time.Duration
-	WriterLimit                    int64
+	CtlDBDSN string
+	// CtlDBReadDSN optionally points at a read replica of the ctldb. When
+	// set, read-only endpoints are served from the replica as long as it
+	// passes health checks, falling back to the primary otherwise.
+	CtlDBReadDSN          string
+	ReplicaHealthInterval time.Duration
+	RequestTimeout        time.Duration
+	MaxTableSize          int64
+	WarnTableSize         int64
+	WriterLimitPeriod     time.Duration
+	WriterLimit           int64
+	// WriterBurst, if set, enforces the default writer limit with a token
+	// bucket of this size. See limits.RateLimit.
+	WriterBurst                    int64
 	EnableDestructiveSchemaChanges bool
+	// ShadowURL optionally points at a secondary executive which write
+	// requests are replayed against after the primary has handled them.
+	ShadowURL       string
+	ShadowQueueSize int
+	// ParameterizedDML makes mutations write parameterized statements to
+	// the ledger. See dbExecutive.ParameterizedDML.
+	ParameterizedDML bool
+	// RecordTraceIDs records the trace ID of each request in the ledger
+	// entries it writes. See dbExecutive.RecordTraceIDs.
+	RecordTraceIDs bool
+	// TableAnalyzer configures the refreshing of the family tables' index
+	// statistics. See TableAnalyzerConfig.
+	TableAnalyzer TableAnalyzerConfig
+	// Webhooks configures the delivery of notifications to the webhooks
+	// registered for families. See WebhooksConfig.
+	Webhooks WebhooksConfig
+	// WriterExpiry configures the disabling of idle writers. See
+	// WriterExpiryConfig.
+	WriterExpiry WriterExpiryConfig
+	// RequireWriterApproval makes writers be requested and approved by an
+	// admin, rather than registered directly. It requires AdminTokens. See
+	// WriterRegistration.
+	RequireWriterApproval bool
+	// TrustedProxies are the addresses or CIDR ranges of the load balancers
+	// in front of the executive. The X-Forwarded-For header of a request is
+	// only used to find its source IP when the request comes from one of them.
+	TrustedProxies []string
+	// RequireAuth rejects requests which present neither an admin or API
+	// token, other than those writers make. It requires AdminTokens. See
+	// ExecutiveEndpoint.RequireAuth.
+	RequireAuth bool
+	// AdminTokens authorize any request. See AdminToken.
+	AdminTokens []AdminToken
+	// ExportDir is the directory which exports to file:// destinations are
+	// written within. They're refused if it's unset.
+	ExportDir string
+	// Migrate applies the ctldb's pending migrations before the service is
+	// created. See ctldb.Migrate.
+	Migrate bool
 }
 
 type executiveService struct {
 	ctldb                          *sql.DB
+	replica                        *replicaDB
+	shadow                         *shadowWriter
 	limiter                        *dbLimiter
+	exporter                       *exporter
+	analyzer                       *tableAnalyzer
+	webhooks                       *webhookNotifier
+	writerExpirer                  *writerExpirer
+	ledgerSeq                      *ledgerSeqCache
 	ctx                            context.Context
 	serveTimeout                   time.Duration
 	enableDestructiveSchemaChanges bool
+	parameterizedDML               bool
+	recordTraceIDs                 bool
+	requireWriterApproval          bool
+	trustedProxies                 []*net.IPNet
+	requireAuth                    bool
+	adminTokens                    []AdminToken
 }
 
 func ExecutiveServiceFromConfig(config ExecutiveServiceConfig) (ExecutiveService, error) {
@@ -53,14 +117,74 @@ func ExecutiveServiceFromConfig(config ExecutiveServiceConfig) (ExecutiveService
 	if err != nil {
 		return nil, fmt.Errorf("Error when opening MySQL: %v", err)
 	}
+	if config.Migrate {
+		applied, err := ctldbpkg.Migrate(context.Background(), ctldb, dbType)
+		if err != nil {
+			return nil, errors.Wrap(err, "migrate ctldb")
+		}
+		for _, m := range applied {
+			events.Log("Applied ctldb migration %{version}d: %{name}s", m.Version, m.Name)
+		}
+		stats.Add("ctldb-migrations-applied", len(applied))
+	} else {
+		// the executive reads and writes tables which only the migrations
+		// create, so it can't serve a ctldb which hasn't been migrated
+		pending, err := ctldbpkg.PendingMigrations(context.Background(), ctldb, dbType)
+		if err != nil {
+			return nil, errors.Wrap(err, "check ctldb migrations")
+		}
+		if len(pending) > 0 {
+			return nil, errors.Errorf("ctldb has %d pending migrations, starting with %d (%s): run the executive with --migrate to apply them",
+				len(pending), pending[0].Version, pending[0].Name)
+		}
+	}
+	trustedProxies, err := parseTrustedProxies(config.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateAdminTokens(config.AdminTokens); err != nil {
+		return nil, err
+	}
+	if config.RequireAuth && len(config.AdminTokens) == 0 {
+		return nil, errors.New("requiring authentication needs admin tokens to manage API tokens with")
+	}
+	if config.RequireWriterApproval && len(config.AdminTokens) == 0 {
+		return nil, errors.New("requiring writer approval needs admin tokens to review writer registrations with")
+	}
 	defaultTableLimit := limits.SizeLimits{MaxSize: config.MaxTableSize, WarnSize: config.WarnTableSize}
-	limiter := newDBLimiter(ctldb, dbType, defaultTableLimit, config.WriterLimitPeriod, config.WriterLimit)
+	defaultWriterLimit := limits.RateLimit{Amount: config.WriterLimit, Period: config.WriterLimitPeriod, Burst: config.WriterBurst}
+	limiter := newDBLimiter(ctldb, dbType, defaultTableLimit, defaultWriterLimit)
 	es := &executiveService{
 		ctldb:                          ctldb,
 		serveTimeout:                   config.RequestTimeout,
 		limiter:                        limiter,
 		enableDestructiveSchemaChanges: config.EnableDestructiveSchemaChanges,
+		parameterizedDML:               config.ParameterizedDML,
+		recordTraceIDs:                 config.RecordTraceIDs,
+		requireWriterApproval:          config.RequireWriterApproval,
+		ledgerSeq:                      newLedgerSeqCache(ledgerSeqCacheTTL),
+		trustedProxies:                 trustedProxies,
+		requireAuth:                    config.RequireAuth,
+		adminTokens:                    config.AdminTokens,
+	}
+	if config.CtlDBReadDSN != "" {
+		readDSN, err := ctldbpkg.SetCtldbDSNParameters(config.CtlDBReadDSN)
+		if err != nil {
+			return nil, errors.Wrap(err, "read dsn")
+		}
+		readDB, err := sql.Open(dbType, readDSN)
+		if err != nil {
+			return nil, fmt.Errorf("Error when opening MySQL replica: %v", err)
+		}
+		es.replica = newReplicaDB(readDB, config.ReplicaHealthInterval)
 	}
+	if config.ShadowURL != "" {
+		es.shadow = newShadowWriter(config.ShadowURL, config.ShadowQueueSize)
+	}
+	es.exporter = newExporter(ctldb, es.replica, config.ExportDir)
+	es.analyzer = newTableAnalyzer(ctldb, dbType, config.TableAnalyzer)
+	es.webhooks = newWebhookNotifier(ctldb, config.Webhooks)
+	es.writerExpirer = newWriterExpirer(ctldb, config.WriterExpiry)
 	return es, nil
 }
 
@@ -68,17 +192,56 @@ func (s *executiveService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(s.ctx, s.serveTimeout)
 	defer cancel()
 
+	// Continue the caller's trace, if any, and tell them the request's
+	// span so that they can find it
+	if remote, ok := tracing.ParseTraceparent(r.Header.Get(tracing.TraceparentHeader)); ok {
+		ctx = tracing.ContextWithRemote(ctx, remote)
+	}
+	ctx, span := tracing.Start(ctx, "executive.request",
+		tracing.String("http.method", r.Method),
+		tracing.String("http.target", r.URL.Path))
+	w.Header().Set(tracing.TraceparentHeader, span.Context.Traceparent())
+	sw := &statusWriter{writer: w, code: http.StatusOK}
+	w = sw
+	defer func() {
+		span.SetAttributes(tracing.String("http.status_code", strconv.Itoa(sw.code)))
+		var err error
+		if sw.code >= http.StatusInternalServerError {
+			err = errors.New(http.StatusText(sw.code))
+		}
+		span.Finish(err)
+	}()
+
 	// Setup and tear these down every req to limit thread-safety garbage
 	cR := r.WithContext(ctx)
-	exec := &dbExecutive{DB: s.ctldb, Ctx: ctx, limiter: s.limiter}
+	exec := &dbExecutive{
+		DB:               s.ctldb,
+		ReadDB:           s.replica.readDB(),
+		Ctx:              ctx,
+		limiter:          s.limiter,
+		exporter:         s.exporter,
+		analyzer:         s.analyzer,
+		webhooks:         s.webhooks,
+		ledgerSeq:        s.ledgerSeq,
+		SourceIP:         requestSourceIP(r, s.trustedProxies),
+		ParameterizedDML: s.parameterizedDML,
+		RecordTraceIDs:   s.recordTraceIDs,
+	}
 	ep := ExecutiveEndpoint{
 		Exec:                           exec,
 		HealthChecker:                  exec,
 		EnableDestructiveSchemaChanges: s.enableDestructiveSchemaChanges,
+		RequireWriterApproval:          s.requireWriterApproval,
+		RequireAuth:                    s.requireAuth,
+		AdminTokens:                    s.adminTokens,
 	}
 	defer ep.Close()
 
 	events.Debug("Request: %{request}+v", cR)
+	if s.shadow.shadows(cR) {
+		s.shadow.serve(w, cR, ep.Handler())
+		return
+	}
 	ep.Handler().ServeHTTP(w, cR)
 }
 
@@ -93,9 +256,24 @@ func (s *executiveService) Start(ctx context.Context, bind string) error {
 		return errors.Wrap(err, "could not start limiter")
 	}
 
+	s.analyzer.start(ctx)
+	s.writerExpirer.start(ctx)
+
+	if err := s.webhooks.start(ctx); err != nil {
+		return errors.Wrap(err, "could not start webhooks")
+	}
+
 	// perform instrumentation in the background
 	go s.instrument(ctx)
 
+	if s.replica != nil {
+		go s.replica.start(ctx)
+	}
+
+	if s.shadow != nil {
+		go s.shadow.start(ctx)
+	}
+
 	h := &http.Server{Addr: bind, Handler: s}
 
 	go func() {
@@ -138,5 +316,80 @@ func (s *executiveService) instrumentLedgerRowCount(ctx context.Context) {
 }
 
 func (s *executiveService) Close() error {
+	if err := s.exporter.Close(); err != nil {
+		events.Log("Error stopping export jobs: %{error}+v", err)
+	}
+	if err := s.replica.Close(); err != nil {
+		events.Log("Error closing ctldb replica: %{error}+v", err)
+	}
 	return s.ctldb.Close()
 }
+
+// requestSourceIP returns the address of the client that made the request.
+// X-Forwarded-For is only honored when the request comes from a trusted
+// proxy, in which case the right-most untrusted entry is the client, since
+// the entries left of it could have been sent by the client itself.
+func requestSourceIP(r *http.Request, trustedProxies []*net.IPNet) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !isTrustedProxy(host, trustedProxies) {
+		return host
+	}
+	fwd := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(fwd) - 1; i >= 0; i-- {
+		addr := strings.TrimSpace(fwd[i])
+		if addr == "" {
+			continue
+		}
+		host = addr
+		if !isTrustedProxy(addr, trustedProxies) {
+			break
+		}
+	}
+	return host
+}
+
+func isTrustedProxy(addr string, trustedProxies []*net.IPNet) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTrustedProxies parses addresses and CIDR ranges, an address being a
+// range of just itself.
+func parseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	var res []*net.IPNet
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, errors.Errorf("invalid trusted proxy %q", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			res = append(res, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid trusted proxy %q", proxy)
+		}
+		res = append(res, network)
+	}
+	return res, nil
+}
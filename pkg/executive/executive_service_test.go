@@ -0,0 +1,84 @@
+package executive
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestSourceIP(t *testing.T) {
+	trustedProxies, err := parseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"})
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		expected   string
+	}{
+		{
+			name:       "direct",
+			remoteAddr: "203.0.113.7:5000",
+			expected:   "203.0.113.7",
+		},
+		{
+			name:       "untrusted remote",
+			remoteAddr: "203.0.113.7:5000",
+			forwarded:  []string{"198.51.100.1"},
+			expected:   "203.0.113.7",
+		},
+		{
+			name:       "trusted proxy",
+			remoteAddr: "10.1.2.3:5000",
+			forwarded:  []string{"198.51.100.1"},
+			expected:   "198.51.100.1",
+		},
+		{
+			name:       "trusted proxy address",
+			remoteAddr: "192.168.1.1:5000",
+			forwarded:  []string{"198.51.100.1"},
+			expected:   "198.51.100.1",
+		},
+		{
+			name:       "spoofed entries",
+			remoteAddr: "10.1.2.3:5000",
+			forwarded:  []string{"1.1.1.1, 198.51.100.1, 10.4.5.6"},
+			expected:   "198.51.100.1",
+		},
+		{
+			name:       "multiple headers",
+			remoteAddr: "10.1.2.3:5000",
+			forwarded:  []string{"1.1.1.1", "198.51.100.1"},
+			expected:   "198.51.100.1",
+		},
+		{
+			name:       "only proxies",
+			remoteAddr: "10.1.2.3:5000",
+			forwarded:  []string{"10.7.8.9, 10.4.5.6"},
+			expected:   "10.7.8.9",
+		},
+		{
+			name:       "trusted proxy without header",
+			remoteAddr: "10.1.2.3:5000",
+			expected:   "10.1.2.3",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			r, err := http.NewRequest("GET", "/", nil)
+			require.NoError(t, err)
+			r.RemoteAddr = test.remoteAddr
+			for _, fwd := range test.forwarded {
+				r.Header.Add("X-Forwarded-For", fwd)
+			}
+			require.Equal(t, test.expected, requestSourceIP(r, trustedProxies))
+		})
+	}
+}
+
+func TestParseTrustedProxiesInvalid(t *testing.T) {
+	for _, proxy := range []string{"not an ip", "10.0.0.0/33"} {
+		_, err := parseTrustedProxies([]string{proxy})
+		require.Error(t, err, proxy)
+	}
+}
@@ -0,0 +1,537 @@
+package executive
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/segmentio/events/v2"
+	"github.com/segmentio/stats/v4"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+	"github.com/segmentio/ctlstore/pkg/parquetwriter"
+	"github.com/segmentio/ctlstore/pkg/schema"
+	"github.com/segmentio/ctlstore/pkg/utils"
+)
+
+const (
+	ExportFormatCSV     = "csv"
+	ExportFormatParquet = "parquet"
+
+	ExportStateRunning   = "running"
+	ExportStateSucceeded = "succeeded"
+	ExportStateFailed    = "failed"
+
+	// how many rows are read from the ctldb at once while exporting
+	exportPageSize = 1000
+)
+
+// ExportRequest starts an export of a table.
+type ExportRequest struct {
+	// Format is ExportFormatCSV or ExportFormatParquet
+	Format string `json:"format"`
+	// Destination is an s3:// URL to write the export to, or a file:// URL
+	// within the executive's export directory
+	Destination string `json:"destination"`
+}
+
+// ExportJob is the status of an export of a table. Jobs run in the
+// background on the executive instance that started them, and record their
+// progress in the ctldb so that any instance can report it.
+type ExportJob struct {
+	ID          string    `json:"id"`
+	Family      string    `json:"family"`
+	Table       string    `json:"table"`
+	Format      string    `json:"format"`
+	Destination string    `json:"destination"`
+	State       string    `json:"state"`
+	Rows        int64     `json:"rows"`
+	Error       string    `json:"error,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// exporter runs export jobs. It outlives the requests which start them.
+type exporter struct {
+	db      *sql.DB
+	replica *replicaDB
+	// dir is the only directory which file:// destinations may be within.
+	// They're refused if it's empty.
+	dir    string
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	// for testing
+	openDestination func(ctx context.Context, destination string) (exportDestination, error)
+}
+
+func newExporter(db *sql.DB, replica *replicaDB, dir string) *exporter {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &exporter{
+		db:              db,
+		replica:         replica,
+		dir:             dir,
+		ctx:             ctx,
+		cancel:          cancel,
+		openDestination: openExportDestination,
+	}
+}
+
+// Close cancels any running jobs, which are recorded as failed, and waits
+// for them to finish.
+func (x *exporter) Close() error {
+	x.cancel()
+	x.wg.Wait()
+	return nil
+}
+
+func (e *dbExecutive) StartExport(familyName string, tableName string, req ExportRequest) (*ExportJob, error) {
+	if e.exporter == nil {
+		return nil, &errs.ServiceUnavailableErr{Err: "Exports are not enabled"}
+	}
+	if req.Format != ExportFormatCSV && req.Format != ExportFormatParquet {
+		return nil, errs.BadRequest("format must be %s or %s", ExportFormatCSV, ExportFormatParquet)
+	}
+	if err := validateExportDestination(req.Destination, e.exporter.dir); err != nil {
+		return nil, err
+	}
+	tbl, err := e.TableSchema(familyName, tableName)
+	switch {
+	case errors.Cause(err) == ErrTableDoesNotExist:
+		return nil, &errs.NotFoundError{Err: "Table not found"}
+	case err != nil:
+		return nil, err
+	}
+
+	ctx, cancel := e.ctx()
+	defer cancel()
+	now := time.Now().Truncate(time.Second)
+	job := &ExportJob{
+		ID:          uuid.New().String(),
+		Family:      tbl.Family,
+		Table:       tbl.Name,
+		Format:      req.Format,
+		Destination: req.Destination,
+		State:       ExportStateRunning,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	_, err = e.DB.ExecContext(ctx,
+		"INSERT INTO export_jobs (id, family_name, table_name, format, destination, state, rows_exported, error, created_at, updated_at) "+
+			"VALUES (?, ?, ?, ?, ?, ?, 0, '', ?, ?)",
+		job.ID, job.Family, job.Table, job.Format, job.Destination, job.State, now.Unix(), now.Unix())
+	if err != nil {
+		return nil, errors.Wrap(err, "insert export job")
+	}
+
+	e.exporter.wg.Add(1)
+	go func() {
+		defer e.exporter.wg.Done()
+		e.exporter.run(*job, tbl)
+	}()
+	return job, nil
+}
+
+func (e *dbExecutive) ReadExportJob(id string) (*ExportJob, error) {
+	ctx, cancel := e.ctx()
+	defer cancel()
+
+	// read from the primary, since a replica may not have seen a job that
+	// was just started
+	var job ExportJob
+	var jobErr sql.NullString
+	var createdAt, updatedAt int64
+	err := e.DB.QueryRowContext(ctx,
+		"SELECT id, family_name, table_name, format, destination, state, rows_exported, error, created_at, updated_at "+
+			"FROM export_jobs WHERE id = ?", id).
+		Scan(&job.ID, &job.Family, &job.Table, &job.Format, &job.Destination, &job.State, &job.Rows, &jobErr, &createdAt, &updatedAt)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, &errs.NotFoundError{Err: "Export job not found"}
+	case err != nil:
+		return nil, errors.Wrap(err, "select export job")
+	}
+	job.Error = jobErr.String
+	job.CreatedAt = time.Unix(createdAt, 0)
+	job.UpdatedAt = time.Unix(updatedAt, 0)
+	return &job, nil
+}
+
+func (x *exporter) run(job ExportJob, tbl *schema.Table) {
+	start := time.Now()
+	events.Log("Starting export %{job}s of %{family}s.%{table}s to %{destination}s", job.ID, job.Family, job.Table, job.Destination)
+
+	rows, err := x.export(job, tbl)
+	state := ExportStateSucceeded
+	var msg string
+	if err != nil {
+		state, msg = ExportStateFailed, err.Error()
+		events.Log("Export %{job}s failed after %d rows: %{error}+v", job.ID, rows, err)
+	} else {
+		events.Log("Finished export %{job}s of %d rows in %s", job.ID, rows, time.Since(start))
+	}
+	stats.Incr("export-jobs", stats.T("format", job.Format), stats.T("state", state))
+	stats.Observe("export-job-time", time.Since(start), stats.T("format", job.Format), stats.T("state", state))
+
+	// the job's context may be canceled, but the outcome should still be
+	// recorded
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := x.update(ctx, job.ID, state, rows, msg); err != nil {
+		events.Log("Could not record the outcome of export %{job}s: %{error}+v", job.ID, err)
+	}
+}
+
+func (x *exporter) update(ctx context.Context, id string, state string, rows int64, msg string) error {
+	_, err := x.db.ExecContext(ctx,
+		"UPDATE export_jobs SET state = ?, rows_exported = ?, error = ?, updated_at = ? WHERE id = ?",
+		state, rows, msg, time.Now().Unix(), id)
+	return errors.Wrap(err, "update export job")
+}
+
+// export streams the table to the job's destination a page at a time,
+// returning how many rows were exported.
+func (x *exporter) export(job ExportJob, tbl *schema.Table) (rows int64, err error) {
+	dst, err := x.openDestination(x.ctx, job.Destination)
+	if err != nil {
+		return 0, errors.Wrap(err, "open destination")
+	}
+	defer func() {
+		if err != nil {
+			dst.Abort(err)
+		}
+	}()
+	enc, err := newExportEncoder(job.Format, tbl, dst)
+	if err != nil {
+		return 0, err
+	}
+
+	exec := &dbExecutive{DB: x.db, ReadDB: x.replica.readDB(), Ctx: x.ctx}
+	query := RowsQuery{Limit: exportPageSize}
+	for {
+		var page int
+		err := exec.ReadRows(job.Family, job.Table, query, func(row map[string]interface{}) error {
+			page++
+			query.After = make([]interface{}, 0, len(tbl.KeyFields))
+			for _, key := range tbl.KeyFields {
+				query.After = append(query.After, row[key])
+			}
+			return enc.Write(row)
+		})
+		if err != nil {
+			return rows, errors.Wrap(err, "export rows")
+		}
+		rows += int64(page)
+		if page < exportPageSize {
+			break
+		}
+		if err := x.update(x.ctx, job.ID, ExportStateRunning, rows, ""); err != nil {
+			return rows, err
+		}
+	}
+
+	if err := enc.Close(); err != nil {
+		return rows, errors.Wrap(err, "finish encoding")
+	}
+	return rows, errors.Wrap(dst.Commit(), "commit export")
+}
+
+// exportEncoder writes rows of a table in one of the export formats.
+type exportEncoder interface {
+	Write(row map[string]interface{}) error
+	Close() error
+}
+
+func newExportEncoder(format string, tbl *schema.Table, w io.Writer) (exportEncoder, error) {
+	fields := make([]schema.NamedFieldType, 0, len(tbl.Fields))
+	ftm := schema.FieldTypeMap()
+	for _, f := range tbl.Fields {
+		ft, ok := ftm[f[1]]
+		if !ok {
+			return nil, errors.Errorf("unknown type %s of field %s", f[1], f[0])
+		}
+		fields = append(fields, schema.NamedFieldType{Name: schema.FieldName{Name: f[0]}, FieldType: ft})
+	}
+	switch format {
+	case ExportFormatCSV:
+		return newCSVExportEncoder(fields, w)
+	case ExportFormatParquet:
+		return newParquetExportEncoder(fields, w), nil
+	default:
+		return nil, errors.Errorf("unknown format %s", format)
+	}
+}
+
+// csvExportEncoder writes a header of field names followed by a record per
+// row. Binary values are base64 encoded, and nulls are empty.
+type csvExportEncoder struct {
+	fields []schema.NamedFieldType
+	w      *csv.Writer
+	record []string
+}
+
+func newCSVExportEncoder(fields []schema.NamedFieldType, w io.Writer) (*csvExportEncoder, error) {
+	enc := &csvExportEncoder{fields: fields, w: csv.NewWriter(w), record: make([]string, len(fields))}
+	for i, f := range fields {
+		enc.record[i] = f.Name.Name
+	}
+	return enc, enc.w.Write(enc.record)
+}
+
+func (enc *csvExportEncoder) Write(row map[string]interface{}) error {
+	for i, f := range enc.fields {
+		switch v := row[f.Name.Name].(type) {
+		case nil:
+			enc.record[i] = ""
+		case []byte:
+			if f.FieldType == schema.FTBinary || f.FieldType == schema.FTByteString {
+				enc.record[i] = base64.StdEncoding.EncodeToString(v)
+			} else {
+				enc.record[i] = string(v)
+			}
+		case string:
+			enc.record[i] = v
+		case float64:
+			enc.record[i] = strconv.FormatFloat(v, 'g', -1, 64)
+		case time.Time:
+			enc.record[i] = v.UTC().Format(schema.TimestampLayout)
+		case bool:
+			// mysql returns booleans as integers, so match them
+			enc.record[i] = "0"
+			if v {
+				enc.record[i] = "1"
+			}
+		default:
+			enc.record[i] = fmt.Sprint(v)
+		}
+	}
+	return enc.w.Write(enc.record)
+}
+
+func (enc *csvExportEncoder) Close() error {
+	enc.w.Flush()
+	return enc.w.Error()
+}
+
+// parquetExportEncoder writes every field as an optional column of the
+// closest parquet type.
+type parquetExportEncoder struct {
+	fields []schema.NamedFieldType
+	w      *parquetwriter.Writer
+	row    []interface{}
+}
+
+func newParquetExportEncoder(fields []schema.NamedFieldType, w io.Writer) *parquetExportEncoder {
+	columns := make([]parquetwriter.Column, len(fields))
+	for i, f := range fields {
+		columns[i].Name = f.Name.Name
+		switch f.FieldType {
+		case schema.FTInteger, schema.FTBoolean:
+			columns[i].Type = parquetwriter.Int64
+		case schema.FTDecimal:
+			columns[i].Type = parquetwriter.Double
+		case schema.FTBinary, schema.FTByteString:
+			columns[i].Type = parquetwriter.Bytes
+		default:
+			columns[i].Type = parquetwriter.String
+		}
+	}
+	return &parquetExportEncoder{
+		fields: fields,
+		w:      parquetwriter.New(w, columns),
+		row:    make([]interface{}, len(fields)),
+	}
+}
+
+func (enc *parquetExportEncoder) Write(row map[string]interface{}) error {
+	for i, f := range enc.fields {
+		v, err := parquetExportValue(f.FieldType, row[f.Name.Name])
+		if err != nil {
+			return errors.Wrapf(err, "field %s", f.Name.Name)
+		}
+		enc.row[i] = v
+	}
+	return enc.w.Write(enc.row)
+}
+
+func (enc *parquetExportEncoder) Close() error {
+	return enc.w.Close()
+}
+
+// parquetExportValue converts a value read from the ctldb to the go type of
+// the field's column, since drivers may return numbers as text.
+func parquetExportValue(ft schema.FieldType, v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	str := func() string {
+		if b, ok := v.([]byte); ok {
+			return string(b)
+		}
+		return fmt.Sprint(v)
+	}
+	switch ft {
+	case schema.FTInteger:
+		if i, ok := v.(int64); ok {
+			return i, nil
+		}
+		return strconv.ParseInt(str(), 10, 64)
+	case schema.FTBoolean:
+		switch v := v.(type) {
+		case bool:
+			if v {
+				return int64(1), nil
+			}
+			return int64(0), nil
+		case int64:
+			return v, nil
+		}
+		return strconv.ParseInt(str(), 10, 64)
+	case schema.FTTimestamp:
+		if t, ok := v.(time.Time); ok {
+			return t.UTC().Format(schema.TimestampLayout), nil
+		}
+		return str(), nil
+	case schema.FTDecimal:
+		if f, ok := v.(float64); ok {
+			return f, nil
+		}
+		return strconv.ParseFloat(str(), 64)
+	case schema.FTBinary, schema.FTByteString:
+		if b, ok := v.([]byte); ok {
+			return b, nil
+		}
+		return []byte(str()), nil
+	default:
+		return str(), nil
+	}
+}
+
+// exportDestination is where an export is written to. Nothing is visible at
+// the destination until the export is committed.
+type exportDestination interface {
+	io.Writer
+	Commit() error
+	Abort(err error)
+}
+
+// validateExportDestination checks that the destination is an s3:// URL, or
+// a file:// URL of a path within dir, since the executive would otherwise
+// write wherever a request asks it to.
+func validateExportDestination(destination string, dir string) error {
+	parsed, err := url.Parse(destination)
+	if err != nil {
+		return errs.BadRequest("invalid destination: %s", err)
+	}
+	switch {
+	case parsed.Scheme == "s3" && parsed.Host != "" && strings.TrimPrefix(parsed.Path, "/") != "":
+		return nil
+	case parsed.Scheme != "file" || parsed.Host != "" || parsed.Path == "":
+		return errs.BadRequest("destination must be an s3://bucket/key or file:///path URL")
+	case dir == "":
+		return errs.BadRequest("file:// destinations are not enabled, since the executive has no export directory")
+	}
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(parsed.Path))
+	if err != nil || !filepath.IsAbs(parsed.Path) || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return errs.BadRequest("file:// destinations must be within %s", dir)
+	}
+	return nil
+}
+
+func openExportDestination(ctx context.Context, destination string) (exportDestination, error) {
+	parsed, err := url.Parse(destination)
+	if err != nil {
+		return nil, err
+	}
+	switch parsed.Scheme {
+	case "s3":
+		return newS3ExportDestination(ctx, parsed.Host, strings.TrimPrefix(parsed.Path, "/"))
+	case "file":
+		return newFileExportDestination(parsed.Path)
+	default:
+		return nil, errors.Errorf("unknown scheme %s", parsed.Scheme)
+	}
+}
+
+// fileExportDestination writes to a temporary file which is renamed into
+// place on commit.
+type fileExportDestination struct {
+	*os.File
+	path string
+}
+
+func newFileExportDestination(path string) (*fileExportDestination, error) {
+	if err := utils.EnsureDirForFile(path); err != nil {
+		return nil, errors.Wrap(err, "ensure export dir exists")
+	}
+	f, err := os.Create(path + ".tmp")
+	if err != nil {
+		return nil, err
+	}
+	return &fileExportDestination{File: f, path: path}, nil
+}
+
+func (d *fileExportDestination) Commit() error {
+	if err := d.File.Close(); err != nil {
+		return err
+	}
+	return os.Rename(d.File.Name(), d.path)
+}
+
+func (d *fileExportDestination) Abort(err error) {
+	d.File.Close()
+	os.Remove(d.File.Name())
+}
+
+// s3ExportDestination streams to a multipart upload, which is aborted
+// rather than completed if the export fails.
+type s3ExportDestination struct {
+	*io.PipeWriter
+	done chan error
+}
+
+func newS3ExportDestination(ctx context.Context, bucket string, key string) (*s3ExportDestination, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "load aws config")
+	}
+	uploader := manager.NewUploader(s3.NewFromConfig(cfg))
+	pr, pw := io.Pipe()
+	d := &s3ExportDestination{PipeWriter: pw, done: make(chan error, 1)}
+	go func() {
+		_, err := uploader.Upload(ctx, &s3.PutObjectInput{
+			Bucket: &bucket,
+			Key:    &key,
+			Body:   pr,
+		})
+		// unblock the writer if the upload failed part way through
+		pr.CloseWithError(err)
+		d.done <- err
+	}()
+	return d, nil
+}
+
+func (d *s3ExportDestination) Commit() error {
+	d.PipeWriter.Close()
+	return errors.Wrap(<-d.done, "upload to s3")
+}
+
+func (d *s3ExportDestination) Abort(err error) {
+	d.PipeWriter.CloseWithError(err)
+	<-d.done
+}
@@ -0,0 +1,42 @@
+package executive
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+)
+
+func TestValidateExportDestination(t *testing.T) {
+	for _, test := range []struct {
+		destination string
+		dir         string
+		valid       bool
+	}{
+		{destination: "s3://bucket/table.csv", valid: true},
+		{destination: "s3://bucket/table.csv", dir: "/var/exports", valid: true},
+		{destination: "s3://bucket"},
+		{destination: "s3:///table.csv"},
+		{destination: "http://example.com/table.csv", dir: "/var/exports"},
+		{destination: "file:///var/exports/table.csv"},
+		{destination: "file:///var/exports/table.csv", dir: "/var/exports", valid: true},
+		{destination: "file:///var/exports/family/table.csv", dir: "/var/exports/", valid: true},
+		{destination: "file:///var/exports", dir: "/var/exports"},
+		{destination: "file:///etc/passwd", dir: "/var/exports"},
+		{destination: "file:///var/exports/../table.csv", dir: "/var/exports"},
+		{destination: "file:///var/exports-other/table.csv", dir: "/var/exports"},
+		{destination: "file://host/var/exports/table.csv", dir: "/var/exports"},
+		{destination: "file:table.csv", dir: "/var/exports"},
+	} {
+		t.Run(test.destination+" in "+test.dir, func(t *testing.T) {
+			err := validateExportDestination(test.destination, test.dir)
+			if test.valid {
+				require.NoError(t, err)
+				return
+			}
+			require.IsType(t, &errs.BadRequestError{}, errors.Cause(err))
+		})
+	}
+}
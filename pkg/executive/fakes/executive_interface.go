@@ -10,13 +10,14 @@ import (
 )
 
 type FakeExecutiveInterface struct {
-	AddFieldsStub        func(string, string, []string, []schema.FieldType) error
+	AddFieldsStub        func(string, string, []string, []schema.FieldType, map[string]schema.FieldOptions) error
 	addFieldsMutex       sync.RWMutex
 	addFieldsArgsForCall []struct {
 		arg1 string
 		arg2 string
 		arg3 []string
 		arg4 []schema.FieldType
+		arg5 map[string]schema.FieldOptions
 	}
 	addFieldsReturns struct {
 		result1 error
@@ -24,6 +25,100 @@ type FakeExecutiveInterface struct {
 	addFieldsReturnsOnCall map[int]struct {
 		result1 error
 	}
+	AddWriterGroupMemberStub        func(string, string, string) error
+	addWriterGroupMemberMutex       sync.RWMutex
+	addWriterGroupMemberArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}
+	addWriterGroupMemberReturns struct {
+		result1 error
+	}
+	addWriterGroupMemberReturnsOnCall map[int]struct {
+		result1 error
+	}
+	AlterFieldStub        func(string, string, string, string, schema.FieldType) error
+	alterFieldMutex       sync.RWMutex
+	alterFieldArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 schema.FieldType
+	}
+	alterFieldReturns struct {
+		result1 error
+	}
+	alterFieldReturnsOnCall map[int]struct {
+		result1 error
+	}
+	AnalyzeTablesStub        func([]schema.FamilyTable) ([]executive.TableAnalysis, error)
+	analyzeTablesMutex       sync.RWMutex
+	analyzeTablesArgsForCall []struct {
+		arg1 []schema.FamilyTable
+	}
+	analyzeTablesReturns struct {
+		result1 []executive.TableAnalysis
+		result2 error
+	}
+	analyzeTablesReturnsOnCall map[int]struct {
+		result1 []executive.TableAnalysis
+		result2 error
+	}
+	ApplySchemaStub        func([]schema.Table, bool) (executive.SchemaPlan, error)
+	applySchemaMutex       sync.RWMutex
+	applySchemaArgsForCall []struct {
+		arg1 []schema.Table
+		arg2 bool
+	}
+	applySchemaReturns struct {
+		result1 executive.SchemaPlan
+		result2 error
+	}
+	applySchemaReturnsOnCall map[int]struct {
+		result1 executive.SchemaPlan
+		result2 error
+	}
+	ApproveWriterRegistrationStub        func(string, executive.WriterReview) error
+	approveWriterRegistrationMutex       sync.RWMutex
+	approveWriterRegistrationArgsForCall []struct {
+		arg1 string
+		arg2 executive.WriterReview
+	}
+	approveWriterRegistrationReturns struct {
+		result1 error
+	}
+	approveWriterRegistrationReturnsOnCall map[int]struct {
+		result1 error
+	}
+	CheckAPITokenStub        func(string) (executive.APIToken, error)
+	checkAPITokenMutex       sync.RWMutex
+	checkAPITokenArgsForCall []struct {
+		arg1 string
+	}
+	checkAPITokenReturns struct {
+		result1 executive.APIToken
+		result2 error
+	}
+	checkAPITokenReturnsOnCall map[int]struct {
+		result1 executive.APIToken
+		result2 error
+	}
+	CheckWriterSecretStub        func(string, string) (bool, error)
+	checkWriterSecretMutex       sync.RWMutex
+	checkWriterSecretArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	checkWriterSecretReturns struct {
+		result1 bool
+		result2 error
+	}
+	checkWriterSecretReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
 	ClearTableStub        func(schema.FamilyTable) error
 	clearTableMutex       sync.RWMutex
 	clearTableArgsForCall []struct {
@@ -35,6 +130,51 @@ type FakeExecutiveInterface struct {
 	clearTableReturnsOnCall map[int]struct {
 		result1 error
 	}
+	CloneTableStub        func(schema.FamilyTable, schema.FamilyTable, int, string) (executive.CloneResult, error)
+	cloneTableMutex       sync.RWMutex
+	cloneTableArgsForCall []struct {
+		arg1 schema.FamilyTable
+		arg2 schema.FamilyTable
+		arg3 int
+		arg4 string
+	}
+	cloneTableReturns struct {
+		result1 executive.CloneResult
+		result2 error
+	}
+	cloneTableReturnsOnCall map[int]struct {
+		result1 executive.CloneResult
+		result2 error
+	}
+	CompareAndSwapWriterCookieStub        func(string, string, []byte, []byte) (executive.CookieSwap, error)
+	compareAndSwapWriterCookieMutex       sync.RWMutex
+	compareAndSwapWriterCookieArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 []byte
+		arg4 []byte
+	}
+	compareAndSwapWriterCookieReturns struct {
+		result1 executive.CookieSwap
+		result2 error
+	}
+	compareAndSwapWriterCookieReturnsOnCall map[int]struct {
+		result1 executive.CookieSwap
+		result2 error
+	}
+	CreateAPITokenStub        func(string) (executive.APIToken, error)
+	createAPITokenMutex       sync.RWMutex
+	createAPITokenArgsForCall []struct {
+		arg1 string
+	}
+	createAPITokenReturns struct {
+		result1 executive.APIToken
+		result2 error
+	}
+	createAPITokenReturnsOnCall map[int]struct {
+		result1 executive.APIToken
+		result2 error
+	}
 	CreateFamilyStub        func(string) error
 	createFamilyMutex       sync.RWMutex
 	createFamilyArgsForCall []struct {
@@ -72,6 +212,29 @@ type FakeExecutiveInterface struct {
 	createTablesReturnsOnCall map[int]struct {
 		result1 error
 	}
+	DeleteAPITokenStub        func(string) error
+	deleteAPITokenMutex       sync.RWMutex
+	deleteAPITokenArgsForCall []struct {
+		arg1 string
+	}
+	deleteAPITokenReturns struct {
+		result1 error
+	}
+	deleteAPITokenReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteFamilyStub        func(string, bool) error
+	deleteFamilyMutex       sync.RWMutex
+	deleteFamilyArgsForCall []struct {
+		arg1 string
+		arg2 bool
+	}
+	deleteFamilyReturns struct {
+		result1 error
+	}
+	deleteFamilyReturnsOnCall map[int]struct {
+		result1 error
+	}
 	DeleteTableSizeLimitStub        func(schema.FamilyTable) error
 	deleteTableSizeLimitMutex       sync.RWMutex
 	deleteTableSizeLimitArgsForCall []struct {
@@ -83,6 +246,41 @@ type FakeExecutiveInterface struct {
 	deleteTableSizeLimitReturnsOnCall map[int]struct {
 		result1 error
 	}
+	DeleteTableTemplateStub        func(string, string) error
+	deleteTableTemplateMutex       sync.RWMutex
+	deleteTableTemplateArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	deleteTableTemplateReturns struct {
+		result1 error
+	}
+	deleteTableTemplateReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteWebhookStub        func(string, string) error
+	deleteWebhookMutex       sync.RWMutex
+	deleteWebhookArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	deleteWebhookReturns struct {
+		result1 error
+	}
+	deleteWebhookReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteWriterGroupStub        func(string) error
+	deleteWriterGroupMutex       sync.RWMutex
+	deleteWriterGroupArgsForCall []struct {
+		arg1 string
+	}
+	deleteWriterGroupReturns struct {
+		result1 error
+	}
+	deleteWriterGroupReturnsOnCall map[int]struct {
+		result1 error
+	}
 	DeleteWriterRateLimitStub        func(string) error
 	deleteWriterRateLimitMutex       sync.RWMutex
 	deleteWriterRateLimitArgsForCall []struct {
@@ -105,6 +303,17 @@ type FakeExecutiveInterface struct {
 	dropTableReturnsOnCall map[int]struct {
 		result1 error
 	}
+	EnableWriterStub        func(string) error
+	enableWriterMutex       sync.RWMutex
+	enableWriterArgsForCall []struct {
+		arg1 string
+	}
+	enableWriterReturns struct {
+		result1 error
+	}
+	enableWriterReturnsOnCall map[int]struct {
+		result1 error
+	}
 	FamilySchemasStub        func(string) ([]schema.Table, error)
 	familySchemasMutex       sync.RWMutex
 	familySchemasArgsForCall []struct {
@@ -118,6 +327,19 @@ type FakeExecutiveInterface struct {
 		result1 []schema.Table
 		result2 error
 	}
+	FamilyTablesStub        func(string) ([]string, error)
+	familyTablesMutex       sync.RWMutex
+	familyTablesArgsForCall []struct {
+		arg1 string
+	}
+	familyTablesReturns struct {
+		result1 []string
+		result2 error
+	}
+	familyTablesReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
 	GetWriterCookieStub        func(string, string) ([]byte, error)
 	getWriterCookieMutex       sync.RWMutex
 	getWriterCookieArgsForCall []struct {
@@ -132,7 +354,7 @@ type FakeExecutiveInterface struct {
 		result1 []byte
 		result2 error
 	}
-	MutateStub        func(string, string, string, []byte, []byte, []executive.ExecutiveMutationRequest) error
+	MutateStub        func(string, string, string, []byte, []byte, []executive.ExecutiveMutationRequest) (executive.MutationResult, error)
 	mutateMutex       sync.RWMutex
 	mutateArgsForCall []struct {
 		arg1 string
@@ -143,10 +365,67 @@ type FakeExecutiveInterface struct {
 		arg6 []executive.ExecutiveMutationRequest
 	}
 	mutateReturns struct {
-		result1 error
+		result1 executive.MutationResult
+		result2 error
 	}
 	mutateReturnsOnCall map[int]struct {
-		result1 error
+		result1 executive.MutationResult
+		result2 error
+	}
+	MutateFamiliesStub        func(string, string, []byte, []byte, []executive.ExecutiveMutationRequest) (executive.MutationResult, error)
+	mutateFamiliesMutex       sync.RWMutex
+	mutateFamiliesArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 []byte
+		arg4 []byte
+		arg5 []executive.ExecutiveMutationRequest
+	}
+	mutateFamiliesReturns struct {
+		result1 executive.MutationResult
+		result2 error
+	}
+	mutateFamiliesReturnsOnCall map[int]struct {
+		result1 executive.MutationResult
+		result2 error
+	}
+	ReadAPITokensStub        func() ([]executive.APIToken, error)
+	readAPITokensMutex       sync.RWMutex
+	readAPITokensArgsForCall []struct {
+	}
+	readAPITokensReturns struct {
+		result1 []executive.APIToken
+		result2 error
+	}
+	readAPITokensReturnsOnCall map[int]struct {
+		result1 []executive.APIToken
+		result2 error
+	}
+	ReadExportJobStub        func(string) (*executive.ExportJob, error)
+	readExportJobMutex       sync.RWMutex
+	readExportJobArgsForCall []struct {
+		arg1 string
+	}
+	readExportJobReturns struct {
+		result1 *executive.ExportJob
+		result2 error
+	}
+	readExportJobReturnsOnCall map[int]struct {
+		result1 *executive.ExportJob
+		result2 error
+	}
+	ReadFamilyDefaultsStub        func(string) (executive.FamilyDefaults, error)
+	readFamilyDefaultsMutex       sync.RWMutex
+	readFamilyDefaultsArgsForCall []struct {
+		arg1 string
+	}
+	readFamilyDefaultsReturns struct {
+		result1 executive.FamilyDefaults
+		result2 error
+	}
+	readFamilyDefaultsReturnsOnCall map[int]struct {
+		result1 executive.FamilyDefaults
+		result2 error
 	}
 	ReadFamilyTableNamesStub        func(schema.FamilyName) ([]schema.FamilyTable, error)
 	readFamilyTableNamesMutex       sync.RWMutex
@@ -161,6 +440,43 @@ type FakeExecutiveInterface struct {
 		result1 []schema.FamilyTable
 		result2 error
 	}
+	ReadLedgerStub        func(executive.LedgerQuery) ([]executive.LedgerEntry, error)
+	readLedgerMutex       sync.RWMutex
+	readLedgerArgsForCall []struct {
+		arg1 executive.LedgerQuery
+	}
+	readLedgerReturns struct {
+		result1 []executive.LedgerEntry
+		result2 error
+	}
+	readLedgerReturnsOnCall map[int]struct {
+		result1 []executive.LedgerEntry
+		result2 error
+	}
+	ReadLedgerSeqStub        func() (int64, error)
+	readLedgerSeqMutex       sync.RWMutex
+	readLedgerSeqArgsForCall []struct {
+	}
+	readLedgerSeqReturns struct {
+		result1 int64
+		result2 error
+	}
+	readLedgerSeqReturnsOnCall map[int]struct {
+		result1 int64
+		result2 error
+	}
+	ReadMaintenanceStub        func() (executive.Maintenance, error)
+	readMaintenanceMutex       sync.RWMutex
+	readMaintenanceArgsForCall []struct {
+	}
+	readMaintenanceReturns struct {
+		result1 executive.Maintenance
+		result2 error
+	}
+	readMaintenanceReturnsOnCall map[int]struct {
+		result1 executive.Maintenance
+		result2 error
+	}
 	ReadRowStub        func(string, string, map[string]interface{}) (map[string]interface{}, error)
 	readRowMutex       sync.RWMutex
 	readRowArgsForCall []struct {
@@ -176,6 +492,20 @@ type FakeExecutiveInterface struct {
 		result1 map[string]interface{}
 		result2 error
 	}
+	ReadRowsStub        func(string, string, executive.RowsQuery, func(row map[string]interface{}) error) error
+	readRowsMutex       sync.RWMutex
+	readRowsArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 executive.RowsQuery
+		arg4 func(row map[string]interface{}) error
+	}
+	readRowsReturns struct {
+		result1 error
+	}
+	readRowsReturnsOnCall map[int]struct {
+		result1 error
+	}
 	ReadTableSizeLimitsStub        func() (limits.TableSizeLimits, error)
 	readTableSizeLimitsMutex       sync.RWMutex
 	readTableSizeLimitsArgsForCall []struct {
@@ -188,6 +518,83 @@ type FakeExecutiveInterface struct {
 		result1 limits.TableSizeLimits
 		result2 error
 	}
+	ReadTableSizeProjectionStub        func(schema.FamilyTable) (limits.TableSizeProjection, error)
+	readTableSizeProjectionMutex       sync.RWMutex
+	readTableSizeProjectionArgsForCall []struct {
+		arg1 schema.FamilyTable
+	}
+	readTableSizeProjectionReturns struct {
+		result1 limits.TableSizeProjection
+		result2 error
+	}
+	readTableSizeProjectionReturnsOnCall map[int]struct {
+		result1 limits.TableSizeProjection
+		result2 error
+	}
+	ReadTableTemplatesStub        func(string) ([]schema.TableTemplate, error)
+	readTableTemplatesMutex       sync.RWMutex
+	readTableTemplatesArgsForCall []struct {
+		arg1 string
+	}
+	readTableTemplatesReturns struct {
+		result1 []schema.TableTemplate
+		result2 error
+	}
+	readTableTemplatesReturnsOnCall map[int]struct {
+		result1 []schema.TableTemplate
+		result2 error
+	}
+	ReadWebhooksStub        func(string) ([]executive.Webhook, error)
+	readWebhooksMutex       sync.RWMutex
+	readWebhooksArgsForCall []struct {
+		arg1 string
+	}
+	readWebhooksReturns struct {
+		result1 []executive.Webhook
+		result2 error
+	}
+	readWebhooksReturnsOnCall map[int]struct {
+		result1 []executive.Webhook
+		result2 error
+	}
+	ReadWriterActivityStub        func(string) (executive.WriterActivity, error)
+	readWriterActivityMutex       sync.RWMutex
+	readWriterActivityArgsForCall []struct {
+		arg1 string
+	}
+	readWriterActivityReturns struct {
+		result1 executive.WriterActivity
+		result2 error
+	}
+	readWriterActivityReturnsOnCall map[int]struct {
+		result1 executive.WriterActivity
+		result2 error
+	}
+	ReadWriterGroupAuditStub        func(string) ([]executive.WriterGroupEvent, error)
+	readWriterGroupAuditMutex       sync.RWMutex
+	readWriterGroupAuditArgsForCall []struct {
+		arg1 string
+	}
+	readWriterGroupAuditReturns struct {
+		result1 []executive.WriterGroupEvent
+		result2 error
+	}
+	readWriterGroupAuditReturnsOnCall map[int]struct {
+		result1 []executive.WriterGroupEvent
+		result2 error
+	}
+	ReadWriterGroupsStub        func() ([]limits.WriterGroup, error)
+	readWriterGroupsMutex       sync.RWMutex
+	readWriterGroupsArgsForCall []struct {
+	}
+	readWriterGroupsReturns struct {
+		result1 []limits.WriterGroup
+		result2 error
+	}
+	readWriterGroupsReturnsOnCall map[int]struct {
+		result1 []limits.WriterGroup
+		result2 error
+	}
 	ReadWriterRateLimitsStub        func() (limits.WriterRateLimits, error)
 	readWriterRateLimitsMutex       sync.RWMutex
 	readWriterRateLimitsArgsForCall []struct {
@@ -200,6 +607,59 @@ type FakeExecutiveInterface struct {
 		result1 limits.WriterRateLimits
 		result2 error
 	}
+	ReadWriterRegistrationAuditStub        func(string) ([]executive.WriterRegistrationEvent, error)
+	readWriterRegistrationAuditMutex       sync.RWMutex
+	readWriterRegistrationAuditArgsForCall []struct {
+		arg1 string
+	}
+	readWriterRegistrationAuditReturns struct {
+		result1 []executive.WriterRegistrationEvent
+		result2 error
+	}
+	readWriterRegistrationAuditReturnsOnCall map[int]struct {
+		result1 []executive.WriterRegistrationEvent
+		result2 error
+	}
+	ReadWriterRegistrationsStub        func(string) ([]executive.WriterRegistration, error)
+	readWriterRegistrationsMutex       sync.RWMutex
+	readWriterRegistrationsArgsForCall []struct {
+		arg1 string
+	}
+	readWriterRegistrationsReturns struct {
+		result1 []executive.WriterRegistration
+		result2 error
+	}
+	readWriterRegistrationsReturnsOnCall map[int]struct {
+		result1 []executive.WriterRegistration
+		result2 error
+	}
+	ReadWritersStub        func() ([]executive.WriterInfo, error)
+	readWritersMutex       sync.RWMutex
+	readWritersArgsForCall []struct {
+	}
+	readWritersReturns struct {
+		result1 []executive.WriterInfo
+		result2 error
+	}
+	readWritersReturnsOnCall map[int]struct {
+		result1 []executive.WriterInfo
+		result2 error
+	}
+	RegisterWebhookStub        func(string, string, string) (executive.Webhook, error)
+	registerWebhookMutex       sync.RWMutex
+	registerWebhookArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}
+	registerWebhookReturns struct {
+		result1 executive.Webhook
+		result2 error
+	}
+	registerWebhookReturnsOnCall map[int]struct {
+		result1 executive.Webhook
+		result2 error
+	}
 	RegisterWriterStub        func(string, string) error
 	registerWriterMutex       sync.RWMutex
 	registerWriterArgsForCall []struct {
@@ -212,60 +672,172 @@ type FakeExecutiveInterface struct {
 	registerWriterReturnsOnCall map[int]struct {
 		result1 error
 	}
-	SetWriterCookieStub        func(string, string, []byte) error
-	setWriterCookieMutex       sync.RWMutex
-	setWriterCookieArgsForCall []struct {
+	RejectWriterRegistrationStub        func(string, executive.WriterReview) error
+	rejectWriterRegistrationMutex       sync.RWMutex
+	rejectWriterRegistrationArgsForCall []struct {
+		arg1 string
+		arg2 executive.WriterReview
+	}
+	rejectWriterRegistrationReturns struct {
+		result1 error
+	}
+	rejectWriterRegistrationReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RemoveWriterGroupMemberStub        func(string, string) error
+	removeWriterGroupMemberMutex       sync.RWMutex
+	removeWriterGroupMemberArgsForCall []struct {
 		arg1 string
 		arg2 string
-		arg3 []byte
 	}
-	setWriterCookieReturns struct {
+	removeWriterGroupMemberReturns struct {
 		result1 error
 	}
-	setWriterCookieReturnsOnCall map[int]struct {
+	removeWriterGroupMemberReturnsOnCall map[int]struct {
 		result1 error
 	}
-	TableSchemaStub        func(string, string) (*schema.Table, error)
-	tableSchemaMutex       sync.RWMutex
-	tableSchemaArgsForCall []struct {
+	RequestWriterRegistrationStub        func(string, string, string) (executive.WriterRegistration, error)
+	requestWriterRegistrationMutex       sync.RWMutex
+	requestWriterRegistrationArgsForCall []struct {
 		arg1 string
 		arg2 string
+		arg3 string
 	}
-	tableSchemaReturns struct {
-		result1 *schema.Table
+	requestWriterRegistrationReturns struct {
+		result1 executive.WriterRegistration
 		result2 error
 	}
-	tableSchemaReturnsOnCall map[int]struct {
-		result1 *schema.Table
+	requestWriterRegistrationReturnsOnCall map[int]struct {
+		result1 executive.WriterRegistration
 		result2 error
 	}
-	UpdateTableSizeLimitStub        func(limits.TableSizeLimit) error
-	updateTableSizeLimitMutex       sync.RWMutex
-	updateTableSizeLimitArgsForCall []struct {
-		arg1 limits.TableSizeLimit
+	SaveFamilyDefaultsStub        func(executive.FamilyDefaults) error
+	saveFamilyDefaultsMutex       sync.RWMutex
+	saveFamilyDefaultsArgsForCall []struct {
+		arg1 executive.FamilyDefaults
 	}
-	updateTableSizeLimitReturns struct {
+	saveFamilyDefaultsReturns struct {
 		result1 error
 	}
-	updateTableSizeLimitReturnsOnCall map[int]struct {
+	saveFamilyDefaultsReturnsOnCall map[int]struct {
 		result1 error
 	}
-	UpdateWriterRateLimitStub        func(limits.WriterRateLimit) error
-	updateWriterRateLimitMutex       sync.RWMutex
-	updateWriterRateLimitArgsForCall []struct {
-		arg1 limits.WriterRateLimit
+	SaveTableTemplateStub        func(schema.TableTemplate) error
+	saveTableTemplateMutex       sync.RWMutex
+	saveTableTemplateArgsForCall []struct {
+		arg1 schema.TableTemplate
 	}
-	updateWriterRateLimitReturns struct {
+	saveTableTemplateReturns struct {
 		result1 error
 	}
-	updateWriterRateLimitReturnsOnCall map[int]struct {
+	saveTableTemplateReturnsOnCall map[int]struct {
 		result1 error
 	}
-	invocations      map[string][][]interface{}
+	SetMaintenanceStub        func(executive.Maintenance) error
+	setMaintenanceMutex       sync.RWMutex
+	setMaintenanceArgsForCall []struct {
+		arg1 executive.Maintenance
+	}
+	setMaintenanceReturns struct {
+		result1 error
+	}
+	setMaintenanceReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SetTableReferencesStub        func(schema.FamilyTable, map[string]schema.FieldReference, bool) error
+	setTableReferencesMutex       sync.RWMutex
+	setTableReferencesArgsForCall []struct {
+		arg1 schema.FamilyTable
+		arg2 map[string]schema.FieldReference
+		arg3 bool
+	}
+	setTableReferencesReturns struct {
+		result1 error
+	}
+	setTableReferencesReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SetWriterCookieStub        func(string, string, []byte) error
+	setWriterCookieMutex       sync.RWMutex
+	setWriterCookieArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 []byte
+	}
+	setWriterCookieReturns struct {
+		result1 error
+	}
+	setWriterCookieReturnsOnCall map[int]struct {
+		result1 error
+	}
+	StartExportStub        func(string, string, executive.ExportRequest) (*executive.ExportJob, error)
+	startExportMutex       sync.RWMutex
+	startExportArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 executive.ExportRequest
+	}
+	startExportReturns struct {
+		result1 *executive.ExportJob
+		result2 error
+	}
+	startExportReturnsOnCall map[int]struct {
+		result1 *executive.ExportJob
+		result2 error
+	}
+	TableSchemaStub        func(string, string) (*schema.Table, error)
+	tableSchemaMutex       sync.RWMutex
+	tableSchemaArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	tableSchemaReturns struct {
+		result1 *schema.Table
+		result2 error
+	}
+	tableSchemaReturnsOnCall map[int]struct {
+		result1 *schema.Table
+		result2 error
+	}
+	UpdateTableSizeLimitStub        func(limits.TableSizeLimit) error
+	updateTableSizeLimitMutex       sync.RWMutex
+	updateTableSizeLimitArgsForCall []struct {
+		arg1 limits.TableSizeLimit
+	}
+	updateTableSizeLimitReturns struct {
+		result1 error
+	}
+	updateTableSizeLimitReturnsOnCall map[int]struct {
+		result1 error
+	}
+	UpdateWriterGroupStub        func(string, limits.RateLimit) error
+	updateWriterGroupMutex       sync.RWMutex
+	updateWriterGroupArgsForCall []struct {
+		arg1 string
+		arg2 limits.RateLimit
+	}
+	updateWriterGroupReturns struct {
+		result1 error
+	}
+	updateWriterGroupReturnsOnCall map[int]struct {
+		result1 error
+	}
+	UpdateWriterRateLimitStub        func(limits.WriterRateLimit) error
+	updateWriterRateLimitMutex       sync.RWMutex
+	updateWriterRateLimitArgsForCall []struct {
+		arg1 limits.WriterRateLimit
+	}
+	updateWriterRateLimitReturns struct {
+		result1 error
+	}
+	updateWriterRateLimitReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeExecutiveInterface) AddFields(arg1 string, arg2 string, arg3 []string, arg4 []schema.FieldType) error {
+func (fake *FakeExecutiveInterface) AddFields(arg1 string, arg2 string, arg3 []string, arg4 []schema.FieldType, arg5 map[string]schema.FieldOptions) error {
 	var arg3Copy []string
 	if arg3 != nil {
 		arg3Copy = make([]string, len(arg3))
@@ -283,13 +855,14 @@ func (fake *FakeExecutiveInterface) AddFields(arg1 string, arg2 string, arg3 []s
 		arg2 string
 		arg3 []string
 		arg4 []schema.FieldType
-	}{arg1, arg2, arg3Copy, arg4Copy})
+		arg5 map[string]schema.FieldOptions
+	}{arg1, arg2, arg3Copy, arg4Copy, arg5})
 	stub := fake.AddFieldsStub
 	fakeReturns := fake.addFieldsReturns
-	fake.recordInvocation("AddFields", []interface{}{arg1, arg2, arg3Copy, arg4Copy})
+	fake.recordInvocation("AddFields", []interface{}{arg1, arg2, arg3Copy, arg4Copy, arg5})
 	fake.addFieldsMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3, arg4)
+		return stub(arg1, arg2, arg3, arg4, arg5)
 	}
 	if specificReturn {
 		return ret.result1
@@ -303,17 +876,17 @@ func (fake *FakeExecutiveInterface) AddFieldsCallCount() int {
 	return len(fake.addFieldsArgsForCall)
 }
 
-func (fake *FakeExecutiveInterface) AddFieldsCalls(stub func(string, string, []string, []schema.FieldType) error) {
+func (fake *FakeExecutiveInterface) AddFieldsCalls(stub func(string, string, []string, []schema.FieldType, map[string]schema.FieldOptions) error) {
 	fake.addFieldsMutex.Lock()
 	defer fake.addFieldsMutex.Unlock()
 	fake.AddFieldsStub = stub
 }
 
-func (fake *FakeExecutiveInterface) AddFieldsArgsForCall(i int) (string, string, []string, []schema.FieldType) {
+func (fake *FakeExecutiveInterface) AddFieldsArgsForCall(i int) (string, string, []string, []schema.FieldType, map[string]schema.FieldOptions) {
 	fake.addFieldsMutex.RLock()
 	defer fake.addFieldsMutex.RUnlock()
 	argsForCall := fake.addFieldsArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
 }
 
 func (fake *FakeExecutiveInterface) AddFieldsReturns(result1 error) {
@@ -339,18 +912,20 @@ func (fake *FakeExecutiveInterface) AddFieldsReturnsOnCall(i int, result1 error)
 	}{result1}
 }
 
-func (fake *FakeExecutiveInterface) ClearTable(arg1 schema.FamilyTable) error {
-	fake.clearTableMutex.Lock()
-	ret, specificReturn := fake.clearTableReturnsOnCall[len(fake.clearTableArgsForCall)]
-	fake.clearTableArgsForCall = append(fake.clearTableArgsForCall, struct {
-		arg1 schema.FamilyTable
-	}{arg1})
-	stub := fake.ClearTableStub
-	fakeReturns := fake.clearTableReturns
-	fake.recordInvocation("ClearTable", []interface{}{arg1})
-	fake.clearTableMutex.Unlock()
+func (fake *FakeExecutiveInterface) AddWriterGroupMember(arg1 string, arg2 string, arg3 string) error {
+	fake.addWriterGroupMemberMutex.Lock()
+	ret, specificReturn := fake.addWriterGroupMemberReturnsOnCall[len(fake.addWriterGroupMemberArgsForCall)]
+	fake.addWriterGroupMemberArgsForCall = append(fake.addWriterGroupMemberArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.AddWriterGroupMemberStub
+	fakeReturns := fake.addWriterGroupMemberReturns
+	fake.recordInvocation("AddWriterGroupMember", []interface{}{arg1, arg2, arg3})
+	fake.addWriterGroupMemberMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1
@@ -358,60 +933,64 @@ func (fake *FakeExecutiveInterface) ClearTable(arg1 schema.FamilyTable) error {
 	return fakeReturns.result1
 }
 
-func (fake *FakeExecutiveInterface) ClearTableCallCount() int {
-	fake.clearTableMutex.RLock()
-	defer fake.clearTableMutex.RUnlock()
-	return len(fake.clearTableArgsForCall)
+func (fake *FakeExecutiveInterface) AddWriterGroupMemberCallCount() int {
+	fake.addWriterGroupMemberMutex.RLock()
+	defer fake.addWriterGroupMemberMutex.RUnlock()
+	return len(fake.addWriterGroupMemberArgsForCall)
 }
 
-func (fake *FakeExecutiveInterface) ClearTableCalls(stub func(schema.FamilyTable) error) {
-	fake.clearTableMutex.Lock()
-	defer fake.clearTableMutex.Unlock()
-	fake.ClearTableStub = stub
+func (fake *FakeExecutiveInterface) AddWriterGroupMemberCalls(stub func(string, string, string) error) {
+	fake.addWriterGroupMemberMutex.Lock()
+	defer fake.addWriterGroupMemberMutex.Unlock()
+	fake.AddWriterGroupMemberStub = stub
 }
 
-func (fake *FakeExecutiveInterface) ClearTableArgsForCall(i int) schema.FamilyTable {
-	fake.clearTableMutex.RLock()
-	defer fake.clearTableMutex.RUnlock()
-	argsForCall := fake.clearTableArgsForCall[i]
-	return argsForCall.arg1
+func (fake *FakeExecutiveInterface) AddWriterGroupMemberArgsForCall(i int) (string, string, string) {
+	fake.addWriterGroupMemberMutex.RLock()
+	defer fake.addWriterGroupMemberMutex.RUnlock()
+	argsForCall := fake.addWriterGroupMemberArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
-func (fake *FakeExecutiveInterface) ClearTableReturns(result1 error) {
-	fake.clearTableMutex.Lock()
-	defer fake.clearTableMutex.Unlock()
-	fake.ClearTableStub = nil
-	fake.clearTableReturns = struct {
+func (fake *FakeExecutiveInterface) AddWriterGroupMemberReturns(result1 error) {
+	fake.addWriterGroupMemberMutex.Lock()
+	defer fake.addWriterGroupMemberMutex.Unlock()
+	fake.AddWriterGroupMemberStub = nil
+	fake.addWriterGroupMemberReturns = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *FakeExecutiveInterface) ClearTableReturnsOnCall(i int, result1 error) {
-	fake.clearTableMutex.Lock()
-	defer fake.clearTableMutex.Unlock()
-	fake.ClearTableStub = nil
-	if fake.clearTableReturnsOnCall == nil {
-		fake.clearTableReturnsOnCall = make(map[int]struct {
+func (fake *FakeExecutiveInterface) AddWriterGroupMemberReturnsOnCall(i int, result1 error) {
+	fake.addWriterGroupMemberMutex.Lock()
+	defer fake.addWriterGroupMemberMutex.Unlock()
+	fake.AddWriterGroupMemberStub = nil
+	if fake.addWriterGroupMemberReturnsOnCall == nil {
+		fake.addWriterGroupMemberReturnsOnCall = make(map[int]struct {
 			result1 error
 		})
 	}
-	fake.clearTableReturnsOnCall[i] = struct {
+	fake.addWriterGroupMemberReturnsOnCall[i] = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *FakeExecutiveInterface) CreateFamily(arg1 string) error {
-	fake.createFamilyMutex.Lock()
-	ret, specificReturn := fake.createFamilyReturnsOnCall[len(fake.createFamilyArgsForCall)]
-	fake.createFamilyArgsForCall = append(fake.createFamilyArgsForCall, struct {
+func (fake *FakeExecutiveInterface) AlterField(arg1 string, arg2 string, arg3 string, arg4 string, arg5 schema.FieldType) error {
+	fake.alterFieldMutex.Lock()
+	ret, specificReturn := fake.alterFieldReturnsOnCall[len(fake.alterFieldArgsForCall)]
+	fake.alterFieldArgsForCall = append(fake.alterFieldArgsForCall, struct {
 		arg1 string
-	}{arg1})
-	stub := fake.CreateFamilyStub
-	fakeReturns := fake.createFamilyReturns
-	fake.recordInvocation("CreateFamily", []interface{}{arg1})
-	fake.createFamilyMutex.Unlock()
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 schema.FieldType
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.AlterFieldStub
+	fakeReturns := fake.alterFieldReturns
+	fake.recordInvocation("AlterField", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.alterFieldMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2, arg3, arg4, arg5)
 	}
 	if specificReturn {
 		return ret.result1
@@ -419,206 +998,200 @@ func (fake *FakeExecutiveInterface) CreateFamily(arg1 string) error {
 	return fakeReturns.result1
 }
 
-func (fake *FakeExecutiveInterface) CreateFamilyCallCount() int {
-	fake.createFamilyMutex.RLock()
-	defer fake.createFamilyMutex.RUnlock()
-	return len(fake.createFamilyArgsForCall)
+func (fake *FakeExecutiveInterface) AlterFieldCallCount() int {
+	fake.alterFieldMutex.RLock()
+	defer fake.alterFieldMutex.RUnlock()
+	return len(fake.alterFieldArgsForCall)
 }
 
-func (fake *FakeExecutiveInterface) CreateFamilyCalls(stub func(string) error) {
-	fake.createFamilyMutex.Lock()
-	defer fake.createFamilyMutex.Unlock()
-	fake.CreateFamilyStub = stub
+func (fake *FakeExecutiveInterface) AlterFieldCalls(stub func(string, string, string, string, schema.FieldType) error) {
+	fake.alterFieldMutex.Lock()
+	defer fake.alterFieldMutex.Unlock()
+	fake.AlterFieldStub = stub
 }
 
-func (fake *FakeExecutiveInterface) CreateFamilyArgsForCall(i int) string {
-	fake.createFamilyMutex.RLock()
-	defer fake.createFamilyMutex.RUnlock()
-	argsForCall := fake.createFamilyArgsForCall[i]
-	return argsForCall.arg1
+func (fake *FakeExecutiveInterface) AlterFieldArgsForCall(i int) (string, string, string, string, schema.FieldType) {
+	fake.alterFieldMutex.RLock()
+	defer fake.alterFieldMutex.RUnlock()
+	argsForCall := fake.alterFieldArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
 }
 
-func (fake *FakeExecutiveInterface) CreateFamilyReturns(result1 error) {
-	fake.createFamilyMutex.Lock()
-	defer fake.createFamilyMutex.Unlock()
-	fake.CreateFamilyStub = nil
-	fake.createFamilyReturns = struct {
+func (fake *FakeExecutiveInterface) AlterFieldReturns(result1 error) {
+	fake.alterFieldMutex.Lock()
+	defer fake.alterFieldMutex.Unlock()
+	fake.AlterFieldStub = nil
+	fake.alterFieldReturns = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *FakeExecutiveInterface) CreateFamilyReturnsOnCall(i int, result1 error) {
-	fake.createFamilyMutex.Lock()
-	defer fake.createFamilyMutex.Unlock()
-	fake.CreateFamilyStub = nil
-	if fake.createFamilyReturnsOnCall == nil {
-		fake.createFamilyReturnsOnCall = make(map[int]struct {
+func (fake *FakeExecutiveInterface) AlterFieldReturnsOnCall(i int, result1 error) {
+	fake.alterFieldMutex.Lock()
+	defer fake.alterFieldMutex.Unlock()
+	fake.AlterFieldStub = nil
+	if fake.alterFieldReturnsOnCall == nil {
+		fake.alterFieldReturnsOnCall = make(map[int]struct {
 			result1 error
 		})
 	}
-	fake.createFamilyReturnsOnCall[i] = struct {
+	fake.alterFieldReturnsOnCall[i] = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *FakeExecutiveInterface) CreateTable(arg1 string, arg2 string, arg3 []string, arg4 []schema.FieldType, arg5 []string) error {
-	var arg3Copy []string
-	if arg3 != nil {
-		arg3Copy = make([]string, len(arg3))
-		copy(arg3Copy, arg3)
-	}
-	var arg4Copy []schema.FieldType
-	if arg4 != nil {
-		arg4Copy = make([]schema.FieldType, len(arg4))
-		copy(arg4Copy, arg4)
-	}
-	var arg5Copy []string
-	if arg5 != nil {
-		arg5Copy = make([]string, len(arg5))
-		copy(arg5Copy, arg5)
+func (fake *FakeExecutiveInterface) AnalyzeTables(arg1 []schema.FamilyTable) ([]executive.TableAnalysis, error) {
+	var arg1Copy []schema.FamilyTable
+	if arg1 != nil {
+		arg1Copy = make([]schema.FamilyTable, len(arg1))
+		copy(arg1Copy, arg1)
 	}
-	fake.createTableMutex.Lock()
-	ret, specificReturn := fake.createTableReturnsOnCall[len(fake.createTableArgsForCall)]
-	fake.createTableArgsForCall = append(fake.createTableArgsForCall, struct {
-		arg1 string
-		arg2 string
-		arg3 []string
-		arg4 []schema.FieldType
-		arg5 []string
-	}{arg1, arg2, arg3Copy, arg4Copy, arg5Copy})
-	stub := fake.CreateTableStub
-	fakeReturns := fake.createTableReturns
-	fake.recordInvocation("CreateTable", []interface{}{arg1, arg2, arg3Copy, arg4Copy, arg5Copy})
-	fake.createTableMutex.Unlock()
+	fake.analyzeTablesMutex.Lock()
+	ret, specificReturn := fake.analyzeTablesReturnsOnCall[len(fake.analyzeTablesArgsForCall)]
+	fake.analyzeTablesArgsForCall = append(fake.analyzeTablesArgsForCall, struct {
+		arg1 []schema.FamilyTable
+	}{arg1Copy})
+	stub := fake.AnalyzeTablesStub
+	fakeReturns := fake.analyzeTablesReturns
+	fake.recordInvocation("AnalyzeTables", []interface{}{arg1Copy})
+	fake.analyzeTablesMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3, arg4, arg5)
+		return stub(arg1)
 	}
 	if specificReturn {
-		return ret.result1
+		return ret.result1, ret.result2
 	}
-	return fakeReturns.result1
+	return fakeReturns.result1, fakeReturns.result2
 }
 
-func (fake *FakeExecutiveInterface) CreateTableCallCount() int {
-	fake.createTableMutex.RLock()
-	defer fake.createTableMutex.RUnlock()
-	return len(fake.createTableArgsForCall)
+func (fake *FakeExecutiveInterface) AnalyzeTablesCallCount() int {
+	fake.analyzeTablesMutex.RLock()
+	defer fake.analyzeTablesMutex.RUnlock()
+	return len(fake.analyzeTablesArgsForCall)
 }
 
-func (fake *FakeExecutiveInterface) CreateTableCalls(stub func(string, string, []string, []schema.FieldType, []string) error) {
-	fake.createTableMutex.Lock()
-	defer fake.createTableMutex.Unlock()
-	fake.CreateTableStub = stub
+func (fake *FakeExecutiveInterface) AnalyzeTablesCalls(stub func([]schema.FamilyTable) ([]executive.TableAnalysis, error)) {
+	fake.analyzeTablesMutex.Lock()
+	defer fake.analyzeTablesMutex.Unlock()
+	fake.AnalyzeTablesStub = stub
 }
 
-func (fake *FakeExecutiveInterface) CreateTableArgsForCall(i int) (string, string, []string, []schema.FieldType, []string) {
-	fake.createTableMutex.RLock()
-	defer fake.createTableMutex.RUnlock()
-	argsForCall := fake.createTableArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+func (fake *FakeExecutiveInterface) AnalyzeTablesArgsForCall(i int) []schema.FamilyTable {
+	fake.analyzeTablesMutex.RLock()
+	defer fake.analyzeTablesMutex.RUnlock()
+	argsForCall := fake.analyzeTablesArgsForCall[i]
+	return argsForCall.arg1
 }
 
-func (fake *FakeExecutiveInterface) CreateTableReturns(result1 error) {
-	fake.createTableMutex.Lock()
-	defer fake.createTableMutex.Unlock()
-	fake.CreateTableStub = nil
-	fake.createTableReturns = struct {
-		result1 error
-	}{result1}
+func (fake *FakeExecutiveInterface) AnalyzeTablesReturns(result1 []executive.TableAnalysis, result2 error) {
+	fake.analyzeTablesMutex.Lock()
+	defer fake.analyzeTablesMutex.Unlock()
+	fake.AnalyzeTablesStub = nil
+	fake.analyzeTablesReturns = struct {
+		result1 []executive.TableAnalysis
+		result2 error
+	}{result1, result2}
 }
 
-func (fake *FakeExecutiveInterface) CreateTableReturnsOnCall(i int, result1 error) {
-	fake.createTableMutex.Lock()
-	defer fake.createTableMutex.Unlock()
-	fake.CreateTableStub = nil
-	if fake.createTableReturnsOnCall == nil {
-		fake.createTableReturnsOnCall = make(map[int]struct {
-			result1 error
+func (fake *FakeExecutiveInterface) AnalyzeTablesReturnsOnCall(i int, result1 []executive.TableAnalysis, result2 error) {
+	fake.analyzeTablesMutex.Lock()
+	defer fake.analyzeTablesMutex.Unlock()
+	fake.AnalyzeTablesStub = nil
+	if fake.analyzeTablesReturnsOnCall == nil {
+		fake.analyzeTablesReturnsOnCall = make(map[int]struct {
+			result1 []executive.TableAnalysis
+			result2 error
 		})
 	}
-	fake.createTableReturnsOnCall[i] = struct {
-		result1 error
-	}{result1}
+	fake.analyzeTablesReturnsOnCall[i] = struct {
+		result1 []executive.TableAnalysis
+		result2 error
+	}{result1, result2}
 }
 
-func (fake *FakeExecutiveInterface) CreateTables(arg1 []schema.Table) error {
+func (fake *FakeExecutiveInterface) ApplySchema(arg1 []schema.Table, arg2 bool) (executive.SchemaPlan, error) {
 	var arg1Copy []schema.Table
 	if arg1 != nil {
 		arg1Copy = make([]schema.Table, len(arg1))
 		copy(arg1Copy, arg1)
 	}
-	fake.createTablesMutex.Lock()
-	ret, specificReturn := fake.createTablesReturnsOnCall[len(fake.createTablesArgsForCall)]
-	fake.createTablesArgsForCall = append(fake.createTablesArgsForCall, struct {
+	fake.applySchemaMutex.Lock()
+	ret, specificReturn := fake.applySchemaReturnsOnCall[len(fake.applySchemaArgsForCall)]
+	fake.applySchemaArgsForCall = append(fake.applySchemaArgsForCall, struct {
 		arg1 []schema.Table
-	}{arg1Copy})
-	stub := fake.CreateTablesStub
-	fakeReturns := fake.createTablesReturns
-	fake.recordInvocation("CreateTables", []interface{}{arg1Copy})
-	fake.createTablesMutex.Unlock()
+		arg2 bool
+	}{arg1Copy, arg2})
+	stub := fake.ApplySchemaStub
+	fakeReturns := fake.applySchemaReturns
+	fake.recordInvocation("ApplySchema", []interface{}{arg1Copy, arg2})
+	fake.applySchemaMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
-		return ret.result1
+		return ret.result1, ret.result2
 	}
-	return fakeReturns.result1
+	return fakeReturns.result1, fakeReturns.result2
 }
 
-func (fake *FakeExecutiveInterface) CreateTablesCallCount() int {
-	fake.createTablesMutex.RLock()
-	defer fake.createTablesMutex.RUnlock()
-	return len(fake.createTablesArgsForCall)
+func (fake *FakeExecutiveInterface) ApplySchemaCallCount() int {
+	fake.applySchemaMutex.RLock()
+	defer fake.applySchemaMutex.RUnlock()
+	return len(fake.applySchemaArgsForCall)
 }
 
-func (fake *FakeExecutiveInterface) CreateTablesCalls(stub func([]schema.Table) error) {
-	fake.createTablesMutex.Lock()
-	defer fake.createTablesMutex.Unlock()
-	fake.CreateTablesStub = stub
+func (fake *FakeExecutiveInterface) ApplySchemaCalls(stub func([]schema.Table, bool) (executive.SchemaPlan, error)) {
+	fake.applySchemaMutex.Lock()
+	defer fake.applySchemaMutex.Unlock()
+	fake.ApplySchemaStub = stub
 }
 
-func (fake *FakeExecutiveInterface) CreateTablesArgsForCall(i int) []schema.Table {
-	fake.createTablesMutex.RLock()
-	defer fake.createTablesMutex.RUnlock()
-	argsForCall := fake.createTablesArgsForCall[i]
-	return argsForCall.arg1
+func (fake *FakeExecutiveInterface) ApplySchemaArgsForCall(i int) ([]schema.Table, bool) {
+	fake.applySchemaMutex.RLock()
+	defer fake.applySchemaMutex.RUnlock()
+	argsForCall := fake.applySchemaArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
 }
 
-func (fake *FakeExecutiveInterface) CreateTablesReturns(result1 error) {
-	fake.createTablesMutex.Lock()
-	defer fake.createTablesMutex.Unlock()
-	fake.CreateTablesStub = nil
-	fake.createTablesReturns = struct {
-		result1 error
-	}{result1}
+func (fake *FakeExecutiveInterface) ApplySchemaReturns(result1 executive.SchemaPlan, result2 error) {
+	fake.applySchemaMutex.Lock()
+	defer fake.applySchemaMutex.Unlock()
+	fake.ApplySchemaStub = nil
+	fake.applySchemaReturns = struct {
+		result1 executive.SchemaPlan
+		result2 error
+	}{result1, result2}
 }
 
-func (fake *FakeExecutiveInterface) CreateTablesReturnsOnCall(i int, result1 error) {
-	fake.createTablesMutex.Lock()
-	defer fake.createTablesMutex.Unlock()
-	fake.CreateTablesStub = nil
-	if fake.createTablesReturnsOnCall == nil {
-		fake.createTablesReturnsOnCall = make(map[int]struct {
-			result1 error
+func (fake *FakeExecutiveInterface) ApplySchemaReturnsOnCall(i int, result1 executive.SchemaPlan, result2 error) {
+	fake.applySchemaMutex.Lock()
+	defer fake.applySchemaMutex.Unlock()
+	fake.ApplySchemaStub = nil
+	if fake.applySchemaReturnsOnCall == nil {
+		fake.applySchemaReturnsOnCall = make(map[int]struct {
+			result1 executive.SchemaPlan
+			result2 error
 		})
 	}
-	fake.createTablesReturnsOnCall[i] = struct {
-		result1 error
-	}{result1}
+	fake.applySchemaReturnsOnCall[i] = struct {
+		result1 executive.SchemaPlan
+		result2 error
+	}{result1, result2}
 }
 
-func (fake *FakeExecutiveInterface) DeleteTableSizeLimit(arg1 schema.FamilyTable) error {
-	fake.deleteTableSizeLimitMutex.Lock()
-	ret, specificReturn := fake.deleteTableSizeLimitReturnsOnCall[len(fake.deleteTableSizeLimitArgsForCall)]
-	fake.deleteTableSizeLimitArgsForCall = append(fake.deleteTableSizeLimitArgsForCall, struct {
-		arg1 schema.FamilyTable
-	}{arg1})
-	stub := fake.DeleteTableSizeLimitStub
-	fakeReturns := fake.deleteTableSizeLimitReturns
-	fake.recordInvocation("DeleteTableSizeLimit", []interface{}{arg1})
-	fake.deleteTableSizeLimitMutex.Unlock()
+func (fake *FakeExecutiveInterface) ApproveWriterRegistration(arg1 string, arg2 executive.WriterReview) error {
+	fake.approveWriterRegistrationMutex.Lock()
+	ret, specificReturn := fake.approveWriterRegistrationReturnsOnCall[len(fake.approveWriterRegistrationArgsForCall)]
+	fake.approveWriterRegistrationArgsForCall = append(fake.approveWriterRegistrationArgsForCall, struct {
+		arg1 string
+		arg2 executive.WriterReview
+	}{arg1, arg2})
+	stub := fake.ApproveWriterRegistrationStub
+	fakeReturns := fake.approveWriterRegistrationReturns
+	fake.recordInvocation("ApproveWriterRegistration", []interface{}{arg1, arg2})
+	fake.approveWriterRegistrationMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
@@ -626,119 +1199,187 @@ func (fake *FakeExecutiveInterface) DeleteTableSizeLimit(arg1 schema.FamilyTable
 	return fakeReturns.result1
 }
 
-func (fake *FakeExecutiveInterface) DeleteTableSizeLimitCallCount() int {
-	fake.deleteTableSizeLimitMutex.RLock()
-	defer fake.deleteTableSizeLimitMutex.RUnlock()
-	return len(fake.deleteTableSizeLimitArgsForCall)
+func (fake *FakeExecutiveInterface) ApproveWriterRegistrationCallCount() int {
+	fake.approveWriterRegistrationMutex.RLock()
+	defer fake.approveWriterRegistrationMutex.RUnlock()
+	return len(fake.approveWriterRegistrationArgsForCall)
 }
 
-func (fake *FakeExecutiveInterface) DeleteTableSizeLimitCalls(stub func(schema.FamilyTable) error) {
-	fake.deleteTableSizeLimitMutex.Lock()
-	defer fake.deleteTableSizeLimitMutex.Unlock()
-	fake.DeleteTableSizeLimitStub = stub
+func (fake *FakeExecutiveInterface) ApproveWriterRegistrationCalls(stub func(string, executive.WriterReview) error) {
+	fake.approveWriterRegistrationMutex.Lock()
+	defer fake.approveWriterRegistrationMutex.Unlock()
+	fake.ApproveWriterRegistrationStub = stub
 }
 
-func (fake *FakeExecutiveInterface) DeleteTableSizeLimitArgsForCall(i int) schema.FamilyTable {
-	fake.deleteTableSizeLimitMutex.RLock()
-	defer fake.deleteTableSizeLimitMutex.RUnlock()
-	argsForCall := fake.deleteTableSizeLimitArgsForCall[i]
-	return argsForCall.arg1
+func (fake *FakeExecutiveInterface) ApproveWriterRegistrationArgsForCall(i int) (string, executive.WriterReview) {
+	fake.approveWriterRegistrationMutex.RLock()
+	defer fake.approveWriterRegistrationMutex.RUnlock()
+	argsForCall := fake.approveWriterRegistrationArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
 }
 
-func (fake *FakeExecutiveInterface) DeleteTableSizeLimitReturns(result1 error) {
-	fake.deleteTableSizeLimitMutex.Lock()
-	defer fake.deleteTableSizeLimitMutex.Unlock()
-	fake.DeleteTableSizeLimitStub = nil
-	fake.deleteTableSizeLimitReturns = struct {
+func (fake *FakeExecutiveInterface) ApproveWriterRegistrationReturns(result1 error) {
+	fake.approveWriterRegistrationMutex.Lock()
+	defer fake.approveWriterRegistrationMutex.Unlock()
+	fake.ApproveWriterRegistrationStub = nil
+	fake.approveWriterRegistrationReturns = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *FakeExecutiveInterface) DeleteTableSizeLimitReturnsOnCall(i int, result1 error) {
-	fake.deleteTableSizeLimitMutex.Lock()
-	defer fake.deleteTableSizeLimitMutex.Unlock()
-	fake.DeleteTableSizeLimitStub = nil
-	if fake.deleteTableSizeLimitReturnsOnCall == nil {
-		fake.deleteTableSizeLimitReturnsOnCall = make(map[int]struct {
+func (fake *FakeExecutiveInterface) ApproveWriterRegistrationReturnsOnCall(i int, result1 error) {
+	fake.approveWriterRegistrationMutex.Lock()
+	defer fake.approveWriterRegistrationMutex.Unlock()
+	fake.ApproveWriterRegistrationStub = nil
+	if fake.approveWriterRegistrationReturnsOnCall == nil {
+		fake.approveWriterRegistrationReturnsOnCall = make(map[int]struct {
 			result1 error
 		})
 	}
-	fake.deleteTableSizeLimitReturnsOnCall[i] = struct {
+	fake.approveWriterRegistrationReturnsOnCall[i] = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *FakeExecutiveInterface) DeleteWriterRateLimit(arg1 string) error {
-	fake.deleteWriterRateLimitMutex.Lock()
-	ret, specificReturn := fake.deleteWriterRateLimitReturnsOnCall[len(fake.deleteWriterRateLimitArgsForCall)]
-	fake.deleteWriterRateLimitArgsForCall = append(fake.deleteWriterRateLimitArgsForCall, struct {
+func (fake *FakeExecutiveInterface) CheckAPIToken(arg1 string) (executive.APIToken, error) {
+	fake.checkAPITokenMutex.Lock()
+	ret, specificReturn := fake.checkAPITokenReturnsOnCall[len(fake.checkAPITokenArgsForCall)]
+	fake.checkAPITokenArgsForCall = append(fake.checkAPITokenArgsForCall, struct {
 		arg1 string
 	}{arg1})
-	stub := fake.DeleteWriterRateLimitStub
-	fakeReturns := fake.deleteWriterRateLimitReturns
-	fake.recordInvocation("DeleteWriterRateLimit", []interface{}{arg1})
-	fake.deleteWriterRateLimitMutex.Unlock()
+	stub := fake.CheckAPITokenStub
+	fakeReturns := fake.checkAPITokenReturns
+	fake.recordInvocation("CheckAPIToken", []interface{}{arg1})
+	fake.checkAPITokenMutex.Unlock()
 	if stub != nil {
 		return stub(arg1)
 	}
 	if specificReturn {
-		return ret.result1
+		return ret.result1, ret.result2
 	}
-	return fakeReturns.result1
+	return fakeReturns.result1, fakeReturns.result2
 }
 
-func (fake *FakeExecutiveInterface) DeleteWriterRateLimitCallCount() int {
-	fake.deleteWriterRateLimitMutex.RLock()
-	defer fake.deleteWriterRateLimitMutex.RUnlock()
-	return len(fake.deleteWriterRateLimitArgsForCall)
+func (fake *FakeExecutiveInterface) CheckAPITokenCallCount() int {
+	fake.checkAPITokenMutex.RLock()
+	defer fake.checkAPITokenMutex.RUnlock()
+	return len(fake.checkAPITokenArgsForCall)
 }
 
-func (fake *FakeExecutiveInterface) DeleteWriterRateLimitCalls(stub func(string) error) {
-	fake.deleteWriterRateLimitMutex.Lock()
-	defer fake.deleteWriterRateLimitMutex.Unlock()
-	fake.DeleteWriterRateLimitStub = stub
+func (fake *FakeExecutiveInterface) CheckAPITokenCalls(stub func(string) (executive.APIToken, error)) {
+	fake.checkAPITokenMutex.Lock()
+	defer fake.checkAPITokenMutex.Unlock()
+	fake.CheckAPITokenStub = stub
 }
 
-func (fake *FakeExecutiveInterface) DeleteWriterRateLimitArgsForCall(i int) string {
-	fake.deleteWriterRateLimitMutex.RLock()
-	defer fake.deleteWriterRateLimitMutex.RUnlock()
-	argsForCall := fake.deleteWriterRateLimitArgsForCall[i]
+func (fake *FakeExecutiveInterface) CheckAPITokenArgsForCall(i int) string {
+	fake.checkAPITokenMutex.RLock()
+	defer fake.checkAPITokenMutex.RUnlock()
+	argsForCall := fake.checkAPITokenArgsForCall[i]
 	return argsForCall.arg1
 }
 
-func (fake *FakeExecutiveInterface) DeleteWriterRateLimitReturns(result1 error) {
-	fake.deleteWriterRateLimitMutex.Lock()
-	defer fake.deleteWriterRateLimitMutex.Unlock()
-	fake.DeleteWriterRateLimitStub = nil
-	fake.deleteWriterRateLimitReturns = struct {
-		result1 error
-	}{result1}
+func (fake *FakeExecutiveInterface) CheckAPITokenReturns(result1 executive.APIToken, result2 error) {
+	fake.checkAPITokenMutex.Lock()
+	defer fake.checkAPITokenMutex.Unlock()
+	fake.CheckAPITokenStub = nil
+	fake.checkAPITokenReturns = struct {
+		result1 executive.APIToken
+		result2 error
+	}{result1, result2}
 }
 
-func (fake *FakeExecutiveInterface) DeleteWriterRateLimitReturnsOnCall(i int, result1 error) {
-	fake.deleteWriterRateLimitMutex.Lock()
-	defer fake.deleteWriterRateLimitMutex.Unlock()
-	fake.DeleteWriterRateLimitStub = nil
-	if fake.deleteWriterRateLimitReturnsOnCall == nil {
-		fake.deleteWriterRateLimitReturnsOnCall = make(map[int]struct {
-			result1 error
+func (fake *FakeExecutiveInterface) CheckAPITokenReturnsOnCall(i int, result1 executive.APIToken, result2 error) {
+	fake.checkAPITokenMutex.Lock()
+	defer fake.checkAPITokenMutex.Unlock()
+	fake.CheckAPITokenStub = nil
+	if fake.checkAPITokenReturnsOnCall == nil {
+		fake.checkAPITokenReturnsOnCall = make(map[int]struct {
+			result1 executive.APIToken
+			result2 error
 		})
 	}
-	fake.deleteWriterRateLimitReturnsOnCall[i] = struct {
-		result1 error
-	}{result1}
+	fake.checkAPITokenReturnsOnCall[i] = struct {
+		result1 executive.APIToken
+		result2 error
+	}{result1, result2}
 }
 
-func (fake *FakeExecutiveInterface) DropTable(arg1 schema.FamilyTable) error {
-	fake.dropTableMutex.Lock()
-	ret, specificReturn := fake.dropTableReturnsOnCall[len(fake.dropTableArgsForCall)]
-	fake.dropTableArgsForCall = append(fake.dropTableArgsForCall, struct {
+func (fake *FakeExecutiveInterface) CheckWriterSecret(arg1 string, arg2 string) (bool, error) {
+	fake.checkWriterSecretMutex.Lock()
+	ret, specificReturn := fake.checkWriterSecretReturnsOnCall[len(fake.checkWriterSecretArgsForCall)]
+	fake.checkWriterSecretArgsForCall = append(fake.checkWriterSecretArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.CheckWriterSecretStub
+	fakeReturns := fake.checkWriterSecretReturns
+	fake.recordInvocation("CheckWriterSecret", []interface{}{arg1, arg2})
+	fake.checkWriterSecretMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeExecutiveInterface) CheckWriterSecretCallCount() int {
+	fake.checkWriterSecretMutex.RLock()
+	defer fake.checkWriterSecretMutex.RUnlock()
+	return len(fake.checkWriterSecretArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) CheckWriterSecretCalls(stub func(string, string) (bool, error)) {
+	fake.checkWriterSecretMutex.Lock()
+	defer fake.checkWriterSecretMutex.Unlock()
+	fake.CheckWriterSecretStub = stub
+}
+
+func (fake *FakeExecutiveInterface) CheckWriterSecretArgsForCall(i int) (string, string) {
+	fake.checkWriterSecretMutex.RLock()
+	defer fake.checkWriterSecretMutex.RUnlock()
+	argsForCall := fake.checkWriterSecretArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeExecutiveInterface) CheckWriterSecretReturns(result1 bool, result2 error) {
+	fake.checkWriterSecretMutex.Lock()
+	defer fake.checkWriterSecretMutex.Unlock()
+	fake.CheckWriterSecretStub = nil
+	fake.checkWriterSecretReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) CheckWriterSecretReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.checkWriterSecretMutex.Lock()
+	defer fake.checkWriterSecretMutex.Unlock()
+	fake.CheckWriterSecretStub = nil
+	if fake.checkWriterSecretReturnsOnCall == nil {
+		fake.checkWriterSecretReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.checkWriterSecretReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ClearTable(arg1 schema.FamilyTable) error {
+	fake.clearTableMutex.Lock()
+	ret, specificReturn := fake.clearTableReturnsOnCall[len(fake.clearTableArgsForCall)]
+	fake.clearTableArgsForCall = append(fake.clearTableArgsForCall, struct {
 		arg1 schema.FamilyTable
 	}{arg1})
-	stub := fake.DropTableStub
-	fakeReturns := fake.dropTableReturns
-	fake.recordInvocation("DropTable", []interface{}{arg1})
-	fake.dropTableMutex.Unlock()
+	stub := fake.ClearTableStub
+	fakeReturns := fake.clearTableReturns
+	fake.recordInvocation("ClearTable", []interface{}{arg1})
+	fake.clearTableMutex.Unlock()
 	if stub != nil {
 		return stub(arg1)
 	}
@@ -748,60 +1389,65 @@ func (fake *FakeExecutiveInterface) DropTable(arg1 schema.FamilyTable) error {
 	return fakeReturns.result1
 }
 
-func (fake *FakeExecutiveInterface) DropTableCallCount() int {
-	fake.dropTableMutex.RLock()
-	defer fake.dropTableMutex.RUnlock()
-	return len(fake.dropTableArgsForCall)
+func (fake *FakeExecutiveInterface) ClearTableCallCount() int {
+	fake.checkWriterSecretMutex.RLock()
+	defer fake.checkWriterSecretMutex.RUnlock()
+	fake.clearTableMutex.RLock()
+	defer fake.clearTableMutex.RUnlock()
+	return len(fake.clearTableArgsForCall)
 }
 
-func (fake *FakeExecutiveInterface) DropTableCalls(stub func(schema.FamilyTable) error) {
-	fake.dropTableMutex.Lock()
-	defer fake.dropTableMutex.Unlock()
-	fake.DropTableStub = stub
+func (fake *FakeExecutiveInterface) ClearTableCalls(stub func(schema.FamilyTable) error) {
+	fake.clearTableMutex.Lock()
+	defer fake.clearTableMutex.Unlock()
+	fake.ClearTableStub = stub
 }
 
-func (fake *FakeExecutiveInterface) DropTableArgsForCall(i int) schema.FamilyTable {
-	fake.dropTableMutex.RLock()
-	defer fake.dropTableMutex.RUnlock()
-	argsForCall := fake.dropTableArgsForCall[i]
+func (fake *FakeExecutiveInterface) ClearTableArgsForCall(i int) schema.FamilyTable {
+	fake.clearTableMutex.RLock()
+	defer fake.clearTableMutex.RUnlock()
+	argsForCall := fake.clearTableArgsForCall[i]
 	return argsForCall.arg1
 }
 
-func (fake *FakeExecutiveInterface) DropTableReturns(result1 error) {
-	fake.dropTableMutex.Lock()
-	defer fake.dropTableMutex.Unlock()
-	fake.DropTableStub = nil
-	fake.dropTableReturns = struct {
+func (fake *FakeExecutiveInterface) ClearTableReturns(result1 error) {
+	fake.clearTableMutex.Lock()
+	defer fake.clearTableMutex.Unlock()
+	fake.ClearTableStub = nil
+	fake.clearTableReturns = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *FakeExecutiveInterface) DropTableReturnsOnCall(i int, result1 error) {
-	fake.dropTableMutex.Lock()
-	defer fake.dropTableMutex.Unlock()
-	fake.DropTableStub = nil
-	if fake.dropTableReturnsOnCall == nil {
-		fake.dropTableReturnsOnCall = make(map[int]struct {
+func (fake *FakeExecutiveInterface) ClearTableReturnsOnCall(i int, result1 error) {
+	fake.clearTableMutex.Lock()
+	defer fake.clearTableMutex.Unlock()
+	fake.ClearTableStub = nil
+	if fake.clearTableReturnsOnCall == nil {
+		fake.clearTableReturnsOnCall = make(map[int]struct {
 			result1 error
 		})
 	}
-	fake.dropTableReturnsOnCall[i] = struct {
+	fake.clearTableReturnsOnCall[i] = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *FakeExecutiveInterface) FamilySchemas(arg1 string) ([]schema.Table, error) {
-	fake.familySchemasMutex.Lock()
-	ret, specificReturn := fake.familySchemasReturnsOnCall[len(fake.familySchemasArgsForCall)]
-	fake.familySchemasArgsForCall = append(fake.familySchemasArgsForCall, struct {
-		arg1 string
-	}{arg1})
-	stub := fake.FamilySchemasStub
-	fakeReturns := fake.familySchemasReturns
-	fake.recordInvocation("FamilySchemas", []interface{}{arg1})
-	fake.familySchemasMutex.Unlock()
+func (fake *FakeExecutiveInterface) CloneTable(arg1 schema.FamilyTable, arg2 schema.FamilyTable, arg3 int, arg4 string) (executive.CloneResult, error) {
+	fake.cloneTableMutex.Lock()
+	ret, specificReturn := fake.cloneTableReturnsOnCall[len(fake.cloneTableArgsForCall)]
+	fake.cloneTableArgsForCall = append(fake.cloneTableArgsForCall, struct {
+		arg1 schema.FamilyTable
+		arg2 schema.FamilyTable
+		arg3 int
+		arg4 string
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.CloneTableStub
+	fakeReturns := fake.cloneTableReturns
+	fake.recordInvocation("CloneTable", []interface{}{arg1, arg2, arg3, arg4})
+	fake.cloneTableMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2, arg3, arg4)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -809,64 +1455,2494 @@ func (fake *FakeExecutiveInterface) FamilySchemas(arg1 string) ([]schema.Table,
 	return fakeReturns.result1, fakeReturns.result2
 }
 
-func (fake *FakeExecutiveInterface) FamilySchemasCallCount() int {
-	fake.familySchemasMutex.RLock()
-	defer fake.familySchemasMutex.RUnlock()
-	return len(fake.familySchemasArgsForCall)
+func (fake *FakeExecutiveInterface) CloneTableCallCount() int {
+	fake.cloneTableMutex.RLock()
+	defer fake.cloneTableMutex.RUnlock()
+	return len(fake.cloneTableArgsForCall)
 }
 
-func (fake *FakeExecutiveInterface) FamilySchemasCalls(stub func(string) ([]schema.Table, error)) {
-	fake.familySchemasMutex.Lock()
-	defer fake.familySchemasMutex.Unlock()
-	fake.FamilySchemasStub = stub
+func (fake *FakeExecutiveInterface) CloneTableCalls(stub func(schema.FamilyTable, schema.FamilyTable, int, string) (executive.CloneResult, error)) {
+	fake.cloneTableMutex.Lock()
+	defer fake.cloneTableMutex.Unlock()
+	fake.CloneTableStub = stub
 }
 
-func (fake *FakeExecutiveInterface) FamilySchemasArgsForCall(i int) string {
-	fake.familySchemasMutex.RLock()
-	defer fake.familySchemasMutex.RUnlock()
-	argsForCall := fake.familySchemasArgsForCall[i]
-	return argsForCall.arg1
+func (fake *FakeExecutiveInterface) CloneTableArgsForCall(i int) (schema.FamilyTable, schema.FamilyTable, int, string) {
+	fake.cloneTableMutex.RLock()
+	defer fake.cloneTableMutex.RUnlock()
+	argsForCall := fake.cloneTableArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
 }
 
-func (fake *FakeExecutiveInterface) FamilySchemasReturns(result1 []schema.Table, result2 error) {
-	fake.familySchemasMutex.Lock()
-	defer fake.familySchemasMutex.Unlock()
-	fake.FamilySchemasStub = nil
-	fake.familySchemasReturns = struct {
-		result1 []schema.Table
+func (fake *FakeExecutiveInterface) CloneTableReturns(result1 executive.CloneResult, result2 error) {
+	fake.cloneTableMutex.Lock()
+	defer fake.cloneTableMutex.Unlock()
+	fake.CloneTableStub = nil
+	fake.cloneTableReturns = struct {
+		result1 executive.CloneResult
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeExecutiveInterface) FamilySchemasReturnsOnCall(i int, result1 []schema.Table, result2 error) {
-	fake.familySchemasMutex.Lock()
-	defer fake.familySchemasMutex.Unlock()
-	fake.FamilySchemasStub = nil
-	if fake.familySchemasReturnsOnCall == nil {
-		fake.familySchemasReturnsOnCall = make(map[int]struct {
-			result1 []schema.Table
+func (fake *FakeExecutiveInterface) CloneTableReturnsOnCall(i int, result1 executive.CloneResult, result2 error) {
+	fake.cloneTableMutex.Lock()
+	defer fake.cloneTableMutex.Unlock()
+	fake.CloneTableStub = nil
+	if fake.cloneTableReturnsOnCall == nil {
+		fake.cloneTableReturnsOnCall = make(map[int]struct {
+			result1 executive.CloneResult
 			result2 error
 		})
 	}
-	fake.familySchemasReturnsOnCall[i] = struct {
-		result1 []schema.Table
+	fake.cloneTableReturnsOnCall[i] = struct {
+		result1 executive.CloneResult
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeExecutiveInterface) GetWriterCookie(arg1 string, arg2 string) ([]byte, error) {
-	fake.getWriterCookieMutex.Lock()
-	ret, specificReturn := fake.getWriterCookieReturnsOnCall[len(fake.getWriterCookieArgsForCall)]
-	fake.getWriterCookieArgsForCall = append(fake.getWriterCookieArgsForCall, struct {
-		arg1 string
-		arg2 string
+func (fake *FakeExecutiveInterface) CompareAndSwapWriterCookie(arg1 string, arg2 string, arg3 []byte, arg4 []byte) (executive.CookieSwap, error) {
+	var arg3Copy []byte
+	if arg3 != nil {
+		arg3Copy = make([]byte, len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	var arg4Copy []byte
+	if arg4 != nil {
+		arg4Copy = make([]byte, len(arg4))
+		copy(arg4Copy, arg4)
+	}
+	fake.compareAndSwapWriterCookieMutex.Lock()
+	ret, specificReturn := fake.compareAndSwapWriterCookieReturnsOnCall[len(fake.compareAndSwapWriterCookieArgsForCall)]
+	fake.compareAndSwapWriterCookieArgsForCall = append(fake.compareAndSwapWriterCookieArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 []byte
+		arg4 []byte
+	}{arg1, arg2, arg3Copy, arg4Copy})
+	stub := fake.CompareAndSwapWriterCookieStub
+	fakeReturns := fake.compareAndSwapWriterCookieReturns
+	fake.recordInvocation("CompareAndSwapWriterCookie", []interface{}{arg1, arg2, arg3Copy, arg4Copy})
+	fake.compareAndSwapWriterCookieMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeExecutiveInterface) CompareAndSwapWriterCookieCallCount() int {
+	fake.compareAndSwapWriterCookieMutex.RLock()
+	defer fake.compareAndSwapWriterCookieMutex.RUnlock()
+	return len(fake.compareAndSwapWriterCookieArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) CompareAndSwapWriterCookieCalls(stub func(string, string, []byte, []byte) (executive.CookieSwap, error)) {
+	fake.compareAndSwapWriterCookieMutex.Lock()
+	defer fake.compareAndSwapWriterCookieMutex.Unlock()
+	fake.CompareAndSwapWriterCookieStub = stub
+}
+
+func (fake *FakeExecutiveInterface) CompareAndSwapWriterCookieArgsForCall(i int) (string, string, []byte, []byte) {
+	fake.compareAndSwapWriterCookieMutex.RLock()
+	defer fake.compareAndSwapWriterCookieMutex.RUnlock()
+	argsForCall := fake.compareAndSwapWriterCookieArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeExecutiveInterface) CompareAndSwapWriterCookieReturns(result1 executive.CookieSwap, result2 error) {
+	fake.compareAndSwapWriterCookieMutex.Lock()
+	defer fake.compareAndSwapWriterCookieMutex.Unlock()
+	fake.CompareAndSwapWriterCookieStub = nil
+	fake.compareAndSwapWriterCookieReturns = struct {
+		result1 executive.CookieSwap
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) CompareAndSwapWriterCookieReturnsOnCall(i int, result1 executive.CookieSwap, result2 error) {
+	fake.compareAndSwapWriterCookieMutex.Lock()
+	defer fake.compareAndSwapWriterCookieMutex.Unlock()
+	fake.CompareAndSwapWriterCookieStub = nil
+	if fake.compareAndSwapWriterCookieReturnsOnCall == nil {
+		fake.compareAndSwapWriterCookieReturnsOnCall = make(map[int]struct {
+			result1 executive.CookieSwap
+			result2 error
+		})
+	}
+	fake.compareAndSwapWriterCookieReturnsOnCall[i] = struct {
+		result1 executive.CookieSwap
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) CreateAPIToken(arg1 string) (executive.APIToken, error) {
+	fake.createAPITokenMutex.Lock()
+	ret, specificReturn := fake.createAPITokenReturnsOnCall[len(fake.createAPITokenArgsForCall)]
+	fake.createAPITokenArgsForCall = append(fake.createAPITokenArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.CreateAPITokenStub
+	fakeReturns := fake.createAPITokenReturns
+	fake.recordInvocation("CreateAPIToken", []interface{}{arg1})
+	fake.createAPITokenMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeExecutiveInterface) CreateAPITokenCallCount() int {
+	fake.createAPITokenMutex.RLock()
+	defer fake.createAPITokenMutex.RUnlock()
+	return len(fake.createAPITokenArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) CreateAPITokenCalls(stub func(string) (executive.APIToken, error)) {
+	fake.createAPITokenMutex.Lock()
+	defer fake.createAPITokenMutex.Unlock()
+	fake.CreateAPITokenStub = stub
+}
+
+func (fake *FakeExecutiveInterface) CreateAPITokenArgsForCall(i int) string {
+	fake.createAPITokenMutex.RLock()
+	defer fake.createAPITokenMutex.RUnlock()
+	argsForCall := fake.createAPITokenArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeExecutiveInterface) CreateAPITokenReturns(result1 executive.APIToken, result2 error) {
+	fake.createAPITokenMutex.Lock()
+	defer fake.createAPITokenMutex.Unlock()
+	fake.CreateAPITokenStub = nil
+	fake.createAPITokenReturns = struct {
+		result1 executive.APIToken
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) CreateAPITokenReturnsOnCall(i int, result1 executive.APIToken, result2 error) {
+	fake.createAPITokenMutex.Lock()
+	defer fake.createAPITokenMutex.Unlock()
+	fake.CreateAPITokenStub = nil
+	if fake.createAPITokenReturnsOnCall == nil {
+		fake.createAPITokenReturnsOnCall = make(map[int]struct {
+			result1 executive.APIToken
+			result2 error
+		})
+	}
+	fake.createAPITokenReturnsOnCall[i] = struct {
+		result1 executive.APIToken
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) CreateFamily(arg1 string) error {
+	fake.createFamilyMutex.Lock()
+	ret, specificReturn := fake.createFamilyReturnsOnCall[len(fake.createFamilyArgsForCall)]
+	fake.createFamilyArgsForCall = append(fake.createFamilyArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.CreateFamilyStub
+	fakeReturns := fake.createFamilyReturns
+	fake.recordInvocation("CreateFamily", []interface{}{arg1})
+	fake.createFamilyMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeExecutiveInterface) CreateFamilyCallCount() int {
+	fake.createFamilyMutex.RLock()
+	defer fake.createFamilyMutex.RUnlock()
+	return len(fake.createFamilyArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) CreateFamilyCalls(stub func(string) error) {
+	fake.createFamilyMutex.Lock()
+	defer fake.createFamilyMutex.Unlock()
+	fake.CreateFamilyStub = stub
+}
+
+func (fake *FakeExecutiveInterface) CreateFamilyArgsForCall(i int) string {
+	fake.createFamilyMutex.RLock()
+	defer fake.createFamilyMutex.RUnlock()
+	argsForCall := fake.createFamilyArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeExecutiveInterface) CreateFamilyReturns(result1 error) {
+	fake.createFamilyMutex.Lock()
+	defer fake.createFamilyMutex.Unlock()
+	fake.CreateFamilyStub = nil
+	fake.createFamilyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) CreateFamilyReturnsOnCall(i int, result1 error) {
+	fake.createFamilyMutex.Lock()
+	defer fake.createFamilyMutex.Unlock()
+	fake.CreateFamilyStub = nil
+	if fake.createFamilyReturnsOnCall == nil {
+		fake.createFamilyReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.createFamilyReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) CreateTable(arg1 string, arg2 string, arg3 []string, arg4 []schema.FieldType, arg5 []string) error {
+	var arg3Copy []string
+	if arg3 != nil {
+		arg3Copy = make([]string, len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	var arg4Copy []schema.FieldType
+	if arg4 != nil {
+		arg4Copy = make([]schema.FieldType, len(arg4))
+		copy(arg4Copy, arg4)
+	}
+	var arg5Copy []string
+	if arg5 != nil {
+		arg5Copy = make([]string, len(arg5))
+		copy(arg5Copy, arg5)
+	}
+	fake.createTableMutex.Lock()
+	ret, specificReturn := fake.createTableReturnsOnCall[len(fake.createTableArgsForCall)]
+	fake.createTableArgsForCall = append(fake.createTableArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 []string
+		arg4 []schema.FieldType
+		arg5 []string
+	}{arg1, arg2, arg3Copy, arg4Copy, arg5Copy})
+	stub := fake.CreateTableStub
+	fakeReturns := fake.createTableReturns
+	fake.recordInvocation("CreateTable", []interface{}{arg1, arg2, arg3Copy, arg4Copy, arg5Copy})
+	fake.createTableMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeExecutiveInterface) CreateTableCallCount() int {
+	fake.createTableMutex.RLock()
+	defer fake.createTableMutex.RUnlock()
+	return len(fake.createTableArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) CreateTableCalls(stub func(string, string, []string, []schema.FieldType, []string) error) {
+	fake.createTableMutex.Lock()
+	defer fake.createTableMutex.Unlock()
+	fake.CreateTableStub = stub
+}
+
+func (fake *FakeExecutiveInterface) CreateTableArgsForCall(i int) (string, string, []string, []schema.FieldType, []string) {
+	fake.createTableMutex.RLock()
+	defer fake.createTableMutex.RUnlock()
+	argsForCall := fake.createTableArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeExecutiveInterface) CreateTableReturns(result1 error) {
+	fake.createTableMutex.Lock()
+	defer fake.createTableMutex.Unlock()
+	fake.CreateTableStub = nil
+	fake.createTableReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) CreateTableReturnsOnCall(i int, result1 error) {
+	fake.createTableMutex.Lock()
+	defer fake.createTableMutex.Unlock()
+	fake.CreateTableStub = nil
+	if fake.createTableReturnsOnCall == nil {
+		fake.createTableReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.createTableReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) CreateTables(arg1 []schema.Table) error {
+	var arg1Copy []schema.Table
+	if arg1 != nil {
+		arg1Copy = make([]schema.Table, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.createTablesMutex.Lock()
+	ret, specificReturn := fake.createTablesReturnsOnCall[len(fake.createTablesArgsForCall)]
+	fake.createTablesArgsForCall = append(fake.createTablesArgsForCall, struct {
+		arg1 []schema.Table
+	}{arg1Copy})
+	stub := fake.CreateTablesStub
+	fakeReturns := fake.createTablesReturns
+	fake.recordInvocation("CreateTables", []interface{}{arg1Copy})
+	fake.createTablesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeExecutiveInterface) CreateTablesCallCount() int {
+	fake.createTablesMutex.RLock()
+	defer fake.createTablesMutex.RUnlock()
+	return len(fake.createTablesArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) CreateTablesCalls(stub func([]schema.Table) error) {
+	fake.createTablesMutex.Lock()
+	defer fake.createTablesMutex.Unlock()
+	fake.CreateTablesStub = stub
+}
+
+func (fake *FakeExecutiveInterface) CreateTablesArgsForCall(i int) []schema.Table {
+	fake.createTablesMutex.RLock()
+	defer fake.createTablesMutex.RUnlock()
+	argsForCall := fake.createTablesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeExecutiveInterface) CreateTablesReturns(result1 error) {
+	fake.createTablesMutex.Lock()
+	defer fake.createTablesMutex.Unlock()
+	fake.CreateTablesStub = nil
+	fake.createTablesReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) CreateTablesReturnsOnCall(i int, result1 error) {
+	fake.createTablesMutex.Lock()
+	defer fake.createTablesMutex.Unlock()
+	fake.CreateTablesStub = nil
+	if fake.createTablesReturnsOnCall == nil {
+		fake.createTablesReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.createTablesReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) DeleteAPIToken(arg1 string) error {
+	fake.deleteAPITokenMutex.Lock()
+	ret, specificReturn := fake.deleteAPITokenReturnsOnCall[len(fake.deleteAPITokenArgsForCall)]
+	fake.deleteAPITokenArgsForCall = append(fake.deleteAPITokenArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.DeleteAPITokenStub
+	fakeReturns := fake.deleteAPITokenReturns
+	fake.recordInvocation("DeleteAPIToken", []interface{}{arg1})
+	fake.deleteAPITokenMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeExecutiveInterface) DeleteAPITokenCallCount() int {
+	fake.deleteAPITokenMutex.RLock()
+	defer fake.deleteAPITokenMutex.RUnlock()
+	return len(fake.deleteAPITokenArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) DeleteAPITokenCalls(stub func(string) error) {
+	fake.deleteAPITokenMutex.Lock()
+	defer fake.deleteAPITokenMutex.Unlock()
+	fake.DeleteAPITokenStub = stub
+}
+
+func (fake *FakeExecutiveInterface) DeleteAPITokenArgsForCall(i int) string {
+	fake.deleteAPITokenMutex.RLock()
+	defer fake.deleteAPITokenMutex.RUnlock()
+	argsForCall := fake.deleteAPITokenArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeExecutiveInterface) DeleteAPITokenReturns(result1 error) {
+	fake.deleteAPITokenMutex.Lock()
+	defer fake.deleteAPITokenMutex.Unlock()
+	fake.DeleteAPITokenStub = nil
+	fake.deleteAPITokenReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) DeleteAPITokenReturnsOnCall(i int, result1 error) {
+	fake.deleteAPITokenMutex.Lock()
+	defer fake.deleteAPITokenMutex.Unlock()
+	fake.DeleteAPITokenStub = nil
+	if fake.deleteAPITokenReturnsOnCall == nil {
+		fake.deleteAPITokenReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteAPITokenReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) DeleteFamily(arg1 string, arg2 bool) error {
+	fake.deleteFamilyMutex.Lock()
+	ret, specificReturn := fake.deleteFamilyReturnsOnCall[len(fake.deleteFamilyArgsForCall)]
+	fake.deleteFamilyArgsForCall = append(fake.deleteFamilyArgsForCall, struct {
+		arg1 string
+		arg2 bool
+	}{arg1, arg2})
+	stub := fake.DeleteFamilyStub
+	fakeReturns := fake.deleteFamilyReturns
+	fake.recordInvocation("DeleteFamily", []interface{}{arg1, arg2})
+	fake.deleteFamilyMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeExecutiveInterface) DeleteFamilyCallCount() int {
+	fake.deleteFamilyMutex.RLock()
+	defer fake.deleteFamilyMutex.RUnlock()
+	return len(fake.deleteFamilyArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) DeleteFamilyCalls(stub func(string, bool) error) {
+	fake.deleteFamilyMutex.Lock()
+	defer fake.deleteFamilyMutex.Unlock()
+	fake.DeleteFamilyStub = stub
+}
+
+func (fake *FakeExecutiveInterface) DeleteFamilyArgsForCall(i int) (string, bool) {
+	fake.deleteFamilyMutex.RLock()
+	defer fake.deleteFamilyMutex.RUnlock()
+	argsForCall := fake.deleteFamilyArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeExecutiveInterface) DeleteFamilyReturns(result1 error) {
+	fake.deleteFamilyMutex.Lock()
+	defer fake.deleteFamilyMutex.Unlock()
+	fake.DeleteFamilyStub = nil
+	fake.deleteFamilyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) DeleteFamilyReturnsOnCall(i int, result1 error) {
+	fake.deleteFamilyMutex.Lock()
+	defer fake.deleteFamilyMutex.Unlock()
+	fake.DeleteFamilyStub = nil
+	if fake.deleteFamilyReturnsOnCall == nil {
+		fake.deleteFamilyReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteFamilyReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) DeleteTableSizeLimit(arg1 schema.FamilyTable) error {
+	fake.deleteTableSizeLimitMutex.Lock()
+	ret, specificReturn := fake.deleteTableSizeLimitReturnsOnCall[len(fake.deleteTableSizeLimitArgsForCall)]
+	fake.deleteTableSizeLimitArgsForCall = append(fake.deleteTableSizeLimitArgsForCall, struct {
+		arg1 schema.FamilyTable
+	}{arg1})
+	stub := fake.DeleteTableSizeLimitStub
+	fakeReturns := fake.deleteTableSizeLimitReturns
+	fake.recordInvocation("DeleteTableSizeLimit", []interface{}{arg1})
+	fake.deleteTableSizeLimitMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeExecutiveInterface) DeleteTableSizeLimitCallCount() int {
+	fake.deleteTableSizeLimitMutex.RLock()
+	defer fake.deleteTableSizeLimitMutex.RUnlock()
+	return len(fake.deleteTableSizeLimitArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) DeleteTableSizeLimitCalls(stub func(schema.FamilyTable) error) {
+	fake.deleteTableSizeLimitMutex.Lock()
+	defer fake.deleteTableSizeLimitMutex.Unlock()
+	fake.DeleteTableSizeLimitStub = stub
+}
+
+func (fake *FakeExecutiveInterface) DeleteTableSizeLimitArgsForCall(i int) schema.FamilyTable {
+	fake.deleteTableSizeLimitMutex.RLock()
+	defer fake.deleteTableSizeLimitMutex.RUnlock()
+	argsForCall := fake.deleteTableSizeLimitArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeExecutiveInterface) DeleteTableSizeLimitReturns(result1 error) {
+	fake.deleteTableSizeLimitMutex.Lock()
+	defer fake.deleteTableSizeLimitMutex.Unlock()
+	fake.DeleteTableSizeLimitStub = nil
+	fake.deleteTableSizeLimitReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) DeleteTableSizeLimitReturnsOnCall(i int, result1 error) {
+	fake.deleteTableSizeLimitMutex.Lock()
+	defer fake.deleteTableSizeLimitMutex.Unlock()
+	fake.DeleteTableSizeLimitStub = nil
+	if fake.deleteTableSizeLimitReturnsOnCall == nil {
+		fake.deleteTableSizeLimitReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteTableSizeLimitReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) DeleteTableTemplate(arg1 string, arg2 string) error {
+	fake.deleteTableTemplateMutex.Lock()
+	ret, specificReturn := fake.deleteTableTemplateReturnsOnCall[len(fake.deleteTableTemplateArgsForCall)]
+	fake.deleteTableTemplateArgsForCall = append(fake.deleteTableTemplateArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.DeleteTableTemplateStub
+	fakeReturns := fake.deleteTableTemplateReturns
+	fake.recordInvocation("DeleteTableTemplate", []interface{}{arg1, arg2})
+	fake.deleteTableTemplateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeExecutiveInterface) DeleteTableTemplateCallCount() int {
+	fake.deleteTableTemplateMutex.RLock()
+	defer fake.deleteTableTemplateMutex.RUnlock()
+	return len(fake.deleteTableTemplateArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) DeleteTableTemplateCalls(stub func(string, string) error) {
+	fake.deleteTableTemplateMutex.Lock()
+	defer fake.deleteTableTemplateMutex.Unlock()
+	fake.DeleteTableTemplateStub = stub
+}
+
+func (fake *FakeExecutiveInterface) DeleteTableTemplateArgsForCall(i int) (string, string) {
+	fake.deleteTableTemplateMutex.RLock()
+	defer fake.deleteTableTemplateMutex.RUnlock()
+	argsForCall := fake.deleteTableTemplateArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeExecutiveInterface) DeleteTableTemplateReturns(result1 error) {
+	fake.deleteTableTemplateMutex.Lock()
+	defer fake.deleteTableTemplateMutex.Unlock()
+	fake.DeleteTableTemplateStub = nil
+	fake.deleteTableTemplateReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) DeleteTableTemplateReturnsOnCall(i int, result1 error) {
+	fake.deleteTableTemplateMutex.Lock()
+	defer fake.deleteTableTemplateMutex.Unlock()
+	fake.DeleteTableTemplateStub = nil
+	if fake.deleteTableTemplateReturnsOnCall == nil {
+		fake.deleteTableTemplateReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteTableTemplateReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) DeleteWebhook(arg1 string, arg2 string) error {
+	fake.deleteWebhookMutex.Lock()
+	ret, specificReturn := fake.deleteWebhookReturnsOnCall[len(fake.deleteWebhookArgsForCall)]
+	fake.deleteWebhookArgsForCall = append(fake.deleteWebhookArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.DeleteWebhookStub
+	fakeReturns := fake.deleteWebhookReturns
+	fake.recordInvocation("DeleteWebhook", []interface{}{arg1, arg2})
+	fake.deleteWebhookMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeExecutiveInterface) DeleteWebhookCallCount() int {
+	fake.deleteWebhookMutex.RLock()
+	defer fake.deleteWebhookMutex.RUnlock()
+	return len(fake.deleteWebhookArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) DeleteWebhookCalls(stub func(string, string) error) {
+	fake.deleteWebhookMutex.Lock()
+	defer fake.deleteWebhookMutex.Unlock()
+	fake.DeleteWebhookStub = stub
+}
+
+func (fake *FakeExecutiveInterface) DeleteWebhookArgsForCall(i int) (string, string) {
+	fake.deleteWebhookMutex.RLock()
+	defer fake.deleteWebhookMutex.RUnlock()
+	argsForCall := fake.deleteWebhookArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeExecutiveInterface) DeleteWebhookReturns(result1 error) {
+	fake.deleteWebhookMutex.Lock()
+	defer fake.deleteWebhookMutex.Unlock()
+	fake.DeleteWebhookStub = nil
+	fake.deleteWebhookReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) DeleteWebhookReturnsOnCall(i int, result1 error) {
+	fake.deleteWebhookMutex.Lock()
+	defer fake.deleteWebhookMutex.Unlock()
+	fake.DeleteWebhookStub = nil
+	if fake.deleteWebhookReturnsOnCall == nil {
+		fake.deleteWebhookReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteWebhookReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) DeleteWriterGroup(arg1 string) error {
+	fake.deleteWriterGroupMutex.Lock()
+	ret, specificReturn := fake.deleteWriterGroupReturnsOnCall[len(fake.deleteWriterGroupArgsForCall)]
+	fake.deleteWriterGroupArgsForCall = append(fake.deleteWriterGroupArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.DeleteWriterGroupStub
+	fakeReturns := fake.deleteWriterGroupReturns
+	fake.recordInvocation("DeleteWriterGroup", []interface{}{arg1})
+	fake.deleteWriterGroupMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeExecutiveInterface) DeleteWriterGroupCallCount() int {
+	fake.deleteWriterGroupMutex.RLock()
+	defer fake.deleteWriterGroupMutex.RUnlock()
+	return len(fake.deleteWriterGroupArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) DeleteWriterGroupCalls(stub func(string) error) {
+	fake.deleteWriterGroupMutex.Lock()
+	defer fake.deleteWriterGroupMutex.Unlock()
+	fake.DeleteWriterGroupStub = stub
+}
+
+func (fake *FakeExecutiveInterface) DeleteWriterGroupArgsForCall(i int) string {
+	fake.deleteWriterGroupMutex.RLock()
+	defer fake.deleteWriterGroupMutex.RUnlock()
+	argsForCall := fake.deleteWriterGroupArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeExecutiveInterface) DeleteWriterGroupReturns(result1 error) {
+	fake.deleteWriterGroupMutex.Lock()
+	defer fake.deleteWriterGroupMutex.Unlock()
+	fake.DeleteWriterGroupStub = nil
+	fake.deleteWriterGroupReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) DeleteWriterGroupReturnsOnCall(i int, result1 error) {
+	fake.deleteWriterGroupMutex.Lock()
+	defer fake.deleteWriterGroupMutex.Unlock()
+	fake.DeleteWriterGroupStub = nil
+	if fake.deleteWriterGroupReturnsOnCall == nil {
+		fake.deleteWriterGroupReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteWriterGroupReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) DeleteWriterRateLimit(arg1 string) error {
+	fake.deleteWriterRateLimitMutex.Lock()
+	ret, specificReturn := fake.deleteWriterRateLimitReturnsOnCall[len(fake.deleteWriterRateLimitArgsForCall)]
+	fake.deleteWriterRateLimitArgsForCall = append(fake.deleteWriterRateLimitArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.DeleteWriterRateLimitStub
+	fakeReturns := fake.deleteWriterRateLimitReturns
+	fake.recordInvocation("DeleteWriterRateLimit", []interface{}{arg1})
+	fake.deleteWriterRateLimitMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeExecutiveInterface) DeleteWriterRateLimitCallCount() int {
+	fake.deleteWriterRateLimitMutex.RLock()
+	defer fake.deleteWriterRateLimitMutex.RUnlock()
+	return len(fake.deleteWriterRateLimitArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) DeleteWriterRateLimitCalls(stub func(string) error) {
+	fake.deleteWriterRateLimitMutex.Lock()
+	defer fake.deleteWriterRateLimitMutex.Unlock()
+	fake.DeleteWriterRateLimitStub = stub
+}
+
+func (fake *FakeExecutiveInterface) DeleteWriterRateLimitArgsForCall(i int) string {
+	fake.deleteWriterRateLimitMutex.RLock()
+	defer fake.deleteWriterRateLimitMutex.RUnlock()
+	argsForCall := fake.deleteWriterRateLimitArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeExecutiveInterface) DeleteWriterRateLimitReturns(result1 error) {
+	fake.deleteWriterRateLimitMutex.Lock()
+	defer fake.deleteWriterRateLimitMutex.Unlock()
+	fake.DeleteWriterRateLimitStub = nil
+	fake.deleteWriterRateLimitReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) DeleteWriterRateLimitReturnsOnCall(i int, result1 error) {
+	fake.deleteWriterRateLimitMutex.Lock()
+	defer fake.deleteWriterRateLimitMutex.Unlock()
+	fake.DeleteWriterRateLimitStub = nil
+	if fake.deleteWriterRateLimitReturnsOnCall == nil {
+		fake.deleteWriterRateLimitReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteWriterRateLimitReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) DropTable(arg1 schema.FamilyTable) error {
+	fake.dropTableMutex.Lock()
+	ret, specificReturn := fake.dropTableReturnsOnCall[len(fake.dropTableArgsForCall)]
+	fake.dropTableArgsForCall = append(fake.dropTableArgsForCall, struct {
+		arg1 schema.FamilyTable
+	}{arg1})
+	stub := fake.DropTableStub
+	fakeReturns := fake.dropTableReturns
+	fake.recordInvocation("DropTable", []interface{}{arg1})
+	fake.dropTableMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeExecutiveInterface) DropTableCallCount() int {
+	fake.dropTableMutex.RLock()
+	defer fake.dropTableMutex.RUnlock()
+	return len(fake.dropTableArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) DropTableCalls(stub func(schema.FamilyTable) error) {
+	fake.dropTableMutex.Lock()
+	defer fake.dropTableMutex.Unlock()
+	fake.DropTableStub = stub
+}
+
+func (fake *FakeExecutiveInterface) DropTableArgsForCall(i int) schema.FamilyTable {
+	fake.dropTableMutex.RLock()
+	defer fake.dropTableMutex.RUnlock()
+	argsForCall := fake.dropTableArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeExecutiveInterface) DropTableReturns(result1 error) {
+	fake.dropTableMutex.Lock()
+	defer fake.dropTableMutex.Unlock()
+	fake.DropTableStub = nil
+	fake.dropTableReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) DropTableReturnsOnCall(i int, result1 error) {
+	fake.dropTableMutex.Lock()
+	defer fake.dropTableMutex.Unlock()
+	fake.DropTableStub = nil
+	if fake.dropTableReturnsOnCall == nil {
+		fake.dropTableReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.dropTableReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) EnableWriter(arg1 string) error {
+	fake.enableWriterMutex.Lock()
+	ret, specificReturn := fake.enableWriterReturnsOnCall[len(fake.enableWriterArgsForCall)]
+	fake.enableWriterArgsForCall = append(fake.enableWriterArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.EnableWriterStub
+	fakeReturns := fake.enableWriterReturns
+	fake.recordInvocation("EnableWriter", []interface{}{arg1})
+	fake.enableWriterMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeExecutiveInterface) EnableWriterCallCount() int {
+	fake.enableWriterMutex.RLock()
+	defer fake.enableWriterMutex.RUnlock()
+	return len(fake.enableWriterArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) EnableWriterCalls(stub func(string) error) {
+	fake.enableWriterMutex.Lock()
+	defer fake.enableWriterMutex.Unlock()
+	fake.EnableWriterStub = stub
+}
+
+func (fake *FakeExecutiveInterface) EnableWriterArgsForCall(i int) string {
+	fake.enableWriterMutex.RLock()
+	defer fake.enableWriterMutex.RUnlock()
+	argsForCall := fake.enableWriterArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeExecutiveInterface) EnableWriterReturns(result1 error) {
+	fake.enableWriterMutex.Lock()
+	defer fake.enableWriterMutex.Unlock()
+	fake.EnableWriterStub = nil
+	fake.enableWriterReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) EnableWriterReturnsOnCall(i int, result1 error) {
+	fake.enableWriterMutex.Lock()
+	defer fake.enableWriterMutex.Unlock()
+	fake.EnableWriterStub = nil
+	if fake.enableWriterReturnsOnCall == nil {
+		fake.enableWriterReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.enableWriterReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) FamilySchemas(arg1 string) ([]schema.Table, error) {
+	fake.familySchemasMutex.Lock()
+	ret, specificReturn := fake.familySchemasReturnsOnCall[len(fake.familySchemasArgsForCall)]
+	fake.familySchemasArgsForCall = append(fake.familySchemasArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.FamilySchemasStub
+	fakeReturns := fake.familySchemasReturns
+	fake.recordInvocation("FamilySchemas", []interface{}{arg1})
+	fake.familySchemasMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeExecutiveInterface) FamilySchemasCallCount() int {
+	fake.familySchemasMutex.RLock()
+	defer fake.familySchemasMutex.RUnlock()
+	return len(fake.familySchemasArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) FamilySchemasCalls(stub func(string) ([]schema.Table, error)) {
+	fake.familySchemasMutex.Lock()
+	defer fake.familySchemasMutex.Unlock()
+	fake.FamilySchemasStub = stub
+}
+
+func (fake *FakeExecutiveInterface) FamilySchemasArgsForCall(i int) string {
+	fake.familySchemasMutex.RLock()
+	defer fake.familySchemasMutex.RUnlock()
+	argsForCall := fake.familySchemasArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeExecutiveInterface) FamilySchemasReturns(result1 []schema.Table, result2 error) {
+	fake.familySchemasMutex.Lock()
+	defer fake.familySchemasMutex.Unlock()
+	fake.FamilySchemasStub = nil
+	fake.familySchemasReturns = struct {
+		result1 []schema.Table
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) FamilySchemasReturnsOnCall(i int, result1 []schema.Table, result2 error) {
+	fake.familySchemasMutex.Lock()
+	defer fake.familySchemasMutex.Unlock()
+	fake.FamilySchemasStub = nil
+	if fake.familySchemasReturnsOnCall == nil {
+		fake.familySchemasReturnsOnCall = make(map[int]struct {
+			result1 []schema.Table
+			result2 error
+		})
+	}
+	fake.familySchemasReturnsOnCall[i] = struct {
+		result1 []schema.Table
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) FamilyTables(arg1 string) ([]string, error) {
+	fake.familyTablesMutex.Lock()
+	ret, specificReturn := fake.familyTablesReturnsOnCall[len(fake.familyTablesArgsForCall)]
+	fake.familyTablesArgsForCall = append(fake.familyTablesArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.FamilyTablesStub
+	fakeReturns := fake.familyTablesReturns
+	fake.recordInvocation("FamilyTables", []interface{}{arg1})
+	fake.familyTablesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeExecutiveInterface) FamilyTablesCallCount() int {
+	fake.familyTablesMutex.RLock()
+	defer fake.familyTablesMutex.RUnlock()
+	return len(fake.familyTablesArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) FamilyTablesCalls(stub func(string) ([]string, error)) {
+	fake.familyTablesMutex.Lock()
+	defer fake.familyTablesMutex.Unlock()
+	fake.FamilyTablesStub = stub
+}
+
+func (fake *FakeExecutiveInterface) FamilyTablesArgsForCall(i int) string {
+	fake.familyTablesMutex.RLock()
+	defer fake.familyTablesMutex.RUnlock()
+	argsForCall := fake.familyTablesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeExecutiveInterface) FamilyTablesReturns(result1 []string, result2 error) {
+	fake.familyTablesMutex.Lock()
+	defer fake.familyTablesMutex.Unlock()
+	fake.FamilyTablesStub = nil
+	fake.familyTablesReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) FamilyTablesReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.familyTablesMutex.Lock()
+	defer fake.familyTablesMutex.Unlock()
+	fake.FamilyTablesStub = nil
+	if fake.familyTablesReturnsOnCall == nil {
+		fake.familyTablesReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.familyTablesReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) GetWriterCookie(arg1 string, arg2 string) ([]byte, error) {
+	fake.getWriterCookieMutex.Lock()
+	ret, specificReturn := fake.getWriterCookieReturnsOnCall[len(fake.getWriterCookieArgsForCall)]
+	fake.getWriterCookieArgsForCall = append(fake.getWriterCookieArgsForCall, struct {
+		arg1 string
+		arg2 string
 	}{arg1, arg2})
 	stub := fake.GetWriterCookieStub
 	fakeReturns := fake.getWriterCookieReturns
 	fake.recordInvocation("GetWriterCookie", []interface{}{arg1, arg2})
 	fake.getWriterCookieMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeExecutiveInterface) GetWriterCookieCallCount() int {
+	fake.getWriterCookieMutex.RLock()
+	defer fake.getWriterCookieMutex.RUnlock()
+	return len(fake.getWriterCookieArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) GetWriterCookieCalls(stub func(string, string) ([]byte, error)) {
+	fake.getWriterCookieMutex.Lock()
+	defer fake.getWriterCookieMutex.Unlock()
+	fake.GetWriterCookieStub = stub
+}
+
+func (fake *FakeExecutiveInterface) GetWriterCookieArgsForCall(i int) (string, string) {
+	fake.getWriterCookieMutex.RLock()
+	defer fake.getWriterCookieMutex.RUnlock()
+	argsForCall := fake.getWriterCookieArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeExecutiveInterface) GetWriterCookieReturns(result1 []byte, result2 error) {
+	fake.getWriterCookieMutex.Lock()
+	defer fake.getWriterCookieMutex.Unlock()
+	fake.GetWriterCookieStub = nil
+	fake.getWriterCookieReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) GetWriterCookieReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.getWriterCookieMutex.Lock()
+	defer fake.getWriterCookieMutex.Unlock()
+	fake.GetWriterCookieStub = nil
+	if fake.getWriterCookieReturnsOnCall == nil {
+		fake.getWriterCookieReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.getWriterCookieReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) Mutate(arg1 string, arg2 string, arg3 string, arg4 []byte, arg5 []byte, arg6 []executive.ExecutiveMutationRequest) (executive.MutationResult, error) {
+	var arg4Copy []byte
+	if arg4 != nil {
+		arg4Copy = make([]byte, len(arg4))
+		copy(arg4Copy, arg4)
+	}
+	var arg5Copy []byte
+	if arg5 != nil {
+		arg5Copy = make([]byte, len(arg5))
+		copy(arg5Copy, arg5)
+	}
+	var arg6Copy []executive.ExecutiveMutationRequest
+	if arg6 != nil {
+		arg6Copy = make([]executive.ExecutiveMutationRequest, len(arg6))
+		copy(arg6Copy, arg6)
+	}
+	fake.mutateMutex.Lock()
+	ret, specificReturn := fake.mutateReturnsOnCall[len(fake.mutateArgsForCall)]
+	fake.mutateArgsForCall = append(fake.mutateArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 []byte
+		arg5 []byte
+		arg6 []executive.ExecutiveMutationRequest
+	}{arg1, arg2, arg3, arg4Copy, arg5Copy, arg6Copy})
+	stub := fake.MutateStub
+	fakeReturns := fake.mutateReturns
+	fake.recordInvocation("Mutate", []interface{}{arg1, arg2, arg3, arg4Copy, arg5Copy, arg6Copy})
+	fake.mutateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5, arg6)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeExecutiveInterface) MutateCallCount() int {
+	fake.mutateMutex.RLock()
+	defer fake.mutateMutex.RUnlock()
+	return len(fake.mutateArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) MutateCalls(stub func(string, string, string, []byte, []byte, []executive.ExecutiveMutationRequest) (executive.MutationResult, error)) {
+	fake.mutateMutex.Lock()
+	defer fake.mutateMutex.Unlock()
+	fake.MutateStub = stub
+}
+
+func (fake *FakeExecutiveInterface) MutateArgsForCall(i int) (string, string, string, []byte, []byte, []executive.ExecutiveMutationRequest) {
+	fake.mutateMutex.RLock()
+	defer fake.mutateMutex.RUnlock()
+	argsForCall := fake.mutateArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5, argsForCall.arg6
+}
+
+func (fake *FakeExecutiveInterface) MutateReturns(result1 executive.MutationResult, result2 error) {
+	fake.mutateMutex.Lock()
+	defer fake.mutateMutex.Unlock()
+	fake.MutateStub = nil
+	fake.mutateReturns = struct {
+		result1 executive.MutationResult
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) MutateReturnsOnCall(i int, result1 executive.MutationResult, result2 error) {
+	fake.mutateMutex.Lock()
+	defer fake.mutateMutex.Unlock()
+	fake.MutateStub = nil
+	if fake.mutateReturnsOnCall == nil {
+		fake.mutateReturnsOnCall = make(map[int]struct {
+			result1 executive.MutationResult
+			result2 error
+		})
+	}
+	fake.mutateReturnsOnCall[i] = struct {
+		result1 executive.MutationResult
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) MutateFamilies(arg1 string, arg2 string, arg3 []byte, arg4 []byte, arg5 []executive.ExecutiveMutationRequest) (executive.MutationResult, error) {
+	var arg3Copy []byte
+	if arg3 != nil {
+		arg3Copy = make([]byte, len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	var arg4Copy []byte
+	if arg4 != nil {
+		arg4Copy = make([]byte, len(arg4))
+		copy(arg4Copy, arg4)
+	}
+	var arg5Copy []executive.ExecutiveMutationRequest
+	if arg5 != nil {
+		arg5Copy = make([]executive.ExecutiveMutationRequest, len(arg5))
+		copy(arg5Copy, arg5)
+	}
+	fake.mutateFamiliesMutex.Lock()
+	ret, specificReturn := fake.mutateFamiliesReturnsOnCall[len(fake.mutateFamiliesArgsForCall)]
+	fake.mutateFamiliesArgsForCall = append(fake.mutateFamiliesArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 []byte
+		arg4 []byte
+		arg5 []executive.ExecutiveMutationRequest
+	}{arg1, arg2, arg3Copy, arg4Copy, arg5Copy})
+	stub := fake.MutateFamiliesStub
+	fakeReturns := fake.mutateFamiliesReturns
+	fake.recordInvocation("MutateFamilies", []interface{}{arg1, arg2, arg3Copy, arg4Copy, arg5Copy})
+	fake.mutateFamiliesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeExecutiveInterface) MutateFamiliesCallCount() int {
+	fake.mutateFamiliesMutex.RLock()
+	defer fake.mutateFamiliesMutex.RUnlock()
+	return len(fake.mutateFamiliesArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) MutateFamiliesCalls(stub func(string, string, []byte, []byte, []executive.ExecutiveMutationRequest) (executive.MutationResult, error)) {
+	fake.mutateFamiliesMutex.Lock()
+	defer fake.mutateFamiliesMutex.Unlock()
+	fake.MutateFamiliesStub = stub
+}
+
+func (fake *FakeExecutiveInterface) MutateFamiliesArgsForCall(i int) (string, string, []byte, []byte, []executive.ExecutiveMutationRequest) {
+	fake.mutateFamiliesMutex.RLock()
+	defer fake.mutateFamiliesMutex.RUnlock()
+	argsForCall := fake.mutateFamiliesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeExecutiveInterface) MutateFamiliesReturns(result1 executive.MutationResult, result2 error) {
+	fake.mutateFamiliesMutex.Lock()
+	defer fake.mutateFamiliesMutex.Unlock()
+	fake.MutateFamiliesStub = nil
+	fake.mutateFamiliesReturns = struct {
+		result1 executive.MutationResult
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) MutateFamiliesReturnsOnCall(i int, result1 executive.MutationResult, result2 error) {
+	fake.mutateFamiliesMutex.Lock()
+	defer fake.mutateFamiliesMutex.Unlock()
+	fake.MutateFamiliesStub = nil
+	if fake.mutateFamiliesReturnsOnCall == nil {
+		fake.mutateFamiliesReturnsOnCall = make(map[int]struct {
+			result1 executive.MutationResult
+			result2 error
+		})
+	}
+	fake.mutateFamiliesReturnsOnCall[i] = struct {
+		result1 executive.MutationResult
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadAPITokens() ([]executive.APIToken, error) {
+	fake.readAPITokensMutex.Lock()
+	ret, specificReturn := fake.readAPITokensReturnsOnCall[len(fake.readAPITokensArgsForCall)]
+	fake.readAPITokensArgsForCall = append(fake.readAPITokensArgsForCall, struct {
+	}{})
+	stub := fake.ReadAPITokensStub
+	fakeReturns := fake.readAPITokensReturns
+	fake.recordInvocation("ReadAPITokens", []interface{}{})
+	fake.readAPITokensMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeExecutiveInterface) ReadAPITokensCallCount() int {
+	fake.readAPITokensMutex.RLock()
+	defer fake.readAPITokensMutex.RUnlock()
+	return len(fake.readAPITokensArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) ReadAPITokensCalls(stub func() ([]executive.APIToken, error)) {
+	fake.readAPITokensMutex.Lock()
+	defer fake.readAPITokensMutex.Unlock()
+	fake.ReadAPITokensStub = stub
+}
+
+func (fake *FakeExecutiveInterface) ReadAPITokensReturns(result1 []executive.APIToken, result2 error) {
+	fake.readAPITokensMutex.Lock()
+	defer fake.readAPITokensMutex.Unlock()
+	fake.ReadAPITokensStub = nil
+	fake.readAPITokensReturns = struct {
+		result1 []executive.APIToken
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadAPITokensReturnsOnCall(i int, result1 []executive.APIToken, result2 error) {
+	fake.readAPITokensMutex.Lock()
+	defer fake.readAPITokensMutex.Unlock()
+	fake.ReadAPITokensStub = nil
+	if fake.readAPITokensReturnsOnCall == nil {
+		fake.readAPITokensReturnsOnCall = make(map[int]struct {
+			result1 []executive.APIToken
+			result2 error
+		})
+	}
+	fake.readAPITokensReturnsOnCall[i] = struct {
+		result1 []executive.APIToken
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadExportJob(arg1 string) (*executive.ExportJob, error) {
+	fake.readExportJobMutex.Lock()
+	ret, specificReturn := fake.readExportJobReturnsOnCall[len(fake.readExportJobArgsForCall)]
+	fake.readExportJobArgsForCall = append(fake.readExportJobArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ReadExportJobStub
+	fakeReturns := fake.readExportJobReturns
+	fake.recordInvocation("ReadExportJob", []interface{}{arg1})
+	fake.readExportJobMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeExecutiveInterface) ReadExportJobCallCount() int {
+	fake.readExportJobMutex.RLock()
+	defer fake.readExportJobMutex.RUnlock()
+	return len(fake.readExportJobArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) ReadExportJobCalls(stub func(string) (*executive.ExportJob, error)) {
+	fake.readExportJobMutex.Lock()
+	defer fake.readExportJobMutex.Unlock()
+	fake.ReadExportJobStub = stub
+}
+
+func (fake *FakeExecutiveInterface) ReadExportJobArgsForCall(i int) string {
+	fake.readExportJobMutex.RLock()
+	defer fake.readExportJobMutex.RUnlock()
+	argsForCall := fake.readExportJobArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeExecutiveInterface) ReadExportJobReturns(result1 *executive.ExportJob, result2 error) {
+	fake.readExportJobMutex.Lock()
+	defer fake.readExportJobMutex.Unlock()
+	fake.ReadExportJobStub = nil
+	fake.readExportJobReturns = struct {
+		result1 *executive.ExportJob
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadExportJobReturnsOnCall(i int, result1 *executive.ExportJob, result2 error) {
+	fake.readExportJobMutex.Lock()
+	defer fake.readExportJobMutex.Unlock()
+	fake.ReadExportJobStub = nil
+	if fake.readExportJobReturnsOnCall == nil {
+		fake.readExportJobReturnsOnCall = make(map[int]struct {
+			result1 *executive.ExportJob
+			result2 error
+		})
+	}
+	fake.readExportJobReturnsOnCall[i] = struct {
+		result1 *executive.ExportJob
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadFamilyDefaults(arg1 string) (executive.FamilyDefaults, error) {
+	fake.readFamilyDefaultsMutex.Lock()
+	ret, specificReturn := fake.readFamilyDefaultsReturnsOnCall[len(fake.readFamilyDefaultsArgsForCall)]
+	fake.readFamilyDefaultsArgsForCall = append(fake.readFamilyDefaultsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ReadFamilyDefaultsStub
+	fakeReturns := fake.readFamilyDefaultsReturns
+	fake.recordInvocation("ReadFamilyDefaults", []interface{}{arg1})
+	fake.readFamilyDefaultsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeExecutiveInterface) ReadFamilyDefaultsCallCount() int {
+	fake.readFamilyDefaultsMutex.RLock()
+	defer fake.readFamilyDefaultsMutex.RUnlock()
+	return len(fake.readFamilyDefaultsArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) ReadFamilyDefaultsCalls(stub func(string) (executive.FamilyDefaults, error)) {
+	fake.readFamilyDefaultsMutex.Lock()
+	defer fake.readFamilyDefaultsMutex.Unlock()
+	fake.ReadFamilyDefaultsStub = stub
+}
+
+func (fake *FakeExecutiveInterface) ReadFamilyDefaultsArgsForCall(i int) string {
+	fake.readFamilyDefaultsMutex.RLock()
+	defer fake.readFamilyDefaultsMutex.RUnlock()
+	argsForCall := fake.readFamilyDefaultsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeExecutiveInterface) ReadFamilyDefaultsReturns(result1 executive.FamilyDefaults, result2 error) {
+	fake.readFamilyDefaultsMutex.Lock()
+	defer fake.readFamilyDefaultsMutex.Unlock()
+	fake.ReadFamilyDefaultsStub = nil
+	fake.readFamilyDefaultsReturns = struct {
+		result1 executive.FamilyDefaults
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadFamilyDefaultsReturnsOnCall(i int, result1 executive.FamilyDefaults, result2 error) {
+	fake.readFamilyDefaultsMutex.Lock()
+	defer fake.readFamilyDefaultsMutex.Unlock()
+	fake.ReadFamilyDefaultsStub = nil
+	if fake.readFamilyDefaultsReturnsOnCall == nil {
+		fake.readFamilyDefaultsReturnsOnCall = make(map[int]struct {
+			result1 executive.FamilyDefaults
+			result2 error
+		})
+	}
+	fake.readFamilyDefaultsReturnsOnCall[i] = struct {
+		result1 executive.FamilyDefaults
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadFamilyTableNames(arg1 schema.FamilyName) ([]schema.FamilyTable, error) {
+	fake.readFamilyTableNamesMutex.Lock()
+	ret, specificReturn := fake.readFamilyTableNamesReturnsOnCall[len(fake.readFamilyTableNamesArgsForCall)]
+	fake.readFamilyTableNamesArgsForCall = append(fake.readFamilyTableNamesArgsForCall, struct {
+		arg1 schema.FamilyName
+	}{arg1})
+	stub := fake.ReadFamilyTableNamesStub
+	fakeReturns := fake.readFamilyTableNamesReturns
+	fake.recordInvocation("ReadFamilyTableNames", []interface{}{arg1})
+	fake.readFamilyTableNamesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeExecutiveInterface) ReadFamilyTableNamesCallCount() int {
+	fake.readFamilyTableNamesMutex.RLock()
+	defer fake.readFamilyTableNamesMutex.RUnlock()
+	return len(fake.readFamilyTableNamesArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) ReadFamilyTableNamesCalls(stub func(schema.FamilyName) ([]schema.FamilyTable, error)) {
+	fake.readFamilyTableNamesMutex.Lock()
+	defer fake.readFamilyTableNamesMutex.Unlock()
+	fake.ReadFamilyTableNamesStub = stub
+}
+
+func (fake *FakeExecutiveInterface) ReadFamilyTableNamesArgsForCall(i int) schema.FamilyName {
+	fake.readFamilyTableNamesMutex.RLock()
+	defer fake.readFamilyTableNamesMutex.RUnlock()
+	argsForCall := fake.readFamilyTableNamesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeExecutiveInterface) ReadFamilyTableNamesReturns(result1 []schema.FamilyTable, result2 error) {
+	fake.readFamilyTableNamesMutex.Lock()
+	defer fake.readFamilyTableNamesMutex.Unlock()
+	fake.ReadFamilyTableNamesStub = nil
+	fake.readFamilyTableNamesReturns = struct {
+		result1 []schema.FamilyTable
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadFamilyTableNamesReturnsOnCall(i int, result1 []schema.FamilyTable, result2 error) {
+	fake.readFamilyTableNamesMutex.Lock()
+	defer fake.readFamilyTableNamesMutex.Unlock()
+	fake.ReadFamilyTableNamesStub = nil
+	if fake.readFamilyTableNamesReturnsOnCall == nil {
+		fake.readFamilyTableNamesReturnsOnCall = make(map[int]struct {
+			result1 []schema.FamilyTable
+			result2 error
+		})
+	}
+	fake.readFamilyTableNamesReturnsOnCall[i] = struct {
+		result1 []schema.FamilyTable
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadLedger(arg1 executive.LedgerQuery) ([]executive.LedgerEntry, error) {
+	fake.readLedgerMutex.Lock()
+	ret, specificReturn := fake.readLedgerReturnsOnCall[len(fake.readLedgerArgsForCall)]
+	fake.readLedgerArgsForCall = append(fake.readLedgerArgsForCall, struct {
+		arg1 executive.LedgerQuery
+	}{arg1})
+	stub := fake.ReadLedgerStub
+	fakeReturns := fake.readLedgerReturns
+	fake.recordInvocation("ReadLedger", []interface{}{arg1})
+	fake.readLedgerMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeExecutiveInterface) ReadLedgerCallCount() int {
+	fake.readLedgerMutex.RLock()
+	defer fake.readLedgerMutex.RUnlock()
+	return len(fake.readLedgerArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) ReadLedgerCalls(stub func(executive.LedgerQuery) ([]executive.LedgerEntry, error)) {
+	fake.readLedgerMutex.Lock()
+	defer fake.readLedgerMutex.Unlock()
+	fake.ReadLedgerStub = stub
+}
+
+func (fake *FakeExecutiveInterface) ReadLedgerArgsForCall(i int) executive.LedgerQuery {
+	fake.readLedgerMutex.RLock()
+	defer fake.readLedgerMutex.RUnlock()
+	argsForCall := fake.readLedgerArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeExecutiveInterface) ReadLedgerReturns(result1 []executive.LedgerEntry, result2 error) {
+	fake.readLedgerMutex.Lock()
+	defer fake.readLedgerMutex.Unlock()
+	fake.ReadLedgerStub = nil
+	fake.readLedgerReturns = struct {
+		result1 []executive.LedgerEntry
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadLedgerReturnsOnCall(i int, result1 []executive.LedgerEntry, result2 error) {
+	fake.readLedgerMutex.Lock()
+	defer fake.readLedgerMutex.Unlock()
+	fake.ReadLedgerStub = nil
+	if fake.readLedgerReturnsOnCall == nil {
+		fake.readLedgerReturnsOnCall = make(map[int]struct {
+			result1 []executive.LedgerEntry
+			result2 error
+		})
+	}
+	fake.readLedgerReturnsOnCall[i] = struct {
+		result1 []executive.LedgerEntry
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadLedgerSeq() (int64, error) {
+	fake.readLedgerSeqMutex.Lock()
+	ret, specificReturn := fake.readLedgerSeqReturnsOnCall[len(fake.readLedgerSeqArgsForCall)]
+	fake.readLedgerSeqArgsForCall = append(fake.readLedgerSeqArgsForCall, struct {
+	}{})
+	stub := fake.ReadLedgerSeqStub
+	fakeReturns := fake.readLedgerSeqReturns
+	fake.recordInvocation("ReadLedgerSeq", []interface{}{})
+	fake.readLedgerSeqMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeExecutiveInterface) ReadLedgerSeqCallCount() int {
+	fake.readLedgerSeqMutex.RLock()
+	defer fake.readLedgerSeqMutex.RUnlock()
+	return len(fake.readLedgerSeqArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) ReadLedgerSeqCalls(stub func() (int64, error)) {
+	fake.readLedgerSeqMutex.Lock()
+	defer fake.readLedgerSeqMutex.Unlock()
+	fake.ReadLedgerSeqStub = stub
+}
+
+func (fake *FakeExecutiveInterface) ReadLedgerSeqReturns(result1 int64, result2 error) {
+	fake.readLedgerSeqMutex.Lock()
+	defer fake.readLedgerSeqMutex.Unlock()
+	fake.ReadLedgerSeqStub = nil
+	fake.readLedgerSeqReturns = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadLedgerSeqReturnsOnCall(i int, result1 int64, result2 error) {
+	fake.readLedgerSeqMutex.Lock()
+	defer fake.readLedgerSeqMutex.Unlock()
+	fake.ReadLedgerSeqStub = nil
+	if fake.readLedgerSeqReturnsOnCall == nil {
+		fake.readLedgerSeqReturnsOnCall = make(map[int]struct {
+			result1 int64
+			result2 error
+		})
+	}
+	fake.readLedgerSeqReturnsOnCall[i] = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadMaintenance() (executive.Maintenance, error) {
+	fake.readMaintenanceMutex.Lock()
+	ret, specificReturn := fake.readMaintenanceReturnsOnCall[len(fake.readMaintenanceArgsForCall)]
+	fake.readMaintenanceArgsForCall = append(fake.readMaintenanceArgsForCall, struct {
+	}{})
+	stub := fake.ReadMaintenanceStub
+	fakeReturns := fake.readMaintenanceReturns
+	fake.recordInvocation("ReadMaintenance", []interface{}{})
+	fake.readMaintenanceMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeExecutiveInterface) ReadMaintenanceCallCount() int {
+	fake.readMaintenanceMutex.RLock()
+	defer fake.readMaintenanceMutex.RUnlock()
+	return len(fake.readMaintenanceArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) ReadMaintenanceCalls(stub func() (executive.Maintenance, error)) {
+	fake.readMaintenanceMutex.Lock()
+	defer fake.readMaintenanceMutex.Unlock()
+	fake.ReadMaintenanceStub = stub
+}
+
+func (fake *FakeExecutiveInterface) ReadMaintenanceReturns(result1 executive.Maintenance, result2 error) {
+	fake.readMaintenanceMutex.Lock()
+	defer fake.readMaintenanceMutex.Unlock()
+	fake.ReadMaintenanceStub = nil
+	fake.readMaintenanceReturns = struct {
+		result1 executive.Maintenance
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadMaintenanceReturnsOnCall(i int, result1 executive.Maintenance, result2 error) {
+	fake.readMaintenanceMutex.Lock()
+	defer fake.readMaintenanceMutex.Unlock()
+	fake.ReadMaintenanceStub = nil
+	if fake.readMaintenanceReturnsOnCall == nil {
+		fake.readMaintenanceReturnsOnCall = make(map[int]struct {
+			result1 executive.Maintenance
+			result2 error
+		})
+	}
+	fake.readMaintenanceReturnsOnCall[i] = struct {
+		result1 executive.Maintenance
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadRow(arg1 string, arg2 string, arg3 map[string]interface{}) (map[string]interface{}, error) {
+	fake.readRowMutex.Lock()
+	ret, specificReturn := fake.readRowReturnsOnCall[len(fake.readRowArgsForCall)]
+	fake.readRowArgsForCall = append(fake.readRowArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 map[string]interface{}
+	}{arg1, arg2, arg3})
+	stub := fake.ReadRowStub
+	fakeReturns := fake.readRowReturns
+	fake.recordInvocation("ReadRow", []interface{}{arg1, arg2, arg3})
+	fake.readRowMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeExecutiveInterface) ReadRowCallCount() int {
+	fake.readRowMutex.RLock()
+	defer fake.readRowMutex.RUnlock()
+	return len(fake.readRowArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) ReadRowCalls(stub func(string, string, map[string]interface{}) (map[string]interface{}, error)) {
+	fake.readRowMutex.Lock()
+	defer fake.readRowMutex.Unlock()
+	fake.ReadRowStub = stub
+}
+
+func (fake *FakeExecutiveInterface) ReadRowArgsForCall(i int) (string, string, map[string]interface{}) {
+	fake.readRowMutex.RLock()
+	defer fake.readRowMutex.RUnlock()
+	argsForCall := fake.readRowArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeExecutiveInterface) ReadRowReturns(result1 map[string]interface{}, result2 error) {
+	fake.readRowMutex.Lock()
+	defer fake.readRowMutex.Unlock()
+	fake.ReadRowStub = nil
+	fake.readRowReturns = struct {
+		result1 map[string]interface{}
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadRowReturnsOnCall(i int, result1 map[string]interface{}, result2 error) {
+	fake.readRowMutex.Lock()
+	defer fake.readRowMutex.Unlock()
+	fake.ReadRowStub = nil
+	if fake.readRowReturnsOnCall == nil {
+		fake.readRowReturnsOnCall = make(map[int]struct {
+			result1 map[string]interface{}
+			result2 error
+		})
+	}
+	fake.readRowReturnsOnCall[i] = struct {
+		result1 map[string]interface{}
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadRows(arg1 string, arg2 string, arg3 executive.RowsQuery, arg4 func(row map[string]interface{}) error) error {
+	fake.readRowsMutex.Lock()
+	ret, specificReturn := fake.readRowsReturnsOnCall[len(fake.readRowsArgsForCall)]
+	fake.readRowsArgsForCall = append(fake.readRowsArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 executive.RowsQuery
+		arg4 func(row map[string]interface{}) error
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.ReadRowsStub
+	fakeReturns := fake.readRowsReturns
+	fake.recordInvocation("ReadRows", []interface{}{arg1, arg2, arg3, arg4})
+	fake.readRowsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeExecutiveInterface) ReadRowsCallCount() int {
+	fake.readRowsMutex.RLock()
+	defer fake.readRowsMutex.RUnlock()
+	return len(fake.readRowsArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) ReadRowsCalls(stub func(string, string, executive.RowsQuery, func(row map[string]interface{}) error) error) {
+	fake.readRowsMutex.Lock()
+	defer fake.readRowsMutex.Unlock()
+	fake.ReadRowsStub = stub
+}
+
+func (fake *FakeExecutiveInterface) ReadRowsArgsForCall(i int) (string, string, executive.RowsQuery, func(row map[string]interface{}) error) {
+	fake.readRowsMutex.RLock()
+	defer fake.readRowsMutex.RUnlock()
+	argsForCall := fake.readRowsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeExecutiveInterface) ReadRowsReturns(result1 error) {
+	fake.readRowsMutex.Lock()
+	defer fake.readRowsMutex.Unlock()
+	fake.ReadRowsStub = nil
+	fake.readRowsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) ReadRowsReturnsOnCall(i int, result1 error) {
+	fake.readRowsMutex.Lock()
+	defer fake.readRowsMutex.Unlock()
+	fake.ReadRowsStub = nil
+	if fake.readRowsReturnsOnCall == nil {
+		fake.readRowsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.readRowsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) ReadTableSizeLimits() (limits.TableSizeLimits, error) {
+	fake.readTableSizeLimitsMutex.Lock()
+	ret, specificReturn := fake.readTableSizeLimitsReturnsOnCall[len(fake.readTableSizeLimitsArgsForCall)]
+	fake.readTableSizeLimitsArgsForCall = append(fake.readTableSizeLimitsArgsForCall, struct {
+	}{})
+	stub := fake.ReadTableSizeLimitsStub
+	fakeReturns := fake.readTableSizeLimitsReturns
+	fake.recordInvocation("ReadTableSizeLimits", []interface{}{})
+	fake.readTableSizeLimitsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeExecutiveInterface) ReadTableSizeLimitsCallCount() int {
+	fake.readTableSizeLimitsMutex.RLock()
+	defer fake.readTableSizeLimitsMutex.RUnlock()
+	return len(fake.readTableSizeLimitsArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) ReadTableSizeLimitsCalls(stub func() (limits.TableSizeLimits, error)) {
+	fake.readTableSizeLimitsMutex.Lock()
+	defer fake.readTableSizeLimitsMutex.Unlock()
+	fake.ReadTableSizeLimitsStub = stub
+}
+
+func (fake *FakeExecutiveInterface) ReadTableSizeLimitsReturns(result1 limits.TableSizeLimits, result2 error) {
+	fake.readTableSizeLimitsMutex.Lock()
+	defer fake.readTableSizeLimitsMutex.Unlock()
+	fake.ReadTableSizeLimitsStub = nil
+	fake.readTableSizeLimitsReturns = struct {
+		result1 limits.TableSizeLimits
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadTableSizeLimitsReturnsOnCall(i int, result1 limits.TableSizeLimits, result2 error) {
+	fake.readTableSizeLimitsMutex.Lock()
+	defer fake.readTableSizeLimitsMutex.Unlock()
+	fake.ReadTableSizeLimitsStub = nil
+	if fake.readTableSizeLimitsReturnsOnCall == nil {
+		fake.readTableSizeLimitsReturnsOnCall = make(map[int]struct {
+			result1 limits.TableSizeLimits
+			result2 error
+		})
+	}
+	fake.readTableSizeLimitsReturnsOnCall[i] = struct {
+		result1 limits.TableSizeLimits
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadTableSizeProjection(arg1 schema.FamilyTable) (limits.TableSizeProjection, error) {
+	fake.readTableSizeProjectionMutex.Lock()
+	ret, specificReturn := fake.readTableSizeProjectionReturnsOnCall[len(fake.readTableSizeProjectionArgsForCall)]
+	fake.readTableSizeProjectionArgsForCall = append(fake.readTableSizeProjectionArgsForCall, struct {
+		arg1 schema.FamilyTable
+	}{arg1})
+	stub := fake.ReadTableSizeProjectionStub
+	fakeReturns := fake.readTableSizeProjectionReturns
+	fake.recordInvocation("ReadTableSizeProjection", []interface{}{arg1})
+	fake.readTableSizeProjectionMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeExecutiveInterface) ReadTableSizeProjectionCallCount() int {
+	fake.readTableSizeProjectionMutex.RLock()
+	defer fake.readTableSizeProjectionMutex.RUnlock()
+	return len(fake.readTableSizeProjectionArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) ReadTableSizeProjectionCalls(stub func(schema.FamilyTable) (limits.TableSizeProjection, error)) {
+	fake.readTableSizeProjectionMutex.Lock()
+	defer fake.readTableSizeProjectionMutex.Unlock()
+	fake.ReadTableSizeProjectionStub = stub
+}
+
+func (fake *FakeExecutiveInterface) ReadTableSizeProjectionArgsForCall(i int) schema.FamilyTable {
+	fake.readTableSizeProjectionMutex.RLock()
+	defer fake.readTableSizeProjectionMutex.RUnlock()
+	argsForCall := fake.readTableSizeProjectionArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeExecutiveInterface) ReadTableSizeProjectionReturns(result1 limits.TableSizeProjection, result2 error) {
+	fake.readTableSizeProjectionMutex.Lock()
+	defer fake.readTableSizeProjectionMutex.Unlock()
+	fake.ReadTableSizeProjectionStub = nil
+	fake.readTableSizeProjectionReturns = struct {
+		result1 limits.TableSizeProjection
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadTableSizeProjectionReturnsOnCall(i int, result1 limits.TableSizeProjection, result2 error) {
+	fake.readTableSizeProjectionMutex.Lock()
+	defer fake.readTableSizeProjectionMutex.Unlock()
+	fake.ReadTableSizeProjectionStub = nil
+	if fake.readTableSizeProjectionReturnsOnCall == nil {
+		fake.readTableSizeProjectionReturnsOnCall = make(map[int]struct {
+			result1 limits.TableSizeProjection
+			result2 error
+		})
+	}
+	fake.readTableSizeProjectionReturnsOnCall[i] = struct {
+		result1 limits.TableSizeProjection
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadTableTemplates(arg1 string) ([]schema.TableTemplate, error) {
+	fake.readTableTemplatesMutex.Lock()
+	ret, specificReturn := fake.readTableTemplatesReturnsOnCall[len(fake.readTableTemplatesArgsForCall)]
+	fake.readTableTemplatesArgsForCall = append(fake.readTableTemplatesArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ReadTableTemplatesStub
+	fakeReturns := fake.readTableTemplatesReturns
+	fake.recordInvocation("ReadTableTemplates", []interface{}{arg1})
+	fake.readTableTemplatesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeExecutiveInterface) ReadTableTemplatesCallCount() int {
+	fake.readTableTemplatesMutex.RLock()
+	defer fake.readTableTemplatesMutex.RUnlock()
+	return len(fake.readTableTemplatesArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) ReadTableTemplatesCalls(stub func(string) ([]schema.TableTemplate, error)) {
+	fake.readTableTemplatesMutex.Lock()
+	defer fake.readTableTemplatesMutex.Unlock()
+	fake.ReadTableTemplatesStub = stub
+}
+
+func (fake *FakeExecutiveInterface) ReadTableTemplatesArgsForCall(i int) string {
+	fake.readTableTemplatesMutex.RLock()
+	defer fake.readTableTemplatesMutex.RUnlock()
+	argsForCall := fake.readTableTemplatesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeExecutiveInterface) ReadTableTemplatesReturns(result1 []schema.TableTemplate, result2 error) {
+	fake.readTableTemplatesMutex.Lock()
+	defer fake.readTableTemplatesMutex.Unlock()
+	fake.ReadTableTemplatesStub = nil
+	fake.readTableTemplatesReturns = struct {
+		result1 []schema.TableTemplate
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadTableTemplatesReturnsOnCall(i int, result1 []schema.TableTemplate, result2 error) {
+	fake.readTableTemplatesMutex.Lock()
+	defer fake.readTableTemplatesMutex.Unlock()
+	fake.ReadTableTemplatesStub = nil
+	if fake.readTableTemplatesReturnsOnCall == nil {
+		fake.readTableTemplatesReturnsOnCall = make(map[int]struct {
+			result1 []schema.TableTemplate
+			result2 error
+		})
+	}
+	fake.readTableTemplatesReturnsOnCall[i] = struct {
+		result1 []schema.TableTemplate
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadWebhooks(arg1 string) ([]executive.Webhook, error) {
+	fake.readWebhooksMutex.Lock()
+	ret, specificReturn := fake.readWebhooksReturnsOnCall[len(fake.readWebhooksArgsForCall)]
+	fake.readWebhooksArgsForCall = append(fake.readWebhooksArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ReadWebhooksStub
+	fakeReturns := fake.readWebhooksReturns
+	fake.recordInvocation("ReadWebhooks", []interface{}{arg1})
+	fake.readWebhooksMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeExecutiveInterface) ReadWebhooksCallCount() int {
+	fake.readWebhooksMutex.RLock()
+	defer fake.readWebhooksMutex.RUnlock()
+	return len(fake.readWebhooksArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) ReadWebhooksCalls(stub func(string) ([]executive.Webhook, error)) {
+	fake.readWebhooksMutex.Lock()
+	defer fake.readWebhooksMutex.Unlock()
+	fake.ReadWebhooksStub = stub
+}
+
+func (fake *FakeExecutiveInterface) ReadWebhooksArgsForCall(i int) string {
+	fake.readWebhooksMutex.RLock()
+	defer fake.readWebhooksMutex.RUnlock()
+	argsForCall := fake.readWebhooksArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeExecutiveInterface) ReadWebhooksReturns(result1 []executive.Webhook, result2 error) {
+	fake.readWebhooksMutex.Lock()
+	defer fake.readWebhooksMutex.Unlock()
+	fake.ReadWebhooksStub = nil
+	fake.readWebhooksReturns = struct {
+		result1 []executive.Webhook
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadWebhooksReturnsOnCall(i int, result1 []executive.Webhook, result2 error) {
+	fake.readWebhooksMutex.Lock()
+	defer fake.readWebhooksMutex.Unlock()
+	fake.ReadWebhooksStub = nil
+	if fake.readWebhooksReturnsOnCall == nil {
+		fake.readWebhooksReturnsOnCall = make(map[int]struct {
+			result1 []executive.Webhook
+			result2 error
+		})
+	}
+	fake.readWebhooksReturnsOnCall[i] = struct {
+		result1 []executive.Webhook
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterActivity(arg1 string) (executive.WriterActivity, error) {
+	fake.readWriterActivityMutex.Lock()
+	ret, specificReturn := fake.readWriterActivityReturnsOnCall[len(fake.readWriterActivityArgsForCall)]
+	fake.readWriterActivityArgsForCall = append(fake.readWriterActivityArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ReadWriterActivityStub
+	fakeReturns := fake.readWriterActivityReturns
+	fake.recordInvocation("ReadWriterActivity", []interface{}{arg1})
+	fake.readWriterActivityMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterActivityCallCount() int {
+	fake.readWriterActivityMutex.RLock()
+	defer fake.readWriterActivityMutex.RUnlock()
+	return len(fake.readWriterActivityArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterActivityCalls(stub func(string) (executive.WriterActivity, error)) {
+	fake.readWriterActivityMutex.Lock()
+	defer fake.readWriterActivityMutex.Unlock()
+	fake.ReadWriterActivityStub = stub
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterActivityArgsForCall(i int) string {
+	fake.readWriterActivityMutex.RLock()
+	defer fake.readWriterActivityMutex.RUnlock()
+	argsForCall := fake.readWriterActivityArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterActivityReturns(result1 executive.WriterActivity, result2 error) {
+	fake.readWriterActivityMutex.Lock()
+	defer fake.readWriterActivityMutex.Unlock()
+	fake.ReadWriterActivityStub = nil
+	fake.readWriterActivityReturns = struct {
+		result1 executive.WriterActivity
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterActivityReturnsOnCall(i int, result1 executive.WriterActivity, result2 error) {
+	fake.readWriterActivityMutex.Lock()
+	defer fake.readWriterActivityMutex.Unlock()
+	fake.ReadWriterActivityStub = nil
+	if fake.readWriterActivityReturnsOnCall == nil {
+		fake.readWriterActivityReturnsOnCall = make(map[int]struct {
+			result1 executive.WriterActivity
+			result2 error
+		})
+	}
+	fake.readWriterActivityReturnsOnCall[i] = struct {
+		result1 executive.WriterActivity
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterGroupAudit(arg1 string) ([]executive.WriterGroupEvent, error) {
+	fake.readWriterGroupAuditMutex.Lock()
+	ret, specificReturn := fake.readWriterGroupAuditReturnsOnCall[len(fake.readWriterGroupAuditArgsForCall)]
+	fake.readWriterGroupAuditArgsForCall = append(fake.readWriterGroupAuditArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ReadWriterGroupAuditStub
+	fakeReturns := fake.readWriterGroupAuditReturns
+	fake.recordInvocation("ReadWriterGroupAudit", []interface{}{arg1})
+	fake.readWriterGroupAuditMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterGroupAuditCallCount() int {
+	fake.readWriterGroupAuditMutex.RLock()
+	defer fake.readWriterGroupAuditMutex.RUnlock()
+	return len(fake.readWriterGroupAuditArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterGroupAuditCalls(stub func(string) ([]executive.WriterGroupEvent, error)) {
+	fake.readWriterGroupAuditMutex.Lock()
+	defer fake.readWriterGroupAuditMutex.Unlock()
+	fake.ReadWriterGroupAuditStub = stub
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterGroupAuditArgsForCall(i int) string {
+	fake.readWriterGroupAuditMutex.RLock()
+	defer fake.readWriterGroupAuditMutex.RUnlock()
+	argsForCall := fake.readWriterGroupAuditArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterGroupAuditReturns(result1 []executive.WriterGroupEvent, result2 error) {
+	fake.readWriterGroupAuditMutex.Lock()
+	defer fake.readWriterGroupAuditMutex.Unlock()
+	fake.ReadWriterGroupAuditStub = nil
+	fake.readWriterGroupAuditReturns = struct {
+		result1 []executive.WriterGroupEvent
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterGroupAuditReturnsOnCall(i int, result1 []executive.WriterGroupEvent, result2 error) {
+	fake.readWriterGroupAuditMutex.Lock()
+	defer fake.readWriterGroupAuditMutex.Unlock()
+	fake.ReadWriterGroupAuditStub = nil
+	if fake.readWriterGroupAuditReturnsOnCall == nil {
+		fake.readWriterGroupAuditReturnsOnCall = make(map[int]struct {
+			result1 []executive.WriterGroupEvent
+			result2 error
+		})
+	}
+	fake.readWriterGroupAuditReturnsOnCall[i] = struct {
+		result1 []executive.WriterGroupEvent
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterGroups() ([]limits.WriterGroup, error) {
+	fake.readWriterGroupsMutex.Lock()
+	ret, specificReturn := fake.readWriterGroupsReturnsOnCall[len(fake.readWriterGroupsArgsForCall)]
+	fake.readWriterGroupsArgsForCall = append(fake.readWriterGroupsArgsForCall, struct {
+	}{})
+	stub := fake.ReadWriterGroupsStub
+	fakeReturns := fake.readWriterGroupsReturns
+	fake.recordInvocation("ReadWriterGroups", []interface{}{})
+	fake.readWriterGroupsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterGroupsCallCount() int {
+	fake.readWriterGroupAuditMutex.RLock()
+	defer fake.readWriterGroupAuditMutex.RUnlock()
+	fake.readWriterGroupsMutex.RLock()
+	defer fake.readWriterGroupsMutex.RUnlock()
+	return len(fake.readWriterGroupsArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterGroupsCalls(stub func() ([]limits.WriterGroup, error)) {
+	fake.readWriterGroupsMutex.Lock()
+	defer fake.readWriterGroupsMutex.Unlock()
+	fake.ReadWriterGroupsStub = stub
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterGroupsReturns(result1 []limits.WriterGroup, result2 error) {
+	fake.readWriterGroupsMutex.Lock()
+	defer fake.readWriterGroupsMutex.Unlock()
+	fake.ReadWriterGroupsStub = nil
+	fake.readWriterGroupsReturns = struct {
+		result1 []limits.WriterGroup
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterGroupsReturnsOnCall(i int, result1 []limits.WriterGroup, result2 error) {
+	fake.readWriterGroupsMutex.Lock()
+	defer fake.readWriterGroupsMutex.Unlock()
+	fake.ReadWriterGroupsStub = nil
+	if fake.readWriterGroupsReturnsOnCall == nil {
+		fake.readWriterGroupsReturnsOnCall = make(map[int]struct {
+			result1 []limits.WriterGroup
+			result2 error
+		})
+	}
+	fake.readWriterGroupsReturnsOnCall[i] = struct {
+		result1 []limits.WriterGroup
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterRateLimits() (limits.WriterRateLimits, error) {
+	fake.readWriterRateLimitsMutex.Lock()
+	ret, specificReturn := fake.readWriterRateLimitsReturnsOnCall[len(fake.readWriterRateLimitsArgsForCall)]
+	fake.readWriterRateLimitsArgsForCall = append(fake.readWriterRateLimitsArgsForCall, struct {
+	}{})
+	stub := fake.ReadWriterRateLimitsStub
+	fakeReturns := fake.readWriterRateLimitsReturns
+	fake.recordInvocation("ReadWriterRateLimits", []interface{}{})
+	fake.readWriterRateLimitsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterRateLimitsCallCount() int {
+	fake.readWriterRateLimitsMutex.RLock()
+	defer fake.readWriterRateLimitsMutex.RUnlock()
+	return len(fake.readWriterRateLimitsArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterRateLimitsCalls(stub func() (limits.WriterRateLimits, error)) {
+	fake.readWriterRateLimitsMutex.Lock()
+	defer fake.readWriterRateLimitsMutex.Unlock()
+	fake.ReadWriterRateLimitsStub = stub
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterRateLimitsReturns(result1 limits.WriterRateLimits, result2 error) {
+	fake.readWriterRateLimitsMutex.Lock()
+	defer fake.readWriterRateLimitsMutex.Unlock()
+	fake.ReadWriterRateLimitsStub = nil
+	fake.readWriterRateLimitsReturns = struct {
+		result1 limits.WriterRateLimits
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterRateLimitsReturnsOnCall(i int, result1 limits.WriterRateLimits, result2 error) {
+	fake.readWriterRateLimitsMutex.Lock()
+	defer fake.readWriterRateLimitsMutex.Unlock()
+	fake.ReadWriterRateLimitsStub = nil
+	if fake.readWriterRateLimitsReturnsOnCall == nil {
+		fake.readWriterRateLimitsReturnsOnCall = make(map[int]struct {
+			result1 limits.WriterRateLimits
+			result2 error
+		})
+	}
+	fake.readWriterRateLimitsReturnsOnCall[i] = struct {
+		result1 limits.WriterRateLimits
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterRegistrationAudit(arg1 string) ([]executive.WriterRegistrationEvent, error) {
+	fake.readWriterRegistrationAuditMutex.Lock()
+	ret, specificReturn := fake.readWriterRegistrationAuditReturnsOnCall[len(fake.readWriterRegistrationAuditArgsForCall)]
+	fake.readWriterRegistrationAuditArgsForCall = append(fake.readWriterRegistrationAuditArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ReadWriterRegistrationAuditStub
+	fakeReturns := fake.readWriterRegistrationAuditReturns
+	fake.recordInvocation("ReadWriterRegistrationAudit", []interface{}{arg1})
+	fake.readWriterRegistrationAuditMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterRegistrationAuditCallCount() int {
+	fake.readWriterRegistrationAuditMutex.RLock()
+	defer fake.readWriterRegistrationAuditMutex.RUnlock()
+	return len(fake.readWriterRegistrationAuditArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterRegistrationAuditCalls(stub func(string) ([]executive.WriterRegistrationEvent, error)) {
+	fake.readWriterRegistrationAuditMutex.Lock()
+	defer fake.readWriterRegistrationAuditMutex.Unlock()
+	fake.ReadWriterRegistrationAuditStub = stub
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterRegistrationAuditArgsForCall(i int) string {
+	fake.readWriterRegistrationAuditMutex.RLock()
+	defer fake.readWriterRegistrationAuditMutex.RUnlock()
+	argsForCall := fake.readWriterRegistrationAuditArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterRegistrationAuditReturns(result1 []executive.WriterRegistrationEvent, result2 error) {
+	fake.readWriterRegistrationAuditMutex.Lock()
+	defer fake.readWriterRegistrationAuditMutex.Unlock()
+	fake.ReadWriterRegistrationAuditStub = nil
+	fake.readWriterRegistrationAuditReturns = struct {
+		result1 []executive.WriterRegistrationEvent
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterRegistrationAuditReturnsOnCall(i int, result1 []executive.WriterRegistrationEvent, result2 error) {
+	fake.readWriterRegistrationAuditMutex.Lock()
+	defer fake.readWriterRegistrationAuditMutex.Unlock()
+	fake.ReadWriterRegistrationAuditStub = nil
+	if fake.readWriterRegistrationAuditReturnsOnCall == nil {
+		fake.readWriterRegistrationAuditReturnsOnCall = make(map[int]struct {
+			result1 []executive.WriterRegistrationEvent
+			result2 error
+		})
+	}
+	fake.readWriterRegistrationAuditReturnsOnCall[i] = struct {
+		result1 []executive.WriterRegistrationEvent
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterRegistrations(arg1 string) ([]executive.WriterRegistration, error) {
+	fake.readWriterRegistrationsMutex.Lock()
+	ret, specificReturn := fake.readWriterRegistrationsReturnsOnCall[len(fake.readWriterRegistrationsArgsForCall)]
+	fake.readWriterRegistrationsArgsForCall = append(fake.readWriterRegistrationsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ReadWriterRegistrationsStub
+	fakeReturns := fake.readWriterRegistrationsReturns
+	fake.recordInvocation("ReadWriterRegistrations", []interface{}{arg1})
+	fake.readWriterRegistrationsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterRegistrationsCallCount() int {
+	fake.readWriterRegistrationsMutex.RLock()
+	defer fake.readWriterRegistrationsMutex.RUnlock()
+	return len(fake.readWriterRegistrationsArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterRegistrationsCalls(stub func(string) ([]executive.WriterRegistration, error)) {
+	fake.readWriterRegistrationsMutex.Lock()
+	defer fake.readWriterRegistrationsMutex.Unlock()
+	fake.ReadWriterRegistrationsStub = stub
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterRegistrationsArgsForCall(i int) string {
+	fake.readWriterRegistrationsMutex.RLock()
+	defer fake.readWriterRegistrationsMutex.RUnlock()
+	argsForCall := fake.readWriterRegistrationsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterRegistrationsReturns(result1 []executive.WriterRegistration, result2 error) {
+	fake.readWriterRegistrationsMutex.Lock()
+	defer fake.readWriterRegistrationsMutex.Unlock()
+	fake.ReadWriterRegistrationsStub = nil
+	fake.readWriterRegistrationsReturns = struct {
+		result1 []executive.WriterRegistration
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadWriterRegistrationsReturnsOnCall(i int, result1 []executive.WriterRegistration, result2 error) {
+	fake.readWriterRegistrationsMutex.Lock()
+	defer fake.readWriterRegistrationsMutex.Unlock()
+	fake.ReadWriterRegistrationsStub = nil
+	if fake.readWriterRegistrationsReturnsOnCall == nil {
+		fake.readWriterRegistrationsReturnsOnCall = make(map[int]struct {
+			result1 []executive.WriterRegistration
+			result2 error
+		})
+	}
+	fake.readWriterRegistrationsReturnsOnCall[i] = struct {
+		result1 []executive.WriterRegistration
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) ReadWriters() ([]executive.WriterInfo, error) {
+	fake.readWritersMutex.Lock()
+	ret, specificReturn := fake.readWritersReturnsOnCall[len(fake.readWritersArgsForCall)]
+	fake.readWritersArgsForCall = append(fake.readWritersArgsForCall, struct {
+	}{})
+	stub := fake.ReadWritersStub
+	fakeReturns := fake.readWritersReturns
+	fake.recordInvocation("ReadWriters", []interface{}{})
+	fake.readWritersMutex.Unlock()
+	if stub != nil {
+		return stub()
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -874,83 +3950,185 @@ func (fake *FakeExecutiveInterface) GetWriterCookie(arg1 string, arg2 string) ([
 	return fakeReturns.result1, fakeReturns.result2
 }
 
-func (fake *FakeExecutiveInterface) GetWriterCookieCallCount() int {
-	fake.getWriterCookieMutex.RLock()
-	defer fake.getWriterCookieMutex.RUnlock()
-	return len(fake.getWriterCookieArgsForCall)
+func (fake *FakeExecutiveInterface) ReadWritersCallCount() int {
+	fake.readWritersMutex.RLock()
+	defer fake.readWritersMutex.RUnlock()
+	return len(fake.readWritersArgsForCall)
 }
 
-func (fake *FakeExecutiveInterface) GetWriterCookieCalls(stub func(string, string) ([]byte, error)) {
-	fake.getWriterCookieMutex.Lock()
-	defer fake.getWriterCookieMutex.Unlock()
-	fake.GetWriterCookieStub = stub
+func (fake *FakeExecutiveInterface) ReadWritersCalls(stub func() ([]executive.WriterInfo, error)) {
+	fake.readWritersMutex.Lock()
+	defer fake.readWritersMutex.Unlock()
+	fake.ReadWritersStub = stub
 }
 
-func (fake *FakeExecutiveInterface) GetWriterCookieArgsForCall(i int) (string, string) {
-	fake.getWriterCookieMutex.RLock()
-	defer fake.getWriterCookieMutex.RUnlock()
-	argsForCall := fake.getWriterCookieArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+func (fake *FakeExecutiveInterface) ReadWritersReturns(result1 []executive.WriterInfo, result2 error) {
+	fake.readWritersMutex.Lock()
+	defer fake.readWritersMutex.Unlock()
+	fake.ReadWritersStub = nil
+	fake.readWritersReturns = struct {
+		result1 []executive.WriterInfo
+		result2 error
+	}{result1, result2}
 }
 
-func (fake *FakeExecutiveInterface) GetWriterCookieReturns(result1 []byte, result2 error) {
-	fake.getWriterCookieMutex.Lock()
-	defer fake.getWriterCookieMutex.Unlock()
-	fake.GetWriterCookieStub = nil
-	fake.getWriterCookieReturns = struct {
-		result1 []byte
+func (fake *FakeExecutiveInterface) ReadWritersReturnsOnCall(i int, result1 []executive.WriterInfo, result2 error) {
+	fake.readWritersMutex.Lock()
+	defer fake.readWritersMutex.Unlock()
+	fake.ReadWritersStub = nil
+	if fake.readWritersReturnsOnCall == nil {
+		fake.readWritersReturnsOnCall = make(map[int]struct {
+			result1 []executive.WriterInfo
+			result2 error
+		})
+	}
+	fake.readWritersReturnsOnCall[i] = struct {
+		result1 []executive.WriterInfo
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeExecutiveInterface) GetWriterCookieReturnsOnCall(i int, result1 []byte, result2 error) {
-	fake.getWriterCookieMutex.Lock()
-	defer fake.getWriterCookieMutex.Unlock()
-	fake.GetWriterCookieStub = nil
-	if fake.getWriterCookieReturnsOnCall == nil {
-		fake.getWriterCookieReturnsOnCall = make(map[int]struct {
-			result1 []byte
+func (fake *FakeExecutiveInterface) RegisterWebhook(arg1 string, arg2 string, arg3 string) (executive.Webhook, error) {
+	fake.registerWebhookMutex.Lock()
+	ret, specificReturn := fake.registerWebhookReturnsOnCall[len(fake.registerWebhookArgsForCall)]
+	fake.registerWebhookArgsForCall = append(fake.registerWebhookArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.RegisterWebhookStub
+	fakeReturns := fake.registerWebhookReturns
+	fake.recordInvocation("RegisterWebhook", []interface{}{arg1, arg2, arg3})
+	fake.registerWebhookMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeExecutiveInterface) RegisterWebhookCallCount() int {
+	fake.registerWebhookMutex.RLock()
+	defer fake.registerWebhookMutex.RUnlock()
+	return len(fake.registerWebhookArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) RegisterWebhookCalls(stub func(string, string, string) (executive.Webhook, error)) {
+	fake.registerWebhookMutex.Lock()
+	defer fake.registerWebhookMutex.Unlock()
+	fake.RegisterWebhookStub = stub
+}
+
+func (fake *FakeExecutiveInterface) RegisterWebhookArgsForCall(i int) (string, string, string) {
+	fake.registerWebhookMutex.RLock()
+	defer fake.registerWebhookMutex.RUnlock()
+	argsForCall := fake.registerWebhookArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeExecutiveInterface) RegisterWebhookReturns(result1 executive.Webhook, result2 error) {
+	fake.registerWebhookMutex.Lock()
+	defer fake.registerWebhookMutex.Unlock()
+	fake.RegisterWebhookStub = nil
+	fake.registerWebhookReturns = struct {
+		result1 executive.Webhook
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) RegisterWebhookReturnsOnCall(i int, result1 executive.Webhook, result2 error) {
+	fake.registerWebhookMutex.Lock()
+	defer fake.registerWebhookMutex.Unlock()
+	fake.RegisterWebhookStub = nil
+	if fake.registerWebhookReturnsOnCall == nil {
+		fake.registerWebhookReturnsOnCall = make(map[int]struct {
+			result1 executive.Webhook
 			result2 error
 		})
 	}
-	fake.getWriterCookieReturnsOnCall[i] = struct {
-		result1 []byte
+	fake.registerWebhookReturnsOnCall[i] = struct {
+		result1 executive.Webhook
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeExecutiveInterface) Mutate(arg1 string, arg2 string, arg3 string, arg4 []byte, arg5 []byte, arg6 []executive.ExecutiveMutationRequest) error {
-	var arg4Copy []byte
-	if arg4 != nil {
-		arg4Copy = make([]byte, len(arg4))
-		copy(arg4Copy, arg4)
+func (fake *FakeExecutiveInterface) RegisterWriter(arg1 string, arg2 string) error {
+	fake.registerWriterMutex.Lock()
+	ret, specificReturn := fake.registerWriterReturnsOnCall[len(fake.registerWriterArgsForCall)]
+	fake.registerWriterArgsForCall = append(fake.registerWriterArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.RegisterWriterStub
+	fakeReturns := fake.registerWriterReturns
+	fake.recordInvocation("RegisterWriter", []interface{}{arg1, arg2})
+	fake.registerWriterMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
-	var arg5Copy []byte
-	if arg5 != nil {
-		arg5Copy = make([]byte, len(arg5))
-		copy(arg5Copy, arg5)
+	if specificReturn {
+		return ret.result1
 	}
-	var arg6Copy []executive.ExecutiveMutationRequest
-	if arg6 != nil {
-		arg6Copy = make([]executive.ExecutiveMutationRequest, len(arg6))
-		copy(arg6Copy, arg6)
+	return fakeReturns.result1
+}
+
+func (fake *FakeExecutiveInterface) RegisterWriterCallCount() int {
+	fake.registerWriterMutex.RLock()
+	defer fake.registerWriterMutex.RUnlock()
+	return len(fake.registerWriterArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) RegisterWriterCalls(stub func(string, string) error) {
+	fake.registerWriterMutex.Lock()
+	defer fake.registerWriterMutex.Unlock()
+	fake.RegisterWriterStub = stub
+}
+
+func (fake *FakeExecutiveInterface) RegisterWriterArgsForCall(i int) (string, string) {
+	fake.registerWriterMutex.RLock()
+	defer fake.registerWriterMutex.RUnlock()
+	argsForCall := fake.registerWriterArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeExecutiveInterface) RegisterWriterReturns(result1 error) {
+	fake.registerWriterMutex.Lock()
+	defer fake.registerWriterMutex.Unlock()
+	fake.RegisterWriterStub = nil
+	fake.registerWriterReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) RegisterWriterReturnsOnCall(i int, result1 error) {
+	fake.registerWriterMutex.Lock()
+	defer fake.registerWriterMutex.Unlock()
+	fake.RegisterWriterStub = nil
+	if fake.registerWriterReturnsOnCall == nil {
+		fake.registerWriterReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
 	}
-	fake.mutateMutex.Lock()
-	ret, specificReturn := fake.mutateReturnsOnCall[len(fake.mutateArgsForCall)]
-	fake.mutateArgsForCall = append(fake.mutateArgsForCall, struct {
+	fake.registerWriterReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) RejectWriterRegistration(arg1 string, arg2 executive.WriterReview) error {
+	fake.rejectWriterRegistrationMutex.Lock()
+	ret, specificReturn := fake.rejectWriterRegistrationReturnsOnCall[len(fake.rejectWriterRegistrationArgsForCall)]
+	fake.rejectWriterRegistrationArgsForCall = append(fake.rejectWriterRegistrationArgsForCall, struct {
 		arg1 string
-		arg2 string
-		arg3 string
-		arg4 []byte
-		arg5 []byte
-		arg6 []executive.ExecutiveMutationRequest
-	}{arg1, arg2, arg3, arg4Copy, arg5Copy, arg6Copy})
-	stub := fake.MutateStub
-	fakeReturns := fake.mutateReturns
-	fake.recordInvocation("Mutate", []interface{}{arg1, arg2, arg3, arg4Copy, arg5Copy, arg6Copy})
-	fake.mutateMutex.Unlock()
+		arg2 executive.WriterReview
+	}{arg1, arg2})
+	stub := fake.RejectWriterRegistrationStub
+	fakeReturns := fake.rejectWriterRegistrationReturns
+	fake.recordInvocation("RejectWriterRegistration", []interface{}{arg1, arg2})
+	fake.rejectWriterRegistrationMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3, arg4, arg5, arg6)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
@@ -958,124 +4136,122 @@ func (fake *FakeExecutiveInterface) Mutate(arg1 string, arg2 string, arg3 string
 	return fakeReturns.result1
 }
 
-func (fake *FakeExecutiveInterface) MutateCallCount() int {
-	fake.mutateMutex.RLock()
-	defer fake.mutateMutex.RUnlock()
-	return len(fake.mutateArgsForCall)
+func (fake *FakeExecutiveInterface) RejectWriterRegistrationCallCount() int {
+	fake.rejectWriterRegistrationMutex.RLock()
+	defer fake.rejectWriterRegistrationMutex.RUnlock()
+	return len(fake.rejectWriterRegistrationArgsForCall)
 }
 
-func (fake *FakeExecutiveInterface) MutateCalls(stub func(string, string, string, []byte, []byte, []executive.ExecutiveMutationRequest) error) {
-	fake.mutateMutex.Lock()
-	defer fake.mutateMutex.Unlock()
-	fake.MutateStub = stub
+func (fake *FakeExecutiveInterface) RejectWriterRegistrationCalls(stub func(string, executive.WriterReview) error) {
+	fake.rejectWriterRegistrationMutex.Lock()
+	defer fake.rejectWriterRegistrationMutex.Unlock()
+	fake.RejectWriterRegistrationStub = stub
 }
 
-func (fake *FakeExecutiveInterface) MutateArgsForCall(i int) (string, string, string, []byte, []byte, []executive.ExecutiveMutationRequest) {
-	fake.mutateMutex.RLock()
-	defer fake.mutateMutex.RUnlock()
-	argsForCall := fake.mutateArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5, argsForCall.arg6
+func (fake *FakeExecutiveInterface) RejectWriterRegistrationArgsForCall(i int) (string, executive.WriterReview) {
+	fake.rejectWriterRegistrationMutex.RLock()
+	defer fake.rejectWriterRegistrationMutex.RUnlock()
+	argsForCall := fake.rejectWriterRegistrationArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
 }
 
-func (fake *FakeExecutiveInterface) MutateReturns(result1 error) {
-	fake.mutateMutex.Lock()
-	defer fake.mutateMutex.Unlock()
-	fake.MutateStub = nil
-	fake.mutateReturns = struct {
+func (fake *FakeExecutiveInterface) RejectWriterRegistrationReturns(result1 error) {
+	fake.rejectWriterRegistrationMutex.Lock()
+	defer fake.rejectWriterRegistrationMutex.Unlock()
+	fake.RejectWriterRegistrationStub = nil
+	fake.rejectWriterRegistrationReturns = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *FakeExecutiveInterface) MutateReturnsOnCall(i int, result1 error) {
-	fake.mutateMutex.Lock()
-	defer fake.mutateMutex.Unlock()
-	fake.MutateStub = nil
-	if fake.mutateReturnsOnCall == nil {
-		fake.mutateReturnsOnCall = make(map[int]struct {
+func (fake *FakeExecutiveInterface) RejectWriterRegistrationReturnsOnCall(i int, result1 error) {
+	fake.rejectWriterRegistrationMutex.Lock()
+	defer fake.rejectWriterRegistrationMutex.Unlock()
+	fake.RejectWriterRegistrationStub = nil
+	if fake.rejectWriterRegistrationReturnsOnCall == nil {
+		fake.rejectWriterRegistrationReturnsOnCall = make(map[int]struct {
 			result1 error
 		})
 	}
-	fake.mutateReturnsOnCall[i] = struct {
+	fake.rejectWriterRegistrationReturnsOnCall[i] = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *FakeExecutiveInterface) ReadFamilyTableNames(arg1 schema.FamilyName) ([]schema.FamilyTable, error) {
-	fake.readFamilyTableNamesMutex.Lock()
-	ret, specificReturn := fake.readFamilyTableNamesReturnsOnCall[len(fake.readFamilyTableNamesArgsForCall)]
-	fake.readFamilyTableNamesArgsForCall = append(fake.readFamilyTableNamesArgsForCall, struct {
-		arg1 schema.FamilyName
-	}{arg1})
-	stub := fake.ReadFamilyTableNamesStub
-	fakeReturns := fake.readFamilyTableNamesReturns
-	fake.recordInvocation("ReadFamilyTableNames", []interface{}{arg1})
-	fake.readFamilyTableNamesMutex.Unlock()
+func (fake *FakeExecutiveInterface) RemoveWriterGroupMember(arg1 string, arg2 string) error {
+	fake.removeWriterGroupMemberMutex.Lock()
+	ret, specificReturn := fake.removeWriterGroupMemberReturnsOnCall[len(fake.removeWriterGroupMemberArgsForCall)]
+	fake.removeWriterGroupMemberArgsForCall = append(fake.removeWriterGroupMemberArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.RemoveWriterGroupMemberStub
+	fakeReturns := fake.removeWriterGroupMemberReturns
+	fake.recordInvocation("RemoveWriterGroupMember", []interface{}{arg1, arg2})
+	fake.removeWriterGroupMemberMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
-		return ret.result1, ret.result2
+		return ret.result1
 	}
-	return fakeReturns.result1, fakeReturns.result2
+	return fakeReturns.result1
 }
 
-func (fake *FakeExecutiveInterface) ReadFamilyTableNamesCallCount() int {
-	fake.readFamilyTableNamesMutex.RLock()
-	defer fake.readFamilyTableNamesMutex.RUnlock()
-	return len(fake.readFamilyTableNamesArgsForCall)
+func (fake *FakeExecutiveInterface) RemoveWriterGroupMemberCallCount() int {
+	fake.removeWriterGroupMemberMutex.RLock()
+	defer fake.removeWriterGroupMemberMutex.RUnlock()
+	return len(fake.removeWriterGroupMemberArgsForCall)
 }
 
-func (fake *FakeExecutiveInterface) ReadFamilyTableNamesCalls(stub func(schema.FamilyName) ([]schema.FamilyTable, error)) {
-	fake.readFamilyTableNamesMutex.Lock()
-	defer fake.readFamilyTableNamesMutex.Unlock()
-	fake.ReadFamilyTableNamesStub = stub
+func (fake *FakeExecutiveInterface) RemoveWriterGroupMemberCalls(stub func(string, string) error) {
+	fake.removeWriterGroupMemberMutex.Lock()
+	defer fake.removeWriterGroupMemberMutex.Unlock()
+	fake.RemoveWriterGroupMemberStub = stub
 }
 
-func (fake *FakeExecutiveInterface) ReadFamilyTableNamesArgsForCall(i int) schema.FamilyName {
-	fake.readFamilyTableNamesMutex.RLock()
-	defer fake.readFamilyTableNamesMutex.RUnlock()
-	argsForCall := fake.readFamilyTableNamesArgsForCall[i]
-	return argsForCall.arg1
+func (fake *FakeExecutiveInterface) RemoveWriterGroupMemberArgsForCall(i int) (string, string) {
+	fake.removeWriterGroupMemberMutex.RLock()
+	defer fake.removeWriterGroupMemberMutex.RUnlock()
+	argsForCall := fake.removeWriterGroupMemberArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
 }
 
-func (fake *FakeExecutiveInterface) ReadFamilyTableNamesReturns(result1 []schema.FamilyTable, result2 error) {
-	fake.readFamilyTableNamesMutex.Lock()
-	defer fake.readFamilyTableNamesMutex.Unlock()
-	fake.ReadFamilyTableNamesStub = nil
-	fake.readFamilyTableNamesReturns = struct {
-		result1 []schema.FamilyTable
-		result2 error
-	}{result1, result2}
+func (fake *FakeExecutiveInterface) RemoveWriterGroupMemberReturns(result1 error) {
+	fake.removeWriterGroupMemberMutex.Lock()
+	defer fake.removeWriterGroupMemberMutex.Unlock()
+	fake.RemoveWriterGroupMemberStub = nil
+	fake.removeWriterGroupMemberReturns = struct {
+		result1 error
+	}{result1}
 }
 
-func (fake *FakeExecutiveInterface) ReadFamilyTableNamesReturnsOnCall(i int, result1 []schema.FamilyTable, result2 error) {
-	fake.readFamilyTableNamesMutex.Lock()
-	defer fake.readFamilyTableNamesMutex.Unlock()
-	fake.ReadFamilyTableNamesStub = nil
-	if fake.readFamilyTableNamesReturnsOnCall == nil {
-		fake.readFamilyTableNamesReturnsOnCall = make(map[int]struct {
-			result1 []schema.FamilyTable
-			result2 error
+func (fake *FakeExecutiveInterface) RemoveWriterGroupMemberReturnsOnCall(i int, result1 error) {
+	fake.removeWriterGroupMemberMutex.Lock()
+	defer fake.removeWriterGroupMemberMutex.Unlock()
+	fake.RemoveWriterGroupMemberStub = nil
+	if fake.removeWriterGroupMemberReturnsOnCall == nil {
+		fake.removeWriterGroupMemberReturnsOnCall = make(map[int]struct {
+			result1 error
 		})
 	}
-	fake.readFamilyTableNamesReturnsOnCall[i] = struct {
-		result1 []schema.FamilyTable
-		result2 error
-	}{result1, result2}
+	fake.removeWriterGroupMemberReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
 }
 
-func (fake *FakeExecutiveInterface) ReadRow(arg1 string, arg2 string, arg3 map[string]interface{}) (map[string]interface{}, error) {
-	fake.readRowMutex.Lock()
-	ret, specificReturn := fake.readRowReturnsOnCall[len(fake.readRowArgsForCall)]
-	fake.readRowArgsForCall = append(fake.readRowArgsForCall, struct {
+func (fake *FakeExecutiveInterface) RequestWriterRegistration(arg1 string, arg2 string, arg3 string) (executive.WriterRegistration, error) {
+	fake.requestWriterRegistrationMutex.Lock()
+	ret, specificReturn := fake.requestWriterRegistrationReturnsOnCall[len(fake.requestWriterRegistrationArgsForCall)]
+	fake.requestWriterRegistrationArgsForCall = append(fake.requestWriterRegistrationArgsForCall, struct {
 		arg1 string
 		arg2 string
-		arg3 map[string]interface{}
+		arg3 string
 	}{arg1, arg2, arg3})
-	stub := fake.ReadRowStub
-	fakeReturns := fake.readRowReturns
-	fake.recordInvocation("ReadRow", []interface{}{arg1, arg2, arg3})
-	fake.readRowMutex.Unlock()
+	stub := fake.RequestWriterRegistrationStub
+	fakeReturns := fake.requestWriterRegistrationReturns
+	fake.recordInvocation("RequestWriterRegistration", []interface{}{arg1, arg2, arg3})
+	fake.requestWriterRegistrationMutex.Unlock()
 	if stub != nil {
 		return stub(arg1, arg2, arg3)
 	}
@@ -1084,177 +4260,186 @@ func (fake *FakeExecutiveInterface) ReadRow(arg1 string, arg2 string, arg3 map[s
 	}
 	return fakeReturns.result1, fakeReturns.result2
 }
-
-func (fake *FakeExecutiveInterface) ReadRowCallCount() int {
-	fake.readRowMutex.RLock()
-	defer fake.readRowMutex.RUnlock()
-	return len(fake.readRowArgsForCall)
+
+func (fake *FakeExecutiveInterface) RequestWriterRegistrationCallCount() int {
+	fake.requestWriterRegistrationMutex.RLock()
+	defer fake.requestWriterRegistrationMutex.RUnlock()
+	return len(fake.requestWriterRegistrationArgsForCall)
 }
 
-func (fake *FakeExecutiveInterface) ReadRowCalls(stub func(string, string, map[string]interface{}) (map[string]interface{}, error)) {
-	fake.readRowMutex.Lock()
-	defer fake.readRowMutex.Unlock()
-	fake.ReadRowStub = stub
+func (fake *FakeExecutiveInterface) RequestWriterRegistrationCalls(stub func(string, string, string) (executive.WriterRegistration, error)) {
+	fake.requestWriterRegistrationMutex.Lock()
+	defer fake.requestWriterRegistrationMutex.Unlock()
+	fake.RequestWriterRegistrationStub = stub
 }
 
-func (fake *FakeExecutiveInterface) ReadRowArgsForCall(i int) (string, string, map[string]interface{}) {
-	fake.readRowMutex.RLock()
-	defer fake.readRowMutex.RUnlock()
-	argsForCall := fake.readRowArgsForCall[i]
+func (fake *FakeExecutiveInterface) RequestWriterRegistrationArgsForCall(i int) (string, string, string) {
+	fake.requestWriterRegistrationMutex.RLock()
+	defer fake.requestWriterRegistrationMutex.RUnlock()
+	argsForCall := fake.requestWriterRegistrationArgsForCall[i]
 	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
-func (fake *FakeExecutiveInterface) ReadRowReturns(result1 map[string]interface{}, result2 error) {
-	fake.readRowMutex.Lock()
-	defer fake.readRowMutex.Unlock()
-	fake.ReadRowStub = nil
-	fake.readRowReturns = struct {
-		result1 map[string]interface{}
+func (fake *FakeExecutiveInterface) RequestWriterRegistrationReturns(result1 executive.WriterRegistration, result2 error) {
+	fake.requestWriterRegistrationMutex.Lock()
+	defer fake.requestWriterRegistrationMutex.Unlock()
+	fake.RequestWriterRegistrationStub = nil
+	fake.requestWriterRegistrationReturns = struct {
+		result1 executive.WriterRegistration
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeExecutiveInterface) ReadRowReturnsOnCall(i int, result1 map[string]interface{}, result2 error) {
-	fake.readRowMutex.Lock()
-	defer fake.readRowMutex.Unlock()
-	fake.ReadRowStub = nil
-	if fake.readRowReturnsOnCall == nil {
-		fake.readRowReturnsOnCall = make(map[int]struct {
-			result1 map[string]interface{}
+func (fake *FakeExecutiveInterface) RequestWriterRegistrationReturnsOnCall(i int, result1 executive.WriterRegistration, result2 error) {
+	fake.requestWriterRegistrationMutex.Lock()
+	defer fake.requestWriterRegistrationMutex.Unlock()
+	fake.RequestWriterRegistrationStub = nil
+	if fake.requestWriterRegistrationReturnsOnCall == nil {
+		fake.requestWriterRegistrationReturnsOnCall = make(map[int]struct {
+			result1 executive.WriterRegistration
 			result2 error
 		})
 	}
-	fake.readRowReturnsOnCall[i] = struct {
-		result1 map[string]interface{}
+	fake.requestWriterRegistrationReturnsOnCall[i] = struct {
+		result1 executive.WriterRegistration
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeExecutiveInterface) ReadTableSizeLimits() (limits.TableSizeLimits, error) {
-	fake.readTableSizeLimitsMutex.Lock()
-	ret, specificReturn := fake.readTableSizeLimitsReturnsOnCall[len(fake.readTableSizeLimitsArgsForCall)]
-	fake.readTableSizeLimitsArgsForCall = append(fake.readTableSizeLimitsArgsForCall, struct {
-	}{})
-	stub := fake.ReadTableSizeLimitsStub
-	fakeReturns := fake.readTableSizeLimitsReturns
-	fake.recordInvocation("ReadTableSizeLimits", []interface{}{})
-	fake.readTableSizeLimitsMutex.Unlock()
+func (fake *FakeExecutiveInterface) SaveFamilyDefaults(arg1 executive.FamilyDefaults) error {
+	fake.saveFamilyDefaultsMutex.Lock()
+	ret, specificReturn := fake.saveFamilyDefaultsReturnsOnCall[len(fake.saveFamilyDefaultsArgsForCall)]
+	fake.saveFamilyDefaultsArgsForCall = append(fake.saveFamilyDefaultsArgsForCall, struct {
+		arg1 executive.FamilyDefaults
+	}{arg1})
+	stub := fake.SaveFamilyDefaultsStub
+	fakeReturns := fake.saveFamilyDefaultsReturns
+	fake.recordInvocation("SaveFamilyDefaults", []interface{}{arg1})
+	fake.saveFamilyDefaultsMutex.Unlock()
 	if stub != nil {
-		return stub()
+		return stub(arg1)
 	}
 	if specificReturn {
-		return ret.result1, ret.result2
+		return ret.result1
 	}
-	return fakeReturns.result1, fakeReturns.result2
+	return fakeReturns.result1
 }
 
-func (fake *FakeExecutiveInterface) ReadTableSizeLimitsCallCount() int {
-	fake.readTableSizeLimitsMutex.RLock()
-	defer fake.readTableSizeLimitsMutex.RUnlock()
-	return len(fake.readTableSizeLimitsArgsForCall)
+func (fake *FakeExecutiveInterface) SaveFamilyDefaultsCallCount() int {
+	fake.saveFamilyDefaultsMutex.RLock()
+	defer fake.saveFamilyDefaultsMutex.RUnlock()
+	return len(fake.saveFamilyDefaultsArgsForCall)
 }
 
-func (fake *FakeExecutiveInterface) ReadTableSizeLimitsCalls(stub func() (limits.TableSizeLimits, error)) {
-	fake.readTableSizeLimitsMutex.Lock()
-	defer fake.readTableSizeLimitsMutex.Unlock()
-	fake.ReadTableSizeLimitsStub = stub
+func (fake *FakeExecutiveInterface) SaveFamilyDefaultsCalls(stub func(executive.FamilyDefaults) error) {
+	fake.saveFamilyDefaultsMutex.Lock()
+	defer fake.saveFamilyDefaultsMutex.Unlock()
+	fake.SaveFamilyDefaultsStub = stub
 }
 
-func (fake *FakeExecutiveInterface) ReadTableSizeLimitsReturns(result1 limits.TableSizeLimits, result2 error) {
-	fake.readTableSizeLimitsMutex.Lock()
-	defer fake.readTableSizeLimitsMutex.Unlock()
-	fake.ReadTableSizeLimitsStub = nil
-	fake.readTableSizeLimitsReturns = struct {
-		result1 limits.TableSizeLimits
-		result2 error
-	}{result1, result2}
+func (fake *FakeExecutiveInterface) SaveFamilyDefaultsArgsForCall(i int) executive.FamilyDefaults {
+	fake.saveFamilyDefaultsMutex.RLock()
+	defer fake.saveFamilyDefaultsMutex.RUnlock()
+	argsForCall := fake.saveFamilyDefaultsArgsForCall[i]
+	return argsForCall.arg1
 }
 
-func (fake *FakeExecutiveInterface) ReadTableSizeLimitsReturnsOnCall(i int, result1 limits.TableSizeLimits, result2 error) {
-	fake.readTableSizeLimitsMutex.Lock()
-	defer fake.readTableSizeLimitsMutex.Unlock()
-	fake.ReadTableSizeLimitsStub = nil
-	if fake.readTableSizeLimitsReturnsOnCall == nil {
-		fake.readTableSizeLimitsReturnsOnCall = make(map[int]struct {
-			result1 limits.TableSizeLimits
-			result2 error
+func (fake *FakeExecutiveInterface) SaveFamilyDefaultsReturns(result1 error) {
+	fake.saveFamilyDefaultsMutex.Lock()
+	defer fake.saveFamilyDefaultsMutex.Unlock()
+	fake.SaveFamilyDefaultsStub = nil
+	fake.saveFamilyDefaultsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) SaveFamilyDefaultsReturnsOnCall(i int, result1 error) {
+	fake.saveFamilyDefaultsMutex.Lock()
+	defer fake.saveFamilyDefaultsMutex.Unlock()
+	fake.SaveFamilyDefaultsStub = nil
+	if fake.saveFamilyDefaultsReturnsOnCall == nil {
+		fake.saveFamilyDefaultsReturnsOnCall = make(map[int]struct {
+			result1 error
 		})
 	}
-	fake.readTableSizeLimitsReturnsOnCall[i] = struct {
-		result1 limits.TableSizeLimits
-		result2 error
-	}{result1, result2}
+	fake.saveFamilyDefaultsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
 }
 
-func (fake *FakeExecutiveInterface) ReadWriterRateLimits() (limits.WriterRateLimits, error) {
-	fake.readWriterRateLimitsMutex.Lock()
-	ret, specificReturn := fake.readWriterRateLimitsReturnsOnCall[len(fake.readWriterRateLimitsArgsForCall)]
-	fake.readWriterRateLimitsArgsForCall = append(fake.readWriterRateLimitsArgsForCall, struct {
-	}{})
-	stub := fake.ReadWriterRateLimitsStub
-	fakeReturns := fake.readWriterRateLimitsReturns
-	fake.recordInvocation("ReadWriterRateLimits", []interface{}{})
-	fake.readWriterRateLimitsMutex.Unlock()
+func (fake *FakeExecutiveInterface) SaveTableTemplate(arg1 schema.TableTemplate) error {
+	fake.saveTableTemplateMutex.Lock()
+	ret, specificReturn := fake.saveTableTemplateReturnsOnCall[len(fake.saveTableTemplateArgsForCall)]
+	fake.saveTableTemplateArgsForCall = append(fake.saveTableTemplateArgsForCall, struct {
+		arg1 schema.TableTemplate
+	}{arg1})
+	stub := fake.SaveTableTemplateStub
+	fakeReturns := fake.saveTableTemplateReturns
+	fake.recordInvocation("SaveTableTemplate", []interface{}{arg1})
+	fake.saveTableTemplateMutex.Unlock()
 	if stub != nil {
-		return stub()
+		return stub(arg1)
 	}
 	if specificReturn {
-		return ret.result1, ret.result2
+		return ret.result1
 	}
-	return fakeReturns.result1, fakeReturns.result2
+	return fakeReturns.result1
 }
 
-func (fake *FakeExecutiveInterface) ReadWriterRateLimitsCallCount() int {
-	fake.readWriterRateLimitsMutex.RLock()
-	defer fake.readWriterRateLimitsMutex.RUnlock()
-	return len(fake.readWriterRateLimitsArgsForCall)
+func (fake *FakeExecutiveInterface) SaveTableTemplateCallCount() int {
+	fake.saveTableTemplateMutex.RLock()
+	defer fake.saveTableTemplateMutex.RUnlock()
+	return len(fake.saveTableTemplateArgsForCall)
 }
 
-func (fake *FakeExecutiveInterface) ReadWriterRateLimitsCalls(stub func() (limits.WriterRateLimits, error)) {
-	fake.readWriterRateLimitsMutex.Lock()
-	defer fake.readWriterRateLimitsMutex.Unlock()
-	fake.ReadWriterRateLimitsStub = stub
+func (fake *FakeExecutiveInterface) SaveTableTemplateCalls(stub func(schema.TableTemplate) error) {
+	fake.saveTableTemplateMutex.Lock()
+	defer fake.saveTableTemplateMutex.Unlock()
+	fake.SaveTableTemplateStub = stub
 }
 
-func (fake *FakeExecutiveInterface) ReadWriterRateLimitsReturns(result1 limits.WriterRateLimits, result2 error) {
-	fake.readWriterRateLimitsMutex.Lock()
-	defer fake.readWriterRateLimitsMutex.Unlock()
-	fake.ReadWriterRateLimitsStub = nil
-	fake.readWriterRateLimitsReturns = struct {
-		result1 limits.WriterRateLimits
-		result2 error
-	}{result1, result2}
+func (fake *FakeExecutiveInterface) SaveTableTemplateArgsForCall(i int) schema.TableTemplate {
+	fake.saveTableTemplateMutex.RLock()
+	defer fake.saveTableTemplateMutex.RUnlock()
+	argsForCall := fake.saveTableTemplateArgsForCall[i]
+	return argsForCall.arg1
 }
 
-func (fake *FakeExecutiveInterface) ReadWriterRateLimitsReturnsOnCall(i int, result1 limits.WriterRateLimits, result2 error) {
-	fake.readWriterRateLimitsMutex.Lock()
-	defer fake.readWriterRateLimitsMutex.Unlock()
-	fake.ReadWriterRateLimitsStub = nil
-	if fake.readWriterRateLimitsReturnsOnCall == nil {
-		fake.readWriterRateLimitsReturnsOnCall = make(map[int]struct {
-			result1 limits.WriterRateLimits
-			result2 error
+func (fake *FakeExecutiveInterface) SaveTableTemplateReturns(result1 error) {
+	fake.saveTableTemplateMutex.Lock()
+	defer fake.saveTableTemplateMutex.Unlock()
+	fake.SaveTableTemplateStub = nil
+	fake.saveTableTemplateReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) SaveTableTemplateReturnsOnCall(i int, result1 error) {
+	fake.saveTableTemplateMutex.Lock()
+	defer fake.saveTableTemplateMutex.Unlock()
+	fake.SaveTableTemplateStub = nil
+	if fake.saveTableTemplateReturnsOnCall == nil {
+		fake.saveTableTemplateReturnsOnCall = make(map[int]struct {
+			result1 error
 		})
 	}
-	fake.readWriterRateLimitsReturnsOnCall[i] = struct {
-		result1 limits.WriterRateLimits
-		result2 error
-	}{result1, result2}
+	fake.saveTableTemplateReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
 }
 
-func (fake *FakeExecutiveInterface) RegisterWriter(arg1 string, arg2 string) error {
-	fake.registerWriterMutex.Lock()
-	ret, specificReturn := fake.registerWriterReturnsOnCall[len(fake.registerWriterArgsForCall)]
-	fake.registerWriterArgsForCall = append(fake.registerWriterArgsForCall, struct {
-		arg1 string
-		arg2 string
-	}{arg1, arg2})
-	stub := fake.RegisterWriterStub
-	fakeReturns := fake.registerWriterReturns
-	fake.recordInvocation("RegisterWriter", []interface{}{arg1, arg2})
-	fake.registerWriterMutex.Unlock()
+func (fake *FakeExecutiveInterface) SetMaintenance(arg1 executive.Maintenance) error {
+	fake.setMaintenanceMutex.Lock()
+	ret, specificReturn := fake.setMaintenanceReturnsOnCall[len(fake.setMaintenanceArgsForCall)]
+	fake.setMaintenanceArgsForCall = append(fake.setMaintenanceArgsForCall, struct {
+		arg1 executive.Maintenance
+	}{arg1})
+	stub := fake.SetMaintenanceStub
+	fakeReturns := fake.setMaintenanceReturns
+	fake.recordInvocation("SetMaintenance", []interface{}{arg1})
+	fake.setMaintenanceMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1
@@ -1262,44 +4447,107 @@ func (fake *FakeExecutiveInterface) RegisterWriter(arg1 string, arg2 string) err
 	return fakeReturns.result1
 }
 
-func (fake *FakeExecutiveInterface) RegisterWriterCallCount() int {
-	fake.registerWriterMutex.RLock()
-	defer fake.registerWriterMutex.RUnlock()
-	return len(fake.registerWriterArgsForCall)
+func (fake *FakeExecutiveInterface) SetMaintenanceCallCount() int {
+	fake.setMaintenanceMutex.RLock()
+	defer fake.setMaintenanceMutex.RUnlock()
+	return len(fake.setMaintenanceArgsForCall)
 }
 
-func (fake *FakeExecutiveInterface) RegisterWriterCalls(stub func(string, string) error) {
-	fake.registerWriterMutex.Lock()
-	defer fake.registerWriterMutex.Unlock()
-	fake.RegisterWriterStub = stub
+func (fake *FakeExecutiveInterface) SetMaintenanceCalls(stub func(executive.Maintenance) error) {
+	fake.setMaintenanceMutex.Lock()
+	defer fake.setMaintenanceMutex.Unlock()
+	fake.SetMaintenanceStub = stub
 }
 
-func (fake *FakeExecutiveInterface) RegisterWriterArgsForCall(i int) (string, string) {
-	fake.registerWriterMutex.RLock()
-	defer fake.registerWriterMutex.RUnlock()
-	argsForCall := fake.registerWriterArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+func (fake *FakeExecutiveInterface) SetMaintenanceArgsForCall(i int) executive.Maintenance {
+	fake.setMaintenanceMutex.RLock()
+	defer fake.setMaintenanceMutex.RUnlock()
+	argsForCall := fake.setMaintenanceArgsForCall[i]
+	return argsForCall.arg1
 }
 
-func (fake *FakeExecutiveInterface) RegisterWriterReturns(result1 error) {
-	fake.registerWriterMutex.Lock()
-	defer fake.registerWriterMutex.Unlock()
-	fake.RegisterWriterStub = nil
-	fake.registerWriterReturns = struct {
+func (fake *FakeExecutiveInterface) SetMaintenanceReturns(result1 error) {
+	fake.setMaintenanceMutex.Lock()
+	defer fake.setMaintenanceMutex.Unlock()
+	fake.SetMaintenanceStub = nil
+	fake.setMaintenanceReturns = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *FakeExecutiveInterface) RegisterWriterReturnsOnCall(i int, result1 error) {
-	fake.registerWriterMutex.Lock()
-	defer fake.registerWriterMutex.Unlock()
-	fake.RegisterWriterStub = nil
-	if fake.registerWriterReturnsOnCall == nil {
-		fake.registerWriterReturnsOnCall = make(map[int]struct {
+func (fake *FakeExecutiveInterface) SetMaintenanceReturnsOnCall(i int, result1 error) {
+	fake.setMaintenanceMutex.Lock()
+	defer fake.setMaintenanceMutex.Unlock()
+	fake.SetMaintenanceStub = nil
+	if fake.setMaintenanceReturnsOnCall == nil {
+		fake.setMaintenanceReturnsOnCall = make(map[int]struct {
 			result1 error
 		})
 	}
-	fake.registerWriterReturnsOnCall[i] = struct {
+	fake.setMaintenanceReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) SetTableReferences(arg1 schema.FamilyTable, arg2 map[string]schema.FieldReference, arg3 bool) error {
+	fake.setTableReferencesMutex.Lock()
+	ret, specificReturn := fake.setTableReferencesReturnsOnCall[len(fake.setTableReferencesArgsForCall)]
+	fake.setTableReferencesArgsForCall = append(fake.setTableReferencesArgsForCall, struct {
+		arg1 schema.FamilyTable
+		arg2 map[string]schema.FieldReference
+		arg3 bool
+	}{arg1, arg2, arg3})
+	stub := fake.SetTableReferencesStub
+	fakeReturns := fake.setTableReferencesReturns
+	fake.recordInvocation("SetTableReferences", []interface{}{arg1, arg2, arg3})
+	fake.setTableReferencesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeExecutiveInterface) SetTableReferencesCallCount() int {
+	fake.setTableReferencesMutex.RLock()
+	defer fake.setTableReferencesMutex.RUnlock()
+	return len(fake.setTableReferencesArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) SetTableReferencesCalls(stub func(schema.FamilyTable, map[string]schema.FieldReference, bool) error) {
+	fake.setTableReferencesMutex.Lock()
+	defer fake.setTableReferencesMutex.Unlock()
+	fake.SetTableReferencesStub = stub
+}
+
+func (fake *FakeExecutiveInterface) SetTableReferencesArgsForCall(i int) (schema.FamilyTable, map[string]schema.FieldReference, bool) {
+	fake.setTableReferencesMutex.RLock()
+	defer fake.setTableReferencesMutex.RUnlock()
+	argsForCall := fake.setTableReferencesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeExecutiveInterface) SetTableReferencesReturns(result1 error) {
+	fake.setTableReferencesMutex.Lock()
+	defer fake.setTableReferencesMutex.Unlock()
+	fake.SetTableReferencesStub = nil
+	fake.setTableReferencesReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) SetTableReferencesReturnsOnCall(i int, result1 error) {
+	fake.setTableReferencesMutex.Lock()
+	defer fake.setTableReferencesMutex.Unlock()
+	fake.SetTableReferencesStub = nil
+	if fake.setTableReferencesReturnsOnCall == nil {
+		fake.setTableReferencesReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setTableReferencesReturnsOnCall[i] = struct {
 		result1 error
 	}{result1}
 }
@@ -1372,6 +4620,72 @@ func (fake *FakeExecutiveInterface) SetWriterCookieReturnsOnCall(i int, result1
 	}{result1}
 }
 
+func (fake *FakeExecutiveInterface) StartExport(arg1 string, arg2 string, arg3 executive.ExportRequest) (*executive.ExportJob, error) {
+	fake.startExportMutex.Lock()
+	ret, specificReturn := fake.startExportReturnsOnCall[len(fake.startExportArgsForCall)]
+	fake.startExportArgsForCall = append(fake.startExportArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 executive.ExportRequest
+	}{arg1, arg2, arg3})
+	stub := fake.StartExportStub
+	fakeReturns := fake.startExportReturns
+	fake.recordInvocation("StartExport", []interface{}{arg1, arg2, arg3})
+	fake.startExportMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeExecutiveInterface) StartExportCallCount() int {
+	fake.startExportMutex.RLock()
+	defer fake.startExportMutex.RUnlock()
+	return len(fake.startExportArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) StartExportCalls(stub func(string, string, executive.ExportRequest) (*executive.ExportJob, error)) {
+	fake.startExportMutex.Lock()
+	defer fake.startExportMutex.Unlock()
+	fake.StartExportStub = stub
+}
+
+func (fake *FakeExecutiveInterface) StartExportArgsForCall(i int) (string, string, executive.ExportRequest) {
+	fake.startExportMutex.RLock()
+	defer fake.startExportMutex.RUnlock()
+	argsForCall := fake.startExportArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeExecutiveInterface) StartExportReturns(result1 *executive.ExportJob, result2 error) {
+	fake.startExportMutex.Lock()
+	defer fake.startExportMutex.Unlock()
+	fake.StartExportStub = nil
+	fake.startExportReturns = struct {
+		result1 *executive.ExportJob
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExecutiveInterface) StartExportReturnsOnCall(i int, result1 *executive.ExportJob, result2 error) {
+	fake.startExportMutex.Lock()
+	defer fake.startExportMutex.Unlock()
+	fake.StartExportStub = nil
+	if fake.startExportReturnsOnCall == nil {
+		fake.startExportReturnsOnCall = make(map[int]struct {
+			result1 *executive.ExportJob
+			result2 error
+		})
+	}
+	fake.startExportReturnsOnCall[i] = struct {
+		result1 *executive.ExportJob
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeExecutiveInterface) TableSchema(arg1 string, arg2 string) (*schema.Table, error) {
 	fake.tableSchemaMutex.Lock()
 	ret, specificReturn := fake.tableSchemaReturnsOnCall[len(fake.tableSchemaArgsForCall)]
@@ -1498,6 +4812,68 @@ func (fake *FakeExecutiveInterface) UpdateTableSizeLimitReturnsOnCall(i int, res
 	}{result1}
 }
 
+func (fake *FakeExecutiveInterface) UpdateWriterGroup(arg1 string, arg2 limits.RateLimit) error {
+	fake.updateWriterGroupMutex.Lock()
+	ret, specificReturn := fake.updateWriterGroupReturnsOnCall[len(fake.updateWriterGroupArgsForCall)]
+	fake.updateWriterGroupArgsForCall = append(fake.updateWriterGroupArgsForCall, struct {
+		arg1 string
+		arg2 limits.RateLimit
+	}{arg1, arg2})
+	stub := fake.UpdateWriterGroupStub
+	fakeReturns := fake.updateWriterGroupReturns
+	fake.recordInvocation("UpdateWriterGroup", []interface{}{arg1, arg2})
+	fake.updateWriterGroupMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeExecutiveInterface) UpdateWriterGroupCallCount() int {
+	fake.updateWriterGroupMutex.RLock()
+	defer fake.updateWriterGroupMutex.RUnlock()
+	return len(fake.updateWriterGroupArgsForCall)
+}
+
+func (fake *FakeExecutiveInterface) UpdateWriterGroupCalls(stub func(string, limits.RateLimit) error) {
+	fake.updateWriterGroupMutex.Lock()
+	defer fake.updateWriterGroupMutex.Unlock()
+	fake.UpdateWriterGroupStub = stub
+}
+
+func (fake *FakeExecutiveInterface) UpdateWriterGroupArgsForCall(i int) (string, limits.RateLimit) {
+	fake.updateWriterGroupMutex.RLock()
+	defer fake.updateWriterGroupMutex.RUnlock()
+	argsForCall := fake.updateWriterGroupArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeExecutiveInterface) UpdateWriterGroupReturns(result1 error) {
+	fake.updateWriterGroupMutex.Lock()
+	defer fake.updateWriterGroupMutex.Unlock()
+	fake.UpdateWriterGroupStub = nil
+	fake.updateWriterGroupReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExecutiveInterface) UpdateWriterGroupReturnsOnCall(i int, result1 error) {
+	fake.updateWriterGroupMutex.Lock()
+	defer fake.updateWriterGroupMutex.Unlock()
+	fake.UpdateWriterGroupStub = nil
+	if fake.updateWriterGroupReturnsOnCall == nil {
+		fake.updateWriterGroupReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.updateWriterGroupReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeExecutiveInterface) UpdateWriterRateLimit(arg1 limits.WriterRateLimit) error {
 	fake.updateWriterRateLimitMutex.Lock()
 	ret, specificReturn := fake.updateWriterRateLimitReturnsOnCall[len(fake.updateWriterRateLimitArgsForCall)]
@@ -1564,42 +4940,126 @@ func (fake *FakeExecutiveInterface) Invocations() map[string][][]interface{} {
 	defer fake.invocationsMutex.RUnlock()
 	fake.addFieldsMutex.RLock()
 	defer fake.addFieldsMutex.RUnlock()
+	fake.addWriterGroupMemberMutex.RLock()
+	defer fake.addWriterGroupMemberMutex.RUnlock()
+	fake.alterFieldMutex.RLock()
+	defer fake.alterFieldMutex.RUnlock()
+	fake.analyzeTablesMutex.RLock()
+	defer fake.analyzeTablesMutex.RUnlock()
+	fake.applySchemaMutex.RLock()
+	defer fake.applySchemaMutex.RUnlock()
+	fake.approveWriterRegistrationMutex.RLock()
+	defer fake.approveWriterRegistrationMutex.RUnlock()
+	fake.checkAPITokenMutex.RLock()
+	defer fake.checkAPITokenMutex.RUnlock()
 	fake.clearTableMutex.RLock()
 	defer fake.clearTableMutex.RUnlock()
+	fake.cloneTableMutex.RLock()
+	defer fake.cloneTableMutex.RUnlock()
+	fake.compareAndSwapWriterCookieMutex.RLock()
+	defer fake.compareAndSwapWriterCookieMutex.RUnlock()
+	fake.createAPITokenMutex.RLock()
+	defer fake.createAPITokenMutex.RUnlock()
 	fake.createFamilyMutex.RLock()
 	defer fake.createFamilyMutex.RUnlock()
 	fake.createTableMutex.RLock()
 	defer fake.createTableMutex.RUnlock()
 	fake.createTablesMutex.RLock()
 	defer fake.createTablesMutex.RUnlock()
+	fake.deleteAPITokenMutex.RLock()
+	defer fake.deleteAPITokenMutex.RUnlock()
+	fake.deleteFamilyMutex.RLock()
+	defer fake.deleteFamilyMutex.RUnlock()
 	fake.deleteTableSizeLimitMutex.RLock()
 	defer fake.deleteTableSizeLimitMutex.RUnlock()
+	fake.deleteTableTemplateMutex.RLock()
+	defer fake.deleteTableTemplateMutex.RUnlock()
+	fake.deleteWebhookMutex.RLock()
+	defer fake.deleteWebhookMutex.RUnlock()
+	fake.deleteWriterGroupMutex.RLock()
+	defer fake.deleteWriterGroupMutex.RUnlock()
 	fake.deleteWriterRateLimitMutex.RLock()
 	defer fake.deleteWriterRateLimitMutex.RUnlock()
 	fake.dropTableMutex.RLock()
 	defer fake.dropTableMutex.RUnlock()
+	fake.enableWriterMutex.RLock()
+	defer fake.enableWriterMutex.RUnlock()
 	fake.familySchemasMutex.RLock()
 	defer fake.familySchemasMutex.RUnlock()
+	fake.familyTablesMutex.RLock()
+	defer fake.familyTablesMutex.RUnlock()
 	fake.getWriterCookieMutex.RLock()
 	defer fake.getWriterCookieMutex.RUnlock()
 	fake.mutateMutex.RLock()
 	defer fake.mutateMutex.RUnlock()
+	fake.mutateFamiliesMutex.RLock()
+	defer fake.mutateFamiliesMutex.RUnlock()
+	fake.readAPITokensMutex.RLock()
+	defer fake.readAPITokensMutex.RUnlock()
+	fake.readExportJobMutex.RLock()
+	defer fake.readExportJobMutex.RUnlock()
+	fake.readFamilyDefaultsMutex.RLock()
+	defer fake.readFamilyDefaultsMutex.RUnlock()
 	fake.readFamilyTableNamesMutex.RLock()
 	defer fake.readFamilyTableNamesMutex.RUnlock()
+	fake.readLedgerMutex.RLock()
+	defer fake.readLedgerMutex.RUnlock()
+	fake.readLedgerSeqMutex.RLock()
+	defer fake.readLedgerSeqMutex.RUnlock()
+	fake.readMaintenanceMutex.RLock()
+	defer fake.readMaintenanceMutex.RUnlock()
 	fake.readRowMutex.RLock()
 	defer fake.readRowMutex.RUnlock()
+	fake.readRowsMutex.RLock()
+	defer fake.readRowsMutex.RUnlock()
 	fake.readTableSizeLimitsMutex.RLock()
 	defer fake.readTableSizeLimitsMutex.RUnlock()
+	fake.readTableSizeProjectionMutex.RLock()
+	defer fake.readTableSizeProjectionMutex.RUnlock()
+	fake.readTableTemplatesMutex.RLock()
+	defer fake.readTableTemplatesMutex.RUnlock()
+	fake.readWebhooksMutex.RLock()
+	defer fake.readWebhooksMutex.RUnlock()
+	fake.readWriterActivityMutex.RLock()
+	defer fake.readWriterActivityMutex.RUnlock()
+	fake.readWriterGroupsMutex.RLock()
+	defer fake.readWriterGroupsMutex.RUnlock()
 	fake.readWriterRateLimitsMutex.RLock()
 	defer fake.readWriterRateLimitsMutex.RUnlock()
+	fake.readWriterRegistrationAuditMutex.RLock()
+	defer fake.readWriterRegistrationAuditMutex.RUnlock()
+	fake.readWriterRegistrationsMutex.RLock()
+	defer fake.readWriterRegistrationsMutex.RUnlock()
+	fake.readWritersMutex.RLock()
+	defer fake.readWritersMutex.RUnlock()
+	fake.registerWebhookMutex.RLock()
+	defer fake.registerWebhookMutex.RUnlock()
 	fake.registerWriterMutex.RLock()
 	defer fake.registerWriterMutex.RUnlock()
+	fake.rejectWriterRegistrationMutex.RLock()
+	defer fake.rejectWriterRegistrationMutex.RUnlock()
+	fake.removeWriterGroupMemberMutex.RLock()
+	defer fake.removeWriterGroupMemberMutex.RUnlock()
+	fake.requestWriterRegistrationMutex.RLock()
+	defer fake.requestWriterRegistrationMutex.RUnlock()
+	fake.saveFamilyDefaultsMutex.RLock()
+	defer fake.saveFamilyDefaultsMutex.RUnlock()
+	fake.saveTableTemplateMutex.RLock()
+	defer fake.saveTableTemplateMutex.RUnlock()
+	fake.setMaintenanceMutex.RLock()
+	defer fake.setMaintenanceMutex.RUnlock()
+	fake.setTableReferencesMutex.RLock()
+	defer fake.setTableReferencesMutex.RUnlock()
 	fake.setWriterCookieMutex.RLock()
 	defer fake.setWriterCookieMutex.RUnlock()
+	fake.startExportMutex.RLock()
+	defer fake.startExportMutex.RUnlock()
 	fake.tableSchemaMutex.RLock()
 	defer fake.tableSchemaMutex.RUnlock()
 	fake.updateTableSizeLimitMutex.RLock()
 	defer fake.updateTableSizeLimitMutex.RUnlock()
+	fake.updateWriterGroupMutex.RLock()
+	defer fake.updateWriterGroupMutex.RUnlock()
 	fake.updateWriterRateLimitMutex.RLock()
 	defer fake.updateWriterRateLimitMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
@@ -0,0 +1,156 @@
+package executive
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"regexp"
+
+	"github.com/pkg/errors"
+	"github.com/segmentio/events/v2"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+	"github.com/segmentio/ctlstore/pkg/limits"
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+// FamilyDefaults are applied to every table which is created in a family,
+// whether it's created directly, from a template, by cloning or by applying
+// a schema. Tables which already exist aren't changed.
+type FamilyDefaults struct {
+	Family string `json:"family"`
+	// TableSizeLimits become the size limits of each new table, in place of
+	// the global limits.
+	TableSizeLimits *limits.SizeLimits `json:"tableSizeLimits,omitempty"`
+	// KeyFieldPattern is a regular expression which the names of each new
+	// table's key fields must match, e.g. "_id$".
+	KeyFieldPattern string `json:"keyFieldPattern,omitempty"`
+	// MaxKeyFields bounds how many key fields new tables may have.
+	MaxKeyFields int `json:"maxKeyFields,omitempty"`
+}
+
+// Validate checks that the defaults could be applied.
+func (d FamilyDefaults) Validate() error {
+	if d.TableSizeLimits != nil {
+		if d.TableSizeLimits.MaxSize <= 0 || d.TableSizeLimits.WarnSize <= 0 {
+			return errs.BadRequest("Table size limits must be positive")
+		}
+		if d.TableSizeLimits.WarnSize > d.TableSizeLimits.MaxSize {
+			return errs.BadRequest("The warn size may not exceed the max size")
+		}
+	}
+	if _, err := regexp.Compile(d.KeyFieldPattern); err != nil {
+		return errs.BadRequest("Invalid key field pattern: %s", err)
+	}
+	if d.MaxKeyFields < 0 {
+		return errs.BadRequest("The max key fields may not be negative")
+	}
+	return nil
+}
+
+// checkKeyFields returns an error if the key fields of a new table don't
+// satisfy the defaults.
+func (d FamilyDefaults) checkKeyFields(keyFields []string) error {
+	if d.MaxKeyFields > 0 && len(keyFields) > d.MaxKeyFields {
+		return errs.BadRequest("Tables in family '%s' may have at most %d key fields", d.Family, d.MaxKeyFields)
+	}
+	if d.KeyFieldPattern == "" {
+		return nil
+	}
+	re, err := regexp.Compile(d.KeyFieldPattern)
+	if err != nil {
+		return errors.Wrap(err, "compile key field pattern")
+	}
+	for _, name := range keyFields {
+		if !re.MatchString(name) {
+			return errs.BadRequest("Key field '%s' must match '%s' in family '%s'", name, d.KeyFieldPattern, d.Family)
+		}
+	}
+	return nil
+}
+
+// SaveFamilyDefaults replaces the defaults of a family. Saving empty
+// defaults removes them.
+func (e *dbExecutive) SaveFamilyDefaults(defaults FamilyDefaults) error {
+	famName, err := schema.NewFamilyName(defaults.Family)
+	if err != nil {
+		return &errs.BadRequestError{Err: err.Error()}
+	}
+	defaults.Family = famName.Name
+	if err := defaults.Validate(); err != nil {
+		return err
+	}
+	_, ok, err := e.fetchFamilyByName(famName)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return &errs.NotFoundError{Err: "Family not found"}
+	}
+
+	ctx, cancel := e.ctx()
+	defer cancel()
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "start tx")
+	}
+	defer tx.Rollback()
+	_, err = tx.ExecContext(ctx, "DELETE FROM family_defaults WHERE family_name=?", famName.Name)
+	if err != nil {
+		return errors.Wrap(err, "delete family defaults")
+	}
+	if defaults != (FamilyDefaults{Family: famName.Name}) {
+		definition, err := json.Marshal(defaults)
+		if err != nil {
+			return errors.Wrap(err, "marshal family defaults")
+		}
+		_, err = tx.ExecContext(ctx, "INSERT INTO family_defaults (family_name, definition) VALUES (?, ?)",
+			famName.Name, string(definition))
+		if err != nil {
+			return errors.Wrap(err, "insert family defaults")
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "commit tx")
+	}
+	events.Log("Saved defaults of family %{family}s", famName.Name)
+	return nil
+}
+
+// ReadFamilyDefaults returns the defaults of a family, which are empty if
+// none have been saved.
+func (e *dbExecutive) ReadFamilyDefaults(familyName string) (FamilyDefaults, error) {
+	famName, err := schema.NewFamilyName(familyName)
+	if err != nil {
+		return FamilyDefaults{}, &errs.BadRequestError{Err: err.Error()}
+	}
+	_, ok, err := e.fetchFamilyByName(famName)
+	if err != nil {
+		return FamilyDefaults{}, err
+	}
+	if !ok {
+		return FamilyDefaults{}, &errs.NotFoundError{Err: "Family not found"}
+	}
+	ctx, cancel := e.ctx()
+	defer cancel()
+	return readFamilyDefaults(ctx, e.readDB(), famName.Name)
+}
+
+// readFamilyDefaults reads the defaults of a family from the db, which may
+// be a transaction.
+func readFamilyDefaults(ctx context.Context, db interface {
+	QueryRowContext(context.Context, string, ...interface{}) *sql.Row
+}, familyName string) (FamilyDefaults, error) {
+	defaults := FamilyDefaults{Family: familyName}
+	var definition string
+	err := db.QueryRowContext(ctx, "SELECT definition FROM family_defaults WHERE family_name=?",
+		familyName).Scan(&definition)
+	switch {
+	case err == sql.ErrNoRows:
+		return defaults, nil
+	case err != nil:
+		return defaults, errors.Wrap(err, "select family defaults")
+	}
+	err = json.Unmarshal([]byte(definition), &defaults)
+	return defaults, errors.Wrap(err, "unmarshal family defaults")
+}
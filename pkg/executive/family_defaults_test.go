@@ -0,0 +1,74 @@
+package executive
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+	"github.com/segmentio/ctlstore/pkg/limits"
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+// testDBExecutiveFamilyDefaults is run from TestAllDBExecutive
+func testDBExecutiveFamilyDefaults(t *testing.T, dbType string) {
+	u := newDbExecTestUtil(t, dbType)
+	defer u.Close()
+
+	defaults, err := u.e.ReadFamilyDefaults("family1")
+	require.NoError(t, err)
+	require.Equal(t, FamilyDefaults{Family: "family1"}, defaults)
+	_, err = u.e.ReadFamilyDefaults("family9")
+	require.IsType(t, &errs.NotFoundError{}, errors.Cause(err))
+
+	for _, invalid := range []FamilyDefaults{
+		{Family: "family1", TableSizeLimits: &limits.SizeLimits{MaxSize: 100, WarnSize: 200}},
+		{Family: "family1", TableSizeLimits: &limits.SizeLimits{}},
+		{Family: "family1", KeyFieldPattern: "("},
+		{Family: "family1", MaxKeyFields: -1},
+	} {
+		err = u.e.SaveFamilyDefaults(invalid)
+		require.IsType(t, &errs.BadRequestError{}, errors.Cause(err), "%+v", invalid)
+	}
+	err = u.e.SaveFamilyDefaults(FamilyDefaults{Family: "family9", MaxKeyFields: 1})
+	require.IsType(t, &errs.NotFoundError{}, errors.Cause(err))
+
+	defaults = FamilyDefaults{
+		Family:          "family1",
+		TableSizeLimits: &limits.SizeLimits{MaxSize: 1000, WarnSize: 800},
+		KeyFieldPattern: "_id$",
+		MaxKeyFields:    2,
+	}
+	require.NoError(t, u.e.SaveFamilyDefaults(defaults))
+	read, err := u.e.ReadFamilyDefaults("family1")
+	require.NoError(t, err)
+	require.Equal(t, defaults, read)
+
+	err = u.e.CreateTable("family1", "badkey", []string{"name"}, []schema.FieldType{schema.FTString}, []string{"name"})
+	require.IsType(t, &errs.BadRequestError{}, errors.Cause(err))
+	err = u.e.CreateTable("family1", "toomanykeys",
+		[]string{"a_id", "b_id", "c_id"},
+		[]schema.FieldType{schema.FTString, schema.FTString, schema.FTString},
+		[]string{"a_id", "b_id", "c_id"})
+	require.IsType(t, &errs.BadRequestError{}, errors.Cause(err))
+
+	err = u.e.CreateTable("family1", "users", []string{"user_id", "name"},
+		[]schema.FieldType{schema.FTString, schema.FTString}, []string{"user_id"})
+	require.NoError(t, err)
+	tableLimits, err := u.e.ReadTableSizeLimits()
+	require.NoError(t, err)
+	require.Contains(t, tableLimits.Tables, limits.TableSizeLimit{
+		SizeLimits: limits.SizeLimits{MaxSize: 1000, WarnSize: 800},
+		Family:     "family1",
+		Table:      "users",
+	})
+
+	// saving empty defaults removes them
+	require.NoError(t, u.e.SaveFamilyDefaults(FamilyDefaults{Family: "family1"}))
+	read, err = u.e.ReadFamilyDefaults("family1")
+	require.NoError(t, err)
+	require.Equal(t, FamilyDefaults{Family: "family1"}, read)
+	err = u.e.CreateTable("family1", "badkey", []string{"name"}, []schema.FieldType{schema.FTString}, []string{"name"})
+	require.NoError(t, err)
+}
@@ -0,0 +1,133 @@
+package executive
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/segmentio/events/v2"
+)
+
+const (
+	// LedgerSeqHeader is set on the executive's responses to the sequence
+	// of the last ledger entry, so that writers and tooling can correlate
+	// their operations with the progress of the reflectors.
+	LedgerSeqHeader = "X-Ctlstore-Ledger-Seq"
+
+	// ledgerSeqCacheTTL is how long the max ledger seq is cached for
+	// before it's read from the ctldb again
+	ledgerSeqCacheTTL = time.Second
+)
+
+// ledgerSeqCache caches the max ledger seq, so that it isn't read from the
+// ctldb for every request. It's shared by the requests to the executive
+// service, and a nil cache reads the ctldb every time.
+type ledgerSeqCache struct {
+	ttl    time.Duration
+	read   func(ctx context.Context, db *sql.DB) (int64, error)
+	mu     sync.Mutex
+	seq    int64
+	readAt time.Time
+	// refreshing is set while a request reads the seq from the ctldb, during
+	// which the others are served the last known seq
+	refreshing bool
+}
+
+func newLedgerSeqCache(ttl time.Duration) *ledgerSeqCache {
+	return &ledgerSeqCache{ttl: ttl, read: readMaxLedgerSeq}
+}
+
+// get returns the cached seq, reading it from the db if it's expired. The
+// lock isn't held while reading, so that a slow ctldb doesn't serialize
+// every response, health checks included.
+func (c *ledgerSeqCache) get(ctx context.Context, db *sql.DB) (int64, error) {
+	if c == nil {
+		return readMaxLedgerSeq(ctx, db)
+	}
+	c.mu.Lock()
+	if time.Since(c.readAt) < c.ttl || (c.refreshing && !c.readAt.IsZero()) {
+		seq := c.seq
+		c.mu.Unlock()
+		return seq, nil
+	}
+	c.refreshing = true
+	c.mu.Unlock()
+
+	seq, err := c.read(ctx, db)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.refreshing = false
+	if err != nil {
+		return 0, err
+	}
+	if seq > c.seq {
+		c.seq = seq
+	}
+	c.readAt = time.Now()
+	return c.seq, nil
+}
+
+// advance records the seq of a ledger entry which has just been committed,
+// so that the responses to the request which wrote it don't report an
+// earlier seq.
+func (c *ledgerSeqCache) advance(seq int64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if seq > c.seq {
+		c.seq = seq
+	}
+}
+
+func readMaxLedgerSeq(ctx context.Context, db *sql.DB) (int64, error) {
+	var seq sql.NullInt64
+	err := db.QueryRowContext(ctx, "SELECT MAX(seq) FROM "+dmlLedgerTableName).Scan(&seq)
+	return seq.Int64, errors.Wrap(err, "select max ledger seq")
+}
+
+// reportLedgerSeq sets the ledger seq header on every response.
+func (ee *ExecutiveEndpoint) reportLedgerSeq(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lw := &ledgerSeqWriter{ResponseWriter: w, exec: ee.Exec}
+		next.ServeHTTP(lw, r)
+		if !lw.wroteHeader {
+			// handlers which respond without a body never write the header
+			lw.WriteHeader(http.StatusOK)
+		}
+	})
+}
+
+// ledgerSeqWriter sets the ledger seq header just before the response's
+// header is written, so that it's read after the request was handled.
+type ledgerSeqWriter struct {
+	http.ResponseWriter
+	exec        ExecutiveInterface
+	wroteHeader bool
+}
+
+func (w *ledgerSeqWriter) WriteHeader(statusCode int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		seq, err := w.exec.ReadLedgerSeq()
+		if err == nil {
+			w.Header().Set(LedgerSeqHeader, strconv.FormatInt(seq, 10))
+		} else {
+			events.Log("could not read ledger seq: %{error}s", err)
+		}
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *ledgerSeqWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
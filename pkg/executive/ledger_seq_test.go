@@ -0,0 +1,59 @@
+package executive
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLedgerSeqCacheServesLastKnownSeqWhileReading(t *testing.T) {
+	c := newLedgerSeqCache(time.Millisecond)
+	reads := make(chan int64)
+	c.read = func(ctx context.Context, db *sql.DB) (int64, error) {
+		seq, ok := <-reads
+		if !ok {
+			return 0, errors.New("ctldb unavailable")
+		}
+		return seq, nil
+	}
+	get := func() (int64, error) {
+		return c.get(context.Background(), nil)
+	}
+
+	go func() { reads <- 10 }()
+	seq, err := get()
+	require.NoError(t, err)
+	require.EqualValues(t, 10, seq)
+
+	// once it's expired, one request reads the seq while the others are
+	// served the last known one
+	time.Sleep(2 * time.Millisecond)
+	done := make(chan int64)
+	go func() {
+		seq, _ := get()
+		done <- seq
+	}()
+	require.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.refreshing
+	}, time.Second, time.Millisecond)
+	seq, err = get()
+	require.NoError(t, err)
+	require.EqualValues(t, 10, seq)
+	reads <- 12
+	require.EqualValues(t, 12, <-done)
+
+	// a failed read is retried by the next request
+	time.Sleep(2 * time.Millisecond)
+	close(reads)
+	_, err = get()
+	require.Error(t, err)
+	c.mu.Lock()
+	require.False(t, c.refreshing)
+	c.mu.Unlock()
+}
@@ -0,0 +1,120 @@
+package executive
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/segmentio/events/v2"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+)
+
+const (
+	// the id of the row in the maintenance table that holds the switch
+	maintenanceID = "executive"
+
+	// how long writers are told to wait before retrying a mutation that
+	// was rejected for maintenance
+	maintenanceRetryAfter = 30 * time.Second
+)
+
+// Maintenance is the maintenance mode switch. While it's enabled, mutations
+// are rejected so that the ctldb can be safely upgraded or cut over, but
+// schemas and rows can still be read.
+type Maintenance struct {
+	Enabled bool   `json:"enabled"`
+	Reason  string `json:"reason,omitempty"`
+	// UpdatedAt is nil if maintenance mode has never been switched
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+// SetMaintenance switches maintenance mode on or off. It takes the ledger
+// lock, so once it has switched maintenance mode on, no mutation that was
+// in flight can still be written to the ledger.
+func (e *dbExecutive) SetMaintenance(m Maintenance) error {
+	ctx, cancel := e.ctx()
+	defer cancel()
+
+	if len(m.Reason) > 1024 {
+		return errs.BadRequest("Reason is too long")
+	}
+
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin tx error")
+	}
+	defer tx.Rollback()
+
+	if err := e.takeLedgerLock(ctx, tx); err != nil {
+		return errors.Wrap(err, "taking ledger lock")
+	}
+
+	enabled := 0
+	if m.Enabled {
+		enabled = 1
+	}
+	_, err = tx.ExecContext(ctx, "replace into maintenance "+
+		"(id, enabled, reason, updated_at) "+
+		"values (?, ?, ?, ?)", maintenanceID, enabled, m.Reason, time.Now().Unix())
+	if err != nil {
+		return errors.Wrap(err, "replace into maintenance")
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "commit tx")
+	}
+
+	if m.Enabled {
+		events.Log("Maintenance mode enabled: %{reason}s", m.Reason)
+	} else {
+		events.Log("Maintenance mode disabled")
+	}
+	return nil
+}
+
+func (e *dbExecutive) ReadMaintenance() (Maintenance, error) {
+	ctx, cancel := e.ctx()
+	defer cancel()
+	return readMaintenance(ctx, e.DB)
+}
+
+// checkMaintenance returns an error if maintenance mode is enabled. Mutate
+// calls it after taking the ledger lock, so that it can't race with
+// SetMaintenance.
+func checkMaintenance(ctx context.Context, tx *sql.Tx) error {
+	m, err := readMaintenance(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if !m.Enabled {
+		return nil
+	}
+	msg := "Executive is in maintenance mode"
+	if m.Reason != "" {
+		msg = fmt.Sprintf("%s: %s", msg, m.Reason)
+	}
+	return &errs.ServiceUnavailableErr{Err: msg, RetryAfter: maintenanceRetryAfter}
+}
+
+func readMaintenance(ctx context.Context, db SQLDBClient) (Maintenance, error) {
+	var m Maintenance
+	var enabled int
+	var updatedAt int64
+	err := db.QueryRowContext(ctx,
+		"SELECT enabled, reason, updated_at FROM maintenance WHERE id = ?", maintenanceID).
+		Scan(&enabled, &m.Reason, &updatedAt)
+	switch {
+	case err == sql.ErrNoRows:
+		return Maintenance{}, nil
+	case err != nil:
+		return Maintenance{}, errors.Wrap(err, "select maintenance")
+	}
+	m.Enabled = enabled == 1
+	if updatedAt != 0 {
+		t := time.Unix(updatedAt, 0)
+		m.UpdatedAt = &t
+	}
+	return m, nil
+}
@@ -2,14 +2,13 @@ package executive
 
 import (
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
 	"database/sql"
 	"encoding/base64"
-	"encoding/hex"
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
+	"github.com/segmentio/ctlstore/pkg/ctlcrypto"
 	"github.com/segmentio/ctlstore/pkg/limits"
 	"github.com/segmentio/ctlstore/pkg/schema"
 	"github.com/segmentio/ctlstore/pkg/sqlgen"
@@ -18,6 +17,7 @@ import (
 var (
 	ErrCookieConflict      = errors.New("Cookie conflict")
 	ErrWriterNotFound      = errors.New("Writer not found")
+	ErrWriterDisabled      = errors.New("Writer is disabled")
 	ErrWriterAlreadyExists = errors.New("Writer already exists with different credentials")
 	ErrCookieTooLong       = fmt.Errorf("Maximum cookie length is %d bytes", limits.LimitWriterCookieSize)
 )
@@ -30,10 +30,16 @@ type mutatorStore struct {
 	TableName string
 }
 
+// mutatorActivity is recorded against a writer when its cookie is updated
+// as part of a mutation, so that abandoned writers can be identified.
+type mutatorActivity struct {
+	At        time.Time
+	SourceIP  string
+	Mutations int
+}
+
 func hashMutatorSecret(secret string) string {
-	hasher := sha256.New()
-	hasher.Write([]byte(secret))
-	return hex.EncodeToString(hasher.Sum(nil))
+	return ctlcrypto.Default().HashSecret(secret)
 }
 
 // Register associates the supplied secret and writer name in the mutators table.
@@ -41,7 +47,31 @@ func hashMutatorSecret(secret string) string {
 // If the writer already exists but the secret is different, an error will be returned
 // to signal this.
 func (ms *mutatorStore) Register(writerName schema.WriterName, writerSecret string) error {
-	secret := hashMutatorSecret(writerSecret)
+	return ms.registerHashed(writerName, hashMutatorSecret(writerSecret))
+}
+
+// registerHashed is Register, given the hash of the writer's secret.
+func (ms *mutatorStore) registerHashed(writerName schema.WriterName, secret string) error {
+	registered, err := ms.registeredWith(writerName, secret)
+	if err != nil {
+		return err
+	}
+	if registered {
+		// writer already exists with this secret
+		return nil
+	}
+	qs := sqlgen.SqlSprintf("INSERT INTO $1 (writer, secret, cookie, created_at) VALUES(?, ?, ?, ?)", ms.TableName)
+	token := []byte(tokenForWriter(writerName))
+	_, err = ms.DB.ExecContext(ms.Ctx, qs, writerName.Name, secret, token, time.Now().Unix())
+	if err != nil && errorIsRowConflict(err) {
+		return ErrWriterAlreadyExists
+	}
+	return err
+}
+
+// registeredWith returns whether the writer is registered with the hash of
+// a secret.
+func (ms *mutatorStore) registeredWith(writerName schema.WriterName, secret string) (bool, error) {
 	row := ms.DB.QueryRowContext(ms.Ctx,
 		sqlgen.SqlSprintf("SELECT count(*) FROM $1 where writer=? and secret=?", ms.TableName),
 		writerName.Name, secret)
@@ -50,19 +80,11 @@ func (ms *mutatorStore) Register(writerName schema.WriterName, writerSecret stri
 	switch {
 	case err == sql.ErrNoRows:
 		// this is OK, it just means that it wasn't found
+		return false, nil
 	case err != nil:
-		return errors.Wrap(err, "select from mutators")
-	case count == 1:
-		// writer already exists with this secret
-		return nil
+		return false, errors.Wrap(err, "select from mutators")
 	}
-	qs := sqlgen.SqlSprintf("INSERT INTO $1 (writer, secret, cookie) VALUES(?, ?, ?)", ms.TableName)
-	token := []byte(tokenForWriter(writerName))
-	_, err = ms.DB.ExecContext(ms.Ctx, qs, writerName.Name, secret, token)
-	if err != nil && errorIsRowConflict(err) {
-		return ErrWriterAlreadyExists
-	}
-	return err
+	return count == 1, nil
 }
 
 func (ms *mutatorStore) Exists(writerName schema.WriterName) (bool, error) {
@@ -75,26 +97,36 @@ func (ms *mutatorStore) Exists(writerName schema.WriterName) (bool, error) {
 	return count > 0, nil
 }
 
+// Get returns the writer's cookie, or ErrWriterDisabled if the writer has
+// been disabled.
 func (ms *mutatorStore) Get(writerName schema.WriterName, writerSecret string) ([]byte, bool, error) {
-	qs := sqlgen.SqlSprintf("SELECT cookie FROM $1 WHERE writer = ? AND secret = ? LIMIT 1", ms.TableName)
+	qs := sqlgen.SqlSprintf("SELECT cookie, disabled_at FROM $1 WHERE writer = ? AND secret = ? LIMIT 1", ms.TableName)
 	secret := hashMutatorSecret(writerSecret)
 	row := ms.DB.QueryRowContext(ms.Ctx, qs, writerName.Name, secret)
 	cookieBytes := []byte{}
-	err := row.Scan(&cookieBytes)
+	var disabledAt int64
+	err := row.Scan(&cookieBytes, &disabledAt)
 	if err == sql.ErrNoRows {
 		return nil, false, nil
 	}
 	if err != nil || cookieBytes == nil {
 		return nil, false, err
 	}
+	if disabledAt > 0 {
+		return nil, false, ErrWriterDisabled
+	}
 	return cookieBytes, true, nil
 }
 
+// Update sets the writer's cookie, optionally only if the current cookie
+// matches ifCookie. If activity is not nil, it is recorded as the writer's
+// latest mutation.
 func (ms *mutatorStore) Update(
 	writerName schema.WriterName,
 	writerSecret string,
 	cookie []byte,
-	ifCookie []byte) error {
+	ifCookie []byte,
+	activity *mutatorActivity) error {
 
 	if len(cookie) > limits.LimitWriterCookieSize || len(ifCookie) > limits.LimitWriterCookieSize {
 		return ErrCookieTooLong
@@ -103,9 +135,15 @@ func (ms *mutatorStore) Update(
 	// The clock field here is useful because it gives us a way to count
 	// calls which do not alter the cookie to be counted below as an
 	// affected row.
-	qs := sqlgen.SqlSprintf("UPDATE $1 SET cookie=?, clock=clock+1 WHERE writer=? AND secret=?", ms.TableName)
+	qs := sqlgen.SqlSprintf("UPDATE $1 SET cookie=?, clock=clock+1", ms.TableName)
+	args := []interface{}{cookie}
+	if activity != nil {
+		qs += ", last_mutation_at=?, last_source_ip=?, mutation_count=mutation_count+?"
+		args = append(args, activity.At.Unix(), activity.SourceIP, activity.Mutations)
+	}
+	qs += " WHERE writer=? AND secret=? AND disabled_at=0"
 	secret := hashMutatorSecret(writerSecret)
-	args := []interface{}{cookie, writerName.Name, secret}
+	args = append(args, writerName.Name, secret)
 	if ifCookie != nil {
 		qs += " AND cookie=?"
 		args = append(args, ifCookie)
@@ -126,6 +164,7 @@ func (ms *mutatorStore) Update(
 		// Zero rows are affected happens in the case that:
 		//   1. ifCookie check failed
 		//   2. Writer doesn't exist
+		//   3. Writer is disabled
 		//
 		_, found, err := ms.Get(writerName, writerSecret)
 		if err != nil {
@@ -143,6 +182,113 @@ func (ms *mutatorStore) Update(
 	return nil
 }
 
+// List returns the registered writers, ordered by name.
+func (ms *mutatorStore) List() ([]WriterInfo, error) {
+	qs := sqlgen.SqlSprintf("SELECT writer, created_at, last_mutation_at, last_source_ip, mutation_count, LENGTH(cookie), disabled_at FROM $1 ORDER BY writer", ms.TableName)
+	rows, err := ms.DB.QueryContext(ms.Ctx, qs)
+	if err != nil {
+		return nil, errors.Wrap(err, "select from mutators")
+	}
+	defer rows.Close()
+	res := []WriterInfo{}
+	for rows.Next() {
+		var info WriterInfo
+		var createdAt, lastMutationAt, disabledAt int64
+		if err := rows.Scan(&info.Name, &createdAt, &lastMutationAt, &info.LastSourceIP, &info.MutationCount, &info.CookieLength, &disabledAt); err != nil {
+			return nil, errors.Wrap(err, "scan mutator")
+		}
+		info.CreatedAt = unixTimeOrNil(createdAt)
+		info.LastMutationAt = unixTimeOrNil(lastMutationAt)
+		info.DisabledAt = unixTimeOrNil(disabledAt)
+		res = append(res, info)
+	}
+	return res, errors.Wrap(rows.Err(), "iterate mutators")
+}
+
+// Activity returns the recent activity of a writer.
+func (ms *mutatorStore) Activity(writerName schema.WriterName) (WriterActivity, bool, error) {
+	qs := sqlgen.SqlSprintf("SELECT created_at, last_mutation_at, last_source_ip, mutation_count, disabled_at, enabled_at FROM $1 WHERE writer=?", ms.TableName)
+	var createdAt, lastMutationAt, disabledAt, enabledAt int64
+	activity := WriterActivity{Name: writerName.Name}
+	err := ms.DB.QueryRowContext(ms.Ctx, qs, writerName.Name).Scan(
+		&createdAt, &lastMutationAt, &activity.LastSourceIP, &activity.MutationCount, &disabledAt, &enabledAt)
+	switch {
+	case err == sql.ErrNoRows:
+		return activity, false, nil
+	case err != nil:
+		return activity, false, errors.Wrap(err, "select from mutators")
+	}
+	activity.CreatedAt = unixTimeOrNil(createdAt)
+	activity.LastMutationAt = unixTimeOrNil(lastMutationAt)
+	activity.DisabledAt = unixTimeOrNil(disabledAt)
+	activity.EnabledAt = unixTimeOrNil(enabledAt)
+	return activity, true, nil
+}
+
+// DisableIdle disables the writers which haven't mutated since the cutoff,
+// nor been registered or re-enabled since then, and returns their names.
+// Writers which predate the recording of any of these are never disabled,
+// since how long they have been idle is unknown.
+func (ms *mutatorStore) DisableIdle(cutoff time.Time, now time.Time) ([]string, error) {
+	const idle = "disabled_at=0 AND (created_at>0 OR last_mutation_at>0 OR enabled_at>0) " +
+		"AND created_at<? AND last_mutation_at<? AND enabled_at<?"
+	qs := sqlgen.SqlSprintf("SELECT writer FROM $1 WHERE "+idle+" ORDER BY writer", ms.TableName)
+	rows, err := ms.DB.QueryContext(ms.Ctx, qs, cutoff.Unix(), cutoff.Unix(), cutoff.Unix())
+	if err != nil {
+		return nil, errors.Wrap(err, "select idle mutators")
+	}
+	var candidates []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, errors.Wrap(err, "scan idle mutator")
+		}
+		candidates = append(candidates, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "iterate idle mutators")
+	}
+
+	var res []string
+	for _, name := range candidates {
+		// the writer may have mutated since it was selected
+		qs := sqlgen.SqlSprintf("UPDATE $1 SET disabled_at=? WHERE writer=? AND "+idle, ms.TableName)
+		sqlRes, err := ms.DB.ExecContext(ms.Ctx, qs, now.Unix(), name, cutoff.Unix(), cutoff.Unix(), cutoff.Unix())
+		if err != nil {
+			return res, errors.Wrap(err, "disable mutator")
+		}
+		if n, err := sqlRes.RowsAffected(); err != nil {
+			return res, errors.Wrap(err, "rows affected")
+		} else if n > 0 {
+			res = append(res, name)
+		}
+	}
+	return res, nil
+}
+
+// Enable re-enables a disabled writer, restarting its idle period.
+func (ms *mutatorStore) Enable(writerName schema.WriterName, now time.Time) (bool, error) {
+	exists, err := ms.Exists(writerName)
+	if err != nil || !exists {
+		return false, err
+	}
+	qs := sqlgen.SqlSprintf("UPDATE $1 SET disabled_at=0, enabled_at=? WHERE writer=?", ms.TableName)
+	if _, err := ms.DB.ExecContext(ms.Ctx, qs, now.Unix(), writerName.Name); err != nil {
+		return false, errors.Wrap(err, "enable mutator")
+	}
+	return true, nil
+}
+
+func unixTimeOrNil(sec int64) *time.Time {
+	if sec <= 0 {
+		return nil
+	}
+	t := time.Unix(sec, 0).UTC()
+	return &t
+}
+
 // This is used for signing tokens, but it's not security sensitive. It just
 // challenges the writer to make sure it is following the API conventions. The
 // reason to use these signed tokens instead of just adding a row to the DB is
@@ -152,7 +298,7 @@ const writerTokenKey = "143zGC4aYAXdKBs9ZhcxgVcfCCv"
 // Generates the token used the first time the writer initializes by signing it
 // with a "secret"
 func tokenForWriter(writerName schema.WriterName) string {
-	h := hmac.New(sha256.New, []byte(writerTokenKey))
+	h := ctlcrypto.Default().NewMAC([]byte(writerTokenKey))
 	sum := h.Sum([]byte(writerName.Name))
 	return base64.StdEncoding.EncodeToString(sum)
 }
@@ -139,7 +139,8 @@ func TestMutatorStoreUpdate(t *testing.T) {
 				schema.WriterName{Name: testCase.writerName},
 				"",
 				testCase.cookie,
-				testCase.ifCookie)
+				testCase.ifCookie,
+				nil)
 
 			if want, got := testCase.expectErr, err; want != got {
 				t.Errorf("Expected error %v, got %v", want, got)
@@ -0,0 +1,284 @@
+package executive
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/segmentio/events/v2"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+	"github.com/segmentio/ctlstore/pkg/schema"
+	"github.com/segmentio/ctlstore/pkg/sqlgen"
+)
+
+// tableReferences are the references of a table's fields to the key fields
+// of other tables, as stored in the ctldb.
+type tableReferences struct {
+	strict     bool
+	references map[schema.FieldName]schema.FieldReference
+}
+
+// SetTableReferences replaces the references of a table's fields, which
+// must each name a key field of an existing table of the same type. With
+// strict, upserts of the table are rejected unless the values of the
+// referencing fields match the key of an existing row, so strict requires
+// at least one reference.
+//
+// Only the metadata in the ctldb is changed, so nothing is written to the
+// ledger.
+func (e *dbExecutive) SetTableReferences(table schema.FamilyTable, references map[string]schema.FieldReference, strict bool) error {
+	ctx, cancel := e.ctx()
+	defer cancel()
+
+	famName, err := schema.NewFamilyName(table.Family)
+	if err != nil {
+		return &errs.BadRequestError{Err: err.Error()}
+	}
+	tblName, err := schema.NewTableName(table.Table)
+	if err != nil {
+		return &errs.BadRequestError{Err: err.Error()}
+	}
+	tbl, ok, err := e.fetchMetaTableByName(famName, tblName)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return &errs.NotFoundError{Err: "Table not found"}
+	}
+	if strict && len(references) == 0 {
+		return errs.BadRequest("Strict references require at least one reference")
+	}
+
+	refs, err := e.validateReferences(tbl, references)
+	if err != nil {
+		return err
+	}
+
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin tx")
+	}
+	defer tx.Rollback()
+	for _, qs := range []string{
+		"DELETE FROM field_references WHERE family_name = ? AND table_name = ?",
+		"DELETE FROM strict_reference_tables WHERE family_name = ? AND table_name = ?",
+	} {
+		if _, err := tx.ExecContext(ctx, qs, famName.Name, tblName.Name); err != nil {
+			return errors.Wrap(err, "delete references")
+		}
+	}
+	for fn, ref := range refs {
+		_, err = tx.ExecContext(ctx, "INSERT INTO field_references "+
+			"(family_name, table_name, field_name, ref_family_name, ref_table_name, ref_field_name) "+
+			"VALUES (?, ?, ?, ?, ?, ?)",
+			famName.Name, tblName.Name, fn.Name, ref.Family, ref.Table, ref.Field)
+		if err != nil {
+			return errors.Wrap(err, "insert reference")
+		}
+	}
+	if strict {
+		_, err = tx.ExecContext(ctx, "INSERT INTO strict_reference_tables (family_name, table_name) VALUES (?, ?)",
+			famName.Name, tblName.Name)
+		if err != nil {
+			return errors.Wrap(err, "insert strict table")
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "commit tx")
+	}
+	events.Log("Set %{count}d references of table %{table}s, strict: %{strict}t",
+		len(refs), schema.LDBTableName(famName, tblName), strict)
+	return nil
+}
+
+// validateReferences checks that references name fields of tbl, and that
+// each references a key field of the same type, and returns them with
+// their names normalized.
+func (e *dbExecutive) validateReferences(tbl sqlgen.MetaTable, references map[string]schema.FieldReference) (map[schema.FieldName]schema.FieldReference, error) {
+	targets := map[schema.FamilyTable]sqlgen.MetaTable{}
+	referencedBy := map[schema.FieldReference]schema.FieldName{}
+	res := make(map[schema.FieldName]schema.FieldReference, len(references))
+	for name, ref := range references {
+		fn, err := schema.NewFieldName(name)
+		if err != nil {
+			return nil, &errs.BadRequestError{Err: err.Error()}
+		}
+		ft, ok := tbl.FieldTypeByName(fn)
+		if !ok {
+			return nil, errs.BadRequest("Unknown field %s", fn)
+		}
+		refFamName, err := schema.NewFamilyName(ref.Family)
+		if err != nil {
+			return nil, errs.BadRequest("Reference of field %s: %s", fn, err)
+		}
+		refTblName, err := schema.NewTableName(ref.Table)
+		if err != nil {
+			return nil, errs.BadRequest("Reference of field %s: %s", fn, err)
+		}
+		refFn, err := schema.NewFieldName(ref.Field)
+		if err != nil {
+			return nil, errs.BadRequest("Reference of field %s: %s", fn, err)
+		}
+		ref = schema.FieldReference{Family: refFamName.Name, Table: refTblName.Name, Field: refFn.Name}
+
+		target, ok := targets[ref.FamilyTable()]
+		if !ok {
+			target, ok, err = e.fetchMetaTableByName(refFamName, refTblName)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return nil, errs.BadRequest("Field %s references table %s, which doesn't exist", fn, ref.FamilyTable())
+			}
+			targets[ref.FamilyTable()] = target
+		}
+		isKey := false
+		for _, kf := range target.KeyFields.Fields {
+			isKey = isKey || kf == refFn
+		}
+		if !isKey {
+			return nil, errs.BadRequest("Field %s references %s, which isn't a key field", fn, ref)
+		}
+		if refFt, _ := target.FieldTypeByName(refFn); refFt != ft {
+			return nil, errs.BadRequest("Field %s is a %s, but references %s, which is a %s", fn, ft, ref, refFt)
+		}
+		if other, ok := referencedBy[ref]; ok {
+			return nil, errs.BadRequest("Fields %s and %s both reference %s", other, fn, ref)
+		}
+		referencedBy[ref] = fn
+		res[fn] = ref
+	}
+	return res, nil
+}
+
+// readTableReferences reads the references of a table.
+func readTableReferences(ctx context.Context, db *sql.DB, famName schema.FamilyName, tblName schema.TableName) (tableReferences, error) {
+	var res tableReferences
+	rows, err := db.QueryContext(ctx, "SELECT field_name, ref_family_name, ref_table_name, ref_field_name "+
+		"FROM field_references WHERE family_name = ? AND table_name = ?", famName.Name, tblName.Name)
+	if err != nil {
+		return res, errors.Wrap(err, "select references")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var ref schema.FieldReference
+		if err := rows.Scan(&name, &ref.Family, &ref.Table, &ref.Field); err != nil {
+			return res, errors.Wrap(err, "scan reference")
+		}
+		fn, err := schema.NewFieldName(name)
+		if err != nil {
+			return res, err
+		}
+		if res.references == nil {
+			res.references = map[schema.FieldName]schema.FieldReference{}
+		}
+		res.references[fn] = ref
+	}
+	if err := rows.Err(); err != nil {
+		return res, errors.Wrap(err, "select references")
+	}
+
+	var n int
+	err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM strict_reference_tables WHERE family_name = ? AND table_name = ?",
+		famName.Name, tblName.Name).Scan(&n)
+	if err != nil {
+		return res, errors.Wrap(err, "select strict table")
+	}
+	res.strict = n > 0
+	return res, nil
+}
+
+// readStrictReferences reads the references of a family's strict tables,
+// keyed by table name, which is all that's needed to check mutations.
+func readStrictReferences(ctx context.Context, db *sql.DB, famName schema.FamilyName) (map[schema.TableName]tableReferences, error) {
+	rows, err := db.QueryContext(ctx, "SELECT r.table_name, r.field_name, r.ref_family_name, r.ref_table_name, r.ref_field_name "+
+		"FROM field_references r JOIN strict_reference_tables s "+
+		"ON s.family_name = r.family_name AND s.table_name = r.table_name "+
+		"WHERE r.family_name = ?", famName.Name)
+	if err != nil {
+		return nil, errors.Wrap(err, "select strict references")
+	}
+	defer rows.Close()
+	res := map[schema.TableName]tableReferences{}
+	for rows.Next() {
+		var table, field string
+		var ref schema.FieldReference
+		if err := rows.Scan(&table, &field, &ref.Family, &ref.Table, &ref.Field); err != nil {
+			return nil, errors.Wrap(err, "scan reference")
+		}
+		tblName, err := schema.NewTableName(table)
+		if err != nil {
+			return nil, err
+		}
+		fn, err := schema.NewFieldName(field)
+		if err != nil {
+			return nil, err
+		}
+		refs, ok := res[tblName]
+		if !ok {
+			refs = tableReferences{strict: true, references: map[schema.FieldName]schema.FieldReference{}}
+			res[tblName] = refs
+		}
+		refs.references[fn] = ref
+	}
+	return res, errors.Wrap(rows.Err(), "select strict references")
+}
+
+// check returns an error if the values an upsert sets for the referencing
+// fields don't match the key of an existing row of each referenced table.
+// As with foreign keys, a reference is only checked when each of its fields
+// is set and isn't NULL. It must be called in the mutation's transaction,
+// so that rows upserted earlier in the transaction can be referenced.
+func (refs tableReferences) check(ctx context.Context, tx *sql.Tx, fieldNames []schema.FieldName, values []interface{}) error {
+	if !refs.strict {
+		return nil
+	}
+	valueOf := make(map[schema.FieldName]interface{}, len(fieldNames))
+	for i, fn := range fieldNames {
+		valueOf[fn] = values[i]
+	}
+
+	// fields which reference the same table make a composite reference
+	byTable := map[schema.FamilyTable][]schema.FieldName{}
+	for fn, ref := range refs.references {
+		byTable[ref.FamilyTable()] = append(byTable[ref.FamilyTable()], fn)
+	}
+	tables := make([]schema.FamilyTable, 0, len(byTable))
+	for ft := range byTable {
+		tables = append(tables, ft)
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].String() < tables[j].String() })
+
+tables:
+	for _, ft := range tables {
+		fns := byTable[ft]
+		sort.Slice(fns, func(i, j int) bool { return fns[i].Name < fns[j].Name })
+		where := []string{}
+		desc := []string{}
+		args := []interface{}{}
+		for _, fn := range fns {
+			v, ok := valueOf[fn]
+			if !ok || v == nil {
+				continue tables
+			}
+			where = append(where, refs.references[fn].Field+" = ?")
+			desc = append(desc, fmt.Sprintf("%s=%v", refs.references[fn].Field, v))
+			args = append(args, v)
+		}
+		qs := "SELECT 1 FROM " + ft.String() + " WHERE " + strings.Join(where, " AND ") + " LIMIT 1"
+		var exists int
+		err := tx.QueryRowContext(ctx, qs, args...).Scan(&exists)
+		switch {
+		case err == sql.ErrNoRows:
+			return errs.BadRequest("No row of %s matches the reference %s", ft, strings.Join(desc, ", "))
+		case err != nil:
+			return errors.Wrap(err, "checking references")
+		}
+	}
+	return nil
+}
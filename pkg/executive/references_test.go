@@ -0,0 +1,114 @@
+package executive
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+// testDBExecutiveReferences is run from TestAllDBExecutive
+func testDBExecutiveReferences(t *testing.T, dbType string) {
+	u := newDbExecTestUtil(t, dbType)
+	defer u.Close()
+
+	err := u.e.CreateTables([]schema.Table{
+		{
+			Family:    "family1",
+			Name:      "users",
+			Fields:    [][]string{{"org_id", "integer"}, {"id", "integer"}, {"name", "string"}},
+			KeyFields: []string{"org_id", "id"},
+		},
+		{
+			Family:    "family1",
+			Name:      "memberships",
+			Fields:    [][]string{{"id", "string"}, {"org_id", "integer"}, {"user_id", "integer"}, {"role", "string"}},
+			KeyFields: []string{"id"},
+		},
+	})
+	require.NoError(t, err)
+	memberships := schema.FamilyTable{Family: "family1", Table: "memberships"}
+	userRef := func(field string) schema.FieldReference {
+		return schema.FieldReference{Family: "family1", Table: "users", Field: field}
+	}
+
+	for _, refs := range []map[string]schema.FieldReference{
+		{"missing": userRef("id")},
+		{"role": userRef("name")},
+		{"role": userRef("id")},
+		{"user_id": {Family: "family1", Table: "missing", Field: "id"}},
+		{"org_id": userRef("id"), "user_id": userRef("id")},
+	} {
+		err = u.e.SetTableReferences(memberships, refs, true)
+		require.IsType(t, &errs.BadRequestError{}, errors.Cause(err), "%v", refs)
+	}
+	err = u.e.SetTableReferences(memberships, nil, true)
+	require.IsType(t, &errs.BadRequestError{}, errors.Cause(err))
+	err = u.e.SetTableReferences(schema.FamilyTable{Family: "family1", Table: "missing"}, nil, false)
+	require.IsType(t, &errs.NotFoundError{}, errors.Cause(err))
+
+	// org_id and user_id make a composite reference to the key of users
+	refs := map[string]schema.FieldReference{"org_id": userRef("org_id"), "user_id": userRef("id")}
+	require.NoError(t, u.e.SetTableReferences(memberships, refs, true))
+	tableSchema, err := u.e.TableSchema("family1", "memberships")
+	require.NoError(t, err)
+	require.Equal(t, refs, tableSchema.References)
+	require.True(t, tableSchema.StrictReferences)
+
+	mutate := func(reqs ...ExecutiveMutationRequest) error {
+		_, err := u.e.Mutate("writer1", "", "family1", []byte{2}, nil, reqs)
+		return err
+	}
+	membership := func(id string, orgID, userID interface{}) ExecutiveMutationRequest {
+		return ExecutiveMutationRequest{
+			TableName: "memberships",
+			Values:    map[string]interface{}{"id": id, "org_id": orgID, "user_id": userID, "role": "admin"},
+		}
+	}
+	user := ExecutiveMutationRequest{
+		TableName: "users",
+		Values:    map[string]interface{}{"org_id": 1, "id": 2, "name": "alice"},
+	}
+
+	err = mutate(membership("a", 1, 2))
+	require.IsType(t, &errs.BadRequestError{}, errors.Cause(err))
+	require.EqualError(t, err, "No row of family1___users matches the reference org_id=1, id=2")
+	// rows upserted earlier in the request can be referenced
+	require.NoError(t, mutate(user, membership("a", 1, 2)))
+	err = mutate(membership("b", 2, 2))
+	require.IsType(t, &errs.BadRequestError{}, errors.Cause(err))
+	// NULL references aren't checked
+	require.NoError(t, mutate(membership("b", 2, nil)))
+	// nor are deletes
+	require.NoError(t, mutate(ExecutiveMutationRequest{
+		TableName: "users",
+		Delete:    true,
+		Values:    map[string]interface{}{"org_id": 1, "id": 2},
+	}))
+
+	// references follow renamed fields
+	require.NoError(t, u.e.AlterField("family1", "memberships", "user_id", "member_id", 0))
+	tableSchema, err = u.e.TableSchema("family1", "memberships")
+	require.NoError(t, err)
+	refs = map[string]schema.FieldReference{"org_id": userRef("org_id"), "member_id": userRef("id")}
+	require.Equal(t, refs, tableSchema.References)
+
+	// and aren't checked once they're no longer strict
+	require.NoError(t, u.e.SetTableReferences(memberships, refs, false))
+	require.NoError(t, mutate(ExecutiveMutationRequest{
+		TableName: "memberships",
+		Values:    map[string]interface{}{"id": "c", "org_id": 3, "member_id": 3, "role": "admin"},
+	}))
+
+	// dropping the referenced table drops the references to it
+	require.NoError(t, u.e.SetTableReferences(memberships, map[string]schema.FieldReference{
+		"member_id": userRef("id"),
+	}, true))
+	require.NoError(t, u.e.DropTable(schema.FamilyTable{Family: "family1", Table: "users"}))
+	tableSchema, err = u.e.TableSchema("family1", "memberships")
+	require.NoError(t, err)
+	require.Empty(t, tableSchema.References)
+}
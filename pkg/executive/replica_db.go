@@ -0,0 +1,73 @@
+package executive
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+	"time"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+	"github.com/segmentio/ctlstore/pkg/utils"
+	"github.com/segmentio/events/v2"
+	"github.com/segmentio/stats/v4"
+)
+
+const defaultReplicaHealthInterval = 5 * time.Second
+
+// replicaDB tracks the health of a ctldb read replica.  Reads are routed
+// to the replica only while its most recent health check passed, so that
+// an unavailable or broken replica degrades to reading from the primary
+// instead of failing requests.
+type replicaDB struct {
+	db       *sql.DB
+	interval time.Duration
+	healthy  int32
+}
+
+func newReplicaDB(db *sql.DB, interval time.Duration) *replicaDB {
+	if interval <= 0 {
+		interval = defaultReplicaHealthInterval
+	}
+	return &replicaDB{db: db, interval: interval}
+}
+
+// start blocks, checking the health of the replica until the context
+// is cancelled.
+func (r *replicaDB) start(ctx context.Context) {
+	utils.CtxFireLoop(ctx, r.interval, func() {
+		r.check(ctx)
+	})
+}
+
+func (r *replicaDB) check(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, r.interval)
+	defer cancel()
+	err := r.db.PingContext(ctx)
+	if err != nil {
+		if atomic.SwapInt32(&r.healthy, 0) == 1 {
+			events.Log("ctldb replica unhealthy, reads falling back to primary: %{error}+v", err)
+		}
+		errs.IncrDefault(stats.T("op", "replica-health-check"))
+		stats.Set("replica-healthy", 0)
+		return
+	}
+	if atomic.SwapInt32(&r.healthy, 1) == 0 {
+		events.Log("ctldb replica healthy, routing reads to replica")
+	}
+	stats.Set("replica-healthy", 1)
+}
+
+// readDB returns the replica if it is healthy, and nil otherwise.
+func (r *replicaDB) readDB() *sql.DB {
+	if r == nil || atomic.LoadInt32(&r.healthy) == 0 {
+		return nil
+	}
+	return r.db
+}
+
+func (r *replicaDB) Close() error {
+	if r == nil {
+		return nil
+	}
+	return r.db.Close()
+}
@@ -0,0 +1,60 @@
+package executive
+
+import (
+	"context"
+	"testing"
+
+	"github.com/segmentio/ctlstore/pkg/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplicaDBHealthFallback(t *testing.T) {
+	ctx := context.Background()
+	db, teardown := newCtlDBTestConnection(t, "sqlite3")
+	defer teardown()
+
+	r := newReplicaDB(db, 0)
+	require.Nil(t, r.readDB(), "replica should not be used before it is checked")
+
+	r.check(ctx)
+	require.Equal(t, db, r.readDB())
+
+	require.NoError(t, db.Close())
+	r.check(ctx)
+	require.Nil(t, r.readDB(), "closed replica should not be used")
+
+	var nilReplica *replicaDB
+	require.Nil(t, nilReplica.readDB())
+	require.NoError(t, nilReplica.Close())
+}
+
+func TestDBExecutiveReadsFromReplica(t *testing.T) {
+	u := newDbExecTestUtil(t, "sqlite3")
+	defer u.Close()
+
+	replica, teardown := newCtlDBTestConnection(t, "sqlite3")
+	defer teardown()
+	defer replica.Close()
+	_, err := replica.Exec("UPDATE family1___table10 SET field2='from-replica' WHERE field1=1")
+	require.NoError(t, err)
+
+	out, err := u.e.ReadRow("family1", "table10", map[string]interface{}{"field1": 1})
+	require.NoError(t, err)
+	require.Equal(t, "foo", out["field2"])
+
+	u.e.ReadDB = replica
+	out, err = u.e.ReadRow("family1", "table10", map[string]interface{}{"field1": 1})
+	require.NoError(t, err)
+	require.Equal(t, "from-replica", out["field2"])
+
+	// writes are never sent to the replica
+	require.NoError(t, u.e.CreateFamily("family2"))
+	famName, err := schema.NewFamilyName("family2")
+	require.NoError(t, err)
+	_, ok, err := u.e.fetchFamilyByName(famName)
+	require.NoError(t, err)
+	require.True(t, ok)
+	var count int
+	require.NoError(t, replica.QueryRow("SELECT COUNT(*) FROM families WHERE name='family2'").Scan(&count))
+	require.Equal(t, 0, count)
+}
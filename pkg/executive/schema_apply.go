@@ -0,0 +1,226 @@
+package executive
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/segmentio/events/v2"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+	"github.com/segmentio/ctlstore/pkg/schema"
+	"github.com/segmentio/ctlstore/pkg/sqlgen"
+)
+
+// The types of SchemaChange
+const (
+	SchemaChangeCreateFamily = "create-family"
+	SchemaChangeCreateTable  = "create-table"
+	SchemaChangeAddField     = "add-field"
+)
+
+// SchemaChange is a change ApplySchema makes to bring the schemas in the
+// ctldb in line with the desired tables.
+type SchemaChange struct {
+	Type   string `json:"type"`
+	Family string `json:"family"`
+	Table  string `json:"table,omitempty"`
+	// Field and FieldType are set for add-field changes
+	Field     string `json:"field,omitempty"`
+	FieldType string `json:"fieldType,omitempty"`
+}
+
+// SchemaPlan lists the changes ApplySchema made, or would have made if it
+// was a dry run.
+type SchemaPlan struct {
+	Changes []SchemaChange `json:"changes"`
+	Applied bool           `json:"applied"`
+}
+
+// plannedTable is a desired table that doesn't exist yet, or that is
+// missing fields.
+type plannedTable struct {
+	table      schema.Table
+	create     bool
+	fieldNames []string
+	fieldTypes []schema.FieldType
+}
+
+// ApplySchema diffs the desired tables against the schemas in the ctldb and
+// creates the families and tables that are missing, and adds the fields
+// that tables are missing. Fields and tables that aren't in the desired
+// state are left alone, so applying the same tables twice is a no-op.
+//
+// A desired table whose key fields or field types differ from the existing
+// table is a conflict, and nothing is changed. The changes themselves
+// aren't made in a single transaction, since DDL can't be rolled back, but
+// after a failure the remaining changes are made by applying again.
+func (e *dbExecutive) ApplySchema(tables []schema.Table, dryRun bool) (SchemaPlan, error) {
+	planned, plan, err := e.planSchema(tables)
+	if err != nil {
+		return SchemaPlan{}, err
+	}
+	if dryRun {
+		return plan, nil
+	}
+
+	for _, change := range plan.Changes {
+		if change.Type != SchemaChangeCreateFamily {
+			continue
+		}
+		if err := e.CreateFamily(change.Family); err != nil {
+			return SchemaPlan{}, errors.Wrapf(err, "creating family %q", change.Family)
+		}
+	}
+	for _, p := range planned {
+		tbl := p.table
+		if p.create {
+			err = e.createTable(tbl.Family, tbl.Name, p.fieldNames, p.fieldTypes, tbl.KeyFields, tbl.FieldOptions, tbl.UniqueConstraints)
+			if err != nil {
+				return SchemaPlan{}, errors.Wrapf(err, "creating table for family %q table %q", tbl.Family, tbl.Name)
+			}
+			continue
+		}
+		var fieldOptions map[string]schema.FieldOptions
+		for _, name := range p.fieldNames {
+			if opts, ok := tbl.FieldOptions[name]; ok {
+				if fieldOptions == nil {
+					fieldOptions = map[string]schema.FieldOptions{}
+				}
+				fieldOptions[name] = opts
+			}
+		}
+		err = e.AddFields(tbl.Family, tbl.Name, p.fieldNames, p.fieldTypes, fieldOptions)
+		if err != nil {
+			return SchemaPlan{}, errors.Wrapf(err, "adding fields to family %q table %q", tbl.Family, tbl.Name)
+		}
+	}
+	plan.Applied = true
+	events.Log("Applied %{count}d schema changes", len(plan.Changes))
+	return plan, nil
+}
+
+// planSchema returns the tables that ApplySchema has to create or add
+// fields to, and the changes that amounts to.
+func (e *dbExecutive) planSchema(tables []schema.Table) ([]plannedTable, SchemaPlan, error) {
+	plan := SchemaPlan{Changes: []SchemaChange{}}
+	var planned []plannedTable
+	var conflicts []string
+	families := map[string]bool{}
+	seen := map[schema.FamilyTable]bool{}
+
+	for _, table := range tables {
+		famName, err := schema.NewFamilyName(table.Family)
+		if err != nil {
+			return nil, plan, &errs.BadRequestError{Err: fmt.Sprintf("family %q: %v", table.Family, err)}
+		}
+		tblName, err := schema.NewTableName(table.Name)
+		if err != nil {
+			return nil, plan, &errs.BadRequestError{Err: fmt.Sprintf("table %q: %v", table.Name, err)}
+		}
+		table.Family, table.Name = famName.Name, tblName.Name
+		ft := schema.FamilyTable{Family: table.Family, Table: table.Name}
+		if seen[ft] {
+			return nil, plan, errs.BadRequest("Table %s is listed more than once", ft)
+		}
+		seen[ft] = true
+
+		familyExists, checked := families[table.Family]
+		if !checked {
+			_, familyExists, err = e.fetchFamilyByName(famName)
+			if err != nil {
+				return nil, plan, err
+			}
+			families[table.Family] = familyExists
+			if !familyExists {
+				plan.Changes = append(plan.Changes, SchemaChange{Type: SchemaChangeCreateFamily, Family: table.Family})
+			}
+		}
+
+		if table.Template != "" {
+			if !familyExists {
+				return nil, plan, errs.BadRequest("Table %s uses template %q of a family that doesn't exist yet", ft, table.Template)
+			}
+			tmpl, err := e.readTableTemplate(table.Family, table.Template)
+			if err != nil {
+				return nil, plan, errors.Wrap(err, fmt.Sprintf("reading template %q for family %q", table.Template, table.Family))
+			}
+			table.Fields, table.KeyFields, err = tmpl.Apply(table.Fields, table.KeyFields)
+			if err != nil {
+				return nil, plan, errors.Wrap(err, fmt.Sprintf("applying template %q for family %q table %q", table.Template, table.Family, table.Name))
+			}
+		}
+		fieldNames, fieldTypes, err := schema.UnzipFieldsParam(table.Fields)
+		if err != nil {
+			return nil, plan, err
+		}
+
+		var existing sqlgen.MetaTable
+		exists := false
+		if familyExists {
+			existing, exists, err = e.fetchMetaTableByNameFrom(e.DB, famName, tblName)
+			if err != nil {
+				return nil, plan, errors.Wrap(err, "fetch meta table")
+			}
+		}
+		if !exists {
+			plan.Changes = append(plan.Changes, SchemaChange{Type: SchemaChangeCreateTable, Family: table.Family, Table: table.Name})
+			planned = append(planned, plannedTable{table: table, create: true, fieldNames: fieldNames, fieldTypes: fieldTypes})
+			continue
+		}
+
+		var keyFields []string
+		for _, name := range table.KeyFields {
+			keyFields = append(keyFields, strings.ToLower(name))
+		}
+		existingKeyFields := schema.StringifyFieldNames(existing.KeyFields.Fields)
+		if strings.Join(keyFields, ",") != strings.Join(existingKeyFields, ",") {
+			conflicts = append(conflicts, fmt.Sprintf("%s has key fields %v, not %v", ft, existingKeyFields, table.KeyFields))
+		}
+		// unique constraints can't be added to existing tables
+		var uniqueConstraints, existingUniqueConstraints [][]string
+		for _, names := range table.UniqueConstraints {
+			var lowered []string
+			for _, name := range names {
+				lowered = append(lowered, strings.ToLower(name))
+			}
+			uniqueConstraints = append(uniqueConstraints, lowered)
+		}
+		for _, fns := range existing.UniqueConstraints {
+			existingUniqueConstraints = append(existingUniqueConstraints, schema.StringifyFieldNames(fns))
+		}
+		if fmt.Sprint(uniqueConstraints) != fmt.Sprint(existingUniqueConstraints) {
+			conflicts = append(conflicts, fmt.Sprintf("%s has unique constraints %v, not %v", ft, existingUniqueConstraints, table.UniqueConstraints))
+		}
+		p := plannedTable{table: table}
+		for i, name := range fieldNames {
+			fn, err := schema.NewFieldName(name)
+			if err != nil {
+				return nil, plan, &errs.BadRequestError{Err: fmt.Sprintf("field %q: %v", name, err)}
+			}
+			existingType, ok := existing.FieldTypeByName(fn)
+			switch {
+			case !ok:
+				p.fieldNames = append(p.fieldNames, name)
+				p.fieldTypes = append(p.fieldTypes, fieldTypes[i])
+				plan.Changes = append(plan.Changes, SchemaChange{
+					Type:      SchemaChangeAddField,
+					Family:    table.Family,
+					Table:     table.Name,
+					Field:     fn.Name,
+					FieldType: fieldTypes[i].String(),
+				})
+			case existingType != fieldTypes[i]:
+				conflicts = append(conflicts, fmt.Sprintf("%s field %s is of type %s, not %s", ft, fn, existingType, fieldTypes[i]))
+			}
+		}
+		if len(p.fieldNames) > 0 {
+			planned = append(planned, p)
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return nil, plan, &errs.ConflictError{Err: "Schema conflicts: " + strings.Join(conflicts, "; ")}
+	}
+	return planned, plan, nil
+}
@@ -0,0 +1,141 @@
+package executive
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/segmentio/ctlstore/pkg/errs"
+	"github.com/segmentio/events/v2"
+	"github.com/segmentio/stats/v4"
+)
+
+const (
+	defaultShadowQueueSize = 1000
+	shadowRequestTimeout   = 10 * time.Second
+	// ShadowHeader is set on requests replayed against a shadow executive.
+	ShadowHeader = "X-Ctlstore-Shadow"
+)
+
+// shadowWriter replays write requests against a secondary executive, which
+// is usually backed by the ctldb being migrated to. Requests are replayed in
+// order by a single goroutine, so that mutations are applied to the shadow
+// in the same order as to the primary, and the shadow's response status is
+// compared with the primary's to detect divergence. A slow or unavailable
+// shadow never delays the primary: once the queue is full, requests are
+// dropped.
+type shadowWriter struct {
+	url    string
+	client *http.Client
+	queue  chan shadowRequest
+}
+
+type shadowRequest struct {
+	method        string
+	uri           string
+	header        http.Header
+	body          []byte
+	primaryStatus int
+}
+
+func newShadowWriter(url string, queueSize int) *shadowWriter {
+	if queueSize <= 0 {
+		queueSize = defaultShadowQueueSize
+	}
+	return &shadowWriter{
+		url:    strings.TrimSuffix(url, "/"),
+		client: &http.Client{Timeout: shadowRequestTimeout},
+		queue:  make(chan shadowRequest, queueSize),
+	}
+}
+
+// shadows reports whether the request should be replayed against the shadow.
+// Only requests that can change the ctldb are.
+func (s *shadowWriter) shadows(r *http.Request) bool {
+	return s != nil && r.Method != http.MethodGet && r.Method != http.MethodHead
+}
+
+// serve handles the request with next and then queues it to be replayed.
+func (s *shadowWriter) serve(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	sw := &statusWriter{writer: w}
+	next.ServeHTTP(sw, r)
+
+	req := shadowRequest{
+		method:        r.Method,
+		uri:           r.URL.RequestURI(),
+		header:        r.Header.Clone(),
+		body:          body,
+		primaryStatus: sw.code,
+	}
+	if req.primaryStatus == 0 {
+		req.primaryStatus = http.StatusOK
+	}
+	select {
+	case s.queue <- req:
+	default:
+		stats.Incr("shadow.requests", stats.T("result", "dropped"))
+	}
+}
+
+// start blocks, replaying queued requests until the context is cancelled.
+func (s *shadowWriter) start(ctx context.Context) {
+	events.Log("Shadowing writes to %{url}s", s.url)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case req := <-s.queue:
+			stats.Set("shadow.queue-depth", len(s.queue))
+			s.replay(ctx, req)
+		}
+	}
+}
+
+func (s *shadowWriter) replay(ctx context.Context, req shadowRequest) {
+	status, err := s.send(ctx, req)
+	primaryStatus := strconv.Itoa(req.primaryStatus)
+	if err != nil {
+		events.Log("Shadow %{method}s %{uri}s failed: %{error}+v", req.method, req.uri, err)
+		errs.IncrDefault(stats.T("op", "shadow-request"))
+		stats.Incr("shadow.requests", stats.T("result", "error"), stats.T("primary-status", primaryStatus))
+		return
+	}
+	result := "match"
+	if status != req.primaryStatus {
+		result = "diverged"
+		events.Log("Shadow diverged on %{method}s %{uri}s: primary responded %{primary}d, shadow %{shadow}d",
+			req.method, req.uri, req.primaryStatus, status)
+	}
+	stats.Incr("shadow.requests",
+		stats.T("result", result),
+		stats.T("primary-status", primaryStatus),
+		stats.T("shadow-status", strconv.Itoa(status)))
+}
+
+func (s *shadowWriter) send(ctx context.Context, req shadowRequest) (int, error) {
+	hr, err := http.NewRequestWithContext(ctx, req.method, s.url+req.uri, bytes.NewReader(req.body))
+	if err != nil {
+		return 0, errors.Wrap(err, "build shadow request")
+	}
+	hr.Header = req.header
+	hr.Header.Set(ShadowHeader, "true")
+	resp, err := s.client.Do(hr)
+	if err != nil {
+		return 0, errors.Wrap(err, "send shadow request")
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,60 @@
+package executive
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShadowWriter(t *testing.T) {
+	type received struct {
+		uri, body, writer, shadow string
+	}
+	shadowStatus := http.StatusOK
+	requests := make(chan received, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		requests <- received{r.URL.RequestURI(), string(body), r.Header.Get("ctlstore-writer"), r.Header.Get(ShadowHeader)}
+		w.WriteHeader(shadowStatus)
+	}))
+	defer srv.Close()
+
+	s := newShadowWriter(srv.URL+"/", 0)
+	primary := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the primary sees the whole body too
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		w.Write(body)
+	})
+
+	get := httptest.NewRequest(http.MethodGet, "/status", nil)
+	require.False(t, s.shadows(get))
+	var nilShadow *shadowWriter
+	require.False(t, nilShadow.shadows(get))
+
+	post := httptest.NewRequest(http.MethodPost, "/families/f/mutations?x=1", bytes.NewReader([]byte(`{"cookie":"AQ=="}`)))
+	post.Header.Set("ctlstore-writer", "writer1")
+	require.True(t, s.shadows(post))
+	rr := httptest.NewRecorder()
+	s.serve(rr, post, primary)
+	require.Equal(t, `{"cookie":"AQ=="}`, rr.Body.String())
+
+	require.Len(t, s.queue, 1)
+	req := <-s.queue
+	require.Equal(t, http.StatusOK, req.primaryStatus)
+	status, err := s.send(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, received{"/families/f/mutations?x=1", `{"cookie":"AQ=="}`, "writer1", "true"}, <-requests)
+
+	shadowStatus = http.StatusConflict
+	status, err = s.send(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusConflict, status)
+}
@@ -0,0 +1,219 @@
+package executive
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/segmentio/events/v2"
+	"github.com/segmentio/stats/v4"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+	"github.com/segmentio/ctlstore/pkg/schema"
+	"github.com/segmentio/ctlstore/pkg/utils"
+)
+
+// TableAnalyzerConfig configures the background job which refreshes the
+// index statistics of the ctldb's family tables. MySQL samples the
+// statistics as tables change, which can leave them far off on large
+// tables, skewing query plans for ReadRow and the table sizes that the
+// limiter enforces.
+type TableAnalyzerConfig struct {
+	// Interval is the time between scheduled runs. Zero disables them, but
+	// tables can still be analyzed on demand.
+	Interval time.Duration
+	// MinTableSize is the size in bytes, including indexes, that a table
+	// must exceed to be analyzed by a scheduled run.
+	MinTableSize int64
+	// Concurrency is how many tables are analyzed at once. Defaults to 1.
+	Concurrency int
+	// Optimize also rebuilds the tables with OPTIMIZE TABLE, which reclaims
+	// the space of deleted rows but takes far longer than analyzing them.
+	// It has no effect on sqlite3.
+	Optimize bool
+}
+
+// TableAnalysis is the outcome of analyzing a table.
+type TableAnalysis struct {
+	Family string `json:"family"`
+	Table  string `json:"table"`
+	// Size is the table's size in bytes before it was analyzed, which is
+	// always zero on sqlite3
+	Size     int64  `json:"size"`
+	Duration string `json:"duration"`
+	Error    string `json:"error,omitempty"`
+}
+
+// tableAnalyzer runs ANALYZE TABLE, and optionally OPTIMIZE TABLE, against
+// the family tables of the ctldb. Only one run happens at a time across
+// scheduled and on-demand runs of the same executive instance.
+type tableAnalyzer struct {
+	db      *sql.DB
+	dbType  string
+	config  TableAnalyzerConfig
+	running int32
+}
+
+func newTableAnalyzer(db *sql.DB, dbType string, config TableAnalyzerConfig) *tableAnalyzer {
+	if config.Concurrency <= 0 {
+		config.Concurrency = 1
+	}
+	return &tableAnalyzer{
+		db:     db,
+		dbType: dbType,
+		config: config,
+	}
+}
+
+// start analyzes the tables over the size threshold every interval, if
+// scheduled runs are enabled.
+func (a *tableAnalyzer) start(ctx context.Context) {
+	if a.config.Interval <= 0 {
+		events.Log("Table analyzer schedule is disabled")
+		return
+	}
+	events.Log("starting table analyzer with a period of %v", a.config.Interval)
+	go utils.CtxLoop(ctx, a.config.Interval, func() {
+		if _, err := a.analyze(ctx, nil); err != nil {
+			errs.IncrDefault(stats.Tag{Name: "op", Value: "analyze-tables"})
+			events.Log("could not analyze tables: %{err}v", err)
+		}
+	})
+}
+
+// analyze analyzes the given tables, or every table over the size threshold
+// if there are none. Failing to analyze a table doesn't stop the others
+// from being analyzed, and is reported in its TableAnalysis.
+func (a *tableAnalyzer) analyze(ctx context.Context, tables []schema.FamilyTable) ([]TableAnalysis, error) {
+	if !atomic.CompareAndSwapInt32(&a.running, 0, 1) {
+		return nil, &errs.ConflictError{Err: "Tables are already being analyzed"}
+	}
+	defer atomic.StoreInt32(&a.running, 0)
+
+	sizes, err := a.getSizes(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "get table sizes")
+	}
+	var res []TableAnalysis
+	if len(tables) == 0 {
+		for ft, size := range sizes {
+			if size > a.config.MinTableSize {
+				res = append(res, TableAnalysis{Family: ft.Family, Table: ft.Table, Size: size})
+			}
+		}
+		sort.Slice(res, func(i, j int) bool {
+			// the largest tables are the most likely to have drifted
+			return res[i].Size > res[j].Size
+		})
+	} else {
+		for _, ft := range tables {
+			size, ok := sizes[ft]
+			if !ok {
+				return nil, errs.NotFound("Table %s not found", ft)
+			}
+			res = append(res, TableAnalysis{Family: ft.Family, Table: ft.Table, Size: size})
+		}
+	}
+
+	start := time.Now()
+	sem := make(chan struct{}, a.config.Concurrency)
+	var wg sync.WaitGroup
+	for i := range res {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(ta *TableAnalysis) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			a.analyzeTable(ctx, ta)
+		}(&res[i])
+	}
+	wg.Wait()
+
+	stats.Incr("table-analyzer-runs")
+	stats.Observe("table-analyzer-run-time", time.Since(start))
+	events.Log("analyzed %d tables in %v", len(res), time.Since(start))
+	return res, nil
+}
+
+func (a *tableAnalyzer) analyzeTable(ctx context.Context, ta *TableAnalysis) {
+	ft := schema.FamilyTable{Family: ta.Family, Table: ta.Table}
+	start := time.Now()
+	err := a.exec(ctx, "ANALYZE", ft)
+	if err == nil && a.config.Optimize && a.dbType != "sqlite3" {
+		err = a.exec(ctx, "OPTIMIZE", ft)
+	}
+	elapsed := time.Since(start)
+	ta.Duration = elapsed.String()
+	if err != nil {
+		ta.Error = err.Error()
+		errs.Incr("table-analyzer-errors", ft.Tag())
+		events.Log("could not analyze table %{table}s: %{err}v", ft, err)
+		return
+	}
+	stats.Observe("table-analyze-time", elapsed, ft.Tag())
+}
+
+// exec runs an ANALYZE or OPTIMIZE statement against the table. MySQL
+// reports errors in the statement's results rather than failing it.
+func (a *tableAnalyzer) exec(ctx context.Context, op string, ft schema.FamilyTable) error {
+	if a.dbType == "sqlite3" {
+		_, err := a.db.ExecContext(ctx, op+" "+ft.String())
+		return errors.Wrapf(err, "%s %s", op, ft)
+	}
+	rows, err := a.db.QueryContext(ctx, op+" TABLE "+ft.String())
+	if err != nil {
+		return errors.Wrapf(err, "%s %s", op, ft)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table, msgOp, msgType, msgText string
+		if err := rows.Scan(&table, &msgOp, &msgType, &msgText); err != nil {
+			return errors.Wrapf(err, "scan %s %s", op, ft)
+		}
+		if msgType == "error" {
+			return errors.Errorf("%s %s: %s", op, ft, msgText)
+		}
+	}
+	return errors.Wrapf(rows.Err(), "%s %s", op, ft)
+}
+
+// getSizes returns the sizes of the family tables in the ctldb. sqlite3
+// doesn't report them, so every table has a size of zero.
+func (a *tableAnalyzer) getSizes(ctx context.Context) (map[schema.FamilyTable]int64, error) {
+	query := "SELECT table_name, (data_length + index_length) FROM information_schema.tables WHERE table_schema = database()"
+	if a.dbType == "sqlite3" {
+		query = "SELECT name, 0 FROM sqlite_master WHERE type = 'table'"
+	}
+	rows, err := a.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := make(map[schema.FamilyTable]int64)
+	for rows.Next() {
+		var name string
+		var size int64
+		if err := rows.Scan(&name, &size); err != nil {
+			return nil, err
+		}
+		if ft, ok := schema.ParseFamilyTable(name); ok {
+			res[ft] = size
+		}
+	}
+	return res, rows.Err()
+}
+
+func (e *dbExecutive) AnalyzeTables(tables []schema.FamilyTable) ([]TableAnalysis, error) {
+	if e.analyzer == nil {
+		return nil, errors.New("table analysis is not enabled")
+	}
+	ctx, cancel := e.ctx()
+	defer cancel()
+	return e.analyzer.analyze(ctx, tables)
+}
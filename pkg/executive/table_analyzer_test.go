@@ -0,0 +1,60 @@
+package executive
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+func TestTableAnalyzerMySQL(t *testing.T) {
+	doTestTableAnalyzer(t, "mysql")
+}
+
+func TestTableAnalyzerSqlite3(t *testing.T) {
+	doTestTableAnalyzer(t, "sqlite3")
+}
+
+func doTestTableAnalyzer(t *testing.T, dbType string) {
+	ctx := context.Background()
+	db, teardown := newCtlDBTestConnection(t, dbType)
+	defer teardown()
+
+	analyzer := newTableAnalyzer(db, dbType, TableAnalyzerConfig{MinTableSize: -1, Concurrency: 2, Optimize: true})
+
+	// every family table is over a negative threshold
+	res, err := analyzer.analyze(ctx, nil)
+	require.NoError(t, err)
+	tables := map[string]bool{}
+	for _, ta := range res {
+		require.Empty(t, ta.Error, "%s___%s", ta.Family, ta.Table)
+		require.NotEmpty(t, ta.Duration)
+		tables[ta.Family+"___"+ta.Table] = true
+	}
+	require.True(t, tables["family1___table1"])
+	require.True(t, tables["family1___binary_table1"])
+	require.False(t, tables["mutators"])
+
+	res, err = analyzer.analyze(ctx, []schema.FamilyTable{{Family: "family1", Table: "table10"}})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Equal(t, "table10", res[0].Table)
+	require.Empty(t, res[0].Error)
+
+	_, err = analyzer.analyze(ctx, []schema.FamilyTable{{Family: "family1", Table: "missing"}})
+	require.IsType(t, &errs.NotFoundError{}, err)
+
+	// nothing is over a threshold larger than any table
+	analyzer.config.MinTableSize = 1 << 40
+	res, err = analyzer.analyze(ctx, nil)
+	require.NoError(t, err)
+	require.Empty(t, res)
+
+	// runs don't overlap
+	analyzer.running = 1
+	_, err = analyzer.analyze(ctx, nil)
+	require.IsType(t, &errs.ConflictError{}, err)
+}
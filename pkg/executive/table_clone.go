@@ -0,0 +1,288 @@
+package executive
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/segmentio/events/v2"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+	"github.com/segmentio/ctlstore/pkg/limits"
+	"github.com/segmentio/ctlstore/pkg/scanfunc"
+	"github.com/segmentio/ctlstore/pkg/schema"
+	"github.com/segmentio/ctlstore/pkg/sqlgen"
+	"github.com/segmentio/ctlstore/pkg/tracing"
+)
+
+// CloneResult is the outcome of cloning a table.
+type CloneResult struct {
+	Family     string `json:"family"`
+	Table      string `json:"table"`
+	RowsCopied int    `json:"rowsCopied"`
+}
+
+// CloneTable creates the target table with the same fields, key fields and
+// field options as the source table, and then copies up to copyRows of the
+// source's rows into it, in key order. Both the table's creation and the
+// copied rows are written to the ledger, so the clone is reflected into
+// every LDB like any other table.
+//
+// The rows are copied in a ledger transaction of their own after the table
+// is created. They're mutations of the target table made on behalf of the
+// writer, so they count against the writer's rate limit and the target
+// table's size limit, and aren't copied in maintenance mode. If copying
+// fails, the empty target table is left in place.
+func (e *dbExecutive) CloneTable(source schema.FamilyTable, target schema.FamilyTable, copyRows int, writerName string) (res CloneResult, err error) {
+	defer e.trace("executive.CloneTable",
+		tracing.String("family", source.Family), tracing.String("table", source.Table),
+		tracing.String("target_family", target.Family), tracing.String("target_table", target.Table))(&err)
+
+	if copyRows < 0 || copyRows > limits.LimitMaxCloneRows {
+		return CloneResult{}, errs.BadRequest("Can copy between 0 and %d rows", limits.LimitMaxCloneRows)
+	}
+	var wn schema.WriterName
+	if copyRows > 0 {
+		if writerName == "" {
+			return CloneResult{}, errs.BadRequest("A writer is required to copy rows")
+		}
+		wn, err = schema.NewWriterName(writerName)
+		if err != nil {
+			return CloneResult{}, &errs.BadRequestError{Err: err.Error()}
+		}
+		ctx, cancel := e.ctx()
+		defer cancel()
+		ms := mutatorStore{DB: e.DB, Ctx: ctx, TableName: mutatorsTableName}
+		exists, err := ms.Exists(wn)
+		if err != nil {
+			return CloneResult{}, err
+		}
+		if !exists {
+			return CloneResult{}, &errs.NotFoundError{Err: "Writer not found"}
+		}
+	}
+	srcFamName, err := schema.NewFamilyName(source.Family)
+	if err != nil {
+		return CloneResult{}, &errs.BadRequestError{Err: err.Error()}
+	}
+	srcTblName, err := schema.NewTableName(source.Table)
+	if err != nil {
+		return CloneResult{}, &errs.BadRequestError{Err: err.Error()}
+	}
+	famName, err := schema.NewFamilyName(target.Family)
+	if err != nil {
+		return CloneResult{}, &errs.BadRequestError{Err: err.Error()}
+	}
+	tblName, err := schema.NewTableName(target.Table)
+	if err != nil {
+		return CloneResult{}, &errs.BadRequestError{Err: err.Error()}
+	}
+
+	src, ok, err := e.fetchMetaTableByName(srcFamName, srcTblName)
+	if err != nil {
+		return CloneResult{}, err
+	}
+	if !ok {
+		return CloneResult{}, &errs.NotFoundError{Err: "Table not found"}
+	}
+
+	fieldNames := make([]string, len(src.Fields))
+	fieldTypes := make([]schema.FieldType, len(src.Fields))
+	for i, field := range src.Fields {
+		fieldNames[i] = field.Name.Name
+		fieldTypes[i] = field.FieldType
+	}
+	keyFields := make([]string, len(src.KeyFields.Fields))
+	for i, field := range src.KeyFields.Fields {
+		keyFields[i] = field.Name
+	}
+	var fieldOptions map[string]schema.FieldOptions
+	for fn, opts := range src.FieldOptions {
+		if fieldOptions == nil {
+			fieldOptions = map[string]schema.FieldOptions{}
+		}
+		fieldOptions[fn.Name] = opts
+	}
+	var uniqueConstraints [][]string
+	for _, fns := range src.UniqueConstraints {
+		uniqueConstraints = append(uniqueConstraints, schema.StringifyFieldNames(fns))
+	}
+	err = e.createTable(famName.Name, tblName.Name, fieldNames, fieldTypes, keyFields, fieldOptions, uniqueConstraints)
+	if err != nil {
+		return CloneResult{}, err
+	}
+
+	res = CloneResult{Family: famName.Name, Table: tblName.Name}
+	if copyRows > 0 {
+		tbl := src
+		tbl.FamilyName = famName
+		tbl.TableName = tblName
+		res.RowsCopied, err = e.copyRows(src, tbl, copyRows, wn)
+		if err != nil {
+			return res, errors.Wrap(err, "copy rows")
+		}
+	}
+
+	events.Log("Cloned `%{source}s` into `%{target}s` with %{rows}d rows",
+		source.String(), target.String(), res.RowsCopied)
+	return res, nil
+}
+
+// copyRows copies up to limit rows of the source table into the target
+// table, which has the same schema, and writes an upsert of each to the
+// ledger. The upserts count against the writer's rate limit.
+func (e *dbExecutive) copyRows(src sqlgen.MetaTable, tbl sqlgen.MetaTable, limit int, writerName schema.WriterName) (int, error) {
+	ctx, cancel := e.ctx()
+	defer cancel()
+
+	// the rows are counted first, so that the limiter is checked before the
+	// ledger lock is taken, as it is for mutations
+	count, err := countCloneRows(ctx, e.DB, src, limit)
+	if err != nil {
+		return 0, err
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "begin tx")
+	}
+	defer tx.Rollback()
+
+	requests := make([]ExecutiveMutationRequest, count)
+	for i := range requests {
+		requests[i] = ExecutiveMutationRequest{FamilyName: tbl.FamilyName.Name, TableName: tbl.TableName.Name}
+	}
+	_, err = e.limiter.allowed(ctx, tx, limiterRequest{
+		writerName: writerName.Name,
+		requests:   requests,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	err = e.takeLedgerLock(ctx, tx)
+	if err != nil {
+		return 0, errors.Wrap(err, "take ledger lock")
+	}
+
+	err = checkMaintenance(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+
+	// the rows are all read before any is written, since MySQL can't run
+	// statements on a transaction while it's reading a result set. No more
+	// rows are read than were counted against the limit.
+	rows, err := readCloneRows(ctx, tx, src, count)
+	if err != nil {
+		return 0, err
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	dlw := e.ledgerWriter(tx)
+	defer dlw.Close()
+
+	if len(rows) > 1 {
+		if _, err := dlw.BeginTx(ctx); err != nil {
+			return 0, errors.Wrap(err, "logging tx begin failed")
+		}
+	}
+	fieldNames := make([]schema.FieldName, len(tbl.Fields))
+	for i, field := range tbl.Fields {
+		fieldNames[i] = field.Name
+	}
+	for _, row := range rows {
+		values := make([]interface{}, len(tbl.Fields))
+		for i, field := range tbl.Fields {
+			v := row[field.Name.Name]
+			// binary values are passed to the DML generators base64
+			// encoded, as they are in mutations
+			if b, ok := v.([]byte); ok {
+				v = base64.StdEncoding.EncodeToString(b)
+			}
+			values[i] = v
+		}
+
+		dml, ledgerDML, err := e.upsertDML(tbl, fieldNames, values)
+		if err != nil {
+			return 0, err
+		}
+		ledgerStatement, ledgerVersion := ledgerDML.SQL, schema.DMLVersionSQL
+		if e.ParameterizedDML {
+			ledgerStatement, err = ledgerDML.Encode()
+			if err != nil {
+				return 0, err
+			}
+			ledgerVersion = schema.DMLVersionParameterized
+		}
+		if len(ledgerStatement) > limits.LimitMaxDMLSize {
+			return 0, &errs.BadRequestError{Err: "Row generated too large of a DML statement"}
+		}
+
+		if _, err := tx.ExecContext(ctx, dml.SQL, dml.Args...); err != nil {
+			return 0, errors.Wrap(err, "dml exec error")
+		}
+		if _, err := dlw.AddVersion(ctx, ledgerStatement, ledgerVersion); err != nil {
+			return 0, errors.Wrap(err, "log write error")
+		}
+	}
+	if len(rows) > 1 {
+		if _, err := dlw.CommitTx(ctx); err != nil {
+			return 0, errors.Wrap(err, "logging tx commit failed")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errors.Wrap(err, "commit failed")
+	}
+	return len(rows), nil
+}
+
+// countCloneRows counts the table's rows, up to limit.
+func countCloneRows(ctx context.Context, db *sql.DB, tbl sqlgen.MetaTable, limit int) (int, error) {
+	qs := fmt.Sprintf("SELECT COUNT(*) FROM (SELECT 1 FROM %s LIMIT %d) AS clone_rows",
+		schema.LDBTableName(tbl.FamilyName, tbl.TableName), limit)
+	var count int
+	err := db.QueryRowContext(ctx, qs).Scan(&count)
+	return count, errors.Wrap(err, "count rows")
+}
+
+// readCloneRows reads up to limit rows of the table, in key order.
+func readCloneRows(ctx context.Context, tx *sql.Tx, tbl sqlgen.MetaTable, limit int) ([]map[string]interface{}, error) {
+	keys := make([]string, len(tbl.KeyFields.Fields))
+	for i, kf := range tbl.KeyFields.Fields {
+		keys[i] = `"` + kf.Name + `"`
+	}
+	qs := fmt.Sprintf("SELECT * FROM %s ORDER BY %s LIMIT %d",
+		schema.LDBTableName(tbl.FamilyName, tbl.TableName), strings.Join(keys, ", "), limit)
+	rows, err := tx.QueryContext(ctx, qs)
+	if err != nil {
+		return nil, errors.Wrap(err, "select rows")
+	}
+	defer rows.Close()
+	cols, err := schema.DBColumnMetaFromRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	var res []map[string]interface{}
+	for rows.Next() {
+		row := map[string]interface{}{}
+		sfn, err := scanfunc.New(row, cols)
+		if err != nil {
+			return nil, err
+		}
+		if err := sfn(rows); err != nil {
+			return nil, errors.Wrap(err, "scan row")
+		}
+		res = append(res, row)
+	}
+	return res, errors.Wrap(rows.Err(), "read rows")
+}
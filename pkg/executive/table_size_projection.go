@@ -0,0 +1,201 @@
+package executive
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/segmentio/events/v2"
+	"github.com/segmentio/stats/v4"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+	"github.com/segmentio/ctlstore/pkg/limits"
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+const (
+	defaultSizeSampleInterval = time.Hour          // how often table sizes are saved
+	defaultProjectionWindow   = 7 * 24 * time.Hour // how far back growth is measured
+	projectionWarnDays        = 14                 // tables projected to hit their limit sooner are warned about
+)
+
+type tableSizeSample struct {
+	at   int64 // unix seconds
+	size int64
+}
+
+// recordSamples saves the table sizes to the ctldb, at most once per sample
+// interval, so that growth can be projected across executive restarts, and
+// then reports the projections. Samples are keyed by the start of the
+// interval, so that several executives don't save more than one per table.
+func (s *tableSizer) recordSamples(ctx context.Context, sizes map[schema.FamilyTable]int64) error {
+	now := s.now()
+	s.mut.Lock()
+	due := now.Sub(s.lastSampleAt) >= s.sampleInterval
+	if due {
+		s.lastSampleAt = now
+	}
+	s.mut.Unlock()
+	if !due {
+		return nil
+	}
+
+	tx, err := s.ctldb.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin tx")
+	}
+	defer tx.Rollback()
+	sampledAt := now.Truncate(s.sampleInterval).Unix()
+	for ft, size := range sizes {
+		_, err := tx.ExecContext(ctx, "REPLACE INTO table_size_samples "+
+			"(family_name, table_name, sampled_at, size_bytes) VALUES (?, ?, ?, ?)",
+			ft.Family, ft.Table, sampledAt, size)
+		if err != nil {
+			return errors.Wrap(err, "insert table size sample")
+		}
+	}
+	_, err = tx.ExecContext(ctx, "DELETE FROM table_size_samples WHERE sampled_at < ?",
+		now.Add(-s.projectionWindow).Unix())
+	if err != nil {
+		return errors.Wrap(err, "delete old table size samples")
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "commit tx")
+	}
+
+	samples, err := s.readSamples(ctx, nil)
+	if err != nil {
+		return err
+	}
+	s.mut.Lock()
+	maxSizes := map[schema.FamilyTable]int64{}
+	for ft := range samples {
+		maxSizes[ft] = s.defaultTableLimit.MaxSize
+		if limit, ok := s.configuredMaxTableSizes[ft]; ok {
+			maxSizes[ft] = limit.MaxSize
+		}
+	}
+	s.mut.Unlock()
+	for ft, ftSamples := range samples {
+		p := project(ft, ftSamples, maxSizes[ft])
+		if p.DaysUntilLimit == nil {
+			continue
+		}
+		stats.Set("table-size-projected-days", *p.DaysUntilLimit, stats.T("family", ft.Family), stats.T("table", ft.Table))
+		if *p.DaysUntilLimit < projectionWarnDays {
+			stats.Incr("table-size-projection-warning", ft.Tag())
+			events.Log("table %{table}s is projected to reach its max size of %{maxSize}d bytes in %{days}.1f days",
+				ft, p.MaxSize, *p.DaysUntilLimit)
+		}
+	}
+	return nil
+}
+
+// projection returns the size projection of a table from the samples saved
+// in the ctldb. Unlike the rest of the sizer it works with sqlite3, though
+// there won't be any samples unless they're inserted by hand.
+func (s *tableSizer) projection(ctx context.Context, ft schema.FamilyTable) (limits.TableSizeProjection, error) {
+	_, err := s.ctldb.ExecContext(ctx, "SELECT * FROM "+ft.String()+" LIMIT 1")
+	if err != nil {
+		return limits.TableSizeProjection{}, &errs.NotFoundError{Err: "table '" + ft.String() + "' not found"}
+	}
+	samples, err := s.readSamples(ctx, &ft)
+	if err != nil {
+		return limits.TableSizeProjection{}, err
+	}
+	limit, ok, err := s.readLimit(ctx, ft)
+	if err != nil {
+		return limits.TableSizeProjection{}, err
+	}
+	if !ok {
+		limit = s.defaultTableLimit
+	}
+	return project(ft, samples[ft], limit.MaxSize), nil
+}
+
+// project fits a line to the samples to find the table's growth rate.
+func project(ft schema.FamilyTable, samples []tableSizeSample, maxSize int64) limits.TableSizeProjection {
+	res := limits.TableSizeProjection{
+		Family:  ft.Family,
+		Table:   ft.Table,
+		MaxSize: maxSize,
+		Samples: len(samples),
+	}
+	if len(samples) == 0 {
+		return res
+	}
+	res.Size = samples[len(samples)-1].size
+
+	var meanAt, meanSize float64
+	for _, sample := range samples {
+		meanAt += float64(sample.at)
+		meanSize += float64(sample.size)
+	}
+	meanAt /= float64(len(samples))
+	meanSize /= float64(len(samples))
+	var cov, variance float64
+	for _, sample := range samples {
+		dt := float64(sample.at) - meanAt
+		cov += dt * (float64(sample.size) - meanSize)
+		variance += dt * dt
+	}
+	if variance == 0 {
+		return res
+	}
+	res.GrowthPerDay = cov / variance * (24 * time.Hour).Seconds()
+	if res.GrowthPerDay > 0 {
+		days := float64(maxSize-res.Size) / res.GrowthPerDay
+		if days < 0 {
+			days = 0
+		}
+		res.DaysUntilLimit = &days
+	}
+	return res
+}
+
+// readSamples returns the saved samples in order, of just one table if ft
+// isn't nil.
+func (s *tableSizer) readSamples(ctx context.Context, ft *schema.FamilyTable) (map[schema.FamilyTable][]tableSizeSample, error) {
+	qs := "SELECT family_name, table_name, sampled_at, size_bytes FROM table_size_samples"
+	var args []interface{}
+	if ft != nil {
+		qs += " WHERE family_name = ? AND table_name = ?"
+		args = append(args, ft.Family, ft.Table)
+	}
+	rows, err := s.ctldb.QueryContext(ctx, qs+" ORDER BY family_name, table_name, sampled_at", args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "select table size samples")
+	}
+	defer rows.Close()
+	res := map[schema.FamilyTable][]tableSizeSample{}
+	for rows.Next() {
+		var ft schema.FamilyTable
+		var sample tableSizeSample
+		if err := rows.Scan(&ft.Family, &ft.Table, &sample.at, &sample.size); err != nil {
+			return nil, errors.Wrap(err, "scan table size sample")
+		}
+		res[ft] = append(res[ft], sample)
+	}
+	return res, rows.Err()
+}
+
+func (s *tableSizer) readLimit(ctx context.Context, ft schema.FamilyTable) (limits.SizeLimits, bool, error) {
+	var limit limits.SizeLimits
+	err := s.ctldb.QueryRowContext(ctx, "SELECT max_size_bytes, warn_size_bytes FROM max_table_sizes "+
+		"WHERE family_name = ? AND table_name = ?", ft.Family, ft.Table).Scan(&limit.MaxSize, &limit.WarnSize)
+	switch {
+	case err == sql.ErrNoRows:
+		return limit, false, nil
+	case err != nil:
+		return limit, false, errors.Wrap(err, "select table size limit")
+	}
+	return limit, true, nil
+}
+
+func (s *tableSizer) now() time.Time {
+	if s.timeFunc != nil {
+		return s.timeFunc()
+	}
+	return time.Now()
+}
@@ -0,0 +1,111 @@
+package executive
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+	"github.com/segmentio/ctlstore/pkg/limits"
+	"github.com/segmentio/ctlstore/pkg/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectTableSize(t *testing.T) {
+	ft := schema.FamilyTable{Family: "foo", Table: "bar"}
+	day := int64(24 * time.Hour / time.Second)
+	days := func(f float64) *float64 { return &f }
+
+	for _, test := range []struct {
+		name    string
+		samples []tableSizeSample
+		want    limits.TableSizeProjection
+	}{
+		{
+			name: "no samples",
+			want: limits.TableSizeProjection{Family: "foo", Table: "bar", MaxSize: 1000},
+		},
+		{
+			name:    "one sample",
+			samples: []tableSizeSample{{at: day, size: 100}},
+			want:    limits.TableSizeProjection{Family: "foo", Table: "bar", MaxSize: 1000, Size: 100, Samples: 1},
+		},
+		{
+			name:    "growing",
+			samples: []tableSizeSample{{at: 0, size: 100}, {at: day, size: 200}, {at: 2 * day, size: 300}},
+			want: limits.TableSizeProjection{
+				Family: "foo", Table: "bar", MaxSize: 1000, Size: 300, Samples: 3,
+				GrowthPerDay: 100, DaysUntilLimit: days(7),
+			},
+		},
+		{
+			name:    "shrinking",
+			samples: []tableSizeSample{{at: 0, size: 300}, {at: day, size: 200}},
+			want: limits.TableSizeProjection{
+				Family: "foo", Table: "bar", MaxSize: 1000, Size: 200, Samples: 2,
+				GrowthPerDay: -100,
+			},
+		},
+		{
+			name:    "over the limit",
+			samples: []tableSizeSample{{at: 0, size: 1000}, {at: day, size: 1200}},
+			want: limits.TableSizeProjection{
+				Family: "foo", Table: "bar", MaxSize: 1000, Size: 1200, Samples: 2,
+				GrowthPerDay: 200, DaysUntilLimit: days(0),
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.want, project(ft, test.samples, 1000))
+		})
+	}
+}
+
+func TestTableSizerRecordSamples(t *testing.T) {
+	db, teardown := newCtlDBTestConnection(t, "sqlite3")
+	defer teardown()
+	ctx := context.Background()
+
+	_, err := db.ExecContext(ctx, "CREATE TABLE foo___bar (name VARCHAR(100) NOT NULL PRIMARY KEY)")
+	require.NoError(t, err)
+	ft := schema.FamilyTable{Family: "foo", Table: "bar"}
+
+	now := newFakeTime(0)
+	sizer := newTableSizer(db, "sqlite3", limits.SizeLimits{MaxSize: 10000}, time.Minute)
+	sizer.timeFunc = now.get
+
+	p, err := sizer.projection(ctx, ft)
+	require.NoError(t, err)
+	require.Equal(t, limits.TableSizeProjection{Family: "foo", Table: "bar", MaxSize: 10000}, p)
+
+	for i := int64(0); i < 10; i++ {
+		// only the first sample of each interval is saved
+		require.NoError(t, sizer.recordSamples(ctx, map[schema.FamilyTable]int64{ft: 1000 + 100*i}))
+		require.NoError(t, sizer.recordSamples(ctx, map[schema.FamilyTable]int64{ft: 5000}))
+		now.add(int64(12 * time.Hour / time.Second))
+	}
+	p, err = sizer.projection(ctx, ft)
+	require.NoError(t, err)
+	require.Equal(t, 10, p.Samples)
+	require.EqualValues(t, 1900, p.Size)
+	require.InDelta(t, 200, p.GrowthPerDay, 0.001)
+	require.NotNil(t, p.DaysUntilLimit)
+	require.InDelta(t, 40.5, *p.DaysUntilLimit, 0.001)
+
+	// samples older than the projection window are deleted
+	now.add(int64(6 * 24 * time.Hour / time.Second))
+	require.NoError(t, sizer.recordSamples(ctx, map[schema.FamilyTable]int64{ft: 3000}))
+	p, err = sizer.projection(ctx, ft)
+	require.NoError(t, err)
+	require.Equal(t, 3, p.Samples)
+
+	// configured limits are used
+	_, err = db.ExecContext(ctx, "INSERT INTO max_table_sizes (family_name, table_name, warn_size_bytes, max_size_bytes) VALUES ('foo', 'bar', 0, 20000)")
+	require.NoError(t, err)
+	p, err = sizer.projection(ctx, ft)
+	require.NoError(t, err)
+	require.EqualValues(t, 20000, p.MaxSize)
+
+	_, err = sizer.projection(ctx, schema.FamilyTable{Family: "foo", Table: "baz"})
+	require.IsType(t, &errs.NotFoundError{}, err)
+}
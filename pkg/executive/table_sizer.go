@@ -34,6 +34,12 @@ type (
 		defaultTableLimit       limits.SizeLimits
 		configuredMaxTableSizes map[schema.FamilyTable]limits.SizeLimits
 		mut                     sync.Mutex
+
+		// see recordSamples
+		sampleInterval   time.Duration
+		projectionWindow time.Duration
+		lastSampleAt     time.Time
+		timeFunc         func() time.Time
 	}
 )
 
@@ -50,6 +56,8 @@ func newTableSizer(ctldb *sql.DB, dbType string, defaultTableLimit limits.SizeLi
 		defaultTableLimit:       defaultTableLimit,
 		tableSizes:              make(map[schema.FamilyTable]int64), // keyed by full table name
 		configuredMaxTableSizes: make(map[schema.FamilyTable]limits.SizeLimits),
+		sampleInterval:          defaultSizeSampleInterval,
+		projectionWindow:        defaultProjectionWindow,
 	}
 }
 
@@ -82,7 +90,10 @@ func (s *tableSizer) tableOK(ft schema.FamilyTable) (found bool, err error) {
 	switch {
 	case tableSize > maxSize:
 		errs.Incr("table-size-overage", ft.Tag())
-		return found, &errs.InsufficientStorageErr{Err: fmt.Sprintf("table '%s' has exceeded the max size of %d", ft, maxSize)}
+		return found, &errs.InsufficientStorageErr{
+			Err:     fmt.Sprintf("table '%s' has exceeded the max size of %d", ft, maxSize),
+			Details: &errs.LimitDetails{Limit: maxSize, Current: tableSize},
+		}
 	case tableSize > warnSize:
 		stats.Incr("table-size-warning", ft.Tag())
 		return found, nil
@@ -133,9 +144,16 @@ func (s *tableSizer) refresh(ctx context.Context) error {
 		return errors.Wrap(err, "get configured table limits")
 	}
 	s.mut.Lock()
-	defer s.mut.Unlock()
 	s.tableSizes = sizes
 	s.configuredMaxTableSizes = configuredLimits
+	s.mut.Unlock()
+
+	// the sizes are still good if they can't be sampled, e.g. because the
+	// ctldb doesn't have the table_size_samples table yet
+	if err := s.recordSamples(ctx, sizes); err != nil {
+		errs.IncrDefault(stats.Tag{Name: "op", Value: "record-table-size-samples"})
+		events.Log("could not record table size samples: %{err}v", err)
+	}
 	return nil
 }
 
@@ -104,8 +104,7 @@ func (s *TestExecutiveService) ServeHTTP(w http.ResponseWriter, r *http.Request)
 			MaxSize:  100 * units.MEGABYTE,
 			WarnSize: 50 * units.MEGABYTE,
 		},
-		time.Second,
-		1000,
+		limits.RateLimit{Amount: 1000, Period: time.Second},
 	)
 	exec := &dbExecutive{DB: s.ctldb, Ctx: ctx, limiter: limiter}
 	ep := ExecutiveEndpoint{Exec: exec, HealthChecker: exec}
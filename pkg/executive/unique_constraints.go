@@ -0,0 +1,109 @@
+package executive
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+	"github.com/segmentio/ctlstore/pkg/schema"
+	"github.com/segmentio/ctlstore/pkg/sqlgen"
+)
+
+// uniqueConstraintsByName validates the field names of unique constraints,
+// as supplied to the executive.
+func uniqueConstraintsByName(uniqueConstraints [][]string) ([][]schema.FieldName, error) {
+	if len(uniqueConstraints) == 0 {
+		return nil, nil
+	}
+	res := make([][]schema.FieldName, len(uniqueConstraints))
+	for i, names := range uniqueConstraints {
+		for _, name := range names {
+			fn, err := schema.NewFieldName(name)
+			if err != nil {
+				return nil, &errs.BadRequestError{Err: err.Error()}
+			}
+			res[i] = append(res[i], fn)
+		}
+	}
+	return res, nil
+}
+
+// insertUniqueConstraints records the unique constraints of a new table in
+// the ctldb.
+func insertUniqueConstraints(ctx context.Context, tx *sql.Tx, tbl *sqlgen.MetaTable) error {
+	for i, fns := range tbl.UniqueConstraints {
+		for j, fn := range fns {
+			_, err := tx.ExecContext(ctx, "INSERT INTO unique_constraints "+
+				"(family_name, table_name, constraint_num, position, field_name) VALUES (?, ?, ?, ?, ?)",
+				tbl.FamilyName.Name, tbl.TableName.Name, i, j, fn.Name)
+			if err != nil {
+				return errors.Wrap(err, "insert unique constraint")
+			}
+		}
+	}
+	return nil
+}
+
+// readUniqueConstraints reads the unique constraints of a family's tables,
+// keyed by table name.
+func readUniqueConstraints(ctx context.Context, db *sql.DB, famName schema.FamilyName) (map[schema.TableName][][]schema.FieldName, error) {
+	rows, err := db.QueryContext(ctx, "SELECT table_name, constraint_num, field_name FROM unique_constraints "+
+		"WHERE family_name = ? ORDER BY table_name, constraint_num, position", famName.Name)
+	if err != nil {
+		return nil, errors.Wrap(err, "select unique constraints")
+	}
+	defer rows.Close()
+	res := map[schema.TableName][][]schema.FieldName{}
+	for rows.Next() {
+		var table, field string
+		var num int
+		if err := rows.Scan(&table, &num, &field); err != nil {
+			return nil, errors.Wrap(err, "scan unique constraint")
+		}
+		tblName, err := schema.NewTableName(table)
+		if err != nil {
+			return nil, err
+		}
+		fn, err := schema.NewFieldName(field)
+		if err != nil {
+			return nil, err
+		}
+		constraints := res[tblName]
+		for len(constraints) <= num {
+			constraints = append(constraints, nil)
+		}
+		constraints[num] = append(constraints[num], fn)
+		res[tblName] = constraints
+	}
+	return res, errors.Wrap(rows.Err(), "select unique constraints")
+}
+
+// checkUniqueConstraints returns a conflict error if an upsert of the table
+// would give another row the same values for the fields of one of its
+// unique constraints. It must be called in the mutation's transaction, so
+// that rows upserted earlier in the transaction are taken into account. On
+// MySQL, the rows it reads are locked, so that the check sees the latest
+// rows rather than the transaction's snapshot.
+func checkUniqueConstraints(ctx context.Context, tx *sql.Tx, tbl sqlgen.MetaTable, fieldNames []schema.FieldName, values []interface{}) error {
+	conflicts, err := tbl.UniqueConflictQueries(fieldNames, values)
+	if err != nil {
+		return err
+	}
+	for _, conflict := range conflicts {
+		var exists int
+		err := tx.QueryRowContext(ctx, conflict.Query.SQL, conflict.Query.Args...).Scan(&exists)
+		switch {
+		case err == sql.ErrNoRows:
+		case err != nil:
+			return errors.Wrap(err, "checking unique constraints")
+		default:
+			return &errs.ConflictError{Err: "Another row of " + schema.LDBTableName(tbl.FamilyName, tbl.TableName) +
+				" has the same values for the unique fields (" +
+				strings.Join(schema.StringifyFieldNames(conflict.Fields), ", ") + ")"}
+		}
+	}
+	return nil
+}
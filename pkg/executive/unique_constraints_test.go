@@ -0,0 +1,102 @@
+package executive
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+// testDBExecutiveUniqueConstraints is run from TestAllDBExecutive
+func testDBExecutiveUniqueConstraints(t *testing.T, dbType string) {
+	u := newDbExecTestUtil(t, dbType)
+	defer u.Close()
+
+	requireErrType := func(want interface{}, err error) {
+		t.Helper()
+		require.IsType(t, want, errors.Cause(err))
+	}
+
+	err := u.e.CreateTables([]schema.Table{{
+		Family:            "family1",
+		Name:              "invalid",
+		Fields:            [][]string{{"id", "integer"}, {"notes", "text"}},
+		KeyFields:         []string{"id"},
+		UniqueConstraints: [][]string{{"notes"}},
+	}})
+	requireErrType(&errs.BadRequestError{}, err)
+
+	err = u.e.CreateTables([]schema.Table{{
+		Family:            "family1",
+		Name:              "sites",
+		Fields:            [][]string{{"id", "integer"}, {"slug", "string"}},
+		KeyFields:         []string{"id"},
+		UniqueConstraints: [][]string{{"Slug"}},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`CREATE TABLE family1___sites ("id" INTEGER, "slug" VARCHAR(191), PRIMARY KEY("id"), UNIQUE("slug"));`,
+	}, queryDMLTable(t, u.db, 1))
+
+	tableSchema, err := u.e.TableSchema("family1", "sites")
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"slug"}}, tableSchema.UniqueConstraints)
+
+	cookie := byte(1)
+	mutate := func(requests ...ExecutiveMutationRequest) error {
+		t.Helper()
+		cookie++
+		_, err := u.e.Mutate("writer1", "", "family1", []byte{cookie}, nil, requests)
+		return err
+	}
+	upsert := func(id int, slug interface{}) ExecutiveMutationRequest {
+		return ExecutiveMutationRequest{TableName: "sites", Values: map[string]interface{}{"id": id, "slug": slug}}
+	}
+
+	require.NoError(t, mutate(upsert(1, "a")))
+	// the ledger gets an upsert on the primary key for the LDBs, rather than
+	// a REPLACE, which would delete a row that conflicts
+	require.Equal(t, []string{
+		`INSERT INTO family1___sites ("id","slug") VALUES(1,'a') ON CONFLICT("id") DO UPDATE SET "slug"=excluded."slug"`,
+	}, queryDMLTable(t, u.db, 1))
+	err = mutate(upsert(2, "a"))
+	requireErrType(&errs.ConflictError{}, err)
+	require.Contains(t, err.Error(), "unique fields (slug)")
+	// a row doesn't conflict with itself
+	require.NoError(t, mutate(upsert(1, "a")))
+	// rows upserted earlier in the mutation are taken into account
+	require.NoError(t, mutate(upsert(1, "b"), upsert(2, "a")))
+	requireErrType(&errs.ConflictError{}, mutate(upsert(3, "c"), upsert(4, "c")))
+	// NULLs don't conflict
+	require.NoError(t, mutate(upsert(3, nil), upsert(4, nil)))
+
+	var rows int
+	require.NoError(t, u.db.QueryRow("SELECT COUNT(*) FROM family1___sites").Scan(&rows))
+	require.Equal(t, 4, rows)
+
+	// constraints follow renamed fields
+	require.NoError(t, u.e.AlterField("family1", "sites", "slug", "handle", 0))
+	tableSchema, err = u.e.TableSchema("family1", "sites")
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"handle"}}, tableSchema.UniqueConstraints)
+	requireErrType(&errs.ConflictError{}, mutate(ExecutiveMutationRequest{
+		TableName: "sites",
+		Values:    map[string]interface{}{"id": 5, "handle": "a"},
+	}))
+	err = u.e.AlterField("family1", "sites", "handle", "", schema.FTText)
+	requireErrType(&errs.BadRequestError{}, err)
+
+	_, err = u.e.CloneTable(schema.FamilyTable{Family: "family1", Table: "sites"}, schema.FamilyTable{Family: "family1", Table: "sites_copy"}, 0, "")
+	require.NoError(t, err)
+	tableSchema, err = u.e.TableSchema("family1", "sites_copy")
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"handle"}}, tableSchema.UniqueConstraints)
+
+	require.NoError(t, u.e.DropTable(schema.FamilyTable{Family: "family1", Table: "sites"}))
+	var constraints int
+	require.NoError(t, u.db.QueryRow("SELECT COUNT(*) FROM unique_constraints WHERE table_name = 'sites'").Scan(&constraints))
+	require.Equal(t, 0, constraints)
+}
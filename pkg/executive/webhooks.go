@@ -0,0 +1,412 @@
+package executive
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/segmentio/events/v2"
+	"github.com/segmentio/stats/v4"
+
+	"github.com/segmentio/ctlstore/pkg/ctlcrypto"
+	"github.com/segmentio/ctlstore/pkg/errs"
+	"github.com/segmentio/ctlstore/pkg/schema"
+	"github.com/segmentio/ctlstore/pkg/utils"
+)
+
+const (
+	// WebhookSignatureHeader holds the hex encoded HMAC-SHA256 of a webhook
+	// delivery's body, keyed by the webhook's secret, prefixed by "sha256=".
+	WebhookSignatureHeader = "X-Ctlstore-Signature"
+	// WebhookIDHeader holds the ID of the webhook a delivery is for.
+	WebhookIDHeader = "X-Ctlstore-Webhook-Id"
+
+	defaultWebhookQueueSize       = 1000
+	defaultWebhookConcurrency     = 4
+	defaultWebhookRetries         = 3
+	defaultWebhookBackoff         = time.Second
+	defaultWebhookTimeout         = 10 * time.Second
+	defaultWebhookRefreshInterval = 10 * time.Second
+
+	maxWebhookURLLength    = 2048
+	maxWebhookSecretLength = 255
+)
+
+// The events which webhooks are notified of.
+const (
+	WebhookEventMutation     = "mutation"
+	WebhookEventCreateTable  = "create-table"
+	WebhookEventAddFields    = "add-fields"
+	WebhookEventAlterField   = "alter-field"
+	WebhookEventClearTable   = "clear-table"
+	WebhookEventDropTable    = "drop-table"
+	WebhookEventDeleteFamily = "delete-family"
+)
+
+// WebhooksConfig configures the delivery of webhook notifications. Zero
+// values use the defaults.
+type WebhooksConfig struct {
+	// QueueSize is how many deliveries can wait to be sent. Once the queue
+	// is full, notifications are dropped. Defaults to 1000.
+	QueueSize int
+	// Concurrency is how many deliveries are sent at once. Defaults to 4.
+	Concurrency int
+	// Retries is how many times a failed delivery is retried before it's
+	// given up on. Defaults to 3.
+	Retries int
+	// Backoff is the wait before the first retry of a delivery, which is
+	// doubled before each of the others. Defaults to 1s.
+	Backoff time.Duration
+	// Timeout bounds each attempt to deliver. Defaults to 10s.
+	Timeout time.Duration
+	// RefreshInterval is how often the webhooks are reloaded from the
+	// ctldb, which is how webhooks registered with other executive
+	// instances are picked up. Defaults to 10s.
+	RefreshInterval time.Duration
+}
+
+// Webhook is a URL which is notified of the changes made to a family. It
+// doesn't include the webhook's secret.
+type Webhook struct {
+	ID        string    `json:"id"`
+	Family    string    `json:"family"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// WebhookNotification is the JSON body delivered to a webhook once a change
+// to its family has been committed. Notifications may be delivered out of
+// order, and LedgerSeq orders them.
+type WebhookNotification struct {
+	// ID is unique to the notification, and is the same for each retry of
+	// its delivery
+	ID     string   `json:"id"`
+	Event  string   `json:"event"`
+	Family string   `json:"family"`
+	Tables []string `json:"tables,omitempty"`
+	// Writer and Mutations are only set for mutations. Mutations is how
+	// many of the writer's mutations were applied to the family.
+	Writer    string `json:"writer,omitempty"`
+	Mutations int    `json:"mutations,omitempty"`
+	// LedgerSeq is the sequence of the last ledger entry of the change
+	LedgerSeq int64     `json:"ledgerSeq,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type webhook struct {
+	Webhook
+	secret string
+}
+
+type webhookDelivery struct {
+	hook webhook
+	body []byte
+}
+
+// webhookNotifier delivers notifications to the webhooks of the families
+// they're about. Notifying never blocks the request which made the change:
+// deliveries are queued, and sent by a pool of goroutines which retry them
+// with backoff. Deliveries which still fail are counted by the
+// webhooks.dead-letters metric and dropped.
+//
+// The webhooks are cached, so that notifying doesn't query the ctldb.
+type webhookNotifier struct {
+	db     *sql.DB
+	config WebhooksConfig
+	client *http.Client
+	queue  chan webhookDelivery
+
+	mu    sync.RWMutex
+	hooks map[string][]webhook // keyed by family name
+}
+
+func newWebhookNotifier(db *sql.DB, config WebhooksConfig) *webhookNotifier {
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaultWebhookQueueSize
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = defaultWebhookConcurrency
+	}
+	if config.Retries <= 0 {
+		config.Retries = defaultWebhookRetries
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = defaultWebhookBackoff
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultWebhookTimeout
+	}
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = defaultWebhookRefreshInterval
+	}
+	return &webhookNotifier{
+		db:     db,
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		queue:  make(chan webhookDelivery, config.QueueSize),
+		hooks:  map[string][]webhook{},
+	}
+}
+
+// start loads the webhooks, and then keeps them up to date and delivers
+// notifications until the context is cancelled.
+func (n *webhookNotifier) start(ctx context.Context) error {
+	if err := n.refresh(ctx); err != nil {
+		return errors.Wrap(err, "load webhooks")
+	}
+	go utils.CtxLoop(ctx, n.config.RefreshInterval, func() {
+		if err := n.refresh(ctx); err != nil {
+			errs.IncrDefault(stats.T("op", "refresh-webhooks"))
+			events.Log("could not refresh webhooks: %{err}v", err)
+		}
+	})
+	for i := 0; i < n.config.Concurrency; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case d := <-n.queue:
+					stats.Set("webhooks.queue-depth", len(n.queue))
+					n.deliver(ctx, d)
+				}
+			}
+		}()
+	}
+	return nil
+}
+
+// refresh reloads the webhooks from the ctldb.
+func (n *webhookNotifier) refresh(ctx context.Context) error {
+	rows, err := n.db.QueryContext(ctx, "SELECT id, family_name, url, secret, created_at FROM webhooks")
+	if err != nil {
+		return errors.Wrap(err, "select webhooks")
+	}
+	defer rows.Close()
+	hooks := map[string][]webhook{}
+	for rows.Next() {
+		var hook webhook
+		var createdAt int64
+		if err := rows.Scan(&hook.ID, &hook.Family, &hook.URL, &hook.secret, &createdAt); err != nil {
+			return errors.Wrap(err, "scan webhook")
+		}
+		hook.CreatedAt = time.Unix(createdAt, 0)
+		hooks[hook.Family] = append(hooks[hook.Family], hook)
+	}
+	if err := rows.Err(); err != nil {
+		return errors.Wrap(err, "select webhooks")
+	}
+	n.mu.Lock()
+	n.hooks = hooks
+	n.mu.Unlock()
+	return nil
+}
+
+// notify queues a delivery of the notification to each of its family's
+// webhooks. It does nothing if n is nil.
+func (n *webhookNotifier) notify(notification WebhookNotification) {
+	if n == nil {
+		return
+	}
+	n.mu.RLock()
+	hooks := n.hooks[notification.Family]
+	n.mu.RUnlock()
+	if len(hooks) == 0 {
+		return
+	}
+	notification.ID = uuid.New().String()
+	if notification.Timestamp.IsZero() {
+		notification.Timestamp = time.Now()
+	}
+	body, err := json.Marshal(notification)
+	if err != nil {
+		events.Log("could not marshal webhook notification: %{err}v", err)
+		return
+	}
+	for _, hook := range hooks {
+		select {
+		case n.queue <- webhookDelivery{hook: hook, body: body}:
+		default:
+			stats.Incr("webhooks.deliveries", stats.T("family", hook.Family), stats.T("result", "dropped"))
+		}
+	}
+}
+
+// deliver sends the delivery, retrying with backoff until it succeeds or
+// the retries are used up.
+func (n *webhookNotifier) deliver(ctx context.Context, d webhookDelivery) {
+	backoff := n.config.Backoff
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = n.send(ctx, d); err == nil {
+			stats.Incr("webhooks.deliveries", stats.T("family", d.hook.Family), stats.T("result", "delivered"))
+			return
+		}
+		if attempt == n.config.Retries {
+			break
+		}
+		stats.Incr("webhooks.retries", stats.T("family", d.hook.Family))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	events.Log("Giving up on delivering to webhook %{id}s of family %{family}s: %{error}v",
+		d.hook.ID, d.hook.Family, err)
+	stats.Incr("webhooks.dead-letters", stats.T("family", d.hook.Family))
+}
+
+func (n *webhookNotifier) send(ctx context.Context, d webhookDelivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.hook.URL, bytes.NewReader(d.body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookIDHeader, d.hook.ID)
+	req.Header.Set(WebhookSignatureHeader, "sha256="+signWebhookBody(d.hook.secret, d.body))
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("webhook responded %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// signWebhookBody returns the hex encoded HMAC-SHA256 of the body, using
+// the configured crypto provider.
+func signWebhookBody(secret string, body []byte) string {
+	mac := ctlcrypto.Default().NewMAC([]byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// RegisterWebhook registers a URL to be notified of the changes made to the
+// family, with a secret which signs its notifications.
+func (e *dbExecutive) RegisterWebhook(familyName string, hookURL string, secret string) (Webhook, error) {
+	ctx, cancel := e.ctx()
+	defer cancel()
+
+	famName, err := schema.NewFamilyName(familyName)
+	if err != nil {
+		return Webhook{}, &errs.BadRequestError{Err: err.Error()}
+	}
+	if err := validateWebhookURL(hookURL); err != nil {
+		return Webhook{}, err
+	}
+	if secret == "" || len(secret) > maxWebhookSecretLength {
+		return Webhook{}, errs.BadRequest("secret must be between 1 and %d characters", maxWebhookSecretLength)
+	}
+	_, ok, err := e.fetchFamilyByName(famName)
+	if err != nil {
+		return Webhook{}, err
+	}
+	if !ok {
+		return Webhook{}, &errs.NotFoundError{Err: "Family not found"}
+	}
+
+	hook := Webhook{
+		ID:        uuid.New().String(),
+		Family:    famName.Name,
+		URL:       hookURL,
+		CreatedAt: time.Now().Truncate(time.Second),
+	}
+	_, err = e.DB.ExecContext(ctx, "INSERT INTO webhooks (id, family_name, url, secret, created_at) VALUES (?, ?, ?, ?, ?)",
+		hook.ID, hook.Family, hook.URL, secret, hook.CreatedAt.Unix())
+	if err != nil {
+		return Webhook{}, errors.Wrap(err, "insert webhook")
+	}
+	e.refreshWebhooks(ctx)
+	events.Log("Registered webhook %{id}s for family %{family}s", hook.ID, hook.Family)
+	return hook, nil
+}
+
+// ReadWebhooks returns the webhooks of a family, ordered by URL.
+func (e *dbExecutive) ReadWebhooks(familyName string) ([]Webhook, error) {
+	ctx, cancel := e.ctx()
+	defer cancel()
+
+	famName, err := schema.NewFamilyName(familyName)
+	if err != nil {
+		return nil, &errs.BadRequestError{Err: err.Error()}
+	}
+	rows, err := e.readDB().QueryContext(ctx,
+		"SELECT id, url, created_at FROM webhooks WHERE family_name=? ORDER BY url, id", famName.Name)
+	if err != nil {
+		return nil, errors.Wrap(err, "select webhooks")
+	}
+	defer rows.Close()
+	res := []Webhook{}
+	for rows.Next() {
+		hook := Webhook{Family: famName.Name}
+		var createdAt int64
+		if err := rows.Scan(&hook.ID, &hook.URL, &createdAt); err != nil {
+			return nil, errors.Wrap(err, "scan webhook")
+		}
+		hook.CreatedAt = time.Unix(createdAt, 0)
+		res = append(res, hook)
+	}
+	return res, rows.Err()
+}
+
+// DeleteWebhook stops a webhook from being notified. Deliveries which are
+// already queued are still sent.
+func (e *dbExecutive) DeleteWebhook(familyName string, id string) error {
+	ctx, cancel := e.ctx()
+	defer cancel()
+
+	famName, err := schema.NewFamilyName(familyName)
+	if err != nil {
+		return &errs.BadRequestError{Err: err.Error()}
+	}
+	res, err := e.DB.ExecContext(ctx, "DELETE FROM webhooks WHERE family_name=? AND id=?", famName.Name, id)
+	if err != nil {
+		return errors.Wrap(err, "delete webhook")
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "rows affected")
+	}
+	if rows == 0 {
+		return &errs.NotFoundError{Err: "Webhook not found"}
+	}
+	e.refreshWebhooks(ctx)
+	return nil
+}
+
+// refreshWebhooks reloads the webhooks after they've been changed, so that
+// this executive instance doesn't wait for the next refresh to use them.
+func (e *dbExecutive) refreshWebhooks(ctx context.Context) {
+	if e.webhooks == nil {
+		return
+	}
+	if err := e.webhooks.refresh(ctx); err != nil {
+		events.Log("could not refresh webhooks: %{err}v", err)
+	}
+}
+
+func validateWebhookURL(hookURL string) error {
+	if len(hookURL) > maxWebhookURLLength {
+		return errs.BadRequest("url must be at most %d characters", maxWebhookURLLength)
+	}
+	u, err := url.Parse(hookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errs.BadRequest("url must be an absolute http or https URL")
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package executive
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+// testDBExecutiveWebhooks is run from TestAllDBExecutive
+func testDBExecutiveWebhooks(t *testing.T, dbType string) {
+	u := newDbExecTestUtil(t, dbType)
+	defer u.Close()
+	ctx, cancel := context.WithCancel(u.ctx)
+	defer cancel()
+
+	type delivery struct {
+		header       http.Header
+		notification WebhookNotification
+		body         []byte
+	}
+	deliveries := make(chan delivery, 10)
+	var failures int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the first delivery fails, and is retried
+		if atomic.AddInt32(&failures, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		d := delivery{header: r.Header, body: body}
+		require.NoError(t, json.Unmarshal(body, &d.notification))
+		deliveries <- d
+	}))
+	defer srv.Close()
+	next := func() delivery {
+		select {
+		case d := <-deliveries:
+			return d
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timed out waiting for a webhook delivery")
+			return delivery{}
+		}
+	}
+
+	u.e.webhooks = newWebhookNotifier(u.db, WebhooksConfig{Backoff: time.Millisecond, RefreshInterval: time.Hour})
+	require.NoError(t, u.e.webhooks.start(ctx))
+
+	_, err := u.e.RegisterWebhook("family1", "ftp://example.com", "secret")
+	require.IsType(t, &errs.BadRequestError{}, errors.Cause(err))
+	_, err = u.e.RegisterWebhook("family1", srv.URL, "")
+	require.IsType(t, &errs.BadRequestError{}, errors.Cause(err))
+	_, err = u.e.RegisterWebhook("missing", srv.URL, "secret")
+	require.IsType(t, &errs.NotFoundError{}, errors.Cause(err))
+
+	hook, err := u.e.RegisterWebhook("family1", srv.URL, "secret")
+	require.NoError(t, err)
+	require.Equal(t, "family1", hook.Family)
+	hooks, err := u.e.ReadWebhooks("family1")
+	require.NoError(t, err)
+	require.Equal(t, []Webhook{hook}, hooks)
+
+	// mutations are notified once they're committed, with a signature
+	require.NoError(t, u.e.RegisterWriter("webhook-writer", "webhook-secret"))
+	_, err = u.e.Mutate("webhook-writer", "webhook-secret", "family1", []byte{1}, nil, []ExecutiveMutationRequest{
+		{TableName: "table10", Values: map[string]interface{}{"field1": 1, "field2": "foo", "field3": 1.5}},
+		{TableName: "table10", Values: map[string]interface{}{"field1": 2, "field2": "bar", "field3": 2.5}},
+	})
+	require.NoError(t, err)
+	d := next()
+	require.Equal(t, hook.ID, d.header.Get(WebhookIDHeader))
+	require.Equal(t, "sha256="+signWebhookBody("secret", d.body), d.header.Get(WebhookSignatureHeader))
+	require.Equal(t, WebhookEventMutation, d.notification.Event)
+	require.Equal(t, "family1", d.notification.Family)
+	require.Equal(t, []string{"table10"}, d.notification.Tables)
+	require.Equal(t, "webhook-writer", d.notification.Writer)
+	require.Equal(t, 2, d.notification.Mutations)
+	require.NotZero(t, d.notification.LedgerSeq)
+	require.NotEmpty(t, d.notification.ID)
+	require.EqualValues(t, 2, atomic.LoadInt32(&failures))
+
+	// as are schema changes
+	require.NoError(t, u.e.CreateTable("family1", "webhook_table",
+		[]string{"id"}, []schema.FieldType{schema.FTInteger}, []string{"id"}))
+	d = next()
+	require.Equal(t, WebhookEventCreateTable, d.notification.Event)
+	require.Equal(t, []string{"webhook_table"}, d.notification.Tables)
+	require.NoError(t, u.e.AddFields("family1", "webhook_table",
+		[]string{"name"}, []schema.FieldType{schema.FTString}, nil))
+	d = next()
+	require.Equal(t, WebhookEventAddFields, d.notification.Event)
+	require.Greater(t, d.notification.LedgerSeq, int64(0))
+
+	// once deleted, the webhook isn't notified
+	err = u.e.DeleteWebhook("family1", "missing")
+	require.IsType(t, &errs.NotFoundError{}, errors.Cause(err))
+	require.NoError(t, u.e.DeleteWebhook("family1", hook.ID))
+	hooks, err = u.e.ReadWebhooks("family1")
+	require.NoError(t, err)
+	require.Empty(t, hooks)
+	require.NoError(t, u.e.ClearTable(schema.FamilyTable{Family: "family1", Table: "webhook_table"}))
+	select {
+	case d := <-deliveries:
+		require.Failf(t, "unexpected delivery", "%+v", d.notification)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWebhookDeadLetters(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	n := newWebhookNotifier(nil, WebhooksConfig{Retries: 2, Backoff: time.Millisecond})
+	n.deliver(context.Background(), webhookDelivery{
+		hook: webhook{Webhook: Webhook{ID: "hook", Family: "family", URL: srv.URL}, secret: "secret"},
+		body: []byte(`{}`),
+	})
+	require.EqualValues(t, 3, atomic.LoadInt32(&attempts))
+}
+
+func TestSignWebhookBody(t *testing.T) {
+	// subscribers verify the signature as an HMAC-SHA256 whichever crypto
+	// provider is configured
+	require.Equal(t, "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8",
+		signWebhookBody("key", []byte("The quick brown fox jumps over the lazy dog")))
+}
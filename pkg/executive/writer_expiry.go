@@ -0,0 +1,125 @@
+package executive
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/segmentio/events/v2"
+	"github.com/segmentio/stats/v4"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+	"github.com/segmentio/ctlstore/pkg/schema"
+	"github.com/segmentio/ctlstore/pkg/utils"
+)
+
+// defaultWriterExpiryInterval is how often idle writers are looked for when
+// WriterExpiryConfig.Interval isn't set.
+const defaultWriterExpiryInterval = time.Hour
+
+// WriterExpiryConfig configures the background job which disables writers
+// that have been idle for too long, so that forgotten credentials can't be
+// used to mutate. Disabled writers aren't deleted, and can be re-enabled
+// with POST /writers/{writerName}/enable.
+type WriterExpiryConfig struct {
+	// IdleAfter is how long a writer may go without mutating before it's
+	// disabled. Writers which have never mutated are idle from when they
+	// were registered. Zero disables the job.
+	IdleAfter time.Duration
+	// Interval is the time between looking for idle writers. Defaults to an
+	// hour.
+	Interval time.Duration
+}
+
+// writerExpirer disables idle writers
+type writerExpirer struct {
+	db     *sql.DB
+	config WriterExpiryConfig
+	now    func() time.Time
+}
+
+func newWriterExpirer(db *sql.DB, config WriterExpiryConfig) *writerExpirer {
+	if config.Interval <= 0 {
+		config.Interval = defaultWriterExpiryInterval
+	}
+	return &writerExpirer{db: db, config: config, now: time.Now}
+}
+
+// start disables idle writers every interval, if expiry is enabled.
+func (x *writerExpirer) start(ctx context.Context) {
+	if x.config.IdleAfter <= 0 {
+		events.Log("Writer expiry is disabled")
+		return
+	}
+	events.Log("starting writer expiry of writers idle for %v", x.config.IdleAfter)
+	go utils.CtxFireLoop(ctx, x.config.Interval, func() {
+		if _, err := x.expire(ctx); err != nil && !errs.IsCanceled(err) {
+			errs.IncrDefault(stats.Tag{Name: "op", Value: "expire-writers"})
+			events.Log("could not expire idle writers: %{err}v", err)
+		}
+	})
+}
+
+// expire disables the writers which are idle, and returns their names.
+func (x *writerExpirer) expire(ctx context.Context) ([]string, error) {
+	now := x.now()
+	ms := mutatorStore{DB: x.db, Ctx: ctx, TableName: mutatorsTableName}
+	disabled, err := ms.DisableIdle(now.Add(-x.config.IdleAfter), now)
+	for _, name := range disabled {
+		events.Log("Disabled writer %{writer}s after being idle for over %v", name, x.config.IdleAfter)
+	}
+	stats.Add("writers-expired", len(disabled))
+	return disabled, err
+}
+
+// ReadWriterActivity returns how recently a writer has mutated.
+func (e *dbExecutive) ReadWriterActivity(writerName string) (WriterActivity, error) {
+	ctx, cancel := e.ctx()
+	defer cancel()
+
+	wn, err := schema.NewWriterName(writerName)
+	if err != nil {
+		return WriterActivity{}, &errs.BadRequestError{Err: err.Error()}
+	}
+	ms := mutatorStore{DB: e.readDB(), Ctx: ctx, TableName: mutatorsTableName}
+	activity, found, err := ms.Activity(wn)
+	if err != nil {
+		return WriterActivity{}, err
+	}
+	if !found {
+		return WriterActivity{}, &errs.NotFoundError{Err: "Writer not found"}
+	}
+	var lastActive time.Time
+	for _, t := range []*time.Time{activity.CreatedAt, activity.LastMutationAt, activity.EnabledAt} {
+		if t != nil && t.After(lastActive) {
+			lastActive = *t
+		}
+	}
+	if !lastActive.IsZero() {
+		activity.IdleSeconds = int64(time.Since(lastActive) / time.Second)
+	}
+	return activity, nil
+}
+
+// EnableWriter re-enables a writer which was disabled for being idle. Its
+// idle period restarts, so it's disabled again unless it mutates within it.
+func (e *dbExecutive) EnableWriter(writerName string) error {
+	ctx, cancel := e.ctx()
+	defer cancel()
+
+	wn, err := schema.NewWriterName(writerName)
+	if err != nil {
+		return &errs.BadRequestError{Err: err.Error()}
+	}
+	ms := mutatorStore{DB: e.DB, Ctx: ctx, TableName: mutatorsTableName}
+	found, err := ms.Enable(wn, time.Now())
+	if err != nil {
+		return errors.Wrap(err, "enable writer")
+	}
+	if !found {
+		return &errs.NotFoundError{Err: "Writer not found"}
+	}
+	events.Log("Enabled writer %{writer}s", wn.Name)
+	return nil
+}
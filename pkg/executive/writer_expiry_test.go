@@ -0,0 +1,82 @@
+package executive
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+)
+
+// testDBExecutiveWriterExpiry is run from TestAllDBExecutive
+func testDBExecutiveWriterExpiry(t *testing.T, dbType string) {
+	u := newDbExecTestUtil(t, dbType)
+	defer u.Close()
+
+	now := time.Now()
+	expirer := newWriterExpirer(u.db, WriterExpiryConfig{IdleAfter: 24 * time.Hour})
+	expirer.now = func() time.Time { return now }
+
+	require.NoError(t, u.e.RegisterWriter("active", "secret"))
+	require.NoError(t, u.e.RegisterWriter("idle", "secret"))
+	_, err := u.db.Exec("UPDATE mutators SET created_at=? WHERE writer IN ('active', 'idle')", now.Add(-48*time.Hour).Unix())
+	require.NoError(t, err)
+	_, err = u.e.Mutate("active", "secret", "family1", []byte{1}, nil, []ExecutiveMutationRequest{
+		{TableName: "table10", Values: map[string]interface{}{"field1": 2, "field2": "bar", "field3": 1.5}},
+	})
+	require.NoError(t, err)
+
+	activity, err := u.e.ReadWriterActivity("idle")
+	require.NoError(t, err)
+	require.InDelta(t, (48 * time.Hour).Seconds(), activity.IdleSeconds, 5)
+	require.Nil(t, activity.DisabledAt)
+	_, err = u.e.ReadWriterActivity("missing")
+	require.IsType(t, &errs.NotFoundError{}, errors.Cause(err))
+
+	// writer1 predates the recording of registrations, so its idleness is
+	// unknown and it's left alone
+	disabled, err := expirer.expire(u.ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"idle"}, disabled)
+	disabled, err = expirer.expire(u.ctx)
+	require.NoError(t, err)
+	require.Empty(t, disabled)
+
+	activity, err = u.e.ReadWriterActivity("idle")
+	require.NoError(t, err)
+	require.NotNil(t, activity.DisabledAt)
+	writers, err := u.e.ReadWriters()
+	require.NoError(t, err)
+	for _, w := range writers {
+		require.Equal(t, w.Name == "idle", w.DisabledAt != nil, w.Name)
+	}
+
+	// disabled writers can't read their cookie or mutate
+	_, err = u.e.GetWriterCookie("idle", "secret")
+	require.Equal(t, ErrWriterDisabled, errors.Cause(err))
+	_, err = u.e.Mutate("idle", "secret", "family1", []byte{1}, nil, []ExecutiveMutationRequest{
+		{TableName: "table10", Values: map[string]interface{}{"field1": 3, "field2": "bar", "field3": 1.5}},
+	})
+	require.Equal(t, ErrWriterDisabled, errors.Cause(err))
+	// and registering again doesn't enable them
+	require.NoError(t, u.e.RegisterWriter("idle", "secret"))
+	_, err = u.e.GetWriterCookie("idle", "secret")
+	require.Equal(t, ErrWriterDisabled, errors.Cause(err))
+
+	require.NoError(t, u.e.EnableWriter("idle"))
+	_, err = u.e.GetWriterCookie("idle", "secret")
+	require.NoError(t, err)
+	err = u.e.EnableWriter("missing")
+	require.IsType(t, &errs.NotFoundError{}, errors.Cause(err))
+
+	// enabling restarts the idle period
+	disabled, err = expirer.expire(u.ctx)
+	require.NoError(t, err)
+	require.Empty(t, disabled)
+	now = now.Add(25 * time.Hour)
+	disabled, err = expirer.expire(u.ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"active", "idle"}, disabled)
+}
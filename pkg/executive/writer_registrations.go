@@ -0,0 +1,306 @@
+package executive
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/segmentio/events/v2"
+	"github.com/segmentio/stats/v4"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+	"github.com/segmentio/ctlstore/pkg/limits"
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+// The statuses of a writer registration, which are also the actions of its
+// audit records along with WriterRegistrationRequested.
+const (
+	WriterRegistrationPending  = "pending"
+	WriterRegistrationApproved = "approved"
+	WriterRegistrationRejected = "rejected"
+
+	WriterRegistrationRequested = "requested"
+)
+
+const maxWriterRegistrationText = 1024
+
+// ErrWriterApprovalRequired is returned when registering a writer directly
+// while writers must be requested and approved instead.
+var ErrWriterApprovalRequired = errors.New("Writers must be requested with POST /writers/{writerName}/request and approved by an admin")
+
+// WriterRegistration is a request to register a writer. The writer can't
+// mutate until an admin approves it, which registers the writer with the
+// secret it was requested with.
+type WriterRegistration struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	// Description says who the writer is for and what it writes
+	Description  string     `json:"description,omitempty"`
+	RequestedAt  time.Time  `json:"requestedAt"`
+	ReviewedAt   *time.Time `json:"reviewedAt,omitempty"`
+	Reviewer     string     `json:"reviewer,omitempty"`
+	ReviewReason string     `json:"reviewReason,omitempty"`
+}
+
+// WriterReview approves or rejects a writer registration.
+type WriterReview struct {
+	// Reviewer identifies the admin making the review. It's the name of the
+	// admin token the review was made with, rather than anything the
+	// request says.
+	Reviewer string `json:"-"`
+	Reason   string `json:"reason"`
+}
+
+// WriterRegistrationEvent is an audit record of a writer registration being
+// requested, approved or rejected.
+type WriterRegistrationEvent struct {
+	Writer   string    `json:"writer"`
+	Action   string    `json:"action"`
+	Actor    string    `json:"actor,omitempty"`
+	Reason   string    `json:"reason,omitempty"`
+	SourceIP string    `json:"sourceIP,omitempty"`
+	At       time.Time `json:"at"`
+}
+
+// RequestWriterRegistration creates a pending registration of the writer,
+// which replaces a rejected one. The secret is hashed as it is when the
+// writer is registered.
+func (e *dbExecutive) RequestWriterRegistration(writerName string, writerSecret string, description string) (WriterRegistration, error) {
+	ctx, cancel := e.ctx()
+	defer cancel()
+
+	wn, err := schema.NewWriterName(writerName)
+	if err != nil {
+		return WriterRegistration{}, &errs.BadRequestError{Err: err.Error()}
+	}
+	if len(writerSecret) < limits.LimitWriterSecretMinLength {
+		return WriterRegistration{}, errs.BadRequest("Secret should be at least %d characters", limits.LimitWriterSecretMinLength)
+	}
+	if len(writerSecret) > limits.LimitWriterSecretMaxLength {
+		return WriterRegistration{}, errs.BadRequest("Secret can be at most %d characters", limits.LimitWriterSecretMaxLength)
+	}
+	if len(description) > maxWriterRegistrationText {
+		return WriterRegistration{}, errs.BadRequest("Description can be at most %d characters", maxWriterRegistrationText)
+	}
+
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return WriterRegistration{}, errors.Wrap(err, "start tx")
+	}
+	defer tx.Rollback()
+
+	ms := mutatorStore{DB: tx, Ctx: ctx, TableName: mutatorsTableName}
+	exists, err := ms.Exists(wn)
+	if err != nil {
+		return WriterRegistration{}, errors.Wrap(err, "check writer exists")
+	}
+	if exists {
+		return WriterRegistration{}, &errs.ConflictError{Err: "Writer already exists"}
+	}
+	reg, found, err := fetchWriterRegistration(ctx, tx, wn.Name)
+	if err != nil {
+		return WriterRegistration{}, err
+	}
+	if found && reg.Status == WriterRegistrationPending {
+		return WriterRegistration{}, &errs.ConflictError{Err: "Writer registration is already pending"}
+	}
+
+	reg = WriterRegistration{
+		Name:        wn.Name,
+		Status:      WriterRegistrationPending,
+		Description: description,
+		RequestedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	_, err = tx.ExecContext(ctx, "DELETE FROM writer_registrations WHERE writer_name=?", wn.Name)
+	if err != nil {
+		return WriterRegistration{}, errors.Wrap(err, "delete from writer_registrations")
+	}
+	_, err = tx.ExecContext(ctx, "INSERT INTO writer_registrations "+
+		"(writer_name, secret, description, status, requested_at) VALUES (?, ?, ?, ?, ?)",
+		wn.Name, hashMutatorSecret(writerSecret), description, reg.Status, reg.RequestedAt.Unix())
+	if err != nil {
+		if errorIsRowConflict(err) {
+			return WriterRegistration{}, &errs.ConflictError{Err: "Writer registration is already pending"}
+		}
+		return WriterRegistration{}, errors.Wrap(err, "insert into writer_registrations")
+	}
+	if err := e.auditWriterRegistration(ctx, tx, wn.Name, WriterRegistrationRequested, WriterReview{}); err != nil {
+		return WriterRegistration{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return WriterRegistration{}, errors.Wrap(err, "commit tx")
+	}
+	events.Log("Writer %{writer}s was requested", wn.Name)
+	stats.Incr("writer-registrations", stats.T("action", WriterRegistrationRequested))
+	return reg, nil
+}
+
+// ReadWriterRegistrations returns the writer registrations with the status,
+// or all of them if it's empty, ordered by when they were requested.
+func (e *dbExecutive) ReadWriterRegistrations(status string) ([]WriterRegistration, error) {
+	ctx, cancel := e.ctx()
+	defer cancel()
+
+	switch status {
+	case "", WriterRegistrationPending, WriterRegistrationApproved, WriterRegistrationRejected:
+	default:
+		return nil, errs.BadRequest("Invalid writer registration status '%s'", status)
+	}
+	qs := "SELECT " + writerRegistrationColumns + " FROM writer_registrations"
+	var args []interface{}
+	if status != "" {
+		qs += " WHERE status=?"
+		args = append(args, status)
+	}
+	rows, err := e.readDB().QueryContext(ctx, qs+" ORDER BY requested_at, writer_name", args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "select writer registrations")
+	}
+	defer rows.Close()
+	res := []WriterRegistration{}
+	for rows.Next() {
+		reg, err := scanWriterRegistration(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, reg)
+	}
+	return res, rows.Err()
+}
+
+// ApproveWriterRegistration registers the writer of a pending registration
+// with the secret it was requested with.
+func (e *dbExecutive) ApproveWriterRegistration(writerName string, review WriterReview) error {
+	return e.reviewWriterRegistration(writerName, WriterRegistrationApproved, review)
+}
+
+// RejectWriterRegistration rejects a pending registration. The writer may
+// be requested again.
+func (e *dbExecutive) RejectWriterRegistration(writerName string, review WriterReview) error {
+	return e.reviewWriterRegistration(writerName, WriterRegistrationRejected, review)
+}
+
+func (e *dbExecutive) reviewWriterRegistration(writerName string, status string, review WriterReview) error {
+	ctx, cancel := e.ctx()
+	defer cancel()
+
+	wn, err := schema.NewWriterName(writerName)
+	if err != nil {
+		return &errs.BadRequestError{Err: err.Error()}
+	}
+	if len(review.Reviewer) > 191 || len(review.Reason) > maxWriterRegistrationText {
+		return errs.BadRequest("Reviewer can be at most 191 characters, and reason at most %d", maxWriterRegistrationText)
+	}
+
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "start tx")
+	}
+	defer tx.Rollback()
+
+	reg, found, err := fetchWriterRegistration(ctx, tx, wn.Name)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return &errs.NotFoundError{Err: "Writer registration not found"}
+	}
+	if reg.Status != WriterRegistrationPending {
+		return &errs.ConflictError{Err: "Writer registration was already " + reg.Status}
+	}
+	res, err := tx.ExecContext(ctx, "UPDATE writer_registrations "+
+		"SET status=?, reviewed_at=?, reviewer=?, review_reason=? WHERE writer_name=? AND status=?",
+		status, time.Now().Unix(), review.Reviewer, review.Reason, wn.Name, WriterRegistrationPending)
+	if err != nil {
+		return errors.Wrap(err, "update writer_registrations")
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return errors.Wrap(err, "rows affected")
+	} else if n == 0 {
+		return &errs.ConflictError{Err: "Writer registration was reviewed concurrently"}
+	}
+	if status == WriterRegistrationApproved {
+		var secret string
+		err := tx.QueryRowContext(ctx, "SELECT secret FROM writer_registrations WHERE writer_name=?", wn.Name).Scan(&secret)
+		if err != nil {
+			return errors.Wrap(err, "select writer registration secret")
+		}
+		ms := mutatorStore{DB: tx, Ctx: ctx, TableName: mutatorsTableName}
+		switch err := ms.registerHashed(wn, secret); err {
+		case nil:
+		case ErrWriterAlreadyExists:
+			return &errs.ConflictError{Err: "Writer was registered since it was requested"}
+		default:
+			return errors.Wrap(err, "register writer")
+		}
+	}
+	if err := e.auditWriterRegistration(ctx, tx, wn.Name, status, review); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "commit tx")
+	}
+	events.Log("Writer %{writer}s was %{status}s by %{reviewer}q", wn.Name, status, review.Reviewer)
+	stats.Incr("writer-registrations", stats.T("action", status))
+	return nil
+}
+
+// ReadWriterRegistrationAudit returns the audit records of the writer's
+// registrations, oldest first.
+func (e *dbExecutive) ReadWriterRegistrationAudit(writerName string) ([]WriterRegistrationEvent, error) {
+	ctx, cancel := e.ctx()
+	defer cancel()
+
+	rows, err := e.readDB().QueryContext(ctx, "SELECT writer_name, action, actor, reason, source_ip, created_at "+
+		"FROM writer_registration_audit WHERE writer_name=? ORDER BY id", writerName)
+	if err != nil {
+		return nil, errors.Wrap(err, "select writer registration audit")
+	}
+	defer rows.Close()
+	res := []WriterRegistrationEvent{}
+	for rows.Next() {
+		var event WriterRegistrationEvent
+		var createdAt int64
+		if err := rows.Scan(&event.Writer, &event.Action, &event.Actor, &event.Reason, &event.SourceIP, &createdAt); err != nil {
+			return nil, errors.Wrap(err, "scan writer registration audit")
+		}
+		event.At = time.Unix(createdAt, 0).UTC()
+		res = append(res, event)
+	}
+	return res, rows.Err()
+}
+
+func (e *dbExecutive) auditWriterRegistration(ctx context.Context, tx *sql.Tx, writerName string, action string, review WriterReview) error {
+	_, err := tx.ExecContext(ctx, "INSERT INTO writer_registration_audit "+
+		"(writer_name, action, actor, reason, source_ip, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+		writerName, action, review.Reviewer, review.Reason, e.SourceIP, time.Now().Unix())
+	return errors.Wrap(err, "insert into writer_registration_audit")
+}
+
+const writerRegistrationColumns = "writer_name, status, description, requested_at, reviewed_at, reviewer, review_reason"
+
+func fetchWriterRegistration(ctx context.Context, db SQLDBClient, writerName string) (WriterRegistration, bool, error) {
+	row := db.QueryRowContext(ctx, "SELECT "+writerRegistrationColumns+" FROM writer_registrations WHERE writer_name=?", writerName)
+	reg, err := scanWriterRegistration(row)
+	switch {
+	case errors.Cause(err) == sql.ErrNoRows:
+		return WriterRegistration{}, false, nil
+	case err != nil:
+		return WriterRegistration{}, false, err
+	}
+	return reg, true, nil
+}
+
+func scanWriterRegistration(row interface{ Scan(...interface{}) error }) (WriterRegistration, error) {
+	var reg WriterRegistration
+	var requestedAt, reviewedAt int64
+	err := row.Scan(&reg.Name, &reg.Status, &reg.Description, &requestedAt, &reviewedAt, &reg.Reviewer, &reg.ReviewReason)
+	if err != nil {
+		return WriterRegistration{}, errors.Wrap(err, "scan writer registration")
+	}
+	reg.RequestedAt = time.Unix(requestedAt, 0).UTC()
+	reg.ReviewedAt = unixTimeOrNil(reviewedAt)
+	return reg, nil
+}
@@ -0,0 +1,93 @@
+package executive
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+)
+
+// testDBExecutiveWriterRegs is run from TestAllDBExecutive
+func testDBExecutiveWriterRegs(t *testing.T, dbType string) {
+	u := newDbExecTestUtil(t, dbType)
+	defer u.Close()
+	u.e.SourceIP = "10.0.0.1"
+
+	requireErrType := func(want interface{}, err error) {
+		t.Helper()
+		require.IsType(t, want, errors.Cause(err))
+	}
+
+	reg, err := u.e.RequestWriterRegistration("requested", "secret1", "team-a's pipeline")
+	require.NoError(t, err)
+	require.Equal(t, WriterRegistrationPending, reg.Status)
+	_, err = u.e.RequestWriterRegistration("requested", "secret1", "")
+	requireErrType(&errs.ConflictError{}, err)
+	_, err = u.e.RequestWriterRegistration("writer1", "secret1", "")
+	requireErrType(&errs.ConflictError{}, err)
+	_, err = u.e.RequestWriterRegistration("short", "s", "")
+	requireErrType(&errs.BadRequestError{}, err)
+
+	// the writer can't mutate until it's approved
+	_, err = u.e.GetWriterCookie("requested", "secret1")
+	require.Error(t, err)
+	registered, err := u.e.CheckWriterSecret("requested", "secret1")
+	require.NoError(t, err)
+	require.False(t, registered)
+
+	require.NoError(t, u.e.ApproveWriterRegistration("requested", WriterReview{Reviewer: "admin", Reason: "owned by team-a"}))
+	cookie, err := u.e.GetWriterCookie("requested", "secret1")
+	require.NoError(t, err)
+	require.NotNil(t, cookie)
+	// so it can re-register, but not with another secret
+	registered, err = u.e.CheckWriterSecret("requested", "secret1")
+	require.NoError(t, err)
+	require.True(t, registered)
+	registered, err = u.e.CheckWriterSecret("requested", "secret2")
+	require.NoError(t, err)
+	require.False(t, registered)
+	err = u.e.RejectWriterRegistration("requested", WriterReview{})
+	requireErrType(&errs.ConflictError{}, err)
+	err = u.e.ApproveWriterRegistration("missing", WriterReview{})
+	requireErrType(&errs.NotFoundError{}, err)
+
+	// rejected writers may be requested again
+	_, err = u.e.RequestWriterRegistration("rejected", "secret2", "")
+	require.NoError(t, err)
+	require.NoError(t, u.e.RejectWriterRegistration("rejected", WriterReview{Reviewer: "admin", Reason: "use writer1"}))
+	_, err = u.e.GetWriterCookie("rejected", "secret2")
+	require.Error(t, err)
+
+	regs, err := u.e.ReadWriterRegistrations(WriterRegistrationRejected)
+	require.NoError(t, err)
+	require.Len(t, regs, 1)
+	require.Equal(t, "rejected", regs[0].Name)
+	require.Equal(t, "admin", regs[0].Reviewer)
+	require.Equal(t, "use writer1", regs[0].ReviewReason)
+	require.NotNil(t, regs[0].ReviewedAt)
+
+	_, err = u.e.RequestWriterRegistration("rejected", "secret3", "")
+	require.NoError(t, err)
+	regs, err = u.e.ReadWriterRegistrations("")
+	require.NoError(t, err)
+	require.Len(t, regs, 2)
+	regs, err = u.e.ReadWriterRegistrations(WriterRegistrationPending)
+	require.NoError(t, err)
+	require.Len(t, regs, 1)
+	require.Nil(t, regs[0].ReviewedAt)
+	_, err = u.e.ReadWriterRegistrations("bogus")
+	requireErrType(&errs.BadRequestError{}, err)
+
+	audit, err := u.e.ReadWriterRegistrationAudit("rejected")
+	require.NoError(t, err)
+	var actions []string
+	for _, event := range audit {
+		require.Equal(t, "10.0.0.1", event.SourceIP)
+		actions = append(actions, event.Action)
+	}
+	require.Equal(t, []string{WriterRegistrationRequested, WriterRegistrationRejected, WriterRegistrationRequested}, actions)
+	require.Equal(t, "admin", audit[1].Actor)
+	require.Equal(t, "use writer1", audit[1].Reason)
+}
@@ -0,0 +1,236 @@
+package globalstats
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/segmentio/errors-go"
+	"github.com/segmentio/events/v2"
+	"github.com/segmentio/stats/v4"
+)
+
+const defaultOTLPTimeout = 5 * time.Second
+
+// OTLPConfig configures export of metrics to an OpenTelemetry collector
+// using OTLP over HTTP with JSON encoding.
+type OTLPConfig struct {
+	// Endpoint is the full URL metrics are posted to, usually
+	// http://<collector>:4318/v1/metrics.
+	Endpoint string
+	// Headers are added to every export request, e.g. for authentication.
+	Headers map[string]string
+	// ServiceName is reported as the service.name resource attribute.
+	ServiceName string
+	// Timeout bounds each export request. Defaults to 5s.
+	Timeout time.Duration
+}
+
+// OTLPHandler is a stats.Handler that aggregates measures in memory and
+// exports them to an OpenTelemetry collector each time it is flushed.
+// Counters are exported as delta sums, gauges as gauges, and histograms as
+// summaries with their min and max as the 0 and 1 quantiles.
+type OTLPHandler struct {
+	config OTLPConfig
+	client *http.Client
+
+	mut     sync.Mutex
+	start   time.Time
+	metrics map[otlpKey]*otlpAggregate
+}
+
+type otlpKey struct {
+	name string
+	typ  stats.FieldType
+	tags string
+}
+
+type otlpAggregate struct {
+	tags     []stats.Tag
+	count    int64
+	sum      float64
+	min, max float64
+	last     float64
+}
+
+// NewOTLPHandler builds an OTLPHandler. It must be flushed regularly, which
+// the stats engines used by ctlstore already do.
+func NewOTLPHandler(config OTLPConfig) *OTLPHandler {
+	if config.Timeout <= 0 {
+		config.Timeout = defaultOTLPTimeout
+	}
+	return &OTLPHandler{
+		config:  config,
+		client:  &http.Client{Timeout: config.Timeout},
+		start:   time.Now(),
+		metrics: map[otlpKey]*otlpAggregate{},
+	}
+}
+
+func (h *OTLPHandler) HandleMeasures(_ time.Time, measures ...stats.Measure) {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+	for _, m := range measures {
+		tags := ""
+		for _, t := range m.Tags {
+			tags += t.Name + "=" + t.Value + ","
+		}
+		for _, f := range m.Fields {
+			name := m.Name
+			if f.Name != "" {
+				if name != "" {
+					name += "."
+				}
+				name += f.Name
+			}
+			key := otlpKey{name: name, typ: f.Type(), tags: tags}
+			agg, ok := h.metrics[key]
+			if !ok {
+				agg = &otlpAggregate{tags: append([]stats.Tag{}, m.Tags...), min: math.Inf(1), max: math.Inf(-1)}
+				h.metrics[key] = agg
+			}
+			value := otlpFloat(f.Value)
+			agg.count++
+			agg.sum += value
+			agg.last = value
+			agg.min = math.Min(agg.min, value)
+			agg.max = math.Max(agg.max, value)
+		}
+	}
+}
+
+// Flush exports everything aggregated since the last flush.
+func (h *OTLPHandler) Flush() {
+	if err := h.export(context.Background()); err != nil {
+		events.Log("Failed exporting OTLP metrics: %{error}+v", err)
+	}
+}
+
+func (h *OTLPHandler) export(ctx context.Context) error {
+	h.mut.Lock()
+	metrics, start, end := h.metrics, h.start, time.Now()
+	h.metrics, h.start = map[otlpKey]*otlpAggregate{}, end
+	h.mut.Unlock()
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(h.payload(metrics, start, end))
+	if err != nil {
+		return errors.Wrap(err, "encode otlp payload")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "build otlp request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range h.config.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "post otlp metrics")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return errors.Errorf("otlp collector responded with %s", resp.Status)
+	}
+	return nil
+}
+
+// payload builds an ExportMetricsServiceRequest in its proto3 JSON form,
+// in which 64 bit integers are encoded as strings.
+func (h *OTLPHandler) payload(metrics map[otlpKey]*otlpAggregate, start, end time.Time) interface{} {
+	type object = map[string]interface{}
+	startNanos := strconv.FormatInt(start.UnixNano(), 10)
+	endNanos := strconv.FormatInt(end.UnixNano(), 10)
+
+	keys := make([]otlpKey, 0, len(metrics))
+	for k := range metrics {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].name != keys[j].name {
+			return keys[i].name < keys[j].name
+		}
+		return keys[i].tags < keys[j].tags
+	})
+
+	var res []object
+	for _, k := range keys {
+		agg := metrics[k]
+		attrs := make([]object, 0, len(agg.tags))
+		for _, t := range agg.tags {
+			attrs = append(attrs, object{"key": t.Name, "value": object{"stringValue": t.Value}})
+		}
+		point := object{
+			"attributes":        attrs,
+			"startTimeUnixNano": startNanos,
+			"timeUnixNano":      endNanos,
+		}
+		metric := object{"name": k.name}
+		switch k.typ {
+		case stats.Counter:
+			point["asDouble"] = agg.sum
+			metric["sum"] = object{
+				"dataPoints":             []object{point},
+				"aggregationTemporality": 1, // delta
+				"isMonotonic":            true,
+			}
+		case stats.Gauge:
+			point["asDouble"] = agg.last
+			metric["gauge"] = object{"dataPoints": []object{point}}
+		default:
+			point["count"] = strconv.FormatInt(agg.count, 10)
+			point["sum"] = agg.sum
+			point["quantileValues"] = []object{
+				{"quantile": 0.0, "value": agg.min},
+				{"quantile": 1.0, "value": agg.max},
+			}
+			metric["summary"] = object{"dataPoints": []object{point}}
+		}
+		res = append(res, metric)
+	}
+
+	serviceName := h.config.ServiceName
+	if serviceName == "" {
+		serviceName = "ctlstore"
+	}
+	return object{
+		"resourceMetrics": []object{{
+			"resource": object{
+				"attributes": []object{{"key": "service.name", "value": object{"stringValue": serviceName}}},
+			},
+			"scopeMetrics": []object{{
+				"scope":   object{"name": "github.com/segmentio/ctlstore"},
+				"metrics": res,
+			}},
+		}},
+	}
+}
+
+func otlpFloat(v stats.Value) float64 {
+	switch v.Type() {
+	case stats.Bool:
+		if v.Bool() {
+			return 1
+		}
+		return 0
+	case stats.Int:
+		return float64(v.Int())
+	case stats.Uint:
+		return float64(v.Uint())
+	case stats.Float:
+		return v.Float()
+	case stats.Duration:
+		return v.Duration().Seconds()
+	default:
+		return 0
+	}
+}
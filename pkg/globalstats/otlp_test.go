@@ -0,0 +1,64 @@
+package globalstats
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/segmentio/stats/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOTLPHandler(t *testing.T) {
+	var requests []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.Equal(t, "secret", r.Header.Get("X-Api-Key"))
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests = append(requests, body)
+	}))
+	defer srv.Close()
+
+	h := NewOTLPHandler(OTLPConfig{
+		Endpoint:    srv.URL,
+		Headers:     map[string]string{"X-Api-Key": "secret"},
+		ServiceName: "test-app",
+	})
+	engine := stats.NewEngine("ctlstore", h)
+	engine.Add("full-table-scans", 2, stats.T("family", "f"))
+	engine.Add("full-table-scans", 3, stats.T("family", "f"))
+	engine.Set("replica-healthy", 1)
+	engine.Observe("get_row_by_key", 2*time.Second)
+	engine.Observe("get_row_by_key", 4*time.Second)
+	engine.Flush()
+
+	// nothing new to export
+	engine.Flush()
+	require.Len(t, requests, 1)
+
+	resource := requests[0]["resourceMetrics"].([]interface{})[0].(map[string]interface{})
+	scope := resource["scopeMetrics"].([]interface{})[0].(map[string]interface{})
+	metrics := map[string]map[string]interface{}{}
+	for _, m := range scope["metrics"].([]interface{}) {
+		metric := m.(map[string]interface{})
+		metrics[metric["name"].(string)] = metric
+	}
+
+	point := func(name, kind string) map[string]interface{} {
+		m, ok := metrics[name]
+		require.True(t, ok, "missing metric %s in %v", name, metrics)
+		return m[kind].(map[string]interface{})["dataPoints"].([]interface{})[0].(map[string]interface{})
+	}
+	sum := point("ctlstore.full-table-scans", "sum")
+	require.EqualValues(t, 5, sum["asDouble"])
+	require.EqualValues(t, []interface{}{
+		map[string]interface{}{"key": "family", "value": map[string]interface{}{"stringValue": "f"}},
+	}, sum["attributes"])
+	require.EqualValues(t, 1, point("ctlstore.replica-healthy", "gauge")["asDouble"])
+	summary := point("ctlstore.get_row_by_key", "summary")
+	require.Equal(t, "2", summary["count"])
+	require.EqualValues(t, 6, summary["sum"])
+}
@@ -27,7 +27,11 @@ type (
 		FlushEvery   time.Duration
 		// SamplePct is the percent of Observe calls to report.
 		SamplePct float64
-		ctx       context.Context
+		// OTLP, if set, additionally exports stats to an OpenTelemetry
+		// collector.
+		OTLP *OTLPConfig
+		ctx  context.Context
+		otlp *OTLPHandler
 	}
 	observation struct {
 		name  string
@@ -45,6 +49,9 @@ type (
 		name   string
 		family string
 		table  string
+		// the caller_service and caller_endpoint tags, if set
+		service  string
+		endpoint string
 	}
 	statEventType int
 	// statEvent is a union type; only one of its values will be set, depending on the StatEventType.
@@ -80,7 +87,13 @@ func init() {
 }
 
 func Incr(name, family, table string) {
-	k := counterKey{name: name, family: family, table: table}
+	IncrCaller(name, family, table, "", "")
+}
+
+// IncrCaller is like Incr, but also tags the counter with the service and
+// endpoint of the caller it's attributed to. Either may be empty.
+func IncrCaller(name, family, table, service, endpoint string) {
+	k := counterKey{name: name, family: family, table: table, service: service, endpoint: endpoint}
 	select {
 	case eventChan <- statEvent{typ: statEventTypeIncr, incr: k}:
 	default:
@@ -135,6 +148,13 @@ func Initialize(ctx context.Context, cfg Config) {
 	if cfg.StatsHandler == nil {
 		cfg.StatsHandler = stats.DefaultEngine.Handler
 	}
+	if cfg.OTLP != nil {
+		otlpConfig := *cfg.OTLP
+		if otlpConfig.ServiceName == "" {
+			otlpConfig.ServiceName = cfg.AppName
+		}
+		cfg.otlp = NewOTLPHandler(otlpConfig)
+	}
 	cfg.ctx = ctx
 	if cfg.ctx == nil {
 		cfg.ctx = context.Background()
@@ -180,7 +200,7 @@ func loop() {
 
 			// Emit aggregated Incr metrics.
 			for k, v := range m {
-				engine.Add(k.name, v, stats.T("family", k.family), stats.T("table", k.table))
+				engine.Add(k.name, v, k.tags()...)
 				delete(m, k)
 			}
 
@@ -254,6 +274,13 @@ func lazyInitEngine(cfg *Config, engine *stats.Engine) *stats.Engine {
 	if handler == nil || handler == stats.Discard {
 		handler = stats.DefaultEngine.Handler
 	}
+	if cfg.otlp != nil {
+		if handler == stats.Discard {
+			handler = cfg.otlp
+		} else {
+			handler = stats.MultiHandler(handler, cfg.otlp)
+		}
+	}
 	if handler == stats.Discard {
 		return nil
 	}
@@ -266,6 +293,17 @@ func lazyInitEngine(cfg *Config, engine *stats.Engine) *stats.Engine {
 	return stats.NewEngine(statsPrefix, handler, tags...)
 }
 
+func (k counterKey) tags() []stats.Tag {
+	tags := []stats.Tag{stats.T("family", k.family), stats.T("table", k.table)}
+	if k.service != "" {
+		tags = append(tags, stats.T("caller_service", k.service))
+	}
+	if k.endpoint != "" {
+		tags = append(tags, stats.T("caller_endpoint", k.endpoint))
+	}
+	return tags
+}
+
 // incrDroppedStats atomically records that a stat was dropped.
 func incrDroppedStats() {
 	atomic.AddInt64(&droppedStats, 1)
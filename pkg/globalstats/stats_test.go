@@ -133,3 +133,19 @@ func TestGlobalStats(t *testing.T) {
 		},
 	}, flusherMeasures)
 }
+
+func TestCounterKeyTags(t *testing.T) {
+	k := counterKey{name: "a", family: "family-a", table: "table-a"}
+	require.Equal(t, []stats.Tag{
+		stats.T("family", "family-a"),
+		stats.T("table", "table-a"),
+	}, k.tags())
+
+	k.service, k.endpoint = "service-x", "endpoint-y"
+	require.Equal(t, []stats.Tag{
+		stats.T("family", "family-a"),
+		stats.T("table", "table-a"),
+		stats.T("caller_service", "service-x"),
+		stats.T("caller_endpoint", "endpoint-y"),
+	}, k.tags())
+}
@@ -2,11 +2,15 @@ package ldb
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync/atomic"
 	"testing"
 
@@ -16,17 +20,34 @@ import (
 const (
 	LDBSeqTableName           = "_ldb_seq"
 	LDBLastUpdateTableName    = "_ldb_last_update"
+	LDBIdentityTableName      = "_ldb_identity"
+	LDBQuarantineTableName    = "_ldb_quarantine"
 	LDBLastLedgerUpdateColumn = "ledger"
 	LDBSeqTableID             = 1
 	LDBDatabaseDriver         = "sqlite3"
 	DefaultLDBFilename        = "ldb.db"
+
+	// RebuiltPathSuffix is appended to an LDB's path for the file naming
+	// the LDB that it was rebuilt as, which is used in the LDB's place
+	RebuiltPathSuffix = ".rebuilt"
 )
 
 var (
 	// SQL for fetching current tracked sequence
 	ldbFetchSeqSQL = fmt.Sprintf(`
-		SELECT seq FROM %s WHERE id = %d
-		`, LDBSeqTableName, LDBSeqTableID)
+		SELECT seq FROM %s WHERE id = ?
+		`, LDBSeqTableName)
+
+	// SQL for fetching the identity assigned when the LDB was initialized
+	ldbFetchIdentitySQL = fmt.Sprintf(`
+		SELECT identity FROM %s WHERE id = %d
+		`, LDBIdentityTableName, LDBSeqTableID)
+
+	// Assigns an identity only if the LDB doesn't have one yet, so that
+	// LDBs bootstrapped from a snapshot keep the identity of the original.
+	ldbAssignIdentitySQL = fmt.Sprintf(`
+		INSERT OR IGNORE INTO %s (id, identity) VALUES (%d, ?)
+		`, LDBIdentityTableName, LDBSeqTableID)
 
 	ldbInitializeDDLs = []string{
 		// Initialization DDL for table that tracks sequence position. Tried to avoid
@@ -39,6 +60,19 @@ var (
 			name STRING PRIMARY KEY NOT NULL,
 			timestamp DATETIME NOT NULL
 		)`, LDBLastUpdateTableName),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			id INTEGER PRIMARY KEY NOT NULL,
+			identity VARCHAR NOT NULL
+		)`, LDBIdentityTableName),
+		// Statements which the reflector refused to apply
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			ledger_id INTEGER NOT NULL,
+			seq BIGINT NOT NULL,
+			statement TEXT NOT NULL,
+			reason VARCHAR NOT NULL,
+			timestamp DATETIME NOT NULL,
+			PRIMARY KEY (ledger_id, seq)
+		)`, LDBQuarantineTableName),
 	}
 )
 
@@ -75,12 +109,53 @@ func LDBForTest(t testing.TB) (res *sql.DB, teardown func()) {
 }
 
 func OpenLDB(path string, mode string) (*sql.DB, error) {
-	return sql.Open("sqlite3_with_autocheckpoint_off",
-		fmt.Sprintf("file:%s?_journal_mode=wal&mode=%s", path, mode))
+	return OpenLDBWithPragmas(path, mode, DefaultPragmas)
+}
+
+// OpenLDBWithPragmas opens the LDB at path like OpenLDB, applying p rather
+// than DefaultPragmas.
+func OpenLDBWithPragmas(path string, mode string, p Pragmas) (*sql.DB, error) {
+	return OpenLDBWithOptions(path, OpenOptions{Mode: mode}, p)
+}
+
+// OpenOptions tune how OpenLDBWithOptions opens an LDB.
+type OpenOptions struct {
+	// Mode is the SQLite access mode, e.g. "ro" or "rwc". It's ignored for
+	// immutable LDBs, which are always read-only.
+	Mode string
+	// Immutable tells SQLite that the LDB can't change while it's open, so
+	// that it doesn't lock it or check for changes. Only LDBs which are no
+	// longer written, such as versioned LDBs, may be opened this way, since
+	// it also ignores the WAL.
+	Immutable bool
+	// SharedCache makes the connections share a page cache, saving memory
+	// when there are many of them.
+	SharedCache bool
+}
+
+// OpenLDBWithOptions opens the LDB at path with the options, applying p.
+func OpenLDBWithOptions(path string, o OpenOptions, p Pragmas) (*sql.DB, error) {
+	params := url.Values{}
+	if o.Immutable {
+		params.Set("immutable", "true")
+	} else {
+		params.Set("mode", o.Mode)
+	}
+	if o.SharedCache {
+		params.Set("cache", "shared")
+	}
+	dsn := fmt.Sprintf("file:%s?%s", path, params.Encode())
+	return openWithStatements("sqlite3_with_autocheckpoint_off", dsn, p, !o.Immutable)
 }
 
 func OpenImmutableLDB(path string) (*sql.DB, error) {
-	return sql.Open("sqlite3_with_autocheckpoint_off", fmt.Sprintf("file:%s?immutable=true", path))
+	return OpenImmutableLDBWithPragmas(path, DefaultPragmas)
+}
+
+// OpenImmutableLDBWithPragmas opens the LDB at path like OpenImmutableLDB,
+// applying p rather than DefaultPragmas.
+func OpenImmutableLDBWithPragmas(path string, p Pragmas) (*sql.DB, error) {
+	return OpenLDBWithOptions(path, OpenOptions{Immutable: true}, p)
 }
 
 // Ensures the LDB is prepared for queries
@@ -90,7 +165,12 @@ func EnsureLdbInitialized(ctx context.Context, db *sql.DB) error {
 			return err
 		}
 	}
-	return nil
+	identity := make([]byte, 16)
+	if _, err := rand.Read(identity); err != nil {
+		return err
+	}
+	_, err := db.ExecContext(ctx, ldbAssignIdentitySQL, hex.EncodeToString(identity))
+	return err
 }
 
 func NewLDBTmpPath(t *testing.T) (string, func()) {
@@ -111,9 +191,35 @@ func NextTestLdbTmpPath(testTmpDir string) string {
 	return fmt.Sprintf("%s/ldbForTest%d.db", testTmpDir, nextSeq)
 }
 
+// CurrentPath returns the path of the LDB configured at path, which is the
+// LDB it was last rebuilt as if the reflector has rebuilt it, and otherwise
+// the path itself.
+func CurrentPath(path string) (string, error) {
+	b, err := os.ReadFile(path + RebuiltPathSuffix)
+	switch {
+	case os.IsNotExist(err):
+		return path, nil
+	case err != nil:
+		return "", fmt.Errorf("read rebuilt ldb path: %w", err)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// SeqTableIDForLedger returns the id of the sequence tracking row for the
+// upstream ledger with the given ID. The primary ledger is tracked in the
+// LDBSeqTableID row, as it was before sharded ledgers were supported.
+func SeqTableIDForLedger(ledgerID int) int {
+	return LDBSeqTableID + ledgerID
+}
+
 // Gets current sequence from provided db
 func FetchSeqFromLdb(ctx context.Context, db *sql.DB) (schema.DMLSequence, error) {
-	row := db.QueryRowContext(ctx, ldbFetchSeqSQL)
+	return FetchLedgerSeqFromLdb(ctx, db, 0)
+}
+
+// Gets the current sequence of one upstream ledger from provided db
+func FetchLedgerSeqFromLdb(ctx context.Context, db *sql.DB, ledgerID int) (schema.DMLSequence, error) {
+	row := db.QueryRowContext(ctx, ldbFetchSeqSQL, SeqTableIDForLedger(ledgerID))
 	var seq int64
 	err := row.Scan(&seq)
 	if err == sql.ErrNoRows {
@@ -121,3 +227,38 @@ func FetchSeqFromLdb(ctx context.Context, db *sql.DB) (schema.DMLSequence, error
 	}
 	return schema.DMLSequence(seq), err
 }
+
+// Gets the current sequence of every upstream ledger from provided db,
+// keyed by ledger ID
+func FetchLedgerSeqsFromLdb(ctx context.Context, db *sql.DB) (map[int]schema.DMLSequence, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("SELECT id, seq FROM %s", LDBSeqTableName))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	seqs := map[int]schema.DMLSequence{}
+	for rows.Next() {
+		var id int
+		var seq int64
+		if err := rows.Scan(&id, &seq); err != nil {
+			return nil, err
+		}
+		seqs[id-LDBSeqTableID] = schema.DMLSequence(seq)
+	}
+	return seqs, rows.Err()
+}
+
+// Gets the identity of the provided db. LDBs which were initialized
+// before identities were introduced have an empty identity.
+func FetchIdentityFromLdb(ctx context.Context, db *sql.DB) (string, error) {
+	row := db.QueryRowContext(ctx, ldbFetchIdentitySQL)
+	var identity string
+	err := row.Scan(&identity)
+	switch {
+	case err == sql.ErrNoRows:
+		return "", nil
+	case err != nil && strings.Contains(err.Error(), "no such table"):
+		return "", nil
+	}
+	return identity, err
+}
@@ -0,0 +1,131 @@
+package ldb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// Pragmas tune how SQLite accesses an LDB. They're applied to each
+// connection that the reflector and readers open, and zero values leave
+// SQLite's own defaults in place.
+type Pragmas struct {
+	// MmapSize is how many bytes of the LDB are read through a memory map
+	// rather than read calls.
+	MmapSize int64
+	// CacheSize is the size of each connection's page cache, in pages if
+	// positive, or in KiB if negative.
+	CacheSize int
+	// PageSize is the page size of LDBs which are created. It has no
+	// effect on existing LDBs.
+	PageSize int
+	// Synchronous is one of OFF, NORMAL, FULL or EXTRA, and only matters
+	// to the reflector, since readers don't write.
+	Synchronous string
+}
+
+// DefaultPragmas suit readers, which far outnumber writes to an LDB. Memory
+// mapping the LDB saves copying each page that a query reads into the page
+// cache, and the larger cache keeps the indexes of busy tables resident.
+var DefaultPragmas = Pragmas{
+	MmapSize:  256 * 1024 * 1024,
+	CacheSize: -16 * 1024,
+}
+
+// Validate checks that the pragmas have values SQLite accepts, since it
+// otherwise ignores invalid values rather than failing.
+func (p Pragmas) Validate() error {
+	if p.MmapSize < 0 {
+		return fmt.Errorf("mmap size must not be negative: %d", p.MmapSize)
+	}
+	if p.PageSize != 0 && (p.PageSize < 512 || p.PageSize > 65536 || p.PageSize&(p.PageSize-1) != 0) {
+		return fmt.Errorf("page size must be a power of two between 512 and 65536: %d", p.PageSize)
+	}
+	switch strings.ToUpper(p.Synchronous) {
+	case "", "OFF", "NORMAL", "FULL", "EXTRA":
+	default:
+		return fmt.Errorf("synchronous must be one of OFF, NORMAL, FULL or EXTRA: %s", p.Synchronous)
+	}
+	return nil
+}
+
+// statements returns the PRAGMA statements which apply p. The page size
+// comes first, since it can't change once the LDB is in WAL mode, which is
+// enabled afterwards if wal is set.
+func (p Pragmas) statements(wal bool) []string {
+	var res []string
+	if p.PageSize != 0 {
+		res = append(res, fmt.Sprintf("PRAGMA page_size = %d", p.PageSize))
+	}
+	if wal {
+		res = append(res, "PRAGMA journal_mode = wal")
+	}
+	if p.MmapSize != 0 {
+		res = append(res, fmt.Sprintf("PRAGMA mmap_size = %d", p.MmapSize))
+	}
+	if p.CacheSize != 0 {
+		res = append(res, fmt.Sprintf("PRAGMA cache_size = %d", p.CacheSize))
+	}
+	if p.Synchronous != "" {
+		res = append(res, "PRAGMA synchronous = "+strings.ToUpper(p.Synchronous))
+	}
+	return res
+}
+
+// OpenWithPragmas opens an LDB in WAL mode using the named SQLite driver,
+// applying p to each new connection. The DSN shouldn't set a journal mode,
+// since the page size has to be set first.
+func OpenWithPragmas(driverName string, dsn string, p Pragmas) (*sql.DB, error) {
+	return openWithStatements(driverName, dsn, p, true)
+}
+
+func openWithStatements(driverName string, dsn string, p Pragmas, wal bool) (*sql.DB, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	// sql.Open doesn't connect, it's only used to look up the driver
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	drv := db.Driver()
+	db.Close()
+	return sql.OpenDB(&pragmaConnector{
+		driver:     drv,
+		dsn:        dsn,
+		statements: p.statements(wal),
+	}), nil
+}
+
+// pragmaConnector opens connections with a driver, and executes statements
+// on each before it's used.
+type pragmaConnector struct {
+	driver     driver.Driver
+	dsn        string
+	statements []string
+}
+
+func (c *pragmaConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	execer, ok := conn.(driver.ExecerContext)
+	if !ok {
+		conn.Close()
+		return nil, fmt.Errorf("driver connection %T can't execute pragmas", conn)
+	}
+	for _, statement := range c.statements {
+		if _, err := execer.ExecContext(ctx, statement, nil); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s: %w", statement, err)
+		}
+	}
+	return conn, nil
+}
+
+func (c *pragmaConnector) Driver() driver.Driver {
+	return c.driver
+}
@@ -0,0 +1,94 @@
+package ldb
+
+import (
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/segmentio/ctlstore/pkg/sqlite"
+)
+
+func TestOpenLDBWithPragmas(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ldb.db")
+	db, err := OpenLDBWithPragmas(path, "rwc", Pragmas{
+		MmapSize:    1 << 20,
+		CacheSize:   -1024,
+		PageSize:    16384,
+		Synchronous: "normal",
+	})
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec("CREATE TABLE foo___bar (key VARCHAR PRIMARY KEY)")
+	require.NoError(t, err)
+
+	for pragma, want := range map[string]string{
+		"journal_mode": "wal",
+		"page_size":    "16384",
+		"mmap_size":    "1048576",
+		"cache_size":   "-1024",
+		"synchronous":  "1",
+	} {
+		var got string
+		require.NoError(t, db.QueryRow("PRAGMA "+pragma).Scan(&got))
+		require.Equal(t, want, got, pragma)
+	}
+}
+
+func TestPragmasValidate(t *testing.T) {
+	require.NoError(t, DefaultPragmas.Validate())
+	require.NoError(t, Pragmas{}.Validate())
+	require.Error(t, Pragmas{PageSize: 1000}.Validate())
+	require.Error(t, Pragmas{PageSize: 256}.Validate())
+	require.Error(t, Pragmas{MmapSize: -1}.Validate())
+	require.Error(t, Pragmas{Synchronous: "sometimes"}.Validate())
+
+	_, err := OpenLDBWithPragmas(filepath.Join(t.TempDir(), "ldb.db"), "rwc", Pragmas{PageSize: 1000})
+	require.Error(t, err)
+}
+
+// BenchmarkPointReads compares random primary key reads of a table that
+// doesn't fit in SQLite's default page cache.
+func BenchmarkPointReads(b *testing.B) {
+	const rows = 200000
+	path := filepath.Join(b.TempDir(), "ldb.db")
+	db, err := OpenLDB(path, "rwc")
+	require.NoError(b, err)
+	_, err = db.Exec("CREATE TABLE foo___bar (key VARCHAR PRIMARY KEY, val VARCHAR)")
+	require.NoError(b, err)
+	tx, err := db.Begin()
+	require.NoError(b, err)
+	for i := 0; i < rows; i++ {
+		_, err = tx.Exec("INSERT INTO foo___bar VALUES (?, ?)", fmt.Sprintf("key-%d", i), fmt.Sprintf("%0200d", i))
+		require.NoError(b, err)
+	}
+	require.NoError(b, tx.Commit())
+	_, err = db.Exec("PRAGMA wal_checkpoint(TRUNCATE)")
+	require.NoError(b, err)
+	require.NoError(b, db.Close())
+
+	for name, pragmas := range map[string]Pragmas{
+		"sqlite-defaults": {},
+		"default-pragmas": DefaultPragmas,
+	} {
+		b.Run(name, func(b *testing.B) {
+			db, err := OpenLDBWithPragmas(path, "ro", pragmas)
+			require.NoError(b, err)
+			defer db.Close()
+			stmt, err := db.Prepare("SELECT val FROM foo___bar WHERE key = ?")
+			require.NoError(b, err)
+			defer stmt.Close()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				var val string
+				err := stmt.QueryRow(fmt.Sprintf("key-%d", rand.Intn(rows))).Scan(&val)
+				if err != nil && err != sql.ErrNoRows {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
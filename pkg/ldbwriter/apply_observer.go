@@ -0,0 +1,67 @@
+package ldbwriter
+
+import (
+	"context"
+	"time"
+
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+// ApplyObserver is told about each statement that a writer applies, so
+// that programs which embed the reflector as a library can track how it's
+// keeping up, such as for their own SLOs, without scraping its metrics.
+// It's called synchronously by the writer, so it must be quick.
+type ApplyObserver interface {
+	StatementApplied(ctx context.Context, info ApplyInfo)
+}
+
+// ApplyObserverFunc adapts a func to an ApplyObserver.
+type ApplyObserverFunc func(ctx context.Context, info ApplyInfo)
+
+func (f ApplyObserverFunc) StatementApplied(ctx context.Context, info ApplyInfo) {
+	f(ctx, info)
+}
+
+// ApplyOutcome is what a writer did with a statement.
+type ApplyOutcome string
+
+const (
+	// ApplyExecuted statements were executed against the LDB
+	ApplyExecuted ApplyOutcome = "executed"
+	// ApplyFiltered statements belong to a family the writer doesn't apply
+	ApplyFiltered ApplyOutcome = "filtered"
+	// ApplyQuarantined statements were refused by the writer's guard
+	ApplyQuarantined ApplyOutcome = "quarantined"
+	// ApplyControl statements begin or end a ledger transaction
+	ApplyControl ApplyOutcome = "control"
+)
+
+// ApplyInfo describes the application of a statement.
+type ApplyInfo struct {
+	Sequence schema.DMLSequence
+	LedgerID int
+	// Family and Table are those of the statement, if it names a table
+	Family string
+	Table  string
+	// Outcome is empty if the statement failed to apply
+	Outcome ApplyOutcome
+	// RowsChanged is the number of rows inserted, updated or deleted
+	RowsChanged int64
+	// Duration is how long the statement took to apply, including
+	// recording its sequence
+	Duration time.Duration
+	// Committed is whether the statement is visible to readers. It isn't
+	// while it's part of an open ledger transaction or group commit
+	// batch.
+	Committed bool
+	Err       error
+}
+
+// newApplyInfo describes the statement before it's applied.
+func newApplyInfo(statement schema.DMLStatement) ApplyInfo {
+	info := ApplyInfo{Sequence: statement.Sequence, LedgerID: statement.LedgerID}
+	if dml, err := statement.DML(); err == nil {
+		info.Family, info.Table, _ = statementTable(dml.SQL)
+	}
+	return info
+}
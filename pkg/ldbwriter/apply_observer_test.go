@@ -0,0 +1,80 @@
+package ldbwriter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/ldb"
+	"github.com/segmentio/ctlstore/pkg/schema"
+	"github.com/segmentio/ctlstore/pkg/sqlite"
+)
+
+func TestApplyObserver(t *testing.T) {
+	db, teardown := ldb.LDBForTest(t)
+	defer teardown()
+	ctx := context.Background()
+	_, err := db.Exec("CREATE TABLE fam___foo (bar VARCHAR)")
+	require.NoError(t, err)
+
+	var observed []ApplyInfo
+	writer := SqlLdbWriter{
+		Db:          db,
+		GroupCommit: GroupCommit{MaxStatements: 2},
+		Families:    FamilyFilter{Include: []string{"fam"}},
+		Observer: ApplyObserverFunc(func(ctx context.Context, info ApplyInfo) {
+			require.True(t, info.Duration > 0)
+			info.Duration = 0
+			observed = append(observed, info)
+		}),
+	}
+	defer writer.Close()
+
+	var seqs []schema.DMLSequence
+	for _, statement := range []string{
+		"INSERT INTO fam___foo VALUES('a')",
+		"INSERT INTO other___foo VALUES('b')",
+		"DELETE FROM fam___foo",
+	} {
+		st := schema.NewTestDMLStatement(statement)
+		require.NoError(t, writer.ApplyDMLStatement(ctx, st))
+		seqs = append(seqs, st.Sequence)
+	}
+	st := schema.NewTestDMLStatement("INSERT INTO fam___nope VALUES('c')")
+	err = writer.ApplyDMLStatement(ctx, st)
+	require.Error(t, err)
+
+	require.Equal(t, []ApplyInfo{
+		{Sequence: seqs[0], Family: "fam", Table: "foo", Outcome: ApplyExecuted, RowsChanged: 1},
+		{Sequence: seqs[1], Family: "other", Table: "foo", Outcome: ApplyFiltered, Committed: true},
+		{Sequence: seqs[2], Family: "fam", Table: "foo", Outcome: ApplyExecuted, RowsChanged: 1},
+		{Sequence: st.Sequence, Family: "fam", Table: "nope", Err: err},
+	}, observed)
+}
+
+func TestCallbackWriterApplyObserver(t *testing.T) {
+	db, teardown := ldb.LDBForTest(t)
+	defer teardown()
+	ctx := context.Background()
+	_, err := db.Exec("CREATE TABLE fam___foo (bar VARCHAR)")
+	require.NoError(t, err)
+
+	var observed []ApplyInfo
+	changeBuffer := &sqlite.SQLChangeBuffer{}
+	changeBuffer.Add(sqlite.SQLiteWatchChange{})
+	writer := CallbackWriter{
+		DB:           db,
+		Delegate:     &SqlLdbWriter{Db: db},
+		ChangeBuffer: changeBuffer,
+		Observer: ApplyObserverFunc(func(ctx context.Context, info ApplyInfo) {
+			info.Duration = 0
+			observed = append(observed, info)
+		}),
+	}
+	st := schema.NewTestDMLStatement("INSERT INTO fam___foo VALUES('a')")
+	require.NoError(t, writer.ApplyDMLStatement(ctx, st))
+	require.Equal(t, []ApplyInfo{
+		{Sequence: st.Sequence, Family: "fam", Table: "foo", Outcome: ApplyExecuted, RowsChanged: 1},
+	}, observed)
+}
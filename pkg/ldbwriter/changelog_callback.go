@@ -2,20 +2,58 @@ package ldbwriter
 
 import (
 	"context"
+	"strings"
 	"sync/atomic"
 
 	"github.com/segmentio/ctlstore/pkg/changelog"
 	"github.com/segmentio/ctlstore/pkg/schema"
 	"github.com/segmentio/events/v2"
+	"github.com/segmentio/stats/v4"
 )
 
 type ChangelogCallback struct {
 	ChangelogWriter *changelog.ChangelogWriter
 	Seq             int64
+	// Filter selects the tables whose changes are written. Changes to other
+	// tables don't consume seqs.
+	Filter ChangelogFilter
+
+	// the ledger sequence of the open ledger transaction, whose changes are
+	// held until it ends so that its last change can be marked
+	txSeq   int64
+	pending []changelog.ChangelogEntry
 }
 
 func (c *ChangelogCallback) LDBWritten(ctx context.Context, data LDBWriteMetadata) {
+	switch data.Statement.Statement {
+	case schema.DMLTxBeginKey:
+		c.txSeq = data.Statement.Sequence.Int()
+		c.pending = c.pending[:0]
+		return
+	case schema.DMLTxEndKey:
+		c.write(c.pending)
+		c.txSeq = 0
+		c.pending = c.pending[:0]
+		return
+	}
+
+	entries := c.entries(data)
+	if c.txSeq != 0 {
+		c.pending = append(c.pending, entries...)
+		return
+	}
+	c.write(entries)
+}
+
+// entries returns the changelog entries for the changes of a statement.
+func (c *ChangelogCallback) entries(data LDBWriteMetadata) []changelog.ChangelogEntry {
+	var res []changelog.ChangelogEntry
 	for _, change := range data.Changes {
+		if strings.HasPrefix(change.TableName, schema.LDBRebuildTablePrefix) {
+			// the rows copied while a table is rebuilt haven't changed
+			stats.Incr("changelog_callback.rebuild_skipped")
+			continue
+		}
 		fam, tbl, err := schema.DecodeLDBTableName(change.TableName)
 		if err != nil {
 			// This is expected because it'll capture tables like ctlstore_dml_ledger,
@@ -25,6 +63,10 @@ func (c *ChangelogCallback) LDBWritten(ctx context.Context, data LDBWriteMetadat
 				err)
 			continue
 		}
+		if !c.Filter.Matches(fam.Name, tbl.Name) {
+			stats.Incr("changelog_callback.filtered")
+			continue
+		}
 
 		keys, err := change.ExtractKeys(data.DB)
 		if err != nil {
@@ -35,18 +77,30 @@ func (c *ChangelogCallback) LDBWritten(ctx context.Context, data LDBWriteMetadat
 		}
 
 		for _, key := range keys {
-			seq := atomic.AddInt64(&c.Seq, 1)
-			err = c.ChangelogWriter.WriteChange(changelog.ChangelogEntry{
-				Seq:    seq,
-				Family: fam.Name,
-				Table:  tbl.Name,
-				Key:    key,
+			res = append(res, changelog.ChangelogEntry{
+				Family:    fam.Name,
+				Table:     tbl.Name,
+				Key:       key,
+				LedgerSeq: data.Statement.Sequence.Int(),
+				LedgerID:  data.Statement.LedgerID,
+				TxSeq:     c.txSeq,
 			})
-			if err != nil {
-				events.Log("Skipped logging change to %{family}s.%{table}s:%{key}v: %{err}v",
-					fam, tbl, key, err)
-				continue
-			}
+		}
+	}
+	return res
+}
+
+// write writes the entries of a statement or ledger transaction, marking
+// the last one as its end.
+func (c *ChangelogCallback) write(entries []changelog.ChangelogEntry) {
+	for i, e := range entries {
+		e.Seq = atomic.AddInt64(&c.Seq, 1)
+		e.TxEnd = i == len(entries)-1
+		err := c.ChangelogWriter.WriteChange(e)
+		if err != nil {
+			events.Log("Skipped logging change to %{family}s.%{table}s:%{key}v: %{err}v",
+				e.Family, e.Table, e.Key, err)
+			continue
 		}
 	}
 }
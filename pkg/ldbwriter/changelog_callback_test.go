@@ -0,0 +1,102 @@
+package ldbwriter
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/changelog"
+	"github.com/segmentio/ctlstore/pkg/ldb"
+	"github.com/segmentio/ctlstore/pkg/schema"
+	"github.com/segmentio/ctlstore/pkg/sqlite"
+)
+
+func TestChangelogCallbackLedgerSeqs(t *testing.T) {
+	db, teardown := ldb.LDBForTest(t)
+	defer teardown()
+	_, err := db.Exec("CREATE TABLE family1___table1 (id INTEGER PRIMARY KEY, name VARCHAR)")
+	require.NoError(t, err)
+
+	var lines changelogLines
+	cb := &ChangelogCallback{ChangelogWriter: &changelog.ChangelogWriter{WriteLine: &lines}}
+	written := func(seq int64, statement string, ids ...int64) {
+		data := LDBWriteMetadata{
+			DB:        db,
+			Statement: schema.DMLStatement{Sequence: schema.DMLSequence(seq), Statement: statement},
+		}
+		for _, id := range ids {
+			data.Changes = append(data.Changes, sqlite.SQLiteWatchChange{
+				DatabaseName: "main",
+				TableName:    "family1___table1",
+				NewRow:       []interface{}{id, "name"},
+			})
+		}
+		cb.LDBWritten(context.Background(), data)
+	}
+	type entry struct {
+		Seq       int64 `json:"seq"`
+		LedgerSeq int64 `json:"ledgerSeq"`
+		LedgerID  int   `json:"ledgerId"`
+		TxSeq     int64 `json:"txSeq"`
+		TxEnd     bool  `json:"txEnd"`
+	}
+	entries := func() []entry {
+		var res []entry
+		for _, line := range lines {
+			var e entry
+			require.NoError(t, json.Unmarshal([]byte(line), &e))
+			res = append(res, e)
+		}
+		lines = nil
+		return res
+	}
+
+	// the last change of a statement ends it
+	written(10, "REPLACE INTO family1___table1 ...", 1, 2)
+	require.Equal(t, []entry{
+		{Seq: 1, LedgerSeq: 10},
+		{Seq: 2, LedgerSeq: 10, TxEnd: true},
+	}, entries())
+
+	// and the changes of a ledger transaction are held until it ends
+	written(11, schema.DMLTxBeginKey)
+	written(12, "REPLACE INTO family1___table1 ...", 3)
+	written(13, "REPLACE INTO family1___table1 ...", 4)
+	require.Empty(t, lines)
+	written(14, schema.DMLTxEndKey)
+	require.Equal(t, []entry{
+		{Seq: 3, LedgerSeq: 12, TxSeq: 11},
+		{Seq: 4, LedgerSeq: 13, TxSeq: 11, TxEnd: true},
+	}, entries())
+
+	written(15, "REPLACE INTO family1___table1 ...", 5)
+	require.Equal(t, []entry{{Seq: 5, LedgerSeq: 15, TxEnd: true}}, entries())
+
+	// shard ledgers' sequences overlap ledger 0's, so entries name their ledger
+	cb.LDBWritten(context.Background(), LDBWriteMetadata{
+		DB:        db,
+		Statement: schema.DMLStatement{Sequence: 15, LedgerID: 1, Statement: "REPLACE INTO family1___table1 ..."},
+		Changes: []sqlite.SQLiteWatchChange{{
+			DatabaseName: "main",
+			TableName:    "family1___table1",
+			NewRow:       []interface{}{int64(6), "name"},
+		}},
+	})
+	require.Equal(t, []entry{{Seq: 6, LedgerSeq: 15, LedgerID: 1, TxEnd: true}}, entries())
+
+	// the rows copied while rebuilding a table aren't changes to it
+	_, err = db.Exec("CREATE TABLE _rebuild_family1___table1 (id INTEGER PRIMARY KEY, name VARCHAR)")
+	require.NoError(t, err)
+	cb.LDBWritten(context.Background(), LDBWriteMetadata{
+		DB:        db,
+		Statement: schema.DMLStatement{Sequence: 16, Statement: "INSERT INTO _rebuild_family1___table1 SELECT * FROM family1___table1"},
+		Changes: []sqlite.SQLiteWatchChange{{
+			DatabaseName: "main",
+			TableName:    "_rebuild_family1___table1",
+			NewRow:       []interface{}{int64(1), "name"},
+		}},
+	})
+	require.Empty(t, lines)
+}
@@ -0,0 +1,53 @@
+package ldbwriter
+
+import (
+	"path"
+
+	"github.com/pkg/errors"
+)
+
+// ChangelogFilter selects the tables whose changes are written to the
+// changelog, for consumers which only care about a few of them. Patterns
+// are "family.table" globs as matched by path.Match, e.g. "payments.*" or
+// "*.settings". The zero value writes the changes to every table.
+type ChangelogFilter struct {
+	// Include, if set, lists patterns of the only tables to write
+	Include []string
+	// Exclude lists patterns of tables which aren't written
+	Exclude []string
+}
+
+// Validate checks that the patterns are well formed.
+func (f ChangelogFilter) Validate() error {
+	for _, patterns := range [][]string{f.Include, f.Exclude} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, "family.table"); err != nil {
+				return errors.Wrapf(err, "invalid changelog table pattern %q", pattern)
+			}
+		}
+	}
+	return nil
+}
+
+// Enabled returns whether the filter may exclude any table.
+func (f ChangelogFilter) Enabled() bool {
+	return len(f.Include) > 0 || len(f.Exclude) > 0
+}
+
+// Matches returns whether changes to the table are written.
+func (f ChangelogFilter) Matches(family, table string) bool {
+	name := family + "." + table
+	if matchesAny(f.Exclude, name) {
+		return false
+	}
+	return len(f.Include) == 0 || matchesAny(f.Include, name)
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package ldbwriter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/changelog"
+	"github.com/segmentio/ctlstore/pkg/sqlite"
+)
+
+func TestChangelogFilter(t *testing.T) {
+	require.False(t, ChangelogFilter{}.Enabled())
+	require.True(t, ChangelogFilter{}.Matches("family1", "table1"))
+
+	include := ChangelogFilter{Include: []string{"family1.*", "*.settings"}}
+	require.True(t, include.Enabled())
+	require.True(t, include.Matches("family1", "table1"))
+	require.True(t, include.Matches("family2", "settings"))
+	require.False(t, include.Matches("family2", "table1"))
+	require.False(t, include.Matches("family10", "table1"))
+
+	both := ChangelogFilter{Include: []string{"family1.*"}, Exclude: []string{"family1.noisy_*"}}
+	require.True(t, both.Matches("family1", "table1"))
+	require.False(t, both.Matches("family1", "noisy_events"))
+
+	require.NoError(t, both.Validate())
+	require.Error(t, ChangelogFilter{Exclude: []string{"family1.[table"}}.Validate())
+}
+
+type changelogLines []string
+
+func (l *changelogLines) WriteLine(line string) error {
+	*l = append(*l, line)
+	return nil
+}
+
+func TestChangelogCallbackFilter(t *testing.T) {
+	var lines changelogLines
+	cb := &ChangelogCallback{
+		ChangelogWriter: &changelog.ChangelogWriter{WriteLine: &lines},
+		Filter:          ChangelogFilter{Include: []string{"family1.table1"}},
+	}
+
+	// filtered changes are skipped before their keys are read from the
+	// LDB, so none is needed
+	cb.LDBWritten(context.Background(), LDBWriteMetadata{
+		Changes: []sqlite.SQLiteWatchChange{
+			{DatabaseName: "main", TableName: "family1___table2"},
+			{DatabaseName: "main", TableName: "family2___table1"},
+		},
+	})
+	require.Empty(t, lines)
+	require.EqualValues(t, 0, cb.Seq)
+}
@@ -0,0 +1,80 @@
+package ldbwriter
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+// FamilyFilter selects the families whose statements are applied to an
+// LDB, for LDBs which only hold some of the ctldb's families. The zero
+// value applies every family.
+type FamilyFilter struct {
+	// Include, if set, lists the only families to apply
+	Include []string
+	// Exclude lists families which aren't applied
+	Exclude []string
+}
+
+// ledgerTableName finds the first LDB table named by a ledger statement,
+// including the temporary tables which sqlgen rebuilds tables through.
+var ledgerTableName = regexp.MustCompile("(?i)(?:^|[\\s\"`(])(?:_rebuild_)?([a-z][a-z0-9_]*?)___([a-z][a-z0-9_]*)")
+
+// Enabled returns whether the filter may exclude any family.
+func (f FamilyFilter) Enabled() bool {
+	return len(f.Include) > 0 || len(f.Exclude) > 0
+}
+
+// Applies returns whether the family of the statement is selected by the
+// filter. Control statements, and statements which don't name a family's
+// table, are always applied.
+func (f FamilyFilter) Applies(statement string) bool {
+	if !f.Enabled() {
+		return true
+	}
+	family, ok := statementFamily(statement)
+	if !ok {
+		return true
+	}
+	return f.AppliesToFamily(family)
+}
+
+// AppliesToFamily returns whether the family is selected by the filter.
+func (f FamilyFilter) AppliesToFamily(family string) bool {
+	for _, fam := range f.Exclude {
+		if fam == family {
+			return false
+		}
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, fam := range f.Include {
+		if fam == family {
+			return true
+		}
+	}
+	return false
+}
+
+// statementFamily returns the family whose table a ledger statement
+// modifies.
+func statementFamily(statement string) (string, bool) {
+	family, _, ok := statementTable(statement)
+	return family, ok
+}
+
+// statementTable returns the family and table that a ledger statement
+// modifies.
+func statementTable(statement string) (family, table string, ok bool) {
+	switch statement {
+	case schema.DMLTxBeginKey, schema.DMLTxEndKey:
+		return "", "", false
+	}
+	m := ledgerTableName.FindStringSubmatch(statement)
+	if m == nil {
+		return "", "", false
+	}
+	return strings.ToLower(m[1]), strings.ToLower(m[2]), true
+}
@@ -0,0 +1,74 @@
+package ldbwriter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/ldb"
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+func TestStatementFamily(t *testing.T) {
+	for _, test := range []struct {
+		statement string
+		family    string
+	}{
+		{`CREATE TABLE family1___table1 ("key" VARCHAR(191), PRIMARY KEY("key"));`, "family1"},
+		{`REPLACE INTO family1___table1 ("key","val") VALUES('a___b',1)`, "family1"},
+		{`DELETE FROM "my_family___table1" WHERE "key" = 'x'`, "my_family"},
+		{`ALTER TABLE family1___table1 RENAME TO _rebuild_family1___table1`, "family1"},
+		{`INSERT INTO family1___table1 SELECT * FROM _rebuild_family1___table1`, "family1"},
+		{`CREATE TABLE _rebuild_family1___table1 ("key" VARCHAR(191), PRIMARY KEY("key"));`, "family1"},
+		{`INSERT INTO _rebuild_family1___table1 SELECT * FROM family1___table1`, "family1"},
+		{`--- V2 {"sql":"REPLACE INTO family2___table1 (\"key\") VALUES(?)","args":["x"]}`, "family2"},
+		{schema.DMLTxBeginKey, ""},
+		{`CREATE TABLE foo (bar VARCHAR);`, ""},
+	} {
+		family, ok := statementFamily(test.statement)
+		require.Equal(t, test.family != "", ok, test.statement)
+		require.Equal(t, test.family, family, test.statement)
+	}
+}
+
+func TestFamilyFilter(t *testing.T) {
+	require.True(t, FamilyFilter{}.AppliesToFamily("family1"))
+
+	include := FamilyFilter{Include: []string{"family1"}}
+	require.True(t, include.AppliesToFamily("family1"))
+	require.False(t, include.AppliesToFamily("family2"))
+
+	exclude := FamilyFilter{Exclude: []string{"family1"}}
+	require.False(t, exclude.AppliesToFamily("family1"))
+	require.True(t, exclude.AppliesToFamily("family2"))
+	require.True(t, exclude.Applies(`CREATE TABLE foo (bar VARCHAR);`), "statements without a family are applied")
+}
+
+func TestApplyDMLStatementFamilyFilter(t *testing.T) {
+	db, teardown := ldb.LDBForTest(t)
+	defer teardown()
+	ctx := context.Background()
+	writer := SqlLdbWriter{Db: db, Families: FamilyFilter{Exclude: []string{"family2"}}}
+
+	for _, st := range []string{
+		`CREATE TABLE family1___table1 (val VARCHAR);`,
+		`CREATE TABLE family2___table1 (val VARCHAR);`,
+		`INSERT INTO family1___table1 VALUES('hello');`,
+	} {
+		require.NoError(t, writer.ApplyDMLStatement(ctx, schema.NewTestDMLStatement(st)))
+	}
+	last := schema.NewTestDMLStatement(`INSERT INTO family2___table1 VALUES('hello');`)
+	require.NoError(t, writer.ApplyDMLStatement(ctx, last))
+
+	var n int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'family2___table1'").Scan(&n))
+	require.Equal(t, 0, n)
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM family1___table1").Scan(&n))
+	require.Equal(t, 1, n)
+
+	// the sequence of filtered statements is still recorded
+	seq, err := ldb.FetchSeqFromLdb(ctx, db)
+	require.NoError(t, err)
+	require.Equal(t, last.Sequence, seq)
+}
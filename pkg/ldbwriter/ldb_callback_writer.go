@@ -3,6 +3,8 @@ package ldbwriter
 import (
 	"context"
 	"database/sql"
+	"io"
+	"time"
 
 	"github.com/segmentio/ctlstore/pkg/schema"
 	"github.com/segmentio/ctlstore/pkg/sqlite"
@@ -11,26 +13,121 @@ import (
 
 // CallbackWriter is an LDBWriter that delegates to another
 // writer and then, upon a successful write, executes N callbacks.
+//
+// If the delegate leaves statements uncommitted, as an SqlLdbWriter does
+// within a ledger transaction or a group commit batch, the callbacks for
+// them are held until they're committed, and dropped if they're rolled
+// back instead, so that callbacks are only told of changes readers can see.
 type CallbackWriter struct {
 	DB           *sql.DB
 	Delegate     LDBWriter
 	Callbacks    []LDBWriteCallback
 	ChangeBuffer *sqlite.SQLChangeBuffer
+	// Observer is told about each statement that's applied. Its duration
+	// includes the callbacks, and its rows changed are those the callbacks
+	// are told of. The delegate's filtering and commits aren't known, so
+	// an SqlLdbWriter delegate's own observer has more detail.
+	Observer ApplyObserver // optional
+
+	// the writes which haven't been committed yet
+	pending []LDBWriteMetadata
+}
+
+// transactionalWriter is implemented by writers which don't commit every
+// statement as it's applied.
+type transactionalWriter interface {
+	InTransaction() bool
+	Flush() error
 }
 
 func (w *CallbackWriter) ApplyDMLStatement(ctx context.Context, statement schema.DMLStatement) error {
+	if w.Observer == nil {
+		_, err := w.applyDMLStatement(ctx, statement)
+		return err
+	}
+	start := time.Now()
+	info := newApplyInfo(statement)
+	changes, err := w.applyDMLStatement(ctx, statement)
+	info.Duration = time.Since(start)
+	if err != nil {
+		info.Err = err
+	} else {
+		info.Outcome = ApplyExecuted
+		if statement.Statement == schema.DMLTxBeginKey || statement.Statement == schema.DMLTxEndKey {
+			info.Outcome = ApplyControl
+		}
+		info.RowsChanged = int64(changes)
+	}
+	w.Observer.StatementApplied(ctx, info)
+	return err
+}
+
+// applyDMLStatement applies the statement and runs the callbacks, returning
+// the number of changes they were told of.
+func (w *CallbackWriter) applyDMLStatement(ctx context.Context, statement schema.DMLStatement) (int, error) {
 	err := w.Delegate.ApplyDMLStatement(ctx, statement)
 	if err != nil {
-		return err
+		// the delegate rolls back whatever it hadn't committed
+		w.ChangeBuffer.Pop()
+		w.pending = nil
+		return 0, err
 	}
 	changes := w.ChangeBuffer.Pop()
-	for _, callback := range w.Callbacks {
-		events.Debug("Writing DML callback for %{cb}T", callback)
-		callback.LDBWritten(ctx, LDBWriteMetadata{
-			DB:        w.DB,
-			Statement: statement,
-			Changes:   changes,
-		})
+	w.pending = append(w.pending, LDBWriteMetadata{
+		DB:        w.DB,
+		Statement: statement,
+		Changes:   changes,
+	})
+	w.runCallbacks(ctx)
+	return len(changes), nil
+}
+
+// InTransaction reports whether the delegate has statements which it hasn't
+// committed yet.
+func (w *CallbackWriter) InTransaction() bool {
+	tw, ok := w.Delegate.(transactionalWriter)
+	return ok && tw.InTransaction()
+}
+
+// Flush commits the statements the delegate has batched, if it batches
+// them, and runs the callbacks for them.
+func (w *CallbackWriter) Flush() error {
+	tw, ok := w.Delegate.(transactionalWriter)
+	if !ok {
+		return nil
+	}
+	if err := tw.Flush(); err != nil {
+		w.pending = nil
+		return err
 	}
+	w.runCallbacks(context.Background())
 	return nil
 }
+
+// Close closes the delegate, once the callbacks have been run for the
+// statements it had batched.
+func (w *CallbackWriter) Close() error {
+	err := w.Flush()
+	w.pending = nil
+	if c, ok := w.Delegate.(io.Closer); ok {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// runCallbacks tells the callbacks about the pending writes once they've
+// been committed.
+func (w *CallbackWriter) runCallbacks(ctx context.Context) {
+	if w.InTransaction() {
+		return
+	}
+	for _, meta := range w.pending {
+		for _, callback := range w.Callbacks {
+			events.Debug("Writing DML callback for %{cb}T", callback)
+			callback.LDBWritten(ctx, meta)
+		}
+	}
+	w.pending = nil
+}
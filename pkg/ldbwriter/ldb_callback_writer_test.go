@@ -0,0 +1,72 @@
+package ldbwriter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/ldb"
+	"github.com/segmentio/ctlstore/pkg/schema"
+	"github.com/segmentio/ctlstore/pkg/sqlite"
+)
+
+type recordingCallback struct {
+	statements []string
+}
+
+func (c *recordingCallback) LDBWritten(ctx context.Context, data LDBWriteMetadata) {
+	c.statements = append(c.statements, data.Statement.Statement)
+}
+
+func TestCallbackWriterGroupCommit(t *testing.T) {
+	db, teardown := ldb.LDBForTest(t)
+	defer teardown()
+	ctx := context.Background()
+	_, err := db.Exec("CREATE TABLE fam___foo (bar VARCHAR)")
+	require.NoError(t, err)
+
+	callback := &recordingCallback{}
+	writer := &CallbackWriter{
+		DB:           db,
+		Delegate:     &SqlLdbWriter{Db: db, GroupCommit: GroupCommit{MaxStatements: 3}},
+		Callbacks:    []LDBWriteCallback{callback},
+		ChangeBuffer: &sqlite.SQLChangeBuffer{},
+	}
+	defer writer.Close()
+	apply := func(statement string) error {
+		return writer.ApplyDMLStatement(ctx, schema.NewTestDMLStatement(statement))
+	}
+
+	require.NoError(t, apply("INSERT INTO fam___foo VALUES('a')"))
+	require.NoError(t, apply("INSERT INTO fam___foo VALUES('b')"))
+	require.True(t, writer.InTransaction())
+	require.Empty(t, callback.statements, "callbacks run before the batch committed")
+
+	// the third statement commits the batch
+	require.NoError(t, apply("INSERT INTO fam___foo VALUES('c')"))
+	require.False(t, writer.InTransaction())
+	require.Equal(t, []string{
+		"INSERT INTO fam___foo VALUES('a')",
+		"INSERT INTO fam___foo VALUES('b')",
+		"INSERT INTO fam___foo VALUES('c')",
+	}, callback.statements)
+
+	callback.statements = nil
+	require.NoError(t, apply("INSERT INTO fam___foo VALUES('d')"))
+	require.Empty(t, callback.statements)
+	require.NoError(t, writer.Flush())
+	require.Equal(t, []string{"INSERT INTO fam___foo VALUES('d')"}, callback.statements)
+
+	t.Run("rolled back statements", func(t *testing.T) {
+		callback.statements = nil
+		require.NoError(t, apply("INSERT INTO fam___foo VALUES('e')"))
+		require.Error(t, apply("INSERT INTO fam___nope VALUES('f')"))
+		require.NoError(t, writer.Flush())
+		require.Empty(t, callback.statements)
+
+		require.NoError(t, apply("INSERT INTO fam___foo VALUES('g')"))
+		require.NoError(t, writer.Close())
+		require.Equal(t, []string{"INSERT INTO fam___foo VALUES('g')"}, callback.statements)
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
+
 	"github.com/pkg/errors"
 	"github.com/segmentio/events/v2"
 	"github.com/segmentio/stats/v4"
@@ -40,27 +42,105 @@ type SqlLdbWriter struct {
 	// uniquely identify this SqlWriter
 	Logger *events.Logger
 	ID     string
+	// GroupCommit batches statements into fewer LDB transactions
+	GroupCommit GroupCommit // optional
+	// Families selects the families whose statements are executed. The
+	// sequence of a statement that isn't is still recorded.
+	Families FamilyFilter // optional
+	// Guard refuses statements, which are quarantined rather than executed.
+	// Their sequence is still recorded.
+	Guard StatementGuard // optional
+	// StatementTimeout interrupts the execution of a statement which takes
+	// longer, so that a pathological statement fails rather than stalling
+	// the writer. Zero doesn't bound it.
+	StatementTimeout time.Duration // optional
+	// SlowStatementThreshold logs the statements which take at least this
+	// long to execute. Zero doesn't log them.
+	SlowStatementThreshold time.Duration // optional
+	// Observer is told about each statement that's applied
+	Observer ApplyObserver // optional
+
+	// the newest ledger timestamp written to the last update table
+	lastTimestamp time.Time
+
+	// the open group commit transaction, which any ledger transaction is
+	// applied within
+	batchTx         *sql.Tx
+	batchStatements int
+	batchStarted    time.Time
 }
 
+// GroupCommit configures an SqlLdbWriter to apply statements in batches,
+// committing one LDB transaction per batch rather than per statement, which
+// saves an fsync for each statement while the reflector is catching up.
+//
+// A batch is committed once it holds MaxStatements statements or has been
+// open for MaxDelay, but never within a ledger transaction, and otherwise
+// when Flush is called. Until then, its statements aren't visible to
+// readers. If a statement fails to apply, the whole batch is rolled back,
+// so the LDB's sequence never moves past a statement that wasn't applied.
+type GroupCommit struct {
+	// MaxStatements of 0 or 1 disables group commit
+	MaxStatements int
+	// MaxDelay of 0 doesn't bound how long a batch stays open
+	MaxDelay time.Duration
+}
+
+func (g GroupCommit) enabled() bool {
+	return g.MaxStatements > 1
+}
+
+// Upstream timestamps further than this ahead of the local clock are
+// considered skewed.
+const maxLedgerClockSkew = time.Minute
+
+// Slow statements are logged up to this many bytes, since they're often
+// slow because they're huge.
+const maxSlowStatementLogSize = 1024
+
 // Applies a DML statement to the writer's db, updating the sequence
 // tracking table in the same transaction
-func (w *SqlLdbWriter) ApplyDMLStatement(_ context.Context, statement schema.DMLStatement) error {
+func (w *SqlLdbWriter) ApplyDMLStatement(ctx context.Context, statement schema.DMLStatement) error {
+	if w.Observer == nil {
+		return w.applyDMLStatement(ctx, statement, &ApplyInfo{})
+	}
+	start := time.Now()
+	info := newApplyInfo(statement)
+	err := w.applyDMLStatement(ctx, statement, &info)
+	info.Duration = time.Since(start)
+	if err != nil {
+		info.Outcome, info.RowsChanged, info.Committed, info.Err = "", 0, false, err
+	}
+	w.Observer.StatementApplied(ctx, info)
+	return err
+}
+
+// applyDMLStatement applies the statement, filling in the outcome of info.
+func (w *SqlLdbWriter) applyDMLStatement(ctx context.Context, statement schema.DMLStatement, info *ApplyInfo) error {
 	var tx *sql.Tx
 	var err error
 
 	stats.Incr("sql_ldb_writer.apply", stats.T("id", w.ID))
 
 	// Fill in the tx var
-	if w.LedgerTx == nil {
+	switch {
+	case w.LedgerTx != nil:
+		// Applying a ledger transaction, so bring it into scope
+		tx = w.LedgerTx
+	case w.batchTx != nil:
+		// Adding to a group commit
+		tx = w.batchTx
+	default:
 		// Not applying a ledger transaction, so need a local transaction
 		tx, err = w.Db.Begin()
 		if err != nil {
 			errs.Incr("sql_ldb_writer.begin_tx.error", stats.T("id", w.ID))
 			return errors.Wrap(err, "open tx error")
 		}
-	} else {
-		// Applying a ledger transaction, so bring it into scope
-		tx = w.LedgerTx
+		if w.GroupCommit.enabled() {
+			w.batchTx = tx
+			w.batchStarted = time.Now()
+		}
 	}
 	logger := w.logger()
 
@@ -70,7 +150,7 @@ func (w *SqlLdbWriter) ApplyDMLStatement(_ context.Context, statement schema.DML
 			// Attempted to open a transaction without committing the last one,
 			// which is a violation of our invariants. Something is very, very
 			// wrong with the ledger processing.
-			tx.Rollback()
+			w.rollback(tx)
 			errs.Incr("sql_ldb_writer.ledgerTx.begin_invariant_violation", stats.T("id", w.ID))
 			return errors.New("invariant violation")
 		}
@@ -81,12 +161,18 @@ func (w *SqlLdbWriter) ApplyDMLStatement(_ context.Context, statement schema.DML
 	// Update the last update table.  This will allow the ldb reader
 	// the ability to calculate how up to date the ldb is by
 	// subtracting wall time from that value.
+	timestamp, err := w.ledgerTimestamp(tx, statement)
+	if err != nil {
+		w.rollback(tx)
+		errs.Incr("sql_ldb_writer.read_last_update.error", stats.T("id", w.ID))
+		return errors.Wrap(err, "read last_update")
+	}
 	qs := fmt.Sprintf(
 		"REPLACE INTO %s (name, timestamp) VALUES (?, ?)",
 		ldb.LDBLastUpdateTableName)
-	_, err = tx.Exec(qs, ldb.LDBLastLedgerUpdateColumn, statement.Timestamp)
+	_, err = tx.Exec(qs, ldb.LDBLastLedgerUpdateColumn, timestamp)
 	if err != nil {
-		tx.Rollback()
+		w.rollback(tx)
 		errs.Incr("sql_ldb_writer.upsert_last_update.error", stats.T("id", w.ID))
 		return errors.Wrap(err, "update last_update")
 	}
@@ -102,10 +188,10 @@ func (w *SqlLdbWriter) ApplyDMLStatement(_ context.Context, statement schema.DML
 			"(NOT EXISTS (SELECT * FROM %[1]s WHERE id = %[2]d)) OR "+
 			"((SELECT seq FROM %[1]s WHERE id = %[2]d) < $1)",
 		ldb.LDBSeqTableName,
-		ldb.LDBSeqTableID)
+		ldb.SeqTableIDForLedger(statement.LedgerID))
 	res, err := tx.Exec(qs, statement.Sequence.Int())
 	if err != nil {
-		tx.Rollback()
+		w.rollback(tx)
 		errs.Incr("sql_ldb_writer.upsert_seq.error", stats.T("id", w.ID))
 		return errors.Wrap(err, "update seq tracker error")
 	}
@@ -113,12 +199,12 @@ func (w *SqlLdbWriter) ApplyDMLStatement(_ context.Context, statement schema.DML
 	// Check for replayed statements
 	rowsAffected, err := res.RowsAffected()
 	if err != nil {
-		tx.Rollback()
+		w.rollback(tx)
 		errs.Incr("sql_ldb_writer.upsert_seq.rows_affected_error", stats.T("id", w.ID))
 		return errors.Wrap(err, "update seq tracker rows affected error")
 	}
 	if rowsAffected == 0 {
-		tx.Rollback()
+		w.rollback(tx)
 		errs.Incr("sql_ldb_writer.upsert_seq.replay_detected", stats.T("id", w.ID))
 		return errors.New("update seq tracker replay detected")
 	}
@@ -128,6 +214,7 @@ func (w *SqlLdbWriter) ApplyDMLStatement(_ context.Context, statement schema.DML
 	// that it allows the sequence tracker row to be updated after the
 	// transaction is opened.
 	if statement.Statement == schema.DMLTxBeginKey {
+		info.Outcome = ApplyControl
 		return nil
 	}
 
@@ -137,14 +224,22 @@ func (w *SqlLdbWriter) ApplyDMLStatement(_ context.Context, statement schema.DML
 			// Attempted to commit a transaction when there is no transaction
 			// open, which is a violation of our invariants. Something is very,
 			// very wrong with the ledger processing!
-			tx.Rollback()
+			w.rollback(tx)
 			errs.Incr("sql_ldb_writer.ledgerTx.end_invariant_violation", stats.T("id", w.ID))
 			return errors.New("invariant violation")
 		}
+		info.Outcome = ApplyControl
+
+		if w.batchTx != nil {
+			// the ledger transaction is committed along with the batch
+			w.LedgerTx = nil
+			logger.Debug("Batched TX at %{sequence}v", statement.Sequence)
+			return w.maybeCommitBatch(info)
+		}
 
 		err = tx.Commit()
 		if err != nil {
-			tx.Rollback()
+			w.rollback(tx)
 			errs.Incr("sql_ldb_writer.ledgerTx.commit.error", stats.T("id", w.ID))
 			logger.Log("Failed to commit Tx at seq %{seq}s: %{error}+v",
 				statement.Sequence,
@@ -154,33 +249,71 @@ func (w *SqlLdbWriter) ApplyDMLStatement(_ context.Context, statement schema.DML
 		stats.Incr("sql_ldb_writer.ledgerTx.commit.success", stats.T("id", w.ID))
 		logger.Debug("Committed TX at %{sequence}v", statement.Sequence)
 		w.LedgerTx = nil
+		info.Committed = true
 		return nil
 	}
 
 	// Execute non-control statements
-	_, err = tx.Exec(statement.Statement)
+	dml, err := statement.DML()
 	if err != nil {
-		tx.Rollback()
-		errs.Incr("sql_ldb_writer.exec.error", stats.T("id", w.ID))
-		return errors.Wrap(err, "exec dml statement error")
+		w.rollback(tx)
+		errs.Incr("sql_ldb_writer.decode.error", stats.T("id", w.ID))
+		return errors.Wrap(err, "decode dml statement error")
 	}
+	reason, allowed := w.Guard.Check(dml.SQL)
+	switch {
+	case !w.Families.Applies(dml.SQL):
+		stats.Incr("sql_ldb_writer.exec.filtered", stats.T("id", w.ID))
+		info.Outcome = ApplyFiltered
+	case !allowed:
+		err = w.quarantine(tx, statement, reason)
+		if err != nil {
+			w.rollback(tx)
+			errs.Incr("sql_ldb_writer.quarantine.error", stats.T("id", w.ID))
+			return errors.Wrap(err, "quarantine dml statement error")
+		}
+
+		stats.Incr("sql_ldb_writer.exec.quarantined", stats.T("id", w.ID), stats.T("reason", reason))
+		info.Outcome = ApplyQuarantined
+
+		logger.Log("Quarantined DML[%{sequence}d] (%{reason}s): '%{statement}s'",
+			statement.Sequence,
+			reason,
+			statement.Statement)
+	default:
+		info.RowsChanged, err = w.execStatement(ctx, tx, statement, dml)
+		if err != nil {
+			w.rollback(tx)
+			errs.Incr("sql_ldb_writer.exec.error", stats.T("id", w.ID))
+			return errors.Wrap(err, "exec dml statement error")
+		}
 
-	stats.Incr("sql_ldb_writer.exec.success", stats.T("id", w.ID))
+		stats.Incr("sql_ldb_writer.exec.success", stats.T("id", w.ID))
+		info.Outcome = ApplyExecuted
 
-	logger.Debug("Applying DML[%{sequence}d]: '%{statement}s'",
-		statement.Sequence,
-		statement.Statement)
+		logger.Debug("Applying DML[%{sequence}d]: '%{statement}s'",
+			statement.Sequence,
+			statement.Statement)
+	}
+
+	if w.batchTx != nil {
+		if w.LedgerTx != nil {
+			return nil
+		}
+		return w.maybeCommitBatch(info)
+	}
 
 	// Commit if not inside a ledger transaction, since that would be
 	// a single statement transaction.
 	if w.LedgerTx == nil {
 		err = tx.Commit()
 		if err != nil {
-			tx.Rollback()
+			w.rollback(tx)
 			errs.Incr("sql_ldb_writer.single.commit.error", stats.T("id", w.ID))
 			errs.Incr("sql_ldb_writer.commit.error", stats.T("id", w.ID))
 			return errors.Wrap(err, "commit one-statement dml tx error")
 		}
+		info.Committed = true
 	}
 
 	stats.Incr("sql_ldb_writer.commit.success", stats.T("id", w.ID))
@@ -188,12 +321,154 @@ func (w *SqlLdbWriter) ApplyDMLStatement(_ context.Context, statement schema.DML
 	return nil
 }
 
+// maybeCommitBatch counts a statement that was added to the group commit,
+// and commits the batch if it's full or has been open for too long.
+func (w *SqlLdbWriter) maybeCommitBatch(info *ApplyInfo) error {
+	w.batchStatements++
+	if w.batchStatements < w.GroupCommit.MaxStatements &&
+		(w.GroupCommit.MaxDelay == 0 || time.Since(w.batchStarted) < w.GroupCommit.MaxDelay) {
+		return nil
+	}
+	if err := w.commitBatch(); err != nil {
+		return err
+	}
+	info.Committed = true
+	return nil
+}
+
+func (w *SqlLdbWriter) commitBatch() error {
+	tx, n := w.batchTx, w.batchStatements
+	w.batchTx, w.batchStatements = nil, 0
+	err := tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		errs.Incr("sql_ldb_writer.batch.commit.error", stats.T("id", w.ID))
+		errs.Incr("sql_ldb_writer.commit.error", stats.T("id", w.ID))
+		return errors.Wrap(err, "commit group commit tx error")
+	}
+	stats.Observe("sql_ldb_writer.batch.statements", n, stats.T("id", w.ID))
+	stats.Incr("sql_ldb_writer.commit.success", stats.T("id", w.ID))
+	return nil
+}
+
+// Flush commits the open group commit batch, unless a ledger transaction
+// is being applied, in which case the batch is committed along with it.
+func (w *SqlLdbWriter) Flush() error {
+	if w.batchTx == nil || w.LedgerTx != nil {
+		return nil
+	}
+	return w.commitBatch()
+}
+
+// InTransaction reports whether a ledger transaction or a group commit batch
+// is open, in which case statements applied to the LDB by anything but the
+// writer would wait on it to be committed.
+func (w *SqlLdbWriter) InTransaction() bool {
+	return w.LedgerTx != nil || w.batchTx != nil
+}
+
+// rollback rolls back tx, along with the group commit batch if tx is it.
+func (w *SqlLdbWriter) rollback(tx *sql.Tx) {
+	tx.Rollback()
+	if tx == w.batchTx {
+		w.batchTx, w.batchStatements = nil, 0
+	}
+}
+
+// quarantine records a statement that the guard refused in the LDB, in
+// place of executing it.
+func (w *SqlLdbWriter) quarantine(tx *sql.Tx, statement schema.DMLStatement, reason string) error {
+	qs := fmt.Sprintf(
+		"REPLACE INTO %s (ledger_id, seq, statement, reason, timestamp) VALUES (?, ?, ?, ?, ?)",
+		ldb.LDBQuarantineTableName)
+	_, err := tx.Exec(qs, statement.LedgerID, statement.Sequence.Int(), statement.Statement, reason, time.Now().UTC())
+	return err
+}
+
+// execStatement executes a ledger statement, decoded as dml, within the
+// statement timeout, observing how long it took per table and logging it if
+// it was slow. It returns the number of rows the statement changed.
+func (w *SqlLdbWriter) execStatement(ctx context.Context, tx *sql.Tx, statement schema.DMLStatement, dml schema.ParameterizedDML) (int64, error) {
+	if w.StatementTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.StatementTimeout)
+		defer cancel()
+	}
+	table := "unknown"
+	if family, tbl, ok := statementTable(dml.SQL); ok {
+		table = family + "___" + tbl
+	}
+
+	start := time.Now()
+	rows, err := execDML(ctx, tx, dml)
+	elapsed := time.Since(start)
+	stats.Observe("sql_ldb_writer.exec.duration", elapsed, stats.T("id", w.ID), stats.T("table", table))
+
+	if w.SlowStatementThreshold > 0 && elapsed >= w.SlowStatementThreshold {
+		stats.Incr("sql_ldb_writer.exec.slow", stats.T("id", w.ID), stats.T("table", table))
+		logged := statement.Statement
+		if len(logged) > maxSlowStatementLogSize {
+			logged = logged[:maxSlowStatementLogSize] + "..."
+		}
+		w.logger().Log("Slow DML[%{sequence}d] on %{table}s took %{duration}v: '%{statement}s'",
+			statement.Sequence, table, elapsed, logged)
+	}
+	if err != nil && w.StatementTimeout > 0 && ctx.Err() == context.DeadlineExceeded {
+		errs.Incr("sql_ldb_writer.exec.timeout", stats.T("id", w.ID), stats.T("table", table))
+		return 0, errors.Wrapf(err, "statement timed out after %v", w.StatementTimeout)
+	}
+	return rows, err
+}
+
+// execDML executes a decoded ledger statement, and returns the number of
+// rows it changed.
+func execDML(ctx context.Context, tx *sql.Tx, dml schema.ParameterizedDML) (int64, error) {
+	res, err := tx.ExecContext(ctx, dml.SQL, dml.Args...)
+	if err != nil {
+		return 0, err
+	}
+	// SQLite always knows, and DDL changes no rows
+	rows, _ := res.RowsAffected()
+	return rows, nil
+}
+
+// ledgerTimestamp returns the timestamp to record as the last ledger update
+// for the statement. It never moves backwards, which would otherwise happen
+// after a ctldb failover to a leader whose clock is behind, and it never
+// runs ahead of the local clock, so that ledger latency stays positive.
+func (w *SqlLdbWriter) ledgerTimestamp(tx *sql.Tx, statement schema.DMLStatement) (time.Time, error) {
+	if w.lastTimestamp.IsZero() {
+		row := tx.QueryRow("SELECT timestamp FROM "+ldb.LDBLastUpdateTableName+" WHERE name = ?", ldb.LDBLastLedgerUpdateColumn)
+		err := row.Scan(&w.lastTimestamp)
+		if err != nil && err != sql.ErrNoRows {
+			return time.Time{}, err
+		}
+	}
+
+	ts := statement.Timestamp
+	if now := time.Now(); ts.After(now) {
+		skew := ts.Sub(now)
+		stats.Observe("sql_ldb_writer.ledger_timestamp.future_skew", skew, stats.T("id", w.ID))
+		if skew > maxLedgerClockSkew {
+			stats.Incr("sql_ldb_writer.ledger_timestamp.skewed", stats.T("id", w.ID))
+			w.logger().Log("Ledger timestamp at seq %{seq}d is %{skew}v ahead of the local clock", statement.Sequence, skew)
+		}
+		ts = now
+	}
+	if ts.Before(w.lastTimestamp) {
+		stats.Observe("sql_ldb_writer.ledger_timestamp.backwards", w.lastTimestamp.Sub(ts), stats.T("id", w.ID))
+		ts = w.lastTimestamp
+	}
+	w.lastTimestamp = ts
+	return ts, nil
+}
+
 func (w *SqlLdbWriter) Close() error {
 	if w.LedgerTx != nil {
-		w.LedgerTx.Rollback()
+		w.rollback(w.LedgerTx)
 		w.LedgerTx = nil
 	}
-	return nil
+	return w.Flush()
 }
 
 // PragmaWALResult https://www.sqlite.org/pragma.html#pragma_wal_checkpoint
@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/segmentio/events/v2"
 	"github.com/stretchr/testify/require"
 
 	"github.com/segmentio/ctlstore/pkg/ldb"
@@ -77,6 +78,50 @@ func TestApplyDMLStatement(t *testing.T) {
 	}
 }
 
+func TestApplyDMLStatementParameterized(t *testing.T) {
+	db, teardown := ldb.LDBForTest(t)
+	defer teardown()
+	ctx := context.Background()
+	writer := SqlLdbWriter{Db: db}
+
+	err := writer.ApplyDMLStatement(ctx, schema.NewTestDMLStatement("CREATE TABLE foo (bar VARCHAR, baz BLOB);"))
+	require.NoError(t, err)
+
+	statement, err := schema.ParameterizedDML{
+		SQL:  "INSERT INTO foo VALUES(?, ?)",
+		Args: []interface{}{"it's a\x00b", []byte{1, 2, 3}},
+	}.Encode()
+	require.NoError(t, err)
+	dmlStatement := schema.NewTestDMLStatement(statement)
+	dmlStatement.Version = schema.DMLVersionParameterized
+	err = writer.ApplyDMLStatement(ctx, dmlStatement)
+	require.NoError(t, err)
+
+	var bar string
+	var baz []byte
+	err = db.QueryRow("SELECT bar, baz FROM foo").Scan(&bar, &baz)
+	require.NoError(t, err)
+	require.Equal(t, "it's a\x00b", bar)
+	require.Equal(t, []byte{1, 2, 3}, baz)
+
+	dmlStatement = schema.NewTestDMLStatement("{")
+	dmlStatement.Version = schema.DMLVersionParameterized
+	err = writer.ApplyDMLStatement(ctx, dmlStatement)
+	require.Error(t, err)
+
+	// statements of versions this reflector doesn't know are refused
+	// rather than executed as SQL
+	dmlStatement = schema.NewTestDMLStatement("INSERT INTO foo VALUES('x', NULL)")
+	dmlStatement.Version = schema.DMLVersionParameterized + 1
+	err = writer.ApplyDMLStatement(ctx, dmlStatement)
+	require.Error(t, err)
+
+	// reflectors which predate statement versions execute them as SQL,
+	// which must fail rather than doing nothing
+	_, err = db.Exec(statement)
+	require.Error(t, err)
+}
+
 func TestApplyDMLStatementMonotonic(t *testing.T) {
 	var err error
 
@@ -202,6 +247,71 @@ func TestApplyDMLStatementTransaction(t *testing.T) {
 	}
 }
 
+func TestApplyDMLStatementGroupCommit(t *testing.T) {
+	db, teardown := ldb.LDBForTest(t)
+	defer teardown()
+	ctx := context.Background()
+	_, err := db.Exec("CREATE TABLE foo (bar VARCHAR)")
+	require.NoError(t, err)
+	writer := SqlLdbWriter{Db: db, GroupCommit: GroupCommit{MaxStatements: 4}}
+	defer writer.Close()
+
+	count := func() int {
+		var cnt int
+		require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM foo").Scan(&cnt))
+		return cnt
+	}
+	apply := func(statement string) schema.DMLSequence {
+		st := schema.NewTestDMLStatement(statement)
+		require.NoError(t, writer.ApplyDMLStatement(ctx, st))
+		return st.Sequence
+	}
+
+	apply("INSERT INTO foo VALUES('a')")
+	apply("INSERT INTO foo VALUES('b')")
+	require.Equal(t, 0, count(), "batch committed early")
+
+	// the ledger transaction counts as one statement, and the batch isn't
+	// committed within it
+	apply(schema.DMLTxBeginKey)
+	apply("INSERT INTO foo VALUES('c')")
+	apply("INSERT INTO foo VALUES('d')")
+	require.Equal(t, 0, count(), "batch committed within a ledger transaction")
+	apply(schema.DMLTxEndKey)
+	require.Equal(t, 0, count(), "batch committed early")
+
+	lastSeq := apply("INSERT INTO foo VALUES('e')")
+	require.Equal(t, 5, count())
+	seq, err := ldb.FetchSeqFromLdb(ctx, db)
+	require.NoError(t, err)
+	require.Equal(t, lastSeq, seq)
+
+	apply("INSERT INTO foo VALUES('f')")
+	require.Equal(t, 5, count())
+	require.NoError(t, writer.Flush())
+	require.Equal(t, 6, count())
+
+	t.Run("failed statements roll back the batch", func(t *testing.T) {
+		apply("INSERT INTO foo VALUES('g')")
+		err := writer.ApplyDMLStatement(ctx, schema.NewTestDMLStatement("INSERT INTO nope VALUES('h')"))
+		require.Error(t, err)
+		require.NoError(t, writer.Flush())
+		require.Equal(t, 6, count())
+
+		apply("INSERT INTO foo VALUES('i')")
+		require.NoError(t, writer.Flush())
+		require.Equal(t, 7, count())
+	})
+
+	t.Run("max delay", func(t *testing.T) {
+		writer.GroupCommit.MaxDelay = time.Millisecond
+		apply("INSERT INTO foo VALUES('j')")
+		time.Sleep(2 * time.Millisecond)
+		apply("INSERT INTO foo VALUES('k')")
+		require.Equal(t, 9, count())
+	})
+}
+
 func TestApplyDMLStatementAlreadyOpenTxFails(t *testing.T) {
 	var err error
 	db, teardown := ldb.LDBForTest(t)
@@ -453,3 +563,119 @@ func SimulateBusyCheckpointing(t *testing.T) {
 	fmt.Printf("File size: %d\n", fi.Size())
 
 }
+
+func TestApplyDMLStatementTracksSeqPerLedger(t *testing.T) {
+	db, teardown := ldb.LDBForTest(t)
+	defer teardown()
+	defer db.Close()
+	writer := SqlLdbWriter{Db: db}
+	ctx := context.Background()
+
+	_, err := db.Exec("CREATE TABLE foo___bar (x INTEGER)")
+	require.NoError(t, err)
+
+	for _, st := range []schema.DMLStatement{
+		{Sequence: 10, LedgerID: 0, Statement: "INSERT INTO foo___bar VALUES(1)"},
+		{Sequence: 3, LedgerID: 1, Statement: "INSERT INTO foo___bar VALUES(2)"},
+	} {
+		require.NoError(t, writer.ApplyDMLStatement(ctx, st))
+	}
+
+	seq, err := ldb.FetchSeqFromLdb(ctx, db)
+	require.NoError(t, err)
+	require.EqualValues(t, 10, seq)
+	seq, err = ldb.FetchLedgerSeqFromLdb(ctx, db, 1)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, seq)
+
+	// replays are still detected within a ledger
+	err = writer.ApplyDMLStatement(ctx, schema.DMLStatement{Sequence: 3, LedgerID: 1, Statement: "INSERT INTO foo___bar VALUES(3)"})
+	require.Error(t, err)
+}
+
+func TestApplyDMLStatementLedgerTimestampMonotonic(t *testing.T) {
+	db, teardown := ldb.LDBForTest(t)
+	defer teardown()
+	ctx := context.Background()
+
+	_, err := db.Exec("CREATE TABLE foo___bar (x INTEGER)")
+	require.NoError(t, err)
+
+	lastLedgerUpdate := func() time.Time {
+		var timestamp time.Time
+		row := db.QueryRowContext(ctx, "select timestamp from "+ldb.LDBLastUpdateTableName+" where name=?", ldb.LDBLastLedgerUpdateColumn)
+		require.NoError(t, row.Scan(&timestamp))
+		return timestamp
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	writer := &SqlLdbWriter{Db: db}
+	apply := func(seq int64, ts time.Time) {
+		require.NoError(t, writer.ApplyDMLStatement(ctx, schema.DMLStatement{
+			Sequence:  schema.DMLSequence(seq),
+			Statement: "INSERT INTO foo___bar VALUES(1)",
+			Timestamp: ts,
+		}))
+	}
+
+	apply(1, now.Add(-time.Minute))
+	require.True(t, now.Add(-time.Minute).Equal(lastLedgerUpdate()))
+
+	// a timestamp from before a failover doesn't move the last update back
+	apply(2, now.Add(-time.Hour))
+	require.True(t, now.Add(-time.Minute).Equal(lastLedgerUpdate()))
+
+	// nor does a new writer, which picks up where the LDB left off
+	writer = &SqlLdbWriter{Db: db}
+	apply(3, now.Add(-time.Hour))
+	require.True(t, now.Add(-time.Minute).Equal(lastLedgerUpdate()))
+
+	// timestamps from the future are capped at the local clock
+	apply(4, now.Add(time.Hour))
+	require.False(t, lastLedgerUpdate().After(time.Now()))
+}
+
+func TestApplyDMLStatementTimeout(t *testing.T) {
+	db, teardown := ldb.LDBForTest(t)
+	defer teardown()
+	ctx := context.Background()
+
+	_, err := db.Exec("CREATE TABLE foo___bar (x INTEGER)")
+	require.NoError(t, err)
+
+	var logged []string
+	writer := &SqlLdbWriter{
+		Db: db,
+		Logger: events.NewLogger(events.HandlerFunc(func(e *events.Event) {
+			// events reuses the message's memory
+			if !e.Debug {
+				logged = append(logged, string([]byte(e.Message)))
+			}
+		})),
+		StatementTimeout:       100 * time.Millisecond,
+		SlowStatementThreshold: time.Nanosecond,
+	}
+
+	require.NoError(t, writer.ApplyDMLStatement(ctx, schema.DMLStatement{
+		Sequence:  1,
+		Statement: "INSERT INTO foo___bar VALUES(1)",
+	}))
+	require.Len(t, logged, 1)
+	require.Contains(t, logged[0], "Slow DML[1] on foo___bar")
+
+	// a statement which would run for a long time is interrupted, and
+	// isn't applied
+	err = writer.ApplyDMLStatement(ctx, schema.DMLStatement{
+		Sequence: 2,
+		Statement: "INSERT INTO foo___bar WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x+1 FROM c) " +
+			"SELECT x FROM c LIMIT 1000000000",
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "statement timed out after 100ms")
+	var count int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM foo___bar").Scan(&count))
+	require.Equal(t, 1, count)
+	var seq int64
+	require.NoError(t, db.QueryRow("SELECT seq FROM "+ldb.LDBSeqTableName).Scan(&seq))
+	require.EqualValues(t, 1, seq)
+}
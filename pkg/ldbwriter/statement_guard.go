@@ -0,0 +1,81 @@
+package ldbwriter
+
+import (
+	"path"
+	"regexp"
+
+	"github.com/pkg/errors"
+
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+// Reasons that a StatementGuard refuses a statement, which are recorded in
+// the LDB's quarantine table
+const (
+	GuardReasonDeniedTable     = "denied-table"
+	GuardReasonDeniedStatement = "denied-statement"
+	GuardReasonNotAllowed      = "not-allowed"
+)
+
+// StatementGuard is a safety net which refuses to apply ledger statements
+// that a bad writer could have used to damage the LDB. Refused statements
+// are skipped, so that the reflector keeps up with the ledger, but their
+// sequence is still recorded, and they're kept in the LDB's quarantine
+// table to be inspected and replayed by hand.
+//
+// Table patterns are "family.table" globs as in ChangelogFilter. The zero
+// value applies every statement.
+type StatementGuard struct {
+	// AllowTables, if set, lists patterns of the only tables whose
+	// statements are applied. Statements which don't name a family's table
+	// are refused too, which locks the LDB down to the families it serves.
+	AllowTables []string
+	// DenyTables lists patterns of tables whose statements are refused
+	DenyTables []string
+	// DenyStatements lists expressions which refuse any statement whose SQL
+	// they match
+	DenyStatements []*regexp.Regexp
+}
+
+// Validate checks that the table patterns are well formed.
+func (g StatementGuard) Validate() error {
+	for _, patterns := range [][]string{g.AllowTables, g.DenyTables} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, "family.table"); err != nil {
+				return errors.Wrapf(err, "invalid statement guard table pattern %q", pattern)
+			}
+		}
+	}
+	return nil
+}
+
+// Enabled returns whether the guard may refuse any statement.
+func (g StatementGuard) Enabled() bool {
+	return len(g.AllowTables) > 0 || len(g.DenyTables) > 0 || len(g.DenyStatements) > 0
+}
+
+// Check returns whether the SQL of a statement may be applied, and if it
+// may not, the reason that it's refused. Control statements are always
+// applied.
+func (g StatementGuard) Check(statement string) (reason string, ok bool) {
+	if !g.Enabled() {
+		return "", true
+	}
+	switch statement {
+	case schema.DMLTxBeginKey, schema.DMLTxEndKey:
+		return "", true
+	}
+	for _, re := range g.DenyStatements {
+		if re.MatchString(statement) {
+			return GuardReasonDeniedStatement, false
+		}
+	}
+	family, table, named := statementTable(statement)
+	if named && matchesAny(g.DenyTables, family+"."+table) {
+		return GuardReasonDeniedTable, false
+	}
+	if len(g.AllowTables) > 0 && !(named && matchesAny(g.AllowTables, family+"."+table)) {
+		return GuardReasonNotAllowed, false
+	}
+	return "", true
+}
@@ -0,0 +1,101 @@
+package ldbwriter
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/ldb"
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+func TestStatementGuard(t *testing.T) {
+	reason, ok := StatementGuard{}.Check(`DROP TABLE family1___table1`)
+	require.True(t, ok)
+	require.Empty(t, reason)
+
+	deny := StatementGuard{
+		DenyTables:     []string{"family1.secrets"},
+		DenyStatements: []*regexp.Regexp{regexp.MustCompile(`(?i)^\s*DROP\s`)},
+	}
+	for _, test := range []struct {
+		statement string
+		reason    string
+	}{
+		{`REPLACE INTO family1___table1 ("key") VALUES('a')`, ""},
+		{`REPLACE INTO family1___secrets ("key") VALUES('a')`, GuardReasonDeniedTable},
+		{`--- V2 {"sql":"DELETE FROM family1___secrets WHERE \"key\" = ?","args":["x"]}`, GuardReasonDeniedTable},
+		{`drop table family1___table1`, GuardReasonDeniedStatement},
+		{`CREATE TABLE foo (bar VARCHAR);`, ""},
+		{schema.DMLTxBeginKey, ""},
+	} {
+		reason, ok := deny.Check(test.statement)
+		require.Equal(t, test.reason == "", ok, test.statement)
+		require.Equal(t, test.reason, reason, test.statement)
+	}
+
+	allow := StatementGuard{AllowTables: []string{"family1.*"}}
+	for _, test := range []struct {
+		statement string
+		reason    string
+	}{
+		{`REPLACE INTO family1___table1 ("key") VALUES('a')`, ""},
+		{`REPLACE INTO family2___table1 ("key") VALUES('a')`, GuardReasonNotAllowed},
+		{`CREATE TABLE foo (bar VARCHAR);`, GuardReasonNotAllowed},
+		{schema.DMLTxEndKey, ""},
+	} {
+		reason, ok := allow.Check(test.statement)
+		require.Equal(t, test.reason == "", ok, test.statement)
+		require.Equal(t, test.reason, reason, test.statement)
+	}
+
+	require.NoError(t, allow.Validate())
+	require.Error(t, StatementGuard{DenyTables: []string{"family1.[table"}}.Validate())
+}
+
+func TestApplyDMLStatementGuard(t *testing.T) {
+	db, teardown := ldb.LDBForTest(t)
+	defer teardown()
+	ctx := context.Background()
+	writer := SqlLdbWriter{Db: db, Guard: StatementGuard{DenyTables: []string{"family1.table2"}}}
+
+	for _, st := range []string{
+		`CREATE TABLE family1___table1 (val VARCHAR);`,
+		`CREATE TABLE family1___table2 (val VARCHAR);`,
+		`INSERT INTO family1___table1 VALUES('hello');`,
+	} {
+		require.NoError(t, writer.ApplyDMLStatement(ctx, schema.NewTestDMLStatement(st)))
+	}
+	last := schema.NewTestDMLStatement(`INSERT INTO family1___table2 VALUES('hello');`)
+	require.NoError(t, writer.ApplyDMLStatement(ctx, last))
+
+	var n int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'family1___table2'").Scan(&n))
+	require.Equal(t, 0, n)
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM family1___table1").Scan(&n))
+	require.Equal(t, 1, n)
+
+	// refused statements are quarantined, and their sequence is recorded
+	rows, err := db.Query("SELECT seq, statement, reason FROM " + ldb.LDBQuarantineTableName + " ORDER BY seq")
+	require.NoError(t, err)
+	defer rows.Close()
+	var quarantined []string
+	for rows.Next() {
+		var seq int64
+		var statement, reason string
+		require.NoError(t, rows.Scan(&seq, &statement, &reason))
+		require.Equal(t, GuardReasonDeniedTable, reason)
+		quarantined = append(quarantined, statement)
+	}
+	require.NoError(t, rows.Err())
+	require.Equal(t, []string{
+		`CREATE TABLE family1___table2 (val VARCHAR);`,
+		`INSERT INTO family1___table2 VALUES('hello');`,
+	}, quarantined)
+
+	seq, err := ldb.FetchSeqFromLdb(ctx, db)
+	require.NoError(t, err)
+	require.Equal(t, last.Sequence, seq)
+}
@@ -0,0 +1,117 @@
+package ldbwriter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+const defaultTraceSamplerSize = 100
+
+// matches the first family___table identifier in a DML statement
+var statementTableRegexp = regexp.MustCompile(`[a-zA-Z0-9_]+___[a-zA-Z0-9_]+`)
+
+// TraceSample records how a single applied statement fared.
+type TraceSample struct {
+	Sequence  int64         `json:"sequence"`
+	Table     string        `json:"table,omitempty"`
+	Duration  time.Duration `json:"duration"`
+	AppliedAt time.Time     `json:"appliedAt"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// TraceSampler keeps every Nth applied statement in a bounded ring
+// buffer so that intermittent apply problems can be investigated
+// without turning on debug logging.
+type TraceSampler struct {
+	every   int64
+	mut     sync.Mutex
+	count   int64
+	samples []TraceSample
+	next    int
+	full    bool
+}
+
+// NewTraceSampler builds a TraceSampler which records every Nth statement
+// and retains at most size samples.
+func NewTraceSampler(every int, size int) *TraceSampler {
+	if every < 1 {
+		every = 1
+	}
+	if size < 1 {
+		size = defaultTraceSamplerSize
+	}
+	return &TraceSampler{
+		every:   int64(every),
+		samples: make([]TraceSample, size),
+	}
+}
+
+// shouldSample is called once per applied statement and reports
+// whether that statement is to be recorded.
+func (s *TraceSampler) shouldSample() bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.count++
+	return s.count%s.every == 0
+}
+
+func (s *TraceSampler) record(sample TraceSample) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.samples[s.next] = sample
+	s.next = (s.next + 1) % len(s.samples)
+	if s.next == 0 {
+		s.full = true
+	}
+}
+
+// Samples returns the retained samples, oldest first.
+func (s *TraceSampler) Samples() []TraceSample {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if !s.full {
+		return append([]TraceSample{}, s.samples[:s.next]...)
+	}
+	res := make([]TraceSample, 0, len(s.samples))
+	res = append(res, s.samples[s.next:]...)
+	return append(res, s.samples[:s.next]...)
+}
+
+// ServeHTTP writes the retained samples as JSON.
+func (s *TraceSampler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.Samples())
+}
+
+// SamplingWriter is an LDBWriter that delegates to another writer,
+// timing the statements it applies and handing a subset of them to
+// the Sampler.
+type SamplingWriter struct {
+	Delegate LDBWriter
+	Sampler  *TraceSampler
+}
+
+func (w *SamplingWriter) ApplyDMLStatement(ctx context.Context, statement schema.DMLStatement) error {
+	if !w.Sampler.shouldSample() {
+		return w.Delegate.ApplyDMLStatement(ctx, statement)
+	}
+	start := time.Now()
+	err := w.Delegate.ApplyDMLStatement(ctx, statement)
+	sample := TraceSample{
+		Sequence:  statement.Sequence.Int(),
+		Table:     statementTableRegexp.FindString(statement.Statement),
+		Duration:  time.Since(start),
+		AppliedAt: start,
+	}
+	if err != nil {
+		sample.Error = err.Error()
+	}
+	w.Sampler.record(sample)
+	return err
+}
@@ -0,0 +1,63 @@
+package ldbwriter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+type errOnSeqWriter struct {
+	failSeq int64
+}
+
+func (w *errOnSeqWriter) ApplyDMLStatement(_ context.Context, statement schema.DMLStatement) error {
+	if statement.Sequence.Int() == w.failSeq {
+		return errors.New("failed")
+	}
+	return nil
+}
+
+func TestSamplingWriter(t *testing.T) {
+	ctx := context.Background()
+	sampler := NewTraceSampler(2, 3)
+	writer := &SamplingWriter{
+		Delegate: &errOnSeqWriter{failSeq: 8},
+		Sampler:  sampler,
+	}
+	require.Empty(t, sampler.Samples())
+
+	for seq := int64(1); seq <= 10; seq++ {
+		err := writer.ApplyDMLStatement(ctx, schema.DMLStatement{
+			Sequence:  schema.DMLSequence(seq),
+			Statement: fmt.Sprintf("REPLACE INTO family___table%d VALUES(1)", seq),
+		})
+		if seq == 8 {
+			require.Error(t, err)
+		} else {
+			require.NoError(t, err)
+		}
+	}
+
+	samples := sampler.Samples()
+	require.Len(t, samples, 3)
+	for i, seq := range []int64{6, 8, 10} {
+		require.Equal(t, seq, samples[i].Sequence)
+		require.Equal(t, fmt.Sprintf("family___table%d", seq), samples[i].Table)
+	}
+	require.Equal(t, "", samples[0].Error)
+	require.Equal(t, "failed", samples[1].Error)
+
+	w := httptest.NewRecorder()
+	sampler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	var served []TraceSample
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&served))
+	require.Len(t, served, 3)
+	require.Equal(t, int64(10), served[2].Sequence)
+}
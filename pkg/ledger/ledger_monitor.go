@@ -31,7 +31,7 @@ type (
 	// reflected in container instance attributes.
 	HealthConfig struct {
 		DisableECSBehavior      bool          // whether or not to disable container instance attributing
-		MaxHealthyLatency       time.Duration // the max latency which is considered healthy
+		MaxHealthyLatency       time.Duration // the max latency which is considered healthy. beyond it, the ledger has stalled
 		AttributeName           string        // the attribute name to indicate ledger latency health
 		HealthyAttributeValue   string        // if ledger latency is healthy use this attribute value
 		UnhealthyAttributeValue string        // if ledger latency is unhealthy use this attribute value
@@ -46,6 +46,20 @@ type (
 		tickerFunc      func() *time.Ticker // helps us mock out time in tests
 		ecsClient       ECSClient           // helps us to mock out ECS API
 		checkCallback   func()              // called when a check is done. used for testing.
+		stallHandler    StallHandler        // notified when the ledger stalls and recovers
+		now             func() time.Time    // helps us mock out time in tests
+	}
+	// StallHandler is notified when the ledger latency exceeds the max
+	// healthy latency, and when it recovers, so that applications embedding
+	// the monitor can react, e.g. by shedding load. Its methods are called
+	// from the monitor's goroutine, so they should return promptly.
+	StallHandler interface {
+		// LedgerStalled is called when the ledger latency first exceeds the
+		// max healthy latency.
+		LedgerStalled(latency time.Duration)
+		// LedgerRecovered is called when the ledger latency is healthy again,
+		// with how long it had stalled for.
+		LedgerRecovered(stalledFor time.Duration)
 	}
 	latencyFunc     func(ctx context.Context) (time.Duration, error)
 	ecsMetadataFunc func(ctx context.Context) (EcsMetadata, error)
@@ -58,6 +72,7 @@ func NewLedgerMonitor(cfg HealthConfig, llf latencyFunc, opts ...MonitorOpt) (*M
 		latencyFunc:   llf,
 		tickerFunc:    func() *time.Ticker { return time.NewTicker(cfg.PollInterval) },
 		checkCallback: func() {},
+		now:           time.Now,
 	}
 	for _, opt := range opts {
 		if opt != nil {
@@ -71,6 +86,7 @@ func (m *Monitor) Start(ctx context.Context) {
 	events.Log("Ledger monitor starting")
 	defer events.Log("Ledger monitor stopped")
 	var health *bool // pointer for tri-state logic
+	var stalledSince time.Time
 	temporaryErrorLimit := 3
 	utils.CtxFireLoopTicker(ctx, m.tickerFunc(), func() {
 		defer m.checkCallback() // signal that the tick has finished
@@ -81,6 +97,8 @@ func (m *Monitor) Start(ctx context.Context) {
 			}
 			// always instrument ledger latency even if ECS behavior is disabled.
 			stats.Set("reflector-ledger-latency", latency)
+			reportLatency(latency)
+			stalledSince = m.detectStall(latency, stalledSince)
 			if !m.cfg.DisableECSBehavior {
 				switch {
 				case latency <= m.cfg.MaxHealthyLatency && (health == nil || *health != true):
@@ -124,6 +142,39 @@ func (m *Monitor) Start(ctx context.Context) {
 	})
 }
 
+// detectStall tracks an episode of unhealthy ledger latency, which began at
+// stalledSince unless it's zero, and returns when the current one began.
+func (m *Monitor) detectStall(latency time.Duration, stalledSince time.Time) time.Time {
+	if m.cfg.MaxHealthyLatency <= 0 {
+		// without a max healthy latency, the ledger never stalls
+		return stalledSince
+	}
+	stalled := latency > m.cfg.MaxHealthyLatency
+	switch {
+	case stalled && stalledSince.IsZero():
+		events.Log("Ledger stalled with latency %{latency}v", latency)
+		reportStall()
+		if m.stallHandler != nil {
+			m.stallHandler.LedgerStalled(latency)
+		}
+		stalledSince = m.now()
+	case !stalled && !stalledSince.IsZero():
+		stalledFor := m.now().Sub(stalledSince)
+		events.Log("Ledger recovered after stalling for %{duration}v", stalledFor)
+		reportStallDuration(stalledFor)
+		if m.stallHandler != nil {
+			m.stallHandler.LedgerRecovered(stalledFor)
+		}
+		stalledSince = time.Time{}
+	}
+	if stalled {
+		stats.Set("ledger-stalled", 1)
+	} else {
+		stats.Set("ledger-stalled", 0)
+	}
+	return stalledSince
+}
+
 func (m *Monitor) setHealthAttribute(ctx context.Context, attrValue string) error {
 	events.Log("Setting ECS instance attribute: %s=%s", m.cfg.AttributeName, attrValue)
 	ecsMeta, err := m.getECSMetadata(ctx)
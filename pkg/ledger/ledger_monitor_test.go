@@ -1,6 +1,7 @@
 package ledger_test
 
 import (
+	"bytes"
 	"context"
 	"sync"
 	"testing"
@@ -12,6 +13,8 @@ import (
 	"github.com/segmentio/ctlstore/pkg/ledger"
 	"github.com/segmentio/ctlstore/pkg/ledger/fakes"
 	_ "github.com/segmentio/events/v2/log"
+	"github.com/segmentio/stats/v4"
+	"github.com/segmentio/stats/v4/prometheus"
 	"github.com/stretchr/testify/require"
 )
 
@@ -125,6 +128,103 @@ func TestLedgerMonitor(t *testing.T) {
 	validateAttrSet("unhealthy", ecsClient.PutAttributesArgsForCall(callCount-1))
 }
 
+func TestLedgerMonitorStalls(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	h := &prometheus.Handler{}
+	original := stats.DefaultEngine
+	stats.DefaultEngine = stats.NewEngine("ctlstore.reflector", h)
+	defer func() { stats.DefaultEngine = original }()
+	ledger.RegisterPrometheusBuckets("ctlstore.reflector")
+
+	cfg := ledger.HealthConfig{
+		DisableECSBehavior: true,
+		MaxHealthyLatency:  10 * time.Second,
+	}
+	latencyProvider := &latencyProvider{duration: time.Second}
+	now := time.Unix(1000, 0)
+	handler := &stallHandler{}
+	ft := ledger.NewFakeTicker()
+	callbacks := make(chan struct{})
+	tick := func(latency time.Duration, elapsed time.Duration) {
+		latencyProvider.setDuration(latency)
+		now = now.Add(elapsed)
+		ft.Tick(ctx)
+		select {
+		case <-callbacks:
+		case <-ctx.Done():
+		}
+	}
+	mon, err := ledger.NewLedgerMonitor(cfg, latencyProvider.get,
+		ledger.WithTicker(ft.Ticker),
+		ledger.WithStallHandler(handler),
+		ledger.WithNowFunc(func() time.Time { return now }),
+		ledger.WithCheckCallback(func() {
+			select {
+			case callbacks <- struct{}{}:
+			case <-ctx.Done():
+			}
+		}))
+	require.NoError(t, err)
+	go mon.Start(ctx)
+	<-callbacks // the first check happens before ticking
+
+	tick(time.Minute, time.Second)
+	tick(2*time.Minute, time.Minute)
+	require.Equal(t, []time.Duration{time.Minute}, handler.stalls())
+	require.Empty(t, handler.recoveries())
+
+	tick(time.Second, 2*time.Minute)
+	tick(time.Second, time.Minute)
+	require.Equal(t, []time.Duration{3 * time.Minute}, handler.recoveries())
+
+	var buf bytes.Buffer
+	h.WriteStats(&buf)
+	out := buf.String()
+	for _, line := range []string{
+		"# TYPE ctlstore_reflector_ledger_latency_seconds histogram",
+		`ctlstore_reflector_ledger_latency_seconds_count 5`,
+		`ctlstore_reflector_ledger_latency_seconds_bucket{le="60"} 4`,
+		"# TYPE ctlstore_reflector_ledger_stalls_total counter",
+		`ctlstore_reflector_ledger_stalls_total 1`,
+		"# TYPE ctlstore_reflector_ledger_stall_duration_seconds histogram",
+		`ctlstore_reflector_ledger_stall_duration_seconds_bucket{le="300"} 1`,
+	} {
+		require.Contains(t, out, line)
+	}
+}
+
+type stallHandler struct {
+	mut             sync.Mutex
+	stalled         []time.Duration
+	recoveredAfters []time.Duration
+}
+
+func (h *stallHandler) LedgerStalled(latency time.Duration) {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+	h.stalled = append(h.stalled, latency)
+}
+
+func (h *stallHandler) LedgerRecovered(stalledFor time.Duration) {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+	h.recoveredAfters = append(h.recoveredAfters, stalledFor)
+}
+
+func (h *stallHandler) stalls() []time.Duration {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+	return h.stalled
+}
+
+func (h *stallHandler) recoveries() []time.Duration {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+	return h.recoveredAfters
+}
+
 type errHolder struct {
 	err error
 	mut sync.Mutex
@@ -0,0 +1,46 @@
+package ledger
+
+import (
+	"time"
+
+	"github.com/segmentio/stats/v4"
+)
+
+// The monitor reports these structured metrics alongside its other stats,
+// so that the prometheus handler exposes them as histograms and counters.
+
+type latencyMetrics struct {
+	Latency time.Duration `metric:"ledger_latency_seconds" type:"histogram"`
+}
+
+type stallMetrics struct {
+	Count int `metric:"ledger_stalls_total" type:"counter"`
+}
+
+type stallDurationMetrics struct {
+	Duration time.Duration `metric:"ledger_stall_duration_seconds" type:"histogram"`
+}
+
+// RegisterPrometheusBuckets registers the buckets of the histograms above
+// for a stats engine with the given prefix, since the prometheus handler
+// ignores histograms that have none.
+func RegisterPrometheusBuckets(prefix string) {
+	stats.Buckets.Set(prefix+".ledger_latency_seconds",
+		100*time.Millisecond, 500*time.Millisecond, time.Second, 5*time.Second,
+		15*time.Second, 30*time.Second, time.Minute, 5*time.Minute, 15*time.Minute)
+	stats.Buckets.Set(prefix+".ledger_stall_duration_seconds",
+		10*time.Second, 30*time.Second, time.Minute, 5*time.Minute,
+		15*time.Minute, time.Hour, 4*time.Hour)
+}
+
+func reportLatency(latency time.Duration) {
+	stats.Report(latencyMetrics{Latency: latency})
+}
+
+func reportStall() {
+	stats.Report(stallMetrics{Count: 1})
+}
+
+func reportStallDuration(stalledFor time.Duration) {
+	stats.Report(stallDurationMetrics{Duration: stalledFor})
+}
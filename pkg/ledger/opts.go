@@ -20,6 +20,21 @@ func WithECSMetadataFunc(fn ecsMetadataFunc) MonitorOpt {
 	}
 }
 
+// WithStallHandler notifies the handler when the ledger stalls and
+// recovers.
+func WithStallHandler(handler StallHandler) MonitorOpt {
+	return func(m *Monitor) {
+		m.stallHandler = handler
+	}
+}
+
+// WithNowFunc replaces the clock which times stalls.
+func WithNowFunc(fn func() time.Time) MonitorOpt {
+	return func(m *Monitor) {
+		m.now = fn
+	}
+}
+
 func WithTicker(ticker *time.Ticker) MonitorOpt {
 	return func(m *Monitor) {
 		m.tickerFunc = func() *time.Ticker {
@@ -16,6 +16,7 @@ const (
 	LimitFieldValueSize  = 512 * units.KILOBYTE
 
 	LimitMaxMutateRequestCount = 100
+	LimitMaxCloneRows          = 10000
 	LimitWriterCookieSize      = 1024
 
 	LimitWriterSecretMaxLength = 100
@@ -41,6 +42,20 @@ type SizeLimits struct {
 	WarnSize int64 `json:"warn-size"`
 }
 
+// TableSizeProjection estimates when a table will reach its max size, from
+// how fast it has grown over the recent samples of its size.
+type TableSizeProjection struct {
+	Family  string `json:"family"`
+	Table   string `json:"table"`
+	Size    int64  `json:"size"` // as of the latest sample
+	MaxSize int64  `json:"max-size"`
+	Samples int    `json:"samples"`
+	// GrowthPerDay is in bytes, and is zero with fewer than two samples
+	GrowthPerDay float64 `json:"growth-per-day"`
+	// DaysUntilLimit is nil unless the table is growing
+	DaysUntilLimit *float64 `json:"days-until-limit,omitempty"`
+}
+
 // WriterRateLimits represents all of the writer limits
 type WriterRateLimits struct {
 	Global  RateLimit         `json:"global"`
@@ -53,10 +68,26 @@ type WriterRateLimit struct {
 	RateLimit RateLimit `json:"rate-limit"`
 }
 
+// WriterGroup is a set of writers which share a single rate limit, so that
+// a writer service can scale out with one identity per instance without
+// fragmenting its limit. A writer is in at most one group, and while it is,
+// the group's limit applies to it instead of its own.
+type WriterGroup struct {
+	Name      string    `json:"name"`
+	RateLimit RateLimit `json:"rate-limit"`
+	Members   []string  `json:"members"`
+}
+
 // RateLimit composes an amount allowed per duration
 type RateLimit struct {
 	Amount int64         `json:"amount"`
 	Period time.Duration `json:"period"`
+	// Burst, if set, is the most mutations that may be made at once. The
+	// limit is then enforced with a token bucket which holds up to Burst
+	// tokens and refills at Amount per Period, rather than by counting
+	// mutations in fixed windows of Period, so that writers whose average
+	// rate is within the limit aren't rejected for bunching up their writes.
+	Burst int64 `json:"burst,omitempty"`
 }
 
 // UnmarshalJSON allows us to deser time.Durations using string values
@@ -87,10 +118,24 @@ func (l *RateLimit) UnmarshalJSON(b []byte) error {
 			return errors.Errorf("invalid period: '%v'", period)
 		}
 	}
+	if burst, ok := val["burst"]; ok {
+		switch burst := burst.(type) {
+		case float64:
+			if burst < 0 {
+				return errors.Errorf("invalid burst: '%v'", burst)
+			}
+			l.Burst = int64(burst)
+		default:
+			return errors.Errorf("invalid burst: '%v'", burst)
+		}
+	}
 	return nil
 }
 
 func (l RateLimit) String() string {
+	if l.Burst > 0 {
+		return fmt.Sprintf("%d/%v (burst %d)", l.Amount, l.Period, l.Burst)
+	}
 	return fmt.Sprintf("%d/%v", l.Amount, l.Period)
 }
 
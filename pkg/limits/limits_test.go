@@ -69,6 +69,28 @@ func TestRateLimitDeser(t *testing.T) {
 			},
 			err: errors.New("invalid period: '10 seconds'"),
 		},
+		{
+			desc: "burst success",
+			input: map[string]interface{}{
+				"amount": 15,
+				"period": "10s",
+				"burst":  30,
+			},
+			expected: RateLimit{
+				Amount: 15,
+				Period: 10 * time.Second,
+				Burst:  30,
+			},
+		},
+		{
+			desc: "negative burst failure",
+			input: map[string]interface{}{
+				"amount": 15,
+				"period": "10s",
+				"burst":  -1,
+			},
+			err: errors.New("invalid burst: '-1'"),
+		},
 		{
 			desc: "invalid amount failure",
 			input: map[string]interface{}{
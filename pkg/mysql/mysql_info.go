@@ -39,7 +39,7 @@ func (m *MySQLDBInfo) GetColumnInfo(ctx context.Context, tableNames []string) ([
 	}
 
 	qs := sqlgen.SqlSprintf(
-		"SELECT table_name, ordinal_position, column_name, data_type, column_key "+
+		"SELECT table_name, ordinal_position, column_name, data_type, column_key, column_default, is_nullable "+
 			"FROM information_schema.columns "+
 			"WHERE table_name IN ($1) "+
 			"AND table_schema = DATABASE() "+
@@ -66,6 +66,8 @@ func (m *MySQLDBInfo) GetColumnInfo(ctx context.Context, tableNames []string) ([
 		var colName string
 		var dataType string
 		var colKey string
+		var colDefault sql.NullString
+		var isNullable string
 
 		err = rows.Scan(
 			&tableName,
@@ -73,10 +75,16 @@ func (m *MySQLDBInfo) GetColumnInfo(ctx context.Context, tableNames []string) ([
 			&colName,
 			&dataType,
 			&colKey,
+			&colDefault,
+			&isNullable,
 		)
 		if err != nil {
 			return nil, err
 		}
+		var def *string
+		if colDefault.Valid {
+			def = &colDefault.String
+		}
 
 		columnInfos = append(columnInfos, schema.DBColumnInfo{
 			TableName:    tableName,
@@ -84,6 +92,8 @@ func (m *MySQLDBInfo) GetColumnInfo(ctx context.Context, tableNames []string) ([
 			ColumnName:   colName,
 			DataType:     dataType,
 			IsPrimaryKey: (colKey == "PRI"),
+			Default:      def,
+			NotNull:      isNullable == "NO",
 		})
 	}
 	err = rows.Err()
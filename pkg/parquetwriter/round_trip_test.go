@@ -0,0 +1,272 @@
+package parquetwriter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// readParquet decodes a file of flat optional columns, as described by the
+// parquet format rather than by the writer, so that the tests can check what
+// the writer wrote round trips. It returns the column names and the rows.
+func readParquet(t *testing.T, b []byte) ([]string, [][]interface{}) {
+	require.Equal(t, "PAR1", string(b[:4]))
+	require.Equal(t, "PAR1", string(b[len(b)-4:]))
+	footerLen := int(binary.LittleEndian.Uint32(b[len(b)-8:]))
+	footer := &thriftReader{b: b[len(b)-8-footerLen : len(b)-8]}
+	meta, err := footer.readStruct()
+	require.NoError(t, err)
+
+	// the first schema element is the root
+	schema := meta[2].([]interface{})
+	require.EqualValues(t, len(schema)-1, schema[0].(thriftFields)[5])
+	var names []string
+	var types []int32
+	for _, elem := range schema[1:] {
+		fields := elem.(thriftFields)
+		require.EqualValues(t, repetitionOptional, fields[3])
+		names = append(names, string(fields[4].([]byte)))
+		types = append(types, fields[1].(int32))
+	}
+
+	var rows [][]interface{}
+	for _, rg := range meta[4].([]interface{}) {
+		rgFields := rg.(thriftFields)
+		numRows := int(rgFields[3].(int64))
+		groupRows := make([][]interface{}, numRows)
+		for i := range groupRows {
+			groupRows[i] = make([]interface{}, len(names))
+		}
+		for c, chunk := range rgFields[1].([]interface{}) {
+			chunkMeta := chunk.(thriftFields)[3].(thriftFields)
+			require.EqualValues(t, codecNone, chunkMeta[4])
+			require.EqualValues(t, numRows, chunkMeta[5])
+			values := readDataPage(t, b[chunkMeta[9].(int64):], types[c], numRows)
+			for i, v := range values {
+				groupRows[i][c] = v
+			}
+		}
+		rows = append(rows, groupRows...)
+	}
+	require.EqualValues(t, len(rows), meta[3])
+	return names, rows
+}
+
+func readDataPage(t *testing.T, b []byte, physicalType int32, numRows int) []interface{} {
+	r := &thriftReader{b: b}
+	header, err := r.readStruct()
+	require.NoError(t, err)
+	require.EqualValues(t, pageTypeData, header[1])
+	page := b[r.off : r.off+int(header[3].(int32))]
+	dataHeader := header[5].(thriftFields)
+	require.EqualValues(t, numRows, dataHeader[1])
+	require.EqualValues(t, encodingPlain, dataHeader[2])
+	require.EqualValues(t, encodingRLE, dataHeader[3])
+
+	levelsLen := int(binary.LittleEndian.Uint32(page))
+	defined := decodeHybrid(t, page[4:4+levelsLen], numRows)
+	data := page[4+levelsLen:]
+	values := make([]interface{}, numRows)
+	for i := range values {
+		if !defined[i] {
+			continue
+		}
+		switch physicalType {
+		case physicalInt64:
+			values[i] = int64(binary.LittleEndian.Uint64(data))
+			data = data[8:]
+		case physicalDouble:
+			values[i] = math.Float64frombits(binary.LittleEndian.Uint64(data))
+			data = data[8:]
+		case physicalByteArray:
+			n := int(binary.LittleEndian.Uint32(data))
+			values[i] = append([]byte{}, data[4:4+n]...)
+			data = data[4+n:]
+		default:
+			t.Fatalf("unexpected physical type %d", physicalType)
+		}
+	}
+	require.Empty(t, data)
+	return values
+}
+
+// decodeHybrid decodes definition levels of bit width 1 in the RLE/bit
+// packing hybrid encoding.
+func decodeHybrid(t *testing.T, b []byte, n int) []bool {
+	var res []bool
+	for len(b) > 0 {
+		header, size := binary.Uvarint(b)
+		require.True(t, size > 0)
+		b = b[size:]
+		if header&1 == 1 {
+			groups := int(header >> 1)
+			for _, packed := range b[:groups] {
+				for bit := 0; bit < 8; bit++ {
+					res = append(res, packed&(1<<bit) != 0)
+				}
+			}
+			b = b[groups:]
+		} else {
+			for i := 0; i < int(header>>1); i++ {
+				res = append(res, b[0] == 1)
+			}
+			b = b[1:]
+		}
+	}
+	require.True(t, len(res) >= n)
+	return res[:n]
+}
+
+// thriftFields are the fields of a decoded struct by id
+type thriftFields map[int16]interface{}
+
+// thriftReader decodes the thrift compact protocol.
+type thriftReader struct {
+	b   []byte
+	off int
+}
+
+func (r *thriftReader) byte() (byte, error) {
+	if r.off >= len(r.b) {
+		return 0, errors.New("unexpected end of thrift data")
+	}
+	r.off++
+	return r.b[r.off-1], nil
+}
+
+func (r *thriftReader) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(r.b[r.off:])
+	if n <= 0 {
+		return 0, errors.New("invalid varint")
+	}
+	r.off += n
+	return v, nil
+}
+
+func (r *thriftReader) zigzag() (int64, error) {
+	v, err := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1), err
+}
+
+func (r *thriftReader) readStruct() (thriftFields, error) {
+	fields := thriftFields{}
+	var last int16
+	for {
+		b, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		if b == 0 {
+			return fields, nil
+		}
+		id := last + int16(b>>4)
+		if b>>4 == 0 {
+			v, err := r.zigzag()
+			if err != nil {
+				return nil, err
+			}
+			id = int16(v)
+		}
+		if fields[id], err = r.readValue(b & 0x0f); err != nil {
+			return nil, err
+		}
+		last = id
+	}
+}
+
+func (r *thriftReader) readValue(typ byte) (interface{}, error) {
+	switch typ {
+	case 1, 2: // booleans in struct fields
+		return typ == 1, nil
+	case thriftI32:
+		v, err := r.zigzag()
+		return int32(v), err
+	case thriftI64:
+		return r.zigzag()
+	case thriftBinary:
+		n, err := r.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		if r.off+int(n) > len(r.b) {
+			return nil, errors.New("binary past the end of thrift data")
+		}
+		r.off += int(n)
+		return r.b[r.off-int(n) : r.off], nil
+	case thriftList:
+		b, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		n := int(b >> 4)
+		if n == 15 {
+			v, err := r.uvarint()
+			if err != nil {
+				return nil, err
+			}
+			n = int(v)
+		}
+		elems := make([]interface{}, n)
+		for i := range elems {
+			if elems[i], err = r.readValue(b & 0x0f); err != nil {
+				return nil, err
+			}
+		}
+		return elems, nil
+	case thriftStruct:
+		return r.readStruct()
+	default:
+		return nil, errors.Errorf("unsupported thrift type %d", typ)
+	}
+}
+
+func TestWriterRoundTrip(t *testing.T) {
+	columns := []Column{
+		{Name: "id", Type: Int64},
+		{Name: "score", Type: Double},
+		{Name: "name", Type: String},
+		{Name: "blob", Type: Bytes},
+	}
+	var expected [][]interface{}
+	for i := 0; i < 25; i++ {
+		row := []interface{}{int64(i - 10), float64(i) / 4, "name", []byte{byte(i), 0}}
+		// nulls in every column, on different rows
+		row[i%len(columns)] = nil
+		expected = append(expected, row)
+	}
+
+	for _, rowGroupBytes := range []int{0, 64} {
+		var buf bytes.Buffer
+		w := New(&buf, columns)
+		w.RowGroupBytes = rowGroupBytes
+		for _, row := range expected {
+			require.NoError(t, w.Write(row))
+		}
+		require.NoError(t, w.Close())
+
+		names, rows := readParquet(t, buf.Bytes())
+		require.Equal(t, []string{"id", "score", "name", "blob"}, names)
+		require.Len(t, rows, len(expected))
+		for i, row := range rows {
+			// strings are read back as byte arrays
+			if s, ok := expected[i][2].(string); ok {
+				require.Equal(t, []byte(s), row[2])
+				row[2] = s
+			}
+			require.Equal(t, expected[i], row, "row %d", i)
+		}
+	}
+}
+
+func TestWriterRoundTripEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	w := New(&buf, []Column{{Name: "id", Type: Int64}})
+	require.NoError(t, w.Close())
+	names, rows := readParquet(t, buf.Bytes())
+	require.Equal(t, []string{"id"}, names)
+	require.Empty(t, rows)
+}
@@ -0,0 +1,339 @@
+// Package parquetwriter writes tables of rows as parquet files. It supports
+// just what exports of ctlstore tables need: flat schemas of optional
+// columns, with PLAIN encoded and uncompressed values.
+package parquetwriter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// Type is the type of the values of a column.
+type Type int
+
+const (
+	Int64 Type = iota
+	Double
+	// String is a UTF-8 string
+	String
+	Bytes
+)
+
+// Column is a column of the table being written. Every column is optional,
+// so any value may be null.
+type Column struct {
+	Name string
+	Type Type
+}
+
+// parquet format constants
+const (
+	physicalInt64     = 2
+	physicalDouble    = 5
+	physicalByteArray = 6
+
+	repetitionOptional = 1
+	convertedUTF8      = 0
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	pageTypeData = 0
+	codecNone    = 0
+)
+
+var magic = []byte("PAR1")
+
+// DefaultRowGroupBytes is how many bytes of values are buffered before they
+// are written out as a row group.
+const DefaultRowGroupBytes = 64 * 1024 * 1024
+
+// Writer writes rows to a parquet file. Rows are buffered in memory, and
+// written a row group at a time. Close must be called to write the footer.
+type Writer struct {
+	// RowGroupBytes overrides DefaultRowGroupBytes
+	RowGroupBytes int
+
+	w         io.Writer
+	offset    int64
+	columns   []*column
+	rows      int64 // in the buffered row group
+	totalRows int64
+	rowGroups []rowGroup
+}
+
+type column struct {
+	Column
+	defLevels []bool
+	values    bytes.Buffer // PLAIN encoded non-null values
+}
+
+type rowGroup struct {
+	numRows   int64
+	totalSize int64
+	chunks    []columnChunk
+}
+
+type columnChunk struct {
+	offset int64
+	size   int64
+}
+
+// New returns a writer of a table of the given columns to w.
+func New(w io.Writer, columns []Column) *Writer {
+	pw := &Writer{w: w}
+	for _, c := range columns {
+		pw.columns = append(pw.columns, &column{Column: c})
+	}
+	return pw
+}
+
+// Write buffers a row, which has a value for each column in order. Values
+// are int64 for Int64 columns, float64 for Double, string for String and
+// []byte for Bytes. A nil value is null.
+func (w *Writer) Write(row []interface{}) error {
+	if len(row) != len(w.columns) {
+		return errors.Errorf("row has %d values, but there are %d columns", len(row), len(w.columns))
+	}
+	for i, c := range w.columns {
+		if err := c.append(row[i]); err != nil {
+			// leave the columns consistent with each other
+			for _, c := range w.columns[:i] {
+				c.truncate()
+			}
+			return errors.Wrapf(err, "column %s", c.Name)
+		}
+	}
+	w.rows++
+
+	limit := w.RowGroupBytes
+	if limit == 0 {
+		limit = DefaultRowGroupBytes
+	}
+	var size int
+	for _, c := range w.columns {
+		size += c.values.Len()
+	}
+	if size >= limit {
+		return w.flush()
+	}
+	return nil
+}
+
+func (c *column) append(v interface{}) error {
+	if v == nil {
+		c.defLevels = append(c.defLevels, false)
+		return nil
+	}
+	var b [8]byte
+	switch c.Type {
+	case Int64:
+		i, ok := v.(int64)
+		if !ok {
+			return errors.Errorf("expected int64, got %T", v)
+		}
+		binary.LittleEndian.PutUint64(b[:], uint64(i))
+		c.values.Write(b[:])
+	case Double:
+		f, ok := v.(float64)
+		if !ok {
+			return errors.Errorf("expected float64, got %T", v)
+		}
+		binary.LittleEndian.PutUint64(b[:], math.Float64bits(f))
+		c.values.Write(b[:])
+	case String, Bytes:
+		var data []byte
+		switch v := v.(type) {
+		case string:
+			data = []byte(v)
+		case []byte:
+			data = v
+		default:
+			return errors.Errorf("expected string or []byte, got %T", v)
+		}
+		binary.LittleEndian.PutUint32(b[:4], uint32(len(data)))
+		c.values.Write(b[:4])
+		c.values.Write(data)
+	default:
+		return errors.Errorf("unknown type %d", c.Type)
+	}
+	c.defLevels = append(c.defLevels, true)
+	return nil
+}
+
+// truncate removes the last value appended to the column.
+func (c *column) truncate() {
+	last := len(c.defLevels) - 1
+	if c.defLevels[last] {
+		size := 8
+		if c.Type == String || c.Type == Bytes {
+			// the length prefix isn't at a known offset, so re-scan
+			size = lastByteArraySize(c.values.Bytes())
+		}
+		c.values.Truncate(c.values.Len() - size)
+	}
+	c.defLevels = c.defLevels[:last]
+}
+
+func lastByteArraySize(values []byte) int {
+	var last int
+	for off := 0; off < len(values); {
+		last = 4 + int(binary.LittleEndian.Uint32(values[off:]))
+		off += last
+	}
+	return last
+}
+
+func (w *Writer) write(b []byte) error {
+	n, err := w.w.Write(b)
+	w.offset += int64(n)
+	return err
+}
+
+// flush writes the buffered rows as a row group, with a single data page
+// per column.
+func (w *Writer) flush() error {
+	if w.offset == 0 {
+		if err := w.write(magic); err != nil {
+			return err
+		}
+	}
+	if w.rows == 0 {
+		return nil
+	}
+	rg := rowGroup{numRows: w.rows}
+	for _, c := range w.columns {
+		levels := encodeDefLevels(c.defLevels)
+		pageSize := len(levels) + c.values.Len()
+
+		t := newThriftWriter()
+		t.i32(1, pageTypeData)
+		t.i32(2, int32(pageSize))
+		t.i32(3, int32(pageSize))
+		t.beginStruct(5)
+		t.i32(1, int32(len(c.defLevels)))
+		t.i32(2, encodingPlain)
+		t.i32(3, encodingRLE)
+		t.i32(4, encodingRLE)
+		t.endStruct()
+		t.endStruct()
+
+		chunk := columnChunk{offset: w.offset}
+		for _, b := range [][]byte{t.buf.Bytes(), levels, c.values.Bytes()} {
+			if err := w.write(b); err != nil {
+				return err
+			}
+		}
+		chunk.size = w.offset - chunk.offset
+		rg.chunks = append(rg.chunks, chunk)
+		rg.totalSize += chunk.size
+
+		c.defLevels = c.defLevels[:0]
+		c.values.Reset()
+	}
+	w.rowGroups = append(w.rowGroups, rg)
+	w.totalRows += w.rows
+	w.rows = 0
+	return nil
+}
+
+// encodeDefLevels encodes the definition levels of a page, which are 1 for
+// values and 0 for nulls, as a single bit packed run of the RLE/bit packing
+// hybrid encoding, prefixed with its length.
+func encodeDefLevels(defined []bool) []byte {
+	groups := (len(defined) + 7) / 8
+	var header [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(header[:], uint64(groups)<<1|1)
+
+	out := make([]byte, 4, 4+n+groups)
+	out = append(out, header[:n]...)
+	packed := make([]byte, groups)
+	for i, d := range defined {
+		if d {
+			packed[i/8] |= 1 << (i % 8)
+		}
+	}
+	out = append(out, packed...)
+	binary.LittleEndian.PutUint32(out, uint32(len(out)-4))
+	return out
+}
+
+// Close writes any buffered rows and the footer. It doesn't close the
+// underlying writer.
+func (w *Writer) Close() error {
+	if err := w.flush(); err != nil {
+		return err
+	}
+
+	t := newThriftWriter()
+	t.i32(1, 1) // version
+	t.list(2, thriftStruct, len(w.columns)+1)
+	t.beginStruct(0)
+	t.binary(4, []byte("schema"))
+	t.i32(5, int32(len(w.columns)))
+	t.endStruct()
+	for _, c := range w.columns {
+		t.beginStruct(0)
+		t.i32(1, c.physicalType())
+		t.i32(3, repetitionOptional)
+		t.binary(4, []byte(c.Name))
+		if c.Type == String {
+			t.i32(6, convertedUTF8)
+		}
+		t.endStruct()
+	}
+	t.i64(3, w.totalRows)
+	t.list(4, thriftStruct, len(w.rowGroups))
+	for _, rg := range w.rowGroups {
+		t.beginStruct(0)
+		t.list(1, thriftStruct, len(rg.chunks))
+		for i, chunk := range rg.chunks {
+			c := w.columns[i]
+			t.beginStruct(0)
+			t.i64(2, chunk.offset)
+			t.beginStruct(3)
+			t.i32(1, c.physicalType())
+			t.list(2, thriftI32, 2)
+			t.zigzag(encodingPlain)
+			t.zigzag(encodingRLE)
+			t.list(3, thriftBinary, 1)
+			t.binaryElem([]byte(c.Name))
+			t.i32(4, codecNone)
+			t.i64(5, rg.numRows)
+			t.i64(6, chunk.size)
+			t.i64(7, chunk.size)
+			t.i64(9, chunk.offset)
+			t.endStruct()
+			t.endStruct()
+		}
+		t.i64(2, rg.totalSize)
+		t.i64(3, rg.numRows)
+		t.endStruct()
+	}
+	t.binary(6, []byte("ctlstore"))
+	t.endStruct()
+
+	var footerLen [4]byte
+	binary.LittleEndian.PutUint32(footerLen[:], uint32(t.buf.Len()))
+	for _, b := range [][]byte{t.buf.Bytes(), footerLen[:], magic} {
+		if err := w.write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *column) physicalType() int32 {
+	switch c.Type {
+	case Int64:
+		return physicalInt64
+	case Double:
+		return physicalDouble
+	default:
+		return physicalByteArray
+	}
+}
@@ -0,0 +1,75 @@
+package parquetwriter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriterLayout(t *testing.T) {
+	var buf bytes.Buffer
+	w := New(&buf, []Column{
+		{Name: "id", Type: Int64},
+		{Name: "score", Type: Double},
+		{Name: "name", Type: String},
+		{Name: "blob", Type: Bytes},
+	})
+	w.RowGroupBytes = 64
+	for i := 0; i < 10; i++ {
+		var name interface{}
+		if i%2 == 0 {
+			name = "name"
+		}
+		require.NoError(t, w.Write([]interface{}{int64(i), float64(i) / 2, name, []byte{byte(i)}}))
+	}
+	require.NoError(t, w.Close())
+
+	b := buf.Bytes()
+	require.Equal(t, "PAR1", string(b[:4]))
+	require.Equal(t, "PAR1", string(b[len(b)-4:]))
+	footerLen := binary.LittleEndian.Uint32(b[len(b)-8:])
+	require.True(t, int(footerLen) < len(b)-12)
+	require.EqualValues(t, 10, w.totalRows)
+	require.True(t, len(w.rowGroups) > 1)
+
+	var rows int64
+	offset := int64(4)
+	for _, rg := range w.rowGroups {
+		rows += rg.numRows
+		require.Len(t, rg.chunks, 4)
+		for _, chunk := range rg.chunks {
+			require.Equal(t, offset, chunk.offset)
+			offset += chunk.size
+		}
+	}
+	require.EqualValues(t, 10, rows)
+	require.EqualValues(t, len(b)-8-int(footerLen), offset)
+}
+
+func TestWriterRejectsInvalidRows(t *testing.T) {
+	var buf bytes.Buffer
+	w := New(&buf, []Column{
+		{Name: "name", Type: String},
+		{Name: "id", Type: Int64},
+	})
+	require.NoError(t, w.Write([]interface{}{"a", int64(1)}))
+	require.Error(t, w.Write([]interface{}{"b"}))
+	require.Error(t, w.Write([]interface{}{"b", "not an int"}))
+
+	// the rejected row must not leave a value behind in the first column
+	require.Len(t, w.columns[0].defLevels, 1)
+	require.Len(t, w.columns[1].defLevels, 1)
+	require.Equal(t, 5, w.columns[0].values.Len())
+	require.NoError(t, w.Close())
+}
+
+func TestEncodeDefLevels(t *testing.T) {
+	levels := encodeDefLevels([]bool{true, false, true, true, false, false, false, false, true})
+	require.Equal(t, []byte{
+		3, 0, 0, 0, // length
+		2<<1 | 1, // two bit packed groups of eight
+		0x0d, 0x01,
+	}, levels)
+}
@@ -0,0 +1,101 @@
+package reflector
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
+	"github.com/segmentio/go-sqlite3"
+	"github.com/segmentio/stats/v4"
+)
+
+const defaultSlowQueryThreshold = 500 * time.Millisecond
+
+// errUpstreamOverloaded is returned by a dmlSource when the ctldb refuses a
+// ledger query because it's overloaded. The shovel waits out the poll
+// interval, as it does when there are no new statements.
+var errUpstreamOverloaded = errors.New("upstream overloaded")
+
+// AdaptivePollingConfig backs off polling of the ledger while the ctldb is
+// under load, rather than always polling it every PollInterval. The poll
+// interval doubles each time a ledger query takes longer than
+// SlowQueryThreshold, and halves back towards the PollInterval each time
+// one doesn't. If the ctldb refuses connections because it has too many,
+// the interval goes straight to MaxPollInterval.
+type AdaptivePollingConfig struct {
+	// MaxPollInterval of zero, or no more than the PollInterval, disables
+	// adaptive polling
+	MaxPollInterval    time.Duration
+	SlowQueryThreshold time.Duration // optional
+}
+
+// a dmlSource whose poll interval adapts to the load on its upstream
+type adaptiveDmlSource interface {
+	// pollInterval returns zero if the source has no opinion
+	pollInterval() time.Duration
+}
+
+// adaptivePoller tracks the poll interval of a sqlDmlSource.
+type adaptivePoller struct {
+	min, max  time.Duration
+	threshold time.Duration
+	interval  time.Duration
+}
+
+// newAdaptivePoller returns nil if adaptive polling is disabled.
+func newAdaptivePoller(pollInterval time.Duration, cfg AdaptivePollingConfig) *adaptivePoller {
+	if cfg.MaxPollInterval <= pollInterval || pollInterval <= 0 {
+		return nil
+	}
+	threshold := cfg.SlowQueryThreshold
+	if threshold <= 0 {
+		threshold = defaultSlowQueryThreshold
+	}
+	return &adaptivePoller{
+		min:       pollInterval,
+		max:       cfg.MaxPollInterval,
+		threshold: threshold,
+		interval:  pollInterval,
+	}
+}
+
+// observe adjusts the poll interval after a ledger query.
+func (p *adaptivePoller) observe(latency time.Duration, err error) {
+	switch {
+	case isUpstreamOverloaded(err):
+		stats.Incr("sql_dml_source.upstream_overloaded")
+		p.interval = p.max
+	case latency > p.threshold || errors.Cause(err) == context.DeadlineExceeded:
+		stats.Incr("sql_dml_source.slow_query")
+		p.interval *= 2
+		if p.interval > p.max {
+			p.interval = p.max
+		}
+	case err == nil:
+		p.interval /= 2
+		if p.interval < p.min {
+			p.interval = p.min
+		}
+	}
+	stats.Set("sql_dml_source.poll_interval", p.interval)
+}
+
+// isUpstreamOverloaded reports whether err is MySQL refusing a connection
+// because the server or user has too many, or a SQLite CtlDB's writer
+// holding its locks for longer than the busy timeout.
+func isUpstreamOverloaded(err error) bool {
+	if sqliteErr, ok := errors.Cause(err).(sqlite3.Error); ok {
+		return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+	}
+	mysqlErr, ok := errors.Cause(err).(*mysql.MySQLError)
+	if !ok {
+		return false
+	}
+	switch mysqlErr.Number {
+	case 1040, // ER_CON_COUNT_ERROR
+		1203: // ER_TOO_MANY_USER_CONNECTIONS
+		return true
+	}
+	return false
+}
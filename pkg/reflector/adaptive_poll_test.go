@@ -0,0 +1,57 @@
+package reflector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAdaptivePollerDisabled(t *testing.T) {
+	require.Nil(t, newAdaptivePoller(time.Second, AdaptivePollingConfig{}))
+	require.Nil(t, newAdaptivePoller(time.Second, AdaptivePollingConfig{MaxPollInterval: time.Second}))
+
+	p := newAdaptivePoller(time.Second, AdaptivePollingConfig{MaxPollInterval: time.Minute})
+	require.NotNil(t, p)
+	require.Equal(t, defaultSlowQueryThreshold, p.threshold)
+	require.Equal(t, time.Second, p.interval)
+}
+
+func TestAdaptivePollerObserve(t *testing.T) {
+	p := newAdaptivePoller(time.Second, AdaptivePollingConfig{
+		MaxPollInterval:    10 * time.Second,
+		SlowQueryThreshold: 100 * time.Millisecond,
+	})
+
+	p.observe(time.Second, nil)
+	require.Equal(t, 2*time.Second, p.interval)
+	p.observe(time.Millisecond, errors.Wrap(context.DeadlineExceeded, "select row"))
+	require.Equal(t, 4*time.Second, p.interval)
+	p.observe(time.Second, nil)
+	p.observe(time.Second, nil)
+	require.Equal(t, 10*time.Second, p.interval, "capped at the max")
+
+	p.observe(time.Millisecond, nil)
+	require.Equal(t, 5*time.Second, p.interval)
+	for i := 0; i < 5; i++ {
+		p.observe(time.Millisecond, nil)
+	}
+	require.Equal(t, time.Second, p.interval, "floored at the poll interval")
+
+	p.observe(time.Millisecond, errors.New("some other error"))
+	require.Equal(t, time.Second, p.interval, "other errors leave the interval alone")
+
+	p.observe(time.Millisecond, &mysql.MySQLError{Number: 1040, Message: "Too many connections"})
+	require.Equal(t, 10*time.Second, p.interval)
+}
+
+func TestMergedDmlSourcePollInterval(t *testing.T) {
+	fast := &sqlDmlSource{poller: newAdaptivePoller(time.Second, AdaptivePollingConfig{MaxPollInterval: time.Minute})}
+	slow := &sqlDmlSource{poller: newAdaptivePoller(time.Second, AdaptivePollingConfig{MaxPollInterval: time.Minute})}
+	slow.poller.interval = 8 * time.Second
+	src := &mergedDmlSource{sources: []dmlSource{fast, slow, &sqlDmlSource{}}}
+	require.Equal(t, 8*time.Second, src.pollInterval())
+}
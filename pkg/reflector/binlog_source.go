@@ -0,0 +1,356 @@
+package reflector
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/segmentio/errors-go"
+	"github.com/segmentio/events/v2"
+	"github.com/segmentio/stats/v4"
+	"github.com/siddontang/go-log/log"
+
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+// BinlogDriver is the UpstreamConfig.Driver which reads the ledger of a MySQL
+// CtlDB from its binlog, like a replica, rather than by polling the ledger
+// table. The DSN is a MySQL DSN, whose user needs the REPLICATION SLAVE and
+// REPLICATION CLIENT privileges, and the CtlDB must log in ROW format.
+const BinlogDriver = "mysql-binlog"
+
+// binlogWait is how long Next waits for a statement to arrive in the binlog
+// before giving up, so that the shovel can flush and check whether it
+// should stop.
+const binlogWait = time.Second
+
+// binlogMinPollInterval is how long the shovel waits between calls to Next
+// once it has caught up. Next does the waiting itself, so it's short.
+const binlogMinPollInterval = time.Millisecond
+
+// the columns of the ledger table, in the order they're defined, which is
+// how rows are laid out in the binlog. The trace_id and version columns
+// were added by migrations, so older ledgers don't have them.
+const (
+	binlogSeqColumn = iota
+	binlogLeaderTsColumn
+	binlogStatementColumn
+	binlogTraceIDColumn
+	binlogVersionColumn
+)
+
+// binlogStream is the part of a *replication.BinlogStreamer which the
+// binlogDmlSource reads from.
+type binlogStream interface {
+	GetEvent(ctx context.Context) (*replication.BinlogEvent, error)
+}
+
+// a dmlSource which reads the inserts into a MySQL ledger table from the
+// binlog. Statements which were written before it started are read from
+// the ledger table with a sqlDmlSource, and it switches over to the binlog
+// once that has caught up. Since the binlog is positioned before catching
+// up, no statements are missed, and those which were already read are
+// skipped by sequence.
+type binlogDmlSource struct {
+	catchUp     *sqlDmlSource
+	schemaName  string
+	ledgerTable string
+	ledgerID    int
+	// lastSequence is the last statement read, from either the ledger table
+	// or the binlog
+	lastSequence schema.DMLSequence
+	wait         time.Duration
+
+	// position returns the current position of the binlog, and startSync
+	// starts streaming it from a position
+	position  func(ctx context.Context) (mysql.Position, error)
+	startSync func(pos mysql.Position) (binlogStream, error)
+	closeSync func()
+
+	pos    *mysql.Position // set once the position is taken
+	stream binlogStream    // set once caught up
+	buffer []schema.DMLStatement
+}
+
+// newBinlogDmlSource returns a binlogDmlSource which reads the ledger table
+// of the CtlDB at dsn after lastSequence, identifying itself to the CtlDB as
+// a replica with serverID.
+func newBinlogDmlSource(db *sql.DB, dsn string, ledgerTable string, ledgerID int, serverID uint32, lastSequence schema.DMLSequence, catchUp *sqlDmlSource) (*binlogDmlSource, error) {
+	cfg, err := mysqldriver.ParseDSN(dsn)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse upstream DSN")
+	}
+	host, portStr, err := net.SplitHostPort(cfg.Addr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse upstream address %q", cfg.Addr)
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse upstream port %q", portStr)
+	}
+	var tlsConfig *tls.Config
+	switch cfg.TLSConfig {
+	case "", "false":
+	case "true":
+		tlsConfig = &tls.Config{ServerName: host}
+	case "skip-verify":
+		tlsConfig = &tls.Config{InsecureSkipVerify: true}
+	default:
+		return nil, errors.Errorf("the binlog can't be read with tls=%s", cfg.TLSConfig)
+	}
+	if serverID == 0 {
+		// replicas need distinct IDs, which are usually small, so stay
+		// well clear of them
+		serverID = 1<<24 + uint32(rand.Int31n(1<<30))
+	}
+	syncer := replication.NewBinlogSyncer(replication.BinlogSyncerConfig{
+		ServerID:  serverID,
+		Flavor:    mysql.MySQLFlavor,
+		Host:      host,
+		Port:      uint16(port),
+		User:      cfg.User,
+		Password:  cfg.Passwd,
+		TLSConfig: tlsConfig,
+		// timestamps are parsed from strings, as with the ledger table
+		ParseTime:       false,
+		HeartbeatPeriod: 30 * time.Second,
+		ReadTimeout:     90 * time.Second,
+		Logger:          log.NewDefault(&log.NullHandler{}),
+	})
+
+	catchUp.lastSequence = lastSequence
+	return &binlogDmlSource{
+		catchUp:      catchUp,
+		schemaName:   cfg.DBName,
+		ledgerTable:  ledgerTable,
+		ledgerID:     ledgerID,
+		lastSequence: lastSequence,
+		wait:         binlogWait,
+		position: func(ctx context.Context) (mysql.Position, error) {
+			return binlogPosition(ctx, db)
+		},
+		startSync: func(pos mysql.Position) (binlogStream, error) {
+			return syncer.StartSync(pos)
+		},
+		closeSync: syncer.Close,
+	}, nil
+}
+
+// Next returns the next statement of the ledger, waiting a moment for one
+// to arrive in the binlog if there isn't one already. It returns
+// errNoNewStatements if none arrives.
+func (source *binlogDmlSource) Next(ctx context.Context) (schema.DMLStatement, error) {
+	if source.stream == nil {
+		if source.pos == nil {
+			pos, err := source.position(ctx)
+			if err != nil {
+				return schema.DMLStatement{}, err
+			}
+			source.pos = &pos
+		}
+		st, err := source.catchUp.Next(ctx)
+		if err == nil {
+			source.lastSequence = st.Sequence
+		}
+		if errors.Cause(err) != errNoNewStatements {
+			return st, err
+		}
+
+		stream, err := source.startSync(*source.pos)
+		if err != nil {
+			return schema.DMLStatement{}, errors.Wrapf(err, "start binlog sync at %s", source.pos)
+		}
+		source.stream = stream
+		events.Log("Caught up with ledger %{ledger}d at seq %{seq}d, streaming binlog from %{position}s",
+			source.ledgerID, source.lastSequence.Int(), source.pos.String())
+	}
+
+	if len(source.buffer) == 0 {
+		if err := source.read(ctx); err != nil {
+			return schema.DMLStatement{}, err
+		}
+	}
+	if len(source.buffer) == 0 {
+		return schema.DMLStatement{}, errNoNewStatements
+	}
+	st := source.buffer[0]
+	source.buffer = source.buffer[1:]
+	return st, nil
+}
+
+// read buffers the statements of the ledger inserts in the binlog, until
+// there are some or it has waited long enough.
+func (source *binlogDmlSource) read(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, source.wait)
+	defer cancel()
+	for len(source.buffer) == 0 {
+		ev, err := source.stream.GetEvent(ctx)
+		switch {
+		case err == context.DeadlineExceeded || err == context.Canceled:
+			return nil
+		case err != nil:
+			return errors.Wrap(err, "read binlog")
+		}
+		rows, ok := ev.Event.(*replication.RowsEvent)
+		if !ok || !isWriteRowsEvent(ev.Header.EventType) {
+			continue
+		}
+		if string(rows.Table.Schema) != source.schemaName || string(rows.Table.Table) != source.ledgerTable {
+			continue
+		}
+		for _, row := range rows.Rows {
+			st, err := source.statement(row)
+			if err != nil {
+				return err
+			}
+			if st.Sequence <= source.lastSequence {
+				// already read while catching up
+				stats.Incr("binlog_dml_source.duplicate_statement")
+				continue
+			}
+			if st.Sequence > source.lastSequence+1 {
+				stats.Incr("binlog_dml_source.skipped_sequence")
+			}
+			stats.Observe("binlog_dml_source.statement_size", len(st.Statement))
+			source.buffer = append(source.buffer, st)
+			source.lastSequence = st.Sequence
+		}
+	}
+	reportShovelBatch(source.ledgerID, len(source.buffer))
+	return nil
+}
+
+// statement converts a row inserted into the ledger table to a statement.
+func (source *binlogDmlSource) statement(row []interface{}) (schema.DMLStatement, error) {
+	if len(row) <= binlogStatementColumn {
+		return schema.DMLStatement{}, errors.Errorf("ledger row has %d columns, expected at least %d", len(row), binlogStatementColumn+1)
+	}
+	seq, ok := binlogInt(row[binlogSeqColumn])
+	if !ok {
+		return schema.DMLStatement{}, errors.Errorf("unexpected ledger seq %#v", row[binlogSeqColumn])
+	}
+	leaderTs, ok := binlogString(row[binlogLeaderTsColumn])
+	if !ok {
+		return schema.DMLStatement{}, errors.Errorf("unexpected ledger leader_ts %#v", row[binlogLeaderTsColumn])
+	}
+	statement, ok := binlogString(row[binlogStatementColumn])
+	if !ok {
+		return schema.DMLStatement{}, errors.Errorf("unexpected ledger statement %#v", row[binlogStatementColumn])
+	}
+	var version int64
+	if len(row) > binlogVersionColumn {
+		version, ok = binlogInt(row[binlogVersionColumn])
+		if !ok {
+			return schema.DMLStatement{}, errors.Errorf("unexpected ledger version %#v", row[binlogVersionColumn])
+		}
+	}
+	return source.catchUp.statement(seq, leaderTs, statement, int(version))
+}
+
+// pollInterval is short, since Next waits for statements itself.
+func (source *binlogDmlSource) pollInterval() time.Duration {
+	if source.stream == nil {
+		return source.catchUp.pollInterval()
+	}
+	return binlogMinPollInterval
+}
+
+// fetchRange re-reads the ledger table, which gap repair uses to fill in
+// sequences that were skipped over.
+func (source *binlogDmlSource) fetchRange(ctx context.Context, ledgerID int, from, to schema.DMLSequence) ([]schema.DMLStatement, error) {
+	return source.catchUp.fetchRange(ctx, ledgerID, from, to)
+}
+
+// Close stops streaming the binlog.
+func (source *binlogDmlSource) Close() error {
+	if source.closeSync != nil {
+		source.closeSync()
+	}
+	return nil
+}
+
+// binlogPosition returns the position that the CtlDB is writing its binlog
+// at.
+func binlogPosition(ctx context.Context, db *sql.DB) (mysql.Position, error) {
+	var pos mysql.Position
+	rows, err := db.QueryContext(ctx, "SHOW MASTER STATUS")
+	if err != nil {
+		return pos, errors.Wrap(err, "show master status")
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return pos, errors.Wrap(err, "show master status")
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return pos, errors.Wrap(err, "show master status")
+		}
+		return pos, errors.New("the upstream doesn't have binary logging enabled")
+	}
+	// the file and position come first, followed by columns which vary
+	// between versions
+	var offset uint64
+	dest := make([]interface{}, len(cols))
+	dest[0], dest[1] = &pos.Name, &offset
+	for i := 2; i < len(dest); i++ {
+		dest[i] = new(sql.RawBytes)
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return pos, errors.Wrap(err, "scan master status")
+	}
+	pos.Pos = uint32(offset)
+	return pos, rows.Err()
+}
+
+func isWriteRowsEvent(t replication.EventType) bool {
+	switch t {
+	case replication.WRITE_ROWS_EVENTv0, replication.WRITE_ROWS_EVENTv1, replication.WRITE_ROWS_EVENTv2:
+		return true
+	}
+	return false
+}
+
+// binlogInt converts a decoded integer column to an int64.
+func binlogInt(v interface{}) (int64, bool) {
+	switch v := v.(type) {
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), true
+	}
+	return 0, false
+}
+
+// binlogString converts a decoded text or datetime column to a string.
+func binlogString(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case []byte:
+		return string(v), true
+	case fmt.Stringer:
+		return v.String(), true
+	}
+	return "", false
+}
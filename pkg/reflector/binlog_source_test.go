@@ -0,0 +1,134 @@
+package reflector
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+type fakeBinlogStream struct {
+	events chan *replication.BinlogEvent
+	errs   chan error
+}
+
+func (s *fakeBinlogStream) GetEvent(ctx context.Context) (*replication.BinlogEvent, error) {
+	select {
+	case ev := <-s.events:
+		return ev, nil
+	case err := <-s.errs:
+		return nil, err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func binlogRowsEvent(schemaName, table string, rows ...[]interface{}) *replication.BinlogEvent {
+	return &replication.BinlogEvent{
+		Header: &replication.EventHeader{EventType: replication.WRITE_ROWS_EVENTv2},
+		Event: &replication.RowsEvent{
+			Table: &replication.TableMapEvent{Schema: []byte(schemaName), Table: []byte(table)},
+			Rows:  rows,
+		},
+	}
+}
+
+func TestBinlogDmlSource(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	srcutil := &sqlDmlSourceTestUtil{db: db, t: t}
+	srcutil.InitializeDB()
+	srcutil.AddStatement("INSERT INTO foo___bar VALUES('one')")
+	srcutil.AddStatement("INSERT INTO foo___bar VALUES('two')")
+
+	stream := &fakeBinlogStream{events: make(chan *replication.BinlogEvent, 10), errs: make(chan error, 1)}
+	binlogPos := mysql.Position{Name: "mysql-bin.000003", Pos: 1234}
+	var syncedFrom *mysql.Position
+	src := &binlogDmlSource{
+		catchUp:     &sqlDmlSource{db: db, ledgerTableName: "ctlstore_dml_ledger"},
+		schemaName:  "ctldb",
+		ledgerTable: "ctlstore_dml_ledger",
+		wait:        10 * time.Millisecond,
+		position: func(ctx context.Context) (mysql.Position, error) {
+			return binlogPos, nil
+		},
+		startSync: func(pos mysql.Position) (binlogStream, error) {
+			syncedFrom = &pos
+			return stream, nil
+		},
+	}
+
+	// statements written before the source started are read from the
+	// ledger table, starting at seq 2
+	for _, expected := range []string{"INSERT INTO foo___bar VALUES('one')", "INSERT INTO foo___bar VALUES('two')"} {
+		st, err := src.Next(ctx)
+		require.NoError(t, err)
+		require.Equal(t, expected, st.Statement)
+	}
+	require.Nil(t, syncedFrom)
+
+	// and then the binlog is streamed from where it was before catching up
+	_, err = src.Next(ctx)
+	require.Equal(t, errNoNewStatements, err)
+	require.Equal(t, &binlogPos, syncedFrom)
+	require.Equal(t, binlogMinPollInterval, src.pollInterval())
+
+	stream.events <- &replication.BinlogEvent{
+		Header: &replication.EventHeader{EventType: replication.QUERY_EVENT},
+		Event:  &replication.QueryEvent{Query: []byte("BEGIN")},
+	}
+	stream.events <- binlogRowsEvent("ctldb", "locks", []interface{}{"ledger", int64(7)})
+	stream.events <- binlogRowsEvent("other", "ctlstore_dml_ledger",
+		[]interface{}{int32(3), "2020-01-02 03:04:05", []byte("INSERT INTO other___bar VALUES('x')")})
+	stream.events <- binlogRowsEvent("ctldb", "ctlstore_dml_ledger",
+		// already read while catching up
+		[]interface{}{int32(3), "2020-01-02 03:04:05", []byte("INSERT INTO foo___bar VALUES('two')")},
+		[]interface{}{int32(4), "2020-01-02 03:04:05", []byte("INSERT INTO foo___bar VALUES('three')")},
+		// from a ledger with trace_id and version columns
+		[]interface{}{int32(5), "2020-01-02 03:04:06", []byte(`{"sql":"INSERT INTO foo___bar VALUES(?)","args":["four"]}`), nil, int32(schema.DMLVersionParameterized)})
+
+	st, err := src.Next(ctx)
+	require.NoError(t, err)
+	require.Equal(t, schema.DMLStatement{
+		Sequence:  4,
+		Statement: "INSERT INTO foo___bar VALUES('three')",
+		Timestamp: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, st)
+	st, err = src.Next(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 5, st.Sequence)
+	require.Equal(t, schema.DMLVersionParameterized, st.Version)
+	_, err = src.Next(ctx)
+	require.Equal(t, errNoNewStatements, err)
+
+	// gaps are repaired from the ledger table
+	srcutil.AddStatement("INSERT INTO foo___bar VALUES('three')")
+	missed, err := src.fetchRange(ctx, 0, 4, 4)
+	require.NoError(t, err)
+	require.Len(t, missed, 1)
+
+	stream.errs <- errors.New("connection reset")
+	_, err = src.Next(ctx)
+	require.EqualError(t, err, "read binlog: connection reset")
+}
+
+func TestNewBinlogDmlSource(t *testing.T) {
+	src, err := newBinlogDmlSource(nil, "user:pass@tcp(ctldb.local:3307)/ctldb", "ctlstore_dml_ledger", 2, 0, 10, &sqlDmlSource{})
+	require.NoError(t, err)
+	defer src.Close()
+	require.Equal(t, "ctldb", src.schemaName)
+	require.EqualValues(t, 10, src.lastSequence)
+	require.EqualValues(t, 10, src.catchUp.lastSequence)
+
+	_, err = newBinlogDmlSource(nil, "user:pass@tcp(ctldb.local:3307)/ctldb?tls=custom", "ctlstore_dml_ledger", 2, 0, 10, &sqlDmlSource{})
+	require.Error(t, err)
+}
@@ -0,0 +1,100 @@
+package reflector
+
+import (
+	"os"
+	"time"
+
+	"github.com/segmentio/ctlstore/pkg/ldbwriter"
+)
+
+const defaultMaxCheckpointDeferral = 5 * time.Minute
+
+// CheckpointSchedule decides when the WAL monitor checkpoints the WAL once
+// it has grown past its threshold. Checkpoints copy pages into the LDB and
+// so slow down reads, which can be avoided by deferring them while readers
+// are busy and by truncating the WAL during an off-peak window.
+type CheckpointSchedule struct {
+	// ReadLoad returns the reads per second currently being served from the
+	// LDB, for readers in the same process as the reflector. Checkpoints are
+	// deferred while it exceeds MaxReadQPS.
+	ReadLoad   func() float64
+	MaxReadQPS float64
+	// BusyFile defers checkpoints while the file exists, so that readers in
+	// other processes can create it while they're busy.
+	BusyFile string
+	// MaxDeferral is the longest a checkpoint is deferred for while readers
+	// stay busy, after which it's performed anyway so that the WAL can't
+	// grow without bound. Defaults to 5 minutes.
+	MaxDeferral time.Duration
+	// TruncateWindowStart and TruncateWindowEnd are a low-traffic window,
+	// given as offsets from midnight UTC, in which TRUNCATE checkpoints are
+	// performed instead of the configured type. They wait for readers, but
+	// reset the WAL to zero bytes. The window may wrap past midnight. If
+	// both are zero, the configured type is always used.
+	TruncateWindowStart time.Duration
+	TruncateWindowEnd   time.Duration
+}
+
+// busy reports whether readers are too busy for a checkpoint.
+func (s CheckpointSchedule) busy() bool {
+	if s.ReadLoad != nil && s.MaxReadQPS > 0 && s.ReadLoad() > s.MaxReadQPS {
+		return true
+	}
+	if s.BusyFile != "" {
+		if _, err := os.Stat(s.BusyFile); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (s CheckpointSchedule) maxDeferral() time.Duration {
+	if s.MaxDeferral > 0 {
+		return s.MaxDeferral
+	}
+	return defaultMaxCheckpointDeferral
+}
+
+// checkpointScheduler tracks how long a checkpoint has been deferred for.
+type checkpointScheduler struct {
+	schedule       CheckpointSchedule
+	checkpointType ldbwriter.CheckpointType
+	deferredSince  time.Time
+}
+
+// next returns the type of checkpoint to perform at the time, or false if
+// it should be deferred.
+func (c *checkpointScheduler) next(now time.Time) (typ ldbwriter.CheckpointType, ok bool) {
+	if c.schedule.busy() {
+		if c.deferredSince.IsZero() {
+			c.deferredSince = now
+		}
+		if now.Sub(c.deferredSince) < c.schedule.maxDeferral() {
+			return "", false
+		}
+	}
+	c.deferredSince = time.Time{}
+	if (c.schedule.TruncateWindowStart != 0 || c.schedule.TruncateWindowEnd != 0) &&
+		inDailyWindow(now, c.schedule.TruncateWindowStart, c.schedule.TruncateWindowEnd) {
+		return ldbwriter.Truncate, true
+	}
+	return c.checkpointType, true
+}
+
+// reset forgets a deferral once the WAL is back under its threshold without
+// a checkpoint, so that a later busy period is deferred for the full
+// MaxDeferral again.
+func (c *checkpointScheduler) reset() {
+	c.deferredSince = time.Time{}
+}
+
+// inDailyWindow reports whether t falls between the offsets from midnight
+// UTC, which may wrap past midnight.
+func inDailyWindow(t time.Time, start, end time.Duration) bool {
+	t = t.UTC()
+	offset := t.Sub(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC))
+	if start <= end {
+		return offset >= start && offset < end
+	}
+	return offset >= start || offset < end
+}
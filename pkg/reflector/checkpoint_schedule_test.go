@@ -0,0 +1,72 @@
+package reflector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/ldbwriter"
+)
+
+func TestCheckpointScheduler(t *testing.T) {
+	midnight := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	qps := 0.0
+	busyFile := filepath.Join(t.TempDir(), "busy")
+	c := checkpointScheduler{
+		schedule: CheckpointSchedule{
+			ReadLoad:            func() float64 { return qps },
+			MaxReadQPS:          100,
+			BusyFile:            busyFile,
+			MaxDeferral:         time.Minute,
+			TruncateWindowStart: 23 * time.Hour,
+			TruncateWindowEnd:   time.Hour,
+		},
+		checkpointType: ldbwriter.Passive,
+	}
+	next := func(now time.Time) ldbwriter.CheckpointType {
+		typ, ok := c.next(now)
+		if !ok {
+			return "deferred"
+		}
+		return typ
+	}
+
+	noon := midnight.Add(12 * time.Hour)
+	require.Equal(t, ldbwriter.Passive, next(noon))
+	require.Equal(t, ldbwriter.Truncate, next(midnight.Add(30*time.Minute)), "within the window")
+	require.Equal(t, ldbwriter.Truncate, next(midnight.Add(-30*time.Minute)), "the window wraps past midnight")
+
+	qps = 101
+	require.Equal(t, ldbwriter.CheckpointType("deferred"), next(noon))
+	require.Equal(t, ldbwriter.CheckpointType("deferred"), next(noon.Add(59*time.Second)))
+	require.Equal(t, ldbwriter.Passive, next(noon.Add(time.Minute)), "deferred for too long")
+	require.Equal(t, ldbwriter.CheckpointType("deferred"), next(noon.Add(time.Minute+time.Second)),
+		"the deferral restarts after a checkpoint")
+
+	qps = 100
+	require.Equal(t, ldbwriter.Passive, next(noon.Add(2*time.Minute)))
+
+	require.NoError(t, os.WriteFile(busyFile, nil, 0644))
+	require.Equal(t, ldbwriter.CheckpointType("deferred"), next(noon.Add(3*time.Minute)))
+	require.NoError(t, os.Remove(busyFile))
+	require.Equal(t, ldbwriter.Passive, next(noon.Add(3*time.Minute)))
+
+	// a deferral is forgotten once the WAL drops back under its threshold
+	qps = 101
+	require.Equal(t, ldbwriter.CheckpointType("deferred"), next(noon.Add(4*time.Minute)))
+	c.reset()
+	require.Equal(t, ldbwriter.CheckpointType("deferred"), next(noon.Add(10*time.Minute)),
+		"a later busy period is deferred again")
+}
+
+func TestCheckpointSchedulerDefaults(t *testing.T) {
+	c := checkpointScheduler{checkpointType: ldbwriter.Restart}
+	for hour := 0; hour < 24; hour++ {
+		typ, ok := c.next(time.Date(2020, 1, 2, hour, 0, 0, 0, time.UTC))
+		require.True(t, ok)
+		require.Equal(t, ldbwriter.Restart, typ)
+	}
+}
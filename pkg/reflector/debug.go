@@ -0,0 +1,102 @@
+package reflector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/segmentio/errors-go"
+
+	"github.com/segmentio/ctlstore"
+	"github.com/segmentio/ctlstore/pkg/ldb"
+)
+
+// DebugInfo is a snapshot of a reflector's progress, for diagnosing a
+// reflector which has stopped applying the ledger.
+type DebugInfo struct {
+	ID      string `json:"id"`
+	LDBPath string `json:"ldbPath"`
+	// LastSequences are the last sequences committed to the LDB, keyed by
+	// ledger name
+	LastSequences map[string]int64 `json:"lastSequences"`
+	LedgerLatency string           `json:"ledgerLatency,omitempty"`
+	// LDBSize and WALSize are the sizes in bytes of the LDB's file and its
+	// WAL, which are zero for an in-memory LDB
+	LDBSize int64 `json:"ldbSize"`
+	WALSize int64 `json:"walSize"`
+	// Changelog is set if the reflector writes a changelog
+	Changelog *ChangelogDebugInfo `json:"changelog,omitempty"`
+	// ShovelState is set if the reflector saves the shovel's state
+	ShovelState *ShovelState `json:"shovelState,omitempty"`
+}
+
+// ChangelogDebugInfo is how far the reflector has written the changelog.
+type ChangelogDebugInfo struct {
+	Path string `json:"path"`
+	// Seq is the seq of the last changelog entry written
+	Seq int64 `json:"seq"`
+	// Size is the size in bytes of the current changelog file, which is
+	// where its next entry is written
+	Size int64 `json:"size"`
+}
+
+// DebugInfo reads the reflector's progress from the LDB and the files it
+// writes.
+func (r *Reflector) DebugInfo(ctx context.Context) (DebugInfo, error) {
+	info := DebugInfo{
+		ID:            r.id,
+		LDBPath:       r.ldbPath,
+		LastSequences: make(map[string]int64, len(r.ledgers)),
+	}
+	for _, upstream := range r.ledgers {
+		seq, err := ldb.FetchLedgerSeqFromLdb(ctx, r.ldb, upstream.LedgerID)
+		if err != nil {
+			return DebugInfo{}, errors.Wrapf(err, "fetch seq of ledger %s", upstream.Name)
+		}
+		info.LastSequences[upstream.Name] = seq.Int()
+	}
+	if latency, err := ctlstore.NewLDBReaderFromDB(r.ldb).GetLedgerLatency(ctx); err == nil {
+		info.LedgerLatency = latency.Round(time.Millisecond).String()
+	}
+	if r.ldbPath != InMemoryLDBPath {
+		info.LDBSize = fileSize(r.ldbPath)
+		info.WALSize = fileSize(r.ldbPath + "-wal")
+	}
+	if clc := r.changelog.Load(); clc != nil {
+		info.Changelog = &ChangelogDebugInfo{
+			Path: r.changelogPath,
+			Seq:  atomic.LoadInt64(&clc.Seq),
+			Size: fileSize(r.changelogPath),
+		}
+	}
+	if r.state != nil {
+		state := r.state.snapshot()
+		info.ShovelState = &state
+	}
+	return info, nil
+}
+
+// DebugHandler serves the reflector's DebugInfo as JSON.
+func (r *Reflector) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		info, err := r.DebugInfo(req.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(info)
+	})
+}
+
+// fileSize returns the size of the file, or zero if it can't be read.
+func fileSize(path string) int64 {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return fi.Size()
+}
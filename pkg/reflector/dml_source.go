@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -14,7 +15,12 @@ import (
 
 const (
 	defaultQueryBlockSize    = 100
+	defaultQueryBlockBytes   = 8 * 1024 * 1024
 	dmlLedgerTimestampFormat = "2006-01-02 15:04:05"
+	// ledgers which don't have a version column yet are checked for it
+	// again this often, so that statement versions are read once the
+	// ctldb has been migrated
+	ledgerVersionsCheckInterval = time.Minute
 )
 
 var errNoNewStatements = errors.New("No new statements")
@@ -24,14 +30,30 @@ type dmlSource interface {
 	// TODO: probably need a last sequence fetcher
 }
 
+// a dmlSource which can re-read part of a ledger, which is used to fill in
+// sequences that were skipped over
+type ledgerRangeSource interface {
+	// fetchRange returns the statements from the ledger with sequences
+	// between from and to, inclusive, that exist so far
+	fetchRange(ctx context.Context, ledgerID int, from, to schema.DMLSequence) ([]schema.DMLStatement, error)
+}
+
+var errUnknownLedger = errors.New("unknown ledger")
+
 // a dmlSource built on top of a database/sql instance
 type sqlDmlSource struct {
 	db               *sql.DB
 	lastSequence     schema.DMLSequence
 	ledgerTableName  string
+	ledgerID         int
 	queryBlockSize   int
+	queryBlockBytes  int // stops filling the buffer once it holds this many bytes
 	buffer           []schema.DMLStatement
 	scanLoopCallBack func()
+	poller           *adaptivePoller   // nil unless polling is adaptive
+	file             *sqliteLedgerFile // nil unless the upstream is a SQLite file
+	versions         bool              // whether the ledger has a version column
+	versionsChecked  time.Time
 }
 
 // Next returns the next sequential statement in the source. If there are no
@@ -43,16 +65,39 @@ func (source *sqlDmlSource) Next(ctx context.Context) (statement schema.DMLState
 		if blocksize == 0 {
 			blocksize = defaultQueryBlockSize
 		}
+		blockBytes := source.queryBlockBytes
+		if blockBytes == 0 {
+			blockBytes = defaultQueryBlockBytes
+		}
+
+		if err := source.file.reopenIfRotated(); err != nil {
+			return statement, err
+		}
+		columns, err := source.ledgerColumns(ctx)
+		if isUpstreamOverloaded(err) {
+			return statement, errors.Wrap(errUpstreamOverloaded, err.Error())
+		}
+		if err != nil {
+			return statement, err
+		}
 
-		// table layout is: seq, leader_ts, statement
-		qs := sqlgen.SqlSprintf("SELECT seq, leader_ts, statement FROM $1 WHERE seq > ? ORDER BY seq LIMIT $2",
+		// table layout is: seq, leader_ts, statement[, version]
+		qs := sqlgen.SqlSprintf("SELECT $1 FROM $2 WHERE seq > ? ORDER BY seq LIMIT $3",
+			columns,
 			source.ledgerTableName,
 			fmt.Sprintf("%d", blocksize))
 
 		// HMM: do we lean too hard on the LIMIT here? in the loop below
 		// we'll end up spinning if the DB keeps feeding us data
 
+		start := time.Now()
 		rows, err := source.db.QueryContext(ctx, qs, source.lastSequence)
+		if source.poller != nil {
+			source.poller.observe(time.Since(start), err)
+		}
+		if isUpstreamOverloaded(err) {
+			return statement, errors.Wrap(errUpstreamOverloaded, err.Error())
+		}
 		if err != nil {
 			return statement, errors.Wrap(err, "select row")
 		}
@@ -65,9 +110,15 @@ func (source *sqlDmlSource) Next(ctx context.Context) (statement schema.DMLState
 			seq       int64
 			leaderTs  string // this is a string b/c the driver errors when trying to Scan into a *time.Time.
 			statement string
+			version   int
 		}{}
+		bufferedBytes := 0
 
-		for {
+		// rows are scanned one at a time, and no more are read once the
+		// buffer is full, so that a block of large statements doesn't
+		// have to be held in memory all at once. the buffer always takes
+		// at least one statement, however large.
+		for bufferedBytes < blockBytes {
 			if source.scanLoopCallBack != nil {
 				source.scanLoopCallBack()
 			}
@@ -76,24 +127,25 @@ func (source *sqlDmlSource) Next(ctx context.Context) (statement schema.DMLState
 				break
 			}
 
-			err = rows.Scan(&row.seq, &row.leaderTs, &row.statement)
+			dest := []interface{}{&row.seq, &row.leaderTs, &row.statement}
+			if source.versions {
+				dest = append(dest, &row.version)
+			}
+			err = rows.Scan(dest...)
 			if err != nil {
 				return statement, errors.Wrap(err, "scan row")
 			}
 
+			stats.Observe("sql_dml_source.statement_size", len(row.statement))
+			bufferedBytes += len(row.statement)
+
 			if schema.DMLSequence(row.seq) > source.lastSequence+1 {
 				stats.Incr("sql_dml_source.skipped_sequence")
 			}
 
-			timestamp, err := time.Parse(dmlLedgerTimestampFormat, row.leaderTs)
+			dmlst, err := source.statement(row.seq, row.leaderTs, row.statement, row.version)
 			if err != nil {
-				return statement, errors.Wrapf(err, "could not parse time '%s'", row.leaderTs)
-			}
-
-			dmlst := schema.DMLStatement{
-				Sequence:  schema.DMLSequence(row.seq),
-				Statement: row.statement,
-				Timestamp: timestamp,
+				return statement, err
 			}
 
 			source.buffer = append(source.buffer, dmlst)
@@ -108,6 +160,12 @@ func (source *sqlDmlSource) Next(ctx context.Context) (statement schema.DMLState
 		if err != nil {
 			return statement, errors.Wrap(err, "rows err")
 		}
+		if bufferedBytes >= blockBytes {
+			stats.Incr("sql_dml_source.byte_limited_blocks")
+		}
+		if len(source.buffer) > 0 {
+			reportShovelBatch(source.ledgerID, len(source.buffer))
+		}
 	}
 
 	// Still have to guard this case because source.buffer gets
@@ -122,3 +180,164 @@ func (source *sqlDmlSource) Next(ctx context.Context) (statement schema.DMLState
 	err = errNoNewStatements
 	return
 }
+
+func (source *sqlDmlSource) pollInterval() time.Duration {
+	if source.poller == nil {
+		return 0
+	}
+	return source.poller.interval
+}
+
+// ledgerColumns returns the columns of the ledger to select. The version
+// column is only selected once the ledger has it, since the statements of
+// ledgers which haven't been migrated to record versions are all SQL.
+func (source *sqlDmlSource) ledgerColumns(ctx context.Context) (string, error) {
+	if !source.versions && time.Since(source.versionsChecked) >= ledgerVersionsCheckInterval {
+		rows, err := source.db.QueryContext(ctx, sqlgen.SqlSprintf("SELECT * FROM $1 LIMIT 0", source.ledgerTableName))
+		if err != nil {
+			return "", errors.Wrap(err, "select ledger columns")
+		}
+		cols, err := rows.Columns()
+		rows.Close()
+		if err != nil {
+			return "", errors.Wrap(err, "read ledger columns")
+		}
+		for _, col := range cols {
+			if strings.EqualFold(col, "version") {
+				source.versions = true
+			}
+		}
+		source.versionsChecked = time.Now()
+	}
+	if source.versions {
+		return "seq, leader_ts, statement, version", nil
+	}
+	return "seq, leader_ts, statement", nil
+}
+
+func (source *sqlDmlSource) statement(seq int64, leaderTs, statement string, version int) (schema.DMLStatement, error) {
+	timestamp, err := time.Parse(dmlLedgerTimestampFormat, leaderTs)
+	if err != nil {
+		// the sqlite3 driver reads DATETIME columns as times, which are
+		// scanned into strings in this format
+		timestamp, err = time.Parse(time.RFC3339Nano, leaderTs)
+	}
+	if err != nil {
+		return schema.DMLStatement{}, errors.Wrapf(err, "could not parse time '%s'", leaderTs)
+	}
+	return schema.DMLStatement{
+		Sequence:  schema.DMLSequence(seq),
+		Statement: statement,
+		Timestamp: timestamp,
+		LedgerID:  source.ledgerID,
+		Version:   version,
+	}, nil
+}
+
+func (source *sqlDmlSource) fetchRange(ctx context.Context, ledgerID int, from, to schema.DMLSequence) ([]schema.DMLStatement, error) {
+	if ledgerID != source.ledgerID {
+		return nil, errUnknownLedger
+	}
+	columns, err := source.ledgerColumns(ctx)
+	if err != nil {
+		return nil, err
+	}
+	qs := sqlgen.SqlSprintf("SELECT $1 FROM $2 WHERE seq >= ? AND seq <= ? ORDER BY seq",
+		columns,
+		source.ledgerTableName)
+	rows, err := source.db.QueryContext(ctx, qs, from, to)
+	if err != nil {
+		return nil, errors.Wrap(err, "select range")
+	}
+	defer rows.Close()
+
+	var res []schema.DMLStatement
+	for rows.Next() {
+		var seq int64
+		var leaderTs, statement string
+		var version int
+		dest := []interface{}{&seq, &leaderTs, &statement}
+		if source.versions {
+			dest = append(dest, &version)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, errors.Wrap(err, "scan row")
+		}
+		st, err := source.statement(seq, leaderTs, statement, version)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, st)
+	}
+	return res, errors.Wrap(rows.Err(), "rows err")
+}
+
+// a dmlSource which merges the statements of several sources, one for each
+// upstream ledger of a sharded ctldb. Sources take turns, except that once a
+// ledger transaction has begun, its source is read from exclusively until the
+// transaction is committed, since the LDB writer can only have one ledger
+// transaction open at a time.
+type mergedDmlSource struct {
+	sources []dmlSource
+	next    int
+	inTx    bool
+}
+
+func (source *mergedDmlSource) Next(ctx context.Context) (schema.DMLStatement, error) {
+	if source.inTx {
+		return source.nextFrom(ctx, source.next)
+	}
+	for range source.sources {
+		idx := source.next
+		statement, err := source.nextFrom(ctx, idx)
+		if source.inTx {
+			return statement, err
+		}
+		source.next = (idx + 1) % len(source.sources)
+		if err != errNoNewStatements {
+			return statement, err
+		}
+	}
+	return schema.DMLStatement{}, errNoNewStatements
+}
+
+func (source *mergedDmlSource) nextFrom(ctx context.Context, idx int) (schema.DMLStatement, error) {
+	statement, err := source.sources[idx].Next(ctx)
+	if err != nil {
+		return statement, err
+	}
+	switch statement.Statement {
+	case schema.DMLTxBeginKey:
+		source.inTx = true
+	case schema.DMLTxEndKey:
+		source.inTx = false
+		source.next = (idx + 1) % len(source.sources)
+	}
+	return statement, nil
+}
+
+// pollInterval is the longest of the sources' poll intervals, since the
+// shovel polls them together.
+func (source *mergedDmlSource) pollInterval() time.Duration {
+	var res time.Duration
+	for _, src := range source.sources {
+		if as, ok := src.(adaptiveDmlSource); ok && as.pollInterval() > res {
+			res = as.pollInterval()
+		}
+	}
+	return res
+}
+
+func (source *mergedDmlSource) fetchRange(ctx context.Context, ledgerID int, from, to schema.DMLSequence) ([]schema.DMLStatement, error) {
+	for _, src := range source.sources {
+		rs, ok := src.(ledgerRangeSource)
+		if !ok {
+			continue
+		}
+		res, err := rs.fetchRange(ctx, ledgerID, from, to)
+		if err != errUnknownLedger {
+			return res, err
+		}
+	}
+	return nil, errUnknownLedger
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/segmentio/ctlstore/pkg/limits"
+	"github.com/segmentio/ctlstore/pkg/schema"
 	"github.com/segmentio/ctlstore/pkg/sqlgen"
 	"github.com/stretchr/testify/require"
 )
@@ -112,3 +114,151 @@ func TestSqlDmlSource(t *testing.T) {
 		t.Fatal("Expected a context error or an interrupted error")
 	}
 }
+
+func TestSqlDmlSourceBlockBytes(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+
+	srcutil := &sqlDmlSourceTestUtil{db: db, t: t}
+	srcutil.InitializeDB()
+
+	small := srcutil.AddStatement("INSERT INTO foo___bar VALUES('hi')")
+	large := srcutil.AddStatement("INSERT INTO foo___bar VALUES('" + strings.Repeat("x", 1000) + "')")
+	srcutil.AddStatement(small)
+	srcutil.AddStatement(small)
+
+	src := sqlDmlSource{
+		db:              db,
+		ledgerTableName: "ctlstore_dml_ledger",
+		queryBlockSize:  10,
+		queryBlockBytes: 100,
+	}
+
+	// the small statement is under the limit, so the large one is read
+	// too, even though it's over the limit on its own
+	st, err := src.Next(ctx)
+	require.NoError(t, err)
+	require.Equal(t, small, st.Statement)
+	require.Len(t, src.buffer, 1)
+	require.Equal(t, large, src.buffer[0].Statement)
+
+	st, err = src.Next(ctx)
+	require.NoError(t, err)
+	require.Equal(t, large, st.Statement)
+	require.Empty(t, src.buffer)
+
+	// the rest of the ledger is read in the next block
+	for i := 0; i < 2; i++ {
+		st, err = src.Next(ctx)
+		require.NoError(t, err)
+		require.Equal(t, small, st.Statement)
+	}
+	_, err = src.Next(ctx)
+	require.Equal(t, errNoNewStatements, err)
+}
+
+func TestSqlDmlSourceVersions(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+
+	srcutil := &sqlDmlSourceTestUtil{db: db, t: t}
+	srcutil.InitializeDB()
+	sqlStatement := srcutil.AddStatement("INSERT INTO foo___bar VALUES('hi')")
+
+	src := sqlDmlSource{
+		db:              db,
+		ledgerTableName: "ctlstore_dml_ledger",
+	}
+
+	// the statements of ledgers without a version column are SQL
+	st, err := src.Next(ctx)
+	require.NoError(t, err)
+	require.Equal(t, sqlStatement, st.Statement)
+	require.Equal(t, 0, st.Version)
+	require.False(t, src.versions)
+
+	// the column is found once the ledger is migrated
+	_, err = db.Exec("ALTER TABLE ctlstore_dml_ledger ADD COLUMN version INTEGER NOT NULL DEFAULT 1")
+	require.NoError(t, err)
+	parameterized := `{"sql":"INSERT INTO foo___bar VALUES(?)","args":["hi"]}`
+	_, err = db.Exec("INSERT INTO ctlstore_dml_ledger (statement, version) VALUES(?, ?)", parameterized, schema.DMLVersionParameterized)
+	require.NoError(t, err)
+	src.versionsChecked = time.Time{}
+
+	st, err = src.Next(ctx)
+	require.NoError(t, err)
+	require.Equal(t, parameterized, st.Statement)
+	require.Equal(t, schema.DMLVersionParameterized, st.Version)
+
+	sts, err := src.fetchRange(ctx, 0, st.Sequence-1, st.Sequence)
+	require.NoError(t, err)
+	require.Len(t, sts, 2)
+	require.Equal(t, schema.DMLVersionSQL, sts[0].Version)
+	require.Equal(t, schema.DMLVersionParameterized, sts[1].Version)
+}
+
+func TestMergedDmlSource(t *testing.T) {
+	ctx := context.Background()
+	var srcutils []*sqlDmlSourceTestUtil
+	var sources []dmlSource
+	for i := 0; i < 2; i++ {
+		db, err := sql.Open("sqlite3", ":memory:")
+		require.NoError(t, err)
+		srcutil := &sqlDmlSourceTestUtil{db: db, t: t}
+		srcutil.InitializeDB()
+		srcutils = append(srcutils, srcutil)
+		sources = append(sources, &sqlDmlSource{
+			db:              db,
+			ledgerTableName: "ctlstore_dml_ledger",
+			ledgerID:        i,
+		})
+	}
+	src := &mergedDmlSource{sources: sources}
+
+	_, err := src.Next(ctx)
+	require.Equal(t, errNoNewStatements, err)
+
+	srcutils[0].AddStatement(schema.DMLTxBeginKey)
+	srcutils[0].AddStatement("INSERT INTO foo___bar VALUES('a1')")
+	srcutils[1].AddStatement("INSERT INTO foo___bar VALUES('b1')")
+	srcutils[1].AddStatement("INSERT INTO foo___bar VALUES('b2')")
+
+	type result struct {
+		ledgerID  int
+		statement string
+	}
+	next := func() (result, error) {
+		st, err := src.Next(ctx)
+		return result{st.LedgerID, st.Statement}, err
+	}
+
+	// the open transaction on ledger 0 is read exclusively, even while it
+	// has no new statements
+	for _, want := range []result{
+		{0, schema.DMLTxBeginKey},
+		{0, "INSERT INTO foo___bar VALUES('a1')"},
+	} {
+		got, err := next()
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+	_, err = next()
+	require.Equal(t, errNoNewStatements, err)
+
+	srcutils[0].AddStatement(schema.DMLTxEndKey)
+	srcutils[0].AddStatement("INSERT INTO foo___bar VALUES('a2')")
+	for _, want := range []result{
+		{0, schema.DMLTxEndKey},
+		{1, "INSERT INTO foo___bar VALUES('b1')"},
+		{0, "INSERT INTO foo___bar VALUES('a2')"},
+		{1, "INSERT INTO foo___bar VALUES('b2')"},
+	} {
+		got, err := next()
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+	_, err = next()
+	require.Equal(t, errNoNewStatements, err)
+}
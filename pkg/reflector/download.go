@@ -3,8 +3,10 @@ package reflector
 import (
 	"bytes"
 	"compress/gzip"
+	"encoding/json"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
@@ -90,6 +92,163 @@ func (d *S3Downloader) getS3Client() (S3Client, error) {
 	return client, nil
 }
 
+// HTTPDownloader downloads an LDB snapshot over http(s), either from an
+// object store or from a peer's PeerSnapshotHandler.
+type HTTPDownloader struct {
+	URL string
+	// Gzip decompresses the snapshot. It's implied by a URL path ending
+	// in .gz.
+	Gzip bool // optional
+	// BearerToken is sent in the Authorization header if it's set
+	BearerToken         string       // optional
+	Client              *http.Client // optional
+	StartOverOnNotFound bool         // whether we should rebuild LDB if snapshot not found
+	// Source tags the snapshot_download_time metric
+	Source string // optional
+}
+
+func (d *HTTPDownloader) DownloadTo(w io.Writer) (n int64, err error) {
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	source := d.Source
+	if source == "" {
+		source = "http"
+	}
+	start := time.Now()
+	defer func() {
+		stats.Observe("snapshot_download_time", time.Since(start), stats.T("source", source))
+	}()
+	req, err := http.NewRequest(http.MethodGet, d.URL, nil)
+	if err != nil {
+		return -1, errors.Wrap(err, "build snapshot request")
+	}
+	if d.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+d.BearerToken)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return -1, errors.WithTypes(errors.Wrap(err, "get snapshot"), errs.ErrTypeTemporary)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode == http.StatusNotFound && d.StartOverOnNotFound:
+		// don't bother retrying. we'll start with a fresh ldb.
+		return -1, errors.WithTypes(errors.Errorf("snapshot server responded %s", resp.Status), errs.ErrTypePermanent)
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		// retrying won't fix the credentials
+		return -1, errors.Errorf("snapshot server responded %s", resp.Status)
+	default:
+		return -1, errors.WithTypes(errors.Errorf("snapshot server responded %s", resp.Status), errs.ErrTypeTemporary)
+	}
+
+	var body io.Reader = resp.Body
+	if parsed, err := url.Parse(d.URL); d.Gzip || (err == nil && strings.HasSuffix(parsed.Path, ".gz")) {
+		body, err = gzip.NewReader(body)
+		if err != nil {
+			return -1, errors.WithTypes(errors.Wrap(err, "create gzip reader"), errs.ErrTypeTemporary)
+		}
+	}
+
+	// make sure a database is being sent and not, say, an error page from
+	// a proxy, since whatever is downloaded becomes the LDB
+	header := make([]byte, len(sqliteHeader))
+	if _, err := io.ReadFull(body, header); err != nil {
+		return -1, errors.WithTypes(errors.Wrap(err, "read snapshot"), errs.ErrTypeTemporary)
+	}
+	if !bytes.Equal(header, sqliteHeader) {
+		return -1, errors.New("snapshot is not a SQLite database")
+	}
+	n, err = io.Copy(w, io.MultiReader(bytes.NewReader(header), body))
+	if err != nil {
+		return n, errors.WithTypes(errors.Wrap(err, "copy snapshot"), errs.ErrTypeTemporary)
+	}
+	if body == resp.Body && resp.ContentLength >= 0 && n != resp.ContentLength {
+		return n, errors.WithTypes(errors.Errorf("snapshot truncated at %d of %d bytes", n, resp.ContentLength), errs.ErrTypeTemporary)
+	}
+	return n, nil
+}
+
+const (
+	gcsEndpoint = "https://storage.googleapis.com"
+	// where GCE and GKE workloads get access tokens for their service account
+	gcsMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+)
+
+// GCSDownloader downloads an LDB snapshot from Google Cloud Storage with
+// the JSON API. Unless a BearerToken is configured, it authenticates with
+// an access token for the default service account from the GCE metadata
+// server.
+type GCSDownloader struct {
+	Bucket              string
+	Object              string
+	BearerToken         string       // optional
+	Client              *http.Client // optional
+	StartOverOnNotFound bool         // whether we should rebuild LDB if snapshot not found
+	Endpoint            string       // for testing
+	MetadataTokenURL    string       // for testing
+}
+
+func (d *GCSDownloader) DownloadTo(w io.Writer) (int64, error) {
+	token := d.BearerToken
+	if token == "" {
+		var err error
+		token, err = d.metadataToken()
+		if err != nil {
+			return -1, errors.WithTypes(errors.Wrap(err, "get gcs access token"), errs.ErrTypeTemporary)
+		}
+	}
+	endpoint := d.Endpoint
+	if endpoint == "" {
+		endpoint = gcsEndpoint
+	}
+	dl := &HTTPDownloader{
+		URL: endpoint + "/storage/v1/b/" + url.PathEscape(d.Bucket) +
+			"/o/" + url.PathEscape(d.Object) + "?alt=media",
+		// the object name is escaped in the URL, so whether it's compressed
+		// can't be told from the path
+		Gzip:                strings.HasSuffix(d.Object, ".gz"),
+		BearerToken:         token,
+		Client:              d.Client,
+		StartOverOnNotFound: d.StartOverOnNotFound,
+		Source:              "gcs",
+	}
+	return dl.DownloadTo(w)
+}
+
+func (d *GCSDownloader) metadataToken() (string, error) {
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	tokenURL := d.MetadataTokenURL
+	if tokenURL == "" {
+		tokenURL = gcsMetadataTokenURL
+	}
+	req, err := http.NewRequest(http.MethodGet, tokenURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("metadata server responded %s", resp.Status)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", errors.Wrap(err, "decode metadata token")
+	}
+	return token.AccessToken, nil
+}
+
 type memoryDownloader struct {
 	Content []byte
 }
@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"math/rand"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
@@ -181,3 +182,118 @@ func TestS3Downloader(t *testing.T) {
 		})
 	}
 }
+
+// Verifies that snapshots are downloaded over http(s), and that the status
+// of the response decides whether a failure is retried.
+func TestHTTPDownloader(t *testing.T) {
+	snapshot := []byte("SQLite format 3\x00the rest of the ldb")
+	var compressed bytes.Buffer
+	gw := gzip.NewWriter(&compressed)
+	_, err := gw.Write(snapshot)
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/snapshot.db":
+			w.Write(snapshot)
+		case "/snapshot.db.gz":
+			w.Write(compressed.Bytes())
+		case "/unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	for _, test := range []struct {
+		name         string
+		path         string
+		token        string
+		isSupervisor bool
+		err          string
+		errTypes     []string
+	}{
+		{name: "success", path: "/snapshot.db", token: "token"},
+		{name: "with compression", path: "/snapshot.db.gz", token: "token"},
+		{
+			name:  "unauthorized",
+			path:  "/snapshot.db",
+			token: "wrong",
+			err:   "snapshot server responded 401 Unauthorized",
+		},
+		{
+			name:     "temporary failure",
+			path:     "/unavailable",
+			token:    "token",
+			err:      "snapshot server responded 503 Service Unavailable",
+			errTypes: []string{"Temporary"},
+		},
+		{
+			name:         "permanent failure on 404 if supervisor",
+			path:         "/missing",
+			token:        "token",
+			isSupervisor: true,
+			err:          "snapshot server responded 404 Not Found",
+			errTypes:     []string{"Permanent"},
+		},
+		{
+			name:     "temporary failure on 404 if not-supervisor",
+			path:     "/missing",
+			token:    "token",
+			err:      "snapshot server responded 404 Not Found",
+			errTypes: []string{"Temporary"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			d := &reflector.HTTPDownloader{
+				URL:                 server.URL + test.path,
+				BearerToken:         test.token,
+				StartOverOnNotFound: test.isSupervisor,
+			}
+			var buf bytes.Buffer
+			n, err := d.DownloadTo(&buf)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				require.Equal(t, test.errTypes, errors.Types(err))
+				return
+			}
+			require.NoError(t, err)
+			require.EqualValues(t, len(snapshot), n)
+			require.Equal(t, snapshot, buf.Bytes())
+		})
+	}
+}
+
+func TestGCSDownloader(t *testing.T) {
+	snapshot := []byte("SQLite format 3\x00the rest of the ldb")
+	metadata := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Google", r.Header.Get("Metadata-Flavor"))
+		w.Write([]byte(`{"access_token":"metadata-token","expires_in":3599,"token_type":"Bearer"}`))
+	}))
+	defer metadata.Close()
+	gcs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/storage/v1/b/my-bucket/o/snapshots%2Fldb.db", r.URL.EscapedPath())
+		require.Equal(t, "media", r.URL.Query().Get("alt"))
+		require.Equal(t, "Bearer metadata-token", r.Header.Get("Authorization"))
+		w.Write(snapshot)
+	}))
+	defer gcs.Close()
+
+	d := &reflector.GCSDownloader{
+		Bucket:           "my-bucket",
+		Object:           "snapshots/ldb.db",
+		Endpoint:         gcs.URL,
+		MetadataTokenURL: metadata.URL,
+	}
+	var buf bytes.Buffer
+	n, err := d.DownloadTo(&buf)
+	require.NoError(t, err)
+	require.EqualValues(t, len(snapshot), n)
+	require.Equal(t, snapshot, buf.Bytes())
+}
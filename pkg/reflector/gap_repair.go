@@ -0,0 +1,117 @@
+package reflector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/segmentio/errors-go"
+	"github.com/segmentio/stats/v4"
+
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+const (
+	gapRepairInitialBackoff = 100 * time.Millisecond
+	gapRepairMaxBackoff     = 5 * time.Second
+)
+
+// GapReport describes a range of ledger sequences that the reflector skipped
+// over and which never showed up in the ledger. It is written to disk before
+// the shovel aborts.
+type GapReport struct {
+	LedgerID int                  `json:"ledgerID"`
+	From     schema.DMLSequence   `json:"from"`
+	To       schema.DMLSequence   `json:"to"`
+	Missing  []schema.DMLSequence `json:"missing"`
+	// Next is the sequence of the statement that came after the gap.
+	Next       schema.DMLSequence `json:"next"`
+	DetectedAt time.Time          `json:"detectedAt"`
+	Waited     string             `json:"waited"`
+}
+
+// repairGap waits up to the gap grace period for the skipped sequences
+// between from and to, inclusive, to appear in the ledger, returning them in
+// order once they all have. Sequences are usually skipped because the
+// transactions that wrote them to the ledger hadn't committed yet when the
+// ledger was read.
+func (s *shovel) repairGap(ctx context.Context, ledgerID int, from, to, next schema.DMLSequence) ([]schema.DMLStatement, error) {
+	src, ok := s.source.(ledgerRangeSource)
+	if !ok {
+		return nil, skippedSequenceError("source cannot repair gaps")
+	}
+
+	start := time.Now()
+	backoff := gapRepairInitialBackoff
+	var found []schema.DMLStatement
+	for {
+		var err error
+		found, err = src.fetchRange(ctx, ledgerID, from, to)
+		if err != nil {
+			return nil, errors.Wrap(err, "fetch skipped sequences")
+		}
+		if len(found) == int(to-from+1) {
+			stats.Incr("shovel.gap_repaired")
+			stats.Observe("shovel.gap_repair_time", time.Since(start))
+			s.logger().Log("shovel repaired gap from:%{fromSeq}d to:%{toSeq}d after %{waited}v", from, to, time.Since(start))
+			return found, nil
+		}
+		if time.Since(start) >= s.gapGracePeriod {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > gapRepairMaxBackoff {
+			backoff = gapRepairMaxBackoff
+		}
+	}
+
+	stats.Incr("shovel.gap_repair_failed")
+	report := GapReport{
+		LedgerID:   ledgerID,
+		From:       from,
+		To:         to,
+		Next:       next,
+		DetectedAt: start,
+		Waited:     time.Since(start).String(),
+	}
+	present := map[schema.DMLSequence]bool{}
+	for _, st := range found {
+		present[st.Sequence] = true
+	}
+	for seq := from; seq <= to; seq++ {
+		if !present[seq] {
+			report.Missing = append(report.Missing, seq)
+		}
+	}
+	path, err := s.writeGapReport(report)
+	if err != nil {
+		s.logger().Log("shovel could not write gap report: %{error}+v", err)
+	} else {
+		s.logger().Log("shovel wrote gap report to %{path}s", path)
+	}
+	return nil, skippedSequenceError(fmt.Sprintf("%d sequences never appeared", len(report.Missing)))
+}
+
+func (s *shovel) writeGapReport(report GapReport) (string, error) {
+	if s.gapReportDir == "" {
+		return "", errors.New("no gap report directory")
+	}
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("ledger-gap-%d-%d-%d-%d.json", report.LedgerID, report.From, report.To, report.DetectedAt.Unix())
+	path := filepath.Join(s.gapReportDir, name)
+	return path, os.WriteFile(path, b, 0644)
+}
+
+func skippedSequenceError(reason string) error {
+	return errors.WithTypes(errors.Errorf("shovel skipped sequence: %s", reason), "SkippedSequence")
+}
@@ -0,0 +1,95 @@
+package reflector
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/segmentio/errors-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+func TestShovelRepairsGaps(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		lateInsert  bool
+		ignoreSkips bool
+		expectSeqs  []schema.DMLSequence
+		expectAbort bool
+	}{
+		{name: "gap filled", lateInsert: true, expectSeqs: []schema.DMLSequence{1, 2, 3, 4}},
+		{name: "gap never filled", expectSeqs: []schema.DMLSequence{1, 2}, expectAbort: true},
+		{name: "skips ignored", lateInsert: true, ignoreSkips: true, expectSeqs: []schema.DMLSequence{1, 2, 4}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			db, err := sql.Open("sqlite3", filepath.Join(dir, "ctldb.db"))
+			require.NoError(t, err)
+			defer db.Close()
+			_, err = db.Exec(`CREATE TABLE ctlstore_dml_ledger (
+				seq INTEGER PRIMARY KEY AUTOINCREMENT,
+				leader_ts INTEGER NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				statement TEXT NOT NULL
+			)`)
+			require.NoError(t, err)
+			insert := func(seq int) {
+				_, err := db.Exec("INSERT INTO ctlstore_dml_ledger (seq, statement) VALUES(?, 'stmt')", seq)
+				require.NoError(t, err)
+			}
+			insert(1)
+			insert(2)
+			insert(4)
+
+			writer := &mockLdbWriter{}
+			shov := &shovel{
+				source: &sqlDmlSource{
+					db:              db,
+					ledgerTableName: "ctlstore_dml_ledger",
+				},
+				writer:         writer,
+				pollInterval:   10 * time.Millisecond,
+				pollTimeout:    time.Second,
+				abortOnSeqSkip: !test.ignoreSkips,
+				gapGracePeriod: 300 * time.Millisecond,
+				gapReportDir:   dir,
+			}
+			if test.lateInsert {
+				go func() {
+					time.Sleep(100 * time.Millisecond)
+					insert(3)
+				}()
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 600*time.Millisecond)
+			defer cancel()
+			err = shov.Start(ctx)
+
+			var seqs []schema.DMLSequence
+			for _, st := range writer.applied {
+				seqs = append(seqs, st.Sequence)
+			}
+			require.Equal(t, test.expectSeqs, seqs)
+			if !test.expectAbort {
+				require.Equal(t, context.DeadlineExceeded, errors.Cause(err))
+				return
+			}
+
+			require.True(t, errors.Is("SkippedSequence", err), "unexpected error: %v", err)
+			reports, err := filepath.Glob(filepath.Join(dir, "ledger-gap-0-3-3-*.json"))
+			require.NoError(t, err)
+			require.Len(t, reports, 1)
+			b, err := os.ReadFile(reports[0])
+			require.NoError(t, err)
+			var report GapReport
+			require.NoError(t, json.Unmarshal(b, &report))
+			require.Equal(t, []schema.DMLSequence{3}, report.Missing)
+			require.EqualValues(t, 4, report.Next)
+		})
+	}
+}
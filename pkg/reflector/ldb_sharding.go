@@ -0,0 +1,143 @@
+package reflector
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+
+	"github.com/segmentio/errors-go"
+	"github.com/segmentio/events/v2"
+
+	"github.com/segmentio/ctlstore/pkg/ldbwriter"
+	"github.com/segmentio/ctlstore/pkg/schema"
+	"github.com/segmentio/ctlstore/pkg/sqlgen"
+)
+
+// LDBShardingSpec splits the families of a ledger between several LDBs, so
+// that heavy families get LDBs of their own and readers only open the LDBs
+// of the families they need. It is usually loaded from a JSON file such as:
+//
+//	{
+//	  "shards": [
+//	    {"ldbPath": "/var/spool/ctlstore/heavy.db", "families": ["heavy"]},
+//	    {"ldbPath": "/var/spool/ctlstore/users.db", "families": ["users", "accounts"]}
+//	  ]
+//	}
+//
+// Families which aren't listed stay in the reflector's own LDB. Each LDB is
+// reflected separately, and tracks the ledger's sequence on its own. Since
+// an LDB never receives the statements of another shard's families, moving
+// a family between shards requires both LDBs to be rebuilt.
+type LDBShardingSpec struct {
+	Shards []LDBShard `json:"shards"`
+}
+
+// LDBShard is an LDB which holds a subset of the families.
+type LDBShard struct {
+	LDBPath  string   `json:"ldbPath"`
+	Families []string `json:"families"`
+	// ChangelogPath, if set, is where the shard writes its own changelog.
+	ChangelogPath string `json:"changelogPath"`
+}
+
+// LoadLDBShardingSpec reads a JSON encoded LDBShardingSpec from the file at
+// path.
+func LoadLDBShardingSpec(path string) (LDBShardingSpec, error) {
+	var spec LDBShardingSpec
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return spec, errors.Wrap(err, "read ldb sharding spec")
+	}
+	if err := json.Unmarshal(b, &spec); err != nil {
+		return spec, errors.Wrap(err, "decode ldb sharding spec")
+	}
+	return spec, spec.Validate()
+}
+
+// Validate checks that every shard has its own LDB path and at least one
+// family, and that no family belongs to more than one shard.
+func (spec LDBShardingSpec) Validate() error {
+	paths := map[string]bool{}
+	families := map[string]int{}
+	for i, shard := range spec.Shards {
+		if shard.LDBPath == "" {
+			return errors.Errorf("ldb shard %d has no ldbPath", i)
+		}
+		if shard.LDBPath == InMemoryLDBPath {
+			return errors.Errorf("ldb shard %d can't be in memory", i)
+		}
+		if paths[shard.LDBPath] {
+			return errors.Errorf("ldb shard %d reuses ldbPath %s", i, shard.LDBPath)
+		}
+		paths[shard.LDBPath] = true
+		if len(shard.Families) == 0 {
+			return errors.Errorf("ldb shard %d has no families", i)
+		}
+		for _, family := range shard.Families {
+			if _, err := schema.NewFamilyName(family); err != nil {
+				return errors.Wrapf(err, "ldb shard %d family %q", i, family)
+			}
+			if other, ok := families[family]; ok {
+				return errors.Errorf("family %s belongs to both ldb shards %d and %d", family, other, i)
+			}
+			families[family] = i
+		}
+	}
+	return nil
+}
+
+// RemainderFilter selects the families which don't belong to any shard,
+// which are applied to the reflector's own LDB.
+func (spec LDBShardingSpec) RemainderFilter() ldbwriter.FamilyFilter {
+	var filter ldbwriter.FamilyFilter
+	for _, shard := range spec.Shards {
+		filter.Exclude = append(filter.Exclude, shard.Families...)
+	}
+	return filter
+}
+
+// Filter selects the families of the shard.
+func (shard LDBShard) Filter() ldbwriter.FamilyFilter {
+	return ldbwriter.FamilyFilter{Include: shard.Families}
+}
+
+// dropFilteredTables drops the tables of the families which the LDB no
+// longer receives statements for, such as those of an LDB which was
+// bootstrapped from a snapshot of every family, since they'd only go stale.
+func dropFilteredTables(ctx context.Context, db *sql.DB, filter ldbwriter.FamilyFilter) error {
+	if !filter.Enabled() {
+		return nil
+	}
+	rows, err := db.QueryContext(ctx, "SELECT name FROM sqlite_master WHERE type = 'table'")
+	if err != nil {
+		return errors.Wrap(err, "list ldb tables")
+	}
+	var drop []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return errors.Wrap(err, "scan ldb table")
+		}
+		family, _, err := schema.DecodeLDBTableName(name)
+		if err != nil {
+			// not a family's table
+			continue
+		}
+		if !filter.AppliesToFamily(family.Name) {
+			drop = append(drop, name)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return errors.Wrap(err, "list ldb tables")
+	}
+	for _, name := range drop {
+		if _, err := db.ExecContext(ctx, sqlgen.SqlSprintf("DROP TABLE $1", name)); err != nil {
+			return errors.Wrapf(err, "drop table %s", name)
+		}
+		events.Log("Dropped LDB table %{table}s, whose family isn't reflected into this LDB", name)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package reflector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/ldb"
+	"github.com/segmentio/ctlstore/pkg/ldbwriter"
+)
+
+func TestLoadLDBShardingSpec(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ldb-sharding.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"shards": [
+			{"ldbPath": "/tmp/heavy.db", "families": ["heavy"]},
+			{"ldbPath": "/tmp/users.db", "families": ["users", "accounts"], "changelogPath": "/tmp/users.changelog"}
+		]
+	}`), 0644))
+
+	spec, err := LoadLDBShardingSpec(path)
+	require.NoError(t, err)
+	require.Len(t, spec.Shards, 2)
+	require.Equal(t, "/tmp/users.changelog", spec.Shards[1].ChangelogPath)
+	require.Equal(t, ldbwriter.FamilyFilter{Exclude: []string{"heavy", "users", "accounts"}}, spec.RemainderFilter())
+	require.Equal(t, ldbwriter.FamilyFilter{Include: []string{"heavy"}}, spec.Shards[0].Filter())
+}
+
+func TestLDBShardingSpecValidate(t *testing.T) {
+	for _, test := range []struct {
+		desc string
+		spec LDBShardingSpec
+	}{
+		{"no path", LDBShardingSpec{Shards: []LDBShard{{Families: []string{"heavy"}}}}},
+		{"in memory", LDBShardingSpec{Shards: []LDBShard{{LDBPath: InMemoryLDBPath, Families: []string{"heavy"}}}}},
+		{"no families", LDBShardingSpec{Shards: []LDBShard{{LDBPath: "a.db"}}}},
+		{"invalid family", LDBShardingSpec{Shards: []LDBShard{{LDBPath: "a.db", Families: []string{"not__valid"}}}}},
+		{"reused path", LDBShardingSpec{Shards: []LDBShard{
+			{LDBPath: "a.db", Families: []string{"heavy"}},
+			{LDBPath: "a.db", Families: []string{"users"}},
+		}}},
+		{"family in two shards", LDBShardingSpec{Shards: []LDBShard{
+			{LDBPath: "a.db", Families: []string{"heavy"}},
+			{LDBPath: "b.db", Families: []string{"heavy"}},
+		}}},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			require.Error(t, test.spec.Validate())
+		})
+	}
+}
+
+func TestDropFilteredTables(t *testing.T) {
+	ctx := context.Background()
+	db, teardown := ldb.LDBForTest(t)
+	defer teardown()
+
+	for _, table := range []string{"heavy___table1", "heavy___table2", "users___table1"} {
+		_, err := db.Exec("CREATE TABLE " + table + " (val VARCHAR)")
+		require.NoError(t, err)
+	}
+	require.NoError(t, dropFilteredTables(ctx, db, ldbwriter.FamilyFilter{Include: []string{"heavy"}}))
+
+	rows, err := db.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name LIKE '%\\_\\_\\_%' ESCAPE '\\' ORDER BY name")
+	require.NoError(t, err)
+	defer rows.Close()
+	var tables []string
+	for rows.Next() {
+		var name string
+		require.NoError(t, rows.Scan(&name))
+		tables = append(tables, name)
+	}
+	require.NoError(t, rows.Err())
+	require.Equal(t, []string{"heavy___table1", "heavy___table2"}, tables)
+
+	// the LDB's own tables are left alone
+	_, err = ldb.FetchSeqFromLdb(ctx, db)
+	require.NoError(t, err)
+}
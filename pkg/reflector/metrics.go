@@ -0,0 +1,96 @@
+package reflector
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/segmentio/stats/v4"
+
+	"github.com/segmentio/ctlstore/pkg/ldbwriter"
+	"github.com/segmentio/ctlstore/pkg/ledger"
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+// The WAL monitor and the shovel report these structured metrics alongside
+// their other stats. Their fields carry their types, so that the prometheus
+// handler on /metrics exposes them as gauges, counters and histograms rather
+// than untyped values.
+
+type walSizeMetrics struct {
+	LDB string `tag:"ldb"`
+
+	SizeBytes int64 `metric:"wal_size_bytes" type:"gauge"`
+}
+
+type walPagesMetrics struct {
+	LDB string `tag:"ldb"`
+
+	LogPages          int `metric:"wal_log_pages" type:"gauge"`
+	CheckpointedPages int `metric:"wal_checkpointed_pages" type:"gauge"`
+}
+
+type walCheckpointMetrics struct {
+	LDB  string `tag:"ldb"`
+	Type string `tag:"type"`
+	Busy string `tag:"busy"`
+
+	Count    int           `metric:"wal_checkpoints_total" type:"counter"`
+	Duration time.Duration `metric:"wal_checkpoint_duration_seconds" type:"histogram"`
+}
+
+type shovelBatchMetrics struct {
+	Ledger string `tag:"ledger"`
+
+	Size int `metric:"shovel_batch_size" type:"histogram"`
+}
+
+type shovelApplyMetrics struct {
+	Ledger string `tag:"ledger"`
+
+	Applied     int   `metric:"shovel_statements_applied_total" type:"counter"`
+	LastApplied int64 `metric:"shovel_last_applied_seq" type:"gauge"`
+}
+
+// RegisterPrometheusBuckets registers the buckets of the histograms above
+// for a stats engine with the given prefix, since the prometheus handler
+// ignores histograms that have none.
+func RegisterPrometheusBuckets(prefix string) {
+	stats.Buckets.Set(prefix+".wal_checkpoint_duration_seconds",
+		time.Millisecond, 5*time.Millisecond, 25*time.Millisecond, 100*time.Millisecond,
+		250*time.Millisecond, time.Second, 5*time.Second, 30*time.Second)
+	stats.Buckets.Set(prefix+".shovel_batch_size",
+		1, 5, 10, 25, 50, 100, 250, 500, 1000)
+	// the reflector runs the ledger monitor too
+	ledger.RegisterPrometheusBuckets(prefix)
+}
+
+func reportWALSize(ldb string, size int64) {
+	stats.Report(walSizeMetrics{LDB: ldb, SizeBytes: size})
+}
+
+func reportWALCheckpoint(ldb string, res *ldbwriter.PragmaWALResult, busy string, took time.Duration) {
+	stats.Report(walPagesMetrics{
+		LDB:               ldb,
+		LogPages:          res.Log,
+		CheckpointedPages: res.Checkpointed,
+	})
+	stats.Report(walCheckpointMetrics{
+		LDB:      ldb,
+		Type:     string(res.Type),
+		Busy:     busy,
+		Count:    1,
+		Duration: took,
+	})
+}
+
+func reportShovelBatch(ledgerID int, size int) {
+	stats.Report(shovelBatchMetrics{Ledger: strconv.Itoa(ledgerID), Size: size})
+}
+
+func reportShovelApplied(st schema.DMLStatement) {
+	stats.Report(shovelApplyMetrics{
+		Ledger:      strconv.Itoa(st.LedgerID),
+		Applied:     1,
+		LastApplied: st.Sequence.Int(),
+	})
+}
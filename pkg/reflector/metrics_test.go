@@ -0,0 +1,54 @@
+package reflector
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/segmentio/stats/v4"
+	"github.com/segmentio/stats/v4/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/ldbwriter"
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	h := &prometheus.Handler{}
+	original := stats.DefaultEngine
+	stats.DefaultEngine = stats.NewEngine("ctlstore.reflector", h)
+	defer func() { stats.DefaultEngine = original }()
+	RegisterPrometheusBuckets("ctlstore.reflector")
+
+	reportWALSize("ldb.db-wal", 4096)
+	res := &ldbwriter.PragmaWALResult{Log: 10, Checkpointed: 8, Type: ldbwriter.Passive}
+	reportWALCheckpoint("ldb.db-wal", res, "false", 20*time.Millisecond)
+	reportWALCheckpoint("ldb.db-wal", res, "false", 2*time.Second)
+	reportShovelBatch(0, 42)
+	reportShovelApplied(schema.DMLStatement{Sequence: 7})
+	reportShovelApplied(schema.DMLStatement{Sequence: 8})
+
+	var buf bytes.Buffer
+	h.WriteStats(&buf)
+	out := buf.String()
+
+	for _, line := range []string{
+		"# TYPE ctlstore_reflector_wal_size_bytes gauge",
+		`ctlstore_reflector_wal_size_bytes{ldb="ldb.db-wal"} 4096`,
+		"# TYPE ctlstore_reflector_wal_checkpointed_pages gauge",
+		`ctlstore_reflector_wal_checkpointed_pages{ldb="ldb.db-wal"} 8`,
+		"# TYPE ctlstore_reflector_wal_checkpoints_total counter",
+		`ctlstore_reflector_wal_checkpoints_total{busy="false",ldb="ldb.db-wal",type="PASSIVE"} 2`,
+		"# TYPE ctlstore_reflector_wal_checkpoint_duration_seconds histogram",
+		`ctlstore_reflector_wal_checkpoint_duration_seconds_bucket{busy="false",ldb="ldb.db-wal",type="PASSIVE",le="0.025"} 1`,
+		`ctlstore_reflector_wal_checkpoint_duration_seconds_count{busy="false",ldb="ldb.db-wal",type="PASSIVE"} 2`,
+		"# TYPE ctlstore_reflector_shovel_batch_size histogram",
+		`ctlstore_reflector_shovel_batch_size_bucket{ledger="0",le="50"} 1`,
+		"# TYPE ctlstore_reflector_shovel_statements_applied_total counter",
+		`ctlstore_reflector_shovel_statements_applied_total{ledger="0"} 2`,
+		"# TYPE ctlstore_reflector_shovel_last_applied_seq gauge",
+		`ctlstore_reflector_shovel_last_applied_seq{ledger="0"} 8`,
+	} {
+		require.Contains(t, out, line)
+	}
+}
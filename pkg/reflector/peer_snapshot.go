@@ -0,0 +1,132 @@
+package reflector
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/segmentio/errors-go"
+	"github.com/segmentio/events/v2"
+	"github.com/segmentio/go-sqlite3"
+	"github.com/segmentio/stats/v4"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+)
+
+// sqliteHeader starts every SQLite database file.
+var sqliteHeader = []byte("SQLite format 3\x00")
+
+// PeerSnapshotHandler serves a consistent copy of a live LDB so that other
+// reflectors can bootstrap from it with a bootstrap URL pointing at the
+// handler, instead of downloading a snapshot from S3. The copy is taken
+// with the SQLite backup API, so the reflector keeps applying the ledger
+// while it's made. One copy is served at a time and concurrent requests
+// are turned away with a 503, which the bootstrapping reflector retries.
+type PeerSnapshotHandler struct {
+	LDBPath string
+	// TempDir is where copies are staged while they're served. Defaults to
+	// the directory of the LDB.
+	TempDir string // optional
+
+	busy chan struct{}
+}
+
+// NewPeerSnapshotHandler returns a handler serving copies of the LDB at
+// ldbPath.
+func NewPeerSnapshotHandler(ldbPath string) *PeerSnapshotHandler {
+	return &PeerSnapshotHandler{
+		LDBPath: ldbPath,
+		busy:    make(chan struct{}, 1),
+	}
+}
+
+func (h *PeerSnapshotHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	select {
+	case h.busy <- struct{}{}:
+		defer func() { <-h.busy }()
+	default:
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "already serving a snapshot", http.StatusServiceUnavailable)
+		return
+	}
+
+	start := time.Now()
+	path, err := h.backup(r.Context())
+	if path != "" {
+		defer os.Remove(path)
+	}
+	if err != nil {
+		events.Log("Could not copy LDB for peer %{peer}s: %{error}+v", r.RemoteAddr, err)
+		errs.Incr("peer_snapshot_errors")
+		http.Error(w, "could not copy the LDB", http.StatusInternalServerError)
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		errs.Incr("peer_snapshot_errors")
+		http.Error(w, "could not open the LDB copy", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+
+	events.Log("Serving LDB snapshot to peer %{peer}s, copied in %{duration}v", r.RemoteAddr, time.Since(start))
+	stats.Incr("peer_snapshots_served")
+	w.Header().Set("Content-Type", "application/vnd.sqlite3")
+	http.ServeContent(w, r, filepath.Base(h.LDBPath), start, f)
+}
+
+// backup copies the LDB to a temporary file and returns its path.
+func (h *PeerSnapshotHandler) backup(ctx context.Context) (string, error) {
+	dir := h.TempDir
+	if dir == "" {
+		dir = filepath.Dir(h.LDBPath)
+	}
+	if _, err := os.Stat(h.LDBPath); err != nil {
+		return "", errors.Wrap(err, "stat ldb")
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(h.LDBPath)+".peer-*")
+	if err != nil {
+		return "", errors.Wrap(err, "create temp file")
+	}
+	path := tmp.Name()
+	tmp.Close()
+
+	var drv sqlite3.SQLiteDriver
+	src, err := drv.Open("file:" + h.LDBPath + "?mode=ro")
+	if err != nil {
+		return path, errors.Wrap(err, "open ldb")
+	}
+	defer src.Close()
+	dst, err := drv.Open(path)
+	if err != nil {
+		return path, errors.Wrap(err, "open copy")
+	}
+	defer dst.Close()
+
+	bk, err := dst.(*sqlite3.SQLiteConn).Backup("main", src.(*sqlite3.SQLiteConn), "main")
+	if err != nil {
+		return path, errors.Wrap(err, "start backup")
+	}
+	// copying every page in one step holds a read transaction on the LDB
+	// for the duration, which in WAL mode doesn't block the reflector, and
+	// keeps the backup from restarting whenever the reflector writes
+	done, err := bk.Step(-1)
+	if err != nil {
+		bk.Finish()
+		return path, errors.Wrap(err, "backup")
+	}
+	if !done {
+		bk.Finish()
+		return path, errors.New("backup did not complete")
+	}
+	if err := bk.Finish(); err != nil {
+		return path, errors.Wrap(err, "finish backup")
+	}
+	return path, ctx.Err()
+}
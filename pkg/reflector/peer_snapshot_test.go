@@ -0,0 +1,63 @@
+package reflector
+
+import (
+	"database/sql"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerSnapshotBootstrap(t *testing.T) {
+	dir := t.TempDir()
+	peerPath := filepath.Join(dir, "peer.db")
+	peerDB, err := sql.Open("sqlite3", peerPath+"?_journal_mode=wal")
+	require.NoError(t, err)
+	defer peerDB.Close()
+	_, err = peerDB.Exec("CREATE TABLE family1___table1 (id INTEGER PRIMARY KEY, name VARCHAR)")
+	require.NoError(t, err)
+	_, err = peerDB.Exec("INSERT INTO family1___table1 VALUES (1, 'one'), (2, 'two')")
+	require.NoError(t, err)
+
+	server := httptest.NewServer(NewPeerSnapshotHandler(peerPath))
+	defer server.Close()
+
+	path := filepath.Join(dir, "ldb.db")
+	err = bootstrapLDB(ldbBootstrapConfig{
+		url:  server.URL + "/ldb-snapshot",
+		path: path,
+	})
+	require.NoError(t, err)
+
+	db, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	defer db.Close()
+	var count int
+	err = db.QueryRow("SELECT COUNT(*) FROM family1___table1").Scan(&count)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+}
+
+func TestPeerSnapshotHandlerBusy(t *testing.T) {
+	h := NewPeerSnapshotHandler(filepath.Join(t.TempDir(), "ldb.db"))
+	h.busy <- struct{}{}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ldb-snapshot", nil))
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.Equal(t, "1", w.Header().Get("Retry-After"))
+}
+
+func TestPeerDownloaderRejectsNonSQLite(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html>this is not the LDB you are looking for</html>"))
+	}))
+	defer server.Close()
+
+	d := &HTTPDownloader{URL: server.URL}
+	_, err := d.DownloadTo(io.Discard)
+	require.EqualError(t, err, "snapshot is not a SQLite database")
+}
@@ -0,0 +1,72 @@
+package reflector
+
+import (
+	"context"
+	"os"
+
+	"github.com/segmentio/errors-go"
+	"github.com/segmentio/events/v2"
+	"github.com/segmentio/stats/v4"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+	"github.com/segmentio/ctlstore/pkg/ldb"
+)
+
+// rebootstrapIfBehind rebuilds the LDB from the bootstrap URL when it's more
+// than config.RebootstrapLag sequences behind any of the ledgers, such as an
+// LDB restored from an old disk, since downloading a snapshot is much
+// quicker than replaying that much of the ledger. The rebuild jitter applies
+// as it does to a rebuild.
+//
+// The LDB at ldbPath is the one that's currently used in place of the
+// configured one, and the path of the LDB to use from then on is returned.
+// If the snapshot can't be downloaded, the LDB is kept and catches up by
+// replaying the ledger as usual.
+func rebootstrapIfBehind(ctx context.Context, config ReflectorConfig, ldbPath string, ledgers []UpstreamShard, maxKnownSeqs map[int]int64) (string, error) {
+	if _, err := os.Stat(ldbPath); os.IsNotExist(err) {
+		// there's nothing to catch up
+		return ldbPath, nil
+	}
+	lag, ledgerName, err := ldbLag(ctx, ldbPath, ledgers, maxKnownSeqs)
+	if err != nil {
+		return "", errors.Wrap(err, "measure ldb lag")
+	}
+	stats.Set("ldb-startup-lag", lag)
+	if lag <= config.RebootstrapLag {
+		return ldbPath, nil
+	}
+	events.Log("Rebootstrap: the LDB is %{lag}d sequences behind ledger %{ledger}s, more than %{max}d, so it's being bootstrapped again",
+		lag, ledgerName, config.RebootstrapLag)
+
+	// a missing snapshot would otherwise be replaced with an empty LDB,
+	// which is even further behind
+	config.IsSupervisor = false
+	newPath, err := rebuildLDB(ctx, config)
+	if err != nil {
+		errs.Incr("ldb-rebootstrap-errors")
+		events.Log("Rebootstrap: failed, so the LDB will replay the ledger instead: %{error}+v", err)
+		return ldbPath, nil
+	}
+	stats.Incr("ldb-rebootstraps")
+	return newPath, nil
+}
+
+// ldbLag returns the most sequences that the LDB at the path is behind any
+// of the ledgers, and the name of that ledger.
+func ldbLag(ctx context.Context, path string, ledgers []UpstreamShard, maxKnownSeqs map[int]int64) (lag int64, ledgerName string, err error) {
+	db, err := ldb.OpenLDB(path, "ro")
+	if err != nil {
+		return 0, "", err
+	}
+	defer db.Close()
+	for _, ledger := range ledgers {
+		seq, err := ldb.FetchLedgerSeqFromLdb(ctx, db, ledger.LedgerID)
+		if err != nil {
+			return 0, "", errors.Wrapf(err, "fetch seq of ledger %s", ledger.Name)
+		}
+		if l := maxKnownSeqs[ledger.LedgerID] - seq.Int(); l > lag || ledgerName == "" {
+			lag, ledgerName = l, ledger.Name
+		}
+	}
+	return lag, ledgerName, nil
+}
@@ -0,0 +1,102 @@
+package reflector
+
+import (
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore"
+	"github.com/segmentio/ctlstore/pkg/ldb"
+)
+
+func TestRebootstrapIfBehind(t *testing.T) {
+	ctx := context.Background()
+	snapshotPath := filepath.Join(t.TempDir(), "snapshot.db")
+	newTestLDBFile(t, snapshotPath, "family___snapshot")
+	snapshot, err := ioutil.ReadFile(snapshotPath)
+	require.NoError(t, err)
+	snapshotURL := "data:" + base64.URLEncoding.EncodeToString(snapshot)
+
+	ledgers := []UpstreamShard{{Name: "primary"}, {Name: "ledger-1", LedgerID: 1}}
+
+	for _, test := range []struct {
+		name         string
+		bootstrapURL string
+		maxKnownSeqs map[int]int64
+		rebootstrap  bool
+	}{
+		{
+			name:         "caught up",
+			bootstrapURL: snapshotURL,
+			maxKnownSeqs: map[int]int64{0: 100, 1: 50},
+		},
+		{
+			name:         "within the lag",
+			bootstrapURL: snapshotURL,
+			maxKnownSeqs: map[int]int64{0: 200, 1: 50},
+		},
+		{
+			name:         "primary behind",
+			bootstrapURL: snapshotURL,
+			maxKnownSeqs: map[int]int64{0: 201, 1: 50},
+			rebootstrap:  true,
+		},
+		{
+			name:         "shard behind",
+			bootstrapURL: snapshotURL,
+			maxKnownSeqs: map[int]int64{0: 100, 1: 151},
+			rebootstrap:  true,
+		},
+		{
+			name:         "download fails",
+			bootstrapURL: "ftp://snapshots/ldb.db",
+			maxKnownSeqs: map[int]int64{0: 1000, 1: 50},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			ldbPath := filepath.Join(t.TempDir(), "ldb.db")
+			newTestLDBFile(t, ldbPath, "family___old")
+			db, err := ldb.OpenLDB(ldbPath, "rw")
+			require.NoError(t, err)
+			_, err = db.Exec("INSERT INTO "+ldb.LDBSeqTableName+" (id, seq) VALUES (?, 100), (?, 50)",
+				ldb.SeqTableIDForLedger(0), ldb.SeqTableIDForLedger(1))
+			require.NoError(t, err)
+			require.NoError(t, db.Close())
+
+			newPath, err := rebootstrapIfBehind(ctx, ReflectorConfig{
+				LDBPath:        ldbPath,
+				BootstrapURL:   test.bootstrapURL,
+				RebootstrapLag: 100,
+			}, ldbPath, ledgers, test.maxKnownSeqs)
+			require.NoError(t, err)
+			require.Equal(t, test.rebootstrap, newPath != ldbPath)
+			require.Equal(t, test.rebootstrap, ldbTableExists(t, newPath, "family___snapshot"))
+			require.Equal(t, !test.rebootstrap, ldbTableExists(t, newPath, "family___old"))
+
+			// readers opened on the configured path see the LDB that's used
+			reader, err := ctlstore.ReaderForPath(ldbPath)
+			require.NoError(t, err)
+			defer reader.Close()
+			if test.rebootstrap {
+				require.Equal(t, []string{"family___snapshot"}, readerTables(t, reader))
+			} else {
+				require.Equal(t, []string{"family___old"}, readerTables(t, reader))
+			}
+		})
+	}
+}
+
+func TestRebootstrapIfBehindMissingLDB(t *testing.T) {
+	ldbPath := filepath.Join(t.TempDir(), "ldb.db")
+	newPath, err := rebootstrapIfBehind(context.Background(), ReflectorConfig{
+		LDBPath:        ldbPath,
+		BootstrapURL:   "ftp://snapshots/ldb.db",
+		RebootstrapLag: 100,
+	}, ldbPath, []UpstreamShard{{Name: "primary"}}, map[int]int64{0: 1000})
+	require.NoError(t, err)
+	require.Equal(t, ldbPath, newPath)
+}
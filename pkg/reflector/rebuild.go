@@ -0,0 +1,159 @@
+package reflector
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/errors-go"
+	"github.com/segmentio/events/v2"
+	"github.com/segmentio/stats/v4"
+
+	"github.com/segmentio/ctlstore/pkg/ldb"
+)
+
+// rebuildSuffix is appended to the directory of an LDB being rebuilt
+const rebuildSuffix = ".rebuild"
+
+// versionedLDBDir is where rebuilt LDBs are written next to the default
+// LDB, which is where readers with LDB versioning look for them
+const versionedLDBDir = "versioned"
+
+// versionedLDBsPath returns the directory of the timestamped directories
+// that an LDB's rebuilds are written to. Only the default LDB uses the
+// directory readers look in, since LDB shards would otherwise share it.
+func versionedLDBsPath(ldbPath string) string {
+	if filepath.Base(ldbPath) == ldb.DefaultLDBFilename {
+		return filepath.Join(filepath.Dir(ldbPath), versionedLDBDir)
+	}
+	return ldbPath + "." + versionedLDBDir
+}
+
+// rebuildLDB replaces the LDB with a new one, bootstrapped from the
+// bootstrap URL if there is one, and otherwise empty so that the ledger is
+// replayed from the start, and returns the new LDB's path. It first waits
+// for a random delay of up to the rebuild jitter, so that a fleet of
+// reflectors told to rebuild at once doesn't overwhelm the snapshot store
+// or the ctldb.
+//
+// The new LDB is written to a new timestamped directory alongside the
+// versioned LDBs, which is only given its name once the LDB is complete, so
+// that readers with LDB versioning switch to it once it's ready. The old LDB
+// is left as it was, for the readers which have it open, and is still used
+// if the rebuild fails. Rebuilt LDBs older than the old LDB are removed.
+//
+// The file at the LDB's path plus ldb.RebuiltPathSuffix names the new LDB.
+// The reflector keeps using it when it restarts, and readers opened on the
+// LDB's path follow it there.
+func rebuildLDB(ctx context.Context, config ReflectorConfig) (string, error) {
+	if config.RebuildJitter > 0 {
+		delay := time.Duration(newJitter().Rand.Int63n(int64(config.RebuildJitter)))
+		events.Log("Rebuild: waiting %{delay}v before rebuilding the LDB", delay)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	start := time.Now()
+
+	versionsPath := versionedLDBsPath(config.LDBPath)
+	version := fmt.Sprintf("%013d", start.UnixMilli())
+	buildDir := filepath.Join(versionsPath, version+rebuildSuffix)
+	if err := os.RemoveAll(buildDir); err != nil {
+		return "", errors.Wrap(err, "remove partial rebuild")
+	}
+	if err := os.MkdirAll(buildDir, 0755); err != nil {
+		return "", errors.Wrap(err, "create rebuild dir")
+	}
+	defer os.RemoveAll(buildDir)
+
+	buildPath := filepath.Join(buildDir, ldb.DefaultLDBFilename)
+	if config.BootstrapURL != "" {
+		events.Log("Rebuild: bootstrapping the LDB from the bootstrap URL")
+		err := bootstrapLDB(ldbBootstrapConfig{
+			url:                 config.BootstrapURL,
+			path:                buildPath,
+			restartOnS3NotFound: config.IsSupervisor,
+			region:              config.BootstrapRegion,
+			bearerToken:         config.BootstrapBearerToken,
+		})
+		if err != nil {
+			return "", errors.Wrap(err, "bootstrap")
+		}
+	}
+	if _, err := os.Stat(buildPath); os.IsNotExist(err) {
+		// without a snapshot, the ledger is replayed into an empty LDB
+		events.Log("Rebuild: starting with a new LDB, which the ledger will be replayed into")
+		if err := createEmptyLDB(ctx, buildPath); err != nil {
+			return "", errors.Wrap(err, "create empty ldb")
+		}
+	}
+
+	previousPath, err := ldb.CurrentPath(config.LDBPath)
+	if err != nil {
+		return "", err
+	}
+	versionDir := filepath.Join(versionsPath, version)
+	if err := os.Rename(buildDir, versionDir); err != nil {
+		return "", errors.Wrap(err, "publish rebuilt ldb")
+	}
+	newPath := filepath.Join(versionDir, ldb.DefaultLDBFilename)
+	tmpPath := config.LDBPath + ldb.RebuiltPathSuffix + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(newPath), 0644); err != nil {
+		return "", errors.Wrap(err, "write rebuilt ldb path")
+	}
+	if err := os.Rename(tmpPath, config.LDBPath+ldb.RebuiltPathSuffix); err != nil {
+		return "", errors.Wrap(err, "rename rebuilt ldb path")
+	}
+
+	pruneVersionedLDBs(versionsPath, previousPath)
+
+	stats.Incr("ldb-rebuilds")
+	events.Log("Rebuild: replaced the LDB with %{path}s in %{duration}v", newPath, time.Since(start))
+	return newPath, nil
+}
+
+// createEmptyLDB creates an initialized LDB with no tables. Closing it
+// checkpoints its WAL, so the LDB is entirely in the file at the path.
+func createEmptyLDB(ctx context.Context, path string) error {
+	db, err := ldb.OpenLDB(path, "rwc")
+	if err != nil {
+		return err
+	}
+	if err := ldb.EnsureLdbInitialized(ctx, db); err != nil {
+		db.Close()
+		return err
+	}
+	return db.Close()
+}
+
+// pruneVersionedLDBs removes the versioned LDBs which are older than the
+// one at keepPath, which readers may still have open. Readers only switch
+// to newer LDBs, so the older ones aren't read again.
+func pruneVersionedLDBs(versionsPath string, keepPath string) {
+	if filepath.Dir(filepath.Dir(keepPath)) != versionsPath {
+		return
+	}
+	keep, err := strconv.ParseInt(filepath.Base(filepath.Dir(keepPath)), 10, 64)
+	if err != nil {
+		return
+	}
+	entries, err := os.ReadDir(versionsPath)
+	if err != nil {
+		events.Log("Rebuild: couldn't list the old LDBs: %{error}+v", err)
+		return
+	}
+	for _, entry := range entries {
+		version, err := strconv.ParseInt(entry.Name(), 10, 64)
+		if err != nil || !entry.IsDir() || version >= keep {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(versionsPath, entry.Name())); err != nil {
+			events.Log("Rebuild: couldn't remove old LDB %{version}s: %{error}+v", entry.Name(), err)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package reflector
+
+import (
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/segmentio/events/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore"
+	"github.com/segmentio/ctlstore/pkg/ldb"
+	"github.com/segmentio/ctlstore/pkg/ledger"
+)
+
+// newTestLDBFile creates an LDB at the path with a table of the name
+func newTestLDBFile(t *testing.T, path string, table string) {
+	db, err := ldb.OpenLDB(path, "rwc")
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, ldb.EnsureLdbInitialized(context.Background(), db))
+	_, err = db.Exec("CREATE TABLE " + table + " (id INTEGER PRIMARY KEY)")
+	require.NoError(t, err)
+}
+
+func ldbTableExists(t *testing.T, path string, table string) bool {
+	db, err := ldb.OpenLDB(path, "ro")
+	require.NoError(t, err)
+	defer db.Close()
+	var n int
+	err = db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&n)
+	require.NoError(t, err)
+	return n > 0
+}
+
+// readerTables returns the names of the tables that the reader can see
+func readerTables(t *testing.T, reader *ctlstore.LDBReader) []string {
+	stats, err := reader.GetTableStats(context.Background())
+	require.NoError(t, err)
+	var names []string
+	for _, stat := range stats {
+		names = append(names, stat.Family+"___"+stat.Table)
+	}
+	return names
+}
+
+func TestRebuildLDB(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+
+	snapshotPath := filepath.Join(tmpDir, "snapshot.db")
+	newTestLDBFile(t, snapshotPath, "family___snapshot")
+	snapshot, err := ioutil.ReadFile(snapshotPath)
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		name         string
+		bootstrapURL string
+		wantTable    string // in the rebuilt LDB
+		err          string
+	}{
+		{
+			name:         "bootstrap",
+			bootstrapURL: "data:" + base64.URLEncoding.EncodeToString(snapshot),
+			wantTable:    "family___snapshot",
+		},
+		{
+			name:      "replay",
+			wantTable: ldb.LDBSeqTableName,
+		},
+		{
+			name:         "failure",
+			bootstrapURL: "ftp://snapshots/ldb.db",
+			wantTable:    "family___old",
+			err:          "unsupported scheme",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			ldbPath := filepath.Join(t.TempDir(), "ldb.db")
+			newTestLDBFile(t, ldbPath, "family___old")
+
+			newPath, err := rebuildLDB(ctx, ReflectorConfig{
+				LDBPath:      ldbPath,
+				BootstrapURL: test.bootstrapURL,
+			})
+			// the old LDB is left alone for the readers which have it open
+			require.True(t, ldbTableExists(t, ldbPath, "family___old"))
+			entries, readErr := os.ReadDir(filepath.Join(filepath.Dir(ldbPath), versionedLDBDir))
+			require.NoError(t, readErr)
+			current, pathErr := ldb.CurrentPath(ldbPath)
+			require.NoError(t, pathErr)
+			if test.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), test.err)
+				require.Empty(t, entries, "the partial rebuild was left behind")
+				require.Equal(t, ldbPath, current)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+			require.Equal(t, filepath.Join(filepath.Dir(ldbPath), versionedLDBDir, entries[0].Name(), ldb.DefaultLDBFilename), newPath)
+			require.Equal(t, newPath, current)
+			require.True(t, ldbTableExists(t, newPath, test.wantTable))
+			require.False(t, ldbTableExists(t, newPath, "family___old"))
+		})
+	}
+}
+
+func TestRebuildLDBAgain(t *testing.T) {
+	ctx := context.Background()
+	ldbPath := filepath.Join(t.TempDir(), "ldb.db")
+	newTestLDBFile(t, ldbPath, "family___old")
+
+	var paths []string
+	for i := 0; i < 3; i++ {
+		path, err := rebuildLDB(ctx, ReflectorConfig{LDBPath: ldbPath})
+		require.NoError(t, err)
+		paths = append(paths, path)
+		time.Sleep(2 * time.Millisecond) // so each rebuild has a new timestamp
+	}
+	current, err := ldb.CurrentPath(ldbPath)
+	require.NoError(t, err)
+	require.Equal(t, paths[2], current)
+
+	// the LDB that was replaced is kept for its readers, and older ones are
+	// removed
+	_, err = os.Stat(paths[0])
+	require.True(t, os.IsNotExist(err))
+	_, err = os.Stat(paths[1])
+	require.NoError(t, err)
+}
+
+func TestReadersFollowRebuiltLDB(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	ldbPath := filepath.Join(tmpDir, "ldb.db")
+	newTestLDBFile(t, ldbPath, "family___old")
+
+	opened, err := ctlstore.ReaderForPath(ldbPath)
+	require.NoError(t, err)
+	defer opened.Close()
+
+	snapshotPath := filepath.Join(tmpDir, "snapshot.db")
+	newTestLDBFile(t, snapshotPath, "family___snapshot")
+	snapshot, err := ioutil.ReadFile(snapshotPath)
+	require.NoError(t, err)
+	_, err = rebuildLDB(ctx, ReflectorConfig{
+		LDBPath:      ldbPath,
+		BootstrapURL: "data:" + base64.URLEncoding.EncodeToString(snapshot),
+	})
+	require.NoError(t, err)
+
+	// a reader opened on the configured path reads the rebuilt LDB
+	reader, err := ctlstore.ReaderForPath(ldbPath)
+	require.NoError(t, err)
+	defer reader.Close()
+	require.Equal(t, []string{"family___snapshot"}, readerTables(t, reader))
+
+	// and one which was already open switches to it
+	require.Eventually(t, func() bool {
+		names := readerTables(t, opened)
+		return len(names) == 1 && names[0] == "family___snapshot"
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestVersionedLDBsPath(t *testing.T) {
+	require.Equal(t, "/var/spool/ctlstore/versioned", versionedLDBsPath("/var/spool/ctlstore/ldb.db"))
+	require.Equal(t, "/var/spool/ctlstore/heavy.db.versioned", versionedLDBsPath("/var/spool/ctlstore/heavy.db"))
+}
+
+func TestRebuildLDBJitter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ldbPath := filepath.Join(t.TempDir(), "ldb.db")
+	newTestLDBFile(t, ldbPath, "family___old")
+
+	_, err := rebuildLDB(ctx, ReflectorConfig{LDBPath: ldbPath, RebuildJitter: time.Hour})
+	require.Equal(t, context.Canceled, err)
+	current, err := ldb.CurrentPath(ldbPath)
+	require.NoError(t, err)
+	require.Equal(t, ldbPath, current)
+}
+
+func TestReflectorRebuild(t *testing.T) {
+	u := newSQLiteUpstreamTestUtil(t, "wal")
+	ldbPath := filepath.Join(t.TempDir(), "ldb.db")
+	newTestLDBFile(t, ldbPath, "family___old")
+
+	newReflector := func(rebuild bool) *Reflector {
+		reflector, err := ReflectorFromConfigContext(context.Background(), ReflectorConfig{
+			LDBPath: ldbPath,
+			Rebuild: rebuild,
+			Upstream: UpstreamConfig{
+				Driver:       SQLiteDriver,
+				DSN:          u.path,
+				LedgerTable:  "ctlstore_dml_ledger",
+				PollInterval: 10 * time.Millisecond,
+				PollTimeout:  time.Second,
+			},
+			LedgerHealth: ledger.HealthConfig{
+				DisableECSBehavior: true,
+				PollInterval:       10 * time.Second,
+			},
+			Logger: events.DefaultLogger,
+		})
+		require.NoError(t, err)
+		return reflector
+	}
+
+	reflector := newReflector(true)
+	rebuiltPath := reflector.LDBPath()
+	require.NotEqual(t, ldbPath, rebuiltPath)
+	require.NoError(t, reflector.Close())
+
+	// restarts keep using the rebuilt LDB
+	reflector = newReflector(false)
+	defer reflector.Close()
+	require.Equal(t, rebuiltPath, reflector.LDBPath())
+	require.True(t, ldbTableExists(t, ldbPath, "family___old"))
+}
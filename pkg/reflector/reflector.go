@@ -8,9 +8,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -23,6 +26,7 @@ import (
 	"github.com/segmentio/ctlstore/pkg/ldbwriter"
 	"github.com/segmentio/ctlstore/pkg/ledger"
 	"github.com/segmentio/ctlstore/pkg/logwriter"
+	"github.com/segmentio/ctlstore/pkg/schema"
 	"github.com/segmentio/ctlstore/pkg/sqlite"
 	"github.com/segmentio/errors-go"
 	"github.com/segmentio/events/v2"
@@ -37,11 +41,22 @@ import (
 type Reflector struct {
 	shovel        func() (*shovel, error)
 	ldb           *sql.DB
+	ldbConn       *sql.Conn // keeps an in-memory LDB from being dropped
 	logger        *events.Logger
-	upstreamdb    *sql.DB
+	upstreamdbs   []*sql.DB
 	ledgerMonitor *ledger.Monitor
 	walMonitor    starter
+	vacuumer      starter
+	verifier      *verifier // nil once the LDB has been verified
+	state         *shovelStateFile
 	stop          chan struct{}
+
+	// for DebugInfo
+	id            string
+	ldbPath       string
+	ledgers       []UpstreamShard
+	changelogPath string
+	changelog     *atomic.Pointer[ldbwriter.ChangelogCallback] // of the current shovel
 }
 
 // UpstreamConfig specifies how to reach and treat the upstream CtlDB.
@@ -53,12 +68,36 @@ type UpstreamConfig struct {
 	PollInterval          time.Duration
 	PollTimeout           time.Duration
 	PollJitterCoefficient float64
+	// QueryBlockBytes limits how many bytes of statements are read from
+	// the ledger at once, in addition to QueryBlockSize. A statement larger
+	// than the limit is still read, on its own.
+	QueryBlockBytes int // optional
+	// Shards are the ledgers of any other ctldbs that the CtlDB has been
+	// sharded into. Their statements are merged into the same LDB. See
+	// ShardingSpec.
+	Shards []UpstreamShard // optional
+	// AdaptivePolling backs off polling while the CtlDB is under load
+	AdaptivePolling AdaptivePollingConfig // optional
+	// BinlogServerID identifies the reflector to the CtlDB as a replica
+	// when the Driver is BinlogDriver. It must be unique among the CtlDB's
+	// replicas, and is random if zero.
+	BinlogServerID uint32 // optional
+	// BusyTimeout is how long ledger queries wait for the writer of a
+	// SQLite CtlDB to release its locks, when the Driver is SQLiteDriver.
+	// Defaults to 5s.
+	BusyTimeout time.Duration // optional
 }
 
+// InMemoryLDBPath can be used as the LDBPath of a ReflectorConfig to
+// materialize the ledger into an in-memory LDB instead of a file, for tests
+// and short-lived jobs without local disk. The LDB is read with the
+// Reflector's Reader, and is discarded when the Reflector is closed.
+const InMemoryLDBPath = ":memory:"
+
 // ReflectorConfig is used to configure a Reflector instance that
 // is instantiated by ReflectorFromConfig
 type ReflectorConfig struct {
-	LDBPath          string
+	LDBPath          string // or InMemoryLDBPath
 	ChangelogPath    string
 	ChangelogSize    int
 	Upstream         UpstreamConfig
@@ -67,6 +106,9 @@ type ReflectorConfig struct {
 	IsSupervisor     bool
 	LDBWriteCallback ldbwriter.LDBWriteCallback // optional
 	BootstrapRegion  string                     // optional
+	// Sent as a bearer token when bootstrapping from an https:// or gs://
+	// URL. gs:// URLs otherwise use the GCE metadata server's token.
+	BootstrapBearerToken string // optional
 	// How often to poll the WAL stats
 	WALPollInterval time.Duration // optional
 	// Performs a checkpoint after the WAL file exceeds this size in bytes
@@ -75,8 +117,58 @@ type ReflectorConfig struct {
 	WALCheckpointType ldbwriter.CheckpointType // optional
 	DoMonitorWAL      bool                     // optional
 	BusyTimeoutMS     int                      // optional
-	ID                string
-	Logger            *events.Logger
+	// Defers checkpoints while readers are busy, and truncates the WAL
+	// during a low-traffic window
+	CheckpointSchedule CheckpointSchedule // optional
+	// SQLite pragmas applied to the LDB. Defaults to ldb.DefaultPragmas.
+	Pragmas *ldb.Pragmas // optional
+	// Schedules compaction of the LDB
+	Vacuum VacuumConfig // optional
+	// Applies statements in batches, committing fewer LDB transactions
+	GroupCommit ldbwriter.GroupCommit // optional
+	// Selects the families reflected into the LDB, when they're sharded
+	// between several LDBs. See LDBShardingSpec.
+	Families ldbwriter.FamilyFilter // optional
+	// Refuses statements from a bad writer, quarantining them in the LDB
+	Guard ldbwriter.StatementGuard // optional
+	// Interrupts statements which take longer than this to apply
+	StatementTimeout time.Duration // optional
+	// Logs statements which take at least this long to apply
+	SlowStatementThreshold time.Duration // optional
+	// Told about each statement applied to the LDB, for programs which
+	// embed the reflector
+	ApplyObserver ldbwriter.ApplyObserver // optional
+	// Selects the tables whose changes are written to the changelog
+	ChangelogFilter ldbwriter.ChangelogFilter // optional
+	// How long to wait for skipped ledger sequences to appear before
+	// aborting. Zero aborts straight away.
+	GapRepairGracePeriod time.Duration // optional
+	// Where to write a report of sequences that never appeared. Defaults to
+	// the directory of the LDB.
+	GapReportDir string // optional
+	// Compares a sample of the LDB with the ctldb before shoveling begins
+	Verify VerifyConfig // optional
+	// Notified when the ledger latency exceeds LedgerHealth.MaxHealthyLatency
+	// and when it recovers
+	LedgerStallHandler ledger.StallHandler // optional
+	// Records a sample of applied statements for debugging
+	TraceSampler *ldbwriter.TraceSampler // optional
+	// How often to save the shovel's state to a file next to the LDB, which
+	// restarts resume from. Zero disables the state file.
+	StateInterval time.Duration // optional
+	// Bootstraps the LDB again from the BootstrapURL on startup if it's
+	// more than this many sequences behind the ledger, rather than
+	// replaying them. Zero always replays.
+	RebootstrapLag int64 // optional
+	// Rebuild replaces the LDB on startup with one rebuilt from the
+	// BootstrapURL, or by replaying the ledger from the start if there isn't
+	// one, in a new versioned LDB directory next to the LDB.
+	Rebuild bool // optional
+	// The longest random delay before rebuilding, which spreads out the
+	// rebuilds of reflectors which are told to rebuild at the same time
+	RebuildJitter time.Duration // optional
+	ID            string
+	Logger        *events.Logger
 }
 
 type DownloadMetric struct {
@@ -95,9 +187,19 @@ func (c ReflectorConfig) Printable() string {
 		c.BootstrapURL = c.BootstrapURL[:200] + "...<truncated>"
 	}
 	c.Upstream.DSN = "<REDACTED>"
+	shards := make([]UpstreamShard, len(c.Upstream.Shards))
+	for i, shard := range c.Upstream.Shards {
+		shard.DSN = "<REDACTED>"
+		shards[i] = shard
+	}
+	c.Upstream.Shards = shards
 	return fmt.Sprintf("%+v", c)
 }
 
+// readers of an in-memory LDB wait this long by default for the reflector to
+// commit its writes
+const defaultInMemoryBusyTimeoutMS = 5000
+
 // driverNameSequence will be incremented atomically to ensure unique driver names.
 // the database/sql package will panic when registering a driver with the same name
 // more than once.
@@ -106,8 +208,64 @@ var driverNameSequence int64
 // ReflectorFromConfig instantiates a Reflector instance using the
 // configuration specified by a ReflectorConfig instance
 func ReflectorFromConfig(config ReflectorConfig) (*Reflector, error) {
+	return ReflectorFromConfigContext(context.Background(), config)
+}
+
+// ReflectorFromConfigContext is like ReflectorFromConfig, except that
+// rebuilding the LDB stops when the context is done, including the wait
+// for the rebuild jitter.
+func ReflectorFromConfigContext(ctx context.Context, config ReflectorConfig) (*Reflector, error) {
 	events.Log("Config: %{config}s", config.Printable())
 
+	inMemory := config.LDBPath == InMemoryLDBPath
+	if inMemory {
+		// bootstrapping downloads a snapshot file, and the changelog callback
+		// reads the LDB outside of the writer's transactions, which an
+		// in-memory LDB can't serve until those transactions commit
+		if config.BootstrapURL != "" {
+			return nil, errors.New("an in-memory LDB can't be bootstrapped")
+		}
+		if config.ChangelogPath != "" {
+			return nil, errors.New("an in-memory LDB can't write a changelog")
+		}
+		if config.Rebuild {
+			return nil, errors.New("an in-memory LDB can't be rebuilt")
+		}
+	}
+
+	if err := config.ChangelogFilter.Validate(); err != nil {
+		return nil, err
+	}
+	if err := config.Guard.Validate(); err != nil {
+		return nil, err
+	}
+	if config.RebootstrapLag > 0 && config.BootstrapURL == "" {
+		return nil, errors.New("an LDB can only be bootstrapped again with a bootstrap URL")
+	}
+
+	// the LDB is replaced by a rebuilt one at another path, which the
+	// configured path then leads to
+	configured := config
+	switch {
+	case config.Rebuild:
+		path, err := rebuildLDB(ctx, configured)
+		if err != nil {
+			return nil, errors.Wrap(err, "rebuild ldb")
+		}
+		config.LDBPath = path
+	case !inMemory:
+		path, err := ldb.CurrentPath(configured.LDBPath)
+		if err != nil {
+			return nil, err
+		}
+		if path != configured.LDBPath {
+			events.Log("Using %{path}s, the LDB that %{configured}s was rebuilt as", path, configured.LDBPath)
+		}
+		config.LDBPath = path
+	}
+
+	// whether the LDB was just downloaded, so it won't be bootstrapped again
+	bootstrapped := config.Rebuild
 	if config.BootstrapURL != "" {
 		if _, err := os.Stat(config.LDBPath); err != nil {
 			switch {
@@ -118,10 +276,12 @@ func ReflectorFromConfig(config ReflectorConfig) (*Reflector, error) {
 					path:                config.LDBPath,
 					restartOnS3NotFound: config.IsSupervisor, // allow supervisor to restart ldb
 					region:              config.BootstrapRegion,
+					bearerToken:         config.BootstrapBearerToken,
 				})
 				if err != nil {
 					return nil, err
 				}
+				bootstrapped = true
 			default:
 				return nil, err
 			}
@@ -130,6 +290,56 @@ func ReflectorFromConfig(config ReflectorConfig) (*Reflector, error) {
 		}
 	}
 
+	// the ledgers are read first so that the LDB can be bootstrapped again
+	// if it's too far behind them
+	ledgers, err := config.Upstream.ledgers()
+	if err != nil {
+		return nil, err
+	}
+	upstreamdbs := make([]*sql.DB, 0, len(ledgers))
+	maxKnownSeqs := make(map[int]int64, len(ledgers))
+	for _, upstream := range ledgers {
+		dsn := upstream.DSN
+		driver := config.Upstream.Driver
+		if driver == BinlogDriver {
+			// the ledger is read from the binlog once it's caught up
+			driver = "mysql"
+		}
+		switch driver {
+		case "mysql":
+			dsn, err = ctldb.SetCtldbDSNParameters(dsn)
+		case SQLiteDriver:
+			dsn, err = setSQLiteUpstreamDSNParameters(dsn, config.Upstream.BusyTimeout)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		upstreamdb, err := sql.Open(driver, dsn)
+		if err != nil {
+			return nil, fmt.Errorf("Error when opening upstream DB (%v): %v", config.Upstream.Driver, err)
+		}
+		upstreamdbs = append(upstreamdbs, upstreamdb)
+
+		row := upstreamdb.QueryRow("select max(seq) from " + upstream.LedgerTable)
+		var maxKnownSeq sql.NullInt64
+		err = row.Scan(&maxKnownSeq)
+		if err != nil {
+			return nil, errors.Wrapf(err, "find max seq from ledger %s", upstream.Name)
+		}
+		maxKnownSeqs[upstream.LedgerID] = maxKnownSeq.Int64
+
+		events.Log("Max known ledger sequence: %{seq}d (ledger %{ledger}s)", maxKnownSeq, upstream.Name)
+	}
+
+	if config.RebootstrapLag > 0 && !bootstrapped {
+		path, err := rebootstrapIfBehind(ctx, configured, config.LDBPath, ledgers, maxKnownSeqs)
+		if err != nil {
+			return nil, errors.Wrap(err, "rebootstrap ldb")
+		}
+		config.LDBPath = path
+	}
+
 	// Allows registering multiple watches (only for testing)
 	driverName := ldb.LDBDatabaseDriver
 
@@ -139,7 +349,7 @@ func ReflectorFromConfig(config ReflectorConfig) (*Reflector, error) {
 
 	// use a unique driver name to prevent database/sql panics.
 	driverName = fmt.Sprintf("%s_%d", ldb.LDBDatabaseDriver, atomic.AddInt64(&driverNameSequence, 1))
-	err := sqlite.RegisterSQLiteWatch(driverName, &changeBuffer)
+	err = sqlite.RegisterSQLiteWatch(driverName, &changeBuffer)
 	if err != nil {
 		return nil, err
 	}
@@ -153,40 +363,43 @@ func ReflectorFromConfig(config ReflectorConfig) (*Reflector, error) {
 	// themselves are appended to the log instead of the database file. After
 	// the log grows large enough, its contents are "checkpointed" into the
 	// database file in batch.
+	pragmas := ldb.DefaultPragmas
+	if config.Pragmas != nil {
+		pragmas = *config.Pragmas
+	}
 	var ldbDB *sql.DB
 	var openErr error
-	if config.BusyTimeoutMS > 0 {
-		ldbDB, openErr = sql.Open(driverName, config.LDBPath+fmt.Sprintf("?_journal_mode=wal&_busy_timeout=%d", config.BusyTimeoutMS))
+	if inMemory {
+		// the memdb VFS shares the LDB between all of the pool's connections,
+		// unlike a plain :memory: database which is private to a connection.
+		// It doesn't support WAL, so readers wait for writes to commit.
+		busyTimeout := config.BusyTimeoutMS
+		if busyTimeout == 0 {
+			busyTimeout = defaultInMemoryBusyTimeoutMS
+		}
+		ldbDB, openErr = ldb.OpenWithPragmas(driverName, fmt.Sprintf("file:/%s?vfs=memdb&_busy_timeout=%d", driverName, busyTimeout), pragmas)
+	} else if config.BusyTimeoutMS > 0 {
+		ldbDB, openErr = ldb.OpenWithPragmas(driverName, config.LDBPath+fmt.Sprintf("?_busy_timeout=%d", config.BusyTimeoutMS), pragmas)
 	} else {
-		ldbDB, openErr = sql.Open(driverName, config.LDBPath+"?_journal_mode=wal")
+		ldbDB, openErr = ldb.OpenWithPragmas(driverName, config.LDBPath, pragmas)
 	}
 
 	if openErr != nil {
 		return nil, fmt.Errorf("Error when opening LDB at '%v': %v", config.LDBPath, openErr)
 	}
-
-	dsn := config.Upstream.DSN
-	if config.Upstream.Driver == "mysql" {
-		dsn, err = ctldb.SetCtldbDSNParameters(dsn)
+	var ldbConn *sql.Conn
+	if inMemory {
+		// the memdb VFS frees the LDB once its last connection closes, so one
+		// is held open until the reflector is closed
+		ldbConn, err = ldbDB.Conn(context.Background())
 		if err != nil {
-			return nil, err
+			return nil, errors.Wrap(err, "connect to in-memory ldb")
 		}
 	}
-
-	upstreamdb, err := sql.Open(config.Upstream.Driver, dsn)
-	if err != nil {
-		return nil, fmt.Errorf("Error when opening upstream DB (%v): %v", config.Upstream.Driver, err)
-	}
-
-	row := upstreamdb.QueryRow("select max(seq) from " + config.Upstream.LedgerTable)
-	var maxKnownSeq sql.NullInt64
-	err = row.Scan(&maxKnownSeq)
-	if err != nil {
-		return nil, errors.Wrap(err, "find max seq from ledger")
+	if err := dropFilteredTables(context.TODO(), ldbDB, config.Families); err != nil {
+		return nil, err
 	}
 
-	events.Log("Max known ledger sequence: %{seq}d", maxKnownSeq)
-
 	path := "/var/spool/ctlstore/metrics.json"
 	err = emitMetricFromFile(path)
 	if err != nil {
@@ -197,6 +410,23 @@ func ReflectorFromConfig(config ReflectorConfig) (*Reflector, error) {
 	// TODO: check Upstream fields
 	stop := make(chan struct{})
 
+	// held for writing while the LDB is vacuumed
+	ldbLock := &sync.RWMutex{}
+
+	gapReportDir := config.GapReportDir
+	if gapReportDir == "" {
+		gapReportDir = filepath.Dir(config.LDBPath)
+	}
+
+	var changelogCallback atomic.Pointer[ldbwriter.ChangelogCallback]
+	// the writer of the current shovel
+	var ldbWriter atomic.Pointer[ldbwriter.CallbackWriter]
+
+	var state *shovelStateFile
+	if config.StateInterval > 0 && !inMemory {
+		state = loadShovelState(config.LDBPath+".state.json", config.StateInterval)
+	}
+
 	// This is a function so that initialization can be redone each
 	// time the shovel operation does a crash-and-restart loop. A good
 	// example of where this is useful is when the ldbWriter crashes
@@ -207,8 +437,15 @@ func ReflectorFromConfig(config ReflectorConfig) (*Reflector, error) {
 	// the and fetching the last known good sequence in the LDB.
 	shovel := func() (*shovel, error) {
 		sqlDBWriter := &ldbwriter.SqlLdbWriter{Db: ldbDB,
-			ID:     config.ID,
-			Logger: config.Logger,
+			ID:          config.ID,
+			Logger:      config.Logger,
+			GroupCommit: config.GroupCommit,
+			Families:    config.Families,
+			Guard:       config.Guard,
+
+			StatementTimeout:       config.StatementTimeout,
+			SlowStatementThreshold: config.SlowStatementThreshold,
+			Observer:               config.ApplyObserver,
 		}
 		var writer ldbwriter.LDBWriter = sqlDBWriter
 
@@ -223,84 +460,202 @@ func ReflectorFromConfig(config ReflectorConfig) (*Reflector, error) {
 			}
 
 			clw := &changelog.ChangelogWriter{WriteLine: slw}
-			ldbWriteCallbacks = append(ldbWriteCallbacks, &ldbwriter.ChangelogCallback{
+			clc := &ldbwriter.ChangelogCallback{
 				ChangelogWriter: clw,
-			})
+				Filter:          config.ChangelogFilter,
+			}
+			if state != nil {
+				// continue the changelog's seqs rather than starting over
+				clc.Seq = state.trackChangelog(func() int64 { return atomic.LoadInt64(&clc.Seq) })
+			}
+			changelogCallback.Store(clc)
+			ldbWriteCallbacks = append(ldbWriteCallbacks, clc)
 			events.Log("Writing changelog to %{path}s", config.ChangelogPath)
+			if config.ChangelogFilter.Enabled() {
+				events.Log("Writing changes to tables matching %{include}v, except %{exclude}v, to the changelog",
+					config.ChangelogFilter.Include, config.ChangelogFilter.Exclude)
+			}
 		}
 
 		if config.LDBWriteCallback != nil {
 			ldbWriteCallbacks = append(ldbWriteCallbacks, config.LDBWriteCallback)
 		}
-		writer = &ldbwriter.CallbackWriter{
+		callbackWriter := &ldbwriter.CallbackWriter{
 			DB:           ldbDB,
 			Delegate:     writer,
 			Callbacks:    ldbWriteCallbacks,
 			ChangeBuffer: &changeBuffer,
 		}
+		ldbWriter.Store(callbackWriter)
+		writer = callbackWriter
+		if config.TraceSampler != nil {
+			writer = &ldbwriter.SamplingWriter{
+				Delegate: writer,
+				Sampler:  config.TraceSampler,
+			}
+		}
 
 		err = ldb.EnsureLdbInitialized(context.TODO(), ldbDB)
 		if err != nil {
 			return nil, fmt.Errorf("Error when initializing LDB: %v", err)
 		}
 
-		lastSeq, err := ldb.FetchSeqFromLdb(context.TODO(), ldbDB)
-		events.Log("Latest seq from %s: %d", config.ID, lastSeq.Int())
-		if err != nil {
-			return nil, fmt.Errorf("Error when fetching last sequence from LDB: %v", err)
-		}
+		sources := make([]dmlSource, 0, len(ledgers))
+		closers := []io.Closer{callbackWriter}
+		resumeSeqs := map[int]schema.DMLSequence{}
+		for i, upstream := range ledgers {
+			lastSeq, err := ldb.FetchLedgerSeqFromLdb(context.TODO(), ldbDB, upstream.LedgerID)
+			events.Log("Latest seq from %s: %d (ledger %s)", config.ID, lastSeq.Int(), upstream.Name)
+			if err != nil {
+				return nil, fmt.Errorf("Error when fetching last sequence from LDB: %v", err)
+			}
+			if state != nil {
+				if seq, ok := state.resumeSequence(upstream.LedgerID, lastSeq); ok {
+					events.Log("Resuming from seq %d of the saved shovel state (ledger %s)", seq.Int(), upstream.Name)
+					resumeSeqs[upstream.LedgerID] = seq
+				}
+			}
 
-		src := &sqlDmlSource{
-			db:              upstreamdb,
-			lastSequence:    lastSeq,
-			ledgerTableName: config.Upstream.LedgerTable,
-			queryBlockSize:  config.Upstream.QueryBlockSize,
+			sqlSource := &sqlDmlSource{
+				db:              upstreamdbs[i],
+				lastSequence:    lastSeq,
+				ledgerTableName: upstream.LedgerTable,
+				ledgerID:        upstream.LedgerID,
+				queryBlockSize:  upstream.QueryBlockSize,
+				queryBlockBytes: upstream.QueryBlockBytes,
+				poller:          newAdaptivePoller(config.Upstream.PollInterval, config.Upstream.AdaptivePolling),
+			}
+			if config.Upstream.Driver == SQLiteDriver {
+				sqlSource.file, err = newSQLiteLedgerFile(upstreamdbs[i], upstream.DSN)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if config.Upstream.Driver != BinlogDriver {
+				sources = append(sources, sqlSource)
+				continue
+			}
+			binlogSource, err := newBinlogDmlSource(upstreamdbs[i], upstream.DSN, upstream.LedgerTable,
+				upstream.LedgerID, config.Upstream.BinlogServerID, lastSeq, sqlSource)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, binlogSource)
+			closers = append(closers, binlogSource)
+		}
+		src := sources[0]
+		if len(sources) > 1 {
+			src = &mergedDmlSource{sources: sources}
 		}
 
 		return &shovel{
 			writer:            writer,
-			closers:           []io.Closer{sqlDBWriter},
+			closers:           closers,
 			source:            src,
 			pollInterval:      config.Upstream.PollInterval,
 			pollTimeout:       config.Upstream.PollTimeout,
 			jitterCoefficient: config.Upstream.PollJitterCoefficient,
 			abortOnSeqSkip:    true,
-			maxSeqOnStartup:   maxKnownSeq.Int64,
+			maxSeqOnStartup:   maxKnownSeqs,
 			stop:              stop,
 			log:               config.Logger,
+			pause:             ldbLock.RLocker(),
+			gapGracePeriod:    config.GapRepairGracePeriod,
+			gapReportDir:      gapReportDir,
+			flush:             callbackWriter.Flush,
+			state:             state,
+			resumeSeqs:        resumeSeqs,
 		}, nil
 	}
 
 	ledgerLatencyFunc := ctlstore.NewLDBReaderFromDB(ldbDB).GetLedgerLatency
-	ledgerMon, err := ledger.NewLedgerMonitor(config.LedgerHealth, ledgerLatencyFunc)
+	var ledgerMonOpts []ledger.MonitorOpt
+	if config.LedgerStallHandler != nil {
+		ledgerMonOpts = append(ledgerMonOpts, ledger.WithStallHandler(config.LedgerStallHandler))
+	}
+	ledgerMon, err := ledger.NewLedgerMonitor(config.LedgerHealth, ledgerLatencyFunc, ledgerMonOpts...)
 	if err != nil {
 		return nil, errors.Wrap(err, "build ledger latency monitor")
 	}
 
 	var walMon starter
 
-	if config.DoMonitorWAL && config.WALPollInterval > 0 {
+	if config.DoMonitorWAL && config.WALPollInterval > 0 && !inMemory {
 		w := &ldbwriter.SqlLdbWriter{Db: ldbDB}
-		cper := func() (*ldbwriter.PragmaWALResult, error) {
-			return w.Checkpoint(config.WALCheckpointType)
+		cper := func(cpType ldbwriter.CheckpointType) (*ldbwriter.PragmaWALResult, error) {
+			ldbLock.RLock()
+			defer ldbLock.RUnlock()
+			return w.Checkpoint(cpType)
 		}
 		walMon = NewMonitor(MonitorConfig{
 			PollInterval:               config.WALPollInterval,
 			Path:                       config.LDBPath + "-wal",
 			WALCheckpointThresholdSize: int64(config.WALCheckpointThresholdSize),
+			CheckpointType:             config.WALCheckpointType,
+			Schedule:                   config.CheckpointSchedule,
 		}, cper)
 	} else {
 		walMon = &noopStarter{}
 	}
 
+	var vac starter = &noopStarter{}
+	if config.Vacuum.Interval > 0 {
+		vac = &vacuumer{
+			config:  config.Vacuum,
+			db:      ldbDB,
+			ldbLock: ldbLock,
+			now:     time.Now,
+			// only called while ldbLock is held for writing, so the shovel
+			// isn't applying a statement
+			flush: func() error {
+				if w := ldbWriter.Load(); w != nil {
+					return w.Flush()
+				}
+				return nil
+			},
+			inTransaction: func() bool {
+				w := ldbWriter.Load()
+				return w != nil && w.InTransaction()
+			},
+		}
+	}
+
+	var verify *verifier
+	if config.Verify.SampleSize > 0 {
+		verify = &verifier{
+			config: config.Verify,
+			ldb:    ldbDB,
+			logger: config.Logger,
+		}
+		if verify.config.ReportDir == "" {
+			verify.config.ReportDir = filepath.Dir(config.LDBPath)
+		}
+		for i, upstream := range ledgers {
+			verify.upstreams = append(verify.upstreams, verifyUpstream{
+				db:          upstreamdbs[i],
+				ledgerID:    upstream.LedgerID,
+				ledgerTable: upstream.LedgerTable,
+			})
+		}
+	}
+
 	return &Reflector{
 		shovel:        shovel,
+		verifier:      verify,
+		state:         state,
 		ldb:           ldbDB,
+		ldbConn:       ldbConn,
 		logger:        config.Logger,
-		upstreamdb:    upstreamdb,
+		upstreamdbs:   upstreamdbs,
 		ledgerMonitor: ledgerMon,
 		stop:          stop,
 		walMonitor:    walMon,
+		vacuumer:      vac,
+		id:            config.ID,
+		ldbPath:       config.LDBPath,
+		ledgers:       ledgers,
+		changelogPath: config.ChangelogPath,
+		changelog:     &changelogCallback,
 	}, nil
 }
 
@@ -346,8 +701,17 @@ func emitMetricFromFile(path string) error {
 func (r *Reflector) Start(ctx context.Context) error {
 
 	r.logger.Log("Starting Reflector.")
+	if r.verifier != nil {
+		// only verify once, not whenever a supervisor restarts the reflector
+		err := r.verifier.verify(ctx)
+		r.verifier = nil
+		if err != nil {
+			return errors.Wrap(err, "verify ldb")
+		}
+	}
 	go r.ledgerMonitor.Start(ctx)
 	go r.walMonitor.Start(ctx)
+	go r.vacuumer.Start(ctx)
 	for {
 		err := func() error {
 			shovel, err := r.shovel()
@@ -386,23 +750,54 @@ func (r *Reflector) Start(ctx context.Context) error {
 	}
 }
 
+// StateHandler serves the shovel's state as JSON, for debugging. It returns
+// nil if the reflector has no state file.
+func (r *Reflector) StateHandler() http.Handler {
+	if r.state == nil {
+		return nil
+	}
+	return r.state
+}
+
 func (r *Reflector) Stop() {
 	close(r.stop)
 }
 
+// LDBPath returns the path of the reflector's LDB, which is the path it was
+// configured with unless the LDB has been rebuilt.
+func (r *Reflector) LDBPath() string {
+	return r.ldbPath
+}
+
+// Reader returns an LDBReader of the reflector's LDB, sharing its
+// connections. This is the only way to read an in-memory LDB. The reader
+// must not be closed; it is closed along with the reflector.
+func (r *Reflector) Reader() *ctlstore.LDBReader {
+	return ctlstore.NewLDBReaderFromDB(r.ldb)
+}
+
 func (r *Reflector) Close() error {
 	var err error
 
 	r.logger.Log("Close() reflector")
 
+	if r.ldbConn != nil {
+		err = r.ldbConn.Close()
+		if err != nil {
+			return err
+		}
+	}
+
 	err = r.ldb.Close()
 	if err != nil {
 		return err
 	}
 
-	err = r.upstreamdb.Close()
-	if err != nil {
-		return err
+	for _, upstreamdb := range r.upstreamdbs {
+		err = upstreamdb.Close()
+		if err != nil {
+			return err
+		}
 	}
 
 	// CR: use errors.Join here
@@ -414,6 +809,7 @@ type ldbBootstrapConfig struct {
 	url                 string
 	path                string
 	region              string        // optional
+	bearerToken         string        // optional
 	downloadTo          downloadTo    // for testing
 	retryDelay          time.Duration // for testing
 	restartOnS3NotFound bool          // whether or not to recreate the ldb if no snapshot exists
@@ -448,6 +844,20 @@ func bootstrapLDB(cfg ldbBootstrapConfig) error {
 			Key:                 key,
 			StartOverOnNotFound: cfg.restartOnS3NotFound,
 		}
+	case scheme == "http" || scheme == "https":
+		// an object store, or a peer reflector's PeerSnapshotHandler
+		dler = &HTTPDownloader{
+			URL:                 cfg.url,
+			BearerToken:         cfg.bearerToken,
+			StartOverOnNotFound: cfg.restartOnS3NotFound,
+		}
+	case scheme == "gs":
+		dler = &GCSDownloader{
+			Bucket:              parsed.Host,
+			Object:              strings.TrimPrefix(parsed.Path, "/"),
+			BearerToken:         cfg.bearerToken,
+			StartOverOnNotFound: cfg.restartOnS3NotFound,
+		}
 	case scheme == "data":
 		decoded, err := base64.URLEncoding.DecodeString(parsed.Opaque)
 		if err != nil {
@@ -4,8 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"encoding/base64"
+	"encoding/json"
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"sync/atomic"
@@ -13,10 +16,12 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/segmentio/ctlstore"
 	"github.com/segmentio/ctlstore/pkg/ctldb"
 	"github.com/segmentio/ctlstore/pkg/ldb"
 	"github.com/segmentio/ctlstore/pkg/ldbwriter"
 	"github.com/segmentio/ctlstore/pkg/ledger"
+	"github.com/segmentio/ctlstore/pkg/schema"
 	"github.com/segmentio/errors-go"
 	"github.com/segmentio/events/v2"
 	"github.com/stretchr/testify/require"
@@ -208,11 +213,25 @@ func TestReflector(t *testing.T) {
 	clBytes, err := ioutil.ReadFile(changelogPath)
 	require.NoError(t, err)
 
-	expectChangelog := "{\"seq\":1,\"family\":\"family1\",\"table\":\"table1234\",\"key\":[{\"name\":\"field1\",\"type\":\"INTEGER\",\"value\":1234}]}\n"
+	expectChangelog := "{\"seq\":1,\"family\":\"family1\",\"table\":\"table1234\",\"key\":[{\"name\":\"field1\",\"type\":\"INTEGER\",\"value\":1234}],\"ledgerSeq\":2,\"txEnd\":true}\n"
 	if diff := cmp.Diff(expectChangelog, string(clBytes)); diff != "" {
 		t.Errorf("Changelog contents differ\n%s", diff)
 	}
 
+	info, err := reflector.DebugInfo(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{"primary": 2}, info.LastSequences)
+	require.Equal(t, &ChangelogDebugInfo{Path: changelogPath, Seq: 1, Size: int64(len(clBytes))}, info.Changelog)
+	require.NotZero(t, info.LDBSize)
+	require.Nil(t, info.ShovelState)
+
+	rec := httptest.NewRecorder()
+	reflector.DebugHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/ctlstore/ldb", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var served DebugInfo
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&served))
+	require.Equal(t, info.LastSequences, served.LastSequences)
+
 	select {
 	case <-time.After(100 * time.Millisecond):
 		isTerm := atomic.LoadInt64(&isTerminated)
@@ -225,6 +244,80 @@ func TestReflector(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestReflectorInMemory(t *testing.T) {
+	tmpPath, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpPath)
+
+	upstreamDbPath := filepath.Join(tmpPath, "upstream.db")
+	upstreamDb, err := sql.Open("sqlite3", upstreamDbPath)
+	require.NoError(t, err)
+	defer upstreamDb.Close()
+	_, err = upstreamDb.Exec(`
+		CREATE TABLE ctlstore_dml_ledger (
+			seq INTEGER PRIMARY KEY AUTOINCREMENT,
+			leader_ts INTEGER NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			statement VARCHAR(786432)
+		);
+	`)
+	require.NoError(t, err)
+	for _, stmt := range []string{
+		schema.DMLTxBeginKey,
+		`CREATE TABLE family1___table1234 (field1 INTEGER PRIMARY KEY, field2 VARCHAR);`,
+		`INSERT INTO family1___table1234 VALUES(1234, 'hello');`,
+		schema.DMLTxEndKey,
+	} {
+		_, err := upstreamDb.Exec("INSERT INTO ctlstore_dml_ledger (statement) VALUES(?)", stmt)
+		require.NoError(t, err)
+	}
+
+	cfg := ReflectorConfig{
+		LDBPath: InMemoryLDBPath,
+		Upstream: UpstreamConfig{
+			Driver:       "sqlite3",
+			DSN:          upstreamDbPath,
+			LedgerTable:  "ctlstore_dml_ledger",
+			PollInterval: 10 * time.Millisecond,
+			PollTimeout:  10 * time.Millisecond,
+		},
+		LedgerHealth: ledger.HealthConfig{
+			DisableECSBehavior: true,
+			PollInterval:       10 * time.Second,
+		},
+		Logger: events.DefaultLogger,
+	}
+	reflector, err := ReflectorFromConfig(cfg)
+	require.NoError(t, err)
+	defer reflector.Close()
+
+	// a second in-memory reflector has an LDB of its own
+	other, err := ReflectorFromConfig(cfg)
+	require.NoError(t, err)
+	defer other.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reflector.Start(ctx)
+
+	reader := reflector.Reader()
+	var row struct {
+		Field1 int64  `ctlstore:"field1"`
+		Field2 string `ctlstore:"field2"`
+	}
+	require.Eventually(t, func() bool {
+		found, err := reader.GetRowByKey(ctx, &row, "family1", "table1234", 1234)
+		return err == nil && found
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, "hello", row.Field2)
+
+	_, err = other.Reader().GetRowByKey(ctx, &row, "family1", "table1234", 1234)
+	require.Equal(t, ctlstore.ErrTableNotFound, errors.Cause(err))
+
+	cfg.ChangelogPath = filepath.Join(tmpPath, "changelog")
+	_, err = ReflectorFromConfig(cfg)
+	require.EqualError(t, err, "an in-memory LDB can't write a changelog")
+}
+
 func TestEmitMetricFromFile(t *testing.T) {
 	for _, tt := range []struct {
 		name     string
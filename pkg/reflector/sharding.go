@@ -0,0 +1,104 @@
+package reflector
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/segmentio/errors-go"
+)
+
+// ShardingSpec describes the ledgers of a ctldb which has been sharded, so
+// that a single reflector can materialize all of them into one LDB. It is
+// usually loaded from a JSON file such as:
+//
+//	{
+//	  "shards": [
+//	    {"name": "families-a-m", "ledgerID": 1, "dsn": "..."},
+//	    {"name": "families-n-z", "ledgerID": 2, "dsn": "...", "queryBlockSize": 500}
+//	  ]
+//	}
+//
+// The upstream configured by UpstreamConfig.DSN is always ledger 0.
+type ShardingSpec struct {
+	Shards []UpstreamShard `json:"shards"`
+}
+
+// UpstreamShard specifies how to reach one additional upstream ledger.
+// Unset settings are inherited from the UpstreamConfig.
+type UpstreamShard struct {
+	// Name is used in logs, and defaults to "ledger-<LedgerID>".
+	Name string `json:"name"`
+	// LedgerID identifies the shard's sequence space. The LDB tracks the
+	// last applied sequence of each ledger ID separately, so a shard's
+	// LedgerID must never change or be reused.
+	LedgerID        int    `json:"ledgerID"`
+	DSN             string `json:"dsn"`
+	LedgerTable     string `json:"ledgerTable"`
+	QueryBlockSize  int    `json:"queryBlockSize"`
+	QueryBlockBytes int    `json:"queryBlockBytes"`
+}
+
+// LoadShardingSpec reads a JSON encoded ShardingSpec from the file at path.
+func LoadShardingSpec(path string) (ShardingSpec, error) {
+	var spec ShardingSpec
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return spec, errors.Wrap(err, "read sharding spec")
+	}
+	if err := json.Unmarshal(b, &spec); err != nil {
+		return spec, errors.Wrap(err, "decode sharding spec")
+	}
+	return spec, spec.Validate()
+}
+
+// Validate checks that every shard has a DSN and a distinct, positive
+// LedgerID.
+func (spec ShardingSpec) Validate() error {
+	seen := map[int]bool{}
+	for i, shard := range spec.Shards {
+		if shard.LedgerID <= 0 {
+			return errors.Errorf("shard %d must have a positive ledgerID", i)
+		}
+		if seen[shard.LedgerID] {
+			return errors.Errorf("shard %d reuses ledgerID %d", i, shard.LedgerID)
+		}
+		seen[shard.LedgerID] = true
+		if shard.DSN == "" {
+			return errors.Errorf("shard %d has no dsn", i)
+		}
+	}
+	return nil
+}
+
+// ledgers returns the primary ledger followed by any shards, with unset
+// shard settings filled in from the config.
+func (c UpstreamConfig) ledgers() ([]UpstreamShard, error) {
+	spec := ShardingSpec{Shards: c.Shards}
+	if err := spec.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid upstream shards")
+	}
+	res := []UpstreamShard{{
+		Name:            "primary",
+		DSN:             c.DSN,
+		LedgerTable:     c.LedgerTable,
+		QueryBlockSize:  c.QueryBlockSize,
+		QueryBlockBytes: c.QueryBlockBytes,
+	}}
+	for _, shard := range c.Shards {
+		if shard.Name == "" {
+			shard.Name = fmt.Sprintf("ledger-%d", shard.LedgerID)
+		}
+		if shard.LedgerTable == "" {
+			shard.LedgerTable = c.LedgerTable
+		}
+		if shard.QueryBlockSize == 0 {
+			shard.QueryBlockSize = c.QueryBlockSize
+		}
+		if shard.QueryBlockBytes == 0 {
+			shard.QueryBlockBytes = c.QueryBlockBytes
+		}
+		res = append(res, shard)
+	}
+	return res, nil
+}
@@ -0,0 +1,117 @@
+package reflector
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/segmentio/events/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/ldb"
+	"github.com/segmentio/ctlstore/pkg/ledger"
+)
+
+func TestLoadShardingSpec(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sharding.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"shards": [
+			{"name": "second", "ledgerID": 2, "dsn": "dsn-2", "queryBlockSize": 50},
+			{"ledgerID": 1, "dsn": "dsn-1", "ledgerTable": "other_ledger"}
+		]
+	}`), 0644))
+
+	spec, err := LoadShardingSpec(path)
+	require.NoError(t, err)
+
+	ledgers, err := UpstreamConfig{
+		DSN:            "dsn-0",
+		LedgerTable:    "ctlstore_dml_ledger",
+		QueryBlockSize: 10,
+		Shards:         spec.Shards,
+	}.ledgers()
+	require.NoError(t, err)
+	require.Equal(t, []UpstreamShard{
+		{Name: "primary", LedgerID: 0, DSN: "dsn-0", LedgerTable: "ctlstore_dml_ledger", QueryBlockSize: 10},
+		{Name: "second", LedgerID: 2, DSN: "dsn-2", LedgerTable: "ctlstore_dml_ledger", QueryBlockSize: 50},
+		{Name: "ledger-1", LedgerID: 1, DSN: "dsn-1", LedgerTable: "other_ledger", QueryBlockSize: 10},
+	}, ledgers)
+
+	for _, invalid := range []ShardingSpec{
+		{Shards: []UpstreamShard{{DSN: "dsn"}}},
+		{Shards: []UpstreamShard{{LedgerID: 1}}},
+		{Shards: []UpstreamShard{{LedgerID: 1, DSN: "a"}, {LedgerID: 1, DSN: "b"}}},
+	} {
+		require.Error(t, invalid.Validate())
+	}
+}
+
+func TestReflectorShardedUpstreams(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	dir := t.TempDir()
+
+	newUpstream := func(name string, stmts ...string) string {
+		path := filepath.Join(dir, name)
+		db, err := sql.Open("sqlite3", path)
+		require.NoError(t, err)
+		defer db.Close()
+		_, err = db.Exec(`CREATE TABLE ctlstore_dml_ledger (
+			seq INTEGER PRIMARY KEY AUTOINCREMENT,
+			leader_ts INTEGER NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			statement VARCHAR(786432)
+		)`)
+		require.NoError(t, err)
+		for _, stmt := range stmts {
+			_, err := db.Exec("INSERT INTO ctlstore_dml_ledger (statement) VALUES(?)", stmt)
+			require.NoError(t, err)
+		}
+		return path
+	}
+	primary := newUpstream("primary.db",
+		"CREATE TABLE family1___table1 (field1 INTEGER PRIMARY KEY)",
+		"INSERT INTO family1___table1 VALUES(1)",
+		"INSERT INTO family1___table1 VALUES(2)",
+	)
+	shard := newUpstream("shard.db",
+		"CREATE TABLE family2___table1 (field1 INTEGER PRIMARY KEY)",
+		"INSERT INTO family2___table1 VALUES(1)",
+	)
+
+	reflector, err := ReflectorFromConfig(ReflectorConfig{
+		LDBPath: filepath.Join(dir, "ldb.db"),
+		Upstream: UpstreamConfig{
+			Driver:       "sqlite3",
+			DSN:          primary,
+			LedgerTable:  "ctlstore_dml_ledger",
+			PollInterval: 10 * time.Millisecond,
+			PollTimeout:  10 * time.Millisecond,
+			Shards:       []UpstreamShard{{LedgerID: 7, DSN: shard}},
+		},
+		LedgerHealth: ledger.HealthConfig{DisableECSBehavior: true},
+		Logger:       events.DefaultLogger,
+	})
+	require.NoError(t, err)
+	defer reflector.Close()
+
+	shovel, err := reflector.shovel()
+	require.NoError(t, err)
+	defer shovel.Close()
+	require.Equal(t, context.DeadlineExceeded, shovel.Start(ctx))
+
+	for _, table := range []string{"family1___table1", "family2___table1"} {
+		var count int
+		require.NoError(t, reflector.ldb.QueryRow("SELECT COUNT(*) FROM "+table).Scan(&count))
+		require.NotZero(t, count, table)
+	}
+	seq, err := ldb.FetchLedgerSeqFromLdb(context.Background(), reflector.ldb, 0)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, seq)
+	seq, err = ldb.FetchLedgerSeqFromLdb(context.Background(), reflector.ldb, 7)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, seq)
+}
@@ -3,6 +3,7 @@ package reflector
 import (
 	"context"
 	"io"
+	"sync"
 	"time"
 
 	"github.com/segmentio/ctlstore/pkg/errs"
@@ -21,9 +22,23 @@ type shovel struct {
 	pollTimeout       time.Duration
 	jitterCoefficient float64
 	abortOnSeqSkip    bool
-	maxSeqOnStartup   int64
+	maxSeqOnStartup   map[int]int64 // keyed by ledger ID
 	stop              chan struct{}
 	log               *events.Logger
+	// pause, if set, is held while each statement is applied, so that
+	// taking it elsewhere pauses shoveling
+	pause sync.Locker
+	// gapGracePeriod, if set, is how long to wait for skipped sequences to
+	// appear in the ledger before aborting
+	gapGracePeriod time.Duration
+	gapReportDir   string
+	// flush, if set, commits statements the writer has batched. It's
+	// called whenever the shovel catches up with the ledger.
+	flush func() error
+	// state, if set, tracks the shovel's progress across restarts, and
+	// resumeSeqs are the sequences it resumes from, keyed by ledger ID
+	state      *shovelStateFile
+	resumeSeqs map[int]schema.DMLSequence
 }
 
 func (s *shovel) Start(ctx context.Context) error {
@@ -36,7 +51,12 @@ func (s *shovel) Start(ctx context.Context) error {
 		}
 	}
 
-	var lastSeq schema.DMLSequence
+	// sequences are tracked per upstream ledger, since they are only
+	// comparable within a ledger
+	lastSeqs := map[int]schema.DMLSequence{}
+	for ledgerID, seq := range s.resumeSeqs {
+		lastSeqs[ledgerID] = seq
+	}
 
 	// Only actually close out the final cancel
 	defer safeCancel()
@@ -59,10 +79,13 @@ func (s *shovel) Start(ctx context.Context) error {
 		stats.Incr("shovel.loop_enter")
 		s.logger().Debug("shovel polling...")
 		st, err := s.source.Next(sctx)
+		if s.state != nil && (err == nil || errors.Cause(err) == errNoNewStatements) {
+			s.state.polled(time.Now())
+		}
 
 		if err != nil {
 			causeErr := errors.Cause(err)
-			if causeErr != context.DeadlineExceeded && causeErr != errNoNewStatements {
+			if causeErr != context.DeadlineExceeded && causeErr != errNoNewStatements && causeErr != errUpstreamOverloaded {
 				return err
 			}
 
@@ -79,7 +102,12 @@ func (s *shovel) Start(ctx context.Context) error {
 			// no new statements have been found.
 			//
 
-			pollSleep := jitr.Jitter(s.pollInterval, s.jitterCoefficient)
+			if err := s.flushWriter(); err != nil {
+				return err
+			}
+			s.saveState(false)
+
+			pollSleep := jitr.Jitter(s.currentPollInterval(), s.jitterCoefficient)
 			s.logger().Debug("Poll sleep %{sleepTime}s", pollSleep)
 
 			select {
@@ -94,12 +122,26 @@ func (s *shovel) Start(ctx context.Context) error {
 
 		s.logger().Debug("Shovel applying %{statement}v", st)
 
-		if lastSeq != 0 {
-			if st.Sequence > lastSeq+1 && st.Sequence.Int() > s.maxSeqOnStartup {
+		if lastSeq := lastSeqs[st.LedgerID]; lastSeq != 0 {
+			if st.Sequence > lastSeq+1 && st.Sequence.Int() > s.maxSeqOnStartup[st.LedgerID] {
 				stats.Incr("shovel.skipped_sequence")
 				s.logger().Log("shovel skip sequence from:%{fromSeq}d to:%{toSeq}d", lastSeq, st.Sequence)
 
-				if s.abortOnSeqSkip {
+				switch {
+				case !s.abortOnSeqSkip:
+					// skipped sequences are tolerated
+				case s.gapGracePeriod > 0:
+					missed, err := s.repairGap(ctx, st.LedgerID, lastSeq+1, st.Sequence-1, st.Sequence)
+					if err != nil {
+						stats.Incr("shovel.skipped_sequence_abort")
+						return err
+					}
+					for _, mst := range missed {
+						if err := s.apply(ctx, mst); err != nil {
+							return err
+						}
+					}
+				default:
 					// Mitigation for a bug that we haven't found yet
 					stats.Incr("shovel.skipped_sequence_abort")
 					err = errors.New("shovel skipped sequence")
@@ -110,15 +152,11 @@ func (s *shovel) Start(ctx context.Context) error {
 		}
 
 		// there's actually a statement to work
-		err = s.writer.ApplyDMLStatement(ctx, st)
-		if err != nil {
-			errs.Incr("shovel.apply_statement.error")
-			return errors.Wrapf(err, "ledger seq: %d", st.Sequence)
+		if err := s.apply(ctx, st); err != nil {
+			return err
 		}
-
-		lastSeq = st.Sequence
-
-		stats.Incr("shovel.apply_statement.success")
+		lastSeqs[st.LedgerID] = st.Sequence
+		s.saveState(false)
 
 		// check if the context is done each loop
 		select {
@@ -130,6 +168,62 @@ func (s *shovel) Start(ctx context.Context) error {
 	}
 }
 
+func (s *shovel) apply(ctx context.Context, st schema.DMLStatement) error {
+	if s.pause != nil {
+		s.pause.Lock()
+		defer s.pause.Unlock()
+	}
+	err := s.writer.ApplyDMLStatement(ctx, st)
+	if err != nil {
+		errs.Incr("shovel.apply_statement.error")
+		return errors.Wrapf(err, "ledger seq: %d", st.Sequence)
+	}
+	stats.Incr("shovel.apply_statement.success")
+	reportShovelApplied(st)
+	if s.state != nil {
+		s.state.applied(st)
+	}
+	return nil
+}
+
+// saveState saves the shovel's progress, at most once per the state's
+// interval unless forced. Failing to save isn't fatal, since the state
+// only speeds up restarts.
+func (s *shovel) saveState(force bool) {
+	if s.state == nil {
+		return
+	}
+	if err := s.state.save(time.Now(), force); err != nil {
+		s.logger().Log("Could not save shovel state: %{error}v", err)
+	}
+}
+
+// currentPollInterval is how long to wait before polling the source again
+// once it has run out of statements.
+func (s *shovel) currentPollInterval() time.Duration {
+	if as, ok := s.source.(adaptiveDmlSource); ok {
+		if interval := as.pollInterval(); interval > 0 {
+			return interval
+		}
+	}
+	return s.pollInterval
+}
+
+func (s *shovel) flushWriter() error {
+	if s.flush == nil {
+		return nil
+	}
+	if s.pause != nil {
+		s.pause.Lock()
+		defer s.pause.Unlock()
+	}
+	if err := s.flush(); err != nil {
+		errs.Incr("shovel.flush.error")
+		return errors.Wrap(err, "flush ldb writer")
+	}
+	return nil
+}
+
 func (s *shovel) Close() error {
 	for _, closer := range s.closers {
 		err := closer.Close()
@@ -137,6 +231,8 @@ func (s *shovel) Close() error {
 			s.logger().Log("shovel encountered error during close: %{error}s", err)
 		}
 	}
+	// after the closers, which commit anything the writer has batched
+	s.saveState(true)
 	return nil
 }
 
@@ -0,0 +1,161 @@
+package reflector
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/segmentio/errors-go"
+	"github.com/segmentio/events/v2"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+// ShovelState is the shovel's progress, which is saved to a file next to
+// the LDB so that a restarted reflector can resume where it left off rather
+// than starting over from what's in the LDB.
+type ShovelState struct {
+	// LastSequences are the last applied sequences, keyed by ledger ID.
+	// They may be ahead of the LDB while statements are group committed.
+	LastSequences map[int]int64 `json:"lastSequences"`
+	LastPollAt    time.Time     `json:"lastPollAt"`
+	// ChangelogSeq is the seq of the last changelog entry written
+	ChangelogSeq int64     `json:"changelogSeq"`
+	SavedAt      time.Time `json:"savedAt"`
+}
+
+// shovelStateFile tracks the ShovelState of the reflector's shovels, saving
+// it at most once per interval. It outlives each shovel, so that the state
+// carries over when the reflector rebuilds its shovel after an error.
+type shovelStateFile struct {
+	path     string
+	interval time.Duration
+
+	mu           sync.Mutex
+	state        ShovelState
+	changelogSeq func() int64 // reads the current changelog callback's seq
+}
+
+// loadShovelState reads the state saved at path. A missing or unreadable
+// file isn't an error, since the LDB alone is enough to start from.
+func loadShovelState(path string, interval time.Duration) *shovelStateFile {
+	f := &shovelStateFile{
+		path:     path,
+		interval: interval,
+		state:    ShovelState{LastSequences: map[int]int64{}},
+	}
+	b, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		return f
+	case err != nil:
+		events.Log("Ignoring shovel state at %{path}s: %{error}v", path, err)
+		return f
+	}
+	var state ShovelState
+	if err := json.Unmarshal(b, &state); err != nil {
+		events.Log("Ignoring shovel state at %{path}s: %{error}v", path, err)
+		return f
+	}
+	if state.LastSequences == nil {
+		state.LastSequences = map[int]int64{}
+	}
+	f.state = state
+	events.Log("Loaded shovel state saved at %{savedAt}v: %{state}+v", state.SavedAt, state)
+	return f
+}
+
+// resumeSequence returns the sequence the shovel can resume a ledger from,
+// which is the LDB's sequence if the saved state agrees with it. Otherwise
+// the LDB was replaced or the state is stale, and the shovel starts afresh.
+func (f *shovelStateFile) resumeSequence(ledgerID int, ldbSeq schema.DMLSequence) (schema.DMLSequence, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	seq, ok := f.state.LastSequences[ledgerID]
+	if !ok || seq != ldbSeq.Int() || seq == 0 {
+		return 0, false
+	}
+	return ldbSeq, true
+}
+
+// trackChangelog makes the state follow the seq of a new changelog
+// callback, and returns the seq the callback should continue from.
+func (f *shovelStateFile) trackChangelog(seq func() int64) int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.changelogSeq != nil {
+		f.state.ChangelogSeq = f.changelogSeq()
+	}
+	f.changelogSeq = seq
+	return f.state.ChangelogSeq
+}
+
+func (f *shovelStateFile) applied(st schema.DMLStatement) {
+	f.mu.Lock()
+	f.state.LastSequences[st.LedgerID] = st.Sequence.Int()
+	f.mu.Unlock()
+}
+
+func (f *shovelStateFile) polled(at time.Time) {
+	f.mu.Lock()
+	f.state.LastPollAt = at
+	f.mu.Unlock()
+}
+
+// snapshot returns a copy of the current state.
+func (f *shovelStateFile) snapshot() ShovelState {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.snapshotLocked()
+}
+
+// WARNING: assumes mu is locked
+func (f *shovelStateFile) snapshotLocked() ShovelState {
+	state := f.state
+	state.LastSequences = make(map[int]int64, len(f.state.LastSequences))
+	for id, seq := range f.state.LastSequences {
+		state.LastSequences[id] = seq
+	}
+	if f.changelogSeq != nil {
+		state.ChangelogSeq = f.changelogSeq()
+	}
+	return state
+}
+
+// save writes the state to the file if it hasn't been in the last
+// interval, or regardless if force is set. The file is replaced atomically
+// so that a crash never leaves a partial state behind.
+func (f *shovelStateFile) save(now time.Time, force bool) error {
+	f.mu.Lock()
+	if !force && now.Sub(f.state.SavedAt) < f.interval {
+		f.mu.Unlock()
+		return nil
+	}
+	f.state.SavedAt = now
+	state := f.snapshotLocked()
+	f.mu.Unlock()
+
+	b, err := json.Marshal(state)
+	if err != nil {
+		return errors.Wrap(err, "encode shovel state")
+	}
+	tmpPath := f.path + ".tmp"
+	if err := os.WriteFile(tmpPath, b, 0644); err != nil {
+		errs.Incr("shovel.state.save_error")
+		return errors.Wrap(err, "write shovel state")
+	}
+	if err := os.Rename(tmpPath, f.path); err != nil {
+		errs.Incr("shovel.state.save_error")
+		return errors.Wrap(err, "rename shovel state")
+	}
+	return nil
+}
+
+// ServeHTTP writes the current state as JSON.
+func (f *shovelStateFile) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(f.snapshot())
+}
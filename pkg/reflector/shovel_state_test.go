@@ -0,0 +1,93 @@
+package reflector
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+func TestShovelStateSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ldb.db.state.json")
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	f := loadShovelState(path, time.Minute)
+	require.Empty(t, f.snapshot().LastSequences)
+
+	var clSeq int64 = 7
+	require.EqualValues(t, 0, f.trackChangelog(func() int64 { return clSeq }))
+	f.applied(schema.DMLStatement{LedgerID: 1, Sequence: 42})
+	f.polled(now)
+	require.NoError(t, f.save(now, false))
+
+	// within the interval, unless forced
+	f.applied(schema.DMLStatement{LedgerID: 1, Sequence: 43})
+	require.NoError(t, f.save(now.Add(time.Second), false))
+	require.EqualValues(t, 42, loadShovelState(path, time.Minute).snapshot().LastSequences[1])
+	require.NoError(t, f.save(now.Add(time.Second), true))
+
+	loaded := loadShovelState(path, time.Minute).snapshot()
+	require.EqualValues(t, 43, loaded.LastSequences[1])
+	require.EqualValues(t, 7, loaded.ChangelogSeq)
+	require.True(t, now.Equal(loaded.LastPollAt))
+	require.True(t, now.Add(time.Second).Equal(loaded.SavedAt))
+
+	_, err := os.Stat(path + ".tmp")
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestShovelStateLoadCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ldb.db.state.json")
+	require.NoError(t, os.WriteFile(path, []byte("{not json"), 0644))
+
+	f := loadShovelState(path, time.Minute)
+	require.Empty(t, f.snapshot().LastSequences)
+	_, ok := f.resumeSequence(1, 10)
+	require.False(t, ok)
+}
+
+func TestShovelStateResumeSequence(t *testing.T) {
+	f := loadShovelState(filepath.Join(t.TempDir(), "state.json"), time.Minute)
+	f.applied(schema.DMLStatement{LedgerID: 1, Sequence: 10})
+
+	seq, ok := f.resumeSequence(1, 10)
+	require.True(t, ok)
+	require.EqualValues(t, 10, seq)
+
+	_, ok = f.resumeSequence(1, 9)
+	require.False(t, ok, "the LDB disagrees with the state")
+	_, ok = f.resumeSequence(2, 10)
+	require.False(t, ok, "no state for the ledger")
+}
+
+func TestShovelStateTrackChangelog(t *testing.T) {
+	f := loadShovelState(filepath.Join(t.TempDir(), "state.json"), time.Minute)
+
+	first := int64(0)
+	first = f.trackChangelog(func() int64 { return first })
+	first = 5
+
+	// a rebuilt shovel continues from the previous callback's seq
+	second := f.trackChangelog(func() int64 { return 9 })
+	require.EqualValues(t, 5, second)
+	require.EqualValues(t, 9, f.snapshot().ChangelogSeq)
+}
+
+func TestShovelStateServeHTTP(t *testing.T) {
+	f := loadShovelState(filepath.Join(t.TempDir(), "state.json"), time.Minute)
+	f.applied(schema.DMLStatement{LedgerID: 3, Sequence: 100})
+
+	w := httptest.NewRecorder()
+	f.ServeHTTP(w, httptest.NewRequest("GET", "/debug/shovel-state/ldb", nil))
+	require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var got ShovelState
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+	require.EqualValues(t, 100, got.LastSequences[3])
+}
@@ -0,0 +1,101 @@
+package reflector
+
+import (
+	"database/sql"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/segmentio/events/v2"
+	"github.com/segmentio/stats/v4"
+)
+
+// SQLiteDriver is the Driver of an UpstreamConfig whose CtlDB is a SQLite
+// file, typically in WAL mode on storage shared with the executive, for
+// small edge deployments.
+const SQLiteDriver = "sqlite3"
+
+// defaultUpstreamBusyTimeout is how long ledger queries wait for the writer
+// of a SQLite CtlDB to release its locks by default, before failing with
+// SQLITE_BUSY.
+const defaultUpstreamBusyTimeout = 5 * time.Second
+
+// database/sql keeps this many idle connections by default
+const defaultMaxIdleConns = 2
+
+// setSQLiteUpstreamDSNParameters sets the parameters that a reflector reads
+// a SQLite CtlDB with, unless the DSN already sets them. The CtlDB is only
+// ever read, and the reads wait out the writer's locks.
+func setSQLiteUpstreamDSNParameters(dsn string, busyTimeout time.Duration) (string, error) {
+	if busyTimeout <= 0 {
+		busyTimeout = defaultUpstreamBusyTimeout
+	}
+	path, rawQuery, _ := strings.Cut(dsn, "?")
+	q, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", errors.Wrap(err, "parse sqlite3 upstream dsn")
+	}
+	parameters := map[string]string{
+		"_busy_timeout": strconv.FormatInt(busyTimeout.Milliseconds(), 10),
+		"_query_only":   "true",
+	}
+	for name, value := range parameters {
+		if q.Get(name) == "" {
+			q.Set(name, value)
+		}
+	}
+	return path + "?" + q.Encode(), nil
+}
+
+// sqliteLedgerFile notices when the file of a SQLite CtlDB is replaced, such
+// as when a compacted copy of it is renamed over it, since the connections to
+// the old file would otherwise keep reading its ledger.
+type sqliteLedgerFile struct {
+	db   *sql.DB
+	path string
+	info os.FileInfo
+}
+
+// newSQLiteLedgerFile returns nil if the DSN isn't of a file.
+func newSQLiteLedgerFile(db *sql.DB, dsn string) (*sqliteLedgerFile, error) {
+	path, rawQuery, _ := strings.Cut(strings.TrimPrefix(dsn, "file:"), "?")
+	q, _ := url.ParseQuery(rawQuery)
+	if path == "" || path == ":memory:" || q.Get("mode") == "memory" || q.Get("vfs") == "memdb" {
+		return nil, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "stat sqlite3 upstream")
+	}
+	return &sqliteLedgerFile{db: db, path: path, info: info}, nil
+}
+
+// reopenIfRotated closes the connections to the file if it has been replaced,
+// so that the next query reads the new file. A missing file is taken to be in
+// the middle of being replaced, and is checked again on the next poll.
+func (f *sqliteLedgerFile) reopenIfRotated() error {
+	if f == nil {
+		return nil
+	}
+	info, err := os.Stat(f.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "stat sqlite3 upstream")
+	}
+	if os.SameFile(info, f.info) {
+		return nil
+	}
+	events.Log("Upstream %{path}s was replaced, reopening it", f.path)
+	stats.Incr("sql_dml_source.upstream_rotated")
+	// there are no queries in flight between polls, so every connection is
+	// idle and is closed
+	f.db.SetMaxIdleConns(0)
+	f.db.SetMaxIdleConns(defaultMaxIdleConns)
+	f.info = info
+	return nil
+}
@@ -0,0 +1,242 @@
+package reflector
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/segmentio/ctlstore/pkg/ctldb"
+	"github.com/segmentio/ctlstore/pkg/ledger"
+	"github.com/segmentio/events/v2"
+	"github.com/stretchr/testify/require"
+)
+
+type sqliteUpstreamTestFn func(t *testing.T, journalMode string)
+
+func TestAllSQLiteUpstream(t *testing.T) {
+	journalModes := []string{"wal", "delete"}
+	testFns := map[string]sqliteUpstreamTestFn{
+		"testSQLiteUpstreamReadsCommitted": testSQLiteUpstreamReadsCommitted,
+		"testSQLiteUpstreamQueryOnly":      testSQLiteUpstreamQueryOnly,
+		"testSQLiteUpstreamRotation":       testSQLiteUpstreamRotation,
+		"testSQLiteUpstreamReflector":      testSQLiteUpstreamReflector,
+	}
+
+	for _, journalMode := range journalModes {
+		for testName, testFn := range testFns {
+			t.Run(testName+"_"+journalMode, func(t *testing.T) {
+				testFn(t, journalMode)
+			})
+		}
+	}
+}
+
+type sqliteUpstreamTestUtil struct {
+	t    *testing.T
+	path string
+	db   *sql.DB // the executive's connection to the ctldb
+}
+
+func newSQLiteUpstreamTestUtil(t *testing.T, journalMode string) *sqliteUpstreamTestUtil {
+	u := &sqliteUpstreamTestUtil{t: t, path: filepath.Join(t.TempDir(), "ctldb.db")}
+	u.db = u.create(u.path, journalMode)
+	return u
+}
+
+// create makes a ctldb at path
+func (u *sqliteUpstreamTestUtil) create(path string, journalMode string) *sql.DB {
+	db, err := sql.Open("sqlite3", path+"?_journal_mode="+journalMode)
+	require.NoError(u.t, err)
+	u.t.Cleanup(func() { db.Close() })
+	_, err = db.Exec(ctldb.CtlDBSchemaByDriver["sqlite3"])
+	require.NoError(u.t, err)
+	return db
+}
+
+func (u *sqliteUpstreamTestUtil) addStatements(db *sql.DB, statements ...string) {
+	for _, st := range statements {
+		_, err := db.Exec("INSERT INTO ctlstore_dml_ledger (statement) VALUES(?)", st)
+		require.NoError(u.t, err)
+	}
+}
+
+// source reads the ctldb as a reflector does
+func (u *sqliteUpstreamTestUtil) source(busyTimeout time.Duration) *sqlDmlSource {
+	dsn, err := setSQLiteUpstreamDSNParameters(u.path, busyTimeout)
+	require.NoError(u.t, err)
+	db, err := sql.Open(SQLiteDriver, dsn)
+	require.NoError(u.t, err)
+	u.t.Cleanup(func() { db.Close() })
+	file, err := newSQLiteLedgerFile(db, u.path)
+	require.NoError(u.t, err)
+	return &sqlDmlSource{
+		db:              db,
+		ledgerTableName: "ctlstore_dml_ledger",
+		file:            file,
+	}
+}
+
+func (u *sqliteUpstreamTestUtil) readAll(src *sqlDmlSource) []string {
+	var res []string
+	for {
+		st, err := src.Next(context.Background())
+		if err == errNoNewStatements {
+			return res
+		}
+		require.NoError(u.t, err)
+		res = append(res, st.Statement)
+	}
+}
+
+func testSQLiteUpstreamReadsCommitted(t *testing.T, journalMode string) {
+	u := newSQLiteUpstreamTestUtil(t, journalMode)
+	src := u.source(0)
+
+	u.addStatements(u.db, "statement 1", "statement 2")
+	require.Equal(t, []string{"statement 1", "statement 2"}, u.readAll(src))
+
+	// statements of a transaction that's still open aren't read
+	tx, err := u.db.Begin()
+	require.NoError(t, err)
+	_, err = tx.Exec("INSERT INTO ctlstore_dml_ledger (statement) VALUES('statement 3')")
+	require.NoError(t, err)
+	if journalMode == "wal" {
+		// WAL readers aren't blocked by the writer
+		require.Empty(t, u.readAll(src))
+	}
+	require.NoError(t, tx.Commit())
+	require.Equal(t, []string{"statement 3"}, u.readAll(src))
+}
+
+func testSQLiteUpstreamQueryOnly(t *testing.T, journalMode string) {
+	u := newSQLiteUpstreamTestUtil(t, journalMode)
+	src := u.source(0)
+
+	_, err := src.db.Exec("INSERT INTO ctlstore_dml_ledger (statement) VALUES('statement 1')")
+	require.Error(t, err)
+	require.Empty(t, u.readAll(src))
+}
+
+func testSQLiteUpstreamRotation(t *testing.T, journalMode string) {
+	u := newSQLiteUpstreamTestUtil(t, journalMode)
+	src := u.source(0)
+
+	u.addStatements(u.db, "statement 1", "statement 2")
+	require.Equal(t, []string{"statement 1", "statement 2"}, u.readAll(src))
+
+	// a copy of the ctldb with more statements is moved over it
+	rotatedPath := filepath.Join(filepath.Dir(u.path), "ctldb.db.new")
+	rotated := u.create(rotatedPath, journalMode)
+	u.addStatements(rotated, "statement 1", "statement 2", "statement 3")
+	require.NoError(t, rotated.Close())
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		os.Remove(u.path + suffix)
+	}
+	require.NoError(t, os.Rename(rotatedPath, u.path))
+
+	require.Equal(t, []string{"statement 3"}, u.readAll(src))
+}
+
+func testSQLiteUpstreamReflector(t *testing.T, journalMode string) {
+	u := newSQLiteUpstreamTestUtil(t, journalMode)
+	u.addStatements(u.db,
+		"CREATE TABLE family1___table1 (field1 INTEGER PRIMARY KEY, field2 VARCHAR);",
+		"INSERT INTO family1___table1 VALUES(1, 'hello');",
+	)
+
+	reflector, err := ReflectorFromConfig(ReflectorConfig{
+		LDBPath: InMemoryLDBPath,
+		Upstream: UpstreamConfig{
+			Driver:       SQLiteDriver,
+			DSN:          u.path,
+			LedgerTable:  "ctlstore_dml_ledger",
+			PollInterval: 10 * time.Millisecond,
+			PollTimeout:  time.Second,
+		},
+		LedgerHealth: ledger.HealthConfig{
+			DisableECSBehavior: true,
+			PollInterval:       10 * time.Second,
+		},
+		Logger: events.DefaultLogger,
+	})
+	require.NoError(t, err)
+	defer reflector.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reflector.Start(ctx)
+
+	// the reflector keeps up with the executive writing to the ctldb
+	u.addStatements(u.db, "INSERT INTO family1___table1 VALUES(2, 'there');")
+	reader := reflector.Reader()
+	var row struct {
+		Field1 int64  `ctlstore:"field1"`
+		Field2 string `ctlstore:"field2"`
+	}
+	require.Eventually(t, func() bool {
+		found, err := reader.GetRowByKey(ctx, &row, "family1", "table1", 2)
+		return err == nil && found
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, "there", row.Field2)
+}
+
+func TestSQLiteUpstreamBusy(t *testing.T) {
+	u := newSQLiteUpstreamTestUtil(t, "delete")
+	src := u.source(10 * time.Millisecond)
+
+	// the writer holds an exclusive lock for longer than the busy timeout
+	writer, err := sql.Open("sqlite3", u.path+"?_txlock=exclusive")
+	require.NoError(t, err)
+	defer writer.Close()
+	tx, err := writer.Begin()
+	require.NoError(t, err)
+	_, err = tx.Exec("INSERT INTO ctlstore_dml_ledger (statement) VALUES('statement 1')")
+	require.NoError(t, err)
+
+	_, err = src.Next(context.Background())
+	require.Equal(t, errUpstreamOverloaded, errors.Cause(err))
+
+	require.NoError(t, tx.Commit())
+	require.Equal(t, []string{"statement 1"}, u.readAll(src))
+}
+
+func TestSetSQLiteUpstreamDSNParameters(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		dsn         string
+		busyTimeout time.Duration
+		expected    string
+	}{
+		{
+			name:     "path",
+			dsn:      "/var/ctldb.db",
+			expected: "/var/ctldb.db?_busy_timeout=5000&_query_only=true",
+		},
+		{
+			name:        "busy timeout",
+			dsn:         "/var/ctldb.db",
+			busyTimeout: time.Second,
+			expected:    "/var/ctldb.db?_busy_timeout=1000&_query_only=true",
+		},
+		{
+			name:     "uri",
+			dsn:      "file:/var/ctldb.db?mode=ro",
+			expected: "file:/var/ctldb.db?_busy_timeout=5000&_query_only=true&mode=ro",
+		},
+		{
+			name:     "parameters already set",
+			dsn:      "/var/ctldb.db?_busy_timeout=200",
+			expected: "/var/ctldb.db?_busy_timeout=200&_query_only=true",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			dsn, err := setSQLiteUpstreamDSNParameters(test.dsn, test.busyTimeout)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, dsn)
+		})
+	}
+}
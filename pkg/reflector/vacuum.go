@@ -0,0 +1,230 @@
+package reflector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/segmentio/errors-go"
+	"github.com/segmentio/events/v2"
+	"github.com/segmentio/stats/v4"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+	"github.com/segmentio/ctlstore/pkg/ldbwriter"
+)
+
+const incrementalVacuumPages = 1000
+
+// how long to wait for the writer to finish a transaction before trying to
+// pause it again
+const vacuumPauseRetryInterval = 100 * time.Millisecond
+
+// VacuumConfig schedules compaction of the LDB, which reclaims the free
+// pages left behind by cleared and dropped tables.
+type VacuumConfig struct {
+	// Interval is how often to vacuum. Zero disables vacuuming.
+	Interval time.Duration
+	// MaxDuration bounds a single run. A full VACUUM that exceeds it is
+	// interrupted and rolled back, while an incremental one keeps the pages
+	// it has already freed. Zero means no limit.
+	MaxDuration time.Duration
+	// WindowStart and WindowEnd restrict vacuuming to an off-peak window,
+	// given as offsets from midnight UTC. The window may wrap past midnight.
+	// If both are zero, vacuums may run at any time.
+	WindowStart time.Duration
+	WindowEnd   time.Duration
+	// MinFreePages skips runs while the LDB has fewer free pages than this.
+	MinFreePages int64
+	// Incremental switches the LDB to auto_vacuum=INCREMENTAL, which takes
+	// one full VACUUM, and from then on frees pages in batches so that the
+	// shovel only pauses for one batch at a time.
+	Incremental bool
+}
+
+// vacuumer periodically compacts the LDB. Shoveling is paused while it
+// holds ldbLock for writing, and WAL checkpoints hold ldbLock for reading
+// so that they never run at the same time as a vacuum.
+type vacuumer struct {
+	config  VacuumConfig
+	db      *sql.DB
+	ldbLock *sync.RWMutex
+	now     func() time.Time
+	// flush commits the statements the writer has batched, so that a group
+	// commit doesn't stay open while shoveling is paused. Optional.
+	flush func() error
+	// inTransaction reports whether the writer has a transaction open,
+	// which shoveling is paused in the middle of when it does. Optional.
+	inTransaction func() bool
+}
+
+func (v *vacuumer) Start(ctx context.Context) {
+	events.Log("LDB vacuum scheduler starting, interval:%{interval}v", v.config.Interval)
+	defer events.Log("LDB vacuum scheduler stopped")
+	ticker := time.NewTicker(v.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !v.inWindow(v.now()) {
+				stats.Incr("ldb-vacuum-skipped", stats.T("reason", "window"))
+				continue
+			}
+			if err := v.vacuum(ctx); err != nil {
+				errs.Incr("reflector.vacuum_error")
+				events.Log("LDB vacuum failed: %{error}+v", err)
+			}
+		}
+	}
+}
+
+// inWindow reports whether t falls within the configured off-peak window.
+func (v *vacuumer) inWindow(t time.Time) bool {
+	start, end := v.config.WindowStart, v.config.WindowEnd
+	if start == 0 && end == 0 {
+		return true
+	}
+	return inDailyWindow(t, start, end)
+}
+
+func (v *vacuumer) vacuum(ctx context.Context) error {
+	free, err := v.freePages(ctx)
+	if err != nil {
+		return err
+	}
+	stats.Set("ldb-free-pages", free)
+	if free == 0 || free < v.config.MinFreePages {
+		stats.Incr("ldb-vacuum-skipped", stats.T("reason", "free-pages"))
+		return nil
+	}
+
+	if v.config.MaxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, v.config.MaxDuration)
+		defer cancel()
+	}
+
+	start := time.Now()
+	mode := "full"
+	if v.config.Incremental {
+		var autoVacuum int
+		if err := v.db.QueryRowContext(ctx, "PRAGMA auto_vacuum").Scan(&autoVacuum); err != nil {
+			return errors.Wrap(err, "read auto_vacuum")
+		}
+		// 2 is INCREMENTAL. Switching to it only takes effect after a full
+		// VACUUM, so the first run is a full one.
+		if autoVacuum == 2 {
+			mode = "incremental"
+		} else if _, err := v.db.ExecContext(ctx, "PRAGMA auto_vacuum = INCREMENTAL"); err != nil {
+			return errors.Wrap(err, "set auto_vacuum")
+		}
+	}
+	events.Log("Vacuuming LDB (%{mode}s), %{pages}d free pages", mode, free)
+
+	if mode == "incremental" {
+		err = v.incrementalVacuum(ctx)
+	} else {
+		err = v.fullVacuum(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	stats.Observe("ldb-vacuum-duration", time.Since(start), stats.T("mode", mode))
+
+	// vacuuming writes through the WAL, so truncate it rather than leaving
+	// it for the WAL monitor
+	v.ldbLock.RLock()
+	defer v.ldbLock.RUnlock()
+	w := &ldbwriter.SqlLdbWriter{Db: v.db}
+	if _, err := w.Checkpoint(ldbwriter.Truncate); err != nil {
+		return errors.Wrap(err, "checkpoint after vacuum")
+	}
+
+	if free, err = v.freePages(context.Background()); err == nil {
+		stats.Set("ldb-free-pages", free)
+	}
+	events.Log("Vacuumed LDB in %{duration}v, %{pages}d free pages remain", time.Since(start), free)
+	return nil
+}
+
+// pause takes ldbLock for writing once the writer is between transactions,
+// since the vacuum would otherwise wait on the writer's open transaction
+// while the writer waits on the vacuum to finish. The writer's group commit
+// batch is committed first, but a ledger transaction can only be waited on.
+func (v *vacuumer) pause(ctx context.Context) error {
+	for {
+		v.ldbLock.Lock()
+		if v.flush != nil {
+			if err := v.flush(); err != nil {
+				v.ldbLock.Unlock()
+				return errors.Wrap(err, "flush writer")
+			}
+		}
+		if v.inTransaction == nil || !v.inTransaction() {
+			return nil
+		}
+		v.ldbLock.Unlock()
+		stats.Incr("ldb-vacuum-waits")
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(vacuumPauseRetryInterval):
+		}
+	}
+}
+
+func (v *vacuumer) fullVacuum(ctx context.Context) error {
+	if err := v.pause(ctx); err != nil {
+		return errors.Wrap(err, "pause writer")
+	}
+	defer v.ldbLock.Unlock()
+	_, err := v.db.ExecContext(ctx, "VACUUM")
+	return errors.Wrap(err, "vacuum")
+}
+
+// incrementalVacuum frees pages a batch at a time until there are none left
+// or the context is done, unpausing the shovel between batches.
+func (v *vacuumer) incrementalVacuum(ctx context.Context) error {
+	for {
+		err := v.incrementalVacuumBatch(ctx)
+		var free int64
+		if err == nil {
+			free, err = v.freePages(ctx)
+		}
+		switch {
+		case ctx.Err() != nil:
+			events.Log("Stopping incremental vacuum: %{error}v", ctx.Err())
+			return nil
+		case err != nil:
+			return err
+		case free == 0:
+			return nil
+		}
+	}
+}
+
+func (v *vacuumer) incrementalVacuumBatch(ctx context.Context) error {
+	if err := v.pause(ctx); err != nil {
+		return errors.Wrap(err, "pause writer")
+	}
+	defer v.ldbLock.Unlock()
+	// the pragma frees one page per step, so it must be read to completion
+	// rather than executed
+	rows, err := v.db.QueryContext(ctx, fmt.Sprintf("PRAGMA incremental_vacuum(%d)", incrementalVacuumPages))
+	if err != nil {
+		return errors.Wrap(err, "incremental vacuum")
+	}
+	defer rows.Close()
+	for rows.Next() {
+	}
+	return errors.Wrap(rows.Err(), "incremental vacuum")
+}
+
+func (v *vacuumer) freePages(ctx context.Context) (int64, error) {
+	var free int64
+	err := v.db.QueryRowContext(ctx, "PRAGMA freelist_count").Scan(&free)
+	return free, errors.Wrap(err, "read freelist_count")
+}
@@ -0,0 +1,173 @@
+package reflector
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVacuumerInWindow(t *testing.T) {
+	at := func(hour int) time.Time {
+		return time.Date(2020, 1, 1, hour, 30, 0, 0, time.UTC)
+	}
+	for _, test := range []struct {
+		name       string
+		start, end time.Duration
+		in, out    []int
+	}{
+		{name: "no window", in: []int{0, 12, 23}},
+		{name: "same day", start: 2 * time.Hour, end: 5 * time.Hour, in: []int{2, 4}, out: []int{1, 5, 23}},
+		{name: "past midnight", start: 22 * time.Hour, end: 3 * time.Hour, in: []int{22, 23, 0, 2}, out: []int{3, 12, 21}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			v := &vacuumer{config: VacuumConfig{WindowStart: test.start, WindowEnd: test.end}}
+			for _, hour := range test.in {
+				require.True(t, v.inWindow(at(hour)), "hour %d", hour)
+			}
+			for _, hour := range test.out {
+				require.False(t, v.inWindow(at(hour)), "hour %d", hour)
+			}
+		})
+	}
+}
+
+func TestVacuumer(t *testing.T) {
+	for _, incremental := range []bool{false, true} {
+		t.Run(map[bool]string{false: "full", true: "incremental"}[incremental], func(t *testing.T) {
+			ctx := context.Background()
+			db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "ldb.db")+"?_journal_mode=wal")
+			require.NoError(t, err)
+			defer db.Close()
+
+			// leave behind plenty of free pages
+			fill := func() {
+				_, err = db.Exec("CREATE TABLE family___table (id INTEGER PRIMARY KEY, value TEXT)")
+				require.NoError(t, err)
+				for i := 0; i < 500; i++ {
+					_, err = db.Exec("INSERT INTO family___table (value) VALUES(?)", strings.Repeat("x", 4096))
+					require.NoError(t, err)
+				}
+				_, err = db.Exec("DROP TABLE family___table")
+				require.NoError(t, err)
+			}
+			fill()
+
+			v := &vacuumer{
+				config:  VacuumConfig{MinFreePages: 10, Incremental: incremental},
+				db:      db,
+				ldbLock: &sync.RWMutex{},
+				now:     time.Now,
+			}
+			free, err := v.freePages(ctx)
+			require.NoError(t, err)
+			require.Greater(t, free, int64(500))
+
+			require.NoError(t, v.vacuum(ctx))
+			free, err = v.freePages(ctx)
+			require.NoError(t, err)
+			require.Zero(t, free)
+
+			if incremental {
+				var autoVacuum int
+				require.NoError(t, db.QueryRow("PRAGMA auto_vacuum").Scan(&autoVacuum))
+				require.Equal(t, 2, autoVacuum)
+
+				// later runs free pages in batches
+				fill()
+				require.NoError(t, v.vacuum(ctx))
+				free, err = v.freePages(ctx)
+				require.NoError(t, err)
+				require.Zero(t, free)
+			}
+		})
+	}
+}
+
+func TestVacuumerWaitsForWriterTransaction(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "ldb.db")+"?_journal_mode=wal")
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec("CREATE TABLE family___table (id INTEGER PRIMARY KEY, value TEXT)")
+	require.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		_, err = db.Exec("INSERT INTO family___table (value) VALUES(?)", strings.Repeat("x", 4096))
+		require.NoError(t, err)
+	}
+	_, err = db.Exec("DELETE FROM family___table")
+	require.NoError(t, err)
+
+	// the writer is in the middle of a ledger transaction
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	_, err = tx.Exec("INSERT INTO family___table (value) VALUES('in tx')")
+	require.NoError(t, err)
+	open := true
+
+	v := &vacuumer{
+		config:  VacuumConfig{},
+		db:      db,
+		ldbLock: &sync.RWMutex{},
+		now:     time.Now,
+		inTransaction: func() bool {
+			return open
+		},
+	}
+	done := make(chan error)
+	go func() { done <- v.vacuum(ctx) }()
+
+	select {
+	case err := <-done:
+		t.Fatalf("vacuumed within the writer's transaction: %v", err)
+	case <-time.After(3 * vacuumPauseRetryInterval):
+	}
+
+	// the writer commits between statements, while it's paused
+	v.ldbLock.RLock()
+	require.NoError(t, tx.Commit())
+	open = false
+	v.ldbLock.RUnlock()
+	require.NoError(t, <-done)
+	free, err := v.freePages(ctx)
+	require.NoError(t, err)
+	require.Zero(t, free)
+}
+
+func TestVacuumerWaitCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	v := &vacuumer{
+		ldbLock:       &sync.RWMutex{},
+		inTransaction: func() bool { return true },
+	}
+	cancel()
+	require.Equal(t, context.Canceled, v.pause(ctx))
+	// the lock isn't left held
+	require.True(t, v.ldbLock.TryLock())
+}
+
+func TestVacuumerFlushesWriter(t *testing.T) {
+	ctx := context.Background()
+	flushed := 0
+	v := &vacuumer{
+		ldbLock: &sync.RWMutex{},
+		flush: func() error {
+			flushed++
+			return nil
+		},
+	}
+	require.NoError(t, v.pause(ctx))
+	require.Equal(t, 1, flushed)
+	v.ldbLock.Unlock()
+
+	// a batch that fails to commit doesn't leave the lock held
+	v.flush = func() error { return errors.New("commit failed") }
+	require.Error(t, v.pause(ctx))
+	require.True(t, v.ldbLock.TryLock())
+}
@@ -0,0 +1,338 @@
+package reflector
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/segmentio/errors-go"
+	"github.com/segmentio/events/v2"
+	"github.com/segmentio/stats/v4"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+	"github.com/segmentio/ctlstore/pkg/ldb"
+	"github.com/segmentio/ctlstore/pkg/schema"
+	"github.com/segmentio/ctlstore/pkg/sqlite"
+)
+
+// VerifyConfig configures a check of the LDB against the upstream ctldb,
+// made when the reflector starts and before it begins shoveling, to detect
+// LDBs that diverged from the ctldb, e.g. because of past bugs.
+type VerifyConfig struct {
+	// SampleSize is how many random rows of each table are compared. Zero
+	// disables verification.
+	SampleSize int
+	// FailOnDivergence stops the reflector from starting if any sampled
+	// row differs from the ctldb.
+	FailOnDivergence bool
+	// ReportDir is where the verification report is written. Defaults to
+	// the directory of the LDB.
+	ReportDir string // optional
+}
+
+// VerifyReport is the outcome of verifying the LDB against the ctldb.
+type VerifyReport struct {
+	StartedAt time.Time           `json:"startedAt"`
+	Took      string              `json:"took"`
+	Tables    []TableVerifyReport `json:"tables"`
+	// Diverged is the total number of sampled rows which differ from the
+	// ctldb.
+	Diverged int `json:"diverged"`
+}
+
+// TableVerifyReport is the outcome of verifying one table.
+type TableVerifyReport struct {
+	Table   string `json:"table"`
+	Sampled int    `json:"sampled"`
+	// Pending counts rows which differ from the ctldb, but which the
+	// ledger has yet to be applied to the LDB for, so that the difference
+	// may only be lag.
+	Pending  int           `json:"pending"`
+	Diverged []DivergedRow `json:"diverged,omitempty"`
+	// Error is set if the table couldn't be verified.
+	Error string `json:"error,omitempty"`
+}
+
+// DivergedRow describes a sampled row which differs from the ctldb.
+type DivergedRow struct {
+	Key      []interface{}          `json:"key"`
+	LDB      map[string]interface{} `json:"ldb"`
+	Upstream map[string]interface{} `json:"upstream"` // nil if the row is missing
+}
+
+// verifyUpstream is an upstream ledger the LDB is verified against.
+type verifyUpstream struct {
+	db          *sql.DB
+	ledgerID    int
+	ledgerTable string
+}
+
+// verifier compares sampled LDB rows with the ctldb.
+type verifier struct {
+	config    VerifyConfig
+	ldb       *sql.DB
+	upstreams []verifyUpstream
+	logger    *events.Logger
+}
+
+// verify samples rows from every table of the LDB and compares them with
+// the upstream ctldb, writing a report of the outcome. It returns an error
+// if rows diverged and the config says to fail on divergence.
+func (v *verifier) verify(ctx context.Context) error {
+	report := VerifyReport{StartedAt: time.Now()}
+	if err := ldb.EnsureLdbInitialized(ctx, v.ldb); err != nil {
+		return errors.Wrap(err, "initialize ldb")
+	}
+	tables, err := (&sqlite.SqliteDBInfo{Db: v.ldb}).GetAllTables(ctx)
+	if err != nil {
+		return errors.Wrap(err, "get ldb tables")
+	}
+	v.logger.Log("Verifying %{sampleSize}d rows of %{tables}d tables against the ctldb", v.config.SampleSize, len(tables))
+	for _, ft := range tables {
+		tr, err := v.verifyTable(ctx, ft)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			v.logger.Log("Could not verify table %{table}s: %{error}+v", ft, err)
+			tr.Error = err.Error()
+			errs.Incr("reflector.verify_errors", ft.Tag())
+		}
+		stats.Add("reflector.verify_rows_sampled", tr.Sampled, ft.Tag())
+		stats.Add("reflector.verify_rows_pending", tr.Pending, ft.Tag())
+		stats.Add("reflector.verify_rows_diverged", len(tr.Diverged), ft.Tag())
+		report.Diverged += len(tr.Diverged)
+		report.Tables = append(report.Tables, tr)
+	}
+	report.Took = time.Since(report.StartedAt).String()
+
+	path, err := v.writeReport(report)
+	if err != nil {
+		v.logger.Log("Could not write verification report: %{error}+v", err)
+	} else {
+		v.logger.Log("Verified LDB against the ctldb, %{diverged}d rows diverged. Report written to %{path}s", report.Diverged, path)
+	}
+	if report.Diverged > 0 && v.config.FailOnDivergence {
+		return errors.Errorf("%d sampled rows of the LDB diverged from the ctldb", report.Diverged)
+	}
+	return nil
+}
+
+func (v *verifier) verifyTable(ctx context.Context, ft schema.FamilyTable) (TableVerifyReport, error) {
+	tr := TableVerifyReport{Table: ft.String()}
+	cols, err := (&sqlite.SqliteDBInfo{Db: v.ldb}).GetColumnInfo(ctx, []string{ft.String()})
+	if err != nil {
+		return tr, errors.Wrap(err, "get columns")
+	}
+	var names, keys []string
+	var keyIdx []int
+	for i, col := range cols {
+		names = append(names, col.ColumnName)
+		if col.IsPrimaryKey {
+			keys = append(keys, col.ColumnName)
+			keyIdx = append(keyIdx, i)
+		}
+	}
+	if len(keys) == 0 {
+		return tr, errors.New("table has no primary key")
+	}
+	selectCols := strings.Join(quoteIdents(names), ",")
+
+	// ordering by random() scans the table, but this happens once, before
+	// the reflector starts applying the ledger
+	sampled, err := queryRows(ctx, v.ldb, fmt.Sprintf("SELECT %s FROM %s ORDER BY random() LIMIT %d",
+		selectCols, ft.String(), v.config.SampleSize), len(names))
+	if err != nil {
+		return tr, errors.Wrap(err, "sample ldb")
+	}
+
+	where := make([]string, len(keys))
+	for i, k := range quoteIdents(keys) {
+		where[i] = k + " = ?"
+	}
+	upstreamQuery := fmt.Sprintf("SELECT %s FROM %s WHERE %s", selectCols, ft.String(), strings.Join(where, " AND "))
+
+	var diverged []DivergedRow
+	for _, row := range sampled {
+		key := make([]interface{}, len(keyIdx))
+		for i, idx := range keyIdx {
+			key[i] = row[idx]
+		}
+		upstream, err := v.upstreamRow(ctx, upstreamQuery, key, len(names))
+		if err != nil {
+			return tr, err
+		}
+		tr.Sampled++
+		if upstream != nil && rowsEqual(row, upstream) {
+			continue
+		}
+		d := DivergedRow{Key: displayValues(key), LDB: rowMap(names, row)}
+		if upstream != nil {
+			d.Upstream = rowMap(names, upstream)
+		}
+		diverged = append(diverged, d)
+	}
+	if len(diverged) == 0 {
+		return tr, nil
+	}
+
+	// the ctldb is ahead of the LDB, so rows may differ only because the
+	// ledger hasn't been applied yet
+	pending, err := v.pendingStatements(ctx, ft)
+	if err != nil {
+		return tr, err
+	}
+	if pending {
+		tr.Pending = len(diverged)
+	} else {
+		tr.Diverged = diverged
+	}
+	return tr, nil
+}
+
+// upstreamRow returns the row with the key from whichever upstream has the
+// table, or nil if none has the row.
+func (v *verifier) upstreamRow(ctx context.Context, query string, key []interface{}, numCols int) ([]interface{}, error) {
+	var lastErr error
+	for _, u := range v.upstreams {
+		rows, err := queryRows(ctx, u.db, query, numCols, key...)
+		if err != nil {
+			// with a sharded ctldb, the table only exists on one shard
+			lastErr = err
+			continue
+		}
+		if len(rows) > 0 {
+			return rows[0], nil
+		}
+		return nil, nil
+	}
+	return nil, errors.Wrap(lastErr, "query upstream")
+}
+
+// pendingStatements returns whether any ledger has statements for the table
+// which haven't been applied to the LDB.
+func (v *verifier) pendingStatements(ctx context.Context, ft schema.FamilyTable) (bool, error) {
+	for _, u := range v.upstreams {
+		seq, err := ldb.FetchLedgerSeqFromLdb(ctx, v.ldb, u.ledgerID)
+		if err != nil {
+			return false, errors.Wrap(err, "fetch ldb sequence")
+		}
+		var n int64
+		err = u.db.QueryRowContext(ctx,
+			"SELECT COUNT(*) FROM "+u.ledgerTable+" WHERE seq > ? AND statement LIKE ?",
+			seq.Int(), "%"+ft.String()+"%").Scan(&n)
+		if err != nil {
+			return false, errors.Wrap(err, "count pending statements")
+		}
+		if n > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (v *verifier) writeReport(report VerifyReport) (string, error) {
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("ldb-verify-%d.json", report.StartedAt.Unix())
+	path := filepath.Join(v.config.ReportDir, name)
+	return path, os.WriteFile(path, b, 0644)
+}
+
+func queryRows(ctx context.Context, db *sql.DB, query string, numCols int, args ...interface{}) ([][]interface{}, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res [][]interface{}
+	for rows.Next() {
+		row := make([]interface{}, numCols)
+		ptrs := make([]interface{}, numCols)
+		for i := range row {
+			ptrs[i] = &row[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		res = append(res, row)
+	}
+	return res, rows.Err()
+}
+
+func quoteIdents(names []string) []string {
+	res := make([]string, len(names))
+	for i, name := range names {
+		res[i] = `"` + name + `"`
+	}
+	return res
+}
+
+// rowsEqual compares rows read from SQLite and MySQL, which return the same
+// values as different types, e.g. MySQL returns numbers as text.
+func rowsEqual(a, b []interface{}) bool {
+	for i := range a {
+		if !valuesEqual(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func valuesEqual(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	as, bs := valueString(a), valueString(b)
+	if as == bs {
+		return true
+	}
+	af, aerr := strconv.ParseFloat(as, 64)
+	bf, berr := strconv.ParseFloat(bs, 64)
+	return aerr == nil && berr == nil && af == bf
+}
+
+func valueString(v interface{}) string {
+	switch v := v.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func rowMap(names []string, row []interface{}) map[string]interface{} {
+	m := make(map[string]interface{}, len(names))
+	for i, name := range names {
+		m[name] = displayValues(row[i : i+1])[0]
+	}
+	return m
+}
+
+// displayValues converts text read as []byte to strings, so that reports
+// aren't full of base64.
+func displayValues(values []interface{}) []interface{} {
+	res := make([]interface{}, len(values))
+	for i, v := range values {
+		if b, ok := v.([]byte); ok && utf8.Valid(b) {
+			v = string(b)
+		}
+		res[i] = v
+	}
+	return res
+}
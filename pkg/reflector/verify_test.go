@@ -0,0 +1,124 @@
+package reflector
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/segmentio/events/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/ldb"
+)
+
+func TestVerifier(t *testing.T) {
+	for _, test := range []struct {
+		name             string
+		upstream         []string
+		ledger           []string
+		failOnDivergence bool
+		wantDiverged     int
+		wantPending      int
+		wantErr          string
+	}{
+		{
+			name:     "matching",
+			upstream: []string{"INSERT INTO family1___table1 VALUES (1, 'one', 1.5)"},
+		},
+		{
+			name:         "diverged",
+			upstream:     []string{"INSERT INTO family1___table1 VALUES (1, 'uno', 1.5)"},
+			wantDiverged: 1,
+		},
+		{
+			name:         "missing upstream",
+			wantDiverged: 1,
+		},
+		{
+			name:             "fail on divergence",
+			upstream:         []string{"INSERT INTO family1___table1 VALUES (1, 'uno', 1.5)"},
+			failOnDivergence: true,
+			wantDiverged:     1,
+			wantErr:          "1 sampled rows of the LDB diverged from the ctldb",
+		},
+		{
+			name:     "pending",
+			upstream: []string{"INSERT INTO family1___table1 VALUES (1, 'uno', 1.5)"},
+			ledger: []string{
+				`REPLACE INTO family1___table1 ("id","name","score") VALUES(1,'uno',1.5)`,
+			},
+			wantPending: 1,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			dir := t.TempDir()
+
+			ldbDB, err := sql.Open("sqlite3", filepath.Join(dir, "ldb.db"))
+			require.NoError(t, err)
+			defer ldbDB.Close()
+			upstreamDB, err := sql.Open("sqlite3", filepath.Join(dir, "ctldb.db"))
+			require.NoError(t, err)
+			defer upstreamDB.Close()
+
+			require.NoError(t, ldb.EnsureLdbInitialized(ctx, ldbDB))
+			for _, db := range []*sql.DB{ldbDB, upstreamDB} {
+				_, err = db.Exec("CREATE TABLE family1___table1 (id INTEGER PRIMARY KEY, name VARCHAR, score REAL)")
+				require.NoError(t, err)
+			}
+			_, err = ldbDB.Exec("INSERT INTO family1___table1 VALUES (1, 'one', 1.5)")
+			require.NoError(t, err)
+			_, err = upstreamDB.Exec(`CREATE TABLE ctlstore_dml_ledger (
+				seq INTEGER PRIMARY KEY AUTOINCREMENT,
+				leader_ts INTEGER NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				statement VARCHAR(768)
+			)`)
+			require.NoError(t, err)
+			for _, stmt := range test.upstream {
+				_, err = upstreamDB.Exec(stmt)
+				require.NoError(t, err)
+			}
+			for _, stmt := range test.ledger {
+				_, err = upstreamDB.Exec("INSERT INTO ctlstore_dml_ledger (statement) VALUES(?)", stmt)
+				require.NoError(t, err)
+			}
+
+			v := &verifier{
+				config: VerifyConfig{
+					SampleSize:       10,
+					FailOnDivergence: test.failOnDivergence,
+					ReportDir:        dir,
+				},
+				ldb: ldbDB,
+				upstreams: []verifyUpstream{{
+					db:          upstreamDB,
+					ledgerTable: "ctlstore_dml_ledger",
+				}},
+				logger: events.DefaultLogger,
+			}
+			err = v.verify(ctx)
+			if test.wantErr != "" {
+				require.EqualError(t, err, test.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			reports, err := filepath.Glob(filepath.Join(dir, "ldb-verify-*.json"))
+			require.NoError(t, err)
+			require.Len(t, reports, 1)
+			b, err := os.ReadFile(reports[0])
+			require.NoError(t, err)
+			var report VerifyReport
+			require.NoError(t, json.Unmarshal(b, &report))
+			require.Equal(t, test.wantDiverged, report.Diverged)
+			require.Len(t, report.Tables, 1)
+			require.Equal(t, "family1___table1", report.Tables[0].Table)
+			require.Equal(t, 1, report.Tables[0].Sampled)
+			require.Equal(t, test.wantPending, report.Tables[0].Pending)
+			require.Len(t, report.Tables[0].Diverged, test.wantDiverged)
+		})
+	}
+}
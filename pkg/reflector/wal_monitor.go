@@ -19,6 +19,10 @@ type (
 		PollInterval               time.Duration
 		Path                       string
 		WALCheckpointThresholdSize int64
+		// The type of checkpoint to perform, outside of the schedule's
+		// truncate window
+		CheckpointType ldbwriter.CheckpointType
+		Schedule       CheckpointSchedule
 	}
 
 	// WALMonitor is responsible for querying the file size of sqlite's WAL file while in WAL mode as well as sqlite's checkpointing of the WAL file.
@@ -31,14 +35,16 @@ type (
 		// tickerFunc returns a ticker configured for the polling interval
 		tickerFunc   func() *time.Ticker
 		cpTesterFunc checkpointTesterFunc
+		scheduler    checkpointScheduler
+		now          func() time.Time
 		// consecutiveMaxErrors indicates when to stop performing a monitor when it fails consecutiveMaxErrors in a row
 		// under default configuration, this is 5 minutes of failures before stopping
 		consecutiveMaxErrors int
 	}
 	// returns the size of the wal file, or error
 	walSizeFunc func(string) (int64, error)
-	// returns WAL checkpoint status, or error
-	checkpointTesterFunc func() (*ldbwriter.PragmaWALResult, error)
+	// performs a checkpoint of the type, returning the WAL checkpoint status, or error
+	checkpointTesterFunc func(ldbwriter.CheckpointType) (*ldbwriter.PragmaWALResult, error)
 	// MonitorOps configuration functions that customize the monitor
 	MonitorOps func(config *WALMonitor)
 )
@@ -52,6 +58,11 @@ func NewMonitor(cfg MonitorConfig, checkpointTester checkpointTesterFunc, opts .
 		tickerFunc: func() *time.Ticker {
 			return time.NewTicker(cfg.PollInterval)
 		},
+		scheduler: checkpointScheduler{
+			schedule:       cfg.Schedule,
+			checkpointType: cfg.CheckpointType,
+		},
+		now: time.Now,
 	}
 
 	for _, opt := range opts {
@@ -97,13 +108,21 @@ func (m *WALMonitor) Start(ctx context.Context) {
 
 		ldbFileName := path.Base(m.walPath)
 		stats.Set("wal-file-size", size, stats.T("ldb", ldbFileName))
+		reportWALSize(ldbFileName, size)
 
 		if size <= m.walCheckpointThresholdSize {
 			stats.Incr("wal-no-checkpoint")
+			m.scheduler.reset()
+			return
+		}
+		cpType, ok := m.scheduler.next(m.now())
+		if !ok {
+			stats.Incr("wal-checkpoint-deferred", stats.T("ldb", ldbFileName))
 			return
 		}
 
-		res, err := m.cpTesterFunc()
+		start := time.Now()
+		res, err := m.cpTesterFunc(cpType)
 		if err != nil {
 			events.Log("error checking wal's checkpoint status, %s", err)
 			failedInARow++
@@ -122,6 +141,7 @@ func (m *WALMonitor) Start(ctx context.Context) {
 		stats.Set("wal-checkpoint-status", 1, stats.T("busy", isBusy), stats.T("ldb", ldbFileName))
 		stats.Set("wal-total-pages", res.Log, stats.T("ldb", ldbFileName))
 		stats.Set("wal-checkpointed-pages", res.Checkpointed, stats.T("ldb", ldbFileName))
+		reportWALCheckpoint(ldbFileName, res, isBusy, time.Since(start))
 
 		failedInARow = 0
 	})
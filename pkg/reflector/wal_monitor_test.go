@@ -43,7 +43,7 @@ func (f *fake) Ticker() func(m *WALMonitor) {
 
 func (f *fake) Checkpointer() func(m *WALMonitor) {
 	return func(m *WALMonitor) {
-		m.cpTesterFunc = func() (*ldbwriter.PragmaWALResult, error) {
+		m.cpTesterFunc = func(ldbwriter.CheckpointType) (*ldbwriter.PragmaWALResult, error) {
 			defer f.wg.Done()
 			f.cpCallCount.Add(1)
 			return nil, fmt.Errorf("fail")
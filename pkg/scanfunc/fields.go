@@ -0,0 +1,156 @@
+package scanfunc
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// columnField is a candidate for the field a column is scanned into
+type columnField struct {
+	field  reflect.StructField // Index and Offset are from the outermost struct
+	tagged bool
+	// whether the field is reached through an embedded pointer, so that
+	// its offset can't be used
+	indirect bool
+}
+
+// StructColumns returns the index of the field of the struct type which each
+// column is scanned into, keyed by the column's name, for use with
+// FieldByIndex. See NewUnmarshalTargetSlice for how columns are matched with
+// fields.
+func StructColumns(typ reflect.Type) map[string][]int {
+	fields := columnFields(typ)
+	res := make(map[string][]int, len(fields))
+	for name, f := range fields {
+		res[name] = f.field.Index
+	}
+	return res
+}
+
+// FieldByIndex returns the nested field of the struct value with the index,
+// allocating any nil embedded pointers which lead to it. The struct must be
+// addressable.
+func FieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// columnFields maps column names to the fields of the struct type that they
+// are scanned into. The fields of embedded structs are promoted as Go
+// promotes them, so a field of the outer struct hides a field of an
+// embedded struct with the same column, and columns which are ambiguous
+// between fields at the same depth aren't scanned, unless only one of those
+// fields is tagged.
+func columnFields(typ reflect.Type) map[string]columnField {
+	candidates := map[string][]columnField{}
+	var walk func(typ reflect.Type, parent columnField, visited map[reflect.Type]bool)
+	walk = func(typ reflect.Type, parent columnField, visited map[reflect.Type]bool) {
+		if visited[typ] {
+			return
+		}
+		visited[typ] = true
+		defer delete(visited, typ)
+
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			tag, tagged := field.Tag.Lookup(ctlTagString)
+			if tag == "-" {
+				continue
+			}
+			f := columnField{field: field, tagged: tagged, indirect: parent.indirect}
+			f.field.Index = append(append([]int{}, parent.field.Index...), i)
+			f.field.Offset += parent.field.Offset
+
+			if field.Anonymous && !tagged {
+				embedded := field.Type
+				if embedded.Kind() == reflect.Ptr {
+					embedded = embedded.Elem()
+					f.indirect = true
+				}
+				if f.indirect && field.PkgPath != "" {
+					// an unexported pointer can't be allocated
+					continue
+				}
+				// embedded structs are flattened, unless they're scanned
+				// as a whole
+				if embedded.Kind() == reflect.Struct && !reflect.PtrTo(embedded).Implements(scannerType) &&
+					unmarshalKindOf(embedded) == noUnmarshaler {
+					walk(embedded, f, visited)
+					continue
+				}
+			}
+			if field.PkgPath != "" && (!tagged || f.indirect) {
+				// unexported fields are only scanned if they're tagged, and
+				// can't be reached through a pointer
+				continue
+			}
+			name := strings.ToLower(tag)
+			if !tagged || name == "" {
+				name = snakeCase(field.Name)
+			}
+			candidates[name] = append(candidates[name], f)
+		}
+	}
+	walk(typ, columnField{}, map[reflect.Type]bool{})
+
+	res := make(map[string]columnField, len(candidates))
+	for name, fields := range candidates {
+		if f, ok := dominantField(fields); ok {
+			res[name] = f
+		}
+	}
+	return res
+}
+
+// dominantField picks the field a column is scanned into from the fields
+// with its name, as encoding/json does.
+func dominantField(fields []columnField) (columnField, bool) {
+	depth := len(fields[0].field.Index)
+	for _, f := range fields[1:] {
+		if len(f.field.Index) < depth {
+			depth = len(f.field.Index)
+		}
+	}
+	var res columnField
+	var found, tagged bool
+	ambiguous := false
+	for _, f := range fields {
+		if len(f.field.Index) != depth {
+			continue
+		}
+		switch {
+		case !found, f.tagged && !tagged:
+			res, found, tagged, ambiguous = f, true, f.tagged, false
+		case f.tagged == tagged:
+			ambiguous = true
+		}
+	}
+	return res, found && !ambiguous
+}
+
+// snakeCase infers the column name of a field from its name, e.g. UserID
+// becomes user_id and HTTPStatus becomes http_status.
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
@@ -19,6 +19,11 @@ type (
 	UnmarshalTypeMetaField struct {
 		Field   reflect.StructField
 		Factory unsafe.InterfaceFactory
+		// how the field is unmarshaled, if database/sql can't scan it
+		unmarshalKind unmarshalKind
+		// whether the field is reached through an embedded pointer, so
+		// Factory can't find it
+		indirect bool
 	}
 	UtmGetterFunc func(reflect.Type) (UnmarshalTypeMeta, error)
 )
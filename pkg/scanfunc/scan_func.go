@@ -110,19 +110,18 @@ func NewUnmarshalTargetSlice(target interface{}, cols []schema.DBColumnMeta) ([]
 			return UnmarshalTypeMeta{}, ErrUnmarshalUnsupportedType
 		}
 		// Reads the field type information to extract the tags, which are used
-		// to map the struct fields to column names. It then builds a map indexed
-		// by the column name which references the field metadata, tying them
-		// together for later use.
+		// to map the struct fields to column names. Fields without a tag are
+		// mapped to the snake_case of their names, fields tagged "-" are
+		// skipped, and the fields of embedded structs are promoted. It then
+		// builds a map indexed by the column name which references the field
+		// metadata, tying them together for later use.
 		fields := map[string]UnmarshalTypeMetaField{}
-		for i := 0; i < targetType.NumField(); i++ {
-			field := targetType.Field(i)
-			tagVal, found := field.Tag.Lookup(ctlTagString)
-			if found {
-				tagVal = strings.ToLower(tagVal)
-				fields[tagVal] = UnmarshalTypeMetaField{
-					Field:   field,
-					Factory: unsafe.NewInterfaceFactory(field.Type),
-				}
+		for name, f := range columnFields(targetType) {
+			fields[name] = UnmarshalTypeMetaField{
+				Field:         f.field,
+				Factory:       unsafe.NewInterfaceFactory(f.field.Type),
+				unmarshalKind: unmarshalKindOf(f.field.Type),
+				indirect:      f.indirect,
 			}
 		}
 		return UnmarshalTypeMeta{
@@ -150,12 +149,26 @@ func NewUnmarshalTargetSlice(target interface{}, cols []schema.DBColumnMeta) ([]
 	// to the value of a type which implements Scanner, but does nothing,
 	// or the "no-op" scanner.
 	//
+	// Fields which implement encoding.TextUnmarshaler or json.Unmarshaler,
+	// but not sql.Scanner, are wrapped in a Scanner which unmarshals them.
+	//
 	targets := make([]interface{}, len(cols))
 	for i, col := range cols {
 		colName := col.Name
 		var elem interface{} = &UtcNoopScanner
 		if fieldMeta, ok := meta.Fields[colName]; ok {
-			elem = fieldMeta.Factory.PtrToStructField(target, fieldMeta.Field)
+			if fieldMeta.indirect {
+				// the field is in a struct that an embedded pointer points to
+				elem = FieldByIndex(targetVal, fieldMeta.Field.Index).Addr().Interface()
+			} else {
+				elem = fieldMeta.Factory.PtrToStructField(target, fieldMeta.Field)
+			}
+			if fieldMeta.unmarshalKind != noUnmarshaler {
+				elem = &unmarshalScanner{
+					field: reflect.ValueOf(elem).Elem(),
+					kind:  fieldMeta.unmarshalKind,
+				}
+			}
 		}
 		targets[i] = elem
 	}
@@ -0,0 +1,120 @@
+package scanfunc
+
+import (
+	"database/sql"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+type unmarshalKind int
+
+const (
+	// the field is scanned by database/sql, which supports basic types
+	// and fields implementing sql.Scanner
+	noUnmarshaler unmarshalKind = iota
+	textUnmarshaler
+	jsonUnmarshaler
+	// the field is a map or slice, such as map[string]interface{}, which
+	// json columns are decoded into
+	jsonValue
+)
+
+var (
+	scannerType         = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// unmarshalKindOf returns how a field of type typ should be scanned.
+// sql.Scanner takes precedence, followed by encoding.TextUnmarshaler and
+// then json.Unmarshaler. Maps and slices other than []byte without any of
+// them are decoded from JSON. Pointer fields are checked by their element
+// type.
+func unmarshalKindOf(typ reflect.Type) unmarshalKind {
+	ptrType := reflect.PtrTo(typ)
+	if typ.Kind() == reflect.Ptr {
+		ptrType = typ
+	}
+	switch elem := ptrType.Elem(); {
+	case ptrType.Implements(scannerType):
+		return noUnmarshaler
+	case ptrType.Implements(textUnmarshalerType):
+		return textUnmarshaler
+	case ptrType.Implements(jsonUnmarshalerType):
+		return jsonUnmarshaler
+	case elem.Kind() == reflect.Map,
+		elem.Kind() == reflect.Slice && elem.Elem().Kind() != reflect.Uint8:
+		return jsonValue
+	}
+	return noUnmarshaler
+}
+
+// unmarshalScanner adapts a field implementing encoding.TextUnmarshaler or
+// json.Unmarshaler, or decoded from JSON, to sql.Scanner, so that it can be
+// passed to rows.Scan.
+type unmarshalScanner struct {
+	field reflect.Value // addressable
+	kind  unmarshalKind
+}
+
+// Scan unmarshals text and blob columns with the field's unmarshaler. Other
+// values are assigned directly if they have the field's type, and are
+// otherwise formatted as text or encoded as JSON first. NULL zeroes the
+// field.
+func (s *unmarshalScanner) Scan(src interface{}) error {
+	if src == nil {
+		s.field.Set(reflect.Zero(s.field.Type()))
+		return nil
+	}
+	ptr := s.field
+	if ptr.Kind() == reflect.Ptr {
+		if ptr.IsNil() {
+			ptr.Set(reflect.New(ptr.Type().Elem()))
+		}
+	} else {
+		ptr = ptr.Addr()
+	}
+	if sv := reflect.ValueOf(src); sv.Type().AssignableTo(ptr.Elem().Type()) {
+		ptr.Elem().Set(sv)
+		return nil
+	}
+
+	var b []byte
+	switch src := src.(type) {
+	case []byte:
+		b = src
+	case string:
+		b = []byte(src)
+	}
+
+	var err error
+	switch s.kind {
+	case textUnmarshaler:
+		if b == nil {
+			b = []byte(fmt.Sprint(src))
+		}
+		err = ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText(b)
+	case jsonUnmarshaler:
+		if b == nil {
+			if b, err = json.Marshal(src); err != nil {
+				break
+			}
+		}
+		err = ptr.Interface().(json.Unmarshaler).UnmarshalJSON(b)
+	case jsonValue:
+		if b == nil {
+			return fmt.Errorf("unmarshal %T into %s: not a JSON document", src, s.field.Type())
+		}
+		// decode into a zero value, rather than merging with the field's
+		v := reflect.New(ptr.Elem().Type())
+		if err = json.Unmarshal(b, v.Interface()); err == nil {
+			ptr.Elem().Set(v.Elem())
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("unmarshal %T into %s: %w", src, s.field.Type(), err)
+	}
+	return nil
+}
@@ -6,4 +6,8 @@ type DBColumnInfo struct {
 	ColumnName   string
 	DataType     string
 	IsPrimaryKey bool
+	// Default is the column's default value as text, or nil if it has
+	// none. String defaults are unquoted.
+	Default *string
+	NotNull bool
 }
@@ -18,6 +18,14 @@ type DMLStatement struct {
 	Sequence  DMLSequence
 	Timestamp time.Time
 	Statement string
+	// LedgerID identifies the upstream ledger the statement was read from
+	// when a reflector merges the ledgers of a sharded ctldb. Sequences are
+	// only comparable between statements from the same ledger. Zero is the
+	// primary ledger.
+	LedgerID int
+	// Version is how Statement is encoded, from the ledger row's version
+	// column. Zero, for ledgers without the column, is DMLVersionSQL.
+	Version int
 }
 
 func (seq DMLSequence) Int() int64 {
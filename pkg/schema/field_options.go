@@ -0,0 +1,63 @@
+package schema
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+)
+
+// FieldOptions are the optional constraints of a field.
+type FieldOptions struct {
+	// Default is the value of the field when a mutation doesn't supply it.
+	// Like mutation values, defaults of byte string fields are base64
+	// encoded. A nil Default means the field has no default.
+	Default interface{} `json:"default,omitempty"`
+	// NotNull rejects NULL values for the field.
+	NotNull bool `json:"notNull,omitempty"`
+}
+
+// HasDefault returns whether the field has a default value.
+func (o FieldOptions) HasDefault() bool {
+	return o.Default != nil
+}
+
+// CanHaveDefault returns if fields of this type may have a default value.
+// MySQL doesn't support literal defaults for TEXT and BLOB columns.
+func (ft FieldType) CanHaveDefault() bool {
+	return ft == FTString || ft == FTInteger || ft == FTDecimal || ft == FTByteString
+}
+
+// Validate returns an error if the options can't be applied to a field of
+// the given type.
+func (o FieldOptions) Validate(ft FieldType) error {
+	if !o.HasDefault() {
+		return nil
+	}
+	if !ft.CanHaveDefault() {
+		return fmt.Errorf("Fields of type '%s' cannot have a default", ft)
+	}
+	switch v := o.Default.(type) {
+	case string:
+		switch ft {
+		case FTString:
+			return nil
+		case FTByteString:
+			if _, err := base64.StdEncoding.DecodeString(v); err != nil {
+				return fmt.Errorf("Default of a '%s' field must be base64 encoded", ft)
+			}
+			return nil
+		}
+	case float64:
+		switch {
+		case ft == FTDecimal:
+			return nil
+		case ft == FTInteger && v == math.Trunc(v):
+			return nil
+		}
+	case int64:
+		if ft == FTInteger || ft == FTDecimal {
+			return nil
+		}
+	}
+	return fmt.Errorf("Invalid default %v for a field of type '%s'", o.Default, ft)
+}
@@ -0,0 +1,22 @@
+package schema
+
+import "strings"
+
+// FieldReference names the key field of another table which a field refers
+// to, like a foreign key. Fields of a table which reference the same table
+// together make a composite reference.
+type FieldReference struct {
+	Family string `json:"family"`
+	Table  string `json:"table"`
+	Field  string `json:"field"`
+}
+
+// FamilyTable returns the table which is referenced.
+func (r FieldReference) FamilyTable() FamilyTable {
+	return FamilyTable{Family: r.Family, Table: r.Table}
+}
+
+// String returns the fully qualified name of the referenced field.
+func (r FieldReference) String() string {
+	return strings.Join([]string{r.FamilyTable().String(), r.Field}, ".")
+}
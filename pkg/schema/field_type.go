@@ -9,6 +9,12 @@ func (ft FieldType) CanBeKey() bool {
 	return ft == FTString || ft == FTInteger || ft == FTByteString
 }
 
+// CanWidenTo returns if a column of this type can be changed to the other
+// type without truncating or reinterpreting existing values
+func (ft FieldType) CanWidenTo(other FieldType) bool {
+	return ft == other || fieldTypeWidenings[ft] == other
+}
+
 func (ft FieldType) String() string {
 	if s, ok := FieldTypeStringsByFieldType[ft]; ok {
 		return s
@@ -25,6 +31,9 @@ const (
 	FTText
 	FTBinary
 	FTByteString
+	FTTimestamp
+	FTBoolean
+	FTJSON
 )
 
 // Maps FieldTypes to their stringly typed version
@@ -35,6 +44,15 @@ var FieldTypeStringsByFieldType = map[FieldType]string{
 	FTText:       "text",
 	FTBinary:     "binary",
 	FTByteString: "bytestring",
+	FTTimestamp:  "timestamp",
+	FTBoolean:    "boolean",
+	FTJSON:       "json",
+}
+
+// Maps FieldTypes to the wider type their columns may be migrated to
+var fieldTypeWidenings = map[FieldType]FieldType{
+	FTString:     FTText,
+	FTByteString: FTBinary,
 }
 
 // Used for converting SQL-ized field types to FieldTypes
@@ -63,6 +81,15 @@ var _sqlTypesToFieldTypes = map[string]FieldType{
 
 	"varbinary": FTByteString,
 	"blob(255)": FTByteString,
+
+	"datetime": FTTimestamp,
+
+	// MySQL reports BOOLEAN columns as TINYINT(1)
+	"boolean": FTBoolean,
+	"tinyint": FTBoolean,
+
+	"json":      FTJSON,
+	"json text": FTJSON,
 }
 
 // Convert a known SQL type string to a FieldType
@@ -0,0 +1,68 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// TimestampLayout is how the values of timestamp fields are stored. They're
+// kept in UTC with microsecond precision, which is the most MySQL keeps.
+const TimestampLayout = "2006-01-02 15:04:05.999999"
+
+// NormalizeFieldValue validates a mutation's value for a field of type ft,
+// and converts it to the value that is stored. Timestamps are supplied as
+// RFC 3339 strings, and booleans as JSON booleans which are stored as 1 or 0.
+// JSON documents are supplied either as a string holding the document, which
+// must be well-formed, or as the document itself, and are stored as text.
+// Values of the other types are returned as is.
+func NormalizeFieldValue(ft FieldType, v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch ft {
+	case FTTimestamp:
+		var t time.Time
+		switch v := v.(type) {
+		case time.Time:
+			t = v
+		case string:
+			parsed, err := time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid timestamp %q, must be in RFC 3339 format", v)
+			}
+			t = parsed
+		default:
+			return nil, fmt.Errorf("Invalid timestamp %v, must be an RFC 3339 string", v)
+		}
+		return t.UTC().Format(TimestampLayout), nil
+	case FTBoolean:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("Invalid boolean %v", v)
+		}
+		if b {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case FTJSON:
+		switch v := v.(type) {
+		case string:
+			if !json.Valid([]byte(v)) {
+				return nil, fmt.Errorf("Invalid JSON %q", v)
+			}
+			return v, nil
+		case []byte:
+			if !json.Valid(v) {
+				return nil, fmt.Errorf("Invalid JSON %q", v)
+			}
+			return string(v), nil
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid JSON %v: %v", v, err)
+		}
+		return string(b), nil
+	}
+	return v, nil
+}
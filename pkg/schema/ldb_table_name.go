@@ -7,6 +7,11 @@ import (
 
 const (
 	ldbTableNameDelimiter = "___"
+
+	// LDBRebuildTablePrefix names the copy of a table that's built when
+	// its columns are changed in a way SQLite can't alter in place. The
+	// rows copied into it aren't changes to the table.
+	LDBRebuildTablePrefix = "_rebuild_"
 )
 
 // Converts a family/table name pair to a concatenated version that works
@@ -0,0 +1,114 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// The versions of ledger statements, which are recorded in the version
+// column of the ledger. Version 1 statements are SQL with their values
+// spliced in, while version 2 statements are a JSON encoded
+// ParameterizedDML.
+const (
+	DMLVersionSQL           = 1
+	DMLVersionParameterized = 2
+)
+
+// ParameterizedDML is a DML statement with ? placeholders for its values,
+// which are applied as arguments rather than being quoted into the SQL.
+type ParameterizedDML struct {
+	SQL  string        `json:"sql"`
+	Args []interface{} `json:"args"`
+}
+
+// dmlBinaryArg is how []byte arguments are encoded, since JSON would
+// otherwise decode them as strings.
+type dmlBinaryArg struct {
+	Binary string `json:"b"`
+}
+
+// Encode returns the statement as it's written to the ledger.
+func (p ParameterizedDML) Encode() (string, error) {
+	args := make([]interface{}, len(p.Args))
+	for i, arg := range p.Args {
+		switch arg := arg.(type) {
+		case []byte:
+			args[i] = dmlBinaryArg{Binary: base64.StdEncoding.EncodeToString(arg)}
+		case nil, string, bool,
+			int, int8, int16, int32, int64,
+			uint, uint8, uint16, uint32, uint64,
+			float32, float64:
+			args[i] = arg
+		default:
+			return "", fmt.Errorf("unsupported DML argument type %T", arg)
+		}
+	}
+	b, err := json.Marshal(ParameterizedDML{SQL: p.SQL, Args: args})
+	if err != nil {
+		return "", fmt.Errorf("encode parameterized DML: %v", err)
+	}
+	return string(b), nil
+}
+
+// DecodeParameterizedDML decodes a version 2 ledger statement written by
+// Encode.
+func DecodeParameterizedDML(statement string) (ParameterizedDML, error) {
+	var raw struct {
+		SQL  string            `json:"sql"`
+		Args []json.RawMessage `json:"args"`
+	}
+	if err := json.Unmarshal([]byte(statement), &raw); err != nil {
+		return ParameterizedDML{}, fmt.Errorf("decode parameterized DML: %v", err)
+	}
+	p := ParameterizedDML{SQL: raw.SQL, Args: make([]interface{}, len(raw.Args))}
+	for i, rawArg := range raw.Args {
+		arg, err := decodeDMLArg(rawArg)
+		if err != nil {
+			return ParameterizedDML{}, fmt.Errorf("decode parameterized DML argument %d: %v", i, err)
+		}
+		p.Args[i] = arg
+	}
+	return p, nil
+}
+
+// DML returns the statement as SQL and its arguments, decoding it according
+// to its version.
+func (s DMLStatement) DML() (ParameterizedDML, error) {
+	switch s.Version {
+	case 0, DMLVersionSQL:
+		return ParameterizedDML{SQL: s.Statement}, nil
+	case DMLVersionParameterized:
+		return DecodeParameterizedDML(s.Statement)
+	default:
+		return ParameterizedDML{}, fmt.Errorf("unknown ledger statement version %d", s.Version)
+	}
+}
+
+func decodeDMLArg(raw json.RawMessage) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	switch v := v.(type) {
+	case json.Number:
+		// integers are decoded as such so that they don't lose precision
+		if i, err := v.Int64(); err == nil {
+			return i, nil
+		}
+		return v.Float64()
+	case map[string]interface{}:
+		var bin dmlBinaryArg
+		if err := json.Unmarshal(raw, &bin); err != nil {
+			return nil, err
+		}
+		return base64.StdEncoding.DecodeString(bin.Binary)
+	case nil, string, bool:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("unsupported DML argument %s", raw)
+	}
+}
@@ -0,0 +1,65 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParameterizedDMLRoundTrip(t *testing.T) {
+	dml := ParameterizedDML{
+		SQL:  `REPLACE INTO family1___table1 ("a","b","c","d","e","f","g") VALUES(?,?,?,?,?,?,?)`,
+		Args: []interface{}{int64(1) << 62, 1.5, "it's a\x00b", []byte{0, 1, 2}, nil, true, 7},
+	}
+	encoded, err := dml.Encode()
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	got, err := DMLStatement{Statement: encoded, Version: DMLVersionParameterized}.DML()
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	want := ParameterizedDML{
+		SQL:  dml.SQL,
+		Args: []interface{}{int64(1) << 62, 1.5, "it's a\x00b", []byte{0, 1, 2}, nil, true, int64(7)},
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected %#v, got %#v", want, got)
+	}
+}
+
+func TestDMLStatementDML(t *testing.T) {
+	suite := []struct {
+		desc      string
+		statement DMLStatement
+		expectSQL string
+		expectErr bool
+	}{
+		{"Plain SQL", DMLStatement{Statement: "DELETE FROM family1___table1", Version: DMLVersionSQL}, "DELETE FROM family1___table1", false},
+		{"No version", DMLStatement{Statement: "DELETE FROM family1___table1"}, "DELETE FROM family1___table1", false},
+		{"Tx marker", DMLStatement{Statement: DMLTxBeginKey, Version: DMLVersionSQL}, DMLTxBeginKey, false},
+		{"Parameterized", DMLStatement{Statement: `{"sql":"DELETE FROM family1___table1","args":[]}`, Version: DMLVersionParameterized}, "DELETE FROM family1___table1", false},
+		{"Invalid JSON", DMLStatement{Statement: "{", Version: DMLVersionParameterized}, "", true},
+		{"Unsupported argument", DMLStatement{Statement: `{"sql":"","args":[[1]]}`, Version: DMLVersionParameterized}, "", true},
+		{"Unknown version", DMLStatement{Statement: "DELETE FROM family1___table1", Version: 3}, "", true},
+	}
+
+	for _, testCase := range suite {
+		t.Run(testCase.desc, func(t *testing.T) {
+			dml, err := testCase.statement.DML()
+			if want, got := testCase.expectSQL, dml.SQL; want != got {
+				t.Errorf("Expected SQL %q, got %q", want, got)
+			}
+			if want, got := testCase.expectErr, err != nil; want != got {
+				t.Errorf("Expected error %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestParameterizedDMLEncodeUnsupported(t *testing.T) {
+	dml := ParameterizedDML{SQL: "", Args: []interface{}{struct{}{}}}
+	if _, err := dml.Encode(); err == nil {
+		t.Errorf("Expected an error")
+	}
+}
@@ -5,4 +5,20 @@ type Table struct {
 	Name      string     `json:"name"`
 	Fields    [][]string `json:"fields"`
 	KeyFields []string   `json:"keyFields"`
+	// Template optionally names a TableTemplate of the family to apply
+	// when creating the table.
+	Template string `json:"template,omitempty"`
+	// FieldOptions optionally sets defaults and nullability of fields,
+	// keyed by field name.
+	FieldOptions map[string]FieldOptions `json:"fieldOptions,omitempty"`
+	// References holds the fields which reference the key fields of other
+	// tables, keyed by field name.
+	References map[string]FieldReference `json:"references,omitempty"`
+	// StrictReferences makes the executive reject mutations of the table
+	// whose References don't match an existing row.
+	StrictReferences bool `json:"strictReferences,omitempty"`
+	// UniqueConstraints optionally lists the sets of fields, besides the
+	// key fields, whose values must be unique among the table's rows. They
+	// can only be declared when the table is created.
+	UniqueConstraints [][]string `json:"uniqueConstraints,omitempty"`
 }
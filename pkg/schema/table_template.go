@@ -0,0 +1,107 @@
+package schema
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+)
+
+var templateNameRegexp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_\-]{0,190}$`)
+
+// TableTemplate describes a standard shape for tables in a family. Tables
+// created from a template have the template's KeyFields as the leading
+// columns of their primary key, and also include the template's Fields.
+// Both are specified as [name, type] tuples, like Table.Fields.
+type TableTemplate struct {
+	Family    string     `json:"family"`
+	Name      string     `json:"name"`
+	KeyFields [][]string `json:"keyFields"`
+	Fields    [][]string `json:"fields"`
+}
+
+// Validate checks that the template could be used to create a table.
+func (t TableTemplate) Validate() error {
+	if _, err := NewFamilyName(t.Family); err != nil {
+		return &errs.BadRequestError{Err: err.Error()}
+	}
+	if !templateNameRegexp.MatchString(t.Name) {
+		return errs.BadRequest("Invalid template name: '%s'", t.Name)
+	}
+	keyNames, keyTypes, err := UnzipFieldsParam(t.KeyFields)
+	if err != nil {
+		return err
+	}
+	fieldNames, _, err := UnzipFieldsParam(t.Fields)
+	if err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	for i, name := range append(keyNames, fieldNames...) {
+		if _, err := NewFieldName(name); err != nil {
+			return errs.BadRequest("Field name error for '%s': %s", name, err)
+		}
+		if seen[name] {
+			return errs.BadRequest("Field '%s' is defined more than once", name)
+		}
+		seen[name] = true
+		if i < len(keyTypes) && !keyTypes[i].CanBeKey() {
+			return errs.BadRequest("Field '%s' of type %s cannot be a key field", name, keyTypes[i])
+		}
+	}
+	return nil
+}
+
+// Apply merges the template with the fields and key fields of a table
+// definition. The template's key fields lead both the fields and key fields
+// of the result, and its other fields are appended if the definition doesn't
+// already include them. The definition may repeat template fields, but only
+// with the same type.
+func (t TableTemplate) Apply(fields [][]string, keyFields []string) ([][]string, []string, error) {
+	templateTypes := map[string]string{}
+	for _, f := range append(append([][]string{}, t.KeyFields...), t.Fields...) {
+		if len(f) == 2 {
+			templateTypes[f[0]] = f[1]
+		}
+	}
+
+	defined := map[string]bool{}
+	resFields := append([][]string{}, t.KeyFields...)
+	for _, f := range t.KeyFields {
+		defined[f[0]] = true
+	}
+	for idx, f := range fields {
+		if len(f) != 2 {
+			return nil, nil, &errs.BadRequestError{Err: fmt.Sprintf("Field #%d is malformed: expected 2 elements, got %d", idx, len(f))}
+		}
+		if typ, ok := templateTypes[f[0]]; ok {
+			if typ != f[1] {
+				return nil, nil, errs.BadRequest("Field '%s' must have type %s to match template '%s'", f[0], typ, t.Name)
+			}
+			if defined[f[0]] {
+				continue
+			}
+		}
+		defined[f[0]] = true
+		resFields = append(resFields, f)
+	}
+	for _, f := range t.Fields {
+		if !defined[f[0]] {
+			resFields = append(resFields, f)
+		}
+	}
+
+	var resKeyFields []string
+	keyDefined := map[string]bool{}
+	for _, f := range t.KeyFields {
+		keyDefined[f[0]] = true
+		resKeyFields = append(resKeyFields, f[0])
+	}
+	for _, kf := range keyFields {
+		if !keyDefined[kf] {
+			keyDefined[kf] = true
+			resKeyFields = append(resKeyFields, kf)
+		}
+	}
+	return resFields, resKeyFields, nil
+}
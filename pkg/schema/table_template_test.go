@@ -0,0 +1,73 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableTemplateValidate(t *testing.T) {
+	valid := TableTemplate{
+		Family:    "family1",
+		Name:      "tenant-scoped",
+		KeyFields: [][]string{{"tenant_id", "string"}},
+		Fields:    [][]string{{"updated_at", "integer"}},
+	}
+	require.NoError(t, valid.Validate())
+
+	for _, test := range []struct {
+		desc   string
+		modify func(tt *TableTemplate)
+	}{
+		{"bad family", func(tt *TableTemplate) { tt.Family = "a" }},
+		{"bad name", func(tt *TableTemplate) { tt.Name = "no spaces" }},
+		{"bad type", func(tt *TableTemplate) { tt.Fields = [][]string{{"updated_at", "uuid"}} }},
+		{"duplicate field", func(tt *TableTemplate) { tt.Fields = [][]string{{"tenant_id", "string"}} }},
+		{"unkeyable type", func(tt *TableTemplate) { tt.KeyFields = [][]string{{"tenant_id", "text"}} }},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			tt := valid
+			test.modify(&tt)
+			require.Error(t, tt.Validate())
+		})
+	}
+}
+
+func TestTableTemplateApply(t *testing.T) {
+	tt := TableTemplate{
+		Family:    "family1",
+		Name:      "tenant-scoped",
+		KeyFields: [][]string{{"tenant_id", "string"}},
+		Fields:    [][]string{{"updated_at", "integer"}},
+	}
+
+	fields, keyFields, err := tt.Apply(
+		[][]string{{"id", "string"}, {"value", "text"}},
+		[]string{"id"},
+	)
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"tenant_id", "string"},
+		{"id", "string"},
+		{"value", "text"},
+		{"updated_at", "integer"},
+	}, fields)
+	require.Equal(t, []string{"tenant_id", "id"}, keyFields)
+
+	// template fields may be repeated, and keep their position
+	fields, keyFields, err = tt.Apply(
+		[][]string{{"tenant_id", "string"}, {"id", "string"}, {"updated_at", "integer"}, {"value", "text"}},
+		[]string{"tenant_id", "id"},
+	)
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"tenant_id", "string"},
+		{"id", "string"},
+		{"updated_at", "integer"},
+		{"value", "text"},
+	}, fields)
+	require.Equal(t, []string{"tenant_id", "id"}, keyFields)
+
+	_, _, err = tt.Apply([][]string{{"updated_at", "string"}}, []string{"updated_at"})
+	require.Error(t, err)
+}
@@ -116,3 +116,42 @@ func TestValidateWriterName(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeFieldValue(t *testing.T) {
+	suite := []struct {
+		desc      string
+		ft        FieldType
+		input     interface{}
+		expectVal interface{}
+		expectErr bool
+	}{
+		{"Timestamp", FTTimestamp, "2020-01-02T03:04:05.123456789Z", "2020-01-02 03:04:05.123456", false},
+		{"Timestamp with offset", FTTimestamp, "2020-01-02T03:04:05-02:00", "2020-01-02 05:04:05", false},
+		{"Timestamp null", FTTimestamp, nil, nil, false},
+		{"Timestamp not RFC 3339", FTTimestamp, "2020-01-02", nil, true},
+		{"Timestamp number", FTTimestamp, float64(1577934245), nil, true},
+		{"Boolean true", FTBoolean, true, int64(1), false},
+		{"Boolean false", FTBoolean, false, int64(0), false},
+		{"Boolean number", FTBoolean, float64(1), nil, true},
+		{"JSON string", FTJSON, `{"a": [1, "b"]}`, `{"a": [1, "b"]}`, false},
+		{"JSON bytes", FTJSON, []byte(`"abc"`), `"abc"`, false},
+		{"JSON document", FTJSON, map[string]interface{}{"a": []interface{}{float64(1), "b"}}, `{"a":[1,"b"]}`, false},
+		{"JSON number", FTJSON, float64(1), `1`, false},
+		{"JSON malformed", FTJSON, `{"a":`, nil, true},
+		{"JSON bare string", FTJSON, "abc", nil, true},
+		{"Other types pass through", FTString, "abc", "abc", false},
+	}
+
+	for i, testCase := range suite {
+		testName := fmt.Sprintf("%d %s", i, testCase.desc)
+		t.Run(testName, func(t *testing.T) {
+			gotVal, gotErr := NormalizeFieldValue(testCase.ft, testCase.input)
+			if want, got := testCase.expectErr, gotErr != nil; want != got {
+				t.Errorf("Expected error %v, got %v", want, gotErr)
+			}
+			if want, got := testCase.expectVal, gotVal; want != got {
+				t.Errorf("Expected %v, got %v", want, got)
+			}
+		})
+	}
+}
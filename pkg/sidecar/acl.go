@@ -0,0 +1,129 @@
+package sidecar
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/segmentio/errors-go"
+	"github.com/segmentio/stats/v4"
+)
+
+const wildcard = "*"
+
+type (
+	// ACL maps application tokens to the families and tables that those
+	// applications may read. When a sidecar is configured with an ACL,
+	// every read must present a token in an "Authorization: Bearer" header.
+	ACL struct {
+		Applications []ApplicationACL `json:"applications"`
+	}
+	// ApplicationACL grants a single application access. Entries in Allow
+	// are either a family name, which grants access to all of the family's
+	// tables, or "family/table". "*" grants access to everything.
+	ApplicationACL struct {
+		Name  string   `json:"name"`
+		Token string   `json:"token"`
+		Allow []string `json:"allow"`
+	}
+)
+
+// LoadACL reads a JSON encoded ACL from the file at path.
+func LoadACL(path string) (*ACL, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "read acl")
+	}
+	var acl ACL
+	if err := json.Unmarshal(b, &acl); err != nil {
+		return nil, errors.Wrap(err, "decode acl")
+	}
+	if err := acl.Validate(); err != nil {
+		return nil, err
+	}
+	return &acl, nil
+}
+
+// Validate checks that every application has a name and a distinct token.
+func (acl *ACL) Validate() error {
+	tokens := map[string]string{}
+	for _, app := range acl.Applications {
+		if app.Name == "" {
+			return errors.New("acl application has no name")
+		}
+		if app.Token == "" {
+			return errors.Errorf("acl application %q has no token", app.Name)
+		}
+		if other, ok := tokens[app.Token]; ok {
+			return errors.Errorf("acl applications %q and %q share a token", other, app.Name)
+		}
+		tokens[app.Token] = app.Name
+	}
+	return nil
+}
+
+// application returns the application which the token belongs to, if any.
+// Tokens are compared in constant time.
+func (acl *ACL) application(token string) (ApplicationACL, bool) {
+	var res ApplicationACL
+	found := false
+	for _, app := range acl.Applications {
+		if subtle.ConstantTimeCompare([]byte(app.Token), []byte(token)) == 1 {
+			res, found = app, true
+		}
+	}
+	return res, found
+}
+
+func (app ApplicationACL) allows(family, table string) bool {
+	for _, allow := range app.Allow {
+		if allow == wildcard || allow == family || allow == family+"/"+table {
+			return true
+		}
+	}
+	return false
+}
+
+// authorize checks that the request may read from the table. It is a no-op
+// if the ACL is nil.
+func (acl *ACL) authorize(r *http.Request, family, table string) error {
+	if acl == nil {
+		return nil
+	}
+	app, err := acl.authenticate(r, family, table)
+	if err != nil {
+		return err
+	}
+	if !app.allows(family, table) {
+		stats.Incr("acl-denied", stats.T("application", app.Name), stats.T("family", family), stats.T("table", table), stats.T("reason", "forbidden"))
+		return errors.WithTypes(errors.Errorf("application %q may not read %s/%s", app.Name, family, table), "forbidden")
+	}
+	return nil
+}
+
+// authenticate returns the application whose token the request presents.
+// The family and table are only used to tag the denial metric.
+func (acl *ACL) authenticate(r *http.Request, family, table string) (ApplicationACL, error) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	app, ok := acl.application(token)
+	if token == "" || !ok {
+		stats.Incr("acl-denied", stats.T("application", "unknown"), stats.T("family", family), stats.T("table", table), stats.T("reason", "unauthenticated"))
+		return app, errors.WithTypes(errors.New("missing or unknown application token"), "unauthenticated")
+	}
+	return app, nil
+}
+
+// tableFilter authenticates the request and returns a func reporting
+// whether it may read a table. Every table is readable if the ACL is nil.
+func (acl *ACL) tableFilter(r *http.Request, family string) (func(table string) bool, error) {
+	if acl == nil {
+		return func(string) bool { return true }, nil
+	}
+	app, err := acl.authenticate(r, family, "")
+	if err != nil {
+		return nil, err
+	}
+	return func(table string) bool { return app.allows(family, table) }, nil
+}
@@ -0,0 +1,122 @@
+package sidecar
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/segmentio/errors-go"
+	"github.com/segmentio/objconv/msgpack"
+	"github.com/segmentio/stats/v4"
+)
+
+const (
+	contentTypeJSON    = "application/json"
+	contentTypeMsgpack = "application/msgpack"
+
+	// bodies smaller than this aren't worth the CPU it takes to gzip them
+	minGzipSize = 1024
+)
+
+var (
+	bufferPool = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+	gzipPool   = sync.Pool{New: func() interface{} { return gzip.NewWriter(nil) }}
+)
+
+// writeResponse encodes v in the format the request accepts, which is JSON
+// unless it prefers msgpack, and gzips it if the request accepts that and
+// it's large enough to be worth it.
+func writeResponse(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer bufferPool.Put(buf)
+
+	contentType := negotiateContentType(r.Header.Get("Accept"))
+	var err error
+	switch contentType {
+	case contentTypeMsgpack:
+		err = msgpack.NewEncoder(buf).Encode(v)
+	default:
+		err = json.NewEncoder(buf).Encode(v)
+	}
+	if err != nil {
+		return errors.Wrap(err, "encode response")
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", contentType)
+	h.Add("Vary", "Accept, Accept-Encoding")
+	encoding := "identity"
+	if buf.Len() >= minGzipSize && acceptsEncoding(r.Header.Get("Accept-Encoding"), "gzip") {
+		encoding = "gzip"
+	}
+	stats.Observe("response-size", buf.Len(), stats.T("content-type", contentType), stats.T("encoding", encoding))
+	if encoding != "gzip" {
+		_, err = w.Write(buf.Bytes())
+		return err
+	}
+
+	h.Set("Content-Encoding", "gzip")
+	gz := gzipPool.Get().(*gzip.Writer)
+	defer gzipPool.Put(gz)
+	gz.Reset(w)
+	if _, err := gz.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// negotiateContentType returns the response content type that an Accept
+// header most prefers.
+func negotiateContentType(accept string) string {
+	best, bestQ := contentTypeJSON, 0.0
+	for _, rng := range strings.Split(accept, ",") {
+		mediaType, q := parseAcceptRange(rng)
+		switch mediaType {
+		case contentTypeMsgpack, "application/x-msgpack":
+			mediaType = contentTypeMsgpack
+		case contentTypeJSON, "application/*", "*/*":
+			mediaType = contentTypeJSON
+		default:
+			continue
+		}
+		if q > bestQ {
+			best, bestQ = mediaType, q
+		}
+	}
+	return best
+}
+
+// acceptsEncoding returns whether an Accept-Encoding header allows the
+// content coding.
+func acceptsEncoding(acceptEncoding string, coding string) bool {
+	for _, rng := range strings.Split(acceptEncoding, ",") {
+		c, q := parseAcceptRange(rng)
+		if (c == coding || c == "*") && q > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAcceptRange splits an element of an Accept or Accept-Encoding header
+// into its lowercased value and its quality, which defaults to 1.
+func parseAcceptRange(rng string) (value string, q float64) {
+	parts := strings.Split(rng, ";")
+	value = strings.ToLower(strings.TrimSpace(parts[0]))
+	q = 1
+	for _, param := range parts[1:] {
+		k, v, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok || strings.TrimSpace(k) != "q" {
+			continue
+		}
+		if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			q = f
+		}
+	}
+	return value, q
+}
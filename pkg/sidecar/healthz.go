@@ -0,0 +1,69 @@
+package sidecar
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/segmentio/ctlstore"
+	"github.com/segmentio/stats/v4"
+)
+
+// healthzResponse is the body of /healthz responses.
+type healthzResponse struct {
+	Healthy bool `json:"healthy"`
+	Ping    bool `json:"ping"`
+	// Sequence is the last ledger sequence applied to the LDB
+	Sequence int64 `json:"sequence"`
+	// LedgerLatency and MaxLedgerLatency are in seconds. LedgerLatency is
+	// omitted if the LDB hasn't received any ledger updates yet.
+	LedgerLatency    *float64 `json:"ledgerLatency,omitempty"`
+	MaxLedgerLatency float64  `json:"maxLedgerLatency,omitempty"`
+	// Errors explains why the sidecar is unhealthy
+	Errors []string `json:"errors,omitempty"`
+}
+
+// healthz reports the state of the LDB, and responds with a 503 if it can't
+// be read or is staler than the configured MaxLedgerLatency, so that load
+// balancers stop routing reads to the sidecar.
+func (s *Sidecar) healthz(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	maxLedgerLatency := s.settings.Load().maxLedgerLatency
+	res := healthzResponse{
+		Ping:             s.reader.Ping(ctx),
+		MaxLedgerLatency: maxLedgerLatency.Seconds(),
+	}
+	if !res.Ping {
+		res.Errors = append(res.Errors, "ldb ping failed")
+	}
+
+	seq, err := s.reader.GetLastSequence(ctx)
+	if err != nil {
+		res.Errors = append(res.Errors, "get last sequence: "+err.Error())
+	}
+	res.Sequence = seq.Int()
+
+	latency, err := s.reader.GetLedgerLatency(ctx)
+	switch {
+	case err == ctlstore.ErrNoLedgerUpdates:
+		// staleness can't be known, which only matters if it's gated on
+		if maxLedgerLatency > 0 {
+			res.Errors = append(res.Errors, err.Error())
+		}
+	case err != nil:
+		res.Errors = append(res.Errors, "get ledger latency: "+err.Error())
+	default:
+		seconds := latency.Seconds()
+		res.LedgerLatency = &seconds
+		if maxLedgerLatency > 0 && latency > maxLedgerLatency {
+			res.Errors = append(res.Errors, "ledger latency exceeds the maximum")
+		}
+	}
+
+	res.Healthy = len(res.Errors) == 0
+	w.Header().Set("Content-Type", "application/json")
+	if !res.Healthy {
+		stats.Incr("healthz-unhealthy")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	return json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,63 @@
+package sidecar
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/segmentio/errors-go"
+
+	"github.com/segmentio/ctlstore"
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+// getTableSchema responds with the fields and key fields of a table, as
+// they are in the LDB, so that clients don't have to ask the executive.
+func (s *Sidecar) getTableSchema(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	family := vars["familyName"]
+	table := vars["tableName"]
+
+	if err := s.settings.Load().acl.authorize(r, family, table); err != nil {
+		return err
+	}
+	tbl, err := s.reader.GetTableSchema(r.Context(), family, table)
+	switch {
+	case errors.Cause(err) == ctlstore.ErrTableNotFound:
+		w.Header().Set("X-Ctlstore", "Not Found") // to differentiate between route based 404s
+		w.WriteHeader(http.StatusNotFound)
+		return nil
+	case err != nil:
+		return errors.Wrap(err, "get table schema")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(tbl)
+}
+
+// getFamilySchemas responds with the schemas of the family's tables. If the
+// sidecar has an ACL, tables the application may not read are left out.
+func (s *Sidecar) getFamilySchemas(w http.ResponseWriter, r *http.Request) error {
+	family := mux.Vars(r)["familyName"]
+
+	readable, err := s.settings.Load().acl.tableFilter(r, family)
+	if err != nil {
+		return err
+	}
+	tables, err := s.reader.GetFamilySchemas(r.Context(), family)
+	if err != nil {
+		return errors.Wrap(err, "get family schemas")
+	}
+	res := make([]schema.Table, 0, len(tables))
+	for _, tbl := range tables {
+		if readable(tbl.Name) {
+			res = append(res, tbl)
+		}
+	}
+	if len(res) == 0 {
+		w.Header().Set("X-Ctlstore", "Not Found")
+		w.WriteHeader(http.StatusNotFound)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(res)
+}
@@ -3,13 +3,19 @@ package sidecar
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"hash/fnv"
 	"log"
 	"net/http"
 	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/segmentio/ctlstore"
+	"github.com/segmentio/ctlstore/pkg/schema"
 	"github.com/segmentio/errors-go"
 	"github.com/segmentio/stats/v4"
 	"github.com/segmentio/stats/v4/httpstats"
@@ -17,21 +23,56 @@ import (
 
 type (
 	Sidecar struct {
-		bindAddr string
-		reader   Reader
-		maxRows  int
-		handler  http.Handler
+		bindAddr    string
+		application string
+		ui          bool
+		reader      Reader
+		handler     http.Handler
+		settings    atomic.Pointer[settings]
+		throttle    *throttle
+	}
+	// settings are the parts of the config which Reload can change while
+	// the sidecar is serving
+	settings struct {
+		maxRows            int
+		acl                *ACL
+		consistencyTimeout time.Duration
+		maxLedgerLatency   time.Duration
+		requestLimits      RequestLimits
 	}
 	Config struct {
 		BindAddr    string
 		Reader      Reader
 		MaxRows     int
 		Application string
+		// ConsistencyTimeout bounds how long a read waits for the LDB to
+		// catch up to a consistency token supplied by the client.
+		ConsistencyTimeout time.Duration
+		// ACL, if set, restricts reads to applications with a token that
+		// grants access to the requested table.
+		ACL *ACL
+		// UI serves pages under /ui/ for browsing the LDB.
+		UI bool
+		// MaxLedgerLatency, if set, makes /healthz fail once the LDB is
+		// staler than this.
+		MaxLedgerLatency time.Duration
+		// RequestLimits bound the reads of each application. Health checks
+		// aren't limited.
+		RequestLimits RequestLimits
 	}
 	Reader interface {
 		GetRowByKey(ctx context.Context, out interface{}, familyName string, tableName string, key ...interface{}) (found bool, err error)
 		GetRowsByKeyPrefix(ctx context.Context, familyName string, tableName string, key ...interface{}) (*ctlstore.Rows, error)
+		QueryRowsByKeyPrefix(ctx context.Context, familyName string, tableName string, query ctlstore.PrefixQuery, key ...interface{}) (*ctlstore.Rows, error)
 		GetLedgerLatency(ctx context.Context) (time.Duration, error)
+		GetLastSequence(ctx context.Context) (schema.DMLSequence, error)
+		GetLedgerSequences(ctx context.Context) (map[int]schema.DMLSequence, error)
+		Ping(ctx context.Context) bool
+		ConsistencyToken(ctx context.Context) (ctlstore.ConsistencyToken, error)
+		WaitForConsistency(ctx context.Context, token ctlstore.ConsistencyToken) error
+		GetTableStats(ctx context.Context) ([]ctlstore.TableStats, error)
+		GetTableSchema(ctx context.Context, familyName string, tableName string) (*schema.Table, error)
+		GetFamilySchemas(ctx context.Context, familyName string) ([]schema.Table, error)
 	}
 	ReadRequest struct {
 		Key []Key
@@ -53,6 +94,8 @@ func (k Key) ToValue() interface{} {
 	}
 }
 
+const defaultConsistencyTimeout = time.Second
+
 func keysToInterface(keys []Key) []interface{} {
 	var res []interface{}
 	for _, k := range keys {
@@ -62,11 +105,18 @@ func keysToInterface(keys []Key) []interface{} {
 }
 
 func New(config Config) (*Sidecar, error) {
+	settings, err := newSettings(config)
+	if err != nil {
+		return nil, err
+	}
 	sidecar := &Sidecar{
-		bindAddr: config.BindAddr,
-		reader:   config.Reader,
-		maxRows:  config.MaxRows,
+		bindAddr:    config.BindAddr,
+		application: config.Application,
+		ui:          config.UI,
+		reader:      config.Reader,
+		throttle:    newThrottle(),
 	}
+	sidecar.settings.Store(settings)
 	mux := mux.NewRouter()
 	handleErr := func(fn func(http.ResponseWriter, *http.Request) error) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
@@ -75,16 +125,36 @@ func New(config Config) (*Sidecar, error) {
 			case err == nil:
 			case errors.Is("limit-exceeded", err):
 				w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			case errors.Is("unauthenticated", err):
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+			case errors.Is("forbidden", err):
+				http.Error(w, err.Error(), http.StatusForbidden)
+			case errors.Is("invalid-consistency-token", err):
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			case errors.Is("invalid-query", err), errors.Cause(err) == ctlstore.ErrInvalidQuery:
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			case errors.Is("consistency-token-mismatch", err):
+				http.Error(w, err.Error(), http.StatusConflict)
+			case errors.Is("consistency-not-reached", err):
+				http.Error(w, err.Error(), http.StatusPreconditionFailed)
 			default:
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 			}
 		}
 	}
-	mux.HandleFunc("/get-row-by-key/{familyName}/{tableName}", handleErr(sidecar.getRowByKey)).Methods("POST")
-	mux.HandleFunc("/get-rows-by-key-prefix/{familyName}/{tableName}", handleErr(sidecar.getRowsByKeyPrefix)).Methods("POST")
+	mux.HandleFunc("/get-row-by-key/{familyName}/{tableName}", sidecar.limited(handleErr(sidecar.getRowByKey))).Methods("POST")
+	mux.HandleFunc("/get-rows-by-key-prefix/{familyName}/{tableName}", sidecar.limited(handleErr(sidecar.getRowsByKeyPrefix))).Methods("POST")
 	mux.HandleFunc("/get-ledger-latency", handleErr(sidecar.getLedgerLatency)).Methods("GET")
 	mux.HandleFunc("/healthcheck", handleErr(sidecar.healthcheck)).Methods("GET")
 	mux.HandleFunc("/ping", handleErr(sidecar.ping)).Methods("GET")
+	mux.HandleFunc("/healthz", handleErr(sidecar.healthz)).Methods("GET")
+	mux.HandleFunc("/schema/{familyName}", sidecar.limited(handleErr(sidecar.getFamilySchemas))).Methods("GET")
+	mux.HandleFunc("/schema/{familyName}/{tableName}", sidecar.limited(handleErr(sidecar.getTableSchema))).Methods("GET")
+	if config.UI {
+		mux.Handle("/ui", http.RedirectHandler("/ui/", http.StatusMovedPermanently)).Methods("GET")
+		mux.HandleFunc("/ui/", sidecar.limited(handleErr(sidecar.uiIndex))).Methods("GET")
+		mux.HandleFunc("/ui/{familyName}/{tableName}", sidecar.limited(handleErr(sidecar.uiTable))).Methods("GET")
+	}
 
 	application := orUnknown(config.Application)
 	stats.DefaultEngine.Tags = append(stats.DefaultEngine.Tags, stats.T("application", application))
@@ -95,6 +165,59 @@ func New(config Config) (*Sidecar, error) {
 	return sidecar, nil
 }
 
+// newSettings validates the reloadable parts of the config.
+func newSettings(config Config) (*settings, error) {
+	if config.MaxRows < 0 {
+		return nil, errors.Errorf("max rows can't be negative (%d)", config.MaxRows)
+	}
+	if config.ACL != nil {
+		if err := config.ACL.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if err := config.RequestLimits.Validate(); err != nil {
+		return nil, err
+	}
+	res := &settings{
+		maxRows:            config.MaxRows,
+		acl:                config.ACL,
+		consistencyTimeout: config.ConsistencyTimeout,
+		maxLedgerLatency:   config.MaxLedgerLatency,
+		requestLimits:      config.RequestLimits,
+	}
+	if res.consistencyTimeout <= 0 {
+		res.consistencyTimeout = defaultConsistencyTimeout
+	}
+	return res, nil
+}
+
+// Reload applies the MaxRows, ACL, ConsistencyTimeout, MaxLedgerLatency and
+// RequestLimits of the config to requests which begin after it returns, without dropping
+// any connections. Requests already being served finish with the settings
+// they began with. Nothing is changed if the config is invalid.
+//
+// The rest of the config can't be changed without a restart, so its
+// BindAddr, Application and UI must be unchanged, and its Reader is
+// ignored.
+func (s *Sidecar) Reload(config Config) error {
+	switch {
+	case config.BindAddr != s.bindAddr:
+		return errors.New("bind address can't be changed without a restart")
+	case config.Application != s.application:
+		return errors.New("application can't be changed without a restart")
+	case config.UI != s.ui:
+		return errors.New("ui can't be changed without a restart")
+	}
+	settings, err := newSettings(config)
+	if err != nil {
+		stats.Incr("config-reloads", stats.T("result", "invalid"))
+		return errors.Wrap(err, "invalid config")
+	}
+	s.settings.Store(settings)
+	stats.Incr("config-reloads", stats.T("result", "applied"))
+	return nil
+}
+
 func (s *Sidecar) Start(ctx context.Context) error {
 	srv := &http.Server{
 		Addr:         s.bindAddr,
@@ -142,6 +265,90 @@ func (s *Sidecar) ping(w http.ResponseWriter, r *http.Request) error {
 	return s.healthcheck(w, r)
 }
 
+// checkConsistency waits for the LDB to catch up to the token in the
+// request, if there is one, and then sets the token for the read that is
+// about to be served on the response.
+func (s *Sidecar) checkConsistency(w http.ResponseWriter, r *http.Request) (ctlstore.ConsistencyToken, error) {
+	if header := r.Header.Get(ctlstore.ConsistencyTokenHeader); header != "" {
+		token, err := ctlstore.ParseConsistencyToken(header)
+		if err != nil {
+			return ctlstore.ConsistencyToken{}, errors.WithTypes(err, "invalid-consistency-token")
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), s.settings.Load().consistencyTimeout)
+		defer cancel()
+		err = s.reader.WaitForConsistency(ctx, token)
+		switch {
+		case err == ctlstore.ErrConsistencyTokenMismatch:
+			return ctlstore.ConsistencyToken{}, errors.WithTypes(err, "consistency-token-mismatch")
+		case err == ctlstore.ErrConsistencyNotReached:
+			stats.Incr("consistency-not-reached")
+			return ctlstore.ConsistencyToken{}, errors.WithTypes(err, "consistency-not-reached")
+		case err != nil:
+			return ctlstore.ConsistencyToken{}, errors.Wrap(err, "wait for consistency")
+		}
+	}
+	token, err := s.reader.ConsistencyToken(r.Context())
+	if err != nil {
+		return token, errors.Wrap(err, "consistency token")
+	}
+	w.Header().Set(ctlstore.ConsistencyTokenHeader, token.String())
+	return token, nil
+}
+
+// checkETag sets the ETag of a read of the keys of a table at the LDB's
+// current sequences on the response. It returns true, having responded with
+// a 304, if the request already has that ETag. The ETag changes whenever the
+// LDB applies statements from any of its ledgers, since the LDB doesn't track
+// which tables they change, so a 304 is only sent while the LDB hasn't
+// advanced.
+func (s *Sidecar) checkETag(w http.ResponseWriter, r *http.Request, token ctlstore.ConsistencyToken, family, table string, keys []Key) (bool, error) {
+	seqs, err := s.reader.GetLedgerSequences(r.Context())
+	if err != nil {
+		return false, errors.Wrap(err, "ledger sequences")
+	}
+	ledgerIDs := make([]int, 0, len(seqs))
+	for id := range seqs {
+		ledgerIDs = append(ledgerIDs, id)
+	}
+	sort.Ints(ledgerIDs)
+	h := fnv.New64a()
+	b, err := json.Marshal(keys)
+	if err != nil {
+		return false, errors.Wrap(err, "encode keys")
+	}
+	// the query string can narrow the response, e.g. to some fields
+	for _, part := range [][]byte{[]byte(token.LDB), []byte(family), []byte(table), b, []byte(r.URL.RawQuery)} {
+		h.Write(part)
+		h.Write([]byte{0})
+	}
+	for _, id := range ledgerIDs {
+		fmt.Fprintf(h, "%d:%d", id, seqs[id].Int())
+		h.Write([]byte{0})
+	}
+	etag := fmt.Sprintf(`"%x"`, h.Sum64())
+	w.Header().Set("ETag", etag)
+	// clients may cache responses but must revalidate them
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if !etagMatches(r.Header.Get("If-None-Match"), etag) {
+		return false, nil
+	}
+	stats.Incr("etag-not-modified", stats.T("family", family), stats.T("table", table))
+	w.WriteHeader(http.StatusNotModified)
+	return true, nil
+}
+
+// etagMatches returns whether an If-None-Match header lists the ETag.
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *Sidecar) getRowsByKeyPrefix(w http.ResponseWriter, r *http.Request) error {
 	vars := mux.Vars(r)
 	family := vars["familyName"]
@@ -152,8 +359,28 @@ func (s *Sidecar) getRowsByKeyPrefix(w http.ResponseWriter, r *http.Request) err
 	if err != nil {
 		return errors.Wrap(err, "decode body")
 	}
+	query, err := parsePrefixQuery(r)
+	if err != nil {
+		return err
+	}
+	settings := s.settings.Load()
+	if err := settings.acl.authorize(r, family, table); err != nil {
+		return err
+	}
+	token, err := s.checkConsistency(w, r)
+	if err != nil {
+		return err
+	}
+	if notModified, err := s.checkETag(w, r, token, family, table, rr.Key); err != nil || notModified {
+		return err
+	}
 	res := make([]interface{}, 0)
-	rows, err := s.reader.GetRowsByKeyPrefix(r.Context(), family, table, keysToInterface(rr.Key)...)
+	var rows *ctlstore.Rows
+	if len(query.Fields) > 0 || len(query.Where) > 0 {
+		rows, err = s.reader.QueryRowsByKeyPrefix(r.Context(), family, table, query, keysToInterface(rr.Key)...)
+	} else {
+		rows, err = s.reader.GetRowsByKeyPrefix(r.Context(), family, table, keysToInterface(rr.Key)...)
+	}
 	if err != nil {
 		return err
 	}
@@ -165,8 +392,8 @@ func (s *Sidecar) getRowsByKeyPrefix(w http.ResponseWriter, r *http.Request) err
 			return errors.Wrap(err, "scan")
 		}
 		res = append(res, out)
-		if s.maxRows > 0 && len(res) > s.maxRows {
-			err = errors.Errorf("max row count (%d) exceeded", s.maxRows)
+		if settings.maxRows > 0 && len(res) > settings.maxRows {
+			err = errors.Errorf("max row count (%d) exceeded", settings.maxRows)
 			err = errors.WithTypes(err, "limit-exceeded")
 			return err
 		}
@@ -176,8 +403,42 @@ func (s *Sidecar) getRowsByKeyPrefix(w http.ResponseWriter, r *http.Request) err
 		return err
 	}
 	stats.Observe("get-rows-by-key-prefix-num-rows", len(res), stats.T("family", family), stats.T("table", table))
-	err = json.NewEncoder(w).Encode(res)
-	return err
+	return writeResponse(w, r, res)
+}
+
+// parsePrefixQuery reads the fields to project and the filters to apply to
+// a prefix read from the query string, e.g.
+//
+//	?fields=name,email&where=status=active&where=region=us
+//
+// Fields may be listed with commas or repeated. Filters compare non-key
+// fields with strings, which the LDB converts for numeric fields.
+func parsePrefixQuery(r *http.Request) (ctlstore.PrefixQuery, error) {
+	var query ctlstore.PrefixQuery
+	params := r.URL.Query()
+	for _, fields := range params["fields"] {
+		for _, field := range strings.Split(fields, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				query.Fields = append(query.Fields, field)
+			}
+		}
+	}
+	for _, where := range params["where"] {
+		field, value, ok := strings.Cut(where, "=")
+		if !ok || field == "" {
+			err := errors.Errorf("where must be of the form field=value: %q", where)
+			return query, errors.WithTypes(err, "invalid-query")
+		}
+		if query.Where == nil {
+			query.Where = map[string]interface{}{}
+		}
+		if _, ok := query.Where[field]; ok {
+			err := errors.Errorf("field %s is filtered more than once", field)
+			return query, errors.WithTypes(err, "invalid-query")
+		}
+		query.Where[field] = value
+	}
+	return query, nil
 }
 
 func (s *Sidecar) getRowByKey(w http.ResponseWriter, r *http.Request) error {
@@ -191,18 +452,28 @@ func (s *Sidecar) getRowByKey(w http.ResponseWriter, r *http.Request) error {
 		return errors.Wrap(err, "decode body")
 	}
 
+	if err := s.settings.Load().acl.authorize(r, family, table); err != nil {
+		return err
+	}
+	token, err := s.checkConsistency(w, r)
+	if err != nil {
+		return err
+	}
+	if notModified, err := s.checkETag(w, r, token, family, table, rr.Key); err != nil || notModified {
+		return err
+	}
 	out := make(map[string]interface{})
 	found, err := s.reader.GetRowByKey(r.Context(), out, family, table, keysToInterface(rr.Key)...)
 	if err != nil {
 		return err
 	}
 	if !found {
+		w.Header().Del("ETag")
 		w.Header().Set("X-Ctlstore", "Not Found") // to differentiate between route based 404s
 		w.WriteHeader(http.StatusNotFound)
 		return nil
 	}
-	err = json.NewEncoder(w).Encode(out)
-	return err
+	return writeResponse(w, r, out)
 }
 
 func orUnknown(value string) string {
@@ -2,14 +2,22 @@ package sidecar
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/segmentio/ctlstore"
+	"github.com/segmentio/ctlstore/pkg/ldb"
+	"github.com/segmentio/objconv/msgpack"
 	"github.com/stretchr/testify/require"
 )
 
@@ -105,6 +113,62 @@ func TestPing(t *testing.T) {
 	require.EqualValues(t, http.StatusOK, w.Code, w.Body.String())
 }
 
+func TestHealthz(t *testing.T) {
+	tu, teardown := ctlstore.NewLDBTestUtil(t)
+	defer teardown()
+
+	tu.CreateTable(ctlstore.LDBTestTableDef{
+		Family: "family",
+		Name:   "table",
+		Fields: [][]string{
+			{"key", "string"},
+		},
+		KeyFields: []string{"key"},
+		Rows: [][]interface{}{
+			{"key-1"},
+		},
+	})
+	_, err := tu.DB.Exec(
+		fmt.Sprintf("REPLACE INTO %s (id, seq) VALUES(?, ?)", ldb.LDBSeqTableName),
+		ldb.LDBSeqTableID, 42)
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		name             string
+		maxLedgerLatency time.Duration
+		code             int
+	}{
+		{"no maximum", 0, http.StatusOK},
+		{"within maximum", time.Hour, http.StatusOK},
+		{"stale", time.Nanosecond, http.StatusServiceUnavailable},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			sc, err := New(Config{
+				Reader:           ctlstore.NewLDBReaderFromDB(tu.DB),
+				MaxLedgerLatency: test.maxLedgerLatency,
+			})
+			require.NoError(t, err)
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+			sc.ServeHTTP(w, r)
+			require.EqualValues(t, test.code, w.Code, w.Body.String())
+
+			var res healthzResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			require.Equal(t, test.code == http.StatusOK, res.Healthy)
+			require.True(t, res.Ping)
+			require.EqualValues(t, 42, res.Sequence)
+			require.NotNil(t, res.LedgerLatency)
+			require.Equal(t, test.maxLedgerLatency.Seconds(), res.MaxLedgerLatency)
+			if res.Healthy {
+				require.Empty(t, res.Errors)
+			} else {
+				require.Equal(t, []string{"ledger latency exceeds the maximum"}, res.Errors)
+			}
+		})
+	}
+}
+
 func TestFetchCtlstoreData(t *testing.T) {
 	for _, test := range []struct {
 		name        string
@@ -264,3 +328,677 @@ func TestFetchCtlstoreData(t *testing.T) {
 	}
 
 }
+
+func TestPrefixQuery(t *testing.T) {
+	tu, teardown := ctlstore.NewLDBTestUtil(t)
+	defer teardown()
+	tu.CreateTable(ctlstore.LDBTestTableDef{
+		Family: "family",
+		Name:   "table",
+		Fields: [][]string{
+			{"key", "string"},
+			{"name", "string"},
+			{"status", "string"},
+			{"count", "integer"},
+		},
+		KeyFields: []string{"key"},
+		Rows: [][]interface{}{
+			{"key-1", "one", "active", 1},
+			{"key-2", "two", "inactive", 2},
+			{"key-3", "three", "active", 3},
+		},
+	})
+	sc, err := New(Config{Reader: ctlstore.NewLDBReaderFromDB(tu.DB)})
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		query  string
+		status int
+		result []interface{}
+	}{
+		{
+			query:  "fields=name",
+			status: http.StatusOK,
+			result: []interface{}{
+				map[string]interface{}{"name": "one"},
+				map[string]interface{}{"name": "two"},
+				map[string]interface{}{"name": "three"},
+			},
+		},
+		{
+			query:  "fields=key,name&where=status=active",
+			status: http.StatusOK,
+			result: []interface{}{
+				map[string]interface{}{"key": "key-1", "name": "one"},
+				map[string]interface{}{"key": "key-3", "name": "three"},
+			},
+		},
+		{
+			query:  "fields=key&fields=count&where=status=active&where=count=3",
+			status: http.StatusOK,
+			result: []interface{}{
+				map[string]interface{}{"key": "key-3", "count": float64(3)},
+			},
+		},
+		{
+			query:  "where=status=deleted",
+			status: http.StatusOK,
+			result: []interface{}{},
+		},
+		{query: "fields=bogus", status: http.StatusBadRequest},
+		{query: "where=key=key-1", status: http.StatusBadRequest},
+		{query: "where=status", status: http.StatusBadRequest},
+		{query: "where=status=active&where=status=inactive", status: http.StatusBadRequest},
+	} {
+		t.Run(test.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/get-rows-by-key-prefix/family/table?"+test.query,
+				strings.NewReader(`{"Key":[]}`))
+			sc.ServeHTTP(w, r)
+			require.Equal(t, test.status, w.Code, w.Body.String())
+			if test.result != nil {
+				var res []interface{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+				require.Equal(t, test.result, res)
+			}
+		})
+	}
+
+	// responses which are narrowed differently have different ETags
+	etag := func(query string) string {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/get-rows-by-key-prefix/family/table?"+query,
+			strings.NewReader(`{"Key":[]}`))
+		sc.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		return w.Header().Get("ETag")
+	}
+	require.NotEqual(t, etag("fields=name"), etag("fields=key"))
+	require.Equal(t, etag("fields=name"), etag("fields=name"))
+}
+
+func TestConsistencyToken(t *testing.T) {
+	tu, teardown := ctlstore.NewLDBTestUtil(t)
+	defer teardown()
+	tu.CreateTable(ctlstore.LDBTestTableDef{
+		Family: "family",
+		Name:   "table",
+		Fields: [][]string{
+			{"key", "string"},
+		},
+		KeyFields: []string{"key"},
+		Rows: [][]interface{}{
+			{"key-1"},
+		},
+	})
+	reader := ctlstore.NewLDBReaderFromDB(tu.DB)
+	sc, err := New(Config{
+		Reader:             reader,
+		ConsistencyTimeout: 50 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	current, err := reader.ConsistencyToken(context.Background())
+	require.NoError(t, err)
+
+	read := func(token string) *httptest.ResponseRecorder {
+		body, err := json.Marshal(ReadRequest{Key: []Key{{Value: "key-1"}}})
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/get-row-by-key/family/table", bytes.NewReader(body))
+		if token != "" {
+			r.Header.Set(ctlstore.ConsistencyTokenHeader, token)
+		}
+		sc.ServeHTTP(w, r)
+		return w
+	}
+
+	w := read("")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Equal(t, current.String(), w.Header().Get(ctlstore.ConsistencyTokenHeader))
+
+	w = read(current.String())
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	ahead := ctlstore.ConsistencyToken{LDB: current.LDB, Sequence: current.Sequence + 1}
+	w = read(ahead.String())
+	require.Equal(t, http.StatusPreconditionFailed, w.Code, w.Body.String())
+
+	w = read(ctlstore.ConsistencyToken{LDB: "other"}.String())
+	require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+
+	w = read("%%%")
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+}
+
+func TestETag(t *testing.T) {
+	tu, teardown := ctlstore.NewLDBTestUtil(t)
+	defer teardown()
+	tu.CreateTable(ctlstore.LDBTestTableDef{
+		Family: "family",
+		Name:   "table",
+		Fields: [][]string{
+			{"key", "string"},
+		},
+		KeyFields: []string{"key"},
+		Rows: [][]interface{}{
+			{"key-1"},
+			{"key-2"},
+		},
+	})
+	sc, err := New(Config{Reader: ctlstore.NewLDBReaderFromDB(tu.DB)})
+	require.NoError(t, err)
+
+	read := func(path string, key string, etag string) *httptest.ResponseRecorder {
+		body, err := json.Marshal(ReadRequest{Key: []Key{{Value: key}}})
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+		if etag != "" {
+			r.Header.Set("If-None-Match", etag)
+		}
+		sc.ServeHTTP(w, r)
+		return w
+	}
+
+	for _, path := range []string{"/get-row-by-key/family/table", "/get-rows-by-key-prefix/family/table"} {
+		t.Run(path, func(t *testing.T) {
+			_, err := tu.DB.Exec("DELETE FROM " + ldb.LDBSeqTableName)
+			require.NoError(t, err)
+			_, err = tu.DB.Exec("REPLACE INTO " + ldb.LDBSeqTableName + " (id, seq) VALUES (1, 42)")
+			require.NoError(t, err)
+
+			w := read(path, "key-1", "")
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			etag := w.Header().Get("ETag")
+			require.NotEmpty(t, etag)
+
+			w = read(path, "key-1", etag)
+			require.Equal(t, http.StatusNotModified, w.Code)
+			require.Empty(t, w.Body.String())
+			w = read(path, "key-1", `"other", W/`+etag)
+			require.Equal(t, http.StatusNotModified, w.Code)
+
+			// the ETag is specific to the key
+			w = read(path, "key-2", etag)
+			require.Equal(t, http.StatusOK, w.Code)
+			require.NotEqual(t, etag, w.Header().Get("ETag"))
+
+			// and changes once the LDB advances
+			_, err = tu.DB.Exec("REPLACE INTO " + ldb.LDBSeqTableName + " (id, seq) VALUES (1, 43)")
+			require.NoError(t, err)
+			w = read(path, "key-1", etag)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			require.NotEqual(t, etag, w.Header().Get("ETag"))
+
+			// including when only another ledger advances
+			etag = w.Header().Get("ETag")
+			_, err = tu.DB.Exec("REPLACE INTO "+ldb.LDBSeqTableName+" (id, seq) VALUES (?, 7)", ldb.SeqTableIDForLedger(1))
+			require.NoError(t, err)
+			w = read(path, "key-1", etag)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			require.NotEqual(t, etag, w.Header().Get("ETag"))
+			w = read(path, "key-1", w.Header().Get("ETag"))
+			require.Equal(t, http.StatusNotModified, w.Code)
+		})
+	}
+}
+
+func TestRequestLimits(t *testing.T) {
+	tu, teardown := ctlstore.NewLDBTestUtil(t)
+	defer teardown()
+	tu.CreateTable(ctlstore.LDBTestTableDef{
+		Family: "family",
+		Name:   "table",
+		Fields: [][]string{
+			{"key", "string"},
+		},
+		KeyFields: []string{"key"},
+		Rows: [][]interface{}{
+			{"key-1"},
+		},
+	})
+	config := Config{
+		Reader:        ctlstore.NewLDBReaderFromDB(tu.DB),
+		RequestLimits: RequestLimits{Rate: 0.5, Burst: 2, MaxConcurrent: 1, Applications: []string{"noisy", "quiet", "slow"}},
+	}
+	sc, err := New(config)
+	require.NoError(t, err)
+	now := time.Now()
+	sc.throttle.now = func() time.Time { return now }
+
+	read := func(app string) *httptest.ResponseRecorder {
+		body, err := json.Marshal(ReadRequest{Key: []Key{{Value: "key-1"}}})
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/get-row-by-key/family/table", bytes.NewReader(body))
+		if app != "" {
+			r.Header.Set(ApplicationHeader, app)
+		}
+		sc.ServeHTTP(w, r)
+		return w
+	}
+
+	// each application gets its own burst
+	for i := 0; i < 2; i++ {
+		require.Equal(t, http.StatusOK, read("noisy").Code)
+	}
+	w := read("noisy")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, "2", w.Header().Get("Retry-After"))
+	require.Equal(t, http.StatusOK, read("quiet").Code)
+
+	// applications which aren't configured share the unknown application's
+	// limits, whatever name they send
+	require.Equal(t, http.StatusOK, read("").Code)
+	require.Equal(t, http.StatusOK, read("rotated-1").Code)
+	require.Equal(t, http.StatusTooManyRequests, read("rotated-2").Code)
+	require.Len(t, sc.throttle.apps, 3, "noisy, quiet and unknown")
+
+	// health checks aren't limited
+	w = httptest.NewRecorder()
+	sc.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthcheck", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	now = now.Add(time.Second)
+	w = read("noisy")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, "1", w.Header().Get("Retry-After"))
+	now = now.Add(time.Second)
+	require.Equal(t, http.StatusOK, read("noisy").Code)
+
+	// an application with a request in flight has to wait for it
+	release, _, _ := sc.throttle.acquire("slow", config.RequestLimits)
+	require.NotNil(t, release)
+	w = read("slow")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, "1", w.Header().Get("Retry-After"))
+	release()
+	require.Equal(t, http.StatusOK, read("slow").Code)
+
+	// limits can be lifted without a restart
+	config.RequestLimits = RequestLimits{}
+	require.NoError(t, sc.Reload(config))
+	require.Equal(t, http.StatusOK, read("noisy").Code)
+
+	config.RequestLimits = RequestLimits{Rate: -1}
+	require.Error(t, sc.Reload(config))
+}
+
+func TestACL(t *testing.T) {
+	tu, teardown := ctlstore.NewLDBTestUtil(t)
+	defer teardown()
+	for _, table := range []string{"table1", "table2"} {
+		tu.CreateTable(ctlstore.LDBTestTableDef{
+			Family:    "family",
+			Name:      table,
+			Fields:    [][]string{{"key", "string"}},
+			KeyFields: []string{"key"},
+			Rows:      [][]interface{}{{"key-1"}},
+		})
+	}
+	acl := &ACL{Applications: []ApplicationACL{
+		{Name: "app1", Token: "token1", Allow: []string{"family"}},
+		{Name: "app2", Token: "token2", Allow: []string{"family/table1"}},
+		{Name: "admin", Token: "token3", Allow: []string{"*"}},
+	}}
+	require.NoError(t, acl.Validate())
+	sc, err := New(Config{
+		Reader: ctlstore.NewLDBReaderFromDB(tu.DB),
+		ACL:    acl,
+	})
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		token  string
+		table  string
+		status int
+	}{
+		{"", "table1", http.StatusUnauthorized},
+		{"bogus", "table1", http.StatusUnauthorized},
+		{"token1", "table1", http.StatusOK},
+		{"token1", "table2", http.StatusOK},
+		{"token2", "table1", http.StatusOK},
+		{"token2", "table2", http.StatusForbidden},
+		{"token3", "table2", http.StatusOK},
+	} {
+		t.Run(test.token+"/"+test.table, func(t *testing.T) {
+			body, err := json.Marshal(ReadRequest{Key: []Key{{Value: "key-1"}}})
+			require.NoError(t, err)
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/get-rows-by-key-prefix/family/"+test.table, bytes.NewReader(body))
+			if test.token != "" {
+				r.Header.Set("Authorization", "Bearer "+test.token)
+			}
+			sc.ServeHTTP(w, r)
+			require.Equal(t, test.status, w.Code, w.Body.String())
+		})
+	}
+
+	invalid := &ACL{Applications: []ApplicationACL{
+		{Name: "app1", Token: "token1"},
+		{Name: "app2", Token: "token1"},
+	}}
+	require.Error(t, invalid.Validate())
+}
+
+func TestReload(t *testing.T) {
+	tu, teardown := ctlstore.NewLDBTestUtil(t)
+	defer teardown()
+	tu.CreateTable(ctlstore.LDBTestTableDef{
+		Family:    "family",
+		Name:      "table",
+		Fields:    [][]string{{"key", "string"}},
+		KeyFields: []string{"key"},
+		Rows:      [][]interface{}{{"key-1"}, {"key-2"}},
+	})
+	reader := ctlstore.NewLDBReaderFromDB(tu.DB)
+	config := Config{BindAddr: "localhost:1331", Reader: reader, MaxRows: 1}
+	sc, err := New(config)
+	require.NoError(t, err)
+
+	read := func(token string) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/get-rows-by-key-prefix/family/table", strings.NewReader(`{"Key":[]}`))
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		sc.ServeHTTP(w, r)
+		return w.Code
+	}
+	require.Equal(t, http.StatusRequestedRangeNotSatisfiable, read(""))
+
+	config.MaxRows = 0
+	config.ACL = &ACL{Applications: []ApplicationACL{{Name: "app1", Token: "token1", Allow: []string{"family"}}}}
+	require.NoError(t, sc.Reload(config))
+	require.Equal(t, http.StatusOK, read("token1"))
+	require.Equal(t, http.StatusUnauthorized, read(""))
+
+	// invalid configs are rejected without changing anything
+	for _, invalid := range []Config{
+		{BindAddr: "localhost:1332", Reader: reader},
+		{BindAddr: config.BindAddr, Application: "app", Reader: reader},
+		{BindAddr: config.BindAddr, MaxRows: -1},
+		{BindAddr: config.BindAddr, ACL: &ACL{Applications: []ApplicationACL{{Name: "app1"}}}},
+	} {
+		require.Error(t, sc.Reload(invalid))
+	}
+	require.Equal(t, http.StatusOK, read("token1"))
+	require.Equal(t, http.StatusUnauthorized, read(""))
+}
+
+func TestSchema(t *testing.T) {
+	tu, teardown := ctlstore.NewLDBTestUtil(t)
+	defer teardown()
+	for _, table := range []string{"table1", "table2"} {
+		tu.CreateTable(ctlstore.LDBTestTableDef{
+			Family:    "family",
+			Name:      table,
+			Fields:    [][]string{{"key", "string"}, {"val", "integer"}},
+			KeyFields: []string{"key"},
+		})
+	}
+	acl := &ACL{Applications: []ApplicationACL{
+		{Name: "app1", Token: "token1", Allow: []string{"family/table1"}},
+		{Name: "app2", Token: "token2", Allow: []string{"other"}},
+	}}
+
+	for _, test := range []struct {
+		name   string
+		acl    *ACL
+		token  string
+		path   string
+		status int
+		body   string
+	}{
+		{
+			name:   "table",
+			path:   "/schema/family/table2",
+			status: http.StatusOK,
+			body:   `{"family":"family","name":"table2","fields":[["key","string"],["val","integer"]],"keyFields":["key"]}`,
+		},
+		{
+			name:   "missing table",
+			path:   "/schema/family/table3",
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "family",
+			path:   "/schema/family",
+			status: http.StatusOK,
+			body: `[{"family":"family","name":"table1","fields":[["key","string"],["val","integer"]],"keyFields":["key"]},` +
+				`{"family":"family","name":"table2","fields":[["key","string"],["val","integer"]],"keyFields":["key"]}]`,
+		},
+		{
+			name:   "missing family",
+			path:   "/schema/other",
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "acl filters family",
+			acl:    acl,
+			token:  "token1",
+			path:   "/schema/family",
+			status: http.StatusOK,
+			body:   `[{"family":"family","name":"table1","fields":[["key","string"],["val","integer"]],"keyFields":["key"]}]`,
+		},
+		{
+			name:   "acl forbids table",
+			acl:    acl,
+			token:  "token1",
+			path:   "/schema/family/table2",
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "acl forbids family",
+			acl:    acl,
+			token:  "token2",
+			path:   "/schema/family",
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "acl requires token",
+			acl:    acl,
+			path:   "/schema/family",
+			status: http.StatusUnauthorized,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			sc, err := New(Config{Reader: ctlstore.NewLDBReaderFromDB(tu.DB), ACL: test.acl})
+			require.NoError(t, err)
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, test.path, nil)
+			if test.token != "" {
+				r.Header.Set("Authorization", "Bearer "+test.token)
+			}
+			sc.ServeHTTP(w, r)
+			require.Equal(t, test.status, w.Code, w.Body.String())
+			if test.body != "" {
+				require.JSONEq(t, test.body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestUI(t *testing.T) {
+	tu, teardown := ctlstore.NewLDBTestUtil(t)
+	defer teardown()
+	tu.CreateTable(ctlstore.LDBTestTableDef{
+		Family:    "family",
+		Name:      "table",
+		Fields:    [][]string{{"key", "string"}},
+		KeyFields: []string{"key"},
+		Rows:      [][]interface{}{{"key-1"}, {"key-2"}},
+	})
+
+	sc, err := New(Config{Reader: ctlstore.NewLDBReaderFromDB(tu.DB)})
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	sc.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ui/", nil))
+	require.Equal(t, http.StatusNotFound, w.Code, "the UI should be disabled by default")
+
+	sc, err = New(Config{Reader: ctlstore.NewLDBReaderFromDB(tu.DB), UI: true})
+	require.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	sc.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ui/", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Contains(t, w.Body.String(), `<a href="/ui/family/table">table</a></td><td class="num">2</td>`)
+
+	w = httptest.NewRecorder()
+	sc.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ui/family/table", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Contains(t, w.Body.String(), `fetch("/get-rows-by-key-prefix/family/table"`)
+
+	t.Run("acl", func(t *testing.T) {
+		tu.CreateTable(ctlstore.LDBTestTableDef{
+			Family:    "family",
+			Name:      "secret",
+			Fields:    [][]string{{"key", "string"}},
+			KeyFields: []string{"key"},
+		})
+		acl := &ACL{Applications: []ApplicationACL{
+			{Name: "app1", Token: "token1", Allow: []string{"family/table"}},
+		}}
+		sc, err := New(Config{Reader: ctlstore.NewLDBReaderFromDB(tu.DB), UI: true, ACL: acl})
+		require.NoError(t, err)
+		get := func(path, token string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, path, nil)
+			if token != "" {
+				r.Header.Set("Authorization", "Bearer "+token)
+			}
+			sc.ServeHTTP(w, r)
+			return w
+		}
+
+		w := get("/ui/", "")
+		require.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+
+		// the table the application may not read is left out
+		w = get("/ui/", "token1")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.Contains(t, w.Body.String(), `<a href="/ui/family/table">table</a>`)
+		require.NotContains(t, w.Body.String(), "secret")
+
+		require.Equal(t, http.StatusOK, get("/ui/family/table", "token1").Code)
+		require.Equal(t, http.StatusForbidden, get("/ui/family/secret", "token1").Code)
+		require.Equal(t, http.StatusUnauthorized, get("/ui/family/table", "").Code)
+	})
+}
+
+func newEncodingTestSidecar(tb testing.TB, rows int) *Sidecar {
+	tu, teardown := ctlstore.NewLDBTestUtil(tb)
+	tb.Cleanup(teardown)
+	def := ctlstore.LDBTestTableDef{
+		Family: "family",
+		Name:   "table",
+		Fields: [][]string{
+			{"prefix", "string"},
+			{"key", "integer"},
+			{"value", "string"},
+		},
+		KeyFields: []string{"prefix", "key"},
+	}
+	for i := 0; i < rows; i++ {
+		def.Rows = append(def.Rows, []interface{}{"prefix", i, fmt.Sprintf("value-%d", i)})
+	}
+	tu.CreateTable(def)
+	sc, err := New(Config{Reader: ctlstore.NewLDBReaderFromDB(tu.DB)})
+	require.NoError(tb, err)
+	return sc
+}
+
+func newPrefixReadRequest(accept, acceptEncoding string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/get-rows-by-key-prefix/family/table",
+		strings.NewReader(`{"Key":[{"Value":"prefix"}]}`))
+	if accept != "" {
+		r.Header.Set("Accept", accept)
+	}
+	if acceptEncoding != "" {
+		r.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	return r
+}
+
+func TestResponseEncoding(t *testing.T) {
+	sc := newEncodingTestSidecar(t, 100)
+
+	for _, test := range []struct {
+		desc            string
+		accept          string
+		acceptEncoding  string
+		contentType     string
+		contentEncoding string
+	}{
+		{desc: "default", contentType: "application/json"},
+		{desc: "gzip", acceptEncoding: "gzip, deflate", contentType: "application/json", contentEncoding: "gzip"},
+		{desc: "gzip refused", acceptEncoding: "gzip;q=0", contentType: "application/json"},
+		{desc: "msgpack", accept: "application/msgpack", contentType: "application/msgpack"},
+		{desc: "msgpack preferred", accept: "application/json;q=0.5, application/x-msgpack", contentType: "application/msgpack"},
+		{desc: "json preferred", accept: "application/json, application/msgpack;q=0.9", contentType: "application/json"},
+		{desc: "msgpack gzip", accept: "application/msgpack", acceptEncoding: "gzip", contentType: "application/msgpack", contentEncoding: "gzip"},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			sc.ServeHTTP(w, newPrefixReadRequest(test.accept, test.acceptEncoding))
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			require.Equal(t, test.contentType, w.Header().Get("Content-Type"))
+			require.Equal(t, test.contentEncoding, w.Header().Get("Content-Encoding"))
+
+			var body io.Reader = w.Body
+			if test.contentEncoding == "gzip" {
+				gz, err := gzip.NewReader(body)
+				require.NoError(t, err)
+				body = gz
+			}
+			var res []map[string]interface{}
+			if test.contentType == "application/msgpack" {
+				require.NoError(t, msgpack.NewDecoder(body).Decode(&res))
+			} else {
+				require.NoError(t, json.NewDecoder(body).Decode(&res))
+			}
+			require.Len(t, res, 100)
+			require.Equal(t, "value-42", res[42]["value"])
+		})
+	}
+
+	// small responses aren't gzipped
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/get-row-by-key/family/table",
+		strings.NewReader(`{"Key":[{"Value":"prefix"},{"Value":1}]}`))
+	r.Header.Set("Accept-Encoding", "gzip")
+	sc.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Empty(t, w.Header().Get("Content-Encoding"))
+	require.JSONEq(t, `{"prefix":"prefix","key":1,"value":"value-1"}`, w.Body.String())
+}
+
+// BenchmarkResponseEncoding compares the size of a large prefix read, and
+// the time it takes to serve it, in each of the encodings.
+func BenchmarkResponseEncoding(b *testing.B) {
+	sc := newEncodingTestSidecar(b, 1000)
+	for _, bench := range []struct {
+		name           string
+		accept         string
+		acceptEncoding string
+	}{
+		{"json", "", ""},
+		{"json-gzip", "", "gzip"},
+		{"msgpack", "application/msgpack", ""},
+		{"msgpack-gzip", "application/msgpack", "gzip"},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			var size int
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				w := httptest.NewRecorder()
+				sc.ServeHTTP(w, newPrefixReadRequest(bench.accept, bench.acceptEncoding))
+				if w.Code != http.StatusOK {
+					b.Fatalf("unexpected status %d: %s", w.Code, w.Body.String())
+				}
+				size = w.Body.Len()
+			}
+			b.ReportMetric(float64(size), "bytes/response")
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package sidecar
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/segmentio/errors-go"
+	"github.com/segmentio/stats/v4"
+)
+
+// ApplicationHeader names the application making a request, whose
+// RequestLimits it counts against. Requests without it, or from an
+// application which isn't configured, share the limits of the "unknown"
+// application.
+const ApplicationHeader = "X-Ctlstore-Application"
+
+type (
+	// RequestLimits bound the reads that each application may make, so that
+	// a misbehaving application can't starve the others on the same host.
+	// Each configured application is limited separately. Requests over the
+	// limits are rejected with a 429 and a Retry-After header.
+	RequestLimits struct {
+		// Rate is the requests per second each application may make. Zero
+		// doesn't limit the rate.
+		Rate float64
+		// Burst is the most requests an application may make at once while
+		// within its rate. Defaults to the rate, rounded up.
+		Burst int
+		// MaxConcurrent is the most requests each application may have in
+		// flight. Zero doesn't limit them.
+		MaxConcurrent int
+		// Applications are the names of the applications which are limited,
+		// and counted, separately. The header is supplied by clients, so the
+		// others share the "unknown" application's limits, rather than
+		// getting their own by sending a new name.
+		Applications []string
+	}
+	// throttle tracks the requests of each application
+	throttle struct {
+		mu   sync.Mutex
+		apps map[string]*appRequests
+		now  func() time.Time
+	}
+	appRequests struct {
+		tokens   float64 // the requests which may be made before being limited
+		last     time.Time
+		inFlight int
+	}
+)
+
+// Validate checks that the limits aren't negative.
+func (l RequestLimits) Validate() error {
+	if l.Rate < 0 || l.Burst < 0 || l.MaxConcurrent < 0 {
+		return errors.New("request limits can't be negative")
+	}
+	return nil
+}
+
+// enabled reports whether the limits limit anything.
+func (l RequestLimits) enabled() bool {
+	return l.Rate > 0 || l.MaxConcurrent > 0
+}
+
+// application returns the name which a request's application is limited
+// and counted as.
+func (l RequestLimits) application(header string) string {
+	for _, app := range l.Applications {
+		if app == header {
+			return app
+		}
+	}
+	return orUnknown("")
+}
+
+func (l RequestLimits) burst() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return math.Ceil(l.Rate)
+}
+
+func newThrottle() *throttle {
+	return &throttle{apps: map[string]*appRequests{}, now: time.Now}
+}
+
+// acquire admits a request of the application if it's within the limits,
+// returning a func to call once it's been served. Otherwise, it returns
+// why the request was refused and how long to wait before retrying.
+func (t *throttle) acquire(app string, limits RequestLimits) (release func(), reason string, retryAfter time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	reqs, ok := t.apps[app]
+	if !ok {
+		reqs = &appRequests{tokens: limits.burst(), last: now}
+		t.apps[app] = reqs
+	}
+	if limits.Rate > 0 {
+		reqs.tokens = math.Min(limits.burst(), reqs.tokens+now.Sub(reqs.last).Seconds()*limits.Rate)
+	}
+	reqs.last = now
+
+	if limits.MaxConcurrent > 0 && reqs.inFlight >= limits.MaxConcurrent {
+		return nil, "concurrency", time.Second
+	}
+	if limits.Rate > 0 {
+		if reqs.tokens < 1 {
+			wait := time.Duration((1 - reqs.tokens) / limits.Rate * float64(time.Second))
+			return nil, "rate", wait
+		}
+		reqs.tokens--
+	}
+	reqs.inFlight++
+	stats.Set("requests-in-flight", reqs.inFlight, stats.T("application", app))
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		reqs.inFlight--
+		stats.Set("requests-in-flight", reqs.inFlight, stats.T("application", app))
+	}, "", 0
+}
+
+// limited applies the request limits of the sidecar's settings to the
+// handler.
+func (s *Sidecar) limited(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limits := s.settings.Load().requestLimits
+		app := limits.application(r.Header.Get(ApplicationHeader))
+		stats.Incr("requests-by-application", stats.T("application", app))
+		if !limits.enabled() {
+			h(w, r)
+			return
+		}
+		release, reason, retryAfter := s.throttle.acquire(app, limits)
+		if release == nil {
+			stats.Incr("requests-throttled", stats.T("application", app), stats.T("reason", reason))
+			w.Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(retryAfter.Seconds())), 10))
+			http.Error(w, "too many requests from application "+app, http.StatusTooManyRequests)
+			return
+		}
+		defer release()
+		h(w, r)
+	}
+}
@@ -0,0 +1,146 @@
+package sidecar
+
+import (
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/segmentio/ctlstore"
+	"github.com/segmentio/errors-go"
+)
+
+// The UI is a couple of server rendered pages for looking at the LDB during
+// incidents without needing the sqlite3 CLI. Lookups are made from the page
+// by calling the read endpoints, so they're subject to the same ACL and row
+// limits as any other read. The pages themselves are subject to the ACL
+// too, which leaves the tables the application may not read out of the
+// index.
+var uiTemplates = template.Must(template.New("ui").Parse(`
+{{define "header"}}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>ctlstore sidecar</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+td.num { text-align: right; }
+pre { background: #f4f4f4; padding: 1em; }
+</style>
+</head>
+<body>
+{{end}}
+
+{{define "footer"}}</body>
+</html>
+{{end}}
+
+{{define "index"}}{{template "header"}}
+<h1>ctlstore sidecar</h1>
+<p>Ledger latency: {{if .LatencyErr}}{{.LatencyErr}}{{else}}{{.Latency}}{{end}}</p>
+<table>
+<tr><th>Family</th><th>Table</th><th>Rows</th></tr>
+{{range .Tables}}<tr><td>{{.Family}}</td><td><a href="/ui/{{.Family}}/{{.Table}}">{{.Table}}</a></td><td class="num">{{.Rows}}</td></tr>
+{{else}}<tr><td colspan="3">The LDB has no tables</td></tr>
+{{end}}</table>
+{{template "footer"}}{{end}}
+
+{{define "table"}}{{template "header"}}
+<p><a href="/ui/">&larr; all tables</a></p>
+<h1>{{.Family}} / {{.Table}}</h1>
+<form id="lookup">
+<p><label>Key, one segment per line. Leave empty to list the table.<br>
+<textarea id="key" rows="3" cols="60"></textarea></label></p>
+<p><label>Application token, if the sidecar has an ACL<br>
+<input id="token" type="password" size="60"></label></p>
+<p><button type="submit">Look up</button></p>
+</form>
+<pre id="result"></pre>
+<script>
+document.getElementById("lookup").addEventListener("submit", function (e) {
+	e.preventDefault();
+	var key = document.getElementById("key").value.split("\n").filter(function (s) {
+		return s !== "";
+	}).map(function (s) {
+		return {Value: s};
+	});
+	var headers = {"Content-Type": "application/json"};
+	var token = document.getElementById("token").value;
+	if (token !== "") {
+		headers["Authorization"] = "Bearer " + token;
+	}
+	var result = document.getElementById("result");
+	result.textContent = "...";
+	fetch({{.PrefixURL}}, {method: "POST", headers: headers, body: JSON.stringify({Key: key})})
+		.then(function (resp) {
+			return resp.text().then(function (body) {
+				if (!resp.ok) {
+					result.textContent = resp.status + " " + resp.statusText + "\n" + body;
+					return;
+				}
+				result.textContent = JSON.stringify(JSON.parse(body), null, 2);
+			});
+		})
+		.catch(function (err) {
+			result.textContent = String(err);
+		});
+});
+</script>
+{{template "footer"}}{{end}}
+`))
+
+type uiIndex struct {
+	Latency    time.Duration
+	LatencyErr error
+	Tables     []ctlstore.TableStats
+}
+
+type uiTable struct {
+	Family    string
+	Table     string
+	PrefixURL string
+}
+
+func (s *Sidecar) uiIndex(w http.ResponseWriter, r *http.Request) error {
+	stats, err := s.reader.GetTableStats(r.Context())
+	if err != nil {
+		return errors.Wrap(err, "get table stats")
+	}
+	acl := s.settings.Load().acl
+	readable := map[string]func(table string) bool{} // by family
+	tables := make([]ctlstore.TableStats, 0, len(stats))
+	for _, tbl := range stats {
+		filter, ok := readable[tbl.Family]
+		if !ok {
+			filter, err = acl.tableFilter(r, tbl.Family)
+			if err != nil {
+				return err
+			}
+			readable[tbl.Family] = filter
+		}
+		if filter(tbl.Table) {
+			tables = append(tables, tbl)
+		}
+	}
+	page := uiIndex{Tables: tables}
+	page.Latency, page.LatencyErr = s.reader.GetLedgerLatency(r.Context())
+	page.Latency = page.Latency.Round(time.Millisecond)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	return uiTemplates.ExecuteTemplate(w, "index", page)
+}
+
+func (s *Sidecar) uiTable(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	page := uiTable{
+		Family: vars["familyName"],
+		Table:  vars["tableName"],
+	}
+	if err := s.settings.Load().acl.authorize(r, page.Family, page.Table); err != nil {
+		return err
+	}
+	page.PrefixURL = "/get-rows-by-key-prefix/" + page.Family + "/" + page.Table
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	return uiTemplates.ExecuteTemplate(w, "table", page)
+}
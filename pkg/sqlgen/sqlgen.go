@@ -23,6 +23,12 @@ type MetaTable struct {
 	TableName  schema.TableName
 	Fields     []schema.NamedFieldType
 	KeyFields  schema.PrimaryKey
+	// FieldOptions holds the defaults and nullability of the fields that
+	// have them.
+	FieldOptions map[schema.FieldName]schema.FieldOptions
+	// UniqueConstraints holds the sets of fields whose values must be
+	// unique, other than the key fields.
+	UniqueConstraints [][]schema.FieldName
 }
 
 var fieldTypeToSQLMap = map[schema.FieldType]map[string]string{
@@ -50,6 +56,21 @@ var fieldTypeToSQLMap = map[schema.FieldType]map[string]string{
 		"mysql":   "VARBINARY(255)",
 		"sqlite3": "BLOB(255)",
 	},
+	schema.FTTimestamp: {
+		"mysql":   "DATETIME(6)",
+		"sqlite3": "DATETIME",
+	},
+	schema.FTBoolean: {
+		"mysql":   "BOOLEAN",
+		"sqlite3": "BOOLEAN",
+	},
+	// SQLite has no JSON type. JSON TEXT has TEXT affinity, so documents
+	// such as 1 aren't stored as numbers, and is still told apart from
+	// FTText when the LDB's schema is read back.
+	schema.FTJSON: {
+		"mysql":   "JSON",
+		"sqlite3": "JSON TEXT",
+	},
 }
 
 func BuildMetaTableFromInput(
@@ -139,16 +160,45 @@ func (t MetaTable) ForDriver(newDriver string) (MetaTable, error) {
 	return t, nil
 }
 
+// columnDDL returns the definition of a column for the field, including
+// its options.
+func (t *MetaTable) columnDDL(fn schema.FieldName, ft schema.FieldType) (string, error) {
+	sqlType, ok := fieldTypeToSQLMap[ft][t.DriverName]
+	if !ok {
+		return "", fmt.Errorf("Invalid driver+type combo %s:%s", ft, t.DriverName)
+	}
+	ddl := SqlSprintf("$1 $2", dblquote(fn.Name), sqlType)
+
+	opts := t.FieldOptions[fn]
+	if opts.NotNull {
+		ddl += " NOT NULL"
+	}
+	if opts.HasDefault() {
+		// the default can't go through SqlSprintf since it may be quoted
+		val, err := maybeDecodeBase64(opts.Default, isBase64EncodedFieldType(ft))
+		if err != nil {
+			return "", err
+		}
+		quoted, err := SQLQuote(val)
+		if err != nil {
+			return "", err
+		}
+		ddl += " DEFAULT " + quoted
+	}
+	return ddl, nil
+}
+
 func (t *MetaTable) AsCreateTableDDL() (string, error) {
-	tableName := schema.LDBTableName(t.FamilyName, t.TableName)
+	return t.createTableDDL(schema.LDBTableName(t.FamilyName, t.TableName))
+}
+
+func (t *MetaTable) createTableDDL(tableName string) (string, error) {
 	lines := []string{}
 	for _, field := range t.Fields {
-		sqlType, ok := fieldTypeToSQLMap[field.FieldType][t.DriverName]
-		if !ok {
-			return "", fmt.Errorf("Invalid driver+type combo %s:%s", field.FieldType, t.DriverName)
+		line, err := t.columnDDL(field.Name, field.FieldType)
+		if err != nil {
+			return "", err
 		}
-
-		line := SqlSprintf("$1 $2", dblquote(field.Name.Name), sqlType)
 		lines = append(lines, line)
 	}
 
@@ -156,29 +206,128 @@ func (t *MetaTable) AsCreateTableDDL() (string, error) {
 	pkFields := strings.Join(dblquoteStrings(t.KeyFields.Strings()), ",")
 	pkDDL := SqlSprintf("PRIMARY KEY($1)", pkFields)
 	lines = append(lines, pkDDL)
+	for _, fns := range t.UniqueConstraints {
+		fields := strings.Join(dblquoteStrings(schema.StringifyFieldNames(fns)), ",")
+		lines = append(lines, SqlSprintf("UNIQUE($1)", fields))
+	}
 
+	// the body was built from sanitized parts, and may hold quoted defaults
 	tableBody := strings.Join(lines, ", ")
-	q := SqlSprintf("CREATE TABLE $1 ($2);", tableName, tableBody)
+	q := SqlSprintf("CREATE TABLE $1 (", tableName) + tableBody + ");"
 	return q, nil
 }
 
 // XXX: should we validate schema with SQLite first? (yes!)
+// The column gets the options set for fn in FieldOptions, if any.
 func (t *MetaTable) AddColumnDDL(fn schema.FieldName, ft schema.FieldType) (string, error) {
-	ftString, ok := fieldTypeToSQLMap[ft][t.DriverName]
-	if !ok {
-		return "", fmt.Errorf("Invalid driver+type combo %s:%s", ft, t.DriverName)
+	colDDL, err := t.columnDDL(fn, ft)
+	if err != nil {
+		return "", err
 	}
 
 	tableName := schema.LDBTableName(t.FamilyName, t.TableName)
-	ddl := SqlSprintf(
-		"ALTER TABLE $1 ADD COLUMN $2 $3",
-		tableName,
-		dblquote(fn.Name),
-		ftString)
+	ddl := SqlSprintf("ALTER TABLE $1 ADD COLUMN ", tableName) + colDDL
 
 	return ddl, nil
 }
 
+// ChangeColumnDDL returns the statements which rename the column from to
+// the column to, and change its type to ft. The table itself is updated to
+// reflect the change.
+//
+// MySQL can do this with a single statement. SQLite can rename a column,
+// but can't change its type, so in that case the table is rebuilt, and the
+// statements must be applied within a single transaction. The rows are
+// copied into a new table which then replaces the old one, so the table
+// keeps its name throughout, and the copied rows aren't changes to it.
+func (t *MetaTable) ChangeColumnDDL(from schema.FieldName, to schema.FieldName, ft schema.FieldType) ([]string, error) {
+	idx := -1
+	for i, field := range t.Fields {
+		if field.Name == from {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return nil, errors.Errorf("Field %s not found", from)
+	}
+	oldType := t.Fields[idx].FieldType
+	if _, ok := fieldTypeToSQLMap[ft][t.DriverName]; !ok {
+		return nil, fmt.Errorf("Invalid driver+type combo %s:%s", ft, t.DriverName)
+	}
+
+	// copy before modifying so that tables sharing these slices, such as
+	// those returned by ForDriver, are left alone
+	t.Fields = append([]schema.NamedFieldType{}, t.Fields...)
+	t.KeyFields.Fields = append([]schema.FieldName{}, t.KeyFields.Fields...)
+	t.Fields[idx] = schema.NamedFieldType{Name: to, FieldType: ft}
+	for i, kf := range t.KeyFields.Fields {
+		if kf == from {
+			t.KeyFields.Fields[i] = to
+		}
+	}
+	if from != to && len(t.UniqueConstraints) > 0 {
+		uniqueConstraints := make([][]schema.FieldName, len(t.UniqueConstraints))
+		for i, fns := range t.UniqueConstraints {
+			uniqueConstraints[i] = append([]schema.FieldName{}, fns...)
+			for j, fn := range fns {
+				if fn == from {
+					uniqueConstraints[i][j] = to
+				}
+			}
+		}
+		t.UniqueConstraints = uniqueConstraints
+	}
+	if opts, ok := t.FieldOptions[from]; ok && from != to {
+		fieldOptions := make(map[schema.FieldName]schema.FieldOptions, len(t.FieldOptions))
+		for fn, o := range t.FieldOptions {
+			fieldOptions[fn] = o
+		}
+		delete(fieldOptions, from)
+		fieldOptions[to] = opts
+		t.FieldOptions = fieldOptions
+	}
+
+	tableName := schema.LDBTableName(t.FamilyName, t.TableName)
+	switch t.DriverName {
+	case "mysql":
+		// the new definition must restate the column's options, or
+		// they're dropped
+		colDDL, err := t.columnDDL(to, ft)
+		if err != nil {
+			return nil, err
+		}
+		return []string{SqlSprintf(
+			"ALTER TABLE $1 CHANGE COLUMN $2 ",
+			tableName,
+			dblquote(from.Name)) + colDDL}, nil
+	case "sqlite3":
+		var ddls []string
+		if from != to {
+			ddls = append(ddls, SqlSprintf(
+				"ALTER TABLE $1 RENAME COLUMN $2 TO $3",
+				tableName,
+				dblquote(from.Name),
+				dblquote(to.Name)))
+		}
+		if oldType != ft {
+			rebuildName := schema.LDBRebuildTablePrefix + tableName
+			createDDL, err := t.createTableDDL(rebuildName)
+			if err != nil {
+				return nil, err
+			}
+			ddls = append(ddls,
+				createDDL,
+				SqlSprintf("INSERT INTO $1 SELECT * FROM $2", rebuildName, tableName),
+				SqlSprintf("DROP TABLE $1", tableName),
+				SqlSprintf("ALTER TABLE $1 RENAME TO $2", rebuildName, tableName))
+		}
+		return ddls, nil
+	default:
+		return nil, errors.Errorf("Unknown driver: %s", t.DriverName)
+	}
+}
+
 // Returns the names of the fields in this table in order
 func (t *MetaTable) FieldNames() []schema.FieldName {
 	fns := []schema.FieldName{}
@@ -193,11 +342,32 @@ func (t *MetaTable) UpsertDML(values []interface{}) (string, error) {
 	if len(values) != len(t.Fields) {
 		return "", errors.New("assertion failed: len(values) != len(t.Fields)")
 	}
+	return t.UpsertFieldsDML(t.FieldNames(), values)
+}
+
+// UpsertFieldsDML returns the DML string for an 'Upsert' which only sets
+// the named fields. The rest get their defaults, even if the row exists.
+//
+// Tables with unique constraints are upserted on their primary key in the
+// table's dialect, rather than with REPLACE, which would delete a row that
+// conflicts on a unique constraint instead of failing.
+func (t *MetaTable) UpsertFieldsDML(fieldNames []schema.FieldName, values []interface{}) (string, error) {
+	if len(values) != len(fieldNames) {
+		return "", errors.New("assertion failed: len(values) != len(fieldNames)")
+	}
 
 	tableName := schema.LDBTableName(t.FamilyName, t.TableName)
-	fieldNames := t.FieldNames()
 	fieldNamesSQL := strings.Join(dblquoteStrings(schema.StringifyFieldNames(fieldNames)), ",")
 	baseSQL := SqlSprintf("REPLACE INTO $1 ($2) VALUES(", tableName, fieldNamesSQL)
+	var conflictSQL string
+	if len(t.UniqueConstraints) > 0 {
+		baseSQL = SqlSprintf("INSERT INTO $1 ($2) VALUES(", tableName, fieldNamesSQL)
+		var err error
+		conflictSQL, err = t.upsertConflictSQL(fieldNames)
+		if err != nil {
+			return "", err
+		}
+	}
 
 	buf := bytes.NewBuffer([]byte{})
 	buf.WriteString(baseSQL)
@@ -207,8 +377,11 @@ func (t *MetaTable) UpsertDML(values []interface{}) (string, error) {
 			buf.WriteString(",")
 		}
 
-		val, err := maybeDecodeBase64(val,
-			isBase64EncodedFieldType(t.Fields[i].FieldType))
+		ft, found := t.FieldTypeByName(fieldNames[i])
+		if !found {
+			return "", errors.Errorf("UpsertFieldsDML couldn't find fieldName %s", fieldNames[i])
+		}
+		val, err := maybeDecodeBase64(val, isBase64EncodedFieldType(ft))
 		if err != nil {
 			return "", err
 		}
@@ -221,10 +394,69 @@ func (t *MetaTable) UpsertDML(values []interface{}) (string, error) {
 	}
 
 	buf.WriteString(")")
+	buf.WriteString(conflictSQL)
 
 	return buf.String(), nil
 }
 
+// upsertConflictSQL returns the clause which makes an INSERT of the named
+// fields update the row with the same primary key, if there is one. As with
+// REPLACE, the fields that aren't named are given their defaults.
+func (t *MetaTable) upsertConflictSQL(fieldNames []schema.FieldName) (string, error) {
+	named := make(map[schema.FieldName]bool, len(fieldNames))
+	for _, fn := range fieldNames {
+		named[fn] = true
+	}
+	isKey := make(map[schema.FieldName]bool, len(t.KeyFields.Fields))
+	for _, fn := range t.KeyFields.Fields {
+		isKey[fn] = true
+	}
+
+	var sets []string
+	for _, field := range t.Fields {
+		if isKey[field.Name] {
+			continue
+		}
+		col := dblquote(field.Name.Name)
+		if named[field.Name] {
+			switch t.DriverName {
+			case "mysql":
+				sets = append(sets, col+"=VALUES("+col+")")
+			case "sqlite3":
+				sets = append(sets, col+"=excluded."+col)
+			}
+			continue
+		}
+		val, err := maybeDecodeBase64(t.FieldOptions[field.Name].Default, isBase64EncodedFieldType(field.FieldType))
+		if err != nil {
+			return "", err
+		}
+		quoted, err := SQLQuote(val)
+		if err != nil {
+			return "", err
+		}
+		sets = append(sets, col+"="+quoted)
+	}
+
+	switch t.DriverName {
+	case "mysql":
+		if len(sets) == 0 {
+			// there's nothing to update, but the clause needs an assignment
+			col := dblquote(t.KeyFields.Fields[0].Name)
+			sets = append(sets, col+"="+col)
+		}
+		return " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ","), nil
+	case "sqlite3":
+		keyFields := strings.Join(dblquoteStrings(t.KeyFields.Strings()), ",")
+		if len(sets) == 0 {
+			return SqlSprintf(" ON CONFLICT($1) DO NOTHING", keyFields), nil
+		}
+		return SqlSprintf(" ON CONFLICT($1) DO UPDATE SET ", keyFields) + strings.Join(sets, ","), nil
+	default:
+		return "", errors.Errorf("Unknown driver: %s", t.DriverName)
+	}
+}
+
 // Returns the DML string for a delete for provided fields with placeholders
 // for all of the key fields in proper order.
 func (t *MetaTable) DeleteDML(values []interface{}) (string, error) {
@@ -248,7 +480,7 @@ func (t *MetaTable) DeleteDML(values []interface{}) (string, error) {
 		buf.WriteString(dblquote(fn.String()))
 		buf.WriteString(" = ")
 
-		ft, found := t.fieldTypeByName(fn)
+		ft, found := t.FieldTypeByName(fn)
 		if !found {
 			return "", errors.Errorf("DeleteDML couldn't find fieldName %s", fn.String())
 		}
@@ -266,6 +498,198 @@ func (t *MetaTable) DeleteDML(values []interface{}) (string, error) {
 	return buf.String(), nil
 }
 
+// UpsertFieldsParameterizedDML is like UpsertFieldsDML, except that the
+// values are returned as arguments rather than being quoted into the SQL.
+func (t *MetaTable) UpsertFieldsParameterizedDML(fieldNames []schema.FieldName, values []interface{}) (schema.ParameterizedDML, error) {
+	if len(values) != len(fieldNames) {
+		return schema.ParameterizedDML{}, errors.New("assertion failed: len(values) != len(fieldNames)")
+	}
+
+	args, err := t.dmlArgs(fieldNames, values)
+	if err != nil {
+		return schema.ParameterizedDML{}, err
+	}
+	tableName := schema.LDBTableName(t.FamilyName, t.TableName)
+	fieldNamesSQL := strings.Join(dblquoteStrings(schema.StringifyFieldNames(fieldNames)), ",")
+	if len(t.UniqueConstraints) > 0 {
+		conflictSQL, err := t.upsertConflictSQL(fieldNames)
+		if err != nil {
+			return schema.ParameterizedDML{}, err
+		}
+		return schema.ParameterizedDML{
+			SQL:  SqlSprintf("INSERT INTO $1 ($2) VALUES($3)", tableName, fieldNamesSQL, SQLPlaceholderSet(len(args))) + conflictSQL,
+			Args: args,
+		}, nil
+	}
+	return schema.ParameterizedDML{
+		SQL:  SqlSprintf("REPLACE INTO $1 ($2) VALUES($3)", tableName, fieldNamesSQL, SQLPlaceholderSet(len(args))),
+		Args: args,
+	}, nil
+}
+
+// DeleteParameterizedDML is like DeleteDML, except that the key values are
+// returned as arguments rather than being quoted into the SQL.
+func (t *MetaTable) DeleteParameterizedDML(values []interface{}) (schema.ParameterizedDML, error) {
+	if len(values) != len(t.KeyFields.Fields) {
+		return schema.ParameterizedDML{}, errors.New("assertion failed: len(values) != len(t.KeyFields.Fields)")
+	}
+
+	args, err := t.dmlArgs(t.KeyFields.Fields, values)
+	if err != nil {
+		return schema.ParameterizedDML{}, err
+	}
+	conds := make([]string, len(t.KeyFields.Fields))
+	for i, fn := range t.KeyFields.Fields {
+		conds[i] = dblquote(fn.String()) + " = ?"
+	}
+	tableName := schema.LDBTableName(t.FamilyName, t.TableName)
+	return schema.ParameterizedDML{
+		SQL:  SqlSprintf("DELETE FROM $1 WHERE ", tableName) + strings.Join(conds, " AND "),
+		Args: args,
+	}, nil
+}
+
+// UniqueConflict is a query for a row that an upsert would conflict with on
+// one of the table's unique constraints.
+type UniqueConflict struct {
+	Fields []schema.FieldName
+	Query  schema.ParameterizedDML
+}
+
+// UniqueConflictQueries returns a query for each unique constraint which
+// selects a row, other than the upserted one, with the values that an
+// upsert of the named fields gives the constraint's fields. As with UNIQUE,
+// a constraint isn't checked if any of its values are NULL, and fields that
+// the upsert doesn't set get their defaults.
+//
+// The queries must find conflicts before the upsert is applied, since an
+// upsert which conflicts on MySQL updates the conflicting row. On MySQL,
+// they lock the rows they read, so that they see the latest row even if
+// the transaction's snapshot is older.
+func (t *MetaTable) UniqueConflictQueries(fieldNames []schema.FieldName, values []interface{}) ([]UniqueConflict, error) {
+	if len(t.UniqueConstraints) == 0 {
+		return nil, nil
+	}
+	args, err := t.dmlArgs(fieldNames, values)
+	if err != nil {
+		return nil, err
+	}
+	valueOf := make(map[schema.FieldName]interface{}, len(fieldNames))
+	for i, fn := range fieldNames {
+		valueOf[fn] = args[i]
+	}
+	valueOrDefault := func(fn schema.FieldName) (interface{}, error) {
+		if v, ok := valueOf[fn]; ok {
+			return v, nil
+		}
+		ft, _ := t.FieldTypeByName(fn)
+		return maybeDecodeBase64(t.FieldOptions[fn].Default, isBase64EncodedFieldType(ft))
+	}
+
+	keyConds := make([]string, len(t.KeyFields.Fields))
+	keyArgs := make([]interface{}, len(t.KeyFields.Fields))
+	for i, fn := range t.KeyFields.Fields {
+		v, ok := valueOf[fn]
+		if !ok {
+			return nil, errors.Errorf("UniqueConflictQueries missing key field %s", fn)
+		}
+		keyConds[i] = dblquote(fn.String()) + " = ?"
+		keyArgs[i] = v
+	}
+
+	lockingRead := ""
+	if t.DriverName == "mysql" {
+		lockingRead = " FOR UPDATE"
+	}
+
+	tableName := schema.LDBTableName(t.FamilyName, t.TableName)
+	var res []UniqueConflict
+constraints:
+	for _, fns := range t.UniqueConstraints {
+		conds := make([]string, len(fns))
+		args := make([]interface{}, 0, len(fns)+len(keyArgs))
+		for i, fn := range fns {
+			v, err := valueOrDefault(fn)
+			if err != nil {
+				return nil, err
+			}
+			if v == nil {
+				continue constraints
+			}
+			conds[i] = dblquote(fn.String()) + " = ?"
+			args = append(args, v)
+		}
+		res = append(res, UniqueConflict{
+			Fields: fns,
+			Query: schema.ParameterizedDML{
+				SQL: SqlSprintf("SELECT 1 FROM $1 WHERE ", tableName) + strings.Join(conds, " AND ") +
+					" AND NOT (" + strings.Join(keyConds, " AND ") + ") LIMIT 1" + lockingRead,
+				Args: append(args, keyArgs...),
+			},
+		})
+	}
+	return res, nil
+}
+
+// ConditionQuery returns a query for whether the row with the key values
+// has the values of the named fields, which selects no row if there's no
+// row with the key values. A NULL value matches a NULL field.
+func (t *MetaTable) ConditionQuery(keyValues []interface{}, fieldNames []schema.FieldName, values []interface{}) (schema.ParameterizedDML, error) {
+	if len(keyValues) != len(t.KeyFields.Fields) {
+		return schema.ParameterizedDML{}, errors.New("assertion failed: len(keyValues) != len(t.KeyFields.Fields)")
+	}
+	keyArgs, err := t.dmlArgs(t.KeyFields.Fields, keyValues)
+	if err != nil {
+		return schema.ParameterizedDML{}, err
+	}
+	condArgs, err := t.dmlArgs(fieldNames, values)
+	if err != nil {
+		return schema.ParameterizedDML{}, err
+	}
+
+	// NULL-safe equality which works in both MySQL and SQLite
+	conds := make([]string, len(fieldNames))
+	args := make([]interface{}, 0, 2*len(condArgs)+len(keyArgs))
+	for i, fn := range fieldNames {
+		name := dblquote(fn.String())
+		conds[i] = "(" + name + " = ? OR (" + name + " IS NULL AND ? IS NULL))"
+		args = append(args, condArgs[i], condArgs[i])
+	}
+	if len(conds) == 0 {
+		// an empty condition matches any row
+		conds = append(conds, "1 = 1")
+	}
+	keyConds := make([]string, len(t.KeyFields.Fields))
+	for i, fn := range t.KeyFields.Fields {
+		keyConds[i] = dblquote(fn.String()) + " = ?"
+	}
+
+	tableName := schema.LDBTableName(t.FamilyName, t.TableName)
+	return schema.ParameterizedDML{
+		SQL: "SELECT " + strings.Join(conds, " AND ") + SqlSprintf(" FROM $1 WHERE ", tableName) +
+			strings.Join(keyConds, " AND ") + " LIMIT 1",
+		Args: append(args, keyArgs...),
+	}, nil
+}
+
+// dmlArgs returns the values of the named fields as DML arguments, which
+// means decoding the base64 encoding of binary values.
+func (t *MetaTable) dmlArgs(fieldNames []schema.FieldName, values []interface{}) ([]interface{}, error) {
+	args := make([]interface{}, len(values))
+	for i, val := range values {
+		ft, found := t.FieldTypeByName(fieldNames[i])
+		if !found {
+			return nil, errors.Errorf("couldn't find fieldName %s", fieldNames[i])
+		}
+		arg, err := maybeDecodeBase64(val, isBase64EncodedFieldType(ft))
+		if err != nil {
+			return nil, err
+		}
+		args[i] = arg
+	}
+	return args, nil
+}
+
 func (t *MetaTable) DropTableDDL() string {
 	tableName := schema.LDBTableName(t.FamilyName, t.TableName)
 	ddl := SqlSprintf(
@@ -283,7 +707,8 @@ func (t *MetaTable) ClearTableDDL() string {
 	return ddl
 }
 
-func (t *MetaTable) fieldTypeByName(fn schema.FieldName) (schema.FieldType, bool) {
+// FieldTypeByName returns the type of the named field, if the table has it.
+func (t *MetaTable) FieldTypeByName(fn schema.FieldName) (schema.FieldType, bool) {
 	for _, x := range t.Fields {
 		if x.Name == fn {
 			return x.FieldType, true
@@ -319,6 +744,41 @@ func (t *MetaTable) Validate() error {
 		}
 	}
 
+	for _, fns := range t.UniqueConstraints {
+		if len(fns) == 0 {
+			return errors.New("Unique constraints must have at least one field")
+		}
+		seen := map[schema.FieldName]bool{}
+		for _, fn := range fns {
+			ft, found := t.FieldTypeByName(fn)
+			if !found {
+				return fmt.Errorf("Unique constraint field '%s' not specified as a field", fn.Name)
+			}
+			if !ft.CanBeKey() {
+				return fmt.Errorf("Fields of type '%s' cannot be in a unique constraint", ft)
+			}
+			if seen[fn] {
+				return fmt.Errorf("Field '%s' is repeated in a unique constraint", fn.Name)
+			}
+			seen[fn] = true
+		}
+	}
+
+	for fn, opts := range t.FieldOptions {
+		ft, found := t.FieldTypeByName(fn)
+		if !found {
+			return fmt.Errorf("Options given for unknown field '%s'", fn.Name)
+		}
+		if err := opts.Validate(ft); err != nil {
+			return err
+		}
+		for _, kf := range t.KeyFields.Fields {
+			if opts.HasDefault() && kf == fn {
+				return fmt.Errorf("Key field '%s' cannot have a default", fn.Name)
+			}
+		}
+	}
+
 	return nil
 }
 
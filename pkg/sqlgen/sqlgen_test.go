@@ -160,6 +160,239 @@ func TestMetaTableAddColumnDDL(t *testing.T) {
 	}
 }
 
+func TestMetaTableFieldOptions(t *testing.T) {
+	famName, _ := schema.NewFamilyName("family1")
+	tblName, _ := schema.NewTableName("table1")
+	tbl := MetaTable{
+		DriverName: "sqlite3",
+		FamilyName: famName,
+		TableName:  tblName,
+		Fields: []schema.NamedFieldType{
+			{Name: schema.FieldName{Name: "field1"}, FieldType: schema.FTString},
+			{Name: schema.FieldName{Name: "field2"}, FieldType: schema.FTString},
+			{Name: schema.FieldName{Name: "field3"}, FieldType: schema.FTInteger},
+			{Name: schema.FieldName{Name: "field4"}, FieldType: schema.FTByteString},
+		},
+		KeyFields: schema.PrimaryKey{Fields: []schema.FieldName{{Name: "field1"}}},
+		FieldOptions: map[schema.FieldName]schema.FieldOptions{
+			{Name: "field2"}: {Default: "it's", NotNull: true},
+			{Name: "field3"}: {Default: float64(7)},
+			{Name: "field4"}: {Default: base64.StdEncoding.EncodeToString([]byte("hi"))},
+		},
+	}
+	require.NoError(t, tbl.Validate())
+
+	ddl, err := tbl.AsCreateTableDDL()
+	require.NoError(t, err)
+	require.Equal(t, `CREATE TABLE family1___table1 (`+
+		`"field1" VARCHAR(191), `+
+		`"field2" VARCHAR(191) NOT NULL DEFAULT 'it''s', `+
+		`"field3" INTEGER DEFAULT 7, `+
+		`"field4" BLOB(255) DEFAULT x'6869', `+
+		`PRIMARY KEY("field1")`+
+		`);`, ddl)
+
+	mysqlTbl, err := tbl.ForDriver("mysql")
+	require.NoError(t, err)
+	addDDL, err := mysqlTbl.AddColumnDDL(schema.FieldName{Name: "field3"}, schema.FTInteger)
+	require.NoError(t, err)
+	require.Equal(t, `ALTER TABLE family1___table1 ADD COLUMN "field3" BIGINT DEFAULT 7`, addDDL)
+	changeDDLs, err := mysqlTbl.ChangeColumnDDL(schema.FieldName{Name: "field2"}, schema.FieldName{Name: "field5"}, schema.FTString)
+	require.NoError(t, err)
+	require.Equal(t, []string{`ALTER TABLE family1___table1 CHANGE COLUMN "field2" "field5" VARCHAR(191) NOT NULL DEFAULT 'it''s'`}, changeDDLs)
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(ddl)
+	require.NoError(t, err)
+
+	// fields left out of an upsert get their defaults
+	dml, err := tbl.UpsertFieldsDML([]schema.FieldName{{Name: "field1"}}, []interface{}{"key"})
+	require.NoError(t, err)
+	require.Equal(t, `REPLACE INTO family1___table1 ("field1") VALUES('key')`, dml)
+	_, err = db.Exec(dml)
+	require.NoError(t, err)
+	var field2 string
+	var field3 int64
+	var field4 []byte
+	err = db.QueryRow("SELECT field2, field3, field4 FROM family1___table1").Scan(&field2, &field3, &field4)
+	require.NoError(t, err)
+	require.Equal(t, "it's", field2)
+	require.EqualValues(t, 7, field3)
+	require.Equal(t, []byte("hi"), field4)
+
+	// SQLite's REPLACE falls back to the default of a NOT NULL column
+	// rather than failing
+	_, err = db.Exec(`REPLACE INTO family1___table1 ("field1", "field2") VALUES('key', NULL)`)
+	require.NoError(t, err)
+	err = db.QueryRow("SELECT field2 FROM family1___table1").Scan(&field2)
+	require.NoError(t, err)
+	require.Equal(t, "it's", field2)
+}
+
+func TestMetaTableValidateFieldOptions(t *testing.T) {
+	famName, _ := schema.NewFamilyName("family1")
+	tblName, _ := schema.NewTableName("table1")
+	for _, test := range []struct {
+		name string
+		opts map[schema.FieldName]schema.FieldOptions
+		err  string
+	}{
+		{
+			name: "unknown field",
+			opts: map[schema.FieldName]schema.FieldOptions{{Name: "nope"}: {NotNull: true}},
+			err:  "Options given for unknown field 'nope'",
+		},
+		{
+			name: "default on text field",
+			opts: map[schema.FieldName]schema.FieldOptions{{Name: "field3"}: {Default: "x"}},
+			err:  "Fields of type 'text' cannot have a default",
+		},
+		{
+			name: "wrong default type",
+			opts: map[schema.FieldName]schema.FieldOptions{{Name: "field2"}: {Default: "x"}},
+			err:  "Invalid default x for a field of type 'integer'",
+		},
+		{
+			name: "fractional integer default",
+			opts: map[schema.FieldName]schema.FieldOptions{{Name: "field2"}: {Default: 1.5}},
+			err:  "Invalid default 1.5 for a field of type 'integer'",
+		},
+		{
+			name: "default on key field",
+			opts: map[schema.FieldName]schema.FieldOptions{{Name: "field1"}: {Default: "x"}},
+			err:  "Key field 'field1' cannot have a default",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			tbl := MetaTable{
+				DriverName: "sqlite3",
+				FamilyName: famName,
+				TableName:  tblName,
+				Fields: []schema.NamedFieldType{
+					{Name: schema.FieldName{Name: "field1"}, FieldType: schema.FTString},
+					{Name: schema.FieldName{Name: "field2"}, FieldType: schema.FTInteger},
+					{Name: schema.FieldName{Name: "field3"}, FieldType: schema.FTText},
+				},
+				KeyFields:    schema.PrimaryKey{Fields: []schema.FieldName{{Name: "field1"}}},
+				FieldOptions: test.opts,
+			}
+			err := tbl.Validate()
+			require.EqualError(t, err, test.err)
+		})
+	}
+}
+
+func TestMetaTableUniqueConstraints(t *testing.T) {
+	famName, _ := schema.NewFamilyName("family1")
+	tblName, _ := schema.NewTableName("table1")
+	tbl := MetaTable{
+		DriverName: "sqlite3",
+		FamilyName: famName,
+		TableName:  tblName,
+		Fields: []schema.NamedFieldType{
+			{Name: schema.FieldName{Name: "id"}, FieldType: schema.FTInteger},
+			{Name: schema.FieldName{Name: "slug"}, FieldType: schema.FTString},
+			{Name: schema.FieldName{Name: "region"}, FieldType: schema.FTString},
+			{Name: schema.FieldName{Name: "notes"}, FieldType: schema.FTText},
+		},
+		KeyFields: schema.PrimaryKey{Fields: []schema.FieldName{{Name: "id"}}},
+		FieldOptions: map[schema.FieldName]schema.FieldOptions{
+			{Name: "region"}: {Default: "us"},
+		},
+		UniqueConstraints: [][]schema.FieldName{
+			{{Name: "slug"}},
+			{{Name: "slug"}, {Name: "region"}},
+		},
+	}
+	require.NoError(t, tbl.Validate())
+
+	ddl, err := tbl.AsCreateTableDDL()
+	require.NoError(t, err)
+	require.Equal(t, `CREATE TABLE family1___table1 (`+
+		`"id" INTEGER, "slug" VARCHAR(191), "region" VARCHAR(191) DEFAULT 'us', "notes" TEXT, `+
+		`PRIMARY KEY("id"), UNIQUE("slug"), UNIQUE("slug","region")`+
+		`);`, ddl)
+
+	// fields left out of the upsert get their defaults
+	got, err := tbl.UniqueConflictQueries([]schema.FieldName{{Name: "id"}, {Name: "slug"}}, []interface{}{1, "a"})
+	require.NoError(t, err)
+	require.Equal(t, []UniqueConflict{
+		{
+			Fields: []schema.FieldName{{Name: "slug"}},
+			Query: schema.ParameterizedDML{
+				SQL:  `SELECT 1 FROM family1___table1 WHERE "slug" = ? AND NOT ("id" = ?) LIMIT 1`,
+				Args: []interface{}{"a", 1},
+			},
+		},
+		{
+			Fields: []schema.FieldName{{Name: "slug"}, {Name: "region"}},
+			Query: schema.ParameterizedDML{
+				SQL:  `SELECT 1 FROM family1___table1 WHERE "slug" = ? AND "region" = ? AND NOT ("id" = ?) LIMIT 1`,
+				Args: []interface{}{"a", "us", 1},
+			},
+		},
+	}, got)
+
+	// NULLs aren't checked
+	got, err = tbl.UniqueConflictQueries([]schema.FieldName{{Name: "id"}, {Name: "slug"}}, []interface{}{1, nil})
+	require.NoError(t, err)
+	require.Empty(t, got)
+
+	// upserts don't REPLACE, which would delete a conflicting row, and the
+	// fields left out of them get their defaults
+	upsertFields := []schema.FieldName{{Name: "id"}, {Name: "slug"}}
+	upsertValues := []interface{}{1, "a"}
+	dml, err := tbl.UpsertFieldsDML(upsertFields, upsertValues)
+	require.NoError(t, err)
+	require.Equal(t, `INSERT INTO family1___table1 ("id","slug") VALUES(1,'a') `+
+		`ON CONFLICT("id") DO UPDATE SET "slug"=excluded."slug","region"='us',"notes"=NULL`, dml)
+	pdml, err := tbl.UpsertFieldsParameterizedDML(upsertFields, upsertValues)
+	require.NoError(t, err)
+	require.Equal(t, schema.ParameterizedDML{
+		SQL: `INSERT INTO family1___table1 ("id","slug") VALUES(?,?) ` +
+			`ON CONFLICT("id") DO UPDATE SET "slug"=excluded."slug","region"='us',"notes"=NULL`,
+		Args: []interface{}{1, "a"},
+	}, pdml)
+
+	mysqlTbl, err := tbl.ForDriver("mysql")
+	require.NoError(t, err)
+	dml, err = mysqlTbl.UpsertFieldsDML(upsertFields, upsertValues)
+	require.NoError(t, err)
+	require.Equal(t, `INSERT INTO family1___table1 ("id","slug") VALUES(1,'a') `+
+		`ON DUPLICATE KEY UPDATE "slug"=VALUES("slug"),"region"='us',"notes"=NULL`, dml)
+
+	// on MySQL, the conflicting rows are locked
+	got, err = mysqlTbl.UniqueConflictQueries(upsertFields, upsertValues)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	require.Equal(t, `SELECT 1 FROM family1___table1 WHERE "slug" = ? AND NOT ("id" = ?) LIMIT 1 FOR UPDATE`, got[0].Query.SQL)
+
+	changed := tbl
+	changeDDLs, err := changed.ChangeColumnDDL(schema.FieldName{Name: "slug"}, schema.FieldName{Name: "handle"}, schema.FTByteString)
+	require.NoError(t, err)
+	require.Contains(t, changeDDLs, `CREATE TABLE _rebuild_family1___table1 (`+
+		`"id" INTEGER, "handle" BLOB(255), "region" VARCHAR(191) DEFAULT 'us', "notes" TEXT, `+
+		`PRIMARY KEY("id"), UNIQUE("handle"), UNIQUE("handle","region")`+
+		`);`)
+	require.Equal(t, schema.FieldName{Name: "slug"}, tbl.UniqueConstraints[0][0], "tables sharing the constraints are left alone")
+
+	for _, test := range []struct {
+		constraint []schema.FieldName
+		err        string
+	}{
+		{nil, "Unique constraints must have at least one field"},
+		{[]schema.FieldName{{Name: "nope"}}, "Unique constraint field 'nope' not specified as a field"},
+		{[]schema.FieldName{{Name: "notes"}}, "Fields of type 'text' cannot be in a unique constraint"},
+		{[]schema.FieldName{{Name: "slug"}, {Name: "slug"}}, "Field 'slug' is repeated in a unique constraint"},
+	} {
+		invalid := tbl
+		invalid.UniqueConstraints = [][]schema.FieldName{test.constraint}
+		require.EqualError(t, invalid.Validate(), test.err)
+	}
+}
+
 func TestMetaTableUpsertDML(t *testing.T) {
 	for _, test := range []struct {
 		name string
@@ -306,6 +539,140 @@ func TestMetaTableDeleteDML(t *testing.T) {
 	}
 }
 
+func TestMetaTableParameterizedDML(t *testing.T) {
+	famName, _ := schema.NewFamilyName("family1")
+	tblName, _ := schema.NewTableName("table1")
+	tbl := MetaTable{
+		FamilyName: famName,
+		TableName:  tblName,
+		Fields: []schema.NamedFieldType{
+			{Name: schema.FieldName{Name: "field1"}, FieldType: schema.FTString},
+			{Name: schema.FieldName{Name: "field2"}, FieldType: schema.FTByteString},
+			{Name: schema.FieldName{Name: "field3"}, FieldType: schema.FTInteger},
+		},
+		KeyFields: schema.PrimaryKey{Fields: []schema.FieldName{{Name: "field1"}, {Name: "field2"}}},
+	}
+	encoded := base64.StdEncoding.EncodeToString([]byte{1, 2, 3})
+
+	t.Run("upsert", func(t *testing.T) {
+		fieldNames := []schema.FieldName{{Name: "field1"}, {Name: "field2"}, {Name: "field3"}}
+		got, err := tbl.UpsertFieldsParameterizedDML(fieldNames, []interface{}{"it's a\x00b", encoded, 123})
+		require.NoError(t, err)
+		require.Equal(t, schema.ParameterizedDML{
+			SQL:  `REPLACE INTO family1___table1 ("field1","field2","field3") VALUES(?,?,?)`,
+			Args: []interface{}{"it's a\x00b", []byte{1, 2, 3}, 123},
+		}, got)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		got, err := tbl.DeleteParameterizedDML([]interface{}{"hello", encoded})
+		require.NoError(t, err)
+		require.Equal(t, schema.ParameterizedDML{
+			SQL:  `DELETE FROM family1___table1 WHERE "field1" = ? AND "field2" = ?`,
+			Args: []interface{}{"hello", []byte{1, 2, 3}},
+		}, got)
+	})
+
+	t.Run("condition", func(t *testing.T) {
+		got, err := tbl.ConditionQuery([]interface{}{"hello", encoded},
+			[]schema.FieldName{{Name: "field2"}, {Name: "field3"}}, []interface{}{encoded, nil})
+		require.NoError(t, err)
+		require.Equal(t, schema.ParameterizedDML{
+			SQL: `SELECT ("field2" = ? OR ("field2" IS NULL AND ? IS NULL)) AND ("field3" = ? OR ("field3" IS NULL AND ? IS NULL)) ` +
+				`FROM family1___table1 WHERE "field1" = ? AND "field2" = ? LIMIT 1`,
+			Args: []interface{}{[]byte{1, 2, 3}, []byte{1, 2, 3}, nil, nil, "hello", []byte{1, 2, 3}},
+		}, got)
+
+		_, err = tbl.ConditionQuery([]interface{}{"hello", encoded},
+			[]schema.FieldName{{Name: "field2"}}, []interface{}{"not base64!"})
+		require.Error(t, err)
+	})
+
+	t.Run("mismatched values", func(t *testing.T) {
+		_, err := tbl.DeleteParameterizedDML([]interface{}{"hello"})
+		require.Error(t, err)
+	})
+}
+
+func TestMetaTableChangeColumnDDL(t *testing.T) {
+	famName, _ := schema.NewFamilyName("family1")
+	tblName, _ := schema.NewTableName("table1")
+	newTable := func(driver string) MetaTable {
+		return MetaTable{
+			DriverName: driver,
+			FamilyName: famName,
+			TableName:  tblName,
+			Fields: []schema.NamedFieldType{
+				{Name: schema.FieldName{Name: "field1"}, FieldType: schema.FTString},
+				{Name: schema.FieldName{Name: "field2"}, FieldType: schema.FTString},
+			},
+			KeyFields: schema.PrimaryKey{Fields: []schema.FieldName{{Name: "field1"}}},
+		}
+	}
+	for _, test := range []struct {
+		name   string
+		driver string
+		from   string
+		to     string
+		ft     schema.FieldType
+		want   []string
+	}{
+		{
+			name:   "mysql rename",
+			driver: "mysql",
+			from:   "field2",
+			to:     "field3",
+			ft:     schema.FTString,
+			want:   []string{`ALTER TABLE family1___table1 CHANGE COLUMN "field2" "field3" VARCHAR(191)`},
+		},
+		{
+			name:   "mysql widen",
+			driver: "mysql",
+			from:   "field2",
+			to:     "field2",
+			ft:     schema.FTText,
+			want:   []string{`ALTER TABLE family1___table1 CHANGE COLUMN "field2" "field2" MEDIUMTEXT`},
+		},
+		{
+			name:   "sqlite rename",
+			driver: "sqlite3",
+			from:   "field2",
+			to:     "field3",
+			ft:     schema.FTString,
+			want:   []string{`ALTER TABLE family1___table1 RENAME COLUMN "field2" TO "field3"`},
+		},
+		{
+			name:   "sqlite rename and widen",
+			driver: "sqlite3",
+			from:   "field2",
+			to:     "field3",
+			ft:     schema.FTText,
+			want: []string{
+				`ALTER TABLE family1___table1 RENAME COLUMN "field2" TO "field3"`,
+				`CREATE TABLE _rebuild_family1___table1 ("field1" VARCHAR(191), "field3" TEXT, PRIMARY KEY("field1"));`,
+				`INSERT INTO _rebuild_family1___table1 SELECT * FROM family1___table1`,
+				`DROP TABLE family1___table1`,
+				`ALTER TABLE _rebuild_family1___table1 RENAME TO family1___table1`,
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			tbl := newTable(test.driver)
+			orig := tbl
+			got, err := tbl.ChangeColumnDDL(schema.FieldName{Name: test.from}, schema.FieldName{Name: test.to}, test.ft)
+			require.NoError(t, err)
+			require.EqualValues(t, test.want, got)
+			require.Equal(t, test.to, tbl.Fields[1].Name.Name)
+			require.Equal(t, test.ft, tbl.Fields[1].FieldType)
+			require.Equal(t, "field2", orig.Fields[1].Name.Name)
+		})
+	}
+
+	tbl := newTable("mysql")
+	_, err := tbl.ChangeColumnDDL(schema.FieldName{Name: "nope"}, schema.FieldName{Name: "nope2"}, schema.FTString)
+	require.Error(t, err)
+}
+
 func TestMetaTableClearTableDDL(t *testing.T) {
 	famName, _ := schema.NewFamilyName("family1")
 	tblName, _ := schema.NewTableName("table1")
@@ -3,7 +3,9 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"encoding/hex"
 	"fmt"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/segmentio/ctlstore/pkg/schema"
@@ -47,7 +49,7 @@ func (m *SqliteDBInfo) GetColumnInfo(ctx context.Context, tableNames []string) (
 			}
 
 			qs := fmt.Sprintf(
-				"SELECT cid, name, type, pk FROM pragma_table_info(%s) "+
+				"SELECT cid, name, type, pk, dflt_value, \"notnull\" FROM pragma_table_info(%s) "+
 					"ORDER BY cid ASC",
 				qTableName)
 
@@ -62,11 +64,21 @@ func (m *SqliteDBInfo) GetColumnInfo(ctx context.Context, tableNames []string) (
 				var colName string
 				var dataType string
 				var pk int
+				var dflt sql.NullString
+				var notNull bool
 
-				err = rows.Scan(&colID, &colName, &dataType, &pk)
+				err = rows.Scan(&colID, &colName, &dataType, &pk, &dflt, &notNull)
 				if err != nil {
 					return err
 				}
+				var def *string
+				if dflt.Valid && !strings.EqualFold(dflt.String, "NULL") {
+					v, err := unquoteSQLiteLiteral(dflt.String)
+					if err != nil {
+						return errors.Wrapf(err, "default of %s.%s", tableName, colName)
+					}
+					def = &v
+				}
 
 				columnInfos = append(columnInfos, schema.DBColumnInfo{
 					TableName:    tableName,
@@ -74,6 +86,8 @@ func (m *SqliteDBInfo) GetColumnInfo(ctx context.Context, tableNames []string) (
 					ColumnName:   colName,
 					DataType:     dataType,
 					IsPrimaryKey: (pk > 0),
+					Default:      def,
+					NotNull:      notNull,
 				})
 			}
 			return rows.Err()
@@ -84,3 +98,18 @@ func (m *SqliteDBInfo) GetColumnInfo(ctx context.Context, tableNames []string) (
 	}
 	return columnInfos, nil
 }
+
+// unquoteSQLiteLiteral returns the value of a string or blob literal as
+// reported by pragma_table_info, e.g. X'6869' is hi. Other literals, such
+// as numbers, are returned as is.
+func unquoteSQLiteLiteral(lit string) (string, error) {
+	switch {
+	case len(lit) >= 2 && lit[0] == '\'' && lit[len(lit)-1] == '\'':
+		return strings.Replace(lit[1:len(lit)-1], "''", "'", -1), nil
+	case len(lit) >= 3 && (lit[0] == 'X' || lit[0] == 'x') && lit[1] == '\'' && lit[len(lit)-1] == '\'':
+		b, err := hex.DecodeString(lit[2 : len(lit)-1])
+		return string(b), err
+	default:
+		return lit, nil
+	}
+}
@@ -3,7 +3,6 @@ package supervisor
 import (
 	"bufio"
 	"context"
-	"crypto/sha1"
 	"encoding/base64"
 	"fmt"
 	"io"
@@ -19,6 +18,7 @@ import (
 	"github.com/segmentio/events/v2"
 	"github.com/segmentio/stats/v4"
 
+	"github.com/segmentio/ctlstore/pkg/ctlcrypto"
 	"github.com/segmentio/ctlstore/pkg/utils"
 )
 
@@ -114,13 +114,14 @@ func getChecksum(path string) (string, error) {
 	}
 	defer f.Close()
 
-	h := sha1.New()
+	provider := ctlcrypto.Default()
+	h := provider.NewChecksum()
 	if _, err := io.Copy(h, f); err != nil {
-		events.Log("failed to generate sha1 of snapshot", err)
+		events.Log("failed to generate %s of snapshot: %{error}v", provider.ChecksumAlgorithm(), err)
 	}
 
 	cs := base64.StdEncoding.EncodeToString(h.Sum(nil))
-	events.Log("base64 encoding of sha1: %s", cs)
+	events.Log("base64 encoding of %s: %s", provider.ChecksumAlgorithm(), cs)
 
 	return cs, nil
 }
@@ -160,7 +161,8 @@ func (c *s3Snapshot) sendToS3(ctx context.Context, key string, bucket string, bo
 		Body:              body,
 		ChecksumAlgorithm: "sha256",
 		Metadata: map[string]string{
-			"checksum": cs,
+			"checksum":           cs,
+			"checksum-algorithm": ctlcrypto.Default().ChecksumAlgorithm(),
 		},
 	})
 	if err == nil {
@@ -0,0 +1,206 @@
+package supervisor
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/segmentio/events/v2"
+	"github.com/segmentio/stats/v4"
+)
+
+const (
+	leaseTableName       = "supervisor_leases"
+	defaultLeaseName     = "snapshots"
+	defaultLeaseDuration = 30 * time.Second
+)
+
+// LeaderElectionConfig configures the election of a single supervisor to
+// take snapshots among several which run for availability. The supervisors
+// compete for a lease on a row of the ctldb, which the leader renews while
+// it runs. When the leader stops renewing it, such as because it crashed,
+// another supervisor takes over once the lease expires.
+type LeaderElectionConfig struct {
+	// DB is the ctldb, which must have the supervisor_leases table.
+	DB *sql.DB
+	// Name identifies the lease. Supervisors which upload to the same
+	// snapshot URLs must share it. Defaults to "snapshots".
+	Name string
+	// Holder identifies this supervisor. Defaults to its hostname and pid.
+	Holder string
+	// LeaseDuration is how long a lease lasts unless it's renewed, which
+	// the leader does at a third of it. The clocks of the supervisors must
+	// be within a fraction of it of each other. Defaults to 30s.
+	LeaseDuration time.Duration
+}
+
+// leaderElector holds the lease on behalf of a supervisor
+type leaderElector struct {
+	db            *sql.DB
+	name          string
+	holder        string
+	leaseDuration time.Duration
+	now           func() time.Time
+
+	mut       sync.Mutex
+	leader    bool
+	expiresAt time.Time // of our lease, as of the last renewal
+}
+
+func newLeaderElector(config LeaderElectionConfig) *leaderElector {
+	if config.Name == "" {
+		config.Name = defaultLeaseName
+	}
+	if config.Holder == "" {
+		hostname, _ := os.Hostname()
+		config.Holder = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	if config.LeaseDuration <= 0 {
+		config.LeaseDuration = defaultLeaseDuration
+	}
+	return &leaderElector{
+		db:            config.DB,
+		name:          config.Name,
+		holder:        config.Holder,
+		leaseDuration: config.LeaseDuration,
+		now:           time.Now,
+	}
+}
+
+// isLeader returns whether we hold an unexpired lease. A nil elector is
+// always the leader.
+func (l *leaderElector) isLeader() bool {
+	if l == nil {
+		return true
+	}
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	return l.leader && l.now().Before(l.expiresAt)
+}
+
+// run competes for the lease, renewing it while we hold it, until the
+// context is done. It then releases the lease so that another supervisor
+// can take over without waiting for it to expire.
+func (l *leaderElector) run(ctx context.Context) {
+	ticker := time.NewTicker(l.leaseDuration / 3)
+	defer ticker.Stop()
+	for {
+		if err := l.campaign(ctx); err != nil && errors.Cause(err) != context.Canceled {
+			events.Log("Failed campaigning for the %{lease}s lease: %{error}+v", l.name, err)
+			stats.Incr("leader-election-errors")
+		}
+		select {
+		case <-ctx.Done():
+			if err := l.release(context.Background()); err != nil {
+				events.Log("Failed releasing the %{lease}s lease: %{error}+v", l.name, err)
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// campaign acquires or renews the lease if it's free, expired or ours.
+func (l *leaderElector) campaign(ctx context.Context) error {
+	now := l.now()
+	expiresAt := now.Add(l.leaseDuration)
+	acquired, err := l.acquire(ctx, now, expiresAt)
+	if err != nil {
+		// we remain the leader until our lease would have expired
+		l.setLeader(l.isLeader(), time.Time{})
+		return err
+	}
+	l.setLeader(acquired, expiresAt)
+	return nil
+}
+
+func (l *leaderElector) acquire(ctx context.Context, now, expiresAt time.Time) (bool, error) {
+	res, err := l.db.ExecContext(ctx,
+		"UPDATE "+leaseTableName+" SET holder = ?, expires_at = ? "+
+			"WHERE name = ? AND (holder = ? OR expires_at < ?)",
+		l.holder, expiresAt.UnixNano()/int64(time.Millisecond),
+		l.name, l.holder, now.UnixNano()/int64(time.Millisecond))
+	if err != nil {
+		return false, errors.Wrap(err, "update lease")
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return false, errors.Wrap(err, "rows affected")
+	} else if n > 0 {
+		return true, nil
+	}
+
+	// Either another supervisor holds the lease, or there's no lease yet,
+	// or MySQL didn't count a renewal which changed nothing.
+	var holder string
+	err = l.db.QueryRowContext(ctx,
+		"SELECT holder FROM "+leaseTableName+" WHERE name = ?", l.name).Scan(&holder)
+	switch {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		return false, errors.Wrap(err, "select lease")
+	default:
+		return holder == l.holder, nil
+	}
+	_, err = l.db.ExecContext(ctx,
+		"INSERT INTO "+leaseTableName+" (name, holder, expires_at) VALUES(?, ?, ?)",
+		l.name, l.holder, expiresAt.UnixNano()/int64(time.Millisecond))
+	if err != nil {
+		if isRowConflict(err) {
+			// another supervisor beat us to it
+			return false, nil
+		}
+		return false, errors.Wrap(err, "insert lease")
+	}
+	return true, nil
+}
+
+// release expires the lease if we hold it
+func (l *leaderElector) release(ctx context.Context) error {
+	l.mut.Lock()
+	wasLeader := l.leader
+	l.mut.Unlock()
+	if !wasLeader {
+		return nil
+	}
+	l.setLeader(false, time.Time{})
+	_, err := l.db.ExecContext(ctx,
+		"UPDATE "+leaseTableName+" SET expires_at = 0 WHERE name = ? AND holder = ?",
+		l.name, l.holder)
+	return errors.Wrap(err, "release lease")
+}
+
+// setLeader records the outcome of a campaign. A zero expiresAt keeps the
+// expiry of the last lease we acquired.
+func (l *leaderElector) setLeader(leader bool, expiresAt time.Time) {
+	l.mut.Lock()
+	changed := leader != l.leader
+	l.leader = leader
+	if !expiresAt.IsZero() {
+		l.expiresAt = expiresAt
+	}
+	l.mut.Unlock()
+
+	if leader {
+		stats.Set("leader", 1)
+	} else {
+		stats.Set("leader", 0)
+	}
+	if changed {
+		stats.Incr("leadership-changes", stats.T("leader", fmt.Sprint(leader)))
+		if leader {
+			events.Log("Became the leader, holding the %{lease}s lease as %{holder}s", l.name, l.holder)
+		} else {
+			events.Log("No longer the leader of the %{lease}s lease", l.name)
+		}
+	}
+}
+
+func isRowConflict(err error) bool {
+	return strings.Contains(err.Error(), "Duplicate entry") ||
+		strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
@@ -0,0 +1,113 @@
+package supervisor
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/ctldb"
+	"github.com/segmentio/ctlstore/pkg/reflector/fakes"
+)
+
+func newTestLeaseDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "ctldb.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	_, err = db.Exec(ctldb.SupervisorLeasesDBSchemaUp)
+	require.NoError(t, err)
+	return db
+}
+
+func TestLeaderElection(t *testing.T) {
+	ctx := context.Background()
+	db := newTestLeaseDB(t)
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	a := newLeaderElector(LeaderElectionConfig{DB: db, Holder: "a", LeaseDuration: 30 * time.Second})
+	b := newLeaderElector(LeaderElectionConfig{DB: db, Holder: "b", LeaseDuration: 30 * time.Second})
+	a.now, b.now = clock, clock
+
+	require.NoError(t, a.campaign(ctx))
+	require.NoError(t, b.campaign(ctx))
+	require.True(t, a.isLeader())
+	require.False(t, b.isLeader())
+
+	// renewals keep the lease
+	now = now.Add(20 * time.Second)
+	require.NoError(t, a.campaign(ctx))
+	now = now.Add(20 * time.Second)
+	require.NoError(t, b.campaign(ctx))
+	require.True(t, a.isLeader())
+	require.False(t, b.isLeader())
+
+	// a stops renewing, so b takes over once the lease expires
+	now = now.Add(9 * time.Second)
+	require.True(t, a.isLeader())
+	require.NoError(t, b.campaign(ctx))
+	require.False(t, b.isLeader())
+	now = now.Add(2 * time.Second)
+	require.False(t, a.isLeader())
+	require.NoError(t, b.campaign(ctx))
+	require.True(t, b.isLeader())
+	require.NoError(t, a.campaign(ctx))
+	require.False(t, a.isLeader())
+
+	// releasing hands over without waiting for the lease to expire
+	require.NoError(t, b.release(ctx))
+	require.False(t, b.isLeader())
+	require.NoError(t, a.campaign(ctx))
+	require.True(t, a.isLeader())
+}
+
+func TestLeaderElectionErrors(t *testing.T) {
+	ctx := context.Background()
+	db := newTestLeaseDB(t)
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	l := newLeaderElector(LeaderElectionConfig{DB: db, Holder: "a", LeaseDuration: 30 * time.Second})
+	l.now = func() time.Time { return now }
+	require.NoError(t, l.campaign(ctx))
+	require.True(t, l.isLeader())
+
+	// the leader stays the leader until its lease expires when it can't
+	// renew it, since no other supervisor can take over until then
+	_, err := db.Exec("DROP TABLE " + leaseTableName)
+	require.NoError(t, err)
+	now = now.Add(20 * time.Second)
+	require.Error(t, l.campaign(ctx))
+	require.True(t, l.isLeader())
+	now = now.Add(20 * time.Second)
+	require.Error(t, l.campaign(ctx))
+	require.False(t, l.isLeader())
+}
+
+func TestSupervisorSnapshotFollower(t *testing.T) {
+	ctx := context.Background()
+	tmpPath := t.TempDir()
+	archivePath := filepath.Join(tmpPath, "archive.db")
+	db := newTestLeaseDB(t)
+
+	leader := newLeaderElector(LeaderElectionConfig{DB: db, Holder: "leader"})
+	require.NoError(t, leader.campaign(ctx))
+
+	supervisorI, err := SupervisorFromConfig(SupervisorConfig{
+		SnapshotInterval: time.Hour,
+		SnapshotURL:      "file://" + archivePath,
+		LDBPath:          filepath.Join(tmpPath, "ldb.db"),
+		Reflector:        fakes.NewFakeReflector(),
+		LeaderElection:   &LeaderElectionConfig{DB: db, Holder: "follower"},
+	})
+	require.NoError(t, err)
+	supervisor := supervisorI.(*supervisor)
+	require.NoError(t, supervisor.leader.campaign(ctx))
+
+	require.NoError(t, supervisor.snapshot(ctx))
+	_, err = os.Stat(archivePath)
+	require.True(t, os.IsNotExist(err), "followers must not upload snapshots")
+}
@@ -0,0 +1,127 @@
+package supervisor
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/segmentio/events/v2"
+
+	"github.com/segmentio/ctlstore/pkg/ldb"
+)
+
+// SnapshotStatus is the body of GET /status responses.
+type SnapshotStatus struct {
+	// Leader is whether this supervisor takes snapshots. It's always true
+	// without leader election.
+	Leader     bool `json:"leader"`
+	InProgress bool `json:"inProgress"`
+	// LastSnapshot is omitted until a snapshot has been uploaded
+	LastSnapshot *SnapshotInfo `json:"lastSnapshot,omitempty"`
+	// LastError is the error of the last snapshot, if it failed
+	LastError string `json:"lastError,omitempty"`
+}
+
+// SnapshotInfo describes an uploaded snapshot.
+type SnapshotInfo struct {
+	At time.Time `json:"at"`
+	// Duration is in seconds, and covers taking and uploading the snapshot
+	Duration  float64 `json:"duration"`
+	SizeBytes int64   `json:"sizeBytes"`
+	// LedgerSeq is the last ledger sequence applied to the snapshot
+	LedgerSeq int64 `json:"ledgerSeq"`
+}
+
+// snapshotTracker records the progress of snapshots for GET /status.
+type snapshotTracker struct {
+	mu         sync.Mutex
+	inProgress bool
+	last       *SnapshotInfo
+	lastErr    error
+}
+
+func (t *snapshotTracker) begin() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.inProgress = true
+}
+
+// end records the snapshot started at info.At, which is only kept if it was
+// uploaded.
+func (t *snapshotTracker) end(info SnapshotInfo, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.inProgress = false
+	t.lastErr = err
+	if err == nil {
+		info.Duration = time.Since(info.At).Seconds()
+		t.last = &info
+	}
+}
+
+func (t *snapshotTracker) status() SnapshotStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	res := SnapshotStatus{InProgress: t.inProgress, LastSnapshot: t.last}
+	if t.lastErr != nil {
+		res.LastError = t.lastErr.Error()
+	}
+	return res
+}
+
+// fetchSnapshotSeq reads the ledger sequence of the LDB at path, which must
+// not be changing.
+func fetchSnapshotSeq(ctx context.Context, path string) (int64, error) {
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return 0, errors.Wrap(err, "open ldb")
+	}
+	defer db.Close()
+	seq, err := ldb.FetchSeqFromLdb(ctx, db)
+	return seq.Int(), err
+}
+
+// statusHandler serves GET /status, and POST /snapshot which takes a snapshot
+// as soon as the supervisor is free to, such as before planned maintenance.
+func (s *supervisor) statusHandler() http.Handler {
+	r := mux.NewRouter()
+	r.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		status := s.tracker.status()
+		status.Leader = s.leader.isLeader()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(status)
+	}).Methods("GET")
+	r.HandleFunc("/snapshot", func(w http.ResponseWriter, r *http.Request) {
+		if !s.leader.isLeader() {
+			http.Error(w, "another supervisor is the leader", http.StatusConflict)
+			return
+		}
+		select {
+		case s.trigger <- struct{}{}:
+			events.Log("Snapshot requested by %{addr}s", r.RemoteAddr)
+		default:
+			// a requested snapshot is already pending
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}).Methods("POST")
+	return r
+}
+
+// serveStatus serves the statusHandler on the bind address until the context
+// is done.
+func (s *supervisor) serveStatus(ctx context.Context, bind string) {
+	h := &http.Server{Addr: bind, Handler: s.statusHandler()}
+	go func() {
+		<-ctx.Done()
+		h.Close()
+	}()
+	events.Log("Serving supervisor status on %{addr}s...", bind)
+	if err := h.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		events.Log("Error serving supervisor status: %{error}+v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package supervisor
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ldbpkg "github.com/segmentio/ctlstore/pkg/ldb"
+	"github.com/segmentio/ctlstore/pkg/reflector/fakes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupervisorStatus(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tmpPath := t.TempDir()
+	ldbDbPath := filepath.Join(tmpPath, "ldb.db")
+	ldb, err := sql.Open("sqlite3", ldbDbPath+"?_journal_mode=wal&cache=shared")
+	require.NoError(t, err)
+	defer ldb.Close()
+	require.NoError(t, ldbpkg.EnsureLdbInitialized(ctx, ldb))
+	_, err = ldb.Exec(
+		fmt.Sprintf("REPLACE INTO %s (id, seq) VALUES(?, ?)", ldbpkg.LDBSeqTableName),
+		ldbpkg.LDBSeqTableID, 100)
+	require.NoError(t, err)
+
+	sup, err := SupervisorFromConfig(SupervisorConfig{
+		SnapshotInterval: time.Hour,
+		SnapshotURL:      "file://" + filepath.Join(tmpPath, "archive.db"),
+		LDBPath:          ldbDbPath,
+		Reflector:        fakes.NewFakeReflector(),
+	})
+	require.NoError(t, err)
+	s := sup.(*supervisor)
+	handler := s.statusHandler()
+
+	getStatus := func() SnapshotStatus {
+		t.Helper()
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/status", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		var status SnapshotStatus
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&status))
+		return status
+	}
+
+	status := getStatus()
+	require.True(t, status.Leader)
+	require.Nil(t, status.LastSnapshot)
+
+	before := time.Now()
+	require.NoError(t, s.snapshot(ctx))
+	status = getStatus()
+	require.False(t, status.InProgress)
+	require.Empty(t, status.LastError)
+	require.NotNil(t, status.LastSnapshot)
+	require.EqualValues(t, 100, status.LastSnapshot.LedgerSeq)
+	require.NotZero(t, status.LastSnapshot.SizeBytes)
+	require.False(t, status.LastSnapshot.At.Before(before))
+
+	// requests are coalesced while one is pending
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("POST", "/snapshot", nil))
+		require.Equal(t, http.StatusAccepted, w.Code)
+	}
+	require.Len(t, s.trigger, 1)
+}
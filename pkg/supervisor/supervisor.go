@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -26,9 +27,22 @@ type Reflector interface {
 
 type SupervisorConfig struct {
 	SnapshotInterval time.Duration
-	SnapshotURL      string
-	LDBPath          string
-	Reflector        Reflector
+	// SnapshotURL is a comma separated list of the destinations which each
+	// snapshot is uploaded to
+	SnapshotURL string
+	// SnapshotURLs are more destinations, such as a bucket in another
+	// region which reflectors can bootstrap from if the primary is down
+	SnapshotURLs []string
+	LDBPath      string
+	Reflector    Reflector
+	// LeaderElection, if set, elects a single supervisor among those which
+	// share the lease to take snapshots. The others keep their reflectors
+	// running so that they're ready to take over.
+	LeaderElection *LeaderElectionConfig
+	// StatusBind, if set, is the address to serve GET /status, which
+	// reports the progress of snapshots, and POST /snapshot, which takes a
+	// snapshot immediately.
+	StatusBind string
 }
 
 type supervisor struct {
@@ -36,52 +50,140 @@ type supervisor struct {
 	BreatheDuration time.Duration
 	LDBPath         string
 	Snapshots       []archivedSnapshot
-	reflectorCtl    *reflector.ReflectorCtl
+	// uploads tracks the uploads to each of the Snapshots, by index
+	uploads      []*snapshotUploads
+	reflectorCtl *reflector.ReflectorCtl
+	leader       *leaderElector
+	statusBind   string
+	tracker      snapshotTracker
+	// trigger requests a snapshot without waiting for the interval
+	trigger chan struct{}
 }
 
 func SupervisorFromConfig(config SupervisorConfig) (Supervisor, error) {
 	var snapshots []archivedSnapshot
-	urls := strings.Split(config.SnapshotURL, ",")
+	var uploads []*snapshotUploads
+	urls := append(strings.Split(config.SnapshotURL, ","), config.SnapshotURLs...)
 	for _, url := range urls {
+		if url == "" && len(urls) > 1 {
+			continue
+		}
 		snapshot, err := archivedSnapshotFromURL(url)
 		if err != nil {
 			return nil, errors.Wrapf(err, "configure snapshot for '%s'", url)
 		}
 		snapshots = append(snapshots, snapshot)
+		uploads = append(uploads, &snapshotUploads{url: url})
 	}
-	return &supervisor{
+	s := &supervisor{
 		SleepDuration:   config.SnapshotInterval,
 		BreatheDuration: 5 * time.Second,
 		LDBPath:         config.LDBPath,
 		Snapshots:       snapshots,
+		uploads:         uploads,
 		reflectorCtl:    reflector.NewReflectorCtl(config.Reflector),
-	}, nil
+		statusBind:      config.StatusBind,
+		trigger:         make(chan struct{}, 1),
+	}
+	if config.LeaderElection != nil {
+		s.leader = newLeaderElector(*config.LeaderElection)
+	}
+	return s, nil
 }
 
-func (s *supervisor) snapshot(ctx context.Context) error {
+func (s *supervisor) snapshot(ctx context.Context) (err error) {
+	if !s.leader.isLeader() {
+		events.Debug("Not taking a snapshot because another supervisor is the leader")
+		stats.Incr("snapshots-skipped")
+		return nil
+	}
 	events.Log("Taking a snapshot")
+	taken := SnapshotInfo{At: time.Now()}
+	s.tracker.begin()
+	defer func() { s.tracker.end(taken, err) }()
 	s.reflectorCtl.Stop(ctx)
 	defer s.reflectorCtl.Start(ctx)
 	if err := s.checkpointLDB(); err != nil {
 		return errors.Wrap(err, "checkpoint ldb")
 	}
+	if err := validateSnapshotWithStats(ctx, s.LDBPath); err != nil {
+		return errors.Wrap(err, "validate ldb")
+	}
 	info, err := os.Stat(s.LDBPath)
 	if err != nil {
 		return errors.Wrap(err, "stat ldb path")
 	}
 	stats.Set("ldb-size-bytes", info.Size())
-	errs := make(chan error, len(s.Snapshots))
-	for _, snapshot := range s.Snapshots {
-		go func(snapshot archivedSnapshot) {
-			err := snapshot.Upload(ctx, s.LDBPath)
-			errs <- errors.Wrapf(err, "upload snapshot")
-		}(snapshot)
-	}
-	for range s.Snapshots {
-		if err := <-errs; err != nil {
-			return err
+	taken.SizeBytes = info.Size()
+	if taken.LedgerSeq, err = fetchSnapshotSeq(ctx, s.LDBPath); err != nil {
+		return errors.Wrap(err, "fetch ldb seq")
+	}
+	// the snapshot may have taken long enough for another supervisor to
+	// have taken over
+	if !s.leader.isLeader() {
+		return errors.New("lost leadership before uploading")
+	}
+	return s.upload(ctx)
+}
+
+// snapshotUploads tracks the uploads of snapshots to one destination.
+type snapshotUploads struct {
+	url                 string
+	consecutiveFailures int
+}
+
+// record counts the upload's outcome in the destination's metrics.
+func (u *snapshotUploads) record(err error, duration time.Duration) {
+	tag := stats.T("destination", u.url)
+	if err != nil {
+		u.consecutiveFailures++
+		stats.Incr("snapshot-uploads", tag, stats.T("result", "error"))
+		events.Log("Error uploading snapshot to %{destination}s (%{failures}d consecutive failures): %{error}+v",
+			u.url, u.consecutiveFailures, err)
+	} else {
+		u.consecutiveFailures = 0
+		stats.Incr("snapshot-uploads", tag, stats.T("result", "success"))
+		stats.Observe("snapshot-upload-duration", duration, tag)
+	}
+	stats.Set("snapshot-upload-consecutive-failures", u.consecutiveFailures, tag)
+}
+
+// upload uploads the LDB to every destination at once, and waits for all of
+// them to finish, since the reflector can't be restarted while the LDB is
+// being read. Each destination's failures are tracked independently, and
+// it's only an error if no destination could be uploaded to, so that an
+// outage of one destination doesn't stop the others from being kept up to
+// date.
+func (s *supervisor) upload(ctx context.Context) error {
+	errs := make([]error, len(s.Snapshots))
+	var wg sync.WaitGroup
+	for i, snapshot := range s.Snapshots {
+		wg.Add(1)
+		go func(i int, snapshot archivedSnapshot) {
+			defer wg.Done()
+			start := time.Now()
+			errs[i] = snapshot.Upload(ctx, s.LDBPath)
+			s.uploads[i].record(errs[i], time.Since(start))
+		}(i, snapshot)
+	}
+	wg.Wait()
+
+	var failed int
+	var firstErr error
+	for i, err := range errs {
+		if err != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = errors.Wrapf(err, "upload snapshot to %s", s.uploads[i].url)
+			}
 		}
 	}
+	if failed > 0 && failed == len(errs) {
+		return firstErr
+	}
+	if failed > 0 {
+		stats.Incr("snapshot-partial-uploads")
+	}
 	return nil
 }
 
@@ -129,6 +231,12 @@ func (s *supervisor) Start(ctx context.Context) {
 	events.Log("Starting supervisor")
 	s.reflectorCtl.Start(ctx)
 	defer events.Log("Stopped Supervisor")
+	if s.leader != nil {
+		go s.leader.run(ctx)
+	}
+	if s.statusBind != "" {
+		go s.serveStatus(ctx, s.statusBind)
+	}
 	sleepDur := s.SleepDuration
 	for {
 		// Wait for the reflector to make changes to its LDB before stopping it.  Sometimes
@@ -152,6 +260,9 @@ func (s *supervisor) Start(ctx context.Context) {
 		case <-time.After(sleepDur):
 			// reset to default
 			sleepDur = s.SleepDuration
+		case <-s.trigger:
+			events.Log("Taking a requested snapshot")
+			sleepDur = s.SleepDuration
 		case <-ctx.Done():
 			events.Log("Supervisor exiting because context done (err=%v)", ctx.Err())
 			// Outer context is done, aborting everything
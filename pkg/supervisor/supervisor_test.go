@@ -7,9 +7,11 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
 	ldbpkg "github.com/segmentio/ctlstore/pkg/ldb"
 	"github.com/segmentio/ctlstore/pkg/reflector/fakes"
 	"github.com/stretchr/testify/require"
@@ -177,6 +179,10 @@ func TestSupervisorSnapshotReflectorCtl(t *testing.T) {
 	require.NoError(t, err)
 	err = ldbpkg.EnsureLdbInitialized(ctx, ldb)
 	require.NoError(t, err)
+	_, err = ldb.Exec(
+		fmt.Sprintf("REPLACE INTO %s (id, seq) VALUES(?, ?)", ldbpkg.LDBSeqTableName),
+		ldbpkg.LDBSeqTableID, 100)
+	require.NoError(t, err)
 
 	reflector := fakes.NewFakeReflector()
 	supervisorI, err := SupervisorFromConfig(SupervisorConfig{
@@ -212,3 +218,58 @@ func TestSupervisorSnapshotReflectorCtl(t *testing.T) {
 	time.Sleep(100 * time.Millisecond)
 	require.Equal(t, 0, len(reflector.Events))
 }
+
+type uploadFunc func(ctx context.Context, path string) error
+
+func (f uploadFunc) Upload(ctx context.Context, path string) error {
+	return f(ctx, path)
+}
+
+func TestSupervisorUploadDestinations(t *testing.T) {
+	sup, err := SupervisorFromConfig(SupervisorConfig{
+		SnapshotURL:  "s3://primary-bucket/snapshot.db.gz",
+		SnapshotURLs: []string{"s3://dr-bucket/snapshot.db.gz"},
+	})
+	require.NoError(t, err)
+	s := sup.(*supervisor)
+	require.Len(t, s.Snapshots, 2)
+	require.Equal(t, "dr-bucket", s.Snapshots[1].(*s3Snapshot).Bucket)
+
+	ctx := context.Background()
+	var primaryErr, drErr error
+	var primaryUploads, drUploads int32
+	s.Snapshots = []archivedSnapshot{
+		uploadFunc(func(ctx context.Context, path string) error {
+			atomic.AddInt32(&primaryUploads, 1)
+			return primaryErr
+		}),
+		uploadFunc(func(ctx context.Context, path string) error {
+			// a slow destination is waited for even if another fails
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&drUploads, 1)
+			return drErr
+		}),
+	}
+
+	// a failed destination doesn't fail the snapshot, and is tracked on
+	// its own
+	drErr = errors.New("region unavailable")
+	require.NoError(t, s.upload(ctx))
+	require.EqualValues(t, 1, atomic.LoadInt32(&primaryUploads))
+	require.EqualValues(t, 1, atomic.LoadInt32(&drUploads))
+	require.Equal(t, 0, s.uploads[0].consecutiveFailures)
+	require.Equal(t, 1, s.uploads[1].consecutiveFailures)
+
+	// unless every destination failed
+	primaryErr = errors.New("bucket unavailable")
+	err = s.upload(ctx)
+	require.EqualError(t, err, "upload snapshot to s3://primary-bucket/snapshot.db.gz: bucket unavailable")
+	require.Equal(t, 1, s.uploads[0].consecutiveFailures)
+	require.Equal(t, 2, s.uploads[1].consecutiveFailures)
+
+	// and failures are reset by an upload
+	primaryErr, drErr = nil, nil
+	require.NoError(t, s.upload(ctx))
+	require.Equal(t, 0, s.uploads[0].consecutiveFailures)
+	require.Equal(t, 0, s.uploads[1].consecutiveFailures)
+}
@@ -0,0 +1,98 @@
+package supervisor
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/segmentio/events/v2"
+	"github.com/segmentio/stats/v4"
+
+	"github.com/segmentio/ctlstore/pkg/ldb"
+)
+
+// snapshotValidationError is returned by validateSnapshot, and carries the
+// reason used to tag the validation failure metric.
+type snapshotValidationError struct {
+	reason string
+	err    error
+}
+
+func (e *snapshotValidationError) Error() string {
+	return fmt.Sprintf("snapshot failed validation (%s): %v", e.reason, e.err)
+}
+
+func (e *snapshotValidationError) Cause() error {
+	return e.err
+}
+
+// validateSnapshot checks that the LDB at path is fit to be uploaded as a
+// snapshot: it must pass sqlite's quick_check, and it must have the tables a
+// reflector bootstrapping from it needs to know where in the ledger to
+// resume. The LDB is opened read-only so that validation can never be the
+// thing that modifies it.
+func validateSnapshot(ctx context.Context, path string) error {
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return &snapshotValidationError{"open", err}
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, "PRAGMA quick_check")
+	if err != nil {
+		return &snapshotValidationError{"quick-check", err}
+	}
+	var problems []string
+	for rows.Next() {
+		var result string
+		if err := rows.Scan(&result); err != nil {
+			rows.Close()
+			return &snapshotValidationError{"quick-check", err}
+		}
+		if result != "ok" {
+			problems = append(problems, result)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return &snapshotValidationError{"quick-check", err}
+	}
+	if len(problems) > 0 {
+		return &snapshotValidationError{"quick-check", errors.Errorf("%d problems, first: %s", len(problems), problems[0])}
+	}
+
+	var seqs int
+	err = db.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s", ldb.LDBSeqTableName)).Scan(&seqs)
+	if err != nil {
+		return &snapshotValidationError{"seq-table", err}
+	}
+	if seqs == 0 {
+		return &snapshotValidationError{"seq-table", errors.New("no ledger sequence is tracked")}
+	}
+
+	// a snapshot taken before the first ledger update may have no rows in
+	// the last update table, but the table itself must be readable
+	var updates int
+	err = db.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s", ldb.LDBLastUpdateTableName)).Scan(&updates)
+	if err != nil {
+		return &snapshotValidationError{"last-update-table", err}
+	}
+	return nil
+}
+
+// validateSnapshotWithStats validates the LDB at path, and records the
+// outcome in the snapshot-validation metrics.
+func validateSnapshotWithStats(ctx context.Context, path string) error {
+	if err := validateSnapshot(ctx, path); err != nil {
+		reason := "unknown"
+		if verr, ok := err.(*snapshotValidationError); ok {
+			reason = verr.reason
+		}
+		events.Log("Not uploading snapshot of %{ldb}s: %{error}v", path, err)
+		stats.Incr("snapshot-validation-failures", stats.T("reason", reason))
+		return err
+	}
+	stats.Incr("snapshot-validation-successes")
+	return nil
+}
@@ -0,0 +1,85 @@
+package supervisor
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/ldb"
+)
+
+func TestValidateSnapshot(t *testing.T) {
+	ctx := context.Background()
+	for _, test := range []struct {
+		name   string
+		setup  func(t *testing.T, path string)
+		reason string
+	}{
+		{
+			name: "valid",
+			setup: func(t *testing.T, path string) {
+				db := initLDBForValidation(t, path)
+				defer db.Close()
+				_, err := db.Exec(fmt.Sprintf("REPLACE INTO %s (id, seq) VALUES (?, ?)", ldb.LDBSeqTableName), ldb.LDBSeqTableID, 10)
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "no seq",
+			setup: func(t *testing.T, path string) {
+				db := initLDBForValidation(t, path)
+				db.Close()
+			},
+			reason: "seq-table",
+		},
+		{
+			name: "uninitialized",
+			setup: func(t *testing.T, path string) {
+				db, err := sql.Open("sqlite3", path)
+				require.NoError(t, err)
+				defer db.Close()
+				_, err = db.Exec("CREATE TABLE family___table (x integer primary key)")
+				require.NoError(t, err)
+			},
+			reason: "seq-table",
+		},
+		{
+			name: "not a database",
+			setup: func(t *testing.T, path string) {
+				require.NoError(t, ioutil.WriteFile(path, []byte("this is not an sqlite database, it is a text file"), 0644))
+			},
+			reason: "quick-check",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "ldb.db")
+			test.setup(t, path)
+
+			err = validateSnapshot(ctx, path)
+			if test.reason == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			verr, ok := err.(*snapshotValidationError)
+			require.True(t, ok, "unexpected error: %v", err)
+			require.Equal(t, test.reason, verr.reason)
+		})
+	}
+}
+
+func initLDBForValidation(t *testing.T, path string) *sql.DB {
+	db, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	require.NoError(t, ldb.EnsureLdbInitialized(context.Background(), db))
+	return db
+}
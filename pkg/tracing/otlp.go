@@ -0,0 +1,186 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/segmentio/errors-go"
+	"github.com/segmentio/events/v2"
+	"github.com/segmentio/stats/v4"
+)
+
+const (
+	defaultOTLPTimeout       = 5 * time.Second
+	defaultOTLPFlushInterval = 5 * time.Second
+	defaultOTLPMaxQueued     = 2048
+)
+
+// OTLPConfig configures export of spans to an OpenTelemetry collector using
+// OTLP over HTTP with JSON encoding.
+type OTLPConfig struct {
+	// Endpoint is the full URL spans are posted to, usually
+	// http://<collector>:4318/v1/traces.
+	Endpoint string
+	// Headers are added to every export request, e.g. for authentication.
+	Headers map[string]string
+	// ServiceName is reported as the service.name resource attribute.
+	ServiceName string
+	// Timeout bounds each export request. Defaults to 5s.
+	Timeout time.Duration
+	// FlushInterval is how often queued spans are exported. Defaults to 5s.
+	FlushInterval time.Duration
+	// MaxQueued is the number of spans queued between exports, beyond which
+	// spans are dropped. Defaults to 2048.
+	MaxQueued int
+}
+
+// OTLPExporter is an Exporter which queues spans in memory and exports them
+// to an OpenTelemetry collector in batches.
+type OTLPExporter struct {
+	config OTLPConfig
+	client *http.Client
+
+	mut   sync.Mutex
+	spans []*Span
+}
+
+// NewOTLPExporter builds an OTLPExporter. Run must be called for spans to be
+// exported.
+func NewOTLPExporter(config OTLPConfig) *OTLPExporter {
+	if config.Timeout <= 0 {
+		config.Timeout = defaultOTLPTimeout
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaultOTLPFlushInterval
+	}
+	if config.MaxQueued <= 0 {
+		config.MaxQueued = defaultOTLPMaxQueued
+	}
+	return &OTLPExporter{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+func (e *OTLPExporter) Export(span *Span) {
+	e.mut.Lock()
+	defer e.mut.Unlock()
+	if len(e.spans) >= e.config.MaxQueued {
+		stats.Incr("tracing.spans_dropped")
+		return
+	}
+	e.spans = append(e.spans, span)
+}
+
+// Run exports queued spans every flush interval until the context is
+// done, then exports whatever remains.
+func (e *OTLPExporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.config.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			e.Flush()
+			return
+		case <-ticker.C:
+			e.Flush()
+		}
+	}
+}
+
+// Flush exports the queued spans.
+func (e *OTLPExporter) Flush() {
+	if err := e.export(context.Background()); err != nil {
+		events.Log("Failed exporting OTLP spans: %{error}+v", err)
+	}
+}
+
+func (e *OTLPExporter) export(ctx context.Context) error {
+	e.mut.Lock()
+	spans := e.spans
+	e.spans = nil
+	e.mut.Unlock()
+	if len(spans) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(e.payload(spans))
+	if err != nil {
+		return errors.Wrap(err, "encode otlp payload")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "build otlp request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.config.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "post otlp spans")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return errors.Errorf("otlp collector responded with %s", resp.Status)
+	}
+	stats.Add("tracing.spans_exported", len(spans))
+	return nil
+}
+
+// payload builds an ExportTraceServiceRequest in its proto3 JSON form, in
+// which trace and span IDs are hex encoded and 64 bit integers are encoded
+// as strings.
+func (e *OTLPExporter) payload(spans []*Span) interface{} {
+	type object = map[string]interface{}
+
+	res := make([]object, 0, len(spans))
+	for _, s := range spans {
+		s.mu.Lock()
+		attrs := make([]object, 0, len(s.Attributes))
+		for _, a := range s.Attributes {
+			attrs = append(attrs, object{"key": a.Key, "value": object{"stringValue": a.Value}})
+		}
+		span := object{
+			"traceId":           s.Context.TraceID.String(),
+			"spanId":            s.Context.SpanID.String(),
+			"name":              s.Name,
+			"kind":              1, // internal
+			"startTimeUnixNano": strconv.FormatInt(s.Start.UnixNano(), 10),
+			"endTimeUnixNano":   strconv.FormatInt(s.End.UnixNano(), 10),
+			"attributes":        attrs,
+		}
+		if s.Parent.IsValid() {
+			span["parentSpanId"] = s.Parent.String()
+		}
+		if s.server {
+			span["kind"] = 2 // server
+		}
+		if s.Err != "" {
+			span["status"] = object{"code": 2, "message": s.Err} // error
+		}
+		s.mu.Unlock()
+		res = append(res, span)
+	}
+
+	serviceName := e.config.ServiceName
+	if serviceName == "" {
+		serviceName = "ctlstore"
+	}
+	return object{
+		"resourceSpans": []object{{
+			"resource": object{
+				"attributes": []object{{"key": "service.name", "value": object{"stringValue": serviceName}}},
+			},
+			"scopeSpans": []object{{
+				"scope": object{"name": "github.com/segmentio/ctlstore"},
+				"spans": res,
+			}},
+		}},
+	}
+}
@@ -0,0 +1,81 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOTLPExporter(t *testing.T) {
+	var requests []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.Equal(t, "secret", r.Header.Get("X-Api-Key"))
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests = append(requests, body)
+	}))
+	defer srv.Close()
+
+	exp := NewOTLPExporter(OTLPConfig{
+		Endpoint:    srv.URL,
+		Headers:     map[string]string{"X-Api-Key": "secret"},
+		ServiceName: "test-app",
+		MaxQueued:   2,
+	})
+	SetExporter(exp)
+	defer SetExporter(nil)
+
+	ctx, parent := Start(context.Background(), "request", String("http.method", "POST"))
+	_, child := Start(ctx, "mutate")
+	child.Finish(errors.New("boom"))
+	parent.Finish(nil)
+	// dropped, as the queue is full
+	_, dropped := Start(context.Background(), "request")
+	dropped.Finish(nil)
+	require.NoError(t, exp.export(context.Background()))
+
+	// nothing new to export
+	require.NoError(t, exp.export(context.Background()))
+	require.Len(t, requests, 1)
+
+	resource := requests[0]["resourceSpans"].([]interface{})[0].(map[string]interface{})
+	require.EqualValues(t, []interface{}{
+		map[string]interface{}{"key": "service.name", "value": map[string]interface{}{"stringValue": "test-app"}},
+	}, resource["resource"].(map[string]interface{})["attributes"])
+	spans := resource["scopeSpans"].([]interface{})[0].(map[string]interface{})["spans"].([]interface{})
+	require.Len(t, spans, 2)
+
+	c := spans[0].(map[string]interface{})
+	require.Equal(t, "mutate", c["name"])
+	require.Equal(t, parent.Context.TraceID.String(), c["traceId"])
+	require.Equal(t, parent.Context.SpanID.String(), c["parentSpanId"])
+	require.EqualValues(t, 1, c["kind"])
+	require.EqualValues(t, map[string]interface{}{"code": 2.0, "message": "boom"}, c["status"])
+
+	p := spans[1].(map[string]interface{})
+	require.Equal(t, "request", p["name"])
+	require.NotContains(t, p, "parentSpanId")
+	require.NotContains(t, p, "status")
+	require.EqualValues(t, 2, p["kind"])
+	require.EqualValues(t, []interface{}{
+		map[string]interface{}{"key": "http.method", "value": map[string]interface{}{"stringValue": "POST"}},
+	}, p["attributes"])
+}
+
+func TestOTLPExporterError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	exp := NewOTLPExporter(OTLPConfig{Endpoint: srv.URL})
+	_, span := Start(context.Background(), "request")
+	exp.Export(span)
+	require.Error(t, exp.export(context.Background()))
+}
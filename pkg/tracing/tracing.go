@@ -0,0 +1,210 @@
+// Package tracing records spans of work, such as the requests the executive
+// serves, and propagates their trace IDs using the W3C traceparent header so
+// that they join the traces of the callers.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceparentHeader is the W3C trace context header.
+const TraceparentHeader = "traceparent"
+
+type (
+	TraceID [16]byte
+	SpanID  [8]byte
+)
+
+func (id TraceID) String() string { return hex.EncodeToString(id[:]) }
+func (id SpanID) String() string  { return hex.EncodeToString(id[:]) }
+
+func (id TraceID) IsValid() bool { return id != TraceID{} }
+func (id SpanID) IsValid() bool  { return id != SpanID{} }
+
+// SpanContext identifies a span within its trace.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// ParseTraceparent parses a traceparent header. It returns false if the
+// header is missing or malformed, in which case a new trace should be
+// started.
+func ParseTraceparent(header string) (SpanContext, bool) {
+	// version-traceid-spanid-flags, e.g.
+	// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return SpanContext{}, false
+	}
+	if parts[0] == "00" && len(parts) != 4 {
+		return SpanContext{}, false
+	}
+	var sc SpanContext
+	var flags [1]byte
+	if !decodeHex(sc.TraceID[:], parts[1]) || !decodeHex(sc.SpanID[:], parts[2]) || !decodeHex(flags[:], parts[3]) {
+		return SpanContext{}, false
+	}
+	if !sc.TraceID.IsValid() || !sc.SpanID.IsValid() {
+		return SpanContext{}, false
+	}
+	sc.Sampled = flags[0]&1 == 1
+	return sc, true
+}
+
+func decodeHex(dst []byte, s string) bool {
+	if len(s) != 2*len(dst) || strings.ToLower(s) != s {
+		return false
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err == nil
+}
+
+// Traceparent formats the span context as a traceparent header.
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", sc.TraceID, sc.SpanID, flags)
+}
+
+// Attribute annotates a span.
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// String returns an Attribute.
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Span is an operation within a trace. It's exported once it ends, if it's
+// sampled and an exporter has been set.
+type Span struct {
+	Name       string
+	Context    SpanContext
+	Parent     SpanID // invalid for the root span of a trace
+	Start      time.Time
+	End        time.Time
+	Attributes []Attribute
+	// Err is the error the operation failed with, if any.
+	Err string
+
+	mu     sync.Mutex
+	ended  bool
+	server bool // the first span of this process within the trace
+}
+
+// SetAttributes adds attributes to the span.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	s.mu.Lock()
+	s.Attributes = append(s.Attributes, attrs...)
+	s.mu.Unlock()
+}
+
+// Finish ends the span, recording err if it's not nil. Only the first call
+// has any effect.
+func (s *Span) Finish(err error) {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.End = time.Now()
+	if err != nil {
+		s.Err = err.Error()
+	}
+	s.mu.Unlock()
+
+	if exp := currentExporter(); exp != nil && s.Context.Sampled {
+		exp.Export(s)
+	}
+}
+
+type spanKey struct{}
+type remoteKey struct{}
+
+// SpanFromContext returns the span of the context, or nil.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// ContextWithRemote returns a context whose spans continue the trace of a
+// remote caller, such as one parsed with ParseTraceparent.
+func ContextWithRemote(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+// TraceIDFromContext returns the trace ID of the context's span, if any.
+func TraceIDFromContext(ctx context.Context) (TraceID, bool) {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.Context.TraceID, true
+	}
+	return TraceID{}, false
+}
+
+// Start starts a span which is a child of the context's span, or of the
+// remote span the context continues, or otherwise the root of a new trace.
+// New traces are sampled if an exporter has been set. The span must be
+// finished.
+func Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, *Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	span := &Span{
+		Name:       name,
+		Start:      time.Now(),
+		Attributes: attrs,
+		server:     true,
+	}
+	if parent := SpanFromContext(ctx); parent != nil {
+		span.Context.TraceID = parent.Context.TraceID
+		span.Context.Sampled = parent.Context.Sampled
+		span.Parent = parent.Context.SpanID
+		span.server = false
+	} else if remote, ok := ctx.Value(remoteKey{}).(SpanContext); ok {
+		span.Context.TraceID = remote.TraceID
+		span.Context.Sampled = remote.Sampled
+		span.Parent = remote.SpanID
+	} else {
+		rand.Read(span.Context.TraceID[:])
+		span.Context.Sampled = currentExporter() != nil
+	}
+	rand.Read(span.Context.SpanID[:])
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// Exporter sends finished spans to a tracing backend.
+type Exporter interface {
+	Export(span *Span)
+}
+
+var (
+	exporterMu sync.RWMutex
+	exporter   Exporter
+)
+
+// SetExporter sets the exporter that sampled spans are exported to. Spans
+// aren't exported until one is set.
+func SetExporter(exp Exporter) {
+	exporterMu.Lock()
+	exporter = exp
+	exporterMu.Unlock()
+}
+
+func currentExporter() Exporter {
+	exporterMu.RLock()
+	defer exporterMu.RUnlock()
+	return exporter
+}
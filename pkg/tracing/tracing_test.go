@@ -0,0 +1,103 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordingExporter struct {
+	spans []*Span
+}
+
+func (e *recordingExporter) Export(span *Span) {
+	e.spans = append(e.spans, span)
+}
+
+func TestParseTraceparent(t *testing.T) {
+	for _, test := range []struct {
+		header  string
+		ok      bool
+		sampled bool
+	}{
+		{header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", ok: true, sampled: true},
+		{header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", ok: true},
+		// future versions may append fields
+		{header: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-xyz", ok: true, sampled: true},
+		{header: ""},
+		{header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-xyz"},
+		{header: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{header: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		{header: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01"},
+		{header: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01"},
+		{header: "00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01"},
+	} {
+		t.Run(test.header, func(t *testing.T) {
+			sc, ok := ParseTraceparent(test.header)
+			require.Equal(t, test.ok, ok)
+			if !ok {
+				return
+			}
+			require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+			require.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+			require.Equal(t, test.sampled, sc.Sampled)
+		})
+	}
+
+	sc, ok := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.True(t, ok)
+	require.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sc.Traceparent())
+}
+
+func TestStart(t *testing.T) {
+	exp := &recordingExporter{}
+	SetExporter(exp)
+	defer SetExporter(nil)
+
+	remote, ok := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.True(t, ok)
+	ctx := ContextWithRemote(context.Background(), remote)
+
+	ctx, parent := Start(ctx, "request", String("http.method", "POST"))
+	require.Equal(t, remote.TraceID, parent.Context.TraceID)
+	require.Equal(t, remote.SpanID, parent.Parent)
+	require.True(t, parent.server)
+
+	_, child := Start(ctx, "mutate")
+	require.Equal(t, remote.TraceID, child.Context.TraceID)
+	require.Equal(t, parent.Context.SpanID, child.Parent)
+	require.NotEqual(t, parent.Context.SpanID, child.Context.SpanID)
+	require.False(t, child.server)
+
+	traceID, ok := TraceIDFromContext(ctx)
+	require.True(t, ok)
+	require.Equal(t, remote.TraceID, traceID)
+
+	child.Finish(errors.New("boom"))
+	child.Finish(nil)
+	parent.Finish(nil)
+	require.Equal(t, []*Span{child, parent}, exp.spans)
+	require.Equal(t, "boom", child.Err)
+	require.False(t, child.End.Before(child.Start))
+}
+
+func TestStartNewTrace(t *testing.T) {
+	_, ok := TraceIDFromContext(context.Background())
+	require.False(t, ok)
+
+	// new traces are only sampled when there's an exporter
+	_, span := Start(context.Background(), "request")
+	require.True(t, span.Context.TraceID.IsValid())
+	require.False(t, span.Parent.IsValid())
+	require.False(t, span.Context.Sampled)
+
+	exp := &recordingExporter{}
+	SetExporter(exp)
+	defer SetExporter(nil)
+	_, span = Start(context.Background(), "request")
+	require.True(t, span.Context.Sampled)
+	span.Finish(nil)
+	require.Len(t, exp.spans, 1)
+}
@@ -0,0 +1,22 @@
+package ctlstore
+
+import (
+	"github.com/segmentio/ctlstore/pkg/ldb"
+)
+
+// WithPragmas makes the reader open its LDB with the given SQLite pragmas
+// instead of ldb.DefaultPragmas. Zero values leave SQLite's own defaults in
+// place.
+func WithPragmas(p ldb.Pragmas) ReaderOption {
+	return func(reader *LDBReader) {
+		reader.pragmas = &p
+	}
+}
+
+// ldbPragmas returns the pragmas the reader opens its LDB with.
+func (reader *LDBReader) ldbPragmas() ldb.Pragmas {
+	if reader.pragmas == nil {
+		return ldb.DefaultPragmas
+	}
+	return *reader.pragmas
+}
@@ -0,0 +1,155 @@
+package ctlstore
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/segmentio/errors-go"
+
+	"github.com/segmentio/ctlstore/pkg/globalstats"
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+// ErrInvalidQuery is the cause of the errors returned for a PrefixQuery
+// which doesn't fit the table, such as one naming a field it doesn't have.
+var ErrInvalidQuery = errors.New("invalid query")
+
+// PrefixQuery narrows what QueryRowsByKeyPrefix reads, so that only the
+// fields and rows which are needed are read from the LDB.
+type PrefixQuery struct {
+	// Fields are the fields to read. Every field is read if it's empty.
+	Fields []string
+	// Where maps non-key fields to the values they must equal. Key fields
+	// are matched with the key prefix instead.
+	Where map[string]interface{}
+}
+
+// QueryRowsByKeyPrefix is like GetRowsByKeyPrefix, but only reads the
+// query's fields of the rows which match its filters, which are applied by
+// the LDB. Unlike GetRowsByKeyPrefix, it doesn't fall back to a sidecar.
+func (reader *LDBReader) QueryRowsByKeyPrefix(ctx context.Context, familyName string, tableName string, query PrefixQuery, key ...interface{}) (res *Rows, err error) {
+	err = reader.retryBusy(ctx, familyName, tableName, func() (err error) {
+		res, err = reader.queryRowsByKeyPrefix(ctx, familyName, tableName, query, key)
+		return err
+	})
+	return res, err
+}
+
+func (reader *LDBReader) queryRowsByKeyPrefix(ctx context.Context, familyName string, tableName string, query PrefixQuery, key []interface{}) (res *Rows, err error) {
+	ctx, cancel := reader.queryContext(ctx)
+	defer func() {
+		if err != nil || res == nil {
+			cancel()
+			err = observeQueryErr(ctx, err, familyName, tableName)
+			return
+		}
+		// the rows are read after we return, so the context lives until
+		// they're closed
+		res.cancel = cancel
+	}()
+	start := time.Now()
+	defer func() {
+		globalstats.Observe("query_rows_by_key_prefix", time.Now().Sub(start),
+			reader.caller.tags(familyName, tableName)...)
+	}()
+
+	reader.mu.RLock()
+	defer reader.mu.RUnlock()
+	ldbTable, pk, err := reader.keyedTable(ctx, familyName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) > len(pk.Fields) {
+		return nil, errors.New("too many keys supplied for table's primary key")
+	}
+	if err = convertKeyBeforeQuery(pk, key); err != nil {
+		return nil, err
+	}
+	fieldTypes, err := reader.fieldTypes(ctx, ldbTable)
+	if err != nil {
+		return nil, err
+	}
+
+	selectExpr := "*"
+	if len(query.Fields) > 0 {
+		fields := make([]string, 0, len(query.Fields))
+		for _, name := range query.Fields {
+			name = strings.ToLower(name)
+			if _, ok := fieldTypes[name]; !ok {
+				return nil, errors.Wrapf(ErrInvalidQuery, "unknown field %s", name)
+			}
+			fields = append(fields, name)
+		}
+		selectExpr = strings.Join(fields, ", ")
+	}
+
+	qs := keyPrefixQuery(selectExpr, pk, ldbTable, len(key))
+	args := append([]interface{}{}, key...)
+	where := make([]string, 0, len(query.Where))
+	for name := range query.Where {
+		where = append(where, name)
+	}
+	sort.Strings(where)
+	for i, name := range where {
+		value := query.Where[name]
+		name = strings.ToLower(name)
+		ft, ok := fieldTypes[name]
+		if !ok {
+			return nil, errors.Wrapf(ErrInvalidQuery, "unknown field %s", name)
+		}
+		for _, kf := range pk.Fields {
+			if kf.Name == name {
+				return nil, errors.Wrapf(ErrInvalidQuery, "%s is a key field, which is matched by the key prefix", name)
+			}
+		}
+		if s, ok := value.(string); ok && (ft == schema.FTBinary || ft == schema.FTByteString) {
+			// as with keys, so that the types match
+			value = []byte(s)
+		}
+		if i == 0 && len(key) == 0 {
+			qs += " WHERE "
+		} else {
+			qs += " AND "
+		}
+		qs += name + " = ?"
+		args = append(args, value)
+	}
+
+	if len(key) == 0 {
+		reader.caller.incr("full-table-scans", familyName, tableName)
+	}
+	rows, err := reader.Db.QueryContext(ctx, qs, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "query rows error")
+	}
+	cols, err := schema.DBColumnMetaFromRows(rows)
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+	return &Rows{rows: rows, cols: cols, limiter: newResultLimiter(reader.resultLimits, familyName, tableName, len(cols))}, nil
+}
+
+// fieldTypes returns the types of the table's fields, keyed by their
+// lowercased names. Fields whose SQL types don't map to a field type are
+// still present, with the zero type.
+func (reader *LDBReader) fieldTypes(ctx context.Context, ldbTable string) (map[string]schema.FieldType, error) {
+	const qs = "SELECT name,type FROM pragma_table_info(?)"
+	rows, err := reader.Db.QueryContext(ctx, qs, ldbTable)
+	if err != nil {
+		return nil, errors.Wrap(err, "query pragma_table_info error")
+	}
+	defer rows.Close()
+	res := map[string]schema.FieldType{}
+	for rows.Next() {
+		var name, sqlType string
+		if err := rows.Scan(&name, &sqlType); err != nil {
+			return nil, errors.Wrap(err, "scan pragma_table_info error")
+		}
+		ft, _ := schema.SqlTypeToFieldType(sqlType)
+		res[strings.ToLower(name)] = ft
+	}
+	return res, errors.Wrap(rows.Err(), "read pragma_table_info error")
+}
@@ -0,0 +1,55 @@
+package ctlstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/segmentio/errors-go"
+
+	"github.com/segmentio/ctlstore/pkg/globalstats"
+)
+
+// DefaultQueryTimeout bounds reads made by a reader configured with
+// WithQueryTimeout when the caller's context has no earlier deadline.
+const DefaultQueryTimeout = 5 * time.Second
+
+// WithQueryTimeout makes the reader honor the deadline and cancellation of
+// the context passed to its read methods, which it otherwise ignores. Reads
+// are additionally bounded by timeout, so that a context without a deadline
+// can't make them unbounded. A timeout of zero uses DefaultQueryTimeout.
+//
+// Reads that time out return an error whose cause is
+// context.DeadlineExceeded, and are counted by the query-timeouts metric.
+func WithQueryTimeout(timeout time.Duration) ReaderOption {
+	if timeout <= 0 {
+		timeout = DefaultQueryTimeout
+	}
+	return func(reader *LDBReader) {
+		reader.queryTimeout = timeout
+	}
+}
+
+// queryContext returns the context a read should be made with. Unless the
+// reader was configured with WithQueryTimeout, that's discardContext().
+func (reader *LDBReader) queryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if reader.queryTimeout == 0 || ctx == nil {
+		return discardContext(), func() {}
+	}
+	return context.WithTimeout(ctx, reader.queryTimeout)
+}
+
+// observeQueryErr counts err if it's the result of the read's context
+// expiring, and makes sure that the context error is its cause, since the
+// sqlite driver reports interrupted queries with errors of its own.
+func observeQueryErr(ctx context.Context, err error, familyName, tableName string) error {
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		globalstats.Incr("query-timeouts", familyName, tableName)
+	}
+	if errors.Cause(err) == ctx.Err() {
+		return err
+	}
+	return errors.Wrap(ctx.Err(), err.Error())
+}
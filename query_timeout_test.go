@@ -0,0 +1,72 @@
+package ctlstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/segmentio/errors-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/ldb"
+)
+
+func TestWithQueryTimeout(t *testing.T) {
+	db, teardown := ldb.LDBForTest(t)
+	defer teardown()
+	_, err := db.Exec(initSQLForReadKeyByRow)
+	require.NoError(t, err)
+
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	t.Run("context ignored by default", func(t *testing.T) {
+		reader := NewLDBReaderFromDB(db)
+		var out testKVStruct
+		found, err := reader.GetRowByKey(expired, &out, "foo", "bar", "foo")
+		require.NoError(t, err)
+		require.True(t, found)
+	})
+
+	t.Run("expired context", func(t *testing.T) {
+		reader := NewLDBReaderFromDB(db)
+		WithQueryTimeout(time.Minute)(reader)
+
+		var out testKVStruct
+		_, err := reader.GetRowByKey(expired, &out, "foo", "bar", "foo")
+		require.Equal(t, context.DeadlineExceeded, errors.Cause(err))
+
+		_, err = reader.GetRowsByKeyPrefix(expired, "foo", "multirow", "a")
+		require.Equal(t, context.DeadlineExceeded, errors.Cause(err))
+
+		_, err = reader.GetLastSequence(expired)
+		require.Equal(t, context.DeadlineExceeded, errors.Cause(err))
+	})
+
+	t.Run("rows outlive the call", func(t *testing.T) {
+		reader := NewLDBReaderFromDB(db)
+		WithQueryTimeout(time.Minute)(reader)
+
+		rows, err := reader.GetRowsByKeyPrefix(context.Background(), "foo", "multirow", "a")
+		require.NoError(t, err)
+		defer rows.Close()
+		n := 0
+		for rows.Next() {
+			n++
+		}
+		require.NoError(t, rows.Err())
+		require.Equal(t, 2, n)
+	})
+
+	t.Run("default timeout", func(t *testing.T) {
+		reader := NewLDBReaderFromDB(db)
+		WithQueryTimeout(0)(reader)
+		require.Equal(t, DefaultQueryTimeout, reader.queryTimeout)
+
+		ctx, cancel := reader.queryContext(context.Background())
+		defer cancel()
+		deadline, ok := ctx.Deadline()
+		require.True(t, ok)
+		require.WithinDuration(t, time.Now().Add(DefaultQueryTimeout), deadline, time.Second)
+	})
+}
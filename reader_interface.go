@@ -0,0 +1,22 @@
+package ctlstore
+
+import (
+	"context"
+	"time"
+)
+
+// ReaderInterface is the read API of an LDBReader. Consumers can depend on
+// it instead of *LDBReader so that the fake in the fakes package can stand
+// in for the LDB in their unit tests.
+//
+//counterfeiter:generate -o fakes/reader_interface.go . ReaderInterface
+type ReaderInterface interface {
+	RowRetriever
+	Ping(ctx context.Context) bool
+	GetLedgerLatency(ctx context.Context) (time.Duration, error)
+}
+
+var (
+	_ ReaderInterface = (*LDBReader)(nil)
+	_ ReaderInterface = (*LDBFailoverReader)(nil)
+)
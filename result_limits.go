@@ -0,0 +1,89 @@
+package ctlstore
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/segmentio/errors-go"
+
+	"github.com/segmentio/ctlstore/pkg/globalstats"
+)
+
+// ErrResultLimitExceeded is the cause of the error returned by Rows.Err
+// when reading rows which exceed the reader's ResultLimits.
+var ErrResultLimitExceeded = errors.New("result limit exceeded")
+
+// ResultLimits bound the rows that a reader's GetRowsByKeyPrefix and
+// QueryRowsByKeyPrefix read from the LDB, so that a misconfigured consumer
+// can't scan entire tables on a shared host. Zero doesn't limit them.
+type ResultLimits struct {
+	// MaxRows is the most rows that a query may return
+	MaxRows int
+	// MaxBytes is the most bytes of values that a query may return, as
+	// stored in the LDB
+	MaxBytes int64
+}
+
+// WithResultLimits applies the limits to the rows read by the reader. A
+// query which exceeds them is aborted: Rows.Next returns false, Rows.Err
+// returns an error whose cause is ErrResultLimitExceeded, and the
+// result-limit-exceeded metric is counted.
+func WithResultLimits(limits ResultLimits) ReaderOption {
+	return func(reader *LDBReader) {
+		reader.resultLimits = limits
+	}
+}
+
+// resultLimiter tracks the size of the rows read so far
+type resultLimiter struct {
+	limits     ResultLimits
+	familyName string
+	tableName  string
+	rows       int
+	bytes      int64
+	values     [][]byte // reused between rows to measure them
+	dest       []interface{}
+}
+
+func newResultLimiter(limits ResultLimits, familyName, tableName string, columnCount int) *resultLimiter {
+	if limits == (ResultLimits{}) {
+		return nil
+	}
+	l := &resultLimiter{limits: limits, familyName: familyName, tableName: tableName}
+	if limits.MaxBytes > 0 {
+		l.values = make([][]byte, columnCount)
+		l.dest = make([]interface{}, columnCount)
+		for i := range l.dest {
+			l.dest[i] = &l.values[i]
+		}
+	}
+	return l
+}
+
+// next counts the current row against the limits.
+func (l *resultLimiter) next(rows *sql.Rows) error {
+	l.rows++
+	if l.limits.MaxRows > 0 && l.rows > l.limits.MaxRows {
+		return l.exceeded(fmt.Sprintf("more than %d rows", l.limits.MaxRows))
+	}
+	if l.limits.MaxBytes > 0 {
+		// rows may be scanned more than once, so this doesn't affect the
+		// caller's scan. sql.RawBytes would avoid copying the values, but
+		// holds the rows until the next call to Next.
+		if err := rows.Scan(l.dest...); err != nil {
+			return errors.Wrap(err, "measure row")
+		}
+		for _, b := range l.values {
+			l.bytes += int64(len(b))
+		}
+		if l.bytes > l.limits.MaxBytes {
+			return l.exceeded(fmt.Sprintf("more than %d bytes", l.limits.MaxBytes))
+		}
+	}
+	return nil
+}
+
+func (l *resultLimiter) exceeded(reason string) error {
+	globalstats.Incr("result-limit-exceeded", l.familyName, l.tableName)
+	return errors.Wrapf(ErrResultLimitExceeded, "reading %s___%s returned %s", l.familyName, l.tableName, reason)
+}
@@ -0,0 +1,75 @@
+package ctlstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/segmentio/errors-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/ldb"
+)
+
+func TestWithResultLimits(t *testing.T) {
+	db, teardown := ldb.LDBForTest(t)
+	defer teardown()
+	_, err := db.Exec(initSQLForReadKeyByRow)
+	require.NoError(t, err)
+
+	type multirow struct {
+		K1  string `ctlstore:"k1"`
+		K2  string `ctlstore:"k2"`
+		Val int    `ctlstore:"val"`
+	}
+
+	// each row of the "a" prefix is 4 bytes, e.g. "a", "A" and "42"
+	for _, test := range []struct {
+		name    string
+		limits  ResultLimits
+		want    []multirow
+		wantErr bool
+	}{
+		{
+			name: "no limits",
+			want: []multirow{{"a", "A", 42}, {"a", "B", 43}},
+		},
+		{
+			name:   "within limits",
+			limits: ResultLimits{MaxRows: 2, MaxBytes: 8},
+			want:   []multirow{{"a", "A", 42}, {"a", "B", 43}},
+		},
+		{
+			name:    "too many rows",
+			limits:  ResultLimits{MaxRows: 1},
+			want:    []multirow{{"a", "A", 42}},
+			wantErr: true,
+		},
+		{
+			name:    "too many bytes",
+			limits:  ResultLimits{MaxBytes: 5},
+			want:    []multirow{{"a", "A", 42}},
+			wantErr: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			reader := NewLDBReaderFromDB(db)
+			WithResultLimits(test.limits)(reader)
+
+			rows, err := reader.GetRowsByKeyPrefix(context.Background(), "foo", "multirow", "a")
+			require.NoError(t, err)
+			defer rows.Close()
+			var got []multirow
+			for rows.Next() {
+				var row multirow
+				require.NoError(t, rows.Scan(&row))
+				got = append(got, row)
+			}
+			require.Equal(t, test.want, got)
+			if test.wantErr {
+				require.Equal(t, ErrResultLimitExceeded, errors.Cause(rows.Err()))
+			} else {
+				require.NoError(t, rows.Err())
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package ctlstore
 
 import (
 	"database/sql"
+	"errors"
 
 	"github.com/segmentio/ctlstore/pkg/scanfunc"
 	"github.com/segmentio/ctlstore/pkg/schema"
@@ -15,14 +16,64 @@ import (
 type Rows struct {
 	rows *sql.Rows
 	cols []schema.DBColumnMeta
+	// fallback holds the rows when they were read from a sidecar instead
+	// of the LDB. See WithSidecarFallback.
+	fallback    []map[string]interface{}
+	fallbackIdx int
+	// cancel releases the context the rows are read with, if any. See
+	// WithQueryTimeout.
+	cancel func()
+	// limiter aborts reading rows which exceed the reader's limits, with
+	// err. See WithResultLimits.
+	limiter *resultLimiter
+	err     error
+}
+
+// ColumnInfo describes a column of the result set returned by Rows.
+type ColumnInfo struct {
+	Name string
+	// DatabaseType is the column type as reported by the LDB, e.g. VARCHAR.
+	DatabaseType string
+	// FieldType is the ctlstore field type corresponding to DatabaseType. It
+	// is zero if the database type is not one that ctlstore generates.
+	FieldType schema.FieldType
+}
+
+// Columns returns metadata about the columns in the result set, in the
+// same order as the values returned by RawScan.
+func (r *Rows) Columns() []ColumnInfo {
+	res := make([]ColumnInfo, 0, len(r.cols))
+	for _, col := range r.cols {
+		ft, _ := schema.SqlTypeToFieldType(col.Type)
+		res = append(res, ColumnInfo{
+			Name:         col.Name,
+			DatabaseType: col.Type,
+			FieldType:    ft,
+		})
+	}
+	return res
 }
 
 // Next returns true if there's another row available.
 func (r *Rows) Next() bool {
-	if r.rows == nil {
+	if r.fallback != nil {
+		r.fallbackIdx++
+		return r.fallbackIdx < len(r.fallback)
+	}
+	if r.rows == nil || r.err != nil {
 		return false
 	}
-	return r.rows.Next()
+	if !r.rows.Next() {
+		return false
+	}
+	if r.limiter != nil {
+		if err := r.limiter.next(r.rows); err != nil {
+			r.err = err
+			r.rows.Close()
+			return false
+		}
+	}
+	return true
 }
 
 // Err returns any error that could have been caused during
@@ -30,6 +81,9 @@ func (r *Rows) Next() bool {
 // must always check Err() to see if that's why iteration
 // failed.
 func (r *Rows) Err() error {
+	if r.err != nil {
+		return r.err
+	}
 	if r.rows == nil {
 		return nil
 	}
@@ -38,6 +92,9 @@ func (r *Rows) Err() error {
 
 // Close closes the underlying *sql.Rows.
 func (r *Rows) Close() error {
+	if r.cancel != nil {
+		defer r.cancel()
+	}
 	if r.rows == nil {
 		return nil
 	}
@@ -48,6 +105,12 @@ func (r *Rows) Close() error {
 // The target must be either a pointer to a struct, or a
 // map[string]interface{}.
 func (r *Rows) Scan(target interface{}) error {
+	if r.fallback != nil {
+		if r.fallbackIdx < 0 || r.fallbackIdx >= len(r.fallback) {
+			return sql.ErrNoRows
+		}
+		return decodeFallbackRow(r.fallback[r.fallbackIdx], target)
+	}
 	if r.rows == nil {
 		return sql.ErrNoRows
 	}
@@ -57,3 +120,25 @@ func (r *Rows) Scan(target interface{}) error {
 	}
 	return scanFunc(r.rows)
 }
+
+// RawScan returns the values of the current row without deserializing
+// them into a target, ordered the same as Columns(). This is useful for
+// tools which handle arbitrary tables and don't have a struct definition
+// for them.
+func (r *Rows) RawScan() ([]interface{}, error) {
+	if r.fallback != nil {
+		return nil, errors.New("raw scans are not supported for rows read from a sidecar")
+	}
+	if r.rows == nil {
+		return nil, sql.ErrNoRows
+	}
+	values := make([]interface{}, len(r.cols))
+	ptrs := make([]interface{}, len(r.cols))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	if err := r.rows.Scan(ptrs...); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
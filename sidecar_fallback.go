@@ -0,0 +1,177 @@
+package ctlstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/segmentio/errors-go"
+	"github.com/segmentio/stats/v4"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+	"github.com/segmentio/ctlstore/pkg/globalstats"
+	"github.com/segmentio/ctlstore/pkg/scanfunc"
+)
+
+const defaultSidecarFallbackTimeout = 5 * time.Second
+
+// ReaderOption configures an LDBReader.
+type ReaderOption func(*LDBReader)
+
+// WithSidecarFallback makes the reader read tables that don't exist in its
+// LDB from the ctlstore sidecar at sidecarURL instead of failing with
+// ErrTableNotFound. This is useful when the local reflector only
+// materializes some families. Rows read from the sidecar are decoded from
+// JSON, so binary columns read into a map[string]interface{} are base64
+// encoded strings rather than []byte.
+func WithSidecarFallback(sidecarURL string) ReaderOption {
+	return func(reader *LDBReader) {
+		reader.fallback = &sidecarFallback{
+			url:    strings.TrimSuffix(sidecarURL, "/"),
+			client: &http.Client{Timeout: defaultSidecarFallbackTimeout},
+		}
+	}
+}
+
+// sidecarFallback reads rows through the sidecar HTTP API.
+type sidecarFallback struct {
+	url    string
+	client *http.Client
+}
+
+// sidecarKey mirrors the sidecar's key segment format.
+type sidecarKey struct {
+	Value  interface{} `json:",omitempty"`
+	Binary []byte      `json:",omitempty"`
+}
+
+func (f *sidecarFallback) getRowByKey(ctx context.Context, out interface{}, familyName, tableName string, key []interface{}) (bool, error) {
+	var row map[string]interface{}
+	found, err := f.post(ctx, "get-row-by-key", familyName, tableName, key, &row)
+	if err != nil || !found {
+		return false, err
+	}
+	return true, decodeFallbackRow(row, out)
+}
+
+func (f *sidecarFallback) getRowsByKeyPrefix(ctx context.Context, familyName, tableName string, key []interface{}) (*Rows, error) {
+	var rows []map[string]interface{}
+	if _, err := f.post(ctx, "get-rows-by-key-prefix", familyName, tableName, key, &rows); err != nil {
+		return nil, err
+	}
+	return &Rows{fallback: rows, fallbackIdx: -1}, nil
+}
+
+// post sends a read request to the sidecar and decodes the response into
+// res. It returns false if the sidecar couldn't find the row.
+func (f *sidecarFallback) post(ctx context.Context, op, familyName, tableName string, key []interface{}, res interface{}) (found bool, err error) {
+	globalstats.Incr("sidecar-fallback-"+op, familyName, tableName)
+	defer func() {
+		if err != nil {
+			errs.IncrDefault(stats.T("op", "sidecar-fallback"), stats.T("family", familyName), stats.T("table", tableName))
+		}
+	}()
+
+	var body struct{ Key []sidecarKey }
+	for _, k := range key {
+		if b, ok := k.([]byte); ok {
+			body.Key = append(body.Key, sidecarKey{Binary: b})
+		} else {
+			body.Key = append(body.Key, sidecarKey{Value: k})
+		}
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		return false, errors.Wrap(err, "encode sidecar request")
+	}
+	u := fmt.Sprintf("%s/%s/%s/%s", f.url, op, url.PathEscape(familyName), url.PathEscape(tableName))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(b))
+	if err != nil {
+		return false, errors.Wrap(err, "build sidecar request")
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return false, errors.Wrap(err, "sidecar request")
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound && resp.Header.Get("X-Ctlstore") == "Not Found":
+		return false, nil
+	case resp.StatusCode != http.StatusOK:
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return false, errors.Errorf("sidecar responded %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	if err := dec.Decode(res); err != nil {
+		return false, errors.Wrap(err, "decode sidecar response")
+	}
+	return true, nil
+}
+
+// decodeFallbackRow fills out, which may be a map[string]interface{} or a
+// pointer to a struct, from a row read from the sidecar.
+func decodeFallbackRow(row map[string]interface{}, out interface{}) error {
+	if m, ok := out.(map[string]interface{}); ok {
+		for k, v := range row {
+			if n, ok := v.(json.Number); ok {
+				if i, err := n.Int64(); err == nil {
+					v = i
+				} else {
+					v, _ = n.Float64()
+				}
+			}
+			m[k] = v
+		}
+		return nil
+	}
+
+	val := reflect.ValueOf(out)
+	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Struct {
+		return scanfunc.ErrUnmarshalUnsupportedType
+	}
+	lower := make(map[string]interface{}, len(row))
+	for k, v := range row {
+		lower[strings.ToLower(k)] = v
+	}
+	elem := val.Elem()
+	for col, index := range scanfunc.StructColumns(elem.Type()) {
+		v, ok := lower[col]
+		if !ok || v == nil {
+			continue
+		}
+		field := scanfunc.FieldByIndex(elem, index)
+		// round trip each value through JSON so that numbers and base64
+		// encoded binary columns end up as the field's type. json columns
+		// are served as text, which is decoded as is into maps and slices.
+		b, err := json.Marshal(v)
+		if err != nil {
+			return errors.Wrapf(err, "encode column %s", col)
+		}
+		if s, ok := v.(string); ok && isJSONValueType(field.Type()) {
+			b = []byte(s)
+		}
+		if err := json.Unmarshal(b, field.Addr().Interface()); err != nil {
+			return errors.Wrapf(err, "decode column %s", col)
+		}
+	}
+	return nil
+}
+
+// isJSONValueType returns whether fields of the type are decoded from json
+// columns, rather than from the JSON string the sidecar serves them as.
+func isJSONValueType(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Map || typ.Kind() == reflect.Slice && typ.Elem().Kind() != reflect.Uint8
+}
@@ -0,0 +1,103 @@
+package ctlstore
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/ldb"
+)
+
+func TestReaderSidecarFallback(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		var body struct{ Key []struct{ Value interface{} } }
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		switch {
+		case r.URL.Path == "/get-row-by-key/remote/kvs" && body.Key[0].Value == "foo":
+			json.NewEncoder(w).Encode(map[string]interface{}{"key": "foo", "value": "bar", "n": 7, "doc": `{"a":1}`})
+		case r.URL.Path == "/get-row-by-key/remote/kvs":
+			w.Header().Set("X-Ctlstore", "Not Found")
+			w.WriteHeader(http.StatusNotFound)
+		case r.URL.Path == "/get-rows-by-key-prefix/remote/kvs":
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"key": "a", "value": "1"},
+				{"key": "b", "value": "2"},
+			})
+		default:
+			http.Error(w, "boom", http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	db, teardown := ldb.LDBForTest(t)
+	defer teardown()
+	_, err := db.Exec(initSQLForReadKeyByRow)
+	require.NoError(t, err)
+
+	reader := NewLDBReaderFromDB(db)
+	_, err = reader.GetRowByKey(ctx, map[string]interface{}{}, "remote", "kvs", "foo")
+	require.Equal(t, ErrTableNotFound, err)
+
+	WithSidecarFallback(srv.URL + "/")(reader)
+
+	// tables in the LDB are still read locally
+	local := testKVStruct{}
+	found, err := reader.GetRowByKey(ctx, &local, "foo", "bar", "foo")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Empty(t, paths)
+
+	row := map[string]interface{}{}
+	found, err = reader.GetRowByKey(ctx, row, "remote", "kvs", "foo")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, map[string]interface{}{"key": "foo", "value": "bar", "n": int64(7), "doc": `{"a":1}`}, row)
+
+	var out testKVStruct
+	found, err = reader.GetRowByKey(ctx, &out, "remote", "kvs", "foo")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, testKVStruct{"foo", "bar"}, out)
+
+	// fields are matched with columns as they are when reading the LDB
+	type embeddedKey struct{ Key string }
+	var inferred struct {
+		embeddedKey
+		Value string
+		Count int `ctlstore:"n"`
+		Doc   map[string]int
+	}
+	found, err = reader.GetRowByKey(ctx, &inferred, "remote", "kvs", "foo")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, "foo", inferred.Key)
+	require.Equal(t, "bar", inferred.Value)
+	require.Equal(t, 7, inferred.Count)
+	require.Equal(t, map[string]int{"a": 1}, inferred.Doc, "json columns are decoded from their text")
+
+	found, err = reader.GetRowByKey(ctx, &out, "remote", "kvs", "missing")
+	require.NoError(t, err)
+	require.False(t, found)
+
+	rows, err := reader.GetRowsByKeyPrefix(ctx, "remote", "kvs")
+	require.NoError(t, err)
+	var got []testKVStruct
+	for rows.Next() {
+		var kv testKVStruct
+		require.NoError(t, rows.Scan(&kv))
+		got = append(got, kv)
+	}
+	require.NoError(t, rows.Err())
+	require.NoError(t, rows.Close())
+	require.Equal(t, []testKVStruct{{"a", "1"}, {"b", "2"}}, got)
+
+	_, err = reader.GetRowByKey(ctx, &out, "remote", "others", "foo")
+	require.Error(t, err)
+}
@@ -0,0 +1,138 @@
+package ctlstore
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/segmentio/errors-go"
+
+	"github.com/segmentio/ctlstore/pkg/ldb"
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+// ErrSnapshotReleased is returned when reading from a released Snapshot.
+var ErrSnapshotReleased = errors.New("snapshot has been released")
+
+// Snapshot is a consistent view of the LDB, as of the ledger sequence it
+// was begun at. Unlike the reader's own methods, whose calls may each see
+// different statements applied, every read from a snapshot sees the same
+// rows, so that rows which are joined across tables agree with each other.
+//
+// A snapshot holds an SQLite read transaction open, which prevents the WAL
+// from being checkpointed past it, so it should be released as soon as
+// possible. Tables which aren't in the LDB aren't read from the sidecar
+// fallback, which can't be read consistently with the LDB.
+type Snapshot struct {
+	reader *LDBReader
+	seq    schema.DMLSequence
+
+	mu    sync.Mutex
+	tx    *sql.Tx // nil once released
+	stmts map[string]*sql.Stmt
+}
+
+// BeginSnapshot pins the current state of the LDB for reads through the
+// returned snapshot. The snapshot must be released, and is released once
+// the context is done.
+func (reader *LDBReader) BeginSnapshot(ctx context.Context) (*Snapshot, error) {
+	reader.mu.RLock()
+	db := reader.Db
+	reader.mu.RUnlock()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "begin snapshot")
+	}
+	// SQLite defers taking the snapshot until the transaction first reads
+	var seq sql.NullInt64
+	err = tx.QueryRowContext(ctx, "SELECT seq FROM "+ldb.LDBSeqTableName+" WHERE id = ?", ldb.LDBSeqTableID).Scan(&seq)
+	if err != nil && err != sql.ErrNoRows {
+		tx.Rollback()
+		return nil, errors.Wrap(err, "read snapshot sequence")
+	}
+	return &Snapshot{
+		reader: reader,
+		seq:    schema.DMLSequence(seq.Int64),
+		tx:     tx,
+		stmts:  map[string]*sql.Stmt{},
+	}, nil
+}
+
+// Sequence returns the ledger sequence of the last statement applied to
+// the LDB as of the snapshot.
+func (s *Snapshot) Sequence() schema.DMLSequence {
+	return s.seq
+}
+
+// GetRowByKey is like LDBReader.GetRowByKey, but reads the row as of the
+// snapshot.
+func (s *Snapshot) GetRowByKey(
+	ctx context.Context,
+	out interface{},
+	familyName string,
+	tableName string,
+	key ...interface{},
+) (found bool, err error) {
+	return s.reader.getRowByKey(ctx, s, out, familyName, tableName, key)
+}
+
+// GetRowsByKeyPrefix is like LDBReader.GetRowsByKeyPrefix, but reads the
+// rows as of the snapshot. The rows must be read before the snapshot is
+// released.
+func (s *Snapshot) GetRowsByKeyPrefix(ctx context.Context, familyName string, tableName string, key ...interface{}) (*Rows, error) {
+	return s.reader.getRowsByKeyPrefix(ctx, s, familyName, tableName, key)
+}
+
+// GetRowCountByKeyPrefix is like LDBReader.GetRowCountByKeyPrefix, but
+// counts the rows as of the snapshot.
+func (s *Snapshot) GetRowCountByKeyPrefix(ctx context.Context, familyName string, tableName string, key ...interface{}) (int64, error) {
+	return s.reader.getRowCountByKeyPrefix(ctx, s, familyName, tableName, key)
+}
+
+// GetRowsByKeys is like LDBReader.GetRowsByKeys, but reads the rows as of
+// the snapshot.
+func (s *Snapshot) GetRowsByKeys(ctx context.Context, familyName string, tableName string, keys [][]interface{}) (*KeyedRows, error) {
+	return s.reader.getRowsByKeys(ctx, s, familyName, tableName, keys)
+}
+
+// RowExists is like LDBReader.RowExists, but checks for the row as of the
+// snapshot.
+func (s *Snapshot) RowExists(ctx context.Context, familyName string, tableName string, key ...interface{}) (bool, error) {
+	return s.reader.rowExists(ctx, s, familyName, tableName, key)
+}
+
+// Release ends the snapshot. It's safe to call more than once.
+func (s *Snapshot) Release() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tx == nil {
+		return nil
+	}
+	// the statements are closed along with the transaction
+	err := s.tx.Rollback()
+	s.tx, s.stmts = nil, nil
+	if err == sql.ErrTxDone {
+		// the snapshot's context is done
+		err = nil
+	}
+	return errors.Wrap(err, "release snapshot")
+}
+
+// prepare returns a statement which reads from the snapshot
+func (s *Snapshot) prepare(ctx context.Context, query string) (*sql.Stmt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tx == nil {
+		return nil, ErrSnapshotReleased
+	}
+	if stmt, ok := s.stmts[query]; ok {
+		return stmt, nil
+	}
+	stmt, err := s.tx.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	s.stmts[query] = stmt
+	return stmt, nil
+}
@@ -0,0 +1,119 @@
+package ctlstore
+
+import (
+	"context"
+	"strings"
+
+	"github.com/segmentio/errors-go"
+
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+// GetTableSchema returns the fields and key fields of a table, as the LDB
+// has them. It returns ErrTableNotFound if the LDB has no such table.
+// Field options aren't included.
+func (reader *LDBReader) GetTableSchema(ctx context.Context, familyName string, tableName string) (res *schema.Table, err error) {
+	ctx, cancel := reader.queryContext(ctx)
+	defer cancel()
+	defer func() { err = observeQueryErr(ctx, err, familyName, tableName) }()
+	reader.mu.RLock()
+	defer reader.mu.RUnlock()
+
+	famName, err := schema.NewFamilyName(familyName)
+	if err != nil {
+		return nil, err
+	}
+	tblName, err := schema.NewTableName(tableName)
+	if err != nil {
+		return nil, err
+	}
+	return reader.tableSchema(ctx, famName.Name, tblName.Name)
+}
+
+// GetFamilySchemas returns the schemas of all of a family's tables in the
+// LDB, ordered by table name. The LDB can't tell a family without tables
+// from one that doesn't exist, so both are an empty slice.
+func (reader *LDBReader) GetFamilySchemas(ctx context.Context, familyName string) (res []schema.Table, err error) {
+	ctx, cancel := reader.queryContext(ctx)
+	defer cancel()
+	defer func() { err = observeQueryErr(ctx, err, familyName, "") }()
+	reader.mu.RLock()
+	defer reader.mu.RUnlock()
+
+	famName, err := schema.NewFamilyName(familyName)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := reader.Db.QueryContext(ctx, "SELECT name FROM sqlite_master WHERE type='table' ORDER BY name")
+	if err != nil {
+		return nil, errors.Wrap(err, "query table names")
+	}
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, errors.Wrap(err, "scan table name")
+		}
+		if ft, ok := schema.ParseFamilyTable(name); ok && ft.Family == famName.Name {
+			tables = append(tables, ft.Table)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "query table names")
+	}
+
+	res = make([]schema.Table, 0, len(tables))
+	for _, table := range tables {
+		tbl, err := reader.tableSchema(ctx, famName.Name, table)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *tbl)
+	}
+	return res, nil
+}
+
+// WARNING: assumes mutex is read locked
+func (reader *LDBReader) tableSchema(ctx context.Context, family string, table string) (*schema.Table, error) {
+	ldbTable := schema.FamilyTable{Family: family, Table: table}.String()
+	const qs = "SELECT name,type,pk FROM pragma_table_info(?) ORDER BY cid ASC"
+	rows, err := reader.Db.QueryContext(ctx, qs, ldbTable)
+	if err != nil {
+		return nil, errors.Wrap(err, "query pragma_table_info error")
+	}
+	defer rows.Close()
+
+	res := &schema.Table{Family: family, Name: table, Fields: [][]string{}}
+	var keyFields []string
+	for rows.Next() {
+		var name, sqlType string
+		var pk int
+		if err := rows.Scan(&name, &sqlType, &pk); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		ft, ok := schema.SqlTypeToFieldType(sqlType)
+		if !ok {
+			return nil, errors.Errorf("could not resolve type %q of %s.%s", sqlType, ldbTable, name)
+		}
+		res.Fields = append(res.Fields, []string{strings.ToLower(name), ft.String()})
+		if pk > 0 {
+			// pk is the field's 1-based position in the primary key
+			for len(keyFields) < pk {
+				keyFields = append(keyFields, "")
+			}
+			keyFields[pk-1] = strings.ToLower(name)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(res.Fields) == 0 {
+		// pragma_table_info has no rows for tables that don't exist
+		return nil, ErrTableNotFound
+	}
+	res.KeyFields = keyFields
+	return res, nil
+}
@@ -0,0 +1,65 @@
+package ctlstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/segmentio/errors-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+func TestGetTableSchema(t *testing.T) {
+	tu, teardown := NewLDBTestUtil(t)
+	defer teardown()
+	tu.CreateTable(LDBTestTableDef{
+		Family:    "family",
+		Name:      "table2",
+		Fields:    [][]string{{"id", "integer"}, {"name", "string"}, {"payload", "binary"}, {"region", "string"}},
+		KeyFields: []string{"region", "id"},
+	})
+	tu.CreateTable(LDBTestTableDef{
+		Family:    "family",
+		Name:      "table1",
+		Fields:    [][]string{{"key", "bytestring"}, {"at", "timestamp"}, {"ok", "boolean"}, {"ratio", "decimal"}},
+		KeyFields: []string{"key"},
+	})
+	tu.CreateTable(LDBTestTableDef{
+		Family:    "other",
+		Name:      "table1",
+		Fields:    [][]string{{"key", "string"}},
+		KeyFields: []string{"key"},
+	})
+	reader := NewLDBReaderFromDB(tu.DB)
+	ctx := context.Background()
+
+	table2 := schema.Table{
+		Family:    "family",
+		Name:      "table2",
+		Fields:    [][]string{{"id", "integer"}, {"name", "string"}, {"payload", "binary"}, {"region", "string"}},
+		KeyFields: []string{"region", "id"},
+	}
+	tbl, err := reader.GetTableSchema(ctx, "family", "table2")
+	require.NoError(t, err)
+	require.Equal(t, table2, *tbl)
+
+	_, err = reader.GetTableSchema(ctx, "family", "table3")
+	require.Equal(t, ErrTableNotFound, errors.Cause(err))
+
+	tables, err := reader.GetFamilySchemas(ctx, "family")
+	require.NoError(t, err)
+	require.Equal(t, []schema.Table{
+		{
+			Family:    "family",
+			Name:      "table1",
+			Fields:    [][]string{{"key", "bytestring"}, {"at", "timestamp"}, {"ok", "boolean"}, {"ratio", "decimal"}},
+			KeyFields: []string{"key"},
+		},
+		table2,
+	}, tables)
+
+	tables, err = reader.GetFamilySchemas(ctx, "missing")
+	require.NoError(t, err)
+	require.Empty(t, tables)
+}
@@ -0,0 +1,242 @@
+package ctlstore
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"sync"
+
+	"github.com/segmentio/errors-go"
+	"github.com/segmentio/events/v2"
+	"github.com/segmentio/stats/v4"
+
+	"github.com/segmentio/ctlstore/pkg/errs"
+	"github.com/segmentio/ctlstore/pkg/event"
+	"github.com/segmentio/ctlstore/pkg/ldbwriter"
+	"github.com/segmentio/ctlstore/pkg/schema"
+)
+
+// rowWatchers tracks the channels returned by WatchRow, keyed by rowKey.
+type rowWatchers struct {
+	mu       sync.Mutex
+	watchers map[string]map[chan struct{}]struct{}
+	// inProcess is set once the reader is fed changes by a reflector in
+	// the same process, in which case the changelog isn't tailed.
+	inProcess  bool
+	cancelTail context.CancelFunc
+}
+
+// WithChangelogPath sets the changelog WatchRow tails when the reader isn't
+// fed changes in-process. It defaults to the changelog next to the LDB.
+func WithChangelogPath(path string) ReaderOption {
+	return func(reader *LDBReader) {
+		reader.changelogPath = path
+	}
+}
+
+// WatchRow returns a channel which receives a value whenever the row with
+// the supplied key is inserted, updated or deleted. Notifications are
+// coalesced, so a slow receiver sees one value for many changes, and may be
+// spurious: the channel also fires when the reader can't tell whether the
+// row changed, for example after falling behind the changelog. The channel
+// is closed once ctx is done.
+//
+// Changes come from the reflector directly if it runs in the same process
+// and was configured with ChangeCallback. Otherwise WatchRow tails the
+// changelog, which the reflector must be configured to write.
+func (reader *LDBReader) WatchRow(ctx context.Context, familyName string, tableName string, key ...interface{}) (<-chan struct{}, error) {
+	famName, err := schema.NewFamilyName(familyName)
+	if err != nil {
+		return nil, err
+	}
+	tblName, err := schema.NewTableName(tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	reader.mu.RLock()
+	pk, err := reader.getPrimaryKey(discardContext(), schema.LDBTableName(famName, tblName))
+	reader.mu.RUnlock()
+	switch {
+	case err != nil:
+		return nil, err
+	case pk.Zero():
+		return nil, ErrTableHasNoPrimaryKey
+	case len(pk.Fields) != len(key):
+		return nil, ErrNeedFullKey
+	}
+	key = append([]interface{}(nil), key...)
+	if err := convertKeyBeforeQuery(pk, key); err != nil {
+		return nil, err
+	}
+	rk, err := rowKey(famName.Name, tblName.Name, key)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &reader.watch
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.inProcess && w.cancelTail == nil {
+		if err := reader.tailChangelog(); err != nil {
+			return nil, err
+		}
+	}
+	ch := make(chan struct{}, 1)
+	if w.watchers == nil {
+		w.watchers = map[string]map[chan struct{}]struct{}{}
+	}
+	if w.watchers[rk] == nil {
+		w.watchers[rk] = map[chan struct{}]struct{}{}
+	}
+	w.watchers[rk][ch] = struct{}{}
+
+	go func() {
+		<-ctx.Done()
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		delete(w.watchers[rk], ch)
+		if len(w.watchers[rk]) == 0 {
+			delete(w.watchers, rk)
+		}
+		close(ch)
+	}()
+	return ch, nil
+}
+
+// ChangeCallback returns a callback which feeds the changes a reflector in
+// the same process makes to the LDB to WatchRow, so that the changelog
+// doesn't need to be tailed. Configure the reflector with it before calling
+// WatchRow.
+func (reader *LDBReader) ChangeCallback() ldbwriter.LDBWriteCallback {
+	reader.watch.mu.Lock()
+	defer reader.watch.mu.Unlock()
+	reader.watch.inProcess = true
+	return readerChangeCallback{reader}
+}
+
+type readerChangeCallback struct {
+	reader *LDBReader
+}
+
+func (c readerChangeCallback) LDBWritten(ctx context.Context, data ldbwriter.LDBWriteMetadata) {
+	for _, change := range data.Changes {
+		fam, tbl, err := schema.DecodeLDBTableName(change.TableName)
+		if err != nil {
+			// e.g. the ledger tables
+			continue
+		}
+		keys, err := change.ExtractKeys(data.DB)
+		if err != nil {
+			events.Log("Could not extract keys of change to %{table}s for watchers: %{error}v", change.TableName, err)
+			c.reader.watch.notifyAll()
+			continue
+		}
+		for _, key := range keys {
+			// encode the key the way the changelog does, so that it is
+			// compared the same way whichever way the change arrives
+			b, err := json.Marshal(key)
+			if err != nil {
+				c.reader.watch.notifyAll()
+				continue
+			}
+			var ek []event.Key
+			if err := json.Unmarshal(b, &ek); err != nil {
+				c.reader.watch.notifyAll()
+				continue
+			}
+			c.reader.watch.notify(fam.Name, tbl.Name, ek)
+		}
+	}
+}
+
+// tailChangelog starts notifying watchers of the changes in the changelog.
+// It assumes the watch mutex is held.
+func (reader *LDBReader) tailChangelog() error {
+	path := reader.changelogPath
+	if path == "" && reader.path != "" {
+		path = filepath.Join(filepath.Dir(reader.path), DefaultChangelogFilename)
+	}
+	if path == "" {
+		return errors.New("no changelog to watch rows with, see WithChangelogPath")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	iter, err := event.NewIterator(ctx, path)
+	if err != nil {
+		cancel()
+		return errors.Wrap(err, "tail changelog")
+	}
+	reader.watch.cancelTail = cancel
+	go func() {
+		defer iter.Close()
+		for {
+			ev, err := iter.Next(ctx)
+			switch {
+			case ctx.Err() != nil:
+				return
+			case err != nil:
+				if errors.Cause(err) != event.ErrOutOfSync {
+					events.Log("Error tailing changelog for watchers: %{error}v", err)
+					errs.IncrDefault(stats.T("op", "watch-changelog"))
+				}
+				reader.watch.notifyAll()
+			default:
+				reader.watch.notify(ev.RowUpdate.FamilyName, ev.RowUpdate.TableName, ev.RowUpdate.Keys)
+			}
+		}
+	}()
+	return nil
+}
+
+func (w *rowWatchers) notify(family, table string, keys []event.Key) {
+	values := make([]interface{}, len(keys))
+	for i, k := range keys {
+		values[i] = k.Value
+	}
+	rk, err := rowKey(family, table, values)
+	if err != nil {
+		w.notifyAll()
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for ch := range w.watchers[rk] {
+		fire(ch)
+	}
+}
+
+func (w *rowWatchers) notifyAll() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, chs := range w.watchers {
+		for ch := range chs {
+			fire(ch)
+		}
+	}
+}
+
+func (w *rowWatchers) close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cancelTail != nil {
+		w.cancelTail()
+	}
+}
+
+func fire(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// rowKey identifies a row. Key values are JSON encoded, so that a watched
+// []byte key matches the base64 string the changelog has for it, and an
+// int64 matches the float64 the changelog decodes to.
+func rowKey(family, table string, key []interface{}) (string, error) {
+	b, err := json.Marshal(key)
+	if err != nil {
+		return "", errors.Wrap(err, "encode key")
+	}
+	return family + "\x00" + table + "\x00" + string(b), nil
+}
@@ -0,0 +1,103 @@
+package ctlstore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/segmentio/ctlstore/pkg/ldb"
+	"github.com/segmentio/ctlstore/pkg/ldbwriter"
+	"github.com/segmentio/ctlstore/pkg/sqlite"
+)
+
+func requireFired(t *testing.T, ch <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("watcher did not fire")
+	}
+}
+
+func requireNotFired(t *testing.T, ch <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-ch:
+		t.Fatal("watcher fired")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestWatchRowInProcess(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db, teardown := ldb.LDBForTest(t)
+	defer teardown()
+	_, err := db.Exec(initSQLForReadKeyByRow)
+	require.NoError(t, err)
+
+	reader := NewLDBReaderFromDB(db)
+	_, err = reader.WatchRow(ctx, "foo", "bar", "foo", "extra")
+	require.Equal(t, ErrNeedFullKey, err)
+	_, err = reader.WatchRow(ctx, "foo", "missing", "foo")
+	require.Equal(t, ErrTableNotFound, err)
+
+	cb := reader.ChangeCallback()
+	watchCtx, stopWatching := context.WithCancel(ctx)
+	foo, err := reader.WatchRow(watchCtx, "foo", "bar", "foo")
+	require.NoError(t, err)
+	other, err := reader.WatchRow(ctx, "foo", "bar", "other")
+	require.NoError(t, err)
+
+	cb.LDBWritten(ctx, ldbwriter.LDBWriteMetadata{
+		DB: db,
+		Changes: []sqlite.SQLiteWatchChange{{
+			DatabaseName: "main",
+			TableName:    "foo___bar",
+			OldRow:       []interface{}{"foo", "bar"},
+			NewRow:       []interface{}{"foo", "baz"},
+		}},
+	})
+	requireFired(t, foo)
+	requireNotFired(t, foo) // the two keys of the update were coalesced
+	requireNotFired(t, other)
+
+	stopWatching()
+	_, open := <-foo
+	require.False(t, open)
+}
+
+func TestWatchRowChangelog(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db, teardown := ldb.LDBForTest(t)
+	defer teardown()
+	_, err := db.Exec(initSQLForReadKeyByRow)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	clPath := filepath.Join(dir, DefaultChangelogFilename)
+	require.NoError(t, os.WriteFile(clPath, nil, 0644))
+
+	reader := NewLDBReaderFromDB(db)
+	WithChangelogPath(clPath)(reader)
+	defer reader.watch.close()
+
+	foo, err := reader.WatchRow(ctx, "foo", "bar", "foo")
+	require.NoError(t, err)
+	other, err := reader.WatchRow(ctx, "foo", "bar", "other")
+	require.NoError(t, err)
+
+	f, err := os.OpenFile(clPath, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = f.WriteString(`{"seq":1,"family":"foo","table":"bar","key":[{"name":"key","type":"VARCHAR","value":"foo"}]}` + "\n")
+	require.NoError(t, err)
+
+	requireFired(t, foo)
+	requireNotFired(t, other)
+}